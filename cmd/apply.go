@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:     "apply",
+	GroupID: groupInstall,
+	Short:   "Reconcile the cluster with a specification file",
+	Long: `Reconcile the cluster with a declarative specification of the installation,
+e.g. kept in Git. The operators and monitoring are provisioned like the install
+command does and the declared database clusters are created or updated. Objects
+which are not declared are left intact, so apply can be re-run at any time.
+
+  apiVersion: everest.percona.com/v1alpha1
+  kind: Everest
+  operators:
+    dbaas-operator:
+      channel: stable-v0
+  monitoring:
+    enabled: true
+    endpoint: https://pmm.example.com
+    credentialsSecret: pmm-credentials
+  backupStorages:
+    s3:
+      type: s3
+      storageProvider:
+        bucket: backups
+        region: us-east-1
+        credentialsSecret: s3-credentials
+  databaseClusters:
+  - name: orders
+    engine: pxc
+    size: 3
+    cpu: "1"
+    memory: 2G
+    disk: 25G
+    backupStorages: [s3]
+
+Backup storages have the fields of spec.backup.storages of DatabaseCluster.
+Passwords are never read from the specification. Reference existing secrets or
+set them in the configuration file or environment variables.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		file, _ := cmd.Flags().GetString("filename")
+		if err := cli.Apply(cmd.Context(), file); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().StringP("filename", "f", "", "Specification file of the installation")
+}
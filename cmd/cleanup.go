@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// cleanupCmd represents the cleanup command
+var cleanupCmd = &cobra.Command{
+	Use:     "cleanup",
+	GroupID: groupInstall,
+	Short:   "Remove resources the provisioner does not use anymore",
+	Long: `Remove resources created by the provisioner which are not used anymore.
+Every object the provisioner applies is labeled with everest.percona.com/created-by
+and the ID of the run in everest.percona.com/run-id; only labeled resources are
+considered.
+
+Pass --orphans to remove subscriptions OLM failed to resolve or install, PMM VM
+agents replaced by a newer one and the vm-operator-* secrets holding credentials
+and certificates of VM agents which do not exist anymore. Resources to be deleted
+are shown and must be confirmed unless --yes is passed.`,
+	Example: "  " + binaryName + " cleanup --orphans --yes",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := cli.CleanupOptions{ConfirmOptions: confirmOptions(cmd)}
+		opts.Orphans, _ = cmd.Flags().GetBool("orphans")
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.Cleanup(cmd.Context(), opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cleanupCmd)
+	cleanupCmd.Flags().Bool("orphans", false, "Remove failed subscriptions, replaced PMM VM agents and their leftover secrets")
+	addConfirmFlags(cleanupCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// binaryName is the name the CLI is shipped under.
+	binaryName = "everestctl"
+	// legacyBinaryName is the former name of the CLI. Invocations under this name
+	// keep working so existing automation does not break.
+	legacyBinaryName = "everest-provisioner"
+)
+
+// setBinaryName adapts the command tree to the name the binary was invoked under.
+// Help and usage show the invoked name while the legacy name prints a rename notice.
+func setBinaryName(arg0 string) {
+	name := strings.TrimSuffix(filepath.Base(arg0), filepath.Ext(arg0))
+	if name != legacyBinaryName {
+		return
+	}
+	rootCmd.Use = legacyBinaryName
+	fmt.Fprintf(os.Stderr, "%s has been renamed to %s, the old name will be removed in a future release\n",
+		legacyBinaryName, binaryName)
+}
+
+// runRootInstall keeps installing when the CLI is run without a command, which
+// is how the installation was started before the install command existed.
+func runRootInstall(cmd *cobra.Command, args []string) {
+	fmt.Fprintf(os.Stderr, "Running %s without a command is deprecated, use \"%s install\" instead\n",
+		cmd.Name(), binaryName)
+	runInstall(cmd, args)
+}
+
+// addLegacyCommand keeps a renamed subcommand of parent working under its former name.
+// The legacy command is hidden from help, prints a deprecation notice when it is run
+// and shares the flags of cmd, so it must be added after the flags of cmd are defined.
+func addLegacyCommand(parent, cmd *cobra.Command, legacyName string) {
+	legacy := &cobra.Command{
+		Use:        legacyName,
+		Short:      cmd.Short,
+		Long:       cmd.Long,
+		Args:       cmd.Args,
+		Hidden:     true,
+		Deprecated: fmt.Sprintf("use %q instead", cmd.Name()),
+		Run: func(c *cobra.Command, args []string) {
+			cmd.Run(c, args)
+		},
+	}
+	legacy.Flags().AddFlagSet(cmd.Flags())
+	parent.AddCommand(legacy)
+}
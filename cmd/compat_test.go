@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegacyCommands(t *testing.T) {
+	for legacyName, cmd := range map[string]string{
+		"diagnose": "support-bundle",
+		"token":    "service-account",
+	} {
+		t.Run(legacyName, func(t *testing.T) {
+			legacy, _, err := rootCmd.Find([]string{legacyName})
+			require.NoError(t, err)
+			assert.Equal(t, legacyName, legacy.Name())
+			assert.True(t, legacy.Hidden)
+			assert.Contains(t, legacy.Deprecated, cmd)
+
+			var out bytes.Buffer
+			rootCmd.SetOut(&out)
+			rootCmd.SetErr(&out)
+			rootCmd.SetArgs([]string{legacyName, "--help"})
+			t.Cleanup(func() {
+				rootCmd.SetOut(nil)
+				rootCmd.SetErr(nil)
+				rootCmd.SetArgs(nil)
+			})
+			require.NoError(t, rootCmd.Execute())
+			assert.Contains(t, out.String(), `Command "`+legacyName+`" is deprecated, use "`+cmd+`" instead`)
+		})
+	}
+}
+
+func TestLegacyCommandFlags(t *testing.T) {
+	legacy, _, err := rootCmd.Find([]string{"diagnose"})
+	require.NoError(t, err)
+	require.NoError(t, legacy.ParseFlags([]string{"--file", "report.tar.gz", "-o", "json"}))
+	t.Cleanup(func() {
+		diagnoseCmd.Flags().Set("file", "")
+		diagnoseCmd.Flags().Set("output", cli.DiagnoseArchive)
+	})
+
+	file, _ := diagnoseCmd.Flags().GetString("file")
+	output, _ := diagnoseCmd.Flags().GetString("output")
+	assert.Equal(t, "report.tar.gz", file)
+	assert.Equal(t, "json", output)
+}
+
+func TestSetBinaryName(t *testing.T) {
+	t.Cleanup(func() { rootCmd.Use = binaryName })
+
+	setBinaryName("/usr/local/bin/" + binaryName)
+	assert.Equal(t, binaryName, rootCmd.Use)
+	setBinaryName("/usr/local/bin/" + legacyBinaryName)
+	assert.Equal(t, legacyBinaryName, rootCmd.Use)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// completeDatabaseClusters completes the first argument with the names of the database
+// clusters. Nothing is completed if the cluster cannot be reached.
+func completeDatabaseClusters(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	c, err := config.ParseConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	cli, err := cli.New(c)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names, err := cli.DatabaseClusterNames(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	completions := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration",
+	Long: `Manage the configuration.
+
+All settings of the flags can be stored in a YAML file passed with --config or
+named by ` + config.ConfigEnv + `, so a single file describes the installation.
+Settings are taken from the first source defining them:
+
+  1. command line flags passed explicitly
+  2. environment variables prefixed with ` + config.EnvPrefix + `_, e.g. ` + config.EnvPrefix + `_MONITORING_PMM_ENDPOINT
+  3. the config file
+  4. defaults of the flags
+
+Lists and maps, e.g. image_pull_secrets and operator_channels, are replaced as
+a whole and can be set in the config file or with flags only.`,
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration",
+	Long: `Validate the configuration merged from the config file, the environment
+variables prefixed with ` + config.EnvPrefix + `_ and the flags. All invalid settings are reported.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := config.ParseConfig(); err != nil {
+			exitWithError(err)
+		}
+		if file := config.File(); file != "" {
+			fmt.Println(cli.Message(cli.MsgConfigValid, file))
+			return
+		}
+		fmt.Println(cli.Message(cli.MsgConfigValidNoFile))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+}
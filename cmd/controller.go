@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// controllerCmd represents the controller command
+var controllerCmd = &cobra.Command{
+	Use:     "controller",
+	GroupID: groupOperator,
+	Short:   "Reconcile the cluster to an EverestInstallation resource",
+	Long: `Install the EverestInstallation CRD and keep the cluster in line with the
+EverestInstallation resource in the default namespace. OLM, the operators and
+monitoring are installed as described by the resource and installed again if
+they are removed. The result is reported in the status of the resource.
+
+The controller never creates API keys in PMM. Reference a secret with the
+credentials the metrics are written with in spec.monitoring.pmm.credentialsSecret
+or configure the PMM username and password of the controller.
+
+Example resource:
+
+  apiVersion: everest.percona.com/v1alpha1
+  kind: EverestInstallation
+  metadata:
+    name: everest
+  spec:
+    operatorChannels:
+      percona-xtradb-cluster-operator: stable-v1
+    monitoring:
+      enabled: true
+      pmm:
+        endpoint: https://pmm.example.com
+        credentialsSecret: pmm-credentials
+
+Pass --metrics-address to serve Prometheus metrics of applied objects, install
+phase durations, retried API requests and failed reconciliations.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		metricsAddress, _ := cmd.Flags().GetString("metrics-address")
+		opts := cli.ControllerOptions{Interval: interval, MetricsAddress: metricsAddress}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.RunController(cmd.Context(), opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(controllerCmd)
+	controllerCmd.Flags().Duration("interval", time.Minute, "How often the cluster is reconciled")
+	controllerCmd.Flags().String("metrics-address", "", "Address Prometheus metrics are served on at /metrics, e.g. :9090; metrics are not served if it is empty")
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// dbCmd represents the db command
+var dbCmd = &cobra.Command{
+	Use:     "db",
+	GroupID: groupDatabase,
+	Aliases: []string{"cluster"},
+	Short:   "Manage database clusters",
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// dbBackupCmd represents the db backup command
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Manage backups of database clusters",
+}
+
+func init() {
+	dbCmd.AddCommand(dbBackupCmd)
+}
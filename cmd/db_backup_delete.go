@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbBackupDeleteCmd represents the db backup delete command
+var dbBackupDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a backup of a database cluster",
+	Long: `Delete a backup of a database cluster. The operator removes the backup
+from its storage. The deletion must be confirmed unless --yes is passed.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.DeleteBackup(cmd.Context(), args[0], confirmOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbBackupCmd.AddCommand(dbBackupDeleteCmd)
+	dbBackupDeleteCmd.Flags().BoolP("yes", "y", false, "Proceed without asking for confirmation")
+}
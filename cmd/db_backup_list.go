@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbBackupListCmd represents the db backup list command
+var dbBackupListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List backups of database clusters",
+	Example: "  " + binaryName + " db backup list --cluster mysql --state Failed",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cluster, _ := cmd.Flags().GetString("cluster")
+		state, _ := cmd.Flags().GetString("state")
+		output, _ := cmd.Flags().GetString("output")
+		opts := cli.BackupListOptions{Cluster: cluster, State: state, Output: output}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.ListBackups(cmd.Context(), opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbBackupCmd.AddCommand(dbBackupListCmd)
+	dbBackupListCmd.Flags().String("cluster", "", "List backups of the database cluster only")
+	dbBackupListCmd.Flags().String("state", "", "List backups in the state only, e.g. Succeeded or Failed")
+	dbBackupListCmd.Flags().StringP("output", "o", cli.OutputText, "Output format: text or json")
+}
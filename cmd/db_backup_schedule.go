@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbBackupScheduleCmd represents the db backup schedule command
+var dbBackupScheduleCmd = &cobra.Command{
+	Use:   "schedule <cluster>",
+	Short: "Set or remove a backup schedule of a database cluster",
+	Long: `Set or remove a backup schedule of a database cluster.
+
+Schedules are identified by their name. Setting a schedule of an existing name
+replaces it. The schedule is a five field cron expression or a macro such as
+@daily, and the backups are written to a storage configured in the cluster.`,
+	Example: "  " + binaryName + " db backup schedule mysql --name daily --cron \"0 2 * * *\" --storage s3 --keep 7\n" +
+		"  " + binaryName + " db backup schedule mysql --name daily --remove",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		cron, _ := cmd.Flags().GetString("cron")
+		storage, _ := cmd.Flags().GetString("storage")
+		keep, _ := cmd.Flags().GetInt("keep")
+		disable, _ := cmd.Flags().GetBool("disable")
+		remove, _ := cmd.Flags().GetBool("remove")
+		opts := cli.BackupScheduleOptions{Name: name, Cron: cron, Storage: storage, Keep: keep, Disable: disable, Remove: remove}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.ScheduleBackups(cmd.Context(), args[0], opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbBackupCmd.AddCommand(dbBackupScheduleCmd)
+	dbBackupScheduleCmd.Flags().String("name", "daily", "Name of the schedule")
+	dbBackupScheduleCmd.Flags().String("cron", "", "Cron expression of the schedule")
+	dbBackupScheduleCmd.Flags().String("storage", "", "Backup storage of the cluster the backups are written to")
+	dbBackupScheduleCmd.Flags().Int("keep", 0, "Number of backups kept by the schedule; 0 keeps all")
+	dbBackupScheduleCmd.Flags().Bool("disable", false, "Set the schedule disabled")
+	dbBackupScheduleCmd.Flags().Bool("remove", false, "Remove the schedule")
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbCloneCmd represents the db clone command
+var dbCloneCmd = &cobra.Command{
+	Use:   "clone <source> <target>",
+	Short: "Clone a database cluster from its latest backup",
+	Long: `Create a new database cluster with the spec of an existing one and restore
+the latest succeeded backup of the existing cluster into it. The admin
+credentials are copied since the restored data keeps its users. Backup
+schedules and point-in-time recovery are not copied.
+
+The number of nodes and the resources of every node can be overridden. The
+disk cannot be smaller than the disk of the source cluster.`,
+	Example:           "  " + binaryName + " db clone orders orders-staging --size 1 --memory 2G",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		size, _ := cmd.Flags().GetInt32("size")
+		cpu, _ := cmd.Flags().GetString("cpu")
+		memory, _ := cmd.Flags().GetString("memory")
+		disk, _ := cmd.Flags().GetString("disk")
+		opts := cli.DatabaseScaleOptions{Size: size, CPU: cpu, Memory: memory, Disk: disk}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.CloneDatabaseCluster(cmd.Context(), args[0], args[1], opts, waitOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbCloneCmd)
+	addWaitFlags(dbCloneCmd)
+	dbCloneCmd.Flags().Int32P("size", "s", 0, "Number of database nodes of the clone (default the size of the source)")
+	dbCloneCmd.Flags().String("cpu", "", "CPU per database node of the clone")
+	dbCloneCmd.Flags().String("memory", "", "Memory per database node of the clone")
+	dbCloneCmd.Flags().String("disk", "", "Disk size per database node of the clone")
+}
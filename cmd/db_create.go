@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbCreateCmd represents the db create command
+var dbCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a database cluster",
+	Long: `Create a database cluster either from a DatabaseCluster manifest
+passed with --file or from the given flags.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		opts := databaseClusterOptions(cmd)
+		if len(args) != 0 {
+			opts.Name = args[0]
+		}
+		if err := cli.CreateDatabaseCluster(cmd.Context(), opts, waitOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func databaseClusterOptions(cmd *cobra.Command) cli.DatabaseClusterOptions {
+	file, _ := cmd.Flags().GetString("file")
+	engine, _ := cmd.Flags().GetString("engine")
+	version, _ := cmd.Flags().GetString("db-version")
+	size, _ := cmd.Flags().GetInt32("size")
+	cpu, _ := cmd.Flags().GetString("cpu")
+	memory, _ := cmd.Flags().GetString("memory")
+	disk, _ := cmd.Flags().GetString("disk")
+	storageClass, _ := cmd.Flags().GetString("storage-class")
+	expose, _ := cmd.Flags().GetString("expose")
+	internal, _ := cmd.Flags().GetBool("internal")
+	ttl, _ := cmd.Flags().GetDuration("ttl")
+	spread, _ := cmd.Flags().GetString("spread")
+	spreadRequired, _ := cmd.Flags().GetBool("spread-required")
+	return cli.DatabaseClusterOptions{
+		File:           file,
+		Engine:         engine,
+		Version:        version,
+		Size:           size,
+		CPU:            cpu,
+		Memory:         memory,
+		Disk:           disk,
+		StorageClass:   storageClass,
+		Expose:         expose,
+		Internal:       internal,
+		TTL:            ttl,
+		Spread:         spread,
+		SpreadRequired: spreadRequired,
+	}
+}
+
+func init() {
+	dbCmd.AddCommand(dbCreateCmd)
+	dbCreateCmd.Flags().StringP("file", "f", "", "DatabaseCluster manifest to create the cluster from")
+	dbCreateCmd.Flags().StringP("engine", "e", "pxc", "Database engine: pxc or psmdb")
+	dbCreateCmd.Flags().String("db-version", "", "Database version supported by the installed operator, see db engines")
+	dbCreateCmd.Flags().Int32P("size", "s", 3, "Number of database nodes")
+	dbCreateCmd.Flags().String("cpu", "1", "CPU per database node")
+	dbCreateCmd.Flags().String("memory", "2G", "Memory per database node")
+	dbCreateCmd.Flags().String("disk", "25G", "Disk size per database node")
+	dbCreateCmd.Flags().String("storage-class", "", "Storage class of the volumes, see the storage-classes command (default the default storage class)")
+	dbCreateCmd.Flags().String("expose", "", "Expose the cluster outside of Kubernetes with a load balancer of the cloud provider: external")
+	dbCreateCmd.Flags().Bool("internal", false, "Make the load balancer of an exposed cluster reachable only from the network of the cluster")
+	dbCreateCmd.Flags().Duration("ttl", 0, "Delete the cluster after the duration, e.g. 4h; expired clusters are deleted by serve or db expire")
+	dbCreateCmd.Flags().String("spread", "", "Failure domain the pods are spread over: auto, zone, hostname or none (default placement.spread or auto)")
+	dbCreateCmd.Flags().Bool("spread-required", false, "Refuse scheduling two pods into the same failure domain instead of only preferring distinct ones")
+	addWaitFlags(dbCreateCmd)
+}
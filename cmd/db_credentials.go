@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbCredentialsCmd represents the db credentials command
+var dbCredentialsCmd = &cobra.Command{
+	Use:   "credentials <name>",
+	Short: "Show the credentials of a database cluster",
+	Long: `Show the host, port, user, password and connection string of the admin
+user of a database cluster. The credentials are read from the secret of the
+cluster created by the engine operator.
+Use --output json to pass the credentials to other tools.`,
+	Example:           "  " + binaryName + " db credentials mysql -o json",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.DatabaseCredentials(cmd.Context(), args[0], output); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbCredentialsCmd)
+	dbCredentialsCmd.Flags().StringP("output", "o", cli.OutputText, "Output format: text or json")
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbDeleteCmd represents the db delete command
+var dbDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a database cluster",
+	Long: `Delete a database cluster together with its volumes and secrets.
+Resources to be deleted are shown and must be confirmed unless --yes is passed.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.DeleteDatabaseCluster(cmd.Context(), args[0], confirmOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbDeleteCmd)
+	addConfirmFlags(dbDeleteCmd)
+}
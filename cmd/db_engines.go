@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbEnginesCmd represents the db engines command
+var dbEnginesCmd = &cobra.Command{
+	Use:   "engines [engine]",
+	Short: "Show database versions supported by the installed operators",
+	Long: `Show the database versions the installed operators of the engines support.
+The versions are read from the database images the cluster service version of
+every operator relates to. Pass one of them to db create with --db-version.`,
+	Example: "  " + binaryName + " db engines\n" +
+		"  " + binaryName + " db engines pxc -o json",
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"pxc", "psmdb"},
+	Run: func(cmd *cobra.Command, args []string) {
+		var engine string
+		if len(args) != 0 {
+			engine = args[0]
+		}
+		output, _ := cmd.Flags().GetString("output")
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.Engines(cmd.Context(), engine, output); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbEnginesCmd)
+	dbEnginesCmd.Flags().StringP("output", "o", cli.OutputText, "Output format: text or json")
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbExecCmd represents the db exec command
+var dbExecCmd = &cobra.Command{
+	Use:   "exec <name> -- <command> [args...]",
+	Short: "Run a command in the primary pod of a database cluster",
+	Long: `Run a command, e.g. the mysql client or mongosh, in the database container
+of the primary pod of a database cluster, like kubectl exec.
+
+The first ready PXC pod by name, the primary of the PSMDB replica set and the
+PostgreSQL pod labelled as the primary are used unless a pod is set with --pod. Pass --stdin to attach the
+standard input and --tty to allocate a terminal for interactive clients. The
+command exits with the exit status of the remote command.`,
+	Example: "  " + binaryName + " db exec mysql -it -- mysql -uroot -p\n" +
+		"  " + binaryName + " db exec mongo -it -- mongosh admin -u databaseAdmin -p\n" +
+		"  " + binaryName + " db exec mysql --pod mysql-pxc-1 -- ls /var/lib/mysql",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.ArgsLenAtDash() != 1 || len(args) < 2 {
+			return errors.New("pass the name of the database cluster and the command after --")
+		}
+		return nil
+	},
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		stdin, _ := cmd.Flags().GetBool("stdin")
+		tty, _ := cmd.Flags().GetBool("tty")
+		pod, _ := cmd.Flags().GetString("pod")
+		container, _ := cmd.Flags().GetString("container")
+		opts := cli.ExecOptions{Command: args[1:], Pod: pod, Container: container, Stdin: stdin, TTY: tty}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.ExecDatabaseCluster(cmd.Context(), args[0], opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbExecCmd)
+	dbExecCmd.Flags().BoolP("stdin", "i", false, "Pass the standard input to the command")
+	dbExecCmd.Flags().BoolP("tty", "t", false, "Allocate a terminal for the command; the standard input must be a terminal")
+	dbExecCmd.Flags().String("pod", "", "Pod to run the command in instead of the primary")
+	dbExecCmd.Flags().StringP("container", "c", "", "Container to run the command in; defaults to the database container")
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbExpireCmd represents the db expire command
+var dbExpireCmd = &cobra.Command{
+	Use:   "expire",
+	Short: "Delete expired database clusters",
+	Long: `Delete the database clusters created with --ttl whose TTL has passed.
+Run it periodically, e.g. from a CronJob, unless the serve command is running.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.DeleteExpiredDatabaseClusters(cmd.Context()); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbExpireCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbMonitoringCmd represents the db monitoring command
+var dbMonitoringCmd = &cobra.Command{
+	Use:   "monitoring",
+	Short: "Choose database clusters to monitor",
+	Long: `Enable or disable monitoring of single database clusters.
+It takes effect when monitoring was installed with --monitoring.selective,
+otherwise all database clusters are monitored.
+
+Pass --instance to enable to make the cluster report to a monitoring instance
+created with "monitoring create" instead.`,
+}
+
+// dbMonitoringEnableCmd represents the db monitoring enable command
+var dbMonitoringEnableCmd = &cobra.Command{
+	Use:               "enable <name>",
+	Short:             "Start monitoring a database cluster",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		instance, _ := cmd.Flags().GetString("instance")
+		setDatabaseClusterMonitoring(cmd.Context(), args[0], true, instance)
+	},
+}
+
+// dbMonitoringDisableCmd represents the db monitoring disable command
+var dbMonitoringDisableCmd = &cobra.Command{
+	Use:               "disable <name>",
+	Short:             "Stop monitoring a database cluster",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		setDatabaseClusterMonitoring(cmd.Context(), args[0], false, "")
+	},
+}
+
+func setDatabaseClusterMonitoring(ctx context.Context, name string, enabled bool, instance string) {
+	c, err := config.ParseConfig()
+	if err != nil {
+		exitWithError(err)
+	}
+	cli, err := cli.New(c)
+	if err != nil {
+		exitWithError(err)
+	}
+	if err := cli.SetDatabaseClusterMonitoring(ctx, name, enabled, instance); err != nil {
+		exitWithError(err)
+	}
+}
+
+func init() {
+	dbCmd.AddCommand(dbMonitoringCmd)
+	dbMonitoringCmd.AddCommand(dbMonitoringEnableCmd)
+	dbMonitoringCmd.AddCommand(dbMonitoringDisableCmd)
+	dbMonitoringEnableCmd.Flags().String("instance", "", "Monitoring instance the database cluster reports to")
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbPauseCmd represents the db pause command
+var dbPauseCmd = &cobra.Command{
+	Use:   "pause <name>",
+	Short: "Pause a database cluster",
+	Long: `Pause a database cluster.
+
+The operator stops the pods of the cluster. Its volumes and secrets are kept,
+so the data is available again once the cluster is resumed with db resume.`,
+	Example:           "  " + binaryName + " db pause mysql",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.PauseDatabaseCluster(cmd.Context(), args[0], waitOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbPauseCmd)
+	addWaitFlags(dbPauseCmd)
+}
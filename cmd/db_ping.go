@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbPingCmd represents the db ping command
+var dbPingCmd = &cobra.Command{
+	Use:   "ping <name>",
+	Short: "Check that a database cluster accepts connections",
+	Long: `Check that a database cluster accepts connections.
+
+A local port is forwarded to a ready pod of the cluster and the database driver
+of the engine connects as the admin user and queries the server version, so the
+check succeeds only if the database itself accepts connections and not only if
+the cluster reports to be ready.`,
+	Example:           "  " + binaryName + " db create mysql --wait && " + binaryName + " db ping mysql",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.PingDatabaseCluster(cmd.Context(), args[0]); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbPingCmd)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbPITRCmd represents the db pitr command
+var dbPITRCmd = &cobra.Command{
+	Use:   "pitr <cluster>",
+	Short: "Configure point-in-time recovery of a database cluster",
+	Long: `Enable or disable point-in-time recovery of a database cluster.
+
+The binary logs of PXC clusters or the oplog of PSMDB clusters are uploaded
+continuously to a backup storage configured in the cluster. The cluster can
+then be restored to any point in time after a backup with
+db restore --pitr --timestamp. Backups of the cluster must be enabled.`,
+	Example: "  " + binaryName + " db pitr mysql --storage s3 --upload-interval 1m\n" +
+		"  " + binaryName + " db pitr mysql --disable",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		storage, _ := cmd.Flags().GetString("storage")
+		interval, _ := cmd.Flags().GetDuration("upload-interval")
+		disable, _ := cmd.Flags().GetBool("disable")
+		opts := cli.PITROptions{Storage: storage, UploadInterval: interval, Disable: disable}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.ConfigurePITR(cmd.Context(), args[0], opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbPITRCmd)
+	dbPITRCmd.Flags().String("storage", "", "Backup storage of the cluster the logs are uploaded to")
+	dbPITRCmd.Flags().Duration("upload-interval", 0, "Time between uploads of the binary logs of PXC clusters; the operator default is used if it is not set")
+	dbPITRCmd.Flags().Bool("disable", false, "Disable point-in-time recovery")
+}
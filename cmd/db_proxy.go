@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbProxyCmd represents the db proxy command
+var dbProxyCmd = &cobra.Command{
+	Use:   "proxy <name>",
+	Short: "Configure the proxy of a database cluster",
+	Long: `Configure the load balancer clients connect to a database cluster through:
+the proxy type (haproxy or proxysql for pxc, mongos for psmdb), the number of
+proxies, their resources and the type of the service exposing them. Settings
+which are not passed are kept.
+
+Exposing the cluster with a LoadBalancer service makes it reachable from outside
+of Kubernetes; restrict the clients with --source-ranges. Pass an empty
+--source-ranges to allow all clients again.`,
+	Example: "  " + binaryName + " db proxy mysql --type proxysql --replicas 2\n" +
+		"  " + binaryName + " db proxy mysql --expose LoadBalancer --source-ranges 203.0.113.0/24",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := cli.DatabaseProxyOptions{}
+		opts.Type, _ = cmd.Flags().GetString("type")
+		opts.Replicas, _ = cmd.Flags().GetInt32("replicas")
+		opts.CPU, _ = cmd.Flags().GetString("cpu")
+		opts.Memory, _ = cmd.Flags().GetString("memory")
+		opts.Expose, _ = cmd.Flags().GetString("expose")
+		if cmd.Flags().Changed("source-ranges") {
+			opts.SourceRanges, _ = cmd.Flags().GetStringSlice("source-ranges")
+			if opts.SourceRanges == nil {
+				opts.SourceRanges = []string{}
+			}
+		}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.ConfigureDatabaseProxy(cmd.Context(), args[0], opts, waitOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbProxyCmd)
+	addWaitFlags(dbProxyCmd)
+	dbProxyCmd.Flags().String("type", "", "Proxy type: haproxy, proxysql or mongos")
+	dbProxyCmd.Flags().Int32("replicas", 0, "Number of proxies")
+	dbProxyCmd.Flags().String("cpu", "", "CPU per proxy")
+	dbProxyCmd.Flags().String("memory", "", "Memory per proxy")
+	dbProxyCmd.Flags().String("expose", "", "Service type exposing the proxies: ClusterIP, LoadBalancer or NodePort")
+	dbProxyCmd.Flags().StringSlice("source-ranges", nil, "CIDRs of the clients allowed to connect to a LoadBalancer service")
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbReleaseCmd represents the db release command
+var dbReleaseCmd = &cobra.Command{
+	Use:   "release <name>",
+	Short: "Stop managing a database cluster without deleting it",
+	Long: `Remove the managed-by labels and annotations from a database cluster
+so it can be handed over to kubectl or GitOps tooling. The cluster keeps running.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.ReleaseDatabaseCluster(cmd.Context(), args[0]); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbReleaseCmd)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbResizeCmd represents the db resize command
+var dbResizeCmd = &cobra.Command{
+	Use:   "resize <name>",
+	Short: "Grow the volumes of a database cluster",
+	Long: `Grow the volume of every node of a database cluster without recreating it.
+The storage class of the volumes must allow volume expansion and the size must
+not exceed the maximum volume size of the cloud provider. Volumes cannot shrink.
+The command waits until all volumes report the new capacity.`,
+	Example:           "  " + binaryName + " db resize mysql --disk 50G",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		disk, _ := cmd.Flags().GetString("disk")
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.ResizeDatabaseVolume(cmd.Context(), args[0], disk, waitOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbResizeCmd)
+	addWaitFlags(dbResizeCmd)
+	dbResizeCmd.Flags().String("disk", "", "New disk size per database node")
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbRestartCmd represents the db restart command
+var dbRestartCmd = &cobra.Command{
+	Use:   "restart <name>",
+	Short: "Restart a database cluster",
+	Long: `Restart a database cluster.
+
+The full strategy makes the operator restart all pods of the cluster, so the
+cluster is unavailable for a while. The rolling strategy restarts one pod at a
+time and waits for the replacement and the cluster to be ready before moving
+on. It stops if the cluster does not recover or the operator reports an error.
+The rolling restart always waits, limited by --timeout.`,
+	Example:           "  " + binaryName + " db restart mysql --strategy rolling",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		strategy, _ := cmd.Flags().GetString("strategy")
+		opts := cli.DatabaseRestartOptions{Strategy: strategy}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.RestartDatabaseCluster(cmd.Context(), args[0], opts, waitOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbRestartCmd)
+	addWaitFlags(dbRestartCmd)
+	dbRestartCmd.Flags().String("strategy", "full", "Restart strategy: rolling or full")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbRestoreCmd represents the db restore command
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore <cluster>",
+	Short: "Restore a database cluster from a backup",
+	Long: `Restore a database cluster from a backup.
+
+The latest succeeded backup of the cluster is restored unless --backup is
+passed. With --pitr the cluster is restored to the point in time passed with
+--timestamp using the logs uploaded after the backup. The latest backup taken
+before the point in time is used then. Timestamps without a time zone are in
+UTC.`,
+	Example: "  " + binaryName + " db restore mysql --backup mysql-daily-20230510\n" +
+		"  " + binaryName + " db restore mysql --pitr --timestamp \"2023-05-10 12:30:00\"",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		backup, _ := cmd.Flags().GetString("backup")
+		pitr, _ := cmd.Flags().GetBool("pitr")
+		timestamp, _ := cmd.Flags().GetString("timestamp")
+		opts := cli.RestoreOptions{Backup: backup, PITR: pitr, Timestamp: timestamp}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.RestoreDatabaseCluster(cmd.Context(), args[0], opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbRestoreCmd)
+	dbRestoreCmd.Flags().String("backup", "", "Name of the backup to restore")
+	dbRestoreCmd.Flags().Bool("pitr", false, "Restore to the point in time passed with --timestamp")
+	dbRestoreCmd.Flags().String("timestamp", "", "Point in time to restore to, RFC 3339 or YYYY-MM-DD hh:mm:ss in UTC")
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbResumeCmd represents the db resume command
+var dbResumeCmd = &cobra.Command{
+	Use:               "resume <name>",
+	Short:             "Resume a paused database cluster",
+	Long:              `Resume a database cluster paused with db pause. The operator starts its pods again.`,
+	Example:           "  " + binaryName + " db resume mysql --timeout 20m",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.ResumeDatabaseCluster(cmd.Context(), args[0], waitOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbResumeCmd)
+	addWaitFlags(dbResumeCmd)
+}
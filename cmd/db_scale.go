@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbScaleCmd represents the db scale command
+var dbScaleCmd = &cobra.Command{
+	Use:   "scale <name>",
+	Short: "Scale a database cluster",
+	Long: `Change the number of nodes of a database cluster and the resources of
+every node. Resources which are not passed are kept. The change is refused if
+the additional resources do not fit into the resources available in the
+cluster. Disks can only grow.`,
+	Example:           "  " + binaryName + " db scale mysql --size 5 --memory 4G",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		size, _ := cmd.Flags().GetInt32("size")
+		cpu, _ := cmd.Flags().GetString("cpu")
+		memory, _ := cmd.Flags().GetString("memory")
+		disk, _ := cmd.Flags().GetString("disk")
+		opts := cli.DatabaseScaleOptions{Size: size, CPU: cpu, Memory: memory, Disk: disk}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.ScaleDatabaseCluster(cmd.Context(), args[0], opts, waitOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbScaleCmd)
+	addWaitFlags(dbScaleCmd)
+	dbScaleCmd.Flags().Int32P("size", "s", 0, "Number of database nodes")
+	dbScaleCmd.Flags().String("cpu", "", "CPU per database node")
+	dbScaleCmd.Flags().String("memory", "", "Memory per database node")
+	dbScaleCmd.Flags().String("disk", "", "Disk size per database node")
+}
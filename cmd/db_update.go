@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// dbUpdateCmd represents the db update command
+var dbUpdateCmd = &cobra.Command{
+	Use:   "update [name]",
+	Short: "Update a database cluster from a modified manifest",
+	Long: `Compare the spec of a modified DatabaseCluster manifest passed with --file
+with the live database cluster, print the changed fields and apply them after
+confirmation. The name of the cluster is taken from the manifest unless it is
+passed as an argument. Only changed fields are patched, so changes of other
+fields made meanwhile are kept.
+
+Changes which lose data or which the operators cannot apply, like changing the
+engine, shrinking volumes or downgrading the engine, require --force.
+
+The rolling strategy lets the operator replace the pods one at a time keeping
+the cluster available. The recreate strategy pauses the cluster with the new
+spec and resumes it, so all pods are recreated at once and the cluster is
+unavailable meanwhile.`,
+	Example:           "  " + binaryName + " db update mysql -f mysql.yaml --strategy recreate",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDatabaseClusters,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		strategy, _ := cmd.Flags().GetString("strategy")
+		opts := cli.DatabaseUpdateOptions{File: file, Strategy: strategy}
+		if len(args) != 0 {
+			opts.Name = args[0]
+		}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.UpdateDatabaseCluster(cmd.Context(), opts, confirmOptions(cmd), waitOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbUpdateCmd)
+	addWaitFlags(dbUpdateCmd)
+	addConfirmFlags(dbUpdateCmd)
+	dbUpdateCmd.Flags().StringP("file", "f", "", "Modified DatabaseCluster manifest")
+	dbUpdateCmd.Flags().String("strategy", "rolling", "Update strategy: rolling or recreate")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// diagnoseCmd represents the support-bundle command. It was called diagnose
+// before, which was easily confused with the doctor command.
+var diagnoseCmd = &cobra.Command{
+	Use:     "support-bundle",
+	GroupID: groupInstall,
+	Short:   "Collect operator diagnostics for support tickets",
+	Long: `Collect the status of the operator deployments, the recent logs and events
+of their pods, the phases of the cluster service versions and the conditions
+of the subscriptions into a single report to attach to support tickets.
+
+By default the report is bundled into a tar.gz archive with the logs and
+events in separate files. With --output json the report is printed to stdout
+unless --file is set. Parts which cannot be collected are listed in the
+errors of the report.`,
+	Example: "  " + binaryName + " support-bundle --file diagnostics.tar.gz",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		file, _ := cmd.Flags().GetString("file")
+		opts := cli.DiagnoseOptions{Format: output, File: file}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.Diagnose(cmd.Context(), opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diagnoseCmd)
+	diagnoseCmd.Flags().StringP("output", "o", cli.DiagnoseArchive, "Report format: tar.gz or json")
+	diagnoseCmd.Flags().StringP("file", "f", "", "File to write the report to, defaults to everest-diagnostics-<time>.tar.gz for archives")
+	addLegacyCommand(rootCmd, diagnoseCmd, "diagnose")
+}
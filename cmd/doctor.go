@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:     "doctor",
+	GroupID: groupInstall,
+	Short:   "Diagnose the installation",
+	Long: `Diagnose the installation.
+The worker nodes are summarized by region, zone and instance type to show whether
+database clusters can be spread across zones.
+
+With --k8s-upgrade the installation is checked for compatibility with a newer
+Kubernetes version before the control plane is upgraded: the upgrade path,
+APIs removed in the new version, stored versions of the operator CRDs and the
+Kubernetes versions the installed operators were tested against.`,
+	Example: "  " + binaryName + " doctor --k8s-upgrade 1.29",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.Doctor(cmd.Context(), doctorOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().String("k8s-upgrade", "", "Kubernetes version to check the installation against, e.g. 1.29")
+}
+
+// doctorOptions returns doctor options from the flags of the doctor command.
+func doctorOptions(cmd *cobra.Command) cli.DoctorOptions {
+	upgrade, _ := cmd.Flags().GetString("k8s-upgrade")
+	return cli.DoctorOptions{KubernetesUpgrade: upgrade}
+}
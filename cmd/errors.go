@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+)
+
+// exitWithError prints the error and exits with the code matching its type.
+func exitWithError(err error) {
+	fmt.Println(err)
+	os.Exit(cli.ExitCode(err))
+}
@@ -0,0 +1,7 @@
+package main
+
+import "github.com/gen1us2k/everest-provisioner/cmd"
+
+func main() {
+	cmd.Execute()
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:     "export",
+	GroupID: groupInstall,
+	Short:   "Write the installation manifests for GitOps controllers",
+	Long: `Write everything the install command would apply - OLM, the catalog source,
+the subscriptions of the operators and the monitoring stack - to a directory
+instead of applying it, so the installation can be committed to Git and
+applied by a GitOps controller.
+
+Every component is written to its own numbered directory with a kustomization.
+The components must be applied in order since later ones use the CRDs of
+earlier ones. --format selects the files referencing them:
+
+  kustomize  a kustomization including all components
+  argocd     Argo CD Applications with sync waves, requires --repo-url
+  flux       Flux Kustomizations depending on the previous component
+
+Subscriptions approve install plans automatically since GitOps controllers
+cannot approve them; pin the versions with --from-snapshot. Credentials are
+never exported. Monitoring requires an existing secret with the PMM
+credentials passed with --monitoring.pmm.credentials_secret.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := viper.BindPFlags(cmd.LocalNonPersistentFlags()); err != nil {
+			exitWithError(err)
+		}
+		opts := cli.ExportOptions{}
+		opts.Format, _ = cmd.Flags().GetString("format")
+		opts.Dir, _ = cmd.Flags().GetString("dir")
+		opts.RepoURL, _ = cmd.Flags().GetString("repo-url")
+		opts.Revision, _ = cmd.Flags().GetString("revision")
+		opts.RepoPath, _ = cmd.Flags().GetString("repo-path")
+		opts.Source, _ = cmd.Flags().GetString("flux-source")
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if snapshot, _ := cmd.Flags().GetString("from-snapshot"); snapshot != "" {
+			if err := cli.UseSnapshot(cmd.Context(), snapshot); err != nil {
+				exitWithError(err)
+			}
+		}
+		if err := cli.Export(cmd.Context(), opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().String("format", cli.ExportFormatKustomize, "Layout of the manifests: argocd, flux or kustomize")
+	exportCmd.Flags().String("dir", "everest", "Directory the manifests are written to")
+	exportCmd.Flags().String("repo-url", "", "Git repository the manifests are committed to, used by Argo CD")
+	exportCmd.Flags().String("revision", "HEAD", "Branch, tag or commit synced by Argo CD")
+	exportCmd.Flags().String("repo-path", "", "Path of the directory in the repository (default --dir)")
+	exportCmd.Flags().String("flux-source", "flux-system", "Flux GitRepository the manifests are committed to")
+	exportCmd.Flags().String("from-snapshot", "", "Pin the versions recorded in the snapshot file")
+	exportCmd.Flags().BoolP("monitoring.enabled", "m", true, "Export monitoring")
+	exportCmd.Flags().StringP("monitoring.pmm.endpoint", "", "http://127.0.0.1", "PMM endpoint URL")
+	exportCmd.Flags().StringP("monitoring.pmm.credentials_secret", "", "", "Existing secret with username and password keys used to write metrics to PMM")
+	exportCmd.Flags().BoolP("install_olm", "o", true, "Export OLM")
+	exportCmd.Flags().StringP("catalog.image", "", "", "Image of the catalog source")
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// exposeCmd represents the expose command
+var exposeCmd = &cobra.Command{
+	Use:     "expose",
+	GroupID: groupInstall,
+	Short:   "Expose Everest and PMM at host names",
+	Long: `Create an Ingress, or a Route on OpenShift, exposing the Everest backend at
+--backend.host and, if --ingress.pmm.host is set, one exposing a PMM server
+running in the cluster. TLS is terminated with the certificate of the TLS
+secret of the host, or the ACM certificate of --ingress.certificate_arn on AWS.
+
+The annotations of the Ingresses are generated for the ingress controller:
+
+  nginx    ingress-nginx, the default
+  traefik  Traefik
+  alb      the AWS load balancer controller, the default on EKS
+
+It is detected from --ingress.class or the cluster type unless it is set with
+--ingress.controller. Routes copy the certificate of the TLS secret since
+they cannot reference secrets, so run the command again after it is renewed.`,
+	Example: "  " + binaryName + " expose --backend.host everest.example.com --backend.tls_secret everest-tls\n" +
+		"  " + binaryName + " expose --ingress.pmm.host pmm.example.com --ingress.pmm.namespace pmm --ingress.certificate_arn arn:aws:acm:...",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := viper.BindPFlags(cmd.LocalNonPersistentFlags()); err != nil {
+			exitWithError(err)
+		}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.Expose(cmd.Context()); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exposeCmd)
+	exposeCmd.Flags().String("backend.host", "", "Host name the Everest backend is exposed at")
+	exposeCmd.Flags().String("backend.tls_secret", "", "Secret with the TLS certificate served for the Everest host")
+	exposeCmd.Flags().String("ingress.pmm.host", "", "Host name PMM is exposed at")
+	exposeCmd.Flags().String("ingress.pmm.tls_secret", "", "Secret with the TLS certificate served for the PMM host")
+	exposeCmd.Flags().String("ingress.pmm.namespace", "", "Namespace of the PMM server (default the installation namespace)")
+	exposeCmd.Flags().String("ingress.pmm.service", "monitoring-service", "HTTPS service of the PMM server")
+	exposeCmd.Flags().Int32("ingress.pmm.port", 443, "Port of the HTTPS service of the PMM server")
+	addIngressFlags(exposeCmd)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// addWaitFlags adds --wait and --timeout flags to a command submitting changes.
+func addWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("wait", true, "Wait for the change to be applied; use --wait=false to return immediately")
+	cmd.Flags().Duration("timeout", cli.DefaultWaitTimeout, "How long to wait for the change to be applied")
+}
+
+// waitOptions returns wait options from the flags added by addWaitFlags.
+func waitOptions(cmd *cobra.Command) cli.WaitOptions {
+	wait, _ := cmd.Flags().GetBool("wait")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	return cli.WaitOptions{Wait: wait, Timeout: timeout}
+}
+
+// addConfirmFlags adds --yes and --force flags to a destructive command.
+func addConfirmFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("yes", "y", false, "Proceed without asking for confirmation")
+	cmd.Flags().Bool("force", false, "Proceed even if the operation is unsafe")
+}
+
+// confirmOptions returns confirmation options from the flags added by addConfirmFlags.
+func confirmOptions(cmd *cobra.Command) cli.ConfirmOptions {
+	yes, _ := cmd.Flags().GetBool("yes")
+	force, _ := cmd.Flags().GetBool("force")
+	return cli.ConfirmOptions{Yes: yes, Force: force}
+}
+
+// addIngressFlags adds the flags selecting the ingress controller exposing the host names.
+func addIngressFlags(cmd *cobra.Command) {
+	cmd.Flags().String("ingress.controller", "", "Ingress controller the annotations are generated for: nginx, traefik or alb (detected by default)")
+	cmd.Flags().String("ingress.class", "", "Ingress class of the Ingresses (default class of the cluster)")
+	cmd.Flags().String("ingress.certificate_arn", "", "ACM certificate served by the AWS load balancer")
+}
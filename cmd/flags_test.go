@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitFlags(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []string
+		want cli.WaitOptions
+	}{
+		{name: "defaults", want: cli.WaitOptions{Wait: true, Timeout: cli.DefaultWaitTimeout}},
+		{name: "timeout", args: []string{"--timeout=30s"}, want: cli.WaitOptions{Wait: true, Timeout: 30 * time.Second}},
+		{name: "no wait", args: []string{"--wait=false"}, want: cli.WaitOptions{Timeout: cli.DefaultWaitTimeout}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			addWaitFlags(cmd)
+			require.NoError(t, cmd.ParseFlags(tt.args))
+			assert.Equal(t, tt.want, waitOptions(cmd))
+		})
+	}
+}
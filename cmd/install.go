@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// installCmd represents the install command
+var installCmd = &cobra.Command{
+	Use:     "install",
+	GroupID: groupInstall,
+	Short:   "Install OLM, the operators and monitoring",
+	Long: `Install OLM, the Percona operators and monitoring into the cluster.
+The installed versions are recorded in the everest-provisioner-state config map.
+Pass a snapshot printed by the snapshot command with --from-snapshot to install
+the same versions on another cluster.
+
+Installation can be re-run safely. Components which are installed already are
+skipped and a failed installation resumes from the failed step. Pass --force
+to re-apply all components. The time every phase took is printed at the end
+and shown by the status command.
+
+Avoid passing the PMM password on the command line. Set it in the config file,
+the EVEREST_MONITORING_PMM_PASSWORD environment variable or a file named by
+--monitoring.pmm.password_file, pipe it with --pmm-password-stdin or type it
+when prompted. Pass --monitoring.pmm.credentials_secret to use credentials
+kept in an existing secret instead.
+
+Pass --with-cert-manager to install cert-manager, unless it is installed already,
+and create a CA issuer. TLS certificates of database clusters created afterwards
+are issued by it instead of being generated by the operators.
+
+Pass --profile hardened to apply network policies allowing ingress to the pods
+of the installation namespace only from the same namespace and to the operator
+webhooks, to run monitoring components with restricted security contexts and
+read-only root filesystems and to require verified TLS connections to the API
+server, PMM and Vault. The PMM API key is rotated every --secret_rotation_period
+(default 720h) by the serve command.
+
+Pass --rollback to remove the resources created by the run if the installation
+fails or is interrupted with Ctrl+C. Resources which existed before are kept.
+
+Run "install backend" afterwards to deploy the Everest API server and UI.
+
+Pass --as-job to print a manifest of a Job running the installation inside the
+cluster instead, e.g. if the API server is not reachable from your workstation.
+The manifest holds the configuration including credentials:
+
+  ` + binaryName + ` install --as-job > install-job.yaml
+  kubectl apply -f install-job.yaml`,
+	Run: runInstall,
+}
+
+func runInstall(cmd *cobra.Command, args []string) {
+	// Install flags are defined on several commands so they are bound to
+	// the configuration of the command being run only.
+	if err := viper.BindPFlags(cmd.LocalNonPersistentFlags()); err != nil {
+		exitWithError(err)
+	}
+	if err := viper.BindPFlag("cert_manager", cmd.Flags().Lookup("with-cert-manager")); err != nil {
+		exitWithError(err)
+	}
+	if err := readPMMPassword(cmd); err != nil {
+		exitWithError(err)
+	}
+	c, err := config.ParseConfig()
+	if err != nil {
+		exitWithError(err)
+	}
+	if asJob, _ := cmd.Flags().GetBool("as-job"); asJob {
+		opts := cli.InstallJobOptions{}
+		opts.Name, _ = cmd.Flags().GetString("job-name")
+		opts.Namespace, _ = cmd.Flags().GetString("job-namespace")
+		opts.Image, _ = cmd.Flags().GetString("job-image")
+		opts.Snapshot, _ = cmd.Flags().GetString("from-snapshot")
+		if err := cli.RenderInstallJob(c, opts, os.Stdout); err != nil {
+			exitWithError(err)
+		}
+		return
+	}
+	cli, err := cli.New(c)
+	if err != nil {
+		exitWithError(err)
+	}
+	if snapshot, _ := cmd.Flags().GetString("from-snapshot"); snapshot != "" {
+		if err := cli.UseSnapshot(cmd.Context(), snapshot); err != nil {
+			exitWithError(err)
+		}
+	}
+	if err := cli.ProvisionCluster(cmd.Context()); err != nil {
+		exitWithError(err)
+	}
+	if err := cli.ConnectDBaaS(cmd.Context()); err != nil {
+		exitWithError(err)
+	}
+}
+
+// readPMMPassword reads the PMM password from stdin if --pmm-password-stdin is passed.
+// The password is prompted for if monitoring is enabled without credentials on a terminal.
+func readPMMPassword(cmd *cobra.Command) error {
+	fromStdin, _ := cmd.Flags().GetBool("pmm-password-stdin")
+	if !fromStdin {
+		if !viper.GetBool("monitoring.enabled") || !cli.Interactive() ||
+			viper.GetString("monitoring.pmm.password") != "" ||
+			viper.GetString("monitoring.pmm.password_file") != "" ||
+			viper.GetString("monitoring.pmm.credentials_secret") != "" ||
+			viper.GetString("monitoring.pmm.api_key") != "" {
+			return nil
+		}
+	}
+	password, err := cli.ReadPassword("PMM password: ")
+	if err != nil {
+		return err
+	}
+	viper.Set("monitoring.pmm.password", password)
+	return nil
+}
+
+// addPMMFlags adds the flags of the PMM server metrics are written to and of the
+// monitoring mode to the command.
+func addPMMFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("monitoring.pmm.endpoint", "", "http://127.0.0.1", "PMM endpoint URL")
+	cmd.Flags().StringP("monitoring.pmm.username", "", "admin", "PMM username")
+	cmd.Flags().StringP("monitoring.pmm.password", "", "", "PMM password, prompted for on a terminal if not set")
+	cmd.Flags().StringP("monitoring.pmm.password_file", "", "", "File holding the PMM password")
+	cmd.Flags().StringP("monitoring.pmm.credentials_secret", "", "", "Existing secret with username and password keys used to write metrics to PMM instead of creating an API key")
+	cmd.Flags().StringP("monitoring.pmm.api_key", "", "", "Existing PMM API key or Grafana service account token used to write metrics to PMM instead of creating one")
+	cmd.Flags().Bool("pmm-password-stdin", false, "Read the PMM password from stdin")
+	cmd.Flags().StringP("monitoring.pmm.tls.ca", "", "", "CA certificate file verifying the PMM server certificate")
+	cmd.Flags().StringP("monitoring.pmm.tls.cert", "", "", "Client certificate file presented to the PMM server")
+	cmd.Flags().StringP("monitoring.pmm.tls.key", "", "", "Key file of the client certificate")
+	cmd.Flags().BoolP("monitoring.pmm.tls.insecure_skip_verify", "", false, "Do not verify the PMM server certificate")
+	cmd.Flags().BoolP("monitoring.selective", "", false, "Scrape only database clusters with monitoring enabled")
+	cmd.Flags().BoolP("monitoring.alerts.enabled", "", false, "Install alerting rules of database clusters sending alerts to PMM")
+	cmd.Flags().StringSliceP("monitoring.alerts.alertmanager_urls", "", nil, "Alertmanagers receiving the alerts instead of PMM")
+}
+
+// addInstallFlags adds the flags of the installation to the command.
+// Flag names match the configuration keys they are bound to by runInstall.
+func addInstallFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("monitoring.enabled", "m", true, "Enable monitoring")
+	cmd.Flags().StringP("monitoring.type", "", "pmm", "Monitoring type")
+	addPMMFlags(cmd)
+	cmd.Flags().StringP("vault.address", "", "", "Store the PMM credentials in the HashiCorp Vault at this address, token taken from VAULT_TOKEN")
+	cmd.Flags().StringP("vault.auth_method", "", "", "Vault auth method: token or kubernetes (default token)")
+	cmd.Flags().StringP("vault.role", "", "", "Vault role used by the kubernetes auth method")
+	cmd.Flags().StringP("vault.mount", "", "", "Vault KV version 2 secrets engine the credentials are stored in (default secret)")
+	cmd.Flags().StringP("vault.path", "", "", "Path prefix of the credentials in the secrets engine (default everest)")
+	cmd.Flags().StringP("vault.injector_role", "", "", "Vault role the Vault Agent injected into the VM agents authenticates with (default vault.role)")
+	cmd.Flags().BoolP("enable_backup", "b", false, "Enable backups")
+	cmd.Flags().StringP("backup.irsa_role_arn", "", "", "IAM role database clusters access the backup storage with on EKS instead of static S3 keys")
+	cmd.Flags().BoolP("install_olm", "o", true, "Install OLM")
+	cmd.Flags().StringP("olm.profile", "", "", "OLM resource profile: minikube or production")
+	cmd.Flags().StringP("catalog.name", "", "", "Name of the catalog source the operators are installed from (default percona-dbaas-catalog)")
+	cmd.Flags().StringP("catalog.image", "", "", "Image of the catalog source, e.g. a mirror of the Percona catalog in a private registry")
+	cmd.Flags().StringP("catalog.namespace", "", "", "Namespace of the catalog source (default olm, openshift-marketplace on OpenShift)")
+	cmd.Flags().BoolP("cleanup_leftovers", "", false, "Remove resources left by previous installations")
+	cmd.Flags().BoolP("skip_preflight", "", false, "Skip preflight checks")
+	cmd.Flags().BoolP("parallel_install", "", false, "Install operators concurrently")
+	cmd.Flags().BoolP("force", "", false, "Re-apply components installed by previous runs")
+	cmd.Flags().Bool("rollback", false, "Remove the resources created by the run if the installation fails or is interrupted")
+	cmd.Flags().String("profile", "", "Installation profile: hardened")
+	cmd.Flags().Duration("secret_rotation_period", 0, "How often the serve command rotates the PMM API key; 0 disables the rotation unless the profile sets it")
+	cmd.Flags().Bool("with-cert-manager", false, "Install cert-manager unless it is installed and issue TLS certificates of database clusters with it")
+	cmd.Flags().String("from-snapshot", "", "Install the versions recorded in the snapshot file")
+	cmd.Flags().Bool("as-job", false, "Print a manifest of a Job running the installation in the cluster instead of installing")
+	cmd.Flags().String("job-name", "everest-provisioner", "Name of the Job and its service account with --as-job")
+	cmd.Flags().String("job-namespace", "default", "Namespace of the Job with --as-job")
+	cmd.Flags().String("job-image", "", "Provisioner image run by the Job with --as-job (default "+cli.DefaultProvisionerImage()+")")
+}
+
+func init() {
+	rootCmd.AddCommand(installCmd)
+	addInstallFlags(installCmd)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// installBackendCmd represents the install backend command
+var installBackendCmd = &cobra.Command{
+	Use:   "backend",
+	Short: "Deploy the Everest API server and UI",
+	Long: `Deploy the Everest backend, serving the API and the UI, into the installation
+namespace. A service account with the RBAC rules required by the backend is
+provisioned like by the service-account command and the backend authenticates
+with a kubeconfig of it kept in the everest-kubeconfig secret.
+
+The backend is exposed with a service of --backend.service_type. Pass
+--backend.host to expose it at a host name with an Ingress, or a Route on
+OpenShift, terminating TLS with the certificate of --backend.tls_secret.
+The annotations of the Ingress are generated for the ingress controller, see
+the expose command. The URL Everest is available at is printed once it has
+been rolled out.`,
+	Example: "  " + binaryName + " install backend --backend.service_type LoadBalancer\n" +
+		"  " + binaryName + " install backend --backend.host everest.example.com --backend.tls_secret everest-tls",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := viper.BindPFlags(cmd.LocalNonPersistentFlags()); err != nil {
+			exitWithError(err)
+		}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.DeployBackend(cmd.Context()); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	installCmd.AddCommand(installBackendCmd)
+	installBackendCmd.Flags().String("backend.image", "", "Image of the backend (default "+cli.DefaultBackendImage+")")
+	installBackendCmd.Flags().Int32("backend.replicas", 1, "Number of replicas of the backend")
+	installBackendCmd.Flags().String("backend.service_type", "ClusterIP", "Type of the service exposing the backend: ClusterIP, NodePort or LoadBalancer")
+	installBackendCmd.Flags().String("backend.host", "", "Host name the backend is exposed at with an Ingress or a Route")
+	installBackendCmd.Flags().String("backend.tls_secret", "", "Secret with the TLS certificate served for the host")
+	installBackendCmd.Flags().String("backend.service_account", "everest-service-account", "Service account the backend authenticates as")
+	addIngressFlags(installBackendCmd)
+}
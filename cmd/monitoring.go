@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// monitoringCmd represents the monitoring command
+var monitoringCmd = &cobra.Command{
+	Use:     "monitoring",
+	GroupID: groupMonitoring,
+	Short:   "Manage monitoring and monitoring instances",
+	Long: `Manage named PMM servers database clusters report to. Every instance gets
+its own VM agent writing the metrics of the database clusters assigned to it
+with "db monitoring enable <name> --instance <instance>".
+
+Install monitoring with --monitoring.selective so the clusters assigned to an
+instance are not reported to the PMM server configured on installation too.`,
+}
+
+// monitoringCreateCmd represents the monitoring create command
+var monitoringCreateCmd = &cobra.Command{
+	Use:     "create <name>",
+	Short:   "Create or update a monitoring instance",
+	Example: "  " + binaryName + " monitoring create pmm-eu --url https://pmm-eu.example.com --username admin --password secret",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := cli.MonitoringInstanceOptions{Name: args[0]}
+		opts.URL, _ = cmd.Flags().GetString("url")
+		opts.Username, _ = cmd.Flags().GetString("username")
+		opts.Password, _ = cmd.Flags().GetString("password")
+		opts.TLS.CA, _ = cmd.Flags().GetString("ca")
+		opts.TLS.Cert, _ = cmd.Flags().GetString("cert")
+		opts.TLS.Key, _ = cmd.Flags().GetString("key")
+		opts.TLS.InsecureSkipVerify, _ = cmd.Flags().GetBool("insecure-skip-verify")
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.CreateMonitoringInstance(cmd.Context(), opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// monitoringListCmd represents the monitoring list command
+var monitoringListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List monitoring instances",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		output, _ := cmd.Flags().GetString("output")
+		if err := cli.ListMonitoringInstances(cmd.Context(), output); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// monitoringDeleteCmd represents the monitoring delete command
+var monitoringDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a monitoring instance no database cluster reports to",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.DeleteMonitoringInstance(cmd.Context(), args[0]); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// monitoringStatusCmd represents the monitoring status command
+var monitoringStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check that metrics reach PMM",
+	Long: `Check that the VM agents writing metrics to PMM are ready and that PMM has
+recent samples of them. PMM is queried with the admin credentials or the API
+key of the configuration; without them only the agents are checked.
+
+The command fails if metrics of an agent do not reach PMM.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		output, _ := cmd.Flags().GetString("output")
+		if err := cli.MonitoringStatus(cmd.Context(), output); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// monitoringEnableCmd represents the monitoring enable command
+var monitoringEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Provision monitoring with the configured PMM server",
+	Long: `Provision the VM agent writing metrics to PMM and the monitoring stack, e.g.
+after "monitoring disable" or if the cluster was installed without monitoring.
+The PMM server and its credentials are configured like on installation.
+
+Monitoring which is provisioned already is kept unless --force is passed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := viper.BindPFlags(cmd.LocalNonPersistentFlags()); err != nil {
+			exitWithError(err)
+		}
+		viper.Set("monitoring.enabled", true)
+		if err := readPMMPassword(cmd); err != nil {
+			exitWithError(err)
+		}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.EnableMonitoring(cmd.Context()); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// monitoringDisableCmd represents the monitoring disable command
+var monitoringDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Remove the VM agents, their credentials and the monitoring stack",
+	Long: `Stop sending metrics to PMM. The VM agents, the secrets with their
+credentials and the monitoring stack are removed. PMM API keys created by
+the provisioner are revoked if the PMM admin credentials are configured.
+Secrets with credentials supplied with monitoring.pmm.credentials_secret are kept.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := cli.ConfirmOptions{}
+		opts.Yes, _ = cmd.Flags().GetBool("yes")
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.DisableMonitoring(cmd.Context(), opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// monitoringRotateCredentialsCmd represents the monitoring rotate-credentials command
+var monitoringRotateCredentialsCmd = &cobra.Command{
+	Use:   "rotate-credentials",
+	Short: "Replace the PMM API key metrics are written with",
+	Long: `Create a new PMM API key, write metrics with it and revoke the previous one,
+whether its rotation is due or not. The VM agents are replaced with agents
+using a secret with the new key. The PMM admin credentials are required.
+
+Credentials of a secret or an API key supplied in the configuration are not
+created by the provisioner and cannot be rotated.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := viper.BindPFlags(cmd.LocalNonPersistentFlags()); err != nil {
+			exitWithError(err)
+		}
+		viper.Set("monitoring.enabled", true)
+		if err := readPMMPassword(cmd); err != nil {
+			exitWithError(err)
+		}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.RotateMonitoringCredentialsNow(cmd.Context()); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(monitoringCmd)
+	monitoringCmd.AddCommand(monitoringCreateCmd)
+	monitoringCmd.AddCommand(monitoringListCmd)
+	monitoringCmd.AddCommand(monitoringDeleteCmd)
+	monitoringCmd.AddCommand(monitoringStatusCmd)
+	monitoringCmd.AddCommand(monitoringEnableCmd)
+	monitoringCmd.AddCommand(monitoringDisableCmd)
+	monitoringCmd.AddCommand(monitoringRotateCredentialsCmd)
+
+	monitoringCreateCmd.Flags().String("url", "", "PMM server URL")
+	monitoringCreateCmd.Flags().String("username", "admin", "PMM username")
+	monitoringCreateCmd.Flags().String("password", "", "PMM password")
+	monitoringCreateCmd.Flags().String("ca", "", "CA certificate file verifying the PMM server certificate")
+	monitoringCreateCmd.Flags().String("cert", "", "Client certificate file presented to the PMM server")
+	monitoringCreateCmd.Flags().String("key", "", "Key file of the client certificate")
+	monitoringCreateCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify the PMM server certificate")
+	monitoringListCmd.Flags().StringP("output", "o", cli.OutputText, "Output format: text or json")
+	monitoringStatusCmd.Flags().StringP("output", "o", cli.OutputText, "Output format: text or json")
+	addPMMFlags(monitoringEnableCmd)
+	monitoringEnableCmd.Flags().Bool("force", false, "Provision monitoring even if it is provisioned already")
+	monitoringEnableCmd.Flags().Duration("secret_rotation_period", 0, "How often the serve command rotates the PMM API key; 0 disables the rotation unless the profile sets it")
+	monitoringDisableCmd.Flags().BoolP("yes", "y", false, "Proceed without asking for confirmation")
+	addPMMFlags(monitoringRotateCredentialsCmd)
+}
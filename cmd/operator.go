@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// operatorCmd represents the operator command
+var operatorCmd = &cobra.Command{
+	Use:     "operator",
+	GroupID: groupOperator,
+	Aliases: []string{"operators"},
+	Short:   "Manage operators installed by the provisioner",
+}
+
+func init() {
+	rootCmd.AddCommand(operatorCmd)
+}
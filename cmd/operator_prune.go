@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// operatorPruneCmd represents the operator prune command
+var operatorPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove leftovers of operator upgrades",
+	Long: `Remove cluster service versions superseded by operator upgrades and failed
+install plans. Cluster service versions and install plans still referenced by
+subscriptions or being replaced by OLM are kept. Resources to be deleted are
+shown and must be confirmed unless --yes is passed.
+
+Upgrades run with --wait clean up after themselves.`,
+	Example: "  " + binaryName + " operators prune --yes",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := confirmOptions(cmd)
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.PruneOperators(cmd.Context(), opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	operatorCmd.AddCommand(operatorPruneCmd)
+	addConfirmFlags(operatorPruneCmd)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// operatorScheduleCmd represents the operator schedule command
+var operatorScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Apply the configured node selector, tolerations and affinity to installed operators",
+	Long: `Apply the node selector, tolerations and affinity of the scheduling section of
+the configuration to the subscriptions of the installed operators. OLM rolls out
+the operator deployments with the new constraints.
+
+Installations pin the VM agents, kube-state-metrics and the operators to the
+configured nodes on their own; use this command after changing the scheduling
+of an existing installation.`,
+	Example: "  " + binaryName + " operators schedule",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.ScheduleOperators(cmd.Context()); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	operatorCmd.AddCommand(operatorScheduleCmd)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// operatorUninstallCmd represents the operator uninstall command
+var operatorUninstallCmd = &cobra.Command{
+	Use:   "uninstall <name>",
+	Short: "Uninstall an operator",
+	Long: `Remove the subscription and the cluster service version of an operator
+together with its CRDs and custom resources. Resources to be deleted are shown
+and must be confirmed unless --yes is passed. Deleting existing custom resources
+requires --force.
+
+Pass --keep-data to keep the CRDs and custom resources so running databases
+are not deleted. They are managed again once the operator is reinstalled.`,
+	Example: "  " + binaryName + " operator uninstall percona-server-mongodb-operator --keep-data",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		keepData, _ := cmd.Flags().GetBool("keep-data")
+		opts := cli.OperatorUninstallOptions{ConfirmOptions: confirmOptions(cmd), KeepData: keepData}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.UninstallOperator(cmd.Context(), args[0], opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	operatorCmd.AddCommand(operatorUninstallCmd)
+	addConfirmFlags(operatorUninstallCmd)
+	operatorUninstallCmd.Flags().Bool("keep-data", false, "Keep CRDs and custom resources so running databases are not deleted")
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// operatorVersionsCmd represents the operator versions command
+var operatorVersionsCmd = &cobra.Command{
+	Use:   "versions [name]",
+	Short: "Show installed and available versions of the operators",
+	Long: `Show the installed version of the operators and the versions available per
+channel in the catalog they are installed from. The versions are read from the
+package manifests served by OLM, so they are the versions the cluster can
+actually upgrade to. Operators which are not installed are looked up in the
+configured catalog.`,
+	Example: "  " + binaryName + " operator versions\n" +
+		"  " + binaryName + " operator versions percona-xtradb-cluster-operator -o json",
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var name string
+		if len(args) != 0 {
+			name = args[0]
+		}
+		output, _ := cmd.Flags().GetString("output")
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.OperatorVersions(cmd.Context(), name, output); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	operatorCmd.AddCommand(operatorVersionsCmd)
+	operatorVersionsCmd.Flags().StringP("output", "o", cli.OutputText, "Output format: text or json")
+}
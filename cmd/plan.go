@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:     "plan",
+	GroupID: groupInstall,
+	Short:   "Show what the install command would change",
+	Long: `Compare the configuration with the cluster and print the changes the install
+command would make without applying them: missing catalog source, operators and
+monitoring, channels and versions differing from the configuration or the
+snapshot passed with --from-snapshot, pending install plans, unhealthy cluster
+service versions and operator deployments, and VM agents sending metrics to
+another PMM endpoint.
+
+Use --output json to review the plan in CI pipelines.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := viper.BindPFlags(cmd.LocalNonPersistentFlags()); err != nil {
+			exitWithError(err)
+		}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if snapshot, _ := cmd.Flags().GetString("from-snapshot"); snapshot != "" {
+			if err := cli.UseSnapshot(cmd.Context(), snapshot); err != nil {
+				exitWithError(err)
+			}
+		}
+		output, _ := cmd.Flags().GetString("output")
+		if err := cli.Plan(cmd.Context(), output); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.Flags().StringP("output", "o", cli.OutputText, "Output format: text or json")
+	planCmd.Flags().BoolP("monitoring.enabled", "m", true, "Compare monitoring")
+	planCmd.Flags().StringP("monitoring.pmm.endpoint", "", "http://127.0.0.1", "PMM endpoint URL")
+	planCmd.Flags().StringP("catalog.name", "", "", "Name of the catalog source the operators are installed from (default percona-dbaas-catalog)")
+	planCmd.Flags().StringP("catalog.image", "", "", "Image of the catalog source")
+	planCmd.Flags().StringP("catalog.namespace", "", "", "Namespace of the catalog source (default olm, openshift-marketplace on OpenShift)")
+	planCmd.Flags().String("from-snapshot", "", "Compare with the versions recorded in the snapshot file")
+}
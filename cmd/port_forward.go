@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// portForwardCmd represents the port-forward command
+var portForwardCmd = &cobra.Command{
+	Use:     "port-forward <service>",
+	GroupID: groupDatabase,
+	Short:   "Forward a local port to a service",
+	Long: `Forward a local port to a service, e.g. a database cluster or PMM running
+in the cluster, without kubectl.
+
+A ready pod is selected with the label selector of the service. If the
+connection to the pod is lost, e.g. because it was restarted, the same local
+port is forwarded to another ready pod of the service. The local port is bound
+to 127.0.0.1 only. Press Ctrl+C to stop.`,
+	Example: "  " + binaryName + " port-forward mysql-haproxy --port 3306 --local-port 3306\n" +
+		"  " + binaryName + " port-forward monitoring-service -n pmm --port 443",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ns, _ := cmd.Flags().GetString("namespace")
+		port, _ := cmd.Flags().GetInt("port")
+		localPort, _ := cmd.Flags().GetInt("local-port")
+		opts := cli.PortForwardOptions{Namespace: ns, Service: args[0], Port: port, LocalPort: localPort}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.PortForward(cmd.Context(), opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(portForwardCmd)
+	portForwardCmd.Flags().StringP("namespace", "n", "", "Namespace of the service; defaults to the installation namespace")
+	portForwardCmd.Flags().IntP("port", "p", 0, "Port of the service; can be omitted if the service has one port")
+	portForwardCmd.Flags().IntP("local-port", "l", 0, "Local port; a random port is used if it is not set")
+}
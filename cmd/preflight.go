@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// preflightCmd represents the preflight command
+var preflightCmd = &cobra.Command{
+	Use:     "preflight",
+	GroupID: groupInstall,
+	Short:   "Check whether the cluster is ready for installation",
+	Long: `Check the Kubernetes server version, available storage classes, node
+resources, RBAC permissions of the current user and existing installations
+of OLM and operators. A pass/fail report with remediation hints is printed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.Preflight(cmd.Context()); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(preflightCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// rbacCmd represents the rbac command
+var rbacCmd = &cobra.Command{
+	Use:     "rbac",
+	GroupID: groupOperator,
+	Short:   "Manage access of teams to database clusters",
+}
+
+// rbacGenerateCmd represents the rbac generate command
+var rbacGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate RBAC rules granting access to database clusters",
+	Long: `Print a ClusterRole with the rules of a persona and RoleBindings of users,
+groups and service accounts to it in the namespaces, or apply them with --apply.
+Since the role is bound in namespaces it grants access to the database
+clusters in them only:
+
+  viewer    reads database clusters, backups, restores, their pods and logs
+  operator  also changes database clusters, creates backups and restores and
+            forwards ports to the pods
+  admin     also creates and deletes database clusters, executes commands in
+            their pods and reads their credentials
+
+Only the ClusterRole is generated if no subjects are given.`,
+	Example: "  " + binaryName + " rbac generate --role viewer --group developers --namespace team-a\n" +
+		"  " + binaryName + " rbac generate --role operator --service-account ci:deployer --namespace team-a --apply",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := cli.RBACOptions{}
+		opts.Role, _ = cmd.Flags().GetString("role")
+		opts.Users, _ = cmd.Flags().GetStringSlice("user")
+		opts.Groups, _ = cmd.Flags().GetStringSlice("group")
+		opts.ServiceAccounts, _ = cmd.Flags().GetStringSlice("service-account")
+		opts.Namespaces, _ = cmd.Flags().GetStringSlice("namespace")
+		if apply, _ := cmd.Flags().GetBool("apply"); !apply {
+			if err := cli.RenderRBAC(opts, os.Stdout); err != nil {
+				exitWithError(err)
+			}
+			return
+		}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.ApplyRBAC(cmd.Context(), opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rbacCmd)
+	rbacCmd.AddCommand(rbacGenerateCmd)
+	rbacGenerateCmd.Flags().String("role", "viewer", "Persona granted: viewer, operator or admin")
+	rbacGenerateCmd.Flags().StringSlice("user", nil, "Users bound to the role")
+	rbacGenerateCmd.Flags().StringSlice("group", nil, "Groups bound to the role")
+	rbacGenerateCmd.Flags().StringSlice("service-account", nil, "Service accounts bound to the role as namespace:name")
+	rbacGenerateCmd.Flags().StringSliceP("namespace", "n", nil, "Namespaces the role is granted in (default the installation namespace)")
+	rbacGenerateCmd.Flags().Bool("apply", false, "Apply the objects instead of printing them")
+}
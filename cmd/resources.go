@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// resourcesCmd represents the resources command
+var resourcesCmd = &cobra.Command{
+	Use:     "resources",
+	GroupID: groupInstall,
+	Short:   "Show resources of the worker nodes",
+	Long: `Show allocatable, used and available CPU, memory and disk of the
+worker nodes. Pass --size to check whether a database cluster of the
+given size fits into the available resources.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.Resources(cmd.Context(), resourcesOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func resourcesOptions(cmd *cobra.Command) cli.ResourcesOptions {
+	output, _ := cmd.Flags().GetString("output")
+	size, _ := cmd.Flags().GetInt32("size")
+	cpu, _ := cmd.Flags().GetString("cpu")
+	memory, _ := cmd.Flags().GetString("memory")
+	disk, _ := cmd.Flags().GetString("disk")
+	return cli.ResourcesOptions{
+		Output: output,
+		Size:   size,
+		CPU:    cpu,
+		Memory: memory,
+		Disk:   disk,
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(resourcesCmd)
+	resourcesCmd.Flags().StringP("output", "o", cli.OutputText, "Output format: text or json")
+	resourcesCmd.Flags().Int32P("size", "s", 0, "Number of nodes of a planned database cluster to check")
+	resourcesCmd.Flags().String("cpu", "1", "CPU per node of the planned database cluster")
+	resourcesCmd.Flags().String("memory", "2G", "Memory per node of the planned database cluster")
+	resourcesCmd.Flags().String("disk", "25G", "Disk size per node of the planned database cluster")
+}
@@ -1,83 +1,105 @@
-/*
-Copyright © 2023 NAME HERE <EMAIL ADDRESS>
-*/
 package cmd
 
 import (
-	"fmt"
+	"context"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/gen1us2k/everest-provisioner/config"
-	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/gen1us2k/everest-provisioner/pkg/httpclient"
+	"github.com/gen1us2k/everest-provisioner/pkg/logger"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// IDs of the groups the commands are listed in by the help of the root command.
+const (
+	groupInstall    = "install"
+	groupDatabase   = "db"
+	groupMonitoring = "monitoring"
+	groupOperator   = "operator"
+)
+
+var cfgFile string
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "everest-provisioner",
-	Short: "A brief description of your application",
-	Long: `A longer description that spans multiple lines and likely contains
-examples and usage of using your application. For example:
+	Use:   binaryName,
+	Short: "Install Everest and manage its database clusters",
+	Long: `Install OLM, the Percona operators and monitoring into a Kubernetes
+cluster and manage database clusters running there.
+
+Running the command without a subcommand installs everything like the install
+command. Shell completion, including the names of database clusters, is
+generated by the completion command, see "` + binaryName + ` completion --help".
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-	// Uncomment the following line if your bare application
-	// has an action associated with it:
-	Run: func(cmd *cobra.Command, args []string) {
-		c, err := config.ParseConfig()
-		if err != nil {
-			os.Exit(1)
-		}
-		cli, err := cli.New(c)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
-		if err := cli.ProvisionCluster(); err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
-		if err := cli.ConnectDBaaS(); err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
-	},
+The CLI used to be called ` + legacyBinaryName + `; the old name keeps working.`,
+	Run: runRootInstall,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Commands are run with a context canceled on SIGINT and SIGTERM.
 func Execute() {
-	err := rootCmd.Execute()
+	setBinaryName(os.Args[0])
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func init() {
-	// Here you will define your flags and configuration settings.
-	// Cobra supports persistent flags, which, if defined here,
-	// will be global for your application.
+	rootCmd.AddGroup(
+		&cobra.Group{ID: groupInstall, Title: "Installation Commands:"},
+		&cobra.Group{ID: groupDatabase, Title: "Database Commands:"},
+		&cobra.Group{ID: groupMonitoring, Title: "Monitoring Commands:"},
+		&cobra.Group{ID: groupOperator, Title: "Operator and Service Commands:"},
+	)
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.everest-provisioner.yaml)")
+	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $"+config.ConfigEnv+" or $HOME/.everest/config.yaml)")
 
-	// Cobra also supports local flags, which will only run
-	// when this action is called directly.
-	rootCmd.Flags().BoolP("monitoring.enabled", "m", true, "Enable monitoring")
-	viper.BindPFlag("monitoring.enabled", rootCmd.Flags().Lookup("monitoring.enabled"))
-	rootCmd.Flags().StringP("monitoring.type", "", "pmm", "Monitoring type")
-	viper.BindPFlag("monitoring.type", rootCmd.Flags().Lookup("monitoring.type"))
-	rootCmd.Flags().StringP("monitoring.pmm.endpoint", "", "http://127.0.0.1", "PMM endpoint URL")
-	viper.BindPFlag("monitoring.pmm.endpoint", rootCmd.Flags().Lookup("monitoring.pmm.endpoint"))
-	rootCmd.Flags().StringP("monitoring.pmm.username", "", "admin", "PMM username")
-	viper.BindPFlag("monitoring.pmm.username", rootCmd.Flags().Lookup("monitoring.pmm.username"))
-	rootCmd.Flags().StringP("monitoring.pmm.password", "", "password", "PMM password")
-	viper.BindPFlag("monitoring.pmm.password", rootCmd.Flags().Lookup("monitoring.pmm.password"))
-	rootCmd.Flags().BoolP("enable_backup", "b", false, "Enable backups")
-	viper.BindPFlag("enable_backup", rootCmd.Flags().Lookup("enable_backup"))
-	rootCmd.Flags().BoolP("install_olm", "o", true, "Install OLM")
-	viper.BindPFlag("install_olm", rootCmd.Flags().Lookup("install_olm"))
-	rootCmd.Flags().StringP("kubeconfig", "k", "~/.kube/config", "specify kubeconfig")
-	viper.BindPFlag("kubeconfig", rootCmd.Flags().Lookup("kubeconfig"))
+	addInstallFlags(rootCmd)
+	rootCmd.PersistentFlags().StringP("kubeconfig", "k", "~/.kube/config", "specify kubeconfig")
+	viper.BindPFlag("kubeconfig", rootCmd.PersistentFlags().Lookup("kubeconfig"))
+	rootCmd.PersistentFlags().StringP("kubeconfig-data", "", "", "base64 encoded kubeconfig used instead of --kubeconfig; - reads a plain kubeconfig from stdin")
+	viper.BindPFlag("kubeconfig_data", rootCmd.PersistentFlags().Lookup("kubeconfig-data"))
+	rootCmd.PersistentFlags().StringP("kube-context", "", "", "kubeconfig context to use instead of the current one")
+	viper.BindPFlag("kube_context", rootCmd.PersistentFlags().Lookup("kube-context"))
+	rootCmd.PersistentFlags().BoolP("in-cluster", "", false, "authenticate with the service account of the pod instead of a kubeconfig")
+	viper.BindPFlag("in_cluster", rootCmd.PersistentFlags().Lookup("in-cluster"))
+	rootCmd.PersistentFlags().StringP("cluster", "", "", "name of a cluster from the clusters registry of the config file")
+	viper.BindPFlag("cluster", rootCmd.PersistentFlags().Lookup("cluster"))
+	rootCmd.PersistentFlags().StringToStringP("global.labels", "", nil, "labels added to every created object")
+	viper.BindPFlag("global.labels", rootCmd.PersistentFlags().Lookup("global.labels"))
+	rootCmd.PersistentFlags().StringToStringP("global.annotations", "", nil, "annotations added to every created object")
+	viper.BindPFlag("global.annotations", rootCmd.PersistentFlags().Lookup("global.annotations"))
+	rootCmd.PersistentFlags().BoolP("no-audit", "", false, "do not record the changes made to the cluster in the audit log, $HOME/.everest/audit.log by default")
+	viper.BindPFlag("audit.disabled", rootCmd.PersistentFlags().Lookup("no-audit"))
+	rootCmd.PersistentFlags().BoolP("force-unlock", "", false, "take over the lock of the cluster held by another run of a mutating command even if that run is still going; it stops once it notices")
+	viper.BindPFlag("force_unlock", rootCmd.PersistentFlags().Lookup("force-unlock"))
+	rootCmd.PersistentFlags().BoolP("disable-retries", "", false, "do not retry API requests failed with transient errors such as timeouts and throttling")
+	viper.BindPFlag("disable_retries", rootCmd.PersistentFlags().Lookup("disable-retries"))
+	rootCmd.PersistentFlags().IntP("http.retries", "", httpclient.DefaultRetries, "how often idempotent outbound HTTP requests failed with transient errors are repeated, 0 sends them once")
+	viper.BindPFlag("http.retries", rootCmd.PersistentFlags().Lookup("http.retries"))
+	rootCmd.PersistentFlags().StringP("cluster-domain", "", "", "DNS domain of the cluster used in service names, detected from the kubelet configuration by default")
+	viper.BindPFlag("cluster_domain", rootCmd.PersistentFlags().Lookup("cluster-domain"))
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "log debug messages")
+	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+	rootCmd.PersistentFlags().StringP("log-format", "", logger.FormatText, "log format, one of: "+strings.Join(logger.Formats, ", "))
+	viper.BindPFlag("log_format", rootCmd.PersistentFlags().Lookup("log-format"))
+}
+
+// initConfig reads the config file and the environment variables.
+func initConfig() {
+	if err := config.Load(cfgFile); err != nil {
+		exitWithError(err)
+	}
+	if err := logger.Configure(viper.GetBool("verbose"), viper.GetString("log_format")); err != nil {
+		exitWithError(err)
+	}
 }
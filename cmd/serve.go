@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:     "serve",
+	GroupID: groupOperator,
+	Short:   "Serve the database cluster API",
+	Long: `Serve an HTTP API to list, create, patch and delete database clusters.
+Status changes of a database cluster are pushed as server-sent events from
+/v1/database-clusters/<name>/events so frontends do not need to poll.
+Prometheus metrics of the provisioner are served from /metrics.
+
+The API listens on the loopback interface by default. Requests creating,
+patching or deleting database clusters must carry the token read from
+--token-file in an "Authorization: Bearer <token>" header; without a token
+file they are refused.
+
+PMM credentials installed with a rotation period, e.g. by the hardened profile,
+are replaced with a new API key once they are due. The PMM endpoint and admin
+credentials are read from the configuration.
+
+A maintenance digest listing pending operator upgrades, failed backups,
+database clusters in error and capacity warnings is sent periodically to the
+webhook and email recipients configured under digest, e.g.
+
+  digest:
+    webhook_url: https://hooks.example.com/everest
+    email:
+      smtp_address: smtp.example.com:587
+      from: everest@example.com
+      to: [dba@example.com]`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		opts, err := serveOptions(cmd)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.Serve(cmd.Context(), opts); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().String("address", "127.0.0.1:8090", "Address to listen on")
+	serveCmd.Flags().String("token-file", "", "File with the bearer token required by requests changing database clusters")
+	serveCmd.Flags().Duration("cache-resync", 10*time.Minute, "Resync period of the informer cache; 0 disables the cache")
+	serveCmd.Flags().Duration("expiry-interval", time.Minute, "How often expired database clusters are deleted; 0 keeps them")
+	serveCmd.Flags().Duration("rotation-interval", time.Hour, "How often PMM credentials due for rotation are looked for; 0 disables the rotation")
+	serveCmd.Flags().Duration("digest-interval", 7*24*time.Hour, "How often the maintenance digest is sent; 0 disables the digest")
+}
+
+// serveOptions returns server options from the flags of the serve command.
+func serveOptions(cmd *cobra.Command) (cli.ServeOptions, error) {
+	address, _ := cmd.Flags().GetString("address")
+	tokenFile, _ := cmd.Flags().GetString("token-file")
+	var token string
+	if tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return cli.ServeOptions{}, err
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	resync, _ := cmd.Flags().GetDuration("cache-resync")
+	expiry, _ := cmd.Flags().GetDuration("expiry-interval")
+	rotation, _ := cmd.Flags().GetDuration("rotation-interval")
+	digest, _ := cmd.Flags().GetDuration("digest-interval")
+	return cli.ServeOptions{
+		Address:          address,
+		Token:            token,
+		CacheResync:      resync,
+		ExpiryInterval:   expiry,
+		RotationInterval: rotation,
+		DigestInterval:   digest,
+	}, nil
+}
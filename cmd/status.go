@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:     "status",
+	GroupID: groupInstall,
+	Short:   "Show the status of the installation",
+	Long: `Show subscriptions, installed cluster service versions, pending
+install plans and catalog health of the installed operators.
+Use --output json to get machine readable output for monitoring systems.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		output, _ := cmd.Flags().GetString("output")
+		if err := cli.Status(cmd.Context(), output); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().StringP("output", "o", cli.OutputText, "Output format: text or json")
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// storageClassesCmd represents the storage-classes command
+var storageClassesCmd = &cobra.Command{
+	Use:     "storage-classes",
+	GroupID: groupDatabase,
+	Short:   "List storage classes database volumes can be provisioned with",
+	Long: `List the storage classes of the cluster with their provisioner, whether they
+are the default storage class and allow volume expansion. Access modes are
+shown for the provisioners of the cloud providers. Pass a storage class to
+db create with --storage-class.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.StorageClasses(cmd.Context(), output); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(storageClassesCmd)
+	storageClassesCmd.Flags().StringP("output", "o", cli.OutputText, "Output format: text or json")
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// tokenCmd represents the service-account command. It was called token before,
+// although it prints a kubeconfig rather than a bare token.
+var tokenCmd = &cobra.Command{
+	Use:     "service-account",
+	GroupID: groupOperator,
+	Short:   "Generate a kubeconfig for the Everest backend",
+	Long: `Create a service account with least-privilege RBAC rules required by
+the Everest backend and print a kubeconfig authenticating as it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		name, _ := cmd.Flags().GetString("name")
+		if err := cli.Token(cmd.Context(), name); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.Flags().String("name", "everest-service-account", "Name of the service account")
+	addLegacyCommand(rootCmd, tokenCmd, "token")
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// uninstallCmd represents the uninstall command
+var uninstallCmd = &cobra.Command{
+	Use:     "uninstall",
+	GroupID: groupInstall,
+	Short:   "Uninstall operators",
+	Long: `Remove subscriptions, cluster service versions, the operator group and
+CRDs of the operators installed by the provisioner. Resources to be deleted
+are shown and must be confirmed unless --yes is passed. Uninstalling while
+database clusters exist requires --force.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.Uninstall(cmd.Context(), confirmOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uninstallCmd)
+	addConfirmFlags(uninstallCmd)
+}
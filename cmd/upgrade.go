@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// upgradeCmd represents the upgrade command
+var upgradeCmd = &cobra.Command{
+	Use:     "upgrade",
+	GroupID: groupInstall,
+	Short:   "Upgrade installed operators",
+	Long: `Upgrade operators installed by the provisioner to the latest versions
+available in their channels. kube-state-metrics is re-applied if the image
+set by monitoring.kube_state_metrics in the configuration differs from the
+deployed one. Pending upgrades are shown and must be confirmed unless --yes
+is passed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		yes, _ := cmd.Flags().GetBool("yes")
+		if err := cli.UpgradeOperators(cmd.Context(), yes, waitOptions(cmd)); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+	upgradeCmd.Flags().BoolP("yes", "y", false, "Upgrade without asking for confirmation")
+	addWaitFlags(upgradeCmd)
+}
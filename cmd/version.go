@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the versions of the CLI, Kubernetes and the operators",
+	Long: `Print the version of the CLI together with the versions of Kubernetes
+and the Percona operators running in the cluster. Include the output in bug reports.
+Pass --client to skip connecting to the cluster.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		if client, _ := cmd.Flags().GetBool("client"); client {
+			if err := cli.PrintVersion(output); err != nil {
+				exitWithError(err)
+			}
+			return
+		}
+		c, err := config.ParseConfig()
+		if err != nil {
+			exitWithError(err)
+		}
+		cli, err := cli.New(c)
+		if err != nil {
+			exitWithError(err)
+		}
+		if err := cli.Version(cmd.Context(), output); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().StringP("output", "o", cli.OutputText, "Output format: text or json")
+	versionCmd.Flags().Bool("client", false, "Print only the version of the CLI")
+}
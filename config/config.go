@@ -1,32 +1,567 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
 
-const MonitoringTypePMM = "pmm"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	MonitoringTypePMM = "pmm"
+
+	// VaultAuthToken and VaultAuthKubernetes are the supported Vault auth methods.
+	VaultAuthToken      = "token"
+	VaultAuthKubernetes = "kubernetes"
+
+	// ProfileHardened enables network policies, restricted security contexts, TLS verification
+	// of all endpoints and the rotation of the PMM credentials.
+	ProfileHardened = "hardened"
+	// DefaultSecretRotationPeriod is how often the hardened profile rotates the PMM credentials.
+	DefaultSecretRotationPeriod = 30 * 24 * time.Hour
+
+	// PlacementSpreadAuto spreads the pods of database clusters over zones if every pod fits
+	// into its own zone and over nodes otherwise. PlacementSpreadNone leaves the placement
+	// to the operators.
+	PlacementSpreadAuto     = "auto"
+	PlacementSpreadZone     = "zone"
+	PlacementSpreadHostname = "hostname"
+	PlacementSpreadNone     = "none"
+
+	// EnvPrefix is the prefix of environment variables overriding the configuration,
+	// e.g. EVEREST_MONITORING_PMM_ENDPOINT overrides monitoring.pmm.endpoint.
+	EnvPrefix = "EVEREST"
+	// ConfigEnv names the configuration file if --config is not passed.
+	ConfigEnv = EnvPrefix + "_CONFIG"
+
+	configDir  = ".everest"
+	configName = "config"
+	// auditLogName is the name of the audit log in the configuration directory.
+	auditLogName = "audit.log"
+)
 
 type (
 	MonitoringType string
 	AppConfig      struct {
-		Monitoring   MonitoringConfig `mapstructure:"monitoring"`
-		Kubeconfig   string           `mapstructure:"kubeconfig"`
-		EnableBackup bool             `mapstructure:"enable_backup"`
-		InstallOLM   bool             `mapstructure:"install_olm"`
+		Monitoring       MonitoringConfig         `mapstructure:"monitoring"`
+		Kubeconfig       string                   `mapstructure:"kubeconfig"`
+		KubeconfigData   string                   `mapstructure:"kubeconfig_data"`
+		KubeContext      string                   `mapstructure:"kube_context"`
+		InCluster        bool                     `mapstructure:"in_cluster"`
+		Cluster          string                   `mapstructure:"cluster"`
+		Clusters         map[string]ClusterConfig `mapstructure:"clusters"`
+		EnableBackup     bool                     `mapstructure:"enable_backup"`
+		InstallOLM       bool                     `mapstructure:"install_olm"`
+		CleanupLeftovers bool                     `mapstructure:"cleanup_leftovers"`
+		SkipPreflight    bool                     `mapstructure:"skip_preflight"`
+		ParallelInstall  bool                     `mapstructure:"parallel_install"`
+		Force            bool                     `mapstructure:"force"`
+		Global           GlobalConfig             `mapstructure:"global"`
+		OLM              OLMConfig                `mapstructure:"olm"`
+		Catalog          CatalogConfig            `mapstructure:"catalog"`
+		// OperatorChannels overrides the subscription channels of the operators by operator name.
+		OperatorChannels map[string]string `mapstructure:"operator_channels"`
+		// ImagePullSecrets hold credentials of private registries the images are pulled from.
+		ImagePullSecrets []ImagePullSecretConfig `mapstructure:"image_pull_secrets"`
+		// DisableRetries stops repeating API requests failed with transient errors.
+		DisableRetries bool `mapstructure:"disable_retries"`
+		// Verbose enables debug logging.
+		Verbose bool `mapstructure:"verbose"`
+		// LogFormat is either text or json.
+		LogFormat string `mapstructure:"log_format"`
+		// Vault stores the PMM credentials and generated database credentials in HashiCorp Vault
+		// if its address is set.
+		Vault VaultConfig `mapstructure:"vault"`
+		// CertManager installs cert-manager and issues the TLS certificates of database clusters with it.
+		CertManager bool `mapstructure:"cert_manager"`
+		// ClusterDomain is the DNS domain of the cluster. It is detected if it is not set.
+		ClusterDomain string `mapstructure:"cluster_domain"`
+		// Profile applies a set of defaults to the installation. Only hardened is supported.
+		Profile string `mapstructure:"profile"`
+		// SecretRotationPeriod is how often the PMM credentials are replaced with a new API key
+		// by the serve command. It defaults to DefaultSecretRotationPeriod with the hardened profile.
+		SecretRotationPeriod time.Duration `mapstructure:"secret_rotation_period"`
+		// Digest delivers the periodic maintenance digest of the serve command.
+		Digest DigestConfig `mapstructure:"digest"`
+		// Rollback removes the resources created by an installation which failed or was interrupted.
+		Rollback bool `mapstructure:"rollback"`
+		// HTTP configures the outbound requests to PMM, Vault and the digest webhook.
+		HTTP HTTPConfig `mapstructure:"http"`
+		// Scheduling pins the VM agents, kube-state-metrics and the operators to nodes.
+		Scheduling SchedulingConfig `mapstructure:"scheduling"`
+		// Backup configures access of database clusters to the backup storage.
+		Backup BackupConfig `mapstructure:"backup"`
+		// Placement spreads the pods of created database clusters over failure domains.
+		Placement PlacementConfig `mapstructure:"placement"`
+		// Backend configures the Everest backend deployed by install backend.
+		Backend BackendConfig `mapstructure:"backend"`
+		// Ingress configures the Ingresses, or Routes on OpenShift, exposing Everest and PMM.
+		Ingress IngressConfig `mapstructure:"ingress"`
+		// Audit records the changes made to the cluster in a local log.
+		Audit AuditConfig `mapstructure:"audit"`
+		// ForceUnlock takes over the lock of the cluster held by another run, stopped or not.
+		ForceUnlock bool `mapstructure:"force_unlock"`
+
+		// kubeconfigSet is true if the kubeconfig was set explicitly rather than defaulted.
+		kubeconfigSet bool
+	}
+	// BackendConfig configures the deployment of the Everest API server and UI and how it is exposed.
+	BackendConfig struct {
+		// Image is the image of the backend, the released one if empty.
+		Image    string `mapstructure:"image"`
+		Replicas int32  `mapstructure:"replicas"`
+		// ServiceType exposes the backend with a ClusterIP, NodePort or LoadBalancer service.
+		ServiceType string `mapstructure:"service_type"`
+		// Host exposes the backend at the host name with an Ingress, or a Route on OpenShift.
+		Host string `mapstructure:"host"`
+		// TLSSecret is a kubernetes.io/tls secret with the certificate served for the host.
+		TLSSecret string `mapstructure:"tls_secret"`
+		// ServiceAccount is the service account the backend authenticates as.
+		ServiceAccount string `mapstructure:"service_account"`
+	}
+	// AuditConfig configures the log of the mutating API requests sent by the provisioner.
+	AuditConfig struct {
+		// Disabled stops recording the requests, set by --no-audit.
+		Disabled bool `mapstructure:"disabled"`
+		// Path of the log, $HOME/.everest/audit.log if empty.
+		Path string `mapstructure:"path"`
+	}
+	// IngressConfig configures the ingress controller serving the host names of Everest and PMM.
+	IngressConfig struct {
+		// Controller the annotations of the Ingresses are generated for: nginx, traefik or alb.
+		// It is detected from the class or the cluster type if it is empty.
+		Controller string `mapstructure:"controller"`
+		// Class selects the ingress controller, the default one if empty.
+		Class string `mapstructure:"class"`
+		// CertificateARN is the ACM certificate served by the AWS load balancer instead of
+		// the TLS secrets.
+		CertificateARN string `mapstructure:"certificate_arn"`
+		// PMM exposes a PMM server running in the cluster if its host is set.
+		PMM IngressPMMConfig `mapstructure:"pmm"`
+	}
+	// IngressPMMConfig exposes the HTTPS service of a PMM server running in the cluster.
+	IngressPMMConfig struct {
+		Host string `mapstructure:"host"`
+		// TLSSecret is a kubernetes.io/tls secret with the certificate served for the host.
+		TLSSecret string `mapstructure:"tls_secret"`
+		// Namespace of the PMM server, the installation namespace if empty.
+		Namespace string `mapstructure:"namespace"`
+		Service   string `mapstructure:"service"`
+		Port      int32  `mapstructure:"port"`
+	}
+	// BackupConfig configures how database clusters authenticate to the backup storage.
+	BackupConfig struct {
+		// IRSARoleARN is the IAM role assumed through IAM roles for service accounts on EKS
+		// instead of static S3 keys, e.g. arn:aws:iam::123456789012:role/everest-backup.
+		IRSARoleARN string `mapstructure:"irsa_role_arn"`
+		// ServiceAccounts are the service accounts annotated with the role. Database cluster
+		// pods run as the default service account, which is used if it is empty.
+		ServiceAccounts []string `mapstructure:"service_accounts"`
+	}
+	// PlacementConfig spreads the pods of database clusters over zones or nodes.
+	PlacementConfig struct {
+		// Spread is the failure domain the pods are spread over: auto, zone, hostname or none.
+		// auto picks zones if every pod fits into its own zone and nodes otherwise.
+		Spread string `mapstructure:"spread"`
+		// Required refuses scheduling two pods into the same failure domain instead of
+		// only preferring distinct ones.
+		Required bool `mapstructure:"required"`
+		// MaxSkew is the maximum difference of the number of pods between failure domains.
+		// It defaults to 1.
+		MaxSkew int32 `mapstructure:"max_skew"`
+	}
+	// SchedulingConfig constrains the nodes the installed components run on, e.g. to an
+	// infra node pool.
+	SchedulingConfig struct {
+		NodeSelector map[string]string  `mapstructure:"node_selector"`
+		Tolerations  []TolerationConfig `mapstructure:"tolerations"`
+		// Affinity is a pod affinity in the format of the Kubernetes API, e.g. nodeAffinity.
+		Affinity map[string]interface{} `mapstructure:"affinity"`
+	}
+	// TolerationConfig is a toleration of a taint in the format of the Kubernetes API.
+	TolerationConfig struct {
+		Key               string `mapstructure:"key"`
+		Operator          string `mapstructure:"operator"`
+		Value             string `mapstructure:"value"`
+		Effect            string `mapstructure:"effect"`
+		TolerationSeconds *int64 `mapstructure:"toleration_seconds"`
+	}
+	// HTTPConfig configures the client of outbound HTTP requests. Retries are disabled
+	// by disable_retries too.
+	HTTPConfig struct {
+		// Proxy is the URL of the proxy. HTTPS_PROXY, HTTP_PROXY and NO_PROXY are used if it is empty.
+		Proxy string `mapstructure:"proxy"`
+		// CA is a PEM file of CAs trusted in addition to the system CAs.
+		CA string `mapstructure:"ca"`
+		// Timeout limits a request including retries, 30s by default.
+		Timeout time.Duration `mapstructure:"timeout"`
+		// Retries is how often failed idempotent requests are repeated, 3 by default.
+		// Requests are sent once if it is zero.
+		Retries int `mapstructure:"retries"`
+		// RateLimit limits the requests per second. It is not limited if it is zero.
+		RateLimit float64 `mapstructure:"rate_limit"`
+	}
+	// DigestConfig configures where the maintenance digest is delivered. It is posted
+	// as JSON to the webhook and mailed as text to the recipients if they are set.
+	DigestConfig struct {
+		WebhookURL string      `mapstructure:"webhook_url"`
+		Email      EmailConfig `mapstructure:"email"`
+	}
+	// EmailConfig configures the SMTP server mails are sent through. The credentials
+	// are optional and sent with PLAIN authentication.
+	EmailConfig struct {
+		// SMTPAddress is the host:port of the SMTP server.
+		SMTPAddress string   `mapstructure:"smtp_address"`
+		Username    string   `mapstructure:"username"`
+		Password    string   `mapstructure:"password"`
+		From        string   `mapstructure:"from"`
+		To          []string `mapstructure:"to"`
+	}
+	// VaultConfig configures the HashiCorp Vault secrets backend. The secrets are
+	// provided to pods by the Vault Agent Injector.
+	VaultConfig struct {
+		Address string `mapstructure:"address"`
+		// AuthMethod is either token or kubernetes. The token is taken from
+		// VAULT_TOKEN if it is not set.
+		AuthMethod string `mapstructure:"auth_method"`
+		Token      string `mapstructure:"token"`
+		// Role and AuthMount are used by the kubernetes auth method.
+		Role      string `mapstructure:"role"`
+		AuthMount string `mapstructure:"auth_mount"`
+		// Mount is the KV version 2 secrets engine and Path prefixes the secrets in it.
+		Mount string `mapstructure:"mount"`
+		Path  string `mapstructure:"path"`
+		// InjectorRole is the role the Vault Agent injected into pods authenticates with.
+		// It defaults to role.
+		InjectorRole string `mapstructure:"injector_role"`
+	}
+	// ImagePullSecretConfig describes a pull secret created by the provisioner.
+	ImagePullSecretConfig struct {
+		Name     string `mapstructure:"name"`
+		Registry string `mapstructure:"registry"`
+		Username string `mapstructure:"username"`
+		Password string `mapstructure:"password"`
+	}
+	// CatalogConfig points the installation at a mirrored or internal catalog source.
+	// Empty fields keep the Percona catalog.
+	CatalogConfig struct {
+		Name      string `mapstructure:"name"`
+		Image     string `mapstructure:"image"`
+		Namespace string `mapstructure:"namespace"`
+	}
+	// OLMConfig holds the tuning of the OLM components. Components override the profile.
+	OLMConfig struct {
+		Profile    string                        `mapstructure:"profile"`
+		Components map[string]OLMComponentConfig `mapstructure:"components"`
+	}
+	// OLMComponentConfig overrides the replicas and resource requests of an OLM component.
+	OLMComponentConfig struct {
+		Replicas *int32 `mapstructure:"replicas"`
+		CPU      string `mapstructure:"cpu"`
+		Memory   string `mapstructure:"memory"`
+	}
+	// GlobalConfig holds settings applied to every object created by the provisioner.
+	GlobalConfig struct {
+		Labels      map[string]string `mapstructure:"labels"`
+		Annotations map[string]string `mapstructure:"annotations"`
+	}
+	// ClusterConfig describes a named cluster from the clusters registry.
+	ClusterConfig struct {
+		Kubeconfig string `mapstructure:"kubeconfig"`
+		Context    string `mapstructure:"context"`
 	}
 	MonitoringConfig struct {
 		Enabled bool           `mapstructure:"enabled"`
 		Type    MonitoringType `mapstructure:"type"`
 		PMM     *PMMConfig     `mapstructure:"pmm"`
+		// Selective limits scraping to database clusters with monitoring enabled.
+		Selective bool `mapstructure:"selective"`
+		// Resources overrides the requests and limits of the monitoring stack.
+		Resources MonitoringResourcesConfig `mapstructure:"resources"`
+		// Scrape customizes what the VM agent scrapes and the labels of the metrics.
+		Scrape ScrapeConfig `mapstructure:"scrape"`
+		// KubeStateMetrics overrides the image of kube-state-metrics. Its resources are
+		// set in Resources.
+		KubeStateMetrics KubeStateMetricsConfig `mapstructure:"kube_state_metrics"`
+		// Alerts installs alerting rules of database clusters evaluated against PMM.
+		Alerts AlertsConfig `mapstructure:"alerts"`
+	}
+	// AlertsConfig configures the alerting rules of database clusters. Alerts are sent to the
+	// Alertmanager of PMM unless Alertmanager URLs are set.
+	AlertsConfig struct {
+		Enabled bool `mapstructure:"enabled"`
+		// AlertmanagerURLs receive the alerts instead of PMM, e.g. http://alertmanager:9093.
+		AlertmanagerURLs []string `mapstructure:"alertmanager_urls"`
+		// EvaluationInterval is how often the rules are evaluated, e.g. 1m.
+		EvaluationInterval string `mapstructure:"evaluation_interval"`
+	}
+	// KubeStateMetricsConfig overrides the image of kube-state-metrics, e.g. to use a mirror
+	// or a newer version. The upgrade command re-applies kube-state-metrics if it changes.
+	KubeStateMetricsConfig struct {
+		// Image replaces the default image. The default tag is kept if it has none.
+		Image string `mapstructure:"image"`
+		// Tag replaces the tag of the image, e.g. v2.8.2.
+		Tag string `mapstructure:"tag"`
+	}
+	// ScrapeConfig customizes the scraping of the VM agent writing to PMM. Empty fields keep the defaults.
+	ScrapeConfig struct {
+		// Interval is how often targets without an interval of their own are scraped, e.g. 30s.
+		Interval string `mapstructure:"interval"`
+		// ServiceScrapeSelector and PodScrapeSelector limit the VMServiceScrapes and VMPodScrapes
+		// scraped to the ones with all the labels. The scrapes of the provisioner get the labels too.
+		ServiceScrapeSelector map[string]string `mapstructure:"service_scrape_selector"`
+		PodScrapeSelector     map[string]string `mapstructure:"pod_scrape_selector"`
+		// ExternalLabels are added to all metrics, e.g. the cluster name and the environment,
+		// so metrics of several clusters can be told apart in PMM.
+		ExternalLabels map[string]string `mapstructure:"external_labels"`
+		// MemoryAllowedPercent is the share of the memory of the VM agent used for caches (default 40).
+		MemoryAllowedPercent int `mapstructure:"memory_allowed_percent"`
+	}
+	// MonitoringResourcesConfig holds the resources of the VM agents and kube-state-metrics.
+	MonitoringResourcesConfig struct {
+		VMAgent          ResourcesConfig `mapstructure:"vmagent"`
+		KubeStateMetrics ResourcesConfig `mapstructure:"kube_state_metrics"`
+	}
+	// ResourcesConfig holds resource requests and limits. Empty fields keep the defaults.
+	ResourcesConfig struct {
+		Requests ResourceListConfig `mapstructure:"requests"`
+		Limits   ResourceListConfig `mapstructure:"limits"`
+	}
+	// ResourceListConfig holds CPU and memory quantities, e.g. 250m and 350Mi.
+	ResourceListConfig struct {
+		CPU    string `mapstructure:"cpu"`
+		Memory string `mapstructure:"memory"`
 	}
 	PMMConfig struct {
 		Endpoint string `mapstructure:"endpoint"`
 		Username string `mapstructure:"username"`
 		Password string `mapstructure:"password"`
+		// PasswordFile is read into Password by ParseConfig so the password
+		// does not have to be passed on the command line.
+		PasswordFile string `mapstructure:"password_file"`
+		// CredentialsSecret names an existing secret with the username and password
+		// keys the metrics are written with. No API key is created in PMM if it is set.
+		CredentialsSecret string `mapstructure:"credentials_secret"`
+		// APIKey is an existing PMM API key or Grafana service account token the metrics are
+		// written with. No API key is created and the admin credentials are not needed if it is set.
+		APIKey string    `mapstructure:"api_key"`
+		TLS    TLSConfig `mapstructure:"tls"`
+	}
+	// TLSConfig configures the connection to a monitoring endpoint. Certificates and
+	// the key are paths to PEM files. The system CAs are used if CA is not set.
+	TLSConfig struct {
+		CA                 string `mapstructure:"ca"`
+		Cert               string `mapstructure:"cert"`
+		Key                string `mapstructure:"key"`
+		InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
 	}
 )
 
-func ParseConfig() (*AppConfig, error) {
+// Load reads the configuration file and enables the environment variable overrides.
+// If path is empty, the file named by EVEREST_CONFIG or $HOME/.everest/config.yaml
+// is read if it exists. Settings are taken from the first source defining them:
+//
+//  1. command line flags passed explicitly,
+//  2. environment variables prefixed with EVEREST_, e.g. EVEREST_MONITORING_PMM_ENDPOINT,
+//  3. the configuration file,
+//  4. defaults of the flags.
+//
+// Lists and maps, e.g. image_pull_secrets, are replaced as a whole rather than merged.
+// They cannot be set with environment variables.
+func Load(path string) error {
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+	if err := bindEnv("", reflect.TypeOf(AppConfig{})); err != nil {
+		return err
+	}
+
 	viper.SetConfigType("yaml")
+	if path == "" {
+		path = os.Getenv(ConfigEnv)
+	}
+	if path == "" {
+		path = defaultConfigFile()
+		if path == "" {
+			return nil
+		}
+	}
+	if err := checkConfigFile(path); err != nil {
+		return err
+	}
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("cannot read config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// defaultConfigFile returns the path of the configuration file in the home
+// directory or an empty string if there is none.
+func defaultConfigFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(home, configDir, configName+".yaml")
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return ""
+	}
+	return path
+}
+
+// DefaultAuditLog returns the path of the audit log in the home directory or an empty string
+// if the home directory is unknown.
+func DefaultAuditLog() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, configDir, auditLogName)
+}
+
+// File returns the path of the configuration file in use or an empty string.
+func File() string {
+	return viper.ConfigFileUsed()
+}
+
+// checkConfigFile reports keys of the file unknown to the configuration schema,
+// which are usually typos that would be silently ignored otherwise.
+func checkConfigFile(path string) error {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("cannot read config file %s: %w", path, err)
+	}
+	if err := v.UnmarshalExact(&AppConfig{}); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// bindEnv binds the environment variables of all scalar settings so they are
+// taken into account by viper.Unmarshal, which ignores keys viper does not know about.
+func bindEnv(prefix string, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch ft.Kind() {
+		case reflect.Struct:
+			if err := bindEnv(key, ft); err != nil {
+				return err
+			}
+		case reflect.Map, reflect.Slice:
+		default:
+			if err := viper.BindEnv(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ParseConfig returns the validated configuration.
+func ParseConfig() (*AppConfig, error) {
 	c := &AppConfig{}
-	err := viper.Unmarshal(c)
-	return c, err
+	if err := viper.Unmarshal(c); err != nil {
+		return nil, err
+	}
+	c.kubeconfigSet = viper.IsSet("kubeconfig")
+	if err := c.readPasswordFile(); err != nil {
+		return nil, err
+	}
+	c.applyProfile()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Hardened returns true if the hardened profile is enabled.
+func (c *AppConfig) Hardened() bool {
+	return c.Profile == ProfileHardened
+}
+
+// ParseAffinity decodes the configured affinity. Keys are matched case-insensitively
+// because the configuration keys are lowercased. It returns nil if no affinity is set.
+func (s SchedulingConfig) ParseAffinity() (*corev1.Affinity, error) {
+	if len(s.Affinity) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(s.Affinity)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	affinity := &corev1.Affinity{}
+	if err := dec.Decode(affinity); err != nil {
+		return nil, err
+	}
+	return affinity, nil
+}
+
+// applyProfile sets the defaults of the profile to the settings which are not set.
+func (c *AppConfig) applyProfile() {
+	if c.Hardened() && c.SecretRotationPeriod == 0 {
+		c.SecretRotationPeriod = DefaultSecretRotationPeriod
+	}
+}
+
+// readPasswordFile sets the PMM password from the password file. Trailing
+// newlines are trimmed. Setting both the password and the file is an error.
+func (c *AppConfig) readPasswordFile() error {
+	pmm := c.Monitoring.PMM
+	if pmm == nil || pmm.PasswordFile == "" {
+		return nil
+	}
+	if pmm.Password != "" {
+		return errors.New("monitoring.pmm.password and monitoring.pmm.password_file cannot be set together")
+	}
+	data, err := os.ReadFile(pmm.PasswordFile)
+	if err != nil {
+		return fmt.Errorf("cannot read PMM password file: %w", err)
+	}
+	pmm.Password = strings.TrimRight(string(data), "\r\n")
+	return nil
+}
+
+// KubeTarget returns the kubeconfig path and the context name to connect to.
+// A named cluster from the registry takes precedence over the default kubeconfig
+// while an explicitly set kubeconfig or context overrides the cluster's one.
+func (c *AppConfig) KubeTarget() (kubeconfig, context string, err error) {
+	kubeconfig, context = c.Kubeconfig, c.KubeContext
+	if c.Cluster == "" {
+		return kubeconfig, context, nil
+	}
+	cluster, ok := c.Clusters[c.Cluster]
+	if !ok {
+		return "", "", fmt.Errorf("cluster %q is not defined in the clusters registry", c.Cluster)
+	}
+	if cluster.Kubeconfig != "" && !c.kubeconfigSet {
+		kubeconfig = cluster.Kubeconfig
+	}
+	if context == "" {
+		context = cluster.Context
+	}
+	return kubeconfig, context, nil
 }
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), "install.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+install_olm: false
+monitoring:
+  pmm:
+    endpoint: https://pmm.example.com
+    username: ci
+operator_channels:
+  percona-xtradb-cluster-operator: fast-v1
+`), 0o600))
+	t.Setenv(ConfigEnv, path)
+	t.Setenv(EnvPrefix+"_MONITORING_PMM_USERNAME", "env")
+
+	require.NoError(t, Load(""))
+	assert.Equal(t, path, File())
+	c := &AppConfig{}
+	require.NoError(t, viper.Unmarshal(c))
+	assert.False(t, c.InstallOLM)
+	assert.Equal(t, "https://pmm.example.com", c.Monitoring.PMM.Endpoint)
+	assert.Equal(t, "env", c.Monitoring.PMM.Username)
+	assert.Equal(t, "fast-v1", c.OperatorChannels["percona-xtradb-cluster-operator"])
+}
+
+func TestMarshal(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	replicas := int32(2)
+	c := &AppConfig{
+		InCluster: true,
+		Monitoring: MonitoringConfig{
+			Enabled: false,
+			Type:    MonitoringTypePMM,
+			PMM:     &PMMConfig{Endpoint: "https://pmm.example.com", Username: "admin", Password: "secret"},
+		},
+		OLM:              OLMConfig{Components: map[string]OLMComponentConfig{"catalog-operator": {Replicas: &replicas}}},
+		ImagePullSecrets: []ImagePullSecretConfig{{Name: "registry", Registry: "registry.example.com"}},
+	}
+	data, err := c.Marshal()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	require.NoError(t, Load(path))
+	parsed := &AppConfig{}
+	require.NoError(t, viper.Unmarshal(parsed))
+	assert.Equal(t, c, parsed)
+}
+
+func TestReadPasswordFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pmm-password")
+	require.NoError(t, os.WriteFile(path, []byte("s3cr3t\n"), 0o600))
+
+	c := &AppConfig{Monitoring: MonitoringConfig{PMM: &PMMConfig{PasswordFile: path}}}
+	require.NoError(t, c.readPasswordFile())
+	assert.Equal(t, "s3cr3t", c.Monitoring.PMM.Password)
+
+	c = &AppConfig{Monitoring: MonitoringConfig{PMM: &PMMConfig{Password: "password", PasswordFile: path}}}
+	assert.Error(t, c.readPasswordFile())
+}
+
+func TestKubeTarget(t *testing.T) {
+	clusters := map[string]ClusterConfig{
+		"prod":    {Kubeconfig: "/etc/prod.yaml", Context: "prod"},
+		"staging": {Context: "staging"},
+	}
+	tests := []struct {
+		name       string
+		config     *AppConfig
+		kubeconfig string
+		context    string
+		err        string
+	}{
+		{
+			name:       "explicit kubeconfig and context",
+			config:     &AppConfig{Kubeconfig: "/tmp/kubeconfig", KubeContext: "admin", kubeconfigSet: true},
+			kubeconfig: "/tmp/kubeconfig",
+			context:    "admin",
+		},
+		{
+			name:       "default kubeconfig",
+			config:     &AppConfig{Kubeconfig: "~/.kube/config"},
+			kubeconfig: "~/.kube/config",
+		},
+		{
+			name:       "registry cluster",
+			config:     &AppConfig{Kubeconfig: "~/.kube/config", Cluster: "prod", Clusters: clusters},
+			kubeconfig: "/etc/prod.yaml",
+			context:    "prod",
+		},
+		{
+			name:       "registry cluster in the default kubeconfig",
+			config:     &AppConfig{Kubeconfig: "~/.kube/config", Cluster: "staging", Clusters: clusters},
+			kubeconfig: "~/.kube/config",
+			context:    "staging",
+		},
+		{
+			name:       "explicit kubeconfig wins over the registry",
+			config:     &AppConfig{Kubeconfig: "/tmp/kubeconfig", Cluster: "prod", Clusters: clusters, kubeconfigSet: true},
+			kubeconfig: "/tmp/kubeconfig",
+			context:    "prod",
+		},
+		{
+			name:       "explicit context wins over the registry",
+			config:     &AppConfig{Kubeconfig: "~/.kube/config", KubeContext: "admin", Cluster: "prod", Clusters: clusters},
+			kubeconfig: "/etc/prod.yaml",
+			context:    "admin",
+		},
+		{
+			name:   "missing cluster",
+			config: &AppConfig{Cluster: "dev", Clusters: clusters},
+			err:    `cluster "dev" is not defined in the clusters registry`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeconfig, context, err := tt.config.KubeTarget()
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.kubeconfig, kubeconfig)
+			assert.Equal(t, tt.context, context)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Marshal renders the configuration as a config file. Scalar settings are written
+// even if they are empty so they are not replaced by defaults of the flags when
+// the file is read back.
+func (c *AppConfig) Marshal() ([]byte, error) {
+	return yaml.Marshal(settings(reflect.ValueOf(*c)))
+}
+
+// settings converts the value to maps keyed by the mapstructure tags so the
+// result can be read back by Load.
+func settings(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return settings(v.Elem())
+	case reflect.Struct:
+		m := map[string]interface{}{}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			key := t.Field(i).Tag.Get("mapstructure")
+			if key == "" || empty(v.Field(i)) {
+				continue
+			}
+			m[key] = settings(v.Field(i))
+		}
+		return m
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = settings(iter.Value())
+		}
+		return m
+	case reflect.Slice:
+		s := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s = append(s, settings(v.Index(i)))
+		}
+		return s
+	case reflect.String:
+		return v.String()
+	}
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	return v.Interface()
+}
+
+// empty returns true for unset pointers and empty collections.
+func empty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	}
+	return false
+}
@@ -0,0 +1,564 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/pkg/logger"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// FieldError describes an invalid setting.
+type FieldError struct {
+	// Field is the dotted path of the setting as used in the config file.
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError lists all invalid settings of the configuration.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, "  "+err.Error())
+	}
+	return "invalid configuration:\n" + strings.Join(msgs, "\n")
+}
+
+func (e *ValidationError) add(field, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks the configuration and reports all invalid settings at once.
+func (c *AppConfig) Validate() error {
+	errs := &ValidationError{}
+	c.validateMonitoring(errs)
+	c.validateMonitoringResources(errs)
+	c.validateScrape(errs)
+	c.validateKubeStateMetrics(errs)
+	c.validateAlerts(errs)
+	c.validateBackend(errs)
+	c.validateIngress(errs)
+	c.validateClusters(errs)
+	c.validateOLM(errs)
+	c.validateCatalog(errs)
+	c.validateOperatorChannels(errs)
+	c.validateImagePullSecrets(errs)
+	c.validateLogFormat(errs)
+	c.validateVault(errs)
+	c.validateClusterDomain(errs)
+	c.validateProfile(errs)
+	c.validateDigest(errs)
+	c.validateHTTP(errs)
+	c.validateScheduling(errs)
+	c.validateBackup(errs)
+	c.validatePlacement(errs)
+	validateMetadata(errs, "global.labels", c.Global.Labels, true)
+	validateMetadata(errs, "global.annotations", c.Global.Annotations, false)
+	if len(errs.Errors) != 0 {
+		return errs
+	}
+	return nil
+}
+
+func (c *AppConfig) validateMonitoring(errs *ValidationError) {
+	if !c.Monitoring.Enabled {
+		return
+	}
+	if c.Monitoring.Type != MonitoringTypePMM {
+		errs.add("monitoring.type", "unsupported monitoring type %q, supported types: %s", c.Monitoring.Type, MonitoringTypePMM)
+		return
+	}
+	pmm := c.Monitoring.PMM
+	if pmm == nil || pmm.Endpoint == "" {
+		errs.add("monitoring.pmm.endpoint", "is required when monitoring is enabled")
+		return
+	}
+	u, err := url.Parse(pmm.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs.add("monitoring.pmm.endpoint", "%q is not an http(s) URL", pmm.Endpoint)
+	}
+	switch {
+	case pmm.CredentialsSecret != "" && pmm.APIKey != "":
+		errs.add("monitoring.pmm.api_key", "cannot be set together with credentials_secret")
+	case pmm.CredentialsSecret != "":
+		for _, msg := range validation.IsDNS1123Subdomain(pmm.CredentialsSecret) {
+			errs.add("monitoring.pmm.credentials_secret", "invalid name %q: %s", pmm.CredentialsSecret, msg)
+		}
+	case pmm.APIKey != "":
+	case pmm.Username == "" || pmm.Password == "":
+		errs.add("monitoring.pmm", "username and password, api_key or credentials_secret are required when monitoring is enabled")
+	}
+	pmm.TLS.validate(errs, "monitoring.pmm.tls")
+}
+
+// validate reports certificate files which cannot be read and a certificate without a key.
+func (t TLSConfig) validate(errs *ValidationError, field string) {
+	if (t.Cert == "") != (t.Key == "") {
+		errs.add(field, "cert and key must be set together")
+	}
+	files := []struct{ name, path string }{{"ca", t.CA}, {"cert", t.Cert}, {"key", t.Key}}
+	for _, f := range files {
+		if f.path == "" {
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			errs.add(field+"."+f.name, "cannot read %s: %v", f.path, err)
+		}
+	}
+}
+
+func (c *AppConfig) validateMonitoringResources(errs *ValidationError) {
+	r := c.Monitoring.Resources
+	validateResources(errs, "monitoring.resources.vmagent", r.VMAgent)
+	validateResources(errs, "monitoring.resources.kube_state_metrics", r.KubeStateMetrics)
+}
+
+// metricLabelName matches the names of Prometheus labels.
+var metricLabelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+func (c *AppConfig) validateScrape(errs *ValidationError) {
+	s := c.Monitoring.Scrape
+	if s.Interval != "" {
+		if d, err := time.ParseDuration(s.Interval); err != nil || d <= 0 {
+			errs.add("monitoring.scrape.interval", "%q is not a positive duration, e.g. 30s", s.Interval)
+		}
+	}
+	validateMetadata(errs, "monitoring.scrape.service_scrape_selector", s.ServiceScrapeSelector, true)
+	validateMetadata(errs, "monitoring.scrape.pod_scrape_selector", s.PodScrapeSelector, true)
+	for _, name := range sortedKeys(s.ExternalLabels) {
+		if !metricLabelName.MatchString(name) || strings.HasPrefix(name, "__") {
+			errs.add("monitoring.scrape.external_labels", "invalid label name %q, e.g. environment", name)
+		}
+	}
+	if s.MemoryAllowedPercent < 0 || s.MemoryAllowedPercent > 100 {
+		errs.add("monitoring.scrape.memory_allowed_percent", "%d is not a percentage between 1 and 100", s.MemoryAllowedPercent)
+	}
+}
+
+// imageTag matches the tags of container images.
+var imageTag = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+
+func (c *AppConfig) validateKubeStateMetrics(errs *ValidationError) {
+	ksm := c.Monitoring.KubeStateMetrics
+	if strings.ContainsAny(ksm.Image, " \t\n") {
+		errs.add("monitoring.kube_state_metrics.image", "%q is not a valid image", ksm.Image)
+	}
+	if ksm.Tag != "" && !imageTag.MatchString(ksm.Tag) {
+		errs.add("monitoring.kube_state_metrics.tag", "%q is not a valid image tag, e.g. v2.8.2", ksm.Tag)
+	}
+}
+
+func (c *AppConfig) validateAlerts(errs *ValidationError) {
+	a := c.Monitoring.Alerts
+	if !a.Enabled {
+		return
+	}
+	if !c.Monitoring.Enabled {
+		errs.add("monitoring.alerts.enabled", "requires monitoring.enabled")
+	}
+	for _, alertmanager := range a.AlertmanagerURLs {
+		u, err := url.Parse(alertmanager)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add("monitoring.alerts.alertmanager_urls", "%q is not an http(s) URL", alertmanager)
+		}
+	}
+	if a.EvaluationInterval != "" {
+		if d, err := time.ParseDuration(a.EvaluationInterval); err != nil || d <= 0 {
+			errs.add("monitoring.alerts.evaluation_interval", "%q is not a positive duration, e.g. 1m", a.EvaluationInterval)
+		}
+	}
+}
+
+func (c *AppConfig) validateBackend(errs *ValidationError) {
+	b := c.Backend
+	switch corev1.ServiceType(b.ServiceType) {
+	case "", corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+	default:
+		errs.add("backend.service_type", "%q is not one of ClusterIP, NodePort or LoadBalancer", b.ServiceType)
+	}
+	if b.Replicas < 0 {
+		errs.add("backend.replicas", "must not be negative")
+	}
+	if b.Host != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(b.Host) {
+			errs.add("backend.host", "invalid host name %q: %s", b.Host, msg)
+		}
+	}
+	if b.TLSSecret != "" && b.Host == "" {
+		errs.add("backend.tls_secret", "requires backend.host")
+	}
+	names := []struct{ field, name string }{
+		{"backend.tls_secret", b.TLSSecret},
+		{"backend.service_account", b.ServiceAccount},
+	}
+	for _, n := range names {
+		if n.name == "" {
+			continue
+		}
+		for _, msg := range validation.IsDNS1123Subdomain(n.name) {
+			errs.add(n.field, "invalid name %q: %s", n.name, msg)
+		}
+	}
+}
+
+func (c *AppConfig) validateIngress(errs *ValidationError) {
+	i := c.Ingress
+	switch i.Controller {
+	case "", "nginx", "traefik", "alb":
+	default:
+		errs.add("ingress.controller", "%q is not one of nginx, traefik or alb", i.Controller)
+	}
+	if i.CertificateARN != "" && !strings.HasPrefix(i.CertificateARN, "arn:") {
+		errs.add("ingress.certificate_arn", "%q is not an ARN", i.CertificateARN)
+	}
+	if i.PMM.Host != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(i.PMM.Host) {
+			errs.add("ingress.pmm.host", "invalid host name %q: %s", i.PMM.Host, msg)
+		}
+	}
+	if i.PMM.TLSSecret != "" && i.PMM.Host == "" {
+		errs.add("ingress.pmm.tls_secret", "requires ingress.pmm.host")
+	}
+	if i.PMM.Port < 0 || i.PMM.Port > 65535 {
+		errs.add("ingress.pmm.port", "%d is not a port number", i.PMM.Port)
+	}
+	names := []struct{ field, name string }{
+		{"ingress.class", i.Class},
+		{"ingress.pmm.tls_secret", i.PMM.TLSSecret},
+		{"ingress.pmm.service", i.PMM.Service},
+	}
+	for _, n := range names {
+		if n.name == "" {
+			continue
+		}
+		for _, msg := range validation.IsDNS1123Subdomain(n.name) {
+			errs.add(n.field, "invalid name %q: %s", n.name, msg)
+		}
+	}
+	if i.PMM.Namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(i.PMM.Namespace) {
+			errs.add("ingress.pmm.namespace", "invalid namespace %q: %s", i.PMM.Namespace, msg)
+		}
+	}
+}
+
+func validateResources(errs *ValidationError, field string, r ResourcesConfig) {
+	quantities := []struct {
+		name, request, limit, example string
+	}{
+		{"cpu", r.Requests.CPU, r.Limits.CPU, "250m"},
+		{"memory", r.Requests.Memory, r.Limits.Memory, "350Mi"},
+	}
+	for _, q := range quantities {
+		request, requestErr := resource.ParseQuantity(q.request)
+		if q.request != "" && requestErr != nil {
+			errs.add(field+".requests."+q.name, "%q is not a valid quantity, e.g. %s", q.request, q.example)
+		}
+		limit, limitErr := resource.ParseQuantity(q.limit)
+		if q.limit != "" && limitErr != nil {
+			errs.add(field+".limits."+q.name, "%q is not a valid quantity, e.g. %s", q.limit, q.example)
+		}
+		if q.request != "" && q.limit != "" && requestErr == nil && limitErr == nil && request.Cmp(limit) > 0 {
+			errs.add(field+".requests."+q.name, "%s exceeds the limit %s", q.request, q.limit)
+		}
+	}
+}
+
+func (c *AppConfig) validateClusters(errs *ValidationError) {
+	for _, name := range sortedKeys(c.Clusters) {
+		cluster := c.Clusters[name]
+		if cluster.Kubeconfig == "" && cluster.Context == "" {
+			errs.add("clusters."+name, "either kubeconfig or context is required")
+		}
+	}
+	if c.Cluster == "" {
+		return
+	}
+	if _, ok := c.Clusters[c.Cluster]; !ok {
+		known := sortedKeys(c.Clusters)
+		if len(known) == 0 {
+			errs.add("cluster", "%q is not defined, the clusters registry is empty", c.Cluster)
+			return
+		}
+		errs.add("cluster", "%q is not defined, known clusters: %s", c.Cluster, strings.Join(known, ", "))
+	}
+}
+
+func (c *AppConfig) validateOLM(errs *ValidationError) {
+	for _, name := range sortedKeys(c.OLM.Components) {
+		component := c.OLM.Components[name]
+		field := "olm.components." + name
+		if component.Replicas != nil && *component.Replicas < 0 {
+			errs.add(field+".replicas", "must not be negative")
+		}
+		if _, err := resource.ParseQuantity(component.CPU); component.CPU != "" && err != nil {
+			errs.add(field+".cpu", "%q is not a valid quantity, e.g. 100m", component.CPU)
+		}
+		if _, err := resource.ParseQuantity(component.Memory); component.Memory != "" && err != nil {
+			errs.add(field+".memory", "%q is not a valid quantity, e.g. 128Mi", component.Memory)
+		}
+	}
+}
+
+func (c *AppConfig) validateCatalog(errs *ValidationError) {
+	if c.Catalog.Name != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(c.Catalog.Name) {
+			errs.add("catalog.name", "invalid name %q: %s", c.Catalog.Name, msg)
+		}
+	}
+	if c.Catalog.Namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(c.Catalog.Namespace) {
+			errs.add("catalog.namespace", "invalid namespace %q: %s", c.Catalog.Namespace, msg)
+		}
+	}
+}
+
+func (c *AppConfig) validateLogFormat(errs *ValidationError) {
+	if c.LogFormat == "" {
+		return
+	}
+	for _, f := range logger.Formats {
+		if c.LogFormat == f {
+			return
+		}
+	}
+	errs.add("log_format", "unsupported log format %q, supported formats: %s", c.LogFormat, strings.Join(logger.Formats, ", "))
+}
+
+func (c *AppConfig) validateOperatorChannels(errs *ValidationError) {
+	for _, name := range sortedKeys(c.OperatorChannels) {
+		if c.OperatorChannels[name] == "" {
+			errs.add("operator_channels."+name, "must not be empty")
+		}
+	}
+}
+
+func (c *AppConfig) validateImagePullSecrets(errs *ValidationError) {
+	names := make(map[string]struct{}, len(c.ImagePullSecrets))
+	for i, secret := range c.ImagePullSecrets {
+		field := fmt.Sprintf("image_pull_secrets[%d]", i)
+		for _, msg := range validation.IsDNS1123Subdomain(secret.Name) {
+			errs.add(field+".name", "invalid name %q: %s", secret.Name, msg)
+		}
+		if _, ok := names[secret.Name]; ok {
+			errs.add(field+".name", "%q is defined more than once", secret.Name)
+		}
+		names[secret.Name] = struct{}{}
+		if secret.Registry == "" {
+			errs.add(field+".registry", "is required")
+		}
+		if secret.Username == "" || secret.Password == "" {
+			errs.add(field, "username and password are required")
+		}
+	}
+}
+
+func validateMetadata(errs *ValidationError, field string, metadata map[string]string, labels bool) {
+	for _, key := range sortedKeys(metadata) {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs.add(field, "invalid key %q: %s", key, msg)
+		}
+		if !labels {
+			continue
+		}
+		for _, msg := range validation.IsValidLabelValue(metadata[key]) {
+			errs.add(field, "invalid value of %q: %s", key, msg)
+		}
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *AppConfig) validateVault(errs *ValidationError) {
+	v := c.Vault
+	if v.Address == "" {
+		return
+	}
+	u, err := url.Parse(v.Address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs.add("vault.address", "%q is not an http(s) URL", v.Address)
+	}
+	switch v.AuthMethod {
+	case "", VaultAuthToken:
+	case VaultAuthKubernetes:
+		if v.Role == "" {
+			errs.add("vault.role", "is required by the kubernetes auth method")
+		}
+	default:
+		errs.add("vault.auth_method", "unsupported auth method %q, supported methods: %s, %s", v.AuthMethod, VaultAuthToken, VaultAuthKubernetes)
+	}
+}
+
+func (c *AppConfig) validateClusterDomain(errs *ValidationError) {
+	if c.ClusterDomain == "" {
+		return
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(c.ClusterDomain) {
+		errs.add("cluster_domain", "invalid domain %q: %s", c.ClusterDomain, msg)
+	}
+}
+
+// validateProfile reports unknown profiles and settings the hardened profile does not allow.
+func (c *AppConfig) validateProfile(errs *ValidationError) {
+	if c.SecretRotationPeriod < 0 {
+		errs.add("secret_rotation_period", "must not be negative")
+	}
+	switch c.Profile {
+	case "":
+		return
+	case ProfileHardened:
+	default:
+		errs.add("profile", "unsupported profile %q, supported profiles: %s", c.Profile, ProfileHardened)
+		return
+	}
+	if c.Monitoring.Enabled && c.Monitoring.PMM != nil {
+		if c.Monitoring.PMM.TLS.InsecureSkipVerify {
+			errs.add("monitoring.pmm.tls.insecure_skip_verify", "must not be set with the hardened profile")
+		}
+		if u, err := url.Parse(c.Monitoring.PMM.Endpoint); err == nil && u.Scheme == "http" {
+			errs.add("monitoring.pmm.endpoint", "%q must be an https URL with the hardened profile", c.Monitoring.PMM.Endpoint)
+		}
+	}
+	if u, err := url.Parse(c.Vault.Address); err == nil && u.Scheme == "http" {
+		errs.add("vault.address", "%q must be an https URL with the hardened profile", c.Vault.Address)
+	}
+}
+
+func (c *AppConfig) validateDigest(errs *ValidationError) {
+	d := c.Digest
+	if d.WebhookURL != "" {
+		u, err := url.Parse(d.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add("digest.webhook_url", "%q is not an http(s) URL", d.WebhookURL)
+		}
+	}
+	if len(d.Email.To) == 0 {
+		return
+	}
+	if _, _, err := net.SplitHostPort(d.Email.SMTPAddress); err != nil {
+		errs.add("digest.email.smtp_address", "%q is not a host:port address", d.Email.SMTPAddress)
+	}
+	if _, err := mail.ParseAddress(d.Email.From); err != nil {
+		errs.add("digest.email.from", "invalid address %q", d.Email.From)
+	}
+	for i, to := range d.Email.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			errs.add(fmt.Sprintf("digest.email.to[%d]", i), "invalid address %q", to)
+		}
+	}
+}
+
+func (c *AppConfig) validateHTTP(errs *ValidationError) {
+	h := c.HTTP
+	if h.Proxy != "" {
+		u, err := url.Parse(h.Proxy)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			errs.add("http.proxy", "%q is not a proxy URL", h.Proxy)
+		}
+	}
+	if h.CA != "" {
+		if _, err := os.Stat(h.CA); err != nil {
+			errs.add("http.ca", "cannot read %s: %v", h.CA, err)
+		}
+	}
+	if h.Timeout < 0 {
+		errs.add("http.timeout", "must not be negative")
+	}
+	if h.Retries < 0 {
+		errs.add("http.retries", "must not be negative")
+	}
+	if h.RateLimit < 0 {
+		errs.add("http.rate_limit", "must not be negative")
+	}
+}
+
+func (c *AppConfig) validateScheduling(errs *ValidationError) {
+	validateMetadata(errs, "scheduling.node_selector", c.Scheduling.NodeSelector, true)
+	for i, t := range c.Scheduling.Tolerations {
+		field := fmt.Sprintf("scheduling.tolerations[%d]", i)
+		switch t.Operator {
+		case "", string(corev1.TolerationOpEqual):
+			if t.Key == "" {
+				errs.add(field+".key", "is required with the %s operator", corev1.TolerationOpEqual)
+			}
+		case string(corev1.TolerationOpExists):
+			if t.Value != "" {
+				errs.add(field+".value", "must be empty with the %s operator", corev1.TolerationOpExists)
+			}
+		default:
+			errs.add(field+".operator", "unsupported operator %q, supported operators: %s, %s",
+				t.Operator, corev1.TolerationOpEqual, corev1.TolerationOpExists)
+		}
+		switch corev1.TaintEffect(t.Effect) {
+		case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			errs.add(field+".effect", "unsupported effect %q, supported effects: %s, %s, %s", t.Effect,
+				corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute)
+		}
+		if t.TolerationSeconds != nil && corev1.TaintEffect(t.Effect) != corev1.TaintEffectNoExecute {
+			errs.add(field+".toleration_seconds", "is only supported with the %s effect", corev1.TaintEffectNoExecute)
+		}
+	}
+	if _, err := c.Scheduling.ParseAffinity(); err != nil {
+		errs.add("scheduling.affinity", "invalid affinity: %v", err)
+	}
+}
+
+// iamRoleARN matches ARNs of IAM roles in all AWS partitions.
+var iamRoleARN = regexp.MustCompile(`^arn:aws(-[a-z]+)*:iam::[0-9]{12}:role/.+$`)
+
+func (c *AppConfig) validateBackup(errs *ValidationError) {
+	b := c.Backup
+	if b.IRSARoleARN != "" && !iamRoleARN.MatchString(b.IRSARoleARN) {
+		errs.add("backup.irsa_role_arn", "%q is not an IAM role ARN, e.g. arn:aws:iam::123456789012:role/everest-backup", b.IRSARoleARN)
+	}
+	if len(b.ServiceAccounts) != 0 && b.IRSARoleARN == "" {
+		errs.add("backup.service_accounts", "are annotated only with backup.irsa_role_arn")
+	}
+	for i, name := range b.ServiceAccounts {
+		for _, msg := range validation.IsDNS1123Subdomain(name) {
+			errs.add(fmt.Sprintf("backup.service_accounts[%d]", i), "invalid name %q: %s", name, msg)
+		}
+	}
+}
+
+func (c *AppConfig) validatePlacement(errs *ValidationError) {
+	p := c.Placement
+	switch p.Spread {
+	case "", PlacementSpreadAuto, PlacementSpreadZone, PlacementSpreadHostname:
+	case PlacementSpreadNone:
+		if p.Required {
+			errs.add("placement.required", "requires spreading the pods, placement.spread is %s", PlacementSpreadNone)
+		}
+	default:
+		errs.add("placement.spread", "unsupported spread %q, supported spreads: %s, %s, %s, %s", p.Spread,
+			PlacementSpreadAuto, PlacementSpreadZone, PlacementSpreadHostname, PlacementSpreadNone)
+	}
+	if p.MaxSkew < 0 {
+		errs.add("placement.max_skew", "must not be negative")
+	}
+}
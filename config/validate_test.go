@@ -0,0 +1,363 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	replicas := int32(-1)
+	c := &AppConfig{
+		Monitoring: MonitoringConfig{
+			Enabled: true,
+			Type:    MonitoringTypePMM,
+			PMM: &PMMConfig{
+				Endpoint: "pmm.example.com",
+				TLS:      TLSConfig{Cert: "/nonexistent/tls.crt"},
+			},
+		},
+		Cluster:  "prod",
+		Clusters: map[string]ClusterConfig{"staging": {}},
+		OLM: OLMConfig{
+			Components: map[string]OLMComponentConfig{
+				"olm-operator": {Replicas: &replicas, CPU: "lots"},
+			},
+		},
+		Catalog: CatalogConfig{Namespace: "Mirrors"},
+		Global: GlobalConfig{
+			Labels: map[string]string{"team": "db ops"},
+		},
+		ImagePullSecrets: []ImagePullSecretConfig{{Name: "Registry"}},
+	}
+
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := make([]string, 0, len(verr.Errors))
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{
+		"monitoring.pmm.endpoint",
+		"monitoring.pmm",
+		"monitoring.pmm.tls",
+		"monitoring.pmm.tls.cert",
+		"clusters.staging",
+		"cluster",
+		"olm.components.olm-operator.replicas",
+		"olm.components.olm-operator.cpu",
+		"catalog.namespace",
+		"image_pull_secrets[0].name",
+		"image_pull_secrets[0].registry",
+		"image_pull_secrets[0]",
+		"global.labels",
+	}, fields)
+
+	assert.NoError(t, (&AppConfig{}).Validate())
+}
+
+func TestValidateHardenedProfile(t *testing.T) {
+	t.Parallel()
+	c := &AppConfig{
+		Profile: ProfileHardened,
+		Monitoring: MonitoringConfig{
+			Enabled: true,
+			Type:    MonitoringTypePMM,
+			PMM: &PMMConfig{
+				Endpoint:          "http://pmm.example.com",
+				CredentialsSecret: "pmm-credentials",
+				TLS:               TLSConfig{InsecureSkipVerify: true},
+			},
+		},
+		Vault: VaultConfig{Address: "http://vault.example.com:8200"},
+	}
+	c.applyProfile()
+	assert.Equal(t, DefaultSecretRotationPeriod, c.SecretRotationPeriod)
+
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := make([]string, 0, len(verr.Errors))
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{
+		"monitoring.pmm.tls.insecure_skip_verify",
+		"monitoring.pmm.endpoint",
+		"vault.address",
+	}, fields)
+
+	assert.Error(t, (&AppConfig{Profile: "paranoid"}).Validate())
+}
+
+func TestValidateDigest(t *testing.T) {
+	t.Parallel()
+	c := &AppConfig{Digest: DigestConfig{
+		WebhookURL: "hooks.example.com/digest",
+		Email: EmailConfig{
+			SMTPAddress: "smtp.example.com",
+			From:        "everest@example.com",
+			To:          []string{"dba@example.com", "not an address"},
+		},
+	}}
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := make([]string, 0, len(verr.Errors))
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{
+		"digest.webhook_url",
+		"digest.email.smtp_address",
+		"digest.email.to[1]",
+	}, fields)
+}
+
+func TestValidateHTTP(t *testing.T) {
+	t.Parallel()
+	c := &AppConfig{HTTP: HTTPConfig{
+		Proxy:     "proxy.example.com",
+		CA:        "/nonexistent/ca.pem",
+		Retries:   -1,
+		RateLimit: 5,
+	}}
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := make([]string, 0, len(verr.Errors))
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{"http.proxy", "http.ca", "http.retries"}, fields)
+}
+
+func TestValidateScheduling(t *testing.T) {
+	t.Parallel()
+	c := &AppConfig{Scheduling: SchedulingConfig{
+		NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+		Tolerations: []TolerationConfig{
+			{Key: "infra", Operator: "Exists", Effect: "NoSchedule"},
+			{Operator: "Equal", Value: "true"},
+			{Key: "infra", Operator: "In", Effect: "NoRun"},
+		},
+		Affinity: map[string]interface{}{
+			"nodeaffinity": map[string]interface{}{"unknown": true},
+		},
+	}}
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := make([]string, 0, len(verr.Errors))
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{
+		"scheduling.tolerations[1].key",
+		"scheduling.tolerations[2].operator",
+		"scheduling.tolerations[2].effect",
+		"scheduling.affinity",
+	}, fields)
+}
+
+func TestParseAffinity(t *testing.T) {
+	t.Parallel()
+	// Viper lowercases the keys of the configuration.
+	s := SchedulingConfig{Affinity: map[string]interface{}{
+		"nodeaffinity": map[string]interface{}{
+			"requiredduringschedulingignoredduringexecution": map[string]interface{}{
+				"nodeselectorterms": []interface{}{
+					map[string]interface{}{
+						"matchexpressions": []interface{}{
+							map[string]interface{}{"key": "pool", "operator": "In", "values": []interface{}{"infra"}},
+						},
+					},
+				},
+			},
+		},
+	}}
+	affinity, err := s.ParseAffinity()
+	require.NoError(t, err)
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	require.Len(t, terms, 1)
+	assert.Equal(t, []string{"infra"}, terms[0].MatchExpressions[0].Values)
+}
+
+func TestValidateMonitoringResources(t *testing.T) {
+	t.Parallel()
+	c := &AppConfig{Monitoring: MonitoringConfig{Resources: MonitoringResourcesConfig{
+		VMAgent: ResourcesConfig{
+			Requests: ResourceListConfig{CPU: "1", Memory: "350Mi"},
+			Limits:   ResourceListConfig{CPU: "500m", Memory: "lots"},
+		},
+		KubeStateMetrics: ResourcesConfig{Requests: ResourceListConfig{CPU: "10m", Memory: "64Mi"}},
+	}}}
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := make([]string, 0, len(verr.Errors))
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{"monitoring.resources.vmagent.requests.cpu", "monitoring.resources.vmagent.limits.memory"}, fields)
+}
+
+func TestValidatePMMCredentials(t *testing.T) {
+	t.Parallel()
+	pmm := &PMMConfig{Endpoint: "https://pmm.example.com", APIKey: "glsa_token"}
+	c := &AppConfig{Monitoring: MonitoringConfig{Enabled: true, Type: MonitoringTypePMM, PMM: pmm}}
+	assert.NoError(t, c.Validate())
+
+	pmm.CredentialsSecret = "pmm-credentials"
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Len(t, verr.Errors, 1)
+	assert.Equal(t, "monitoring.pmm.api_key", verr.Errors[0].Field)
+}
+
+func TestValidateScrape(t *testing.T) {
+	t.Parallel()
+	c := &AppConfig{Monitoring: MonitoringConfig{Scrape: ScrapeConfig{
+		Interval:             "-30s",
+		PodScrapeSelector:    map[string]string{"team": "db ops"},
+		ExternalLabels:       map[string]string{"environment": "prod", "k8s-cluster": "eu-1"},
+		MemoryAllowedPercent: 140,
+	}}}
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := make([]string, 0, len(verr.Errors))
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{
+		"monitoring.scrape.interval",
+		"monitoring.scrape.pod_scrape_selector",
+		"monitoring.scrape.external_labels",
+		"monitoring.scrape.memory_allowed_percent",
+	}, fields)
+}
+
+func TestValidateKubeStateMetrics(t *testing.T) {
+	t.Parallel()
+	c := &AppConfig{Monitoring: MonitoringConfig{KubeStateMetrics: KubeStateMetricsConfig{
+		Image: "registry.example.com/kube-state-metrics",
+		Tag:   "v2.8.2",
+	}}}
+	assert.NoError(t, c.Validate())
+
+	c.Monitoring.KubeStateMetrics.Tag = "v2.8.2@sha256"
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Len(t, verr.Errors, 1)
+	assert.Equal(t, "monitoring.kube_state_metrics.tag", verr.Errors[0].Field)
+}
+
+func TestValidateAlerts(t *testing.T) {
+	t.Parallel()
+	c := &AppConfig{Monitoring: MonitoringConfig{Alerts: AlertsConfig{
+		Enabled:            true,
+		AlertmanagerURLs:   []string{"http://alertmanager:9093", "alertmanager:9093"},
+		EvaluationInterval: "soon",
+	}}}
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := make([]string, 0, len(verr.Errors))
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{
+		"monitoring.alerts.enabled",
+		"monitoring.alerts.alertmanager_urls",
+		"monitoring.alerts.evaluation_interval",
+	}, fields)
+}
+
+func TestValidateBackend(t *testing.T) {
+	t.Parallel()
+	c := &AppConfig{Backend: BackendConfig{
+		ServiceType: "LoadBalancer",
+		Host:        "everest.example.com",
+		TLSSecret:   "everest-tls",
+	}}
+	assert.NoError(t, c.Validate())
+
+	c.Backend = BackendConfig{ServiceType: "ExternalName", Replicas: -1, TLSSecret: "Everest_TLS"}
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := make([]string, 0, len(verr.Errors))
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{"backend.service_type", "backend.replicas", "backend.tls_secret", "backend.tls_secret"}, fields)
+}
+
+func TestValidateIngress(t *testing.T) {
+	t.Parallel()
+	c := &AppConfig{Ingress: IngressConfig{
+		Controller:     "alb",
+		CertificateARN: "arn:aws:acm:us-east-1:123456789012:certificate/everest",
+		PMM:            IngressPMMConfig{Host: "pmm.example.com", Namespace: "pmm", Service: "monitoring-service", Port: 443},
+	}}
+	assert.NoError(t, c.Validate())
+
+	c.Ingress = IngressConfig{
+		Controller:     "haproxy",
+		Class:          "Public",
+		CertificateARN: "everest",
+		PMM:            IngressPMMConfig{TLSSecret: "pmm-tls", Port: 70000},
+	}
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := make([]string, 0, len(verr.Errors))
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{"ingress.controller", "ingress.certificate_arn", "ingress.pmm.tls_secret", "ingress.pmm.port", "ingress.class"}, fields)
+}
+
+func TestValidateBackup(t *testing.T) {
+	t.Parallel()
+	c := &AppConfig{Backup: BackupConfig{
+		IRSARoleARN:     "everest-backup",
+		ServiceAccounts: []string{"default", "Backup"},
+	}}
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := make([]string, 0, len(verr.Errors))
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{"backup.irsa_role_arn", "backup.service_accounts[1]"}, fields)
+
+	c.Backup = BackupConfig{IRSARoleARN: "arn:aws-us-gov:iam::123456789012:role/everest-backup"}
+	assert.NoError(t, c.Validate())
+	assert.Error(t, (&AppConfig{Backup: BackupConfig{ServiceAccounts: []string{"default"}}}).Validate())
+}
+
+func TestValidatePlacement(t *testing.T) {
+	t.Parallel()
+	c := &AppConfig{Placement: PlacementConfig{Spread: "region", MaxSkew: -1}}
+	err := c.Validate()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := make([]string, 0, len(verr.Errors))
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{"placement.spread", "placement.max_skew"}, fields)
+
+	c.Placement = PlacementConfig{Spread: PlacementSpreadZone, Required: true, MaxSkew: 2}
+	assert.NoError(t, c.Validate())
+	assert.Error(t, (&AppConfig{Placement: PlacementConfig{Spread: PlacementSpreadNone, Required: true}}).Validate())
+}
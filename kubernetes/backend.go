@@ -0,0 +1,229 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AlekSi/pointer"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// BackendName is the name of the deployment and the service of the Everest backend.
+	BackendName = "everest"
+	// DefaultBackendImage is the image of the Everest API server serving the UI.
+	DefaultBackendImage = "percona/percona-everest:0.1.0"
+	// BackendPort is the port the backend and its service listen on.
+	BackendPort = 8080
+
+	backendKubeconfigSuffix = "-kubeconfig"
+	backendKubeconfigKey    = "kubeconfig"
+	backendKubeconfigDir    = "/etc/everest"
+)
+
+// BackendOptions configures the Everest backend deployed by DeployBackend.
+type BackendOptions struct {
+	// Image is the image of the backend, DefaultBackendImage if empty.
+	Image    string
+	Replicas int32
+	// ServiceType of the service exposing the backend: ClusterIP, NodePort or LoadBalancer.
+	ServiceType corev1.ServiceType
+	// Ingress exposes the backend at a host name if it is set. Its name, service and port
+	// are set to the ones of the backend.
+	Ingress *ExposeOptions
+	// ServiceAccount is the service account the kubeconfig authenticates as. The pods of the
+	// backend run as it too.
+	ServiceAccount string
+	// Kubeconfig authenticates the backend to the API server.
+	Kubeconfig string
+}
+
+// DeployBackend deploys the Everest backend, serving the API and the UI, and exposes it with
+// a service of the service type and, if Ingress is set, an Ingress or a Route on OpenShift.
+// The kubeconfig is kept in a secret mounted by the backend. It waits until the deployment
+// has been rolled out.
+func (k *Kubernetes) DeployBackend(ctx context.Context, opts BackendOptions) (Warnings, error) {
+	namespace := k.client.Namespace()
+	warnings, err := k.applyStackObjects(ctx, backendObjects(namespace, opts))
+	if err != nil {
+		return warnings, err
+	}
+	if opts.Ingress != nil {
+		exposeWarnings, err := k.Expose(ctx, backendExposeOptions(*opts.Ingress))
+		warnings.Merge(exposeWarnings)
+		if err != nil {
+			return warnings, err
+		}
+	}
+	key := types.NamespacedName{Namespace: namespace, Name: BackendName}
+	return warnings, classifyError(errors.Wrap(k.client.DoRolloutWait(ctx, key), "Everest backend was not rolled out"))
+}
+
+// backendExposeOptions returns the options exposing the service of the backend.
+func backendExposeOptions(opts ExposeOptions) ExposeOptions {
+	opts.Name = BackendName
+	opts.Namespace = ""
+	opts.Service = BackendName
+	opts.Port = BackendPort
+	opts.BackendHTTPS = false
+	return opts
+}
+
+// BackendURL returns the URL the backend is reachable at: the host of the Ingress or the Route,
+// the address of the load balancer once it has been assigned, a port of a node or the address
+// of the service in the cluster.
+func (k *Kubernetes) BackendURL(ctx context.Context, opts BackendOptions) (string, error) {
+	if opts.Ingress != nil {
+		return k.ExposedURL(*opts.Ingress), nil
+	}
+	namespace := k.client.Namespace()
+	switch opts.ServiceType {
+	case corev1.ServiceTypeLoadBalancer:
+		var address string
+		err := wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+			svc, err := k.client.GetService(ctx, namespace, BackendName)
+			if err != nil {
+				return false, err
+			}
+			for _, ingress := range svc.Status.LoadBalancer.Ingress {
+				address = ingress.Hostname
+				if address == "" {
+					address = ingress.IP
+				}
+				if address != "" {
+					return true, nil
+				}
+			}
+			return false, nil
+		}, ctx.Done())
+		if err != nil {
+			return "", classifyError(errors.Wrap(err, "load balancer of the Everest backend was not assigned an address"))
+		}
+		return fmt.Sprintf("http://%s:%d", address, BackendPort), nil
+	case corev1.ServiceTypeNodePort:
+		svc, err := k.client.GetService(ctx, namespace, BackendName)
+		if err != nil {
+			return "", classifyError(errors.Wrap(err, "cannot get the service of the Everest backend"))
+		}
+		nodes, err := k.client.GetNodes(ctx)
+		if err != nil {
+			return "", classifyError(errors.Wrap(err, "cannot list nodes"))
+		}
+		if address := nodeAddress(nodes.Items); address != "" && len(svc.Spec.Ports) != 0 {
+			return fmt.Sprintf("http://%s:%d", address, svc.Spec.Ports[0].NodePort), nil
+		}
+	}
+	return fmt.Sprintf("http://%s:%d", ServiceHost(BackendName, namespace, k.ClusterDomain(ctx)), BackendPort), nil
+}
+
+// nodeAddress returns the external address of the first node having one or else the
+// internal address of the first node.
+func nodeAddress(nodes []corev1.Node) string {
+	for _, addressType := range []corev1.NodeAddressType{corev1.NodeExternalIP, corev1.NodeInternalIP} {
+		for _, node := range nodes {
+			for _, address := range node.Status.Addresses {
+				if address.Type == addressType {
+					return address.Address
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// backendObjects returns the secret with the kubeconfig, the deployment and the service of
+// the backend.
+func backendObjects(namespace string, opts BackendOptions) []runtime.Object {
+	labels := map[string]string{"app.kubernetes.io/name": BackendName}
+	objectMeta := metav1.ObjectMeta{Name: BackendName, Namespace: namespace, Labels: labels}
+	image := opts.Image
+	if image == "" {
+		image = DefaultBackendImage
+	}
+	replicas := opts.Replicas
+	if replicas == 0 {
+		replicas = 1
+	}
+	serviceType := opts.ServiceType
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeClusterIP
+	}
+	secretName := BackendName + backendKubeconfigSuffix
+	probe := &corev1.Probe{
+		ProbeHandler:        corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(BackendPort)}},
+		InitialDelaySeconds: 5,
+		TimeoutSeconds:      5,
+	}
+	return []runtime.Object{
+		&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace, Labels: labels},
+			Type:       corev1.SecretTypeOpaque,
+			StringData: map[string]string{backendKubeconfigKey: opts.Kubeconfig},
+		},
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+			ObjectMeta: objectMeta,
+			Spec: appsv1.DeploymentSpec{
+				Replicas: pointer.ToInt32(replicas),
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						ServiceAccountName:           opts.ServiceAccount,
+						AutomountServiceAccountToken: pointer.ToBool(false),
+						Containers: []corev1.Container{{
+							Name:  BackendName,
+							Image: image,
+							Env: []corev1.EnvVar{
+								{Name: "KUBECONFIG", Value: backendKubeconfigDir + "/" + backendKubeconfigKey},
+								{Name: "PORT", Value: fmt.Sprint(BackendPort)},
+							},
+							Ports:          []corev1.ContainerPort{{Name: "http", ContainerPort: BackendPort}},
+							LivenessProbe:  probe,
+							ReadinessProbe: probe,
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "kubeconfig", MountPath: backendKubeconfigDir, ReadOnly: true},
+							},
+						}},
+						Volumes: []corev1.Volume{{
+							Name:         "kubeconfig",
+							VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secretName}},
+						}},
+					},
+				},
+			},
+		},
+		&corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: objectMeta,
+			Spec: corev1.ServiceSpec{
+				Type:     serviceType,
+				Selector: labels,
+				Ports:    []corev1.ServicePort{{Name: "http", Port: BackendPort, TargetPort: intstr.FromString("http")}},
+			},
+		},
+	}
+}
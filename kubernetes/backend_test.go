@@ -0,0 +1,121 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestDeployBackend(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	applied := map[string]*unstructured.Unstructured{}
+	k8sclient.On("Namespace").Return("everest")
+	k8sclient.On("ServerSideApply", ctx, mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+		obj := args.Get(1).(*unstructured.Unstructured)
+		applied[obj.GetKind()+"/"+obj.GetName()] = obj
+	})
+	k8sclient.On("DoRolloutWait", ctx, types.NamespacedName{Namespace: "everest", Name: BackendName}).Return(nil)
+
+	opts := BackendOptions{
+		ServiceType: corev1.ServiceTypeNodePort,
+		Ingress: &ExposeOptions{
+			Host:      "everest.example.com",
+			TLSSecret: "everest-tls",
+			Class:     "nginx",
+		},
+		ServiceAccount: "everest-service-account",
+		Kubeconfig:     "apiVersion: v1",
+	}
+	_, err := k.DeployBackend(ctx, opts)
+	require.NoError(t, err)
+	require.Len(t, applied, 4)
+
+	kubeconfig, _, _ := unstructured.NestedString(applied["Secret/everest-kubeconfig"].Object, "stringData", "kubeconfig")
+	assert.Equal(t, "apiVersion: v1", kubeconfig)
+	account, _, _ := unstructured.NestedString(applied["Deployment/everest"].Object, "spec", "template", "spec", "serviceAccountName")
+	assert.Equal(t, "everest-service-account", account)
+	serviceType, _, _ := unstructured.NestedString(applied["Service/everest"].Object, "spec", "type")
+	assert.Equal(t, "NodePort", serviceType)
+	tls, _, _ := unstructured.NestedSlice(applied["Ingress/everest"].Object, "spec", "tls")
+	require.Len(t, tls, 1)
+	assert.Equal(t, "everest-tls", tls[0].(map[string]interface{})["secretName"])
+	assert.Equal(t, "true", applied["Ingress/everest"].GetAnnotations()[nginxAnnotationPrefix+"ssl-redirect"])
+
+	url, err := k.BackendURL(ctx, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "https://everest.example.com", url)
+}
+
+func TestDeployBackendOpenShift(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k.openShift = true
+
+	applied := map[string]*unstructured.Unstructured{}
+	k8sclient.On("Namespace").Return("everest")
+	k8sclient.On("GetSecret", ctx, "everest-tls").Return(&corev1.Secret{Data: map[string][]byte{
+		corev1.TLSCertKey:       []byte("cert"),
+		corev1.TLSPrivateKeyKey: []byte("key"),
+	}}, nil)
+	k8sclient.On("ServerSideApply", ctx, mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+		obj := args.Get(1).(*unstructured.Unstructured)
+		applied[obj.GetKind()+"/"+obj.GetName()] = obj
+	})
+	k8sclient.On("GetService", ctx, "everest", BackendName).Return(&corev1.Service{Spec: corev1.ServiceSpec{
+		Ports: []corev1.ServicePort{{Name: "http", Port: BackendPort, TargetPort: intstr.FromString("http")}},
+	}}, nil)
+	k8sclient.On("DoRolloutWait", ctx, types.NamespacedName{Namespace: "everest", Name: BackendName}).Return(nil)
+
+	_, err := k.DeployBackend(ctx, BackendOptions{Ingress: &ExposeOptions{Host: "everest.apps.example.com", TLSSecret: "everest-tls"}})
+	require.NoError(t, err)
+	assert.NotContains(t, applied, "Ingress/everest")
+	route := applied["Route/everest"]
+	require.NotNil(t, route)
+	cert, _, _ := unstructured.NestedString(route.Object, "spec", "tls", "certificate")
+	assert.Equal(t, "cert", cert)
+	targetPort, _, _ := unstructured.NestedString(route.Object, "spec", "port", "targetPort")
+	assert.Equal(t, "http", targetPort)
+}
+
+func TestNodeAddress(t *testing.T) {
+	t.Parallel()
+	node := func(addresses ...corev1.NodeAddress) corev1.Node {
+		return corev1.Node{Status: corev1.NodeStatus{Addresses: addresses}}
+	}
+	nodes := []corev1.Node{
+		node(corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}),
+		node(corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "10.0.0.2"}, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "203.0.113.2"}),
+	}
+	assert.Equal(t, "203.0.113.2", nodeAddress(nodes))
+	assert.Equal(t, "10.0.0.1", nodeAddress(nodes[:1]))
+	assert.Equal(t, "", nodeAddress(nil))
+}
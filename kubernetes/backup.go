@@ -0,0 +1,223 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client/database"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	databaseClusterBackupKind = "DatabaseClusterBackup"
+
+	// backupStateSucceeded is the state of backups which can be restored.
+	backupStateSucceeded = "Succeeded"
+	// backupStateFailed is the state of backups the operator failed to take.
+	backupStateFailed = "Failed"
+)
+
+// cronFields holds the bounds of the fields of a cron schedule.
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// cronMacros are the predefined schedules supported by the operators.
+var cronMacros = map[string]struct{}{
+	"@yearly": {}, "@annually": {}, "@monthly": {}, "@weekly": {}, "@daily": {}, "@midnight": {}, "@hourly": {},
+}
+
+// BackupFilter selects database cluster backups. Empty fields match all backups.
+type BackupFilter struct {
+	// Cluster is the name of the database cluster the backups were taken of.
+	Cluster string
+	// State is the state of the backups, e.g. Succeeded.
+	State string
+}
+
+// Matches returns true if the backup is selected by the filter.
+func (f BackupFilter) Matches(backup *database.DatabaseClusterBackup) bool {
+	if f.Cluster != "" && backup.Spec.DBClusterName != f.Cluster {
+		return false
+	}
+	if f.State != "" && string(backup.Status.State) != f.State {
+		return false
+	}
+	return true
+}
+
+// ListDatabaseClusterBackups returns the database cluster backups selected by the filter.
+func (k *Kubernetes) ListDatabaseClusterBackups(ctx context.Context, filter BackupFilter) (*database.DatabaseClusterBackupList, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	backups, err := k.client.ListDatabaseClusterBackups(ctx)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, "cannot list database cluster backups"))
+	}
+	items := backups.Items[:0]
+	for i := range backups.Items {
+		if filter.Matches(&backups.Items[i]) {
+			items = append(items, backups.Items[i])
+		}
+	}
+	backups.Items = items
+	return backups, nil
+}
+
+// GetDatabaseClusterBackup returns the database cluster backup by name.
+func (k *Kubernetes) GetDatabaseClusterBackup(ctx context.Context, name string) (*database.DatabaseClusterBackup, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	backup, err := k.client.GetDatabaseClusterBackup(ctx, name)
+	if err != nil {
+		return nil, classifyError(errors.Wrapf(err, "cannot get database cluster backup %s", name))
+	}
+	return backup, nil
+}
+
+// DeleteDatabaseClusterBackup deletes the database cluster backup. The operator removes
+// the backup from its storage.
+func (k *Kubernetes) DeleteDatabaseClusterBackup(ctx context.Context, name string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	backup, err := k.client.GetDatabaseClusterBackup(ctx, name)
+	if err != nil {
+		return classifyError(errors.Wrapf(err, "cannot get database cluster backup %s", name))
+	}
+	backup.TypeMeta.APIVersion = databaseClusterAPIVersion
+	backup.TypeMeta.Kind = databaseClusterBackupKind
+	return classifyError(errors.Wrapf(k.client.DeleteObject(ctx, backup), "cannot delete database cluster backup %s", name))
+}
+
+// SetDatabaseClusterBackupSchedule adds the backup schedule to the database cluster or replaces
+// the schedule of the same name. The storage of the schedule must be configured in the cluster.
+func (k *Kubernetes) SetDatabaseClusterBackupSchedule(ctx context.Context, cluster string, schedule dbaasv1.BackupSchedule) error {
+	if schedule.Name == "" {
+		return errors.New("backup schedule name is required")
+	}
+	if err := validateCronSchedule(schedule.Schedule); err != nil {
+		return err
+	}
+	if schedule.Keep < 0 {
+		return errors.Errorf("invalid number of backups to keep %d", schedule.Keep)
+	}
+	return k.updateBackupSchedules(ctx, cluster, func(spec *dbaasv1.BackupSpec) error {
+		if _, ok := spec.Storages[schedule.StorageName]; !ok {
+			return errors.Errorf("database cluster %s has no backup storage %q", cluster, schedule.StorageName)
+		}
+		for i := range spec.Schedule {
+			if spec.Schedule[i].Name == schedule.Name {
+				spec.Schedule[i] = schedule
+				return nil
+			}
+		}
+		spec.Schedule = append(spec.Schedule, schedule)
+		return nil
+	})
+}
+
+// RemoveDatabaseClusterBackupSchedule removes the backup schedule from the database cluster.
+// Backups taken by the schedule are kept.
+func (k *Kubernetes) RemoveDatabaseClusterBackupSchedule(ctx context.Context, cluster, name string) error {
+	return k.updateBackupSchedules(ctx, cluster, func(spec *dbaasv1.BackupSpec) error {
+		for i := range spec.Schedule {
+			if spec.Schedule[i].Name == name {
+				spec.Schedule = append(spec.Schedule[:i], spec.Schedule[i+1:]...)
+				return nil
+			}
+		}
+		return errors.Errorf("database cluster %s has no backup schedule %q", cluster, name)
+	})
+}
+
+// updateBackupSchedules changes the backup spec of the database cluster and applies the cluster.
+func (k *Kubernetes) updateBackupSchedules(ctx context.Context, name string, update func(spec *dbaasv1.BackupSpec) error) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	cluster, err := k.client.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return classifyError(err)
+	}
+	if cluster.Spec.Backup == nil {
+		cluster.Spec.Backup = &dbaasv1.BackupSpec{}
+	}
+	if err := update(cluster.Spec.Backup); err != nil {
+		return err
+	}
+	cluster.TypeMeta.APIVersion = databaseClusterAPIVersion
+	cluster.TypeMeta.Kind = databaseClusterKind
+	return classifyError(errors.Wrapf(k.client.ApplyObject(ctx, cluster), "cannot update backup schedules of database cluster %s", name))
+}
+
+// validateCronSchedule checks the schedule is a standard five field cron expression or a macro.
+func validateCronSchedule(schedule string) error {
+	if _, ok := cronMacros[schedule]; ok {
+		return nil
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) {
+		return errors.Errorf("invalid cron schedule %q: expected %d fields, got %d", schedule, len(cronFields), len(fields))
+	}
+	for i, field := range fields {
+		bounds := cronFields[i]
+		for _, item := range strings.Split(field, ",") {
+			if err := validateCronItem(item, bounds.min, bounds.max); err != nil {
+				return errors.Wrapf(err, "invalid cron schedule %q: %s", schedule, bounds.name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateCronItem checks an item of a cron field: *, a value or a range, optionally with a step.
+func validateCronItem(item string, min, max int) error {
+	item, step, hasStep := strings.Cut(item, "/")
+	if hasStep {
+		if n, err := strconv.Atoi(step); err != nil || n <= 0 {
+			return errors.Errorf("invalid step %q", step)
+		}
+	}
+	if item == "*" {
+		return nil
+	}
+	low, high, isRange := strings.Cut(item, "-")
+	if !isRange {
+		high = low
+	}
+	from, err := strconv.Atoi(low)
+	if err != nil {
+		return errors.Errorf("invalid value %q", low)
+	}
+	to, err := strconv.Atoi(high)
+	if err != nil {
+		return errors.Errorf("invalid value %q", high)
+	}
+	if from < min || to > max || from > to {
+		return errors.Errorf("%q is out of range %d-%d", item, min, max)
+	}
+	return nil
+}
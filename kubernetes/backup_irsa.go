@@ -0,0 +1,73 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// irsaRoleAnnotation makes the EKS pod identity webhook inject credentials of the
+// IAM role into pods running as the annotated service account.
+const irsaRoleAnnotation = "eks.amazonaws.com/role-arn"
+
+// ConfigureBackupIRSA lets pods running as the service accounts access the backup
+// storage with the IAM role instead of static S3 keys, so no credentials secret is
+// needed. It requires an EKS cluster issuing service account tokens with its OIDC
+// provider; the provider must also be registered in IAM, which cannot be checked
+// from the cluster.
+func (k *Kubernetes) ConfigureBackupIRSA(ctx context.Context, namespace, roleARN string, accounts []string) error {
+	clusterType, err := k.GetClusterType(ctx)
+	if err != nil {
+		return err
+	}
+	if clusterType != ClusterTypeEKS {
+		return errors.Errorf("IAM roles for service accounts are supported only on EKS, the cluster is %s", clusterType)
+	}
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	issuer, err := k.client.GetOIDCIssuer(ctx)
+	if err != nil {
+		return classifyError(errors.Wrap(err, "cannot get the OIDC issuer of the cluster"))
+	}
+	if !strings.Contains(issuer, "oidc.eks.") {
+		return errors.Errorf("service account tokens are issued by %q instead of the EKS OIDC provider", issuer)
+	}
+	for _, account := range accounts {
+		if err := k.annotateServiceAccount(ctx, namespace, account, irsaRoleAnnotation, roleARN); err != nil {
+			return classifyError(err)
+		}
+	}
+	return nil
+}
+
+func (k *Kubernetes) annotateServiceAccount(ctx context.Context, namespace, account, key, value string) error {
+	sa, err := k.client.GetServiceAccount(ctx, namespace, account)
+	if err != nil {
+		return err
+	}
+	if sa.Annotations[key] == value {
+		return nil
+	}
+	if sa.Annotations == nil {
+		sa.Annotations = map[string]string{}
+	}
+	sa.Annotations[key] = value
+	_, err = k.client.UpdateServiceAccount(ctx, sa)
+	return errors.Wrapf(err, "cannot annotate service account %s", account)
+}
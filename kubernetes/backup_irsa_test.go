@@ -0,0 +1,69 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigureBackupIRSA(t *testing.T) {
+	ctx := context.Background()
+	const roleARN = "arn:aws:iam::123456789012:role/everest-backup"
+
+	newClient := func(provisioner, issuer string) (*Kubernetes, *client.MockKubeClientConnector) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("HasAPIGroup", ctx, openShiftSecurityAPIGroup).Return(false, nil)
+		k8sclient.On("GetStorageClasses", ctx).Return(&storagev1.StorageClassList{
+			Items: []storagev1.StorageClass{{Provisioner: provisioner}},
+		}, nil)
+		k8sclient.On("GetOIDCIssuer", ctx).Return(issuer, nil).Maybe()
+		return k, k8sclient
+	}
+
+	t.Run("annotates the service account", func(t *testing.T) {
+		k, k8sclient := newClient("ebs.csi.aws.com", "https://oidc.eks.eu-west-1.amazonaws.com/id/EXAMPLE")
+		k8sclient.On("GetServiceAccount", ctx, "default", "default").Return(&corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"},
+		}, nil)
+		k8sclient.On("UpdateServiceAccount", ctx, mock.MatchedBy(func(sa *corev1.ServiceAccount) bool {
+			return sa.Annotations[irsaRoleAnnotation] == roleARN
+		})).Return(&corev1.ServiceAccount{}, nil)
+
+		require.NoError(t, k.ConfigureBackupIRSA(ctx, "default", roleARN, []string{"default"}))
+		k8sclient.AssertExpectations(t)
+	})
+
+	t.Run("requires EKS", func(t *testing.T) {
+		k, _ := newClient("pd.csi.storage.gke.io", "")
+		assert.Error(t, k.ConfigureBackupIRSA(ctx, "default", roleARN, []string{"default"}))
+	})
+
+	t.Run("requires the EKS OIDC provider", func(t *testing.T) {
+		k, _ := newClient("ebs.csi.aws.com", "https://kubernetes.default.svc")
+		assert.Error(t, k.ConfigureBackupIRSA(ctx, "default", roleARN, []string{"default"}))
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client/database"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestListDatabaseClusterBackups(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	backup := func(name, cluster, state string) database.DatabaseClusterBackup {
+		b := database.DatabaseClusterBackup{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		b.Spec.DBClusterName = cluster
+		b.Status.State = database.BackupState(state)
+		return b
+	}
+
+	testCases := []struct {
+		name     string
+		filter   BackupFilter
+		expected []string
+	}{
+		{name: "all", filter: BackupFilter{}, expected: []string{"a1", "a2", "b1"}},
+		{name: "cluster", filter: BackupFilter{Cluster: "a"}, expected: []string{"a1", "a2"}},
+		{name: "cluster and state", filter: BackupFilter{Cluster: "a", State: "Succeeded"}, expected: []string{"a1"}},
+		{name: "no match", filter: BackupFilter{Cluster: "c"}, expected: nil},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			k8sclient := &client.MockKubeClientConnector{}
+			k := NewEmpty()
+			k.client = k8sclient
+			k8sclient.On("ListDatabaseClusterBackups", ctx).Return(&database.DatabaseClusterBackupList{
+				Items: []database.DatabaseClusterBackup{
+					backup("a1", "a", "Succeeded"),
+					backup("a2", "a", "Failed"),
+					backup("b1", "b", "Succeeded"),
+				},
+			}, nil)
+
+			backups, err := k.ListDatabaseClusterBackups(ctx, tc.filter)
+			require.NoError(t, err)
+			var names []string
+			for _, b := range backups.Items {
+				names = append(names, b.Name)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestValidateCronSchedule(t *testing.T) {
+	t.Parallel()
+	for _, schedule := range []string{"0 2 * * *", "*/15 * * * 1-5", "0 0,12 1 */2 *", "@daily"} {
+		assert.NoError(t, validateCronSchedule(schedule), schedule)
+	}
+	for _, schedule := range []string{"", "0 2 * *", "60 * * * *", "0 5-2 * * *", "*/0 * * * *", "@sometimes"} {
+		assert.Error(t, validateCronSchedule(schedule), schedule)
+	}
+}
+
+func TestSetDatabaseClusterBackupSchedule(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	cluster := &dbaasv1.DatabaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "mysql"}}
+	cluster.Spec.Backup = &dbaasv1.BackupSpec{
+		Storages: map[string]*dbaasv1.BackupStorageSpec{"s3": {}},
+		Schedule: []dbaasv1.BackupSchedule{
+			{Name: "daily", Enabled: true, Schedule: "0 2 * * *", StorageName: "s3", Keep: 3},
+		},
+	}
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster, nil)
+	k8sclient.On("ApplyObject", mock.Anything, cluster).Return(nil)
+
+	daily := dbaasv1.BackupSchedule{Name: "daily", Enabled: true, Schedule: "0 3 * * *", StorageName: "s3", Keep: 7}
+	require.NoError(t, k.SetDatabaseClusterBackupSchedule(ctx, "mysql", daily))
+	assert.Equal(t, []dbaasv1.BackupSchedule{daily}, cluster.Spec.Backup.Schedule)
+
+	err := k.SetDatabaseClusterBackupSchedule(ctx, "mysql", dbaasv1.BackupSchedule{Name: "weekly", Schedule: "@weekly", StorageName: "gcs"})
+	assert.ErrorContains(t, err, `no backup storage "gcs"`)
+
+	require.NoError(t, k.RemoveDatabaseClusterBackupSchedule(ctx, "mysql", "daily"))
+	assert.Empty(t, cluster.Spec.Backup.Schedule)
+	k8sclient.AssertNumberOfCalls(t, "ApplyObject", 2)
+}
@@ -0,0 +1,252 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// StepCertManager is the provisioning step installing cert-manager and the certificate issuer.
+	StepCertManager = "cert-manager"
+	// CertManagerIssuer issues the TLS certificates of database clusters.
+	CertManagerIssuer = "everest-ca-issuer"
+
+	certManagerAPIGroup   = "cert-manager.io"
+	certManagerAPIVersion = "cert-manager.io/v1"
+	certManagerNamespace  = "operators"
+	certManagerPackage    = "cert-manager"
+	certManagerChannel    = "stable"
+	operatorHubCatalog    = "operatorhubio-catalog"
+
+	selfSignedIssuer = "everest-selfsigned-issuer"
+	caCertificate    = "everest-ca"
+
+	// certIssuerAnnotationKey marks database clusters using certificates issued by cert-manager.
+	certIssuerAnnotationKey = "dbaas.percona.com/cert-manager-issuer"
+)
+
+var (
+	certManagerCRDs = []string{"certificates.cert-manager.io", "issuers.cert-manager.io"}
+
+	issuerGVR = schema.GroupVersionResource{Group: certManagerAPIGroup, Version: "v1", Resource: "issuers"}
+
+	// databaseClusterServices lists the suffixes of the services of database clusters by engine.
+	// The certificates of a cluster are valid for the services and their pods.
+	databaseClusterServices = map[dbaasv1.EngineType][]string{
+		"pxc":   {"pxc", "proxysql", "haproxy", "haproxy-replicas"},
+		"psmdb": {"rs0", "mongos", "cfg"},
+	}
+)
+
+// EnsureCertManager installs cert-manager from the OperatorHub catalog unless it is
+// installed already. On OpenShift cert-manager has to be installed beforehand.
+func (k *Kubernetes) EnsureCertManager(ctx context.Context) error {
+	installed, err := k.client.HasAPIGroup(ctx, certManagerAPIGroup)
+	if err != nil {
+		return classifyError(errors.Wrap(err, "cannot check whether cert-manager is installed"))
+	}
+	if installed {
+		k.l.Debug("cert-manager is installed already")
+		return nil
+	}
+	if k.openShift {
+		return errors.New("cert-manager is not installed, install the cert-manager Operator for Red Hat OpenShift first")
+	}
+	// The global operators group created by OLM in the namespace is used.
+	_, err = k.installOperator(ctx, InstallOperatorRequest{
+		Namespace:              certManagerNamespace,
+		Name:                   certManagerPackage,
+		CatalogSource:          operatorHubCatalog,
+		CatalogSourceNamespace: "olm",
+		Channel:                certManagerChannel,
+		InstallPlanApproval:    v1alpha1.ApprovalManual,
+	})
+	if err != nil {
+		return classifyError(errors.Wrap(err, "cannot install cert-manager"))
+	}
+	for _, crd := range certManagerCRDs {
+		if err := k.client.DoCRDWait(ctx, crd); err != nil {
+			return classifyError(errors.Wrapf(err, "cannot wait for %s CRD", crd))
+		}
+	}
+	return nil
+}
+
+// CreateCertificateIssuer creates a CA issuer in the namespace signing the certificates
+// of database clusters. The CA certificate is self-signed.
+func (k *Kubernetes) CreateCertificateIssuer(ctx context.Context, namespace string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	selfSigned := certManagerObject("Issuer", namespace, selfSignedIssuer)
+	selfSigned.Object["spec"] = map[string]interface{}{"selfSigned": map[string]interface{}{}}
+	ca := certManagerObject("Certificate", namespace, caCertificate)
+	ca.Object["spec"] = map[string]interface{}{
+		"isCA":       true,
+		"commonName": caCertificate,
+		"secretName": caCertificate,
+		"privateKey": map[string]interface{}{"algorithm": "ECDSA", "size": int64(256)},
+		"issuerRef":  map[string]interface{}{"name": selfSignedIssuer, "kind": "Issuer", "group": certManagerAPIGroup},
+	}
+	issuer := certManagerObject("Issuer", namespace, CertManagerIssuer)
+	issuer.Object["spec"] = map[string]interface{}{"ca": map[string]interface{}{"secretName": caCertificate}}
+
+	for _, obj := range []*unstructured.Unstructured{selfSigned, ca, issuer} {
+		// The cert-manager webhook rejects requests until it is started.
+		var applyErr error
+		err := wait.PollImmediate(pollInterval, pollDuration, func() (bool, error) {
+			applyErr = k.client.ApplyObject(ctx, obj)
+			return applyErr == nil, nil
+		})
+		if err != nil {
+			return classifyError(errors.Wrapf(applyErr, "cannot create %s %s", obj.GetKind(), obj.GetName()))
+		}
+	}
+	return nil
+}
+
+// CertificateIssuerExists returns true if the issuer of database cluster certificates
+// exists in the namespace, i.e. the cluster was provisioned with cert-manager.
+func (k *Kubernetes) CertificateIssuerExists(ctx context.Context, namespace string) (bool, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	installed, err := k.client.HasAPIGroup(ctx, certManagerAPIGroup)
+	if err != nil || !installed {
+		return false, classifyError(err)
+	}
+	issuers, err := k.client.ListCRs(ctx, namespace, issuerGVR, nil)
+	if err != nil {
+		return false, classifyError(errors.Wrap(err, "cannot list certificate issuers"))
+	}
+	for _, issuer := range issuers.Items {
+		if issuer.GetName() == CertManagerIssuer {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CreateDatabaseClusterCertificates requests the certificates of the database cluster
+// from the issuer. The engine operators pick up the secrets named <cluster>-ssl and
+// <cluster>-ssl-internal instead of generating their own certificates, so the
+// certificates have to be created before the cluster.
+func (k *Kubernetes) CreateDatabaseClusterCertificates(ctx context.Context, cluster *dbaasv1.DatabaseCluster) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	certs, err := DatabaseClusterCertificates(cluster, k.clusterNamespace(cluster), k.getClusterDomain(ctx))
+	if err != nil {
+		return err
+	}
+	for _, cert := range certs {
+		if err := k.client.ApplyObject(ctx, cert); err != nil {
+			return classifyError(errors.Wrapf(err, "cannot create certificate %s", cert.GetName()))
+		}
+	}
+	if cluster.Annotations == nil {
+		cluster.Annotations = make(map[string]string)
+	}
+	cluster.Annotations[certIssuerAnnotationKey] = CertManagerIssuer
+	return nil
+}
+
+// deleteDatabaseClusterCertificates deletes the certificates of the database cluster
+// and their secrets if they were issued by cert-manager.
+func (k *Kubernetes) deleteDatabaseClusterCertificates(ctx context.Context, cluster *dbaasv1.DatabaseCluster) error {
+	if _, ok := cluster.Annotations[certIssuerAnnotationKey]; !ok {
+		return nil
+	}
+	certs, err := DatabaseClusterCertificates(cluster, k.clusterNamespace(cluster), k.getClusterDomain(ctx))
+	if err != nil {
+		return err
+	}
+	for _, cert := range certs {
+		err := k.client.DeleteObject(ctx, cert)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "cannot delete certificate %s", cert.GetName())
+		}
+		secretName, _, _ := unstructured.NestedString(cert.Object, "spec", "secretName")
+		secret := &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: cert.GetNamespace()},
+		}
+		if err := k.client.DeleteObject(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "cannot delete secret %s", secretName)
+		}
+	}
+	return nil
+}
+
+// DatabaseClusterCertificates returns the certificate requests of the external and
+// internal TLS secrets of the database cluster in the namespace and cluster domain.
+func DatabaseClusterCertificates(cluster *dbaasv1.DatabaseCluster, namespace, domain string) ([]*unstructured.Unstructured, error) {
+	services, ok := databaseClusterServices[cluster.Spec.Database]
+	if !ok {
+		return nil, errors.Errorf("TLS certificates are not supported for %q database engine", cluster.Spec.Database)
+	}
+	var dnsNames []interface{}
+	for _, suffix := range services {
+		service := fmt.Sprintf("%s-%s", cluster.Name, suffix)
+		for _, host := range []string{service, service + "." + namespace, service + "." + namespace + ".svc", ServiceHost(service, namespace, domain)} {
+			dnsNames = append(dnsNames, host, "*."+host)
+		}
+	}
+	commonName := fmt.Sprintf("%s-%s", cluster.Name, services[0])
+	certs := make([]*unstructured.Unstructured, 0, 2)
+	for _, secretName := range []string{cluster.Name + "-ssl", cluster.Name + "-ssl-internal"} {
+		cert := certManagerObject("Certificate", namespace, secretName)
+		cert.SetLabels(map[string]string{instanceLabelKey: cluster.Name})
+		cert.Object["spec"] = map[string]interface{}{
+			"secretName": secretName,
+			"commonName": commonName,
+			"dnsNames":   dnsNames,
+			"issuerRef":  map[string]interface{}{"name": CertManagerIssuer, "kind": "Issuer", "group": certManagerAPIGroup},
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// clusterNamespace returns the namespace of the database cluster, the namespace
+// of the client if it is not set.
+func (k *Kubernetes) clusterNamespace(cluster *dbaasv1.DatabaseCluster) string {
+	if cluster.Namespace != "" {
+		return cluster.Namespace
+	}
+	return k.client.Namespace()
+}
+
+func certManagerObject(kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetAPIVersion(certManagerAPIVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDatabaseClusterCertificates(t *testing.T) {
+	cluster := &dbaasv1.DatabaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "db"},
+		Spec:       dbaasv1.DatabaseSpec{Database: "pxc"},
+	}
+	certs, err := DatabaseClusterCertificates(cluster, "default", "example.internal")
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+	assert.Equal(t, "db-ssl", certs[0].GetName())
+	assert.Equal(t, "db-ssl-internal", certs[1].GetName())
+
+	dnsNames, _, _ := unstructured.NestedStringSlice(certs[0].Object, "spec", "dnsNames")
+	assert.Contains(t, dnsNames, "db-pxc")
+	assert.Contains(t, dnsNames, "*.db-pxc.default.svc.example.internal")
+	assert.Contains(t, dnsNames, "db-haproxy.default")
+	issuer, _, _ := unstructured.NestedString(certs[1].Object, "spec", "issuerRef", "name")
+	assert.Equal(t, CertManagerIssuer, issuer)
+
+	cluster.Spec.Database = "pg"
+	_, err = DatabaseClusterCertificates(cluster, "default", DefaultClusterDomain)
+	assert.Error(t, err)
+}
+
+func TestCreateDatabaseClusterCertificates(t *testing.T) {
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k8sclient.On("Namespace").Return("default")
+	k.SetClusterDomain("example.internal")
+	k8sclient.On("ApplyObject", mock.Anything, mock.AnythingOfType("*unstructured.Unstructured")).Return(nil)
+
+	cluster := &dbaasv1.DatabaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "db"},
+		Spec:       dbaasv1.DatabaseSpec{Database: "psmdb"},
+	}
+	require.NoError(t, k.CreateDatabaseClusterCertificates(context.Background(), cluster))
+	assert.Equal(t, CertManagerIssuer, cluster.Annotations[certIssuerAnnotationKey])
+	k8sclient.AssertNumberOfCalls(t, "ApplyObject", 2)
+}
+
+func TestEnsureCertManager(t *testing.T) {
+	t.Run("installed", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("HasAPIGroup", mock.Anything, certManagerAPIGroup).Return(true, nil)
+
+		require.NoError(t, k.EnsureCertManager(context.Background()))
+		k8sclient.AssertNotCalled(t, "CreateSubscriptionForCatalog")
+	})
+
+	t.Run("openshift", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k.openShift = true
+		k8sclient.On("HasAPIGroup", mock.Anything, certManagerAPIGroup).Return(false, nil)
+
+		assert.Error(t, k.EnsureCertManager(context.Background()))
+	})
+}
@@ -0,0 +1,195 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Audit actions of the mutating requests.
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionPatch  = "patch"
+	AuditActionApply  = "apply"
+	AuditActionDelete = "delete"
+)
+
+// auditIgnoredResources are created to ask the API server a question rather than to change the cluster.
+var auditIgnoredResources = map[string]bool{
+	"selfsubjectaccessreviews.authorization.k8s.io":  true,
+	"selfsubjectrulesreviews.authorization.k8s.io":   true,
+	"subjectaccessreviews.authorization.k8s.io":      true,
+	"localsubjectaccessreviews.authorization.k8s.io": true,
+	"tokenreviews.authentication.k8s.io":             true,
+}
+
+// AuditRecord describes a mutating request sent to the API server.
+type AuditRecord struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	// Resource is the resource of the URL, e.g. deployments.apps or pods/exec.
+	Resource string `json:"resource"`
+	// Kind of the object sent, empty for deletes and patches not carrying it.
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	// Status is the HTTP status of the response, zero if the request failed without one.
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Succeeded returns true if the API server accepted the request.
+func (r AuditRecord) Succeeded() bool {
+	return r.Error == "" && r.Status >= http.StatusOK && r.Status < http.StatusMultipleChoices
+}
+
+// AuditFunc receives the records of the mutating requests. It is called synchronously after
+// the response has been received.
+type AuditFunc func(AuditRecord)
+
+// auditTransport reports the mutating requests to the auditor, if it is set.
+type auditTransport struct {
+	next    http.RoundTripper
+	auditor *atomic.Pointer[AuditFunc]
+}
+
+// newAuditTransport returns a transport reporting mutating requests to the auditor.
+func newAuditTransport(next http.RoundTripper, auditor *atomic.Pointer[AuditFunc]) *auditTransport {
+	return &auditTransport{next: next, auditor: auditor}
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	audit := t.auditor.Load()
+	if audit == nil {
+		return t.next.RoundTrip(req)
+	}
+	record, ok := auditRecord(req)
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		record.Status = resp.StatusCode
+	}
+	record.Time = time.Now().UTC()
+	(*audit)(record)
+	return resp, err
+}
+
+// auditRecord returns the record of a mutating request to a resource. Dry runs and reviews
+// are not recorded.
+func auditRecord(req *http.Request) (AuditRecord, bool) {
+	var record AuditRecord
+	switch req.Method {
+	case http.MethodPost:
+		record.Action = AuditActionCreate
+	case http.MethodPut:
+		record.Action = AuditActionUpdate
+	case http.MethodPatch:
+		record.Action = AuditActionPatch
+		if strings.HasPrefix(req.Header.Get("Content-Type"), string(types.ApplyPatchType)) {
+			record.Action = AuditActionApply
+		}
+	case http.MethodDelete:
+		record.Action = AuditActionDelete
+	default:
+		return record, false
+	}
+	if req.URL.Query().Has("dryRun") {
+		return record, false
+	}
+	resource, namespace, name, ok := parseResourcePath(req.URL.Path)
+	if !ok || auditIgnoredResources[resource] {
+		return record, false
+	}
+	record.Resource, record.Namespace, record.Name = resource, namespace, name
+	if kind, objName := requestObject(req); kind != "" {
+		record.Kind = kind
+		if record.Name == "" {
+			record.Name = objName
+		}
+	}
+	return record, true
+}
+
+// parseResourcePath splits a path of the API, e.g. /apis/apps/v1/namespaces/default/deployments/name,
+// into the resource qualified with its group, the namespace and the name. Subresources are
+// appended to the resource, e.g. pods/exec.
+func parseResourcePath(path string) (resource, namespace, name string, ok bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	var group string
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		group = parts[1]
+		parts = parts[3:]
+	default:
+		return "", "", "", false
+	}
+	// Namespaces are a resource themselves, e.g. /api/v1/namespaces/default.
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		namespace = parts[1]
+		parts = parts[2:]
+	}
+	resource = parts[0]
+	if group != "" {
+		resource += "." + group
+	}
+	if len(parts) >= 2 {
+		name = parts[1]
+	}
+	if len(parts) >= 3 {
+		resource += "/" + strings.Join(parts[2:], "/")
+	}
+	return resource, namespace, name, true
+}
+
+// requestObject returns the kind and the name of the object sent in a JSON body of the request.
+func requestObject(req *http.Request) (kind, name string) {
+	if req.GetBody == nil {
+		return "", ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", ""
+	}
+	defer body.Close() //nolint:errcheck
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", ""
+	}
+	var obj struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return "", ""
+	}
+	return obj.Kind, obj.Metadata.Name
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditTransport(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	var records []AuditRecord
+	audit := AuditFunc(func(r AuditRecord) { records = append(records, r) })
+	auditor := &atomic.Pointer[AuditFunc]{}
+	auditor.Store(&audit)
+	client := &http.Client{Transport: newAuditTransport(http.DefaultTransport, auditor)}
+	send := func(method, path, contentType, body string) {
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close() //nolint:errcheck
+	}
+
+	send(http.MethodGet, "/api/v1/namespaces/default/pods", "", "")
+	send(http.MethodPost, "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews", "application/json", "{}")
+	send(http.MethodPost, "/apis/apps/v1/namespaces/default/deployments?dryRun=All", "application/json", "{}")
+	require.Empty(t, records)
+
+	send(http.MethodPost, "/apis/apps/v1/namespaces/default/deployments", "application/json",
+		`{"kind":"Deployment","metadata":{"name":"everest"}}`)
+	send(http.MethodPatch, "/apis/rbac.authorization.k8s.io/v1/clusterroles/everest-viewer", "application/apply-patch+yaml",
+		`{"kind":"ClusterRole","metadata":{"name":"everest-viewer"}}`)
+	send(http.MethodDelete, "/api/v1/namespaces/default/secrets/pmm", "application/json", "")
+	send(http.MethodPost, "/api/v1/namespaces/default/pods/mysql-0/exec", "", "")
+	require.Len(t, records, 4)
+
+	assert.Equal(t, AuditRecord{
+		Time: records[0].Time, Action: AuditActionCreate, Resource: "deployments.apps",
+		Kind: "Deployment", Namespace: "default", Name: "everest", Status: http.StatusOK,
+	}, records[0])
+	assert.True(t, records[0].Succeeded())
+	assert.Equal(t, AuditActionApply, records[1].Action)
+	assert.Equal(t, "clusterroles.rbac.authorization.k8s.io", records[1].Resource)
+	assert.Empty(t, records[1].Namespace)
+	assert.Equal(t, AuditActionDelete, records[2].Action)
+	assert.Equal(t, "pmm", records[2].Name)
+	assert.False(t, records[2].Succeeded())
+	assert.Equal(t, "pods/exec", records[3].Resource)
+	assert.Equal(t, "mysql-0", records[3].Name)
+
+	auditor.Store(nil)
+	send(http.MethodDelete, "/api/v1/namespaces/default/secrets/pmm", "application/json", "")
+	assert.Len(t, records, 4)
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"time"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const databaseClusterResource = "databaseclusters"
+
+// informerCache serves reads of frequently requested objects from shared informers
+// instead of the API server.
+type informerCache struct {
+	namespace   string
+	dbClusters  cache.SharedIndexInformer
+	secrets     corelisters.SecretLister
+	deployments appslisters.DeploymentLister
+}
+
+// StartCache starts shared informers for database clusters, secrets and deployments
+// and waits for them to sync. Once started, the corresponding get and list calls
+// are served from the cache until the context is done.
+func (c *Client) StartCache(ctx context.Context, resync time.Duration) error {
+	dbClusters := c.dbClusterClient.DBClusters(c.namespace)
+	dbClusterInformer := cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				return dbClusters.List(ctx, opts)
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				return dbClusters.Watch(ctx, opts)
+			},
+		},
+		&dbaasv1.DatabaseCluster{},
+		resync,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+	factory := informers.NewSharedInformerFactoryWithOptions(c.clientset, resync, informers.WithNamespace(c.namespace))
+	ic := &informerCache{
+		namespace:   c.namespace,
+		dbClusters:  dbClusterInformer,
+		secrets:     factory.Core().V1().Secrets().Lister(),
+		deployments: factory.Apps().V1().Deployments().Lister(),
+	}
+
+	go dbClusterInformer.Run(ctx.Done())
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), dbClusterInformer.HasSynced) {
+		return errors.New("cannot sync database clusters cache")
+	}
+	for informer, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return errors.Errorf("cannot sync %s cache", informer)
+		}
+	}
+
+	c.cacheLock.Lock()
+	c.cache = ic
+	c.cacheLock.Unlock()
+	go func() {
+		<-ctx.Done()
+		c.cacheLock.Lock()
+		c.cache = nil
+		c.cacheLock.Unlock()
+	}()
+	return nil
+}
+
+// getCache returns the informer cache or nil if it is not started.
+func (c *Client) getCache() *informerCache {
+	if c.cacheLock == nil {
+		return nil
+	}
+	c.cacheLock.RLock()
+	defer c.cacheLock.RUnlock()
+	return c.cache
+}
+
+func (ic *informerCache) getDatabaseCluster(name string) (*dbaasv1.DatabaseCluster, error) {
+	obj, exists, err := ic.dbClusters.GetIndexer().GetByKey(ic.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, apierrors.NewNotFound(dbaasv1.GroupVersion.WithResource(databaseClusterResource).GroupResource(), name)
+	}
+	return obj.(*dbaasv1.DatabaseCluster).DeepCopy(), nil
+}
+
+func (ic *informerCache) listDatabaseClusters() (*dbaasv1.DatabaseClusterList, error) {
+	objs, err := ic.dbClusters.GetIndexer().ByIndex(cache.NamespaceIndex, ic.namespace)
+	if err != nil {
+		return nil, err
+	}
+	list := &dbaasv1.DatabaseClusterList{}
+	for _, obj := range objs {
+		list.Items = append(list.Items, *obj.(*dbaasv1.DatabaseCluster).DeepCopy())
+	}
+	return list, nil
+}
+
+func (ic *informerCache) getSecret(name string) (*corev1.Secret, error) {
+	secret, err := ic.secrets.Secrets(ic.namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return secret.DeepCopy(), nil
+}
+
+func (ic *informerCache) listSecrets() (*corev1.SecretList, error) {
+	secrets, err := ic.secrets.Secrets(ic.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	list := &corev1.SecretList{}
+	for _, secret := range secrets {
+		list.Items = append(list.Items, *secret.DeepCopy())
+	}
+	return list, nil
+}
+
+func (ic *informerCache) getDeployment(name string) (*appsv1.Deployment, error) {
+	deployment, err := ic.deployments.Deployments(ic.namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return deployment.DeepCopy(), nil
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+const dbClustersPath = "/apis/dbaas.percona.com/v1/namespaces/default/databaseclusters"
+
+// dbaasServer serves the dbaas.percona.com API with a single database cluster and
+// counts the requests reading it.
+func dbaasServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	cluster := `{"apiVersion":"dbaas.percona.com/v1","kind":"DatabaseCluster",` +
+		`"metadata":{"name":"db","namespace":"default","resourceVersion":"1"},"spec":{"databaseType":"pxc"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api":
+			_, _ = w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		case "/apis":
+			_, _ = w.Write([]byte(`{"kind":"APIGroupList","apiVersion":"v1","groups":[{"name":"dbaas.percona.com",` +
+				`"versions":[{"groupVersion":"dbaas.percona.com/v1","version":"v1"}],` +
+				`"preferredVersion":{"groupVersion":"dbaas.percona.com/v1","version":"v1"}}]}`))
+		case dbClustersPath:
+			if r.URL.Query().Get("watch") == "true" {
+				w.WriteHeader(http.StatusOK)
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+				return
+			}
+			requests.Add(1)
+			_, _ = w.Write([]byte(`{"apiVersion":"dbaas.percona.com/v1","kind":"DatabaseClusterList",` +
+				`"metadata":{"resourceVersion":"1"},"items":[` + cluster + `]}`))
+		case dbClustersPath + "/db":
+			requests.Add(1)
+			_, _ = w.Write([]byte(cluster))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStartCache(t *testing.T) {
+	t.Parallel()
+	var requests atomic.Int32
+	server := dbaasServer(t, &requests)
+	dbClusterClient, err := database.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "pmm", Namespace: "default"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "dbaas-operator", Namespace: "default"}},
+	)
+	c := &Client{
+		clientset:       clientset,
+		dbClusterClient: dbClusterClient,
+		namespace:       "default",
+		cacheLock:       &sync.RWMutex{},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, c.StartCache(ctx, 0))
+	clientset.ClearActions()
+	requests.Store(0)
+
+	// Reads are served from the cache.
+	secret, err := c.GetSecret(ctx, "pmm")
+	require.NoError(t, err)
+	assert.Equal(t, "pmm", secret.Name)
+	secrets, err := c.ListSecrets(ctx)
+	require.NoError(t, err)
+	assert.Len(t, secrets.Items, 1)
+	deployment, err := c.GetDeployment(ctx, "dbaas-operator")
+	require.NoError(t, err)
+	assert.Equal(t, "dbaas-operator", deployment.Name)
+	cluster, err := c.GetDatabaseCluster(ctx, "db")
+	require.NoError(t, err)
+	assert.Equal(t, "pxc", string(cluster.Spec.Database))
+	clusters, err := c.ListDatabaseClusters(ctx)
+	require.NoError(t, err)
+	assert.Len(t, clusters.Items, 1)
+	_, err = c.GetDatabaseCluster(ctx, "missing")
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.Empty(t, clientset.Actions())
+	assert.Zero(t, requests.Load())
+
+	// Objects returned by the cache are copies.
+	secret.Labels = map[string]string{"changed": "true"}
+	secret, err = c.GetSecret(ctx, "pmm")
+	require.NoError(t, err)
+	assert.Empty(t, secret.Labels)
+
+	// Reads fall back to the API once the context is done.
+	cancel()
+	require.Eventually(t, func() bool { return c.getCache() == nil }, time.Second, 10*time.Millisecond)
+	_, err = c.GetSecret(context.Background(), "pmm")
+	require.NoError(t, err)
+	_, err = c.GetDeployment(context.Background(), "dbaas-operator")
+	require.NoError(t, err)
+	assert.Len(t, clientset.Actions(), 2)
+	_, err = c.GetDatabaseCluster(context.Background(), "db")
+	require.NoError(t, err)
+	_, err = c.ListDatabaseClusters(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+}
@@ -23,11 +23,12 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
+	"net/http"
 	"os"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"text/tabwriter"
 	"time"
 
@@ -38,10 +39,15 @@ import (
 	v1 "github.com/operator-framework/api/pkg/operators/v1"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned"
+	packagev1 "github.com/operator-framework/operator-lifecycle-manager/pkg/package-server/apis/operators/v1"
+	packageclient "github.com/operator-framework/operator-lifecycle-manager/pkg/package-server/client/clientset/versioned"
 	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -50,14 +56,17 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	yamlSerializer "k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
 	"k8s.io/apimachinery/pkg/util/wait"
 	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -80,14 +89,20 @@ const (
 
 	dbaasToolPath = "/opt/dbaas-tools/bin"
 
+	// inClusterUser is the user name of kubeconfigs generated for the service account of the pod.
+	inClusterUser = "everest-provisioner"
+
 	defaultQPSLimit   = 100
 	defaultBurstLimit = 150
 	defaultChunkSize  = 500
-
-	defaultAPIURIPath  = "/api"
-	defaultAPIsURIPath = "/apis"
 )
 
+// FieldManager owns the fields of the objects applied with server-side apply.
+const FieldManager = "everest-provisioner"
+
+// l logs the progress of long running waits.
+var l = logrus.WithField("component", "client")
+
 // Each level has 2 spaces for PrefixWriter
 //
 //nolint:stylecheck
@@ -107,7 +122,18 @@ type Client struct {
 	dbClusterClient  *database.DatabaseClusterClient
 	rcLock           *sync.Mutex
 	restConfig       *rest.Config
-	namespace        string
+	// retries switches repeating of requests failed with transient errors.
+	retries *atomic.Bool
+	// auditor receives the records of the mutating requests if it is set.
+	auditor *atomic.Pointer[AuditFunc]
+	// threeWayMerge is set once the API server rejected a server-side apply, ApplyObject
+	// uses ThreeWayMerge from then on.
+	threeWayMerge *atomic.Bool
+	namespace     string
+	cacheLock     *sync.RWMutex
+	cache         *informerCache
+	labels        map[string]string
+	annotations   map[string]string
 }
 
 // SortableEvents implements sort.Interface for []api.Event based on the Timestamp field
@@ -160,7 +186,14 @@ func (e podErrors) Error() string {
 	return sb.String()
 }
 
+// NewFromKubeConfig returns a client for the current context of the kubeconfig file.
 func NewFromKubeConfig(kubeconfig string) (*Client, error) {
+	return NewFromKubeConfigContext(kubeconfig, "")
+}
+
+// NewFromKubeConfigContext returns a client for the named context of the kubeconfig file.
+// The current context of the kubeconfig is used if contextName is empty.
+func NewFromKubeConfigContext(kubeconfig, contextName string) (*Client, error) {
 	home := os.Getenv("HOME")
 	path := strings.ReplaceAll(kubeconfig, "~", home)
 	fileData, err := ioutil.ReadFile(path)
@@ -168,13 +201,53 @@ func NewFromKubeConfig(kubeconfig string) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	config, err := clientcmd.RESTConfigFromKubeConfig(fileData)
+	client, err := NewFromKubeConfigData(fileData, contextName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load %s", kubeconfig)
+	}
+	return client, nil
+}
+
+// NewFromKubeConfigData returns a client for the named context of the kubeconfig passed as a string.
+// The current context of the kubeconfig is used if contextName is empty.
+func NewFromKubeConfigData(kubeconfig []byte, contextName string) (*Client, error) {
+	apiConfig, err := NewConfigGetter(string(kubeconfig)).loadFromString()
+	if err != nil {
+		return nil, err
+	}
+	if contextName != "" {
+		if _, ok := apiConfig.Contexts[contextName]; !ok {
+			return nil, errors.Errorf("context %q does not exist", contextName)
+		}
+	}
+	config, err := clientcmd.NewNonInteractiveClientConfig(*apiConfig, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
 	if err != nil {
 		return nil, err
 	}
+	return newForConfig(config)
+}
 
+// NewInCluster returns a client authenticating with the service account of the pod it runs in.
+func NewInCluster() (*Client, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	return newForConfig(config)
+}
+
+func newForConfig(config *rest.Config) (*Client, error) {
 	config.QPS = defaultQPSLimit
 	config.Burst = defaultBurstLimit
+	retries := &atomic.Bool{}
+	retries.Store(true)
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return newRetryTransport(rt, retries)
+	})
+	auditor := &atomic.Pointer[AuditFunc]{}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return newAuditTransport(rt, auditor)
+	})
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, err
@@ -192,11 +265,14 @@ func NewFromKubeConfig(kubeconfig string) (*Client, error) {
 		apiextClientset:  apiextClientset,
 		dynamicClientset: dynamicClientset,
 		restConfig:       config,
+		retries:          retries,
+		auditor:          auditor,
+		threeWayMerge:    &atomic.Bool{},
 		rcLock:           &sync.Mutex{},
+		cacheLock:        &sync.RWMutex{},
 	}
 	err = c.setup()
 	return c, err
-
 }
 
 func (c *Client) setup() error {
@@ -218,10 +294,65 @@ func (c *Client) initOperatorClients() error {
 		return err
 	}
 	c.dbClusterClient = dbClusterClient
-	_, err = c.GetServerVersion()
+	_, err = c.GetServerVersion(context.Background())
 	return err
 }
 
+// Namespace returns the namespace used for namespaced resources
+func (c *Client) Namespace() string {
+	return c.namespace
+}
+
+// SetCommonMetadata sets labels and annotations added to every object created or applied by the client.
+func (c *Client) SetCommonMetadata(labels, annotations map[string]string) {
+	c.labels = labels
+	c.annotations = annotations
+}
+
+// SetRetries switches repeating of requests failed with transient errors such as
+// timeouts, throttling and etcd leader changes. Retries are enabled by default.
+func (c *Client) SetRetries(enabled bool) {
+	if c.retries != nil {
+		c.retries.Store(enabled)
+	}
+}
+
+// SetAuditor sets the function receiving the records of the mutating requests. Requests are
+// not recorded if it is nil.
+func (c *Client) SetAuditor(auditor AuditFunc) {
+	if c.auditor == nil {
+		return
+	}
+	if auditor == nil {
+		c.auditor.Store(nil)
+		return
+	}
+	c.auditor.Store(&auditor)
+}
+
+// addCommonMetadata adds the common labels and annotations to the object.
+// Values already set on the object take precedence.
+func (c *Client) addCommonMetadata(obj metav1.Object) {
+	if len(c.labels) != 0 {
+		obj.SetLabels(mergeMetadata(obj.GetLabels(), c.labels))
+	}
+	if len(c.annotations) != 0 {
+		obj.SetAnnotations(mergeMetadata(obj.GetAnnotations(), c.annotations))
+	}
+}
+
+func mergeMetadata(current, common map[string]string) map[string]string {
+	if current == nil {
+		current = make(map[string]string, len(common))
+	}
+	for k, v := range common {
+		if _, ok := current[k]; !ok {
+			current[k] = v
+		}
+	}
+	return current
+}
+
 // GetSecretsForServiceAccount returns secret by given service account name
 func (c *Client) GetSecretsForServiceAccount(ctx context.Context, accountName string) (*corev1.Secret, error) {
 	serviceAccount, err := c.clientset.CoreV1().ServiceAccounts(c.namespace).Get(ctx, accountName, metav1.GetOptions{})
@@ -241,6 +372,11 @@ func (c *Client) GetSecretsForServiceAccount(ctx context.Context, accountName st
 
 // GenerateKubeConfig generates kubeconfig
 func (c *Client) GenerateKubeConfig(secret *corev1.Secret) ([]byte, error) {
+	return c.GenerateKubeConfigWithToken("pmm-service-account", secret)
+}
+
+// GenerateKubeConfigWithToken generates kubeconfig for the user authenticating with the service account token secret.
+func (c *Client) GenerateKubeConfigWithToken(user string, secret *corev1.Secret) ([]byte, error) {
 	conf := &Config{
 		Kind:           configKind,
 		APIVersion:     apiVersion,
@@ -260,14 +396,14 @@ func (c *Client) GenerateKubeConfig(secret *corev1.Secret) ([]byte, error) {
 			Name: defaultName,
 			Context: Context{
 				Cluster:   defaultName,
-				User:      "pmm-service-account",
+				User:      user,
 				Namespace: defaultName,
 			},
 		},
 	}
 	conf.Users = []UserInfo{
 		{
-			Name: "pmm-service-account",
+			Name: user,
 			User: User{
 				Token: string(secret.Data["token"]),
 			},
@@ -277,18 +413,65 @@ func (c *Client) GenerateKubeConfig(secret *corev1.Secret) ([]byte, error) {
 	return c.marshalKubeConfig(conf)
 }
 
-// GetServerVersion returns server version
-func (c *Client) GetServerVersion() (*version.Info, error) {
+// GenerateInClusterKubeConfig generates kubeconfig for the service account of the pod the client runs in.
+func (c *Client) GenerateInClusterKubeConfig() ([]byte, error) {
+	token, err := os.ReadFile(c.restConfig.BearerTokenFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read service account token")
+	}
+	ca, err := os.ReadFile(c.restConfig.TLSClientConfig.CAFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read cluster CA certificate")
+	}
+	return c.GenerateKubeConfigWithToken(inClusterUser, &corev1.Secret{
+		Data: map[string][]byte{"token": token, "ca.crt": ca},
+	})
+}
+
+// GetServerVersion returns server version.
+// The discovery client does not take a context, so the context is only checked before the request.
+func (c *Client) GetServerVersion(ctx context.Context) (*version.Info, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return c.clientset.Discovery().ServerVersion()
 }
 
+// InsecureTLS returns true if the certificate of the API server is not verified.
+func (c *Client) InsecureTLS() bool {
+	return c.restConfig != nil && c.restConfig.Insecure
+}
+
+// HasAPIGroup returns true if the API server serves the API group.
+func (c *Client) HasAPIGroup(ctx context.Context, name string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	groups, err := c.clientset.Discovery().ServerGroups()
+	if err != nil {
+		return false, err
+	}
+	for _, group := range groups.Groups {
+		if group.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // ListDatabaseClusters returns list of managed PCX clusters.
 func (c *Client) ListDatabaseClusters(ctx context.Context) (*dbaasv1.DatabaseClusterList, error) {
+	if ic := c.getCache(); ic != nil {
+		return ic.listDatabaseClusters()
+	}
 	return c.dbClusterClient.DBClusters(c.namespace).List(ctx, metav1.ListOptions{})
 }
 
 // GetDatabaseCluster returns PXC clusters by provided name.
 func (c *Client) GetDatabaseCluster(ctx context.Context, name string) (*dbaasv1.DatabaseCluster, error) {
+	if ic := c.getCache(); ic != nil {
+		return ic.getDatabaseCluster(name)
+	}
 	cluster, err := c.dbClusterClient.DBClusters(c.namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -296,6 +479,29 @@ func (c *Client) GetDatabaseCluster(ctx context.Context, name string) (*dbaasv1.
 	return cluster, nil
 }
 
+// PatchDatabaseCluster applies the patch of the given type to the database cluster.
+func (c *Client) PatchDatabaseCluster(ctx context.Context, name string, patchType types.PatchType, patch []byte) (*dbaasv1.DatabaseCluster, error) {
+	return c.dbClusterClient.DBClusters(c.namespace).Patch(ctx, name, patchType, patch, metav1.PatchOptions{})
+}
+
+// ListDatabaseClusterBackups returns backups of database clusters.
+func (c *Client) ListDatabaseClusterBackups(ctx context.Context) (*database.DatabaseClusterBackupList, error) {
+	return c.dbClusterClient.DBClusterBackups(c.namespace).List(ctx, metav1.ListOptions{})
+}
+
+// GetDatabaseClusterBackup returns the database cluster backup by name.
+func (c *Client) GetDatabaseClusterBackup(ctx context.Context, name string) (*database.DatabaseClusterBackup, error) {
+	return c.dbClusterClient.DBClusterBackups(c.namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// WatchDatabaseCluster watches changes of the database cluster starting from the given resource version.
+func (c *Client) WatchDatabaseCluster(ctx context.Context, name, resourceVersion string) (watch.Interface, error) {
+	return c.dbClusterClient.DBClusters(c.namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+		ResourceVersion: resourceVersion,
+	})
+}
+
 // GetStorageClasses returns all storage classes available in the cluster
 func (c *Client) GetStorageClasses(ctx context.Context) (*storagev1.StorageClassList, error) {
 	return c.clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
@@ -303,30 +509,96 @@ func (c *Client) GetStorageClasses(ctx context.Context) (*storagev1.StorageClass
 
 // GetDeployment returns deployment by name
 func (c *Client) GetDeployment(ctx context.Context, name string) (*appsv1.Deployment, error) {
+	if ic := c.getCache(); ic != nil {
+		return ic.getDeployment(name)
+	}
 	return c.clientset.AppsV1().Deployments(c.namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
 // GetSecret returns secret by name
 func (c *Client) GetSecret(ctx context.Context, name string) (*corev1.Secret, error) {
+	if ic := c.getCache(); ic != nil {
+		return ic.getSecret(name)
+	}
 	return c.clientset.CoreV1().Secrets(c.namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
+// CreateEvent creates the event in its namespace.
+func (c *Client) CreateEvent(ctx context.Context, event *corev1.Event) error {
+	_, err := c.clientset.CoreV1().Events(event.Namespace).Create(ctx, event, metav1.CreateOptions{})
+	return err
+}
+
+// GetConfigMap returns the config map by namespace and name
+func (c *Client) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	if namespace == "" {
+		namespace = c.namespace
+	}
+	return c.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// GetLease returns the lease by namespace and name.
+func (c *Client) GetLease(ctx context.Context, namespace, name string) (*coordinationv1.Lease, error) {
+	return c.clientset.CoordinationV1().Leases(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// CreateLease creates the lease in its namespace.
+func (c *Client) CreateLease(ctx context.Context, lease *coordinationv1.Lease) (*coordinationv1.Lease, error) {
+	c.addCommonMetadata(lease)
+	return c.clientset.CoordinationV1().Leases(lease.Namespace).Create(ctx, lease, metav1.CreateOptions{})
+}
+
+// UpdateLease updates the lease in its namespace. It fails with a conflict if the lease has
+// been changed since it was read.
+func (c *Client) UpdateLease(ctx context.Context, lease *coordinationv1.Lease) (*coordinationv1.Lease, error) {
+	return c.clientset.CoordinationV1().Leases(lease.Namespace).Update(ctx, lease, metav1.UpdateOptions{})
+}
+
+// DeleteLease deletes the lease unless it has been changed since it was read.
+func (c *Client) DeleteLease(ctx context.Context, lease *coordinationv1.Lease) error {
+	return c.clientset.CoordinationV1().Leases(lease.Namespace).Delete(ctx, lease.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: &lease.ResourceVersion},
+	})
+}
+
+// GetService returns the service by namespace and name.
+func (c *Client) GetService(ctx context.Context, namespace, name string) (*corev1.Service, error) {
+	return c.clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// GetServiceAccount returns the service account by namespace and name.
+func (c *Client) GetServiceAccount(ctx context.Context, namespace, name string) (*corev1.ServiceAccount, error) {
+	return c.clientset.CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// UpdateServiceAccount updates the service account in its namespace.
+func (c *Client) UpdateServiceAccount(ctx context.Context, account *corev1.ServiceAccount) (*corev1.ServiceAccount, error) {
+	return c.clientset.CoreV1().ServiceAccounts(account.Namespace).Update(ctx, account, metav1.UpdateOptions{})
+}
+
+// DeletePod deletes the pod by namespace and name.
+func (c *Client) DeletePod(ctx context.Context, namespace, name string) error {
+	return c.clientset.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
 // ListSecrets returns secrets
 func (c *Client) ListSecrets(ctx context.Context) (*corev1.SecretList, error) {
+	if ic := c.getCache(); ic != nil {
+		return ic.listSecrets()
+	}
 	return c.clientset.CoreV1().Secrets(c.namespace).List(ctx, metav1.ListOptions{})
 }
 
 // DeleteObject deletes object from the k8s cluster
-func (c *Client) DeleteObject(obj runtime.Object) error {
-	groupResources, err := restmapper.GetAPIGroupResources(c.clientset.Discovery())
-	if err != nil {
-		return err
+func (c *Client) DeleteObject(ctx context.Context, obj runtime.Object) error {
+	if c.dbClusterClient != nil {
+		converted, err := c.dbClusterClient.ConvertForServer(obj)
+		if err != nil {
+			return err
+		}
+		obj = converted
 	}
-	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
-
-	gvk := obj.GetObjectKind().GroupVersionKind()
-	gk := schema.GroupKind{Group: gvk.Group, Kind: gvk.Kind}
-	mapping, err := mapper.RESTMapping(gk, gvk.Version)
+	mapping, err := c.restMapping(ctx, obj)
 	if err != nil {
 		return err
 	}
@@ -334,63 +606,182 @@ func (c *Client) DeleteObject(obj runtime.Object) error {
 	if err != nil {
 		return err
 	}
-	cli, err := c.resourceClient(mapping.GroupVersionKind.GroupVersion())
-	if err != nil {
-		return err
+	res := c.resourceInterface(mapping, namespace)
+	if _, err := res.Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return nil //nolint:nilerr
 	}
-	helper := resource.NewHelper(cli, mapping)
-	err = deleteObject(helper, namespace, name)
-	return err
+	return res.Delete(ctx, name, metav1.DeleteOptions{})
 }
 
-func deleteObject(helper *resource.Helper, namespace, name string) error {
-	if _, err := helper.Get(namespace, name); err == nil {
-		_, err = helper.Delete(namespace, name)
-		if err != nil {
-			return err
-		}
+// ApplyObject applies the object with server-side apply. Clusters without support of
+// server-side apply, e.g. older than Kubernetes 1.18, are applied with ThreeWayMerge.
+func (c *Client) ApplyObject(ctx context.Context, obj runtime.Object) error {
+	if c.threeWayMerge != nil && c.threeWayMerge.Load() {
+		return c.ThreeWayMerge(ctx, obj)
 	}
-	return nil
+	err := c.ServerSideApply(ctx, obj)
+	if !serverSideApplyUnsupported(err) || c.threeWayMerge == nil {
+		return err
+	}
+	l.Warnf("the API server does not support server-side apply, falling back to three-way merges: %v", err)
+	c.threeWayMerge.Store(true)
+	return c.ThreeWayMerge(ctx, obj)
 }
 
-func (c *Client) ApplyObject(obj runtime.Object) error {
-	groupResources, err := restmapper.GetAPIGroupResources(c.clientset.Discovery())
+// serverSideApplyUnsupported returns true if the API server rejected the apply patch type.
+func serverSideApplyUnsupported(err error) bool {
+	return apierrors.IsUnsupportedMediaType(err) || apierrors.IsMethodNotSupported(err) || apierrors.IsNotAcceptable(err)
+}
+
+// ServerSideApply applies the object with server-side apply owned by FieldManager.
+// Conflicting fields owned by other managers are taken over, so repeated applies converge
+// on the object without depending on the current state.
+func (c *Client) ServerSideApply(ctx context.Context, obj runtime.Object) error {
+	res, name, u, err := c.applyConfiguration(ctx, obj)
 	if err != nil {
 		return err
 	}
-	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+	_, err = res.Apply(ctx, name, u, metav1.ApplyOptions{FieldManager: FieldManager, Force: true})
+	return err
+}
 
-	gvk := obj.GetObjectKind().GroupVersionKind()
-	gk := schema.GroupKind{Group: gvk.Group, Kind: gvk.Kind}
-	mapping, err := mapper.RESTMapping(gk, gvk.Version)
+// ThreeWayMerge applies the object like kubectl apply without server-side apply. The
+// configuration is stored in the last-applied-configuration annotation and a JSON merge
+// patch of the last applied, the new and the live object is sent, so fields set by other
+// controllers are kept while fields removed from the configuration are deleted.
+func (c *Client) ThreeWayMerge(ctx context.Context, obj runtime.Object) error {
+	res, name, u, err := c.applyConfiguration(ctx, obj)
 	if err != nil {
 		return err
 	}
-	namespace, name, err := c.retrieveMetaFromObject(obj)
+	if err := setLastAppliedConfiguration(u); err != nil {
+		return err
+	}
+	current, err := res.Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		_, err = res.Create(ctx, u, metav1.CreateOptions{FieldManager: FieldManager})
 		return err
 	}
-	cli, err := c.resourceClient(mapping.GroupVersionKind.GroupVersion())
+	patch, err := threeWayMergePatch(u, current)
 	if err != nil {
 		return err
 	}
-	helper := resource.NewHelper(cli, mapping)
-	return c.applyObject(helper, namespace, name, obj)
+	_, err = res.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	return err
 }
 
-func (c *Client) applyObject(helper *resource.Helper, namespace, name string, obj runtime.Object) error {
-	if _, err := helper.Get(namespace, name); err != nil {
-		_, err = helper.Create(namespace, false, obj)
+// applyConfiguration returns the resource of the object and the object without the fields
+// maintained by the API server, which applies reject.
+func (c *Client) applyConfiguration(ctx context.Context, obj runtime.Object) (dynamic.ResourceInterface, string, *unstructured.Unstructured, error) {
+	if c.dbClusterClient != nil {
+		converted, err := c.dbClusterClient.ConvertForServer(obj)
 		if err != nil {
-			return err
+			return nil, "", nil, err
 		}
-	} else {
-		_, err = helper.Replace(namespace, name, true, obj)
+		obj = converted
+	}
+	mapping, err := c.restMapping(ctx, obj)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	namespace, name, err := c.retrieveMetaFromObject(obj)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	c.addCommonMetadata(accessor)
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	u := &unstructured.Unstructured{Object: data}
+	u.SetGroupVersionKind(mapping.GroupVersionKind)
+	u.SetResourceVersion("")
+	u.SetManagedFields(nil)
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(u.Object, "status")
+	return c.resourceInterface(mapping, namespace), name, u, nil
+}
+
+// setLastAppliedConfiguration stores the object without the annotation in the annotation.
+func setLastAppliedConfiguration(u *unstructured.Unstructured) error {
+	annotations := u.GetAnnotations()
+	delete(annotations, corev1.LastAppliedConfigAnnotation)
+	u.SetAnnotations(annotations)
+	config, err := u.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[corev1.LastAppliedConfigAnnotation] = string(config)
+	u.SetAnnotations(annotations)
+	return nil
+}
+
+// threeWayMergePatch returns the JSON merge patch turning the live object into the modified
+// one. Fields missing from the modified object are only deleted if they were applied before.
+func threeWayMergePatch(modified, current *unstructured.Unstructured) ([]byte, error) {
+	original := current.GetAnnotations()[corev1.LastAppliedConfigAnnotation]
+	modifiedJSON, err := modified.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	currentJSON, err := current.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return jsonmergepatch.CreateThreeWayJSONMergePatch([]byte(original), modifiedJSON, currentJSON)
+}
+
+// ObjectExists returns true if the object exists in the cluster.
+func (c *Client) ObjectExists(ctx context.Context, obj runtime.Object) (bool, error) {
+	if c.dbClusterClient != nil {
+		converted, err := c.dbClusterClient.ConvertForServer(obj)
 		if err != nil {
-			return err
+			return false, err
 		}
+		obj = converted
 	}
-	return nil
+	mapping, err := c.restMapping(ctx, obj)
+	if err != nil {
+		return false, err
+	}
+	namespace, name, err := c.retrieveMetaFromObject(obj)
+	if err != nil {
+		return false, err
+	}
+	_, err = c.resourceInterface(mapping, namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		return true, nil
+	case apierrors.IsNotFound(err):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// restMapping returns the mapping of the kind of the object to its resource.
+// The discovery client does not take a context, so the context is only checked before the request.
+func (c *Client) restMapping(ctx context.Context, obj runtime.Object) (*meta.RESTMapping, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	groupResources, err := restmapper.GetAPIGroupResources(c.clientset.Discovery())
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return mapper.RESTMapping(schema.GroupKind{Group: gvk.Group, Kind: gvk.Kind}, gvk.Version)
 }
 
 func (c *Client) retrieveMetaFromObject(obj runtime.Object) (namespace, name string, err error) {
@@ -408,16 +799,13 @@ func (c *Client) retrieveMetaFromObject(obj runtime.Object) (namespace, name str
 	return
 }
 
-func (c *Client) resourceClient(gv schema.GroupVersion) (rest.Interface, error) {
-	cfg := c.restConfig
-	cfg.ContentConfig = resource.UnstructuredPlusDefaultContentConfig()
-	cfg.GroupVersion = &gv
-	if len(gv.Group) == 0 {
-		cfg.APIPath = defaultAPIURIPath
-	} else {
-		cfg.APIPath = defaultAPIsURIPath
+// resourceInterface returns the dynamic client of the resource in the namespace if it is namespaced.
+func (c *Client) resourceInterface(mapping *meta.RESTMapping, namespace string) dynamic.ResourceInterface {
+	res := c.dynamicClientset.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return res.Namespace(namespace)
 	}
-	return rest.RESTClientFor(cfg)
+	return res
 }
 
 func (c *Client) marshalKubeConfig(conf *Config) ([]byte, error) {
@@ -440,6 +828,16 @@ func (c *Client) GetPersistentVolumes(ctx context.Context) (*corev1.PersistentVo
 	return c.clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
 }
 
+// GetPersistentVolumeClaims returns list of persistent volume claims
+func (c *Client) GetPersistentVolumeClaims(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*corev1.PersistentVolumeClaimList, error) {
+	options := metav1.ListOptions{}
+	if labelSelector != nil && (labelSelector.MatchLabels != nil || labelSelector.MatchExpressions != nil) {
+		options.LabelSelector = metav1.FormatLabelSelector(labelSelector)
+	}
+
+	return c.clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, options)
+}
+
 // GetPods returns list of pods
 func (c *Client) GetPods(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*corev1.PodList, error) {
 	options := metav1.ListOptions{}
@@ -455,6 +853,65 @@ func (c *Client) GetNodes(ctx context.Context) (*corev1.NodeList, error) {
 	return c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 }
 
+// GetOIDCIssuer returns the issuer of the service account tokens published in the
+// OpenID discovery document of the API server.
+func (c *Client) GetOIDCIssuer(ctx context.Context) (string, error) {
+	raw, err := c.clientset.Discovery().RESTClient().Get().
+		AbsPath("/.well-known/openid-configuration").
+		DoRaw(ctx)
+	if err != nil {
+		return "", err
+	}
+	var doc struct {
+		Issuer string `json:"issuer"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return "", errors.Wrap(err, "cannot decode the OpenID discovery document")
+	}
+	return doc.Issuer, nil
+}
+
+// GetNodeStatsSummary returns the raw stats summary of the node served by the kubelet
+// through the /api/v1/nodes/<node-name>/proxy/stats/summary endpoint.
+func (c *Client) GetNodeStatsSummary(ctx context.Context, name string) ([]byte, error) {
+	return c.clientset.CoreV1().RESTClient().Get().
+		Resource("nodes").
+		Name(name).
+		SubResource("proxy").
+		Suffix("stats/summary").
+		DoRaw(ctx)
+}
+
+// GetNodeKubeletConfig returns the raw configuration of the kubelet of the node
+// served through the /api/v1/nodes/<node-name>/proxy/configz endpoint.
+func (c *Client) GetNodeKubeletConfig(ctx context.Context, name string) ([]byte, error) {
+	return c.clientset.CoreV1().RESTClient().Get().
+		Resource("nodes").
+		Name(name).
+		SubResource("proxy").
+		Suffix("configz").
+		DoRaw(ctx)
+}
+
+// CanI checks whether the current user is allowed to perform the verb on the resource.
+func (c *Client) CanI(ctx context.Context, verb, group, resource, namespace string) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      verb,
+				Group:     group,
+				Resource:  resource,
+			},
+		},
+	}
+	resp, err := c.clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return resp.Status.Allowed, nil
+}
+
 // GetLogs returns logs for pod
 func (c *Client) GetLogs(ctx context.Context, pod, container string) (string, error) {
 	defaultLogLines := int64(3000)
@@ -515,7 +972,7 @@ func (c *Client) GetEvents(ctx context.Context, name string) (string, error) {
 
 	var events *corev1.EventList
 	if ref, err := reference.GetReference(scheme.Scheme, pod); err != nil {
-		fmt.Printf("Unable to construct reference to '%#v': %v", pod, err)
+		l.Debugf("Unable to construct reference to pod %s: %v", pod.Name, err)
 	} else {
 		ref.Kind = ""
 		if _, isMirrorPod := pod.Annotations[corev1.MirrorPodAnnotationKey]; isMirrorPod {
@@ -647,13 +1104,13 @@ func translateTimestampSince(timestamp metav1.Time) string {
 
 // ApplyFile accepts manifest file contents, parses into []runtime.Object
 // and applies them against the cluster
-func (c *Client) ApplyFile(fileBytes []byte) error {
-	objs, err := c.getObjects(fileBytes)
+func (c *Client) ApplyFile(ctx context.Context, fileBytes []byte) error {
+	objs, err := DecodeObjects(fileBytes)
 	if err != nil {
 		return err
 	}
 	for i := range objs {
-		err := c.ApplyObject(objs[i])
+		err := c.ApplyObject(ctx, objs[i])
 		if err != nil {
 			return err
 		}
@@ -661,7 +1118,8 @@ func (c *Client) ApplyFile(fileBytes []byte) error {
 	return nil
 }
 
-func (c *Client) getObjects(f []byte) ([]runtime.Object, error) {
+// DecodeObjects parses the YAML or JSON manifest into unstructured objects.
+func DecodeObjects(f []byte) ([]runtime.Object, error) {
 	objs := []runtime.Object{}
 	decoder := yamlutil.NewYAMLOrJSONDecoder(bytes.NewReader(f), 100)
 	var err error
@@ -756,7 +1214,7 @@ func (c Client) GetSubscriptionCSV(ctx context.Context, subKey types.NamespacedN
 			Namespace: subKey.Namespace,
 			Name:      installedCSV,
 		}
-		log.Printf("  Found installed CSV %q", installedCSV)
+		l.Debugf("Found installed CSV %q", installedCSV)
 		return true, nil
 	}
 	return csvKey, wait.PollImmediateUntil(time.Second, subscriptionInstalledCSV, ctx.Done())
@@ -906,6 +1364,34 @@ func (c Client) DoRolloutWait(ctx context.Context, key types.NamespacedName) err
 	return wait.PollImmediateUntil(time.Second, rolloutComplete, ctx.Done())
 }
 
+// DoCRDWait waits until a CRD is established and ready to serve custom resources.
+func (c Client) DoCRDWait(ctx context.Context, name string) error {
+	crdEstablished := func() (bool, error) {
+		crd, err := c.apiextClientset.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				// Waiting for CRD to appear
+				return false, nil
+			}
+			return false, err
+		}
+		for _, cond := range crd.Status.Conditions {
+			switch cond.Type {
+			case apiextv1.Established:
+				if cond.Status == apiextv1.ConditionTrue {
+					return true, nil
+				}
+			case apiextv1.NamesAccepted:
+				if cond.Status == apiextv1.ConditionFalse {
+					return false, fmt.Errorf("names of crd %s are not accepted: %s", name, cond.Message)
+				}
+			}
+		}
+		return false, nil
+	}
+	return wait.PollImmediateUntil(time.Second, crdEstablished, ctx.Done())
+}
+
 // GetOperatorGroup retrieves an operator group details by namespace and name.
 func (c *Client) GetOperatorGroup(ctx context.Context, namespace, name string) (*v1.OperatorGroup, error) {
 	operatorClient, err := versioned.NewForConfig(c.restConfig)
@@ -920,6 +1406,20 @@ func (c *Client) GetOperatorGroup(ctx context.Context, namespace, name string) (
 	return operatorClient.OperatorsV1().OperatorGroups(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
+// ListOperatorGroups lists all operator groups in the namespace.
+func (c *Client) ListOperatorGroups(ctx context.Context, namespace string) (*v1.OperatorGroupList, error) {
+	operatorClient, err := versioned.NewForConfig(c.restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create an operator client instance")
+	}
+
+	if namespace == "" {
+		namespace = c.namespace
+	}
+
+	return operatorClient.OperatorsV1().OperatorGroups(namespace).List(ctx, metav1.ListOptions{})
+}
+
 // CreateOperatorGroup creates an operator group to be used as part of a subscription.
 func (c *Client) CreateOperatorGroup(ctx context.Context, namespace, name string) (*v1.OperatorGroup, error) {
 	operatorClient, err := versioned.NewForConfig(c.restConfig)
@@ -944,6 +1444,7 @@ func (c *Client) CreateOperatorGroup(ctx context.Context, namespace, name string
 			},
 		},
 	}
+	c.addCommonMetadata(og)
 
 	return operatorClient.OperatorsV1().OperatorGroups(namespace).Create(ctx, og, metav1.CreateOptions{})
 }
@@ -975,6 +1476,7 @@ func (c *Client) CreateSubscriptionForCatalog(ctx context.Context, namespace, na
 			InstallPlanApproval:    approval,
 		},
 	}
+	c.addCommonMetadata(subscription)
 
 	sub, err := operatorClient.OperatorsV1alpha1().Subscriptions(namespace).Create(ctx, subscription, metav1.CreateOptions{})
 	if err != nil {
@@ -999,6 +1501,19 @@ func (c *Client) GetSubscription(ctx context.Context, namespace, name string) (*
 	return operatorClient.OperatorsV1alpha1().Subscriptions(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
+// UpdateSubscription updates the existing subscription in the specified namespace.
+func (c *Client) UpdateSubscription(ctx context.Context, namespace string, subscription *v1alpha1.Subscription) (*v1alpha1.Subscription, error) {
+	c.rcLock.Lock()
+	defer c.rcLock.Unlock()
+
+	operatorClient, err := versioned.NewForConfig(c.restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create an operator client instance")
+	}
+
+	return operatorClient.OperatorsV1alpha1().Subscriptions(namespace).Update(ctx, subscription, metav1.UpdateOptions{})
+}
+
 // ListSubscriptions all the subscriptions in the namespace.
 func (c *Client) ListSubscriptions(ctx context.Context, namespace string) (*v1alpha1.SubscriptionList, error) {
 	c.rcLock.Lock()
@@ -1025,6 +1540,19 @@ func (c *Client) GetInstallPlan(ctx context.Context, namespace string, name stri
 	return operatorClient.OperatorsV1alpha1().InstallPlans(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
+// ListInstallPlans lists the OLM install plans in the namespace.
+func (c *Client) ListInstallPlans(ctx context.Context, namespace string) (*v1alpha1.InstallPlanList, error) {
+	c.rcLock.Lock()
+	defer c.rcLock.Unlock()
+
+	operatorClient, err := versioned.NewForConfig(c.restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create an operator client instance")
+	}
+
+	return operatorClient.OperatorsV1alpha1().InstallPlans(namespace).List(ctx, metav1.ListOptions{})
+}
+
 // UpdateInstallPlan updates the existing install plan in the specified namespace.
 func (c *Client) UpdateInstallPlan(ctx context.Context, namespace string, installPlan *v1alpha1.InstallPlan) (*v1alpha1.InstallPlan, error) {
 	c.rcLock.Lock()
@@ -1038,6 +1566,34 @@ func (c *Client) UpdateInstallPlan(ctx context.Context, namespace string, instal
 	return operatorClient.OperatorsV1alpha1().InstallPlans(namespace).Update(ctx, installPlan, metav1.UpdateOptions{})
 }
 
+// GetCatalogSource retrieves an OLM catalog source by namespace and name.
+func (c *Client) GetCatalogSource(ctx context.Context, namespace, name string) (*v1alpha1.CatalogSource, error) {
+	c.rcLock.Lock()
+	defer c.rcLock.Unlock()
+
+	operatorClient, err := versioned.NewForConfig(c.restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create an operator client instance")
+	}
+
+	return operatorClient.OperatorsV1alpha1().CatalogSources(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// ListPackageManifests lists the packages of the catalogs available in the namespace.
+// Packages of the catalogs in the global catalog namespace are included.
+func (c *Client) ListPackageManifests(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*packagev1.PackageManifestList, error) {
+	packageClient, err := packageclient.NewForConfig(c.restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create a package server client instance")
+	}
+	options := metav1.ListOptions{}
+	if labelSelector != nil && (labelSelector.MatchLabels != nil || labelSelector.MatchExpressions != nil) {
+		options.LabelSelector = metav1.FormatLabelSelector(labelSelector)
+	}
+
+	return packageClient.OperatorsV1().PackageManifests(namespace).List(ctx, options)
+}
+
 // ListCRDs returns a list of CRDs.
 func (c *Client) ListCRDs(ctx context.Context, labelSelector *metav1.LabelSelector) (*apiextv1.CustomResourceDefinitionList, error) {
 	options := metav1.ListOptions{}
@@ -1063,6 +1619,15 @@ func (c *Client) ListCRs(
 	return c.dynamicClientset.Resource(gvr).Namespace(namespace).List(ctx, options)
 }
 
+// UpdateCRStatus updates the status subresource of a CR.
+func (c *Client) UpdateCRStatus(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	obj *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	return c.dynamicClientset.Resource(gvr).Namespace(obj.GetNamespace()).UpdateStatus(ctx, obj, metav1.UpdateOptions{})
+}
+
 // GetClusterServiceVersion retrieve a CSV by namespaced name.
 func (c *Client) GetClusterServiceVersion(ctx context.Context, key types.NamespacedName) (*v1alpha1.ClusterServiceVersion, error) {
 	operatorClient, err := versioned.NewForConfig(c.restConfig)
@@ -1085,13 +1650,13 @@ func (c *Client) ListClusterServiceVersion(ctx context.Context, namespace string
 
 // DeleteFile accepts manifest file contents parses into []runtime.Object
 // and deletes them from the cluster
-func (c *Client) DeleteFile(fileBytes []byte) error {
-	objs, err := c.getObjects(fileBytes)
+func (c *Client) DeleteFile(ctx context.Context, fileBytes []byte) error {
+	objs, err := DecodeObjects(fileBytes)
 	if err != nil {
 		return err
 	}
 	for i := range objs {
-		err := c.DeleteObject(objs[i])
+		err := c.DeleteObject(ctx, objs[i])
 		if err != nil {
 			return err
 		}
@@ -1113,7 +1678,7 @@ func (c *Client) ListVMAgents(ctx context.Context, namespace string, labels map[
 		})
 	}
 
-	return vmcli.VictoriametricsV1beta1().VMAgents(namespace).List(ctx, metav1.ListOptions{})
+	return vmcli.VictoriametricsV1beta1().VMAgents(namespace).List(ctx, opts)
 }
 
 // DeleteVMAgent deletes a Victoria Metrics agent instance.
@@ -17,6 +17,12 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,6 +31,7 @@ import (
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextv1clientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apiextfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -91,12 +98,81 @@ func TestGetSecretsForServiceAccountNoSecrets(t *testing.T) {
 func TestGetServerVersion(t *testing.T) {
 	clientset := fake.NewSimpleClientset()
 	client := &Client{clientset: clientset, namespace: "default"}
-	ver, err := client.GetServerVersion()
+	ver, err := client.GetServerVersion(context.Background())
 	expectedVersion := &version.Info{}
 	require.NoError(t, err)
 	assert.Equal(t, expectedVersion.Minor, ver.Minor)
 }
 
+// versionServer returns the address of an API server serving only its version.
+func versionServer(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"major":"1","minor":"27","gitVersion":"v1.27.4"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestNewFromKubeConfigContext(t *testing.T) {
+	dev, prod := versionServer(t), versionServer(t)
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(fmt.Sprintf(`
+apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: %s
+- name: prod
+  cluster:
+    server: %s
+users:
+- name: admin
+  user:
+    token: token
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+`, dev, prod)), 0o600))
+
+	tests := []struct {
+		name       string
+		kubeconfig string
+		context    string
+		host       string
+		err        string
+	}{
+		{name: "current context", kubeconfig: kubeconfig, host: dev},
+		{name: "named context", kubeconfig: kubeconfig, context: "prod", host: prod},
+		{name: "missing context", kubeconfig: kubeconfig, context: "staging", err: `context "staging" does not exist`},
+		{name: "missing kubeconfig", kubeconfig: filepath.Join(t.TempDir(), "missing"), err: "no such file or directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewFromKubeConfigContext(tt.kubeconfig, tt.context)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.host, c.restConfig.Host)
+		})
+	}
+}
+
 func TestGetPods(t *testing.T) {
 	t.Parallel()
 
@@ -629,3 +705,65 @@ func TestListCRs(t *testing.T) {
 		}(test))
 	}
 }
+
+func TestAddCommonMetadata(t *testing.T) {
+	c := &Client{}
+	c.SetCommonMetadata(
+		map[string]string{"cost-center": "dbaas", "owner": "platform"},
+		map[string]string{"team": "platform"},
+	)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test",
+			Labels: map[string]string{"owner": "dba"},
+		},
+	}
+	c.addCommonMetadata(secret)
+	assert.Equal(t, map[string]string{"cost-center": "dbaas", "owner": "dba"}, secret.Labels)
+	assert.Equal(t, map[string]string{"team": "platform"}, secret.Annotations)
+
+	obj := &unstructured.Unstructured{}
+	obj.SetName("test")
+	c.addCommonMetadata(obj)
+	assert.Equal(t, map[string]string{"cost-center": "dbaas", "owner": "platform"}, obj.GetLabels())
+}
+
+func TestThreeWayMergePatch(t *testing.T) {
+	applied := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "test", "labels": map[string]interface{}{"app": "everest"}},
+		"data":       map[string]interface{}{"a": "1", "b": "2"},
+	}}
+	require.NoError(t, setLastAppliedConfiguration(applied))
+
+	// Another controller annotated the live object.
+	current := applied.DeepCopy()
+	annotations := current.GetAnnotations()
+	annotations["controller.example.com/seen"] = "true"
+	current.SetAnnotations(annotations)
+
+	modified := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "test", "labels": map[string]interface{}{"app": "everest"}},
+		"data":       map[string]interface{}{"a": "3"},
+	}}
+	require.NoError(t, setLastAppliedConfiguration(modified))
+	patch, err := threeWayMergePatch(modified, current)
+	require.NoError(t, err)
+
+	var p map[string]interface{}
+	require.NoError(t, json.Unmarshal(patch, &p))
+	assert.Equal(t, map[string]interface{}{"a": "3", "b": nil}, p["data"], "the removed field is deleted")
+	patchedAnnotations := p["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})
+	assert.NotContains(t, patchedAnnotations, "controller.example.com/seen", "foreign annotations are kept")
+	assert.Contains(t, patchedAnnotations, corev1.LastAppliedConfigAnnotation)
+}
+
+func TestServerSideApplyUnsupported(t *testing.T) {
+	assert.True(t, serverSideApplyUnsupported(&apierrors.StatusError{ErrStatus: metav1.Status{Code: http.StatusUnsupportedMediaType, Reason: metav1.StatusReasonUnsupportedMediaType}}))
+	assert.False(t, serverSideApplyUnsupported(apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "test")))
+	assert.False(t, serverSideApplyUnsupported(nil))
+}
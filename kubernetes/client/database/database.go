@@ -21,8 +21,14 @@ import (
 	"sync"
 
 	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 )
@@ -30,14 +36,26 @@ import (
 const (
 	DBClusterKind = "DatabaseCluster"
 	apiKind       = "databaseclusters"
+	backupKind    = "DatabaseClusterBackup"
+	backupAPIKind = "databaseclusterbackups"
 )
 
 type DatabaseClusterClientInterface interface {
 	DBClusters(namespace string) DatabaseClusterInterface
+	DBClusterBackups(namespace string) DatabaseClusterBackupInterface
 }
 
+// DatabaseClusterClient reads and writes objects of the dbaas.percona.com API. Objects are
+// exchanged in the version negotiated with the API server and converted to the hub version
+// the Go types are defined in, so the client keeps working across operator upgrades.
 type DatabaseClusterClient struct {
 	restClient rest.Interface
+	discovery  discovery.DiscoveryInterface
+	dynamic    dynamic.Interface
+
+	lock sync.Mutex
+	// version is the negotiated version. It is empty until the first request.
+	version string
 }
 
 var addToScheme sync.Once
@@ -51,6 +69,7 @@ func NewForConfig(c *rest.Config) (*DatabaseClusterClient, error) {
 
 	addToScheme.Do(func() {
 		dbaasv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+		scheme.Scheme.AddKnownTypes(dbaasv1.GroupVersion, &DatabaseClusterBackup{}, &DatabaseClusterBackupList{})
 		metav1.AddToGroupVersion(scheme.Scheme, dbaasv1.GroupVersion)
 	})
 
@@ -58,14 +77,97 @@ func NewForConfig(c *rest.Config) (*DatabaseClusterClient, error) {
 	if err != nil {
 		return nil, err
 	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DatabaseClusterClient{restClient: client, discovery: discoveryClient, dynamic: dynamicClient}, nil
+}
+
+// Version returns the version of the API objects are exchanged in. It is negotiated
+// with the API server on the first call. Failed negotiations are retried on the next call.
+func (c *DatabaseClusterClient) Version() (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.version != "" {
+		return c.version, nil
+	}
+	groups, err := c.discovery.ServerGroups()
+	if err != nil {
+		return "", errors.Wrap(err, "cannot discover the versions of the dbaas.percona.com API")
+	}
+	var served *metav1.APIGroup
+	for i := range groups.Groups {
+		if groups.Groups[i].Name == dbaasv1.GroupVersion.Group {
+			served = &groups.Groups[i]
+			break
+		}
+	}
+	version, err := negotiateVersion(served)
+	if err != nil {
+		return "", err
+	}
+	if served != nil {
+		// Keep the hub version of a cluster without the operator undecided,
+		// the operator may be installed later.
+		c.version = version
+	}
+	return version, nil
+}
+
+// ConvertForServer converts objects of the dbaas.percona.com API to the negotiated version
+// before they are written. Other objects are returned unchanged.
+func (c *DatabaseClusterClient) ConvertForServer(obj runtime.Object) (runtime.Object, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Group != dbaasv1.GroupVersion.Group {
+		return obj, nil
+	}
+	version, err := c.Version()
+	if err != nil || version == gvk.Version {
+		return obj, err
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		// Go types are of the hub version.
+		return fromTyped(obj, version)
+	}
+	if err := ToHub(u); err != nil {
+		return nil, err
+	}
+	if err := FromHub(u, version); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
 
-	return &DatabaseClusterClient{restClient: client}, nil
+// resource returns the dynamic client of the resource if the negotiated version is not
+// the hub version. It returns nil if the typed client can be used.
+func (c *DatabaseClusterClient) resource(resource, namespace string) (dynamic.ResourceInterface, string, error) {
+	version, err := c.Version()
+	if err != nil || version == HubVersion {
+		return nil, version, err
+	}
+	gvr := dbaasv1.GroupVersion.WithResource(resource)
+	gvr.Version = version
+	return c.dynamic.Resource(gvr).Namespace(namespace), version, nil
 }
 
 func (c *DatabaseClusterClient) DBClusters(namespace string) DatabaseClusterInterface {
 	return &dbClusterClient{
-		restClient: c.restClient,
-		namespace:  namespace,
+		client:    c,
+		namespace: namespace,
+	}
+}
+
+func (c *DatabaseClusterClient) DBClusterBackups(namespace string) DatabaseClusterBackupInterface {
+	return &dbClusterBackupClient{
+		client:    c,
+		namespace: namespace,
 	}
 }
 
@@ -73,16 +175,36 @@ type DatabaseClusterInterface interface {
 	List(ctx context.Context, opts metav1.ListOptions) (*dbaasv1.DatabaseClusterList, error)
 	Get(ctx context.Context, name string, options metav1.GetOptions) (*dbaasv1.DatabaseCluster, error)
 	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions) (*dbaasv1.DatabaseCluster, error)
 }
 
 type dbClusterClient struct {
-	restClient rest.Interface
-	namespace  string
+	client    *DatabaseClusterClient
+	namespace string
 }
 
 func (c *dbClusterClient) List(ctx context.Context, opts metav1.ListOptions) (*dbaasv1.DatabaseClusterList, error) {
 	result := &dbaasv1.DatabaseClusterList{}
-	err := c.restClient.
+	dyn, _, err := c.client.resource(apiKind, c.namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dyn != nil {
+		list, err := dyn.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		result.ResourceVersion = list.GetResourceVersion()
+		result.Continue = list.GetContinue()
+		result.Items = make([]dbaasv1.DatabaseCluster, len(list.Items))
+		for i := range list.Items {
+			if err := toTyped(&list.Items[i], &result.Items[i]); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	err = c.client.restClient.
 		Get().
 		Namespace(c.namespace).
 		Resource(apiKind).
@@ -94,7 +216,18 @@ func (c *dbClusterClient) List(ctx context.Context, opts metav1.ListOptions) (*d
 
 func (c *dbClusterClient) Get(ctx context.Context, name string, opts metav1.GetOptions) (*dbaasv1.DatabaseCluster, error) {
 	result := &dbaasv1.DatabaseCluster{}
-	err := c.restClient.
+	dyn, _, err := c.client.resource(apiKind, c.namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dyn != nil {
+		obj, err := dyn.Get(ctx, name, opts)
+		if err != nil {
+			return nil, err
+		}
+		return result, toTyped(obj, result)
+	}
+	err = c.client.restClient.
 		Get().
 		Namespace(c.namespace).
 		Resource(apiKind).
@@ -107,10 +240,148 @@ func (c *dbClusterClient) Get(ctx context.Context, name string, opts metav1.GetO
 
 func (c *dbClusterClient) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
 	opts.Watch = true
-	return c.restClient.
+	dyn, _, err := c.client.resource(apiKind, c.namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dyn != nil {
+		w, err := dyn.Watch(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return watch.Filter(w, func(e watch.Event) (watch.Event, bool) {
+			obj, ok := e.Object.(*unstructured.Unstructured)
+			if !ok {
+				return e, true
+			}
+			cluster := &dbaasv1.DatabaseCluster{}
+			if err := toTyped(obj, cluster); err != nil {
+				return watch.Event{Type: watch.Error, Object: &metav1.Status{
+					Status:  metav1.StatusFailure,
+					Message: err.Error(),
+				}}, true
+			}
+			e.Object = cluster
+			return e, true
+		}), nil
+	}
+	return c.client.restClient.
 		Get().
 		Namespace(c.namespace).
 		Resource(apiKind).
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Watch(ctx)
 }
+
+// Patch applies the patch to the database cluster. Patches are written against the hub
+// version, so only merge patches are supported if another version is negotiated. They are
+// applied to the converted cluster which is written back.
+func (c *dbClusterClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions) (*dbaasv1.DatabaseCluster, error) {
+	result := &dbaasv1.DatabaseCluster{}
+	dyn, version, err := c.client.resource(apiKind, c.namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dyn != nil {
+		if pt != types.MergePatchType {
+			return nil, errors.Errorf("%s patches cannot be converted to version %s of the dbaas.percona.com API, use a merge patch", pt, version)
+		}
+		cluster, err := c.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		cluster.APIVersion = dbaasv1.GroupVersion.String()
+		cluster.Kind = DBClusterKind
+		current, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cluster)
+		if err != nil {
+			return nil, err
+		}
+		patched, err := mergePatch(&unstructured.Unstructured{Object: current}, data)
+		if err != nil {
+			return nil, err
+		}
+		if err := FromHub(patched, version); err != nil {
+			return nil, err
+		}
+		obj, err := dyn.Update(ctx, patched, metav1.UpdateOptions{DryRun: opts.DryRun, FieldManager: opts.FieldManager})
+		if err != nil {
+			return nil, err
+		}
+		return result, toTyped(obj, result)
+	}
+	err = c.client.restClient.
+		Patch(pt).
+		Namespace(c.namespace).
+		Resource(apiKind).
+		Name(name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+type DatabaseClusterBackupInterface interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*DatabaseClusterBackupList, error)
+	Get(ctx context.Context, name string, options metav1.GetOptions) (*DatabaseClusterBackup, error)
+}
+
+type dbClusterBackupClient struct {
+	client    *DatabaseClusterClient
+	namespace string
+}
+
+func (c *dbClusterBackupClient) List(ctx context.Context, opts metav1.ListOptions) (*DatabaseClusterBackupList, error) {
+	result := &DatabaseClusterBackupList{}
+	dyn, _, err := c.client.resource(backupAPIKind, c.namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dyn != nil {
+		list, err := dyn.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		result.ResourceVersion = list.GetResourceVersion()
+		result.Continue = list.GetContinue()
+		result.Items = make([]DatabaseClusterBackup, len(list.Items))
+		for i := range list.Items {
+			if err := toTyped(&list.Items[i], &result.Items[i]); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	err = c.client.restClient.
+		Get().
+		Namespace(c.namespace).
+		Resource(backupAPIKind).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+func (c *dbClusterBackupClient) Get(ctx context.Context, name string, opts metav1.GetOptions) (*DatabaseClusterBackup, error) {
+	result := &DatabaseClusterBackup{}
+	dyn, _, err := c.client.resource(backupAPIKind, c.namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dyn != nil {
+		obj, err := dyn.Get(ctx, name, opts)
+		if err != nil {
+			return nil, err
+		}
+		return result, toTyped(obj, result)
+	}
+	err = c.client.restClient.
+		Get().
+		Namespace(c.namespace).
+		Resource(backupAPIKind).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Name(name).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
@@ -0,0 +1,176 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The Go types of the dbaas.percona.com API module lack backups and point-in-time recovery,
+// so the objects the operator serves for them are declared here.
+
+// BackupState is the state of a database cluster backup.
+type BackupState string
+
+// DatabaseClusterBackupSpec defines the desired state of DatabaseClusterBackup.
+type DatabaseClusterBackupSpec struct {
+	DBClusterName     string `json:"dbClusterName"`
+	BackupStorageName string `json:"backupStorageName,omitempty"`
+}
+
+// DatabaseClusterBackupStatus defines the observed state of DatabaseClusterBackup.
+type DatabaseClusterBackupStatus struct {
+	State       BackupState  `json:"state,omitempty"`
+	CompletedAt *metav1.Time `json:"completed,omitempty"`
+	Destination string       `json:"destination,omitempty"`
+}
+
+// DatabaseClusterBackup is a backup of a database cluster.
+type DatabaseClusterBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseClusterBackupSpec   `json:"spec,omitempty"`
+	Status DatabaseClusterBackupStatus `json:"status,omitempty"`
+}
+
+// DatabaseClusterBackupList contains a list of DatabaseClusterBackup.
+type DatabaseClusterBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatabaseClusterBackup `json:"items"`
+}
+
+// PITRSpec configures the upload of the binary logs or the oplog of a database cluster for
+// point-in-time recovery, it is the pitr field of the backup spec of the cluster.
+type PITRSpec struct {
+	Enabled            bool    `json:"enabled"`
+	StorageName        string  `json:"storageName,omitempty"`
+	TimeBetweenUploads float64 `json:"timeBetweenUploads,omitempty"`
+}
+
+// PITR restores a backup up to a point in time.
+type PITR struct {
+	Type string `json:"type,omitempty"`
+	Date string `json:"date,omitempty"`
+}
+
+// DatabaseClusterRestoreSpec defines the desired state of DatabaseClusterRestore.
+type DatabaseClusterRestoreSpec struct {
+	dbaasv1.DatabaseClusterRestoreSpec `json:",inline"`
+	PITR                               *PITR `json:"pitr,omitempty"`
+}
+
+// DatabaseClusterRestore is a restore of a database cluster with point-in-time recovery.
+type DatabaseClusterRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatabaseClusterRestoreSpec           `json:"spec,omitempty"`
+	Status dbaasv1.DatabaseClusterRestoreStatus `json:"status,omitempty"`
+}
+
+// DeepCopyInto copies the backup into out.
+func (in *DatabaseClusterBackup) DeepCopyInto(out *DatabaseClusterBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Status.CompletedAt != nil {
+		out.Status.CompletedAt = in.Status.CompletedAt.DeepCopy()
+	}
+}
+
+// DeepCopy returns a copy of the backup.
+func (in *DatabaseClusterBackup) DeepCopy() *DatabaseClusterBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseClusterBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *DatabaseClusterBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies the list into out.
+func (in *DatabaseClusterBackupList) DeepCopyInto(out *DatabaseClusterBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]DatabaseClusterBackup, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy returns a copy of the list.
+func (in *DatabaseClusterBackupList) DeepCopy() *DatabaseClusterBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseClusterBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *DatabaseClusterBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies the restore into out.
+func (in *DatabaseClusterRestore) DeepCopyInto(out *DatabaseClusterRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DatabaseClusterRestoreSpec.DeepCopyInto(&out.Spec.DatabaseClusterRestoreSpec)
+	if in.Spec.PITR != nil {
+		pitr := *in.Spec.PITR
+		out.Spec.PITR = &pitr
+	}
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy returns a copy of the restore.
+func (in *DatabaseClusterRestore) DeepCopy() *DatabaseClusterRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseClusterRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *DatabaseClusterRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// HubVersion is the version of the dbaas.percona.com API the Go types are defined in.
+// Objects of the other supported versions are converted to and from it.
+var HubVersion = dbaasv1.GroupVersion.Version
+
+// ErrUnsupportedVersion is returned if the operator serves no version of the API the client supports.
+var ErrUnsupportedVersion = errors.New("the installed dbaas-operator serves no supported version of the dbaas.percona.com API")
+
+// Conversion converts objects of an API version to and from the hub version in place.
+// Nil functions only change the apiVersion of the object.
+type Conversion struct {
+	ToHub   func(obj map[string]interface{}) error
+	FromHub func(obj map[string]interface{}) error
+}
+
+// conversions holds the conversions of the supported API versions by kind. Versions
+// introduced by operator upgrades are supported by registering their conversions here.
+var conversions = map[string]map[string]Conversion{
+	DBClusterKind: {HubVersion: {}},
+	backupKind:    {HubVersion: {}},
+}
+
+// SupportedVersions returns the versions of the API the client can read and write sorted
+// with the hub version first.
+func SupportedVersions() []string {
+	versions := make([]string, 0, len(conversions[DBClusterKind]))
+	for version := range conversions[DBClusterKind] {
+		if version != HubVersion {
+			versions = append(versions, version)
+		}
+	}
+	sort.Strings(versions)
+	return append([]string{HubVersion}, versions...)
+}
+
+// negotiateVersion returns the version of the API group objects are read and written in.
+// The hub version is preferred to avoid conversions, then the version preferred by the
+// server and then the other served versions. The hub version is used if the group is not
+// served, e.g. before the operator is installed.
+func negotiateVersion(group *metav1.APIGroup) (string, error) {
+	if group == nil {
+		return HubVersion, nil
+	}
+	served := make([]string, 0, len(group.Versions))
+	for _, v := range group.Versions {
+		if v.Version == HubVersion {
+			return HubVersion, nil
+		}
+		served = append(served, v.Version)
+	}
+	if _, ok := conversions[DBClusterKind][group.PreferredVersion.Version]; ok {
+		return group.PreferredVersion.Version, nil
+	}
+	for _, version := range served {
+		if _, ok := conversions[DBClusterKind][version]; ok {
+			return version, nil
+		}
+	}
+	return "", errors.Wrapf(ErrUnsupportedVersion, "served versions: %s, supported versions: %s",
+		strings.Join(served, ", "), strings.Join(SupportedVersions(), ", "))
+}
+
+// ToHub converts the object from its version to the hub version.
+func ToHub(obj *unstructured.Unstructured) error {
+	gvk := obj.GroupVersionKind()
+	if gvk.Version == HubVersion {
+		return nil
+	}
+	conversion, err := conversionOf(gvk.Kind, gvk.Version)
+	if err != nil {
+		return err
+	}
+	if conversion.ToHub != nil {
+		if err := conversion.ToHub(obj.Object); err != nil {
+			return errors.Wrapf(err, "cannot convert %s %s from %s", gvk.Kind, obj.GetName(), gvk.Version)
+		}
+	}
+	obj.SetAPIVersion(dbaasv1.GroupVersion.String())
+	return nil
+}
+
+// FromHub converts the object from the hub version to the given version.
+func FromHub(obj *unstructured.Unstructured, version string) error {
+	gvk := obj.GroupVersionKind()
+	if gvk.Version != HubVersion {
+		return errors.Errorf("%s %s is not of the hub version %s", gvk.Kind, obj.GetName(), HubVersion)
+	}
+	if version == HubVersion {
+		return nil
+	}
+	conversion, err := conversionOf(gvk.Kind, version)
+	if err != nil {
+		return err
+	}
+	if conversion.FromHub != nil {
+		if err := conversion.FromHub(obj.Object); err != nil {
+			return errors.Wrapf(err, "cannot convert %s %s to %s", gvk.Kind, obj.GetName(), version)
+		}
+	}
+	obj.SetAPIVersion(dbaasv1.GroupVersion.Group + "/" + version)
+	return nil
+}
+
+func conversionOf(kind, version string) (Conversion, error) {
+	conversion, ok := conversions[kind][version]
+	if !ok {
+		return Conversion{}, errors.Errorf("conversion of %s from %s to %s is not supported", kind, version, HubVersion)
+	}
+	return conversion, nil
+}
+
+// toTyped converts the object to the hub version and decodes it into the Go type.
+func toTyped(obj *unstructured.Unstructured, into runtime.Object) error {
+	if err := ToHub(obj); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, into)
+}
+
+// fromTyped encodes the object of the hub version and converts it to the given version.
+func fromTyped(obj runtime.Object, version string) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	if err := FromHub(u, version); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// mergePatch applies the JSON merge patch to the object. The result is decoded again
+// so numbers are typed the way the API machinery expects.
+func mergePatch(obj *unstructured.Unstructured, patch []byte) (*unstructured.Unstructured, error) {
+	var p map[string]interface{}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, errors.Wrap(err, "merge patch must be a JSON object")
+	}
+	merge(obj.Object, p)
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return nil, err
+	}
+	patched := &unstructured.Unstructured{}
+	if err := patched.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+func merge(obj, patch map[string]interface{}) {
+	for key, value := range patch {
+		if value == nil {
+			delete(obj, key)
+			continue
+		}
+		p, ok := value.(map[string]interface{})
+		if !ok {
+			obj[key] = value
+			continue
+		}
+		o, ok := obj[key].(map[string]interface{})
+		if !ok {
+			o = map[string]interface{}{}
+			obj[key] = o
+		}
+		merge(o, p)
+	}
+}
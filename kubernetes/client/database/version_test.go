@@ -0,0 +1,162 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func apiGroup(preferred string, versions ...string) *metav1.APIGroup {
+	group := &metav1.APIGroup{
+		Name:             dbaasv1.GroupVersion.Group,
+		PreferredVersion: metav1.GroupVersionForDiscovery{Version: preferred},
+	}
+	for _, version := range versions {
+		group.Versions = append(group.Versions, metav1.GroupVersionForDiscovery{Version: version})
+	}
+	return group
+}
+
+func registerV2(t *testing.T) {
+	t.Helper()
+	conversions[DBClusterKind]["v2"] = Conversion{
+		ToHub: func(obj map[string]interface{}) error {
+			spec := obj["spec"].(map[string]interface{})
+			spec["databaseType"] = spec["engineType"]
+			delete(spec, "engineType")
+			return nil
+		},
+		FromHub: func(obj map[string]interface{}) error {
+			spec := obj["spec"].(map[string]interface{})
+			spec["engineType"] = spec["databaseType"]
+			delete(spec, "databaseType")
+			return nil
+		},
+	}
+	t.Cleanup(func() { delete(conversions[DBClusterKind], "v2") })
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	t.Run("not installed", func(t *testing.T) {
+		version, err := negotiateVersion(nil)
+		require.NoError(t, err)
+		assert.Equal(t, HubVersion, version)
+	})
+	t.Run("hub version served", func(t *testing.T) {
+		version, err := negotiateVersion(apiGroup("v2", HubVersion, "v2"))
+		require.NoError(t, err)
+		assert.Equal(t, HubVersion, version)
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := negotiateVersion(apiGroup("v2", "v2"))
+		assert.ErrorIs(t, err, ErrUnsupportedVersion)
+	})
+	t.Run("registered", func(t *testing.T) {
+		registerV2(t)
+		version, err := negotiateVersion(apiGroup("v2", "v2"))
+		require.NoError(t, err)
+		assert.Equal(t, "v2", version)
+	})
+}
+
+func TestConversion(t *testing.T) {
+	registerV2(t)
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": dbaasv1.GroupVersion.Group + "/v2",
+		"kind":       DBClusterKind,
+		"metadata":   map[string]interface{}{"name": "test"},
+		"spec":       map[string]interface{}{"engineType": "pxc"},
+	}}
+
+	cluster := &dbaasv1.DatabaseCluster{}
+	require.NoError(t, toTyped(obj, cluster))
+	assert.Equal(t, "pxc", string(cluster.Spec.Database))
+
+	patched, err := mergePatch(obj, []byte(`{"spec":{"pause":true}}`))
+	require.NoError(t, err)
+	require.NoError(t, FromHub(patched, "v2"))
+	assert.Equal(t, dbaasv1.GroupVersion.Group+"/v2", patched.GetAPIVersion())
+	assert.Equal(t, map[string]interface{}{"engineType": "pxc", "pause": true}, patched.Object["spec"])
+}
+
+func TestDatabaseClusterClientConversion(t *testing.T) {
+	registerV2(t)
+	ctx := context.Background()
+	gvr := schema.GroupVersionResource{Group: dbaasv1.GroupVersion.Group, Version: "v2", Resource: apiKind}
+	stored := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvr.GroupVersion().String(),
+		"kind":       DBClusterKind,
+		"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+		"spec":       map[string]interface{}{"engineType": "pxc"},
+	}}
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: DBClusterKind + "List"}, stored)
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	discovery.Resources = []*metav1.APIResourceList{{GroupVersion: gvr.GroupVersion().String()}}
+	c := &DatabaseClusterClient{discovery: discovery, dynamic: dynamic}
+
+	version, err := c.Version()
+	require.NoError(t, err)
+	assert.Equal(t, "v2", version)
+
+	cluster, err := c.DBClusters("default").Get(ctx, "test", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, dbaasv1.EngineType("pxc"), cluster.Spec.Database)
+
+	list, err := c.DBClusters("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, dbaasv1.EngineType("pxc"), list.Items[0].Spec.Database)
+
+	patched, err := c.DBClusters("default").Patch(ctx, "test", types.MergePatchType, []byte(`{"spec":{"pause":true}}`), metav1.PatchOptions{})
+	require.NoError(t, err)
+	assert.True(t, patched.Spec.Pause)
+	written, err := dynamic.Resource(gvr).Namespace("default").Get(ctx, "test", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, gvr.GroupVersion().String(), written.GetAPIVersion())
+	engine, _, _ := unstructured.NestedString(written.Object, "spec", "engineType")
+	assert.Equal(t, "pxc", engine)
+	pause, _, _ := unstructured.NestedBool(written.Object, "spec", "pause")
+	assert.True(t, pause)
+
+	_, err = c.DBClusters("default").Patch(ctx, "test", types.JSONPatchType, []byte(`[]`), metav1.PatchOptions{})
+	assert.ErrorContains(t, err, "use a merge patch")
+
+	converted, err := c.ConvertForServer(&dbaasv1.DatabaseCluster{
+		TypeMeta:   metav1.TypeMeta{APIVersion: dbaasv1.GroupVersion.String(), Kind: DBClusterKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       dbaasv1.DatabaseSpec{Database: "psmdb"},
+	})
+	require.NoError(t, err)
+	u, ok := converted.(*unstructured.Unstructured)
+	require.True(t, ok)
+	assert.Equal(t, gvr.GroupVersion().String(), u.GetAPIVersion())
+	engine, _, _ = unstructured.NestedString(u.Object, "spec", "engineType")
+	assert.Equal(t, "psmdb", engine)
+}
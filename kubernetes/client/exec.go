@@ -0,0 +1,75 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// ExecOptions describes a command run in a container.
+type ExecOptions struct {
+	Command []string
+	// Stdin is attached to the command if set.
+	Stdin  io.Reader
+	Stdout io.Writer
+	// Stderr is not used if TTY is set, the terminal merges it into Stdout.
+	Stderr io.Writer
+	// TTY allocates a terminal for the command.
+	TTY bool
+	// TerminalSizes reports the size of the local terminal if TTY is set.
+	TerminalSizes remotecommand.TerminalSizeQueue
+}
+
+// Exec runs the command in the container of the pod and streams its input and output
+// until it exits. The exit status of a failed command is reported by an error
+// implementing k8s.io/client-go/util/exec.ExitError.
+func (c *Client) Exec(ctx context.Context, namespace, pod, container string, opts ExecOptions) error {
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   opts.Command,
+			Stdin:     opts.Stdin != nil,
+			Stdout:    opts.Stdout != nil,
+			Stderr:    opts.Stderr != nil && !opts.TTY,
+			TTY:       opts.TTY,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(c.restConfig, http.MethodPost, req.URL())
+	if err != nil {
+		return errors.Wrapf(err, "cannot execute in pod %s", pod)
+	}
+	streamOpts := remotecommand.StreamOptions{
+		Stdin:  opts.Stdin,
+		Stdout: opts.Stdout,
+		Tty:    opts.TTY,
+	}
+	if !opts.TTY {
+		streamOpts.Stderr = opts.Stderr
+	} else {
+		streamOpts.TerminalSizeQueue = opts.TerminalSizes
+	}
+	return executor.StreamWithContext(ctx, streamOpts)
+}
@@ -4,12 +4,16 @@ package client
 
 import (
 	"context"
+	"time"
 
 	vmv1beta1 "github.com/VictoriaMetrics/operator/api/victoriametrics/v1beta1"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client/database"
 	v1 "github.com/operator-framework/api/pkg/operators/v1"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	packagev1 "github.com/operator-framework/operator-lifecycle-manager/pkg/package-server/apis/operators/v1"
 	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -19,75 +23,167 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/apimachinery/pkg/watch"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
 
 // KubeClientConnector ...
 type KubeClientConnector interface {
+	// Namespace returns the namespace used for namespaced resources
+	Namespace() string
+	// SetCommonMetadata sets labels and annotations added to every object created or applied by the client.
+	SetCommonMetadata(labels, annotations map[string]string)
+	// SetRetries switches repeating of requests failed with transient errors such as
+	// timeouts, throttling and etcd leader changes. Retries are enabled by default.
+	SetRetries(enabled bool)
+	// SetAuditor sets the function receiving the records of the mutating requests. Requests are
+	// not recorded if it is nil.
+	SetAuditor(auditor AuditFunc)
+	// StartCache starts shared informers for database clusters, secrets and deployments
+	// and waits for them to sync. Once started, the corresponding get and list calls
+	// are served from the cache until the context is done.
+	StartCache(ctx context.Context, resync time.Duration) error
 	// GetSecretsForServiceAccount returns secret by given service account name
 	GetSecretsForServiceAccount(ctx context.Context, accountName string) (*corev1.Secret, error)
 	// GenerateKubeConfig generates kubeconfig
 	GenerateKubeConfig(secret *corev1.Secret) ([]byte, error)
-	// GetServerVersion returns server version
-	GetServerVersion() (*version.Info, error)
+	// GenerateKubeConfigWithToken generates kubeconfig for the user authenticating with the service account token secret.
+	GenerateKubeConfigWithToken(user string, secret *corev1.Secret) ([]byte, error)
+	// GenerateInClusterKubeConfig generates kubeconfig for the service account of the pod the client runs in.
+	GenerateInClusterKubeConfig() ([]byte, error)
+	// GetServerVersion returns server version.
+	// The discovery client does not take a context, so the context is only checked before the request.
+	GetServerVersion(ctx context.Context) (*version.Info, error)
+	// InsecureTLS returns true if the certificate of the API server is not verified.
+	InsecureTLS() bool
+	// HasAPIGroup returns true if the API server serves the API group.
+	HasAPIGroup(ctx context.Context, name string) (bool, error)
 	// ListDatabaseClusters returns list of managed PCX clusters.
 	ListDatabaseClusters(ctx context.Context) (*dbaasv1.DatabaseClusterList, error)
 	// GetDatabaseCluster returns PXC clusters by provided name.
 	GetDatabaseCluster(ctx context.Context, name string) (*dbaasv1.DatabaseCluster, error)
+	// PatchDatabaseCluster applies the patch of the given type to the database cluster.
+	PatchDatabaseCluster(ctx context.Context, name string, patchType types.PatchType, patch []byte) (*dbaasv1.DatabaseCluster, error)
+	// ListDatabaseClusterBackups returns backups of database clusters.
+	ListDatabaseClusterBackups(ctx context.Context) (*database.DatabaseClusterBackupList, error)
+	// GetDatabaseClusterBackup returns the database cluster backup by name.
+	GetDatabaseClusterBackup(ctx context.Context, name string) (*database.DatabaseClusterBackup, error)
+	// WatchDatabaseCluster watches changes of the database cluster starting from the given resource version.
+	WatchDatabaseCluster(ctx context.Context, name, resourceVersion string) (watch.Interface, error)
 	// GetStorageClasses returns all storage classes available in the cluster
 	GetStorageClasses(ctx context.Context) (*storagev1.StorageClassList, error)
 	// GetDeployment returns deployment by name
 	GetDeployment(ctx context.Context, name string) (*appsv1.Deployment, error)
 	// GetSecret returns secret by name
 	GetSecret(ctx context.Context, name string) (*corev1.Secret, error)
+	// CreateEvent creates the event in its namespace.
+	CreateEvent(ctx context.Context, event *corev1.Event) error
+	// GetConfigMap returns the config map by namespace and name
+	GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
+	// GetLease returns the lease by namespace and name.
+	GetLease(ctx context.Context, namespace, name string) (*coordinationv1.Lease, error)
+	// CreateLease creates the lease in its namespace.
+	CreateLease(ctx context.Context, lease *coordinationv1.Lease) (*coordinationv1.Lease, error)
+	// UpdateLease updates the lease in its namespace. It fails with a conflict if the lease has
+	// been changed since it was read.
+	UpdateLease(ctx context.Context, lease *coordinationv1.Lease) (*coordinationv1.Lease, error)
+	// DeleteLease deletes the lease unless it has been changed since it was read.
+	DeleteLease(ctx context.Context, lease *coordinationv1.Lease) error
+	// GetService returns the service by namespace and name.
+	GetService(ctx context.Context, namespace, name string) (*corev1.Service, error)
+	// GetServiceAccount returns the service account by namespace and name.
+	GetServiceAccount(ctx context.Context, namespace, name string) (*corev1.ServiceAccount, error)
+	// UpdateServiceAccount updates the service account in its namespace.
+	UpdateServiceAccount(ctx context.Context, account *corev1.ServiceAccount) (*corev1.ServiceAccount, error)
+	// DeletePod deletes the pod by namespace and name.
+	DeletePod(ctx context.Context, namespace, name string) error
 	// ListSecrets returns secrets
 	ListSecrets(ctx context.Context) (*corev1.SecretList, error)
 	// DeleteObject deletes object from the k8s cluster
-	DeleteObject(obj runtime.Object) error
+	DeleteObject(ctx context.Context, obj runtime.Object) error
 	// GetClusterServiceVersion retrieve a CSV by namespaced name.
 	GetClusterServiceVersion(ctx context.Context, key types.NamespacedName) (*v1alpha1.ClusterServiceVersion, error)
 	// ListClusterServiceVersion list all CSVs for the given namespace.
 	ListClusterServiceVersion(ctx context.Context, namespace string) (*v1alpha1.ClusterServiceVersionList, error)
 	// DeleteFile accepts manifest file contents parses into []runtime.Object
 	// and deletes them from the cluster
-	DeleteFile(fileBytes []byte) error
+	DeleteFile(ctx context.Context, fileBytes []byte) error
 	// GetPersistentVolumes returns Persistent Volumes available in the cluster
 	GetPersistentVolumes(ctx context.Context) (*corev1.PersistentVolumeList, error)
+	// GetPersistentVolumeClaims returns list of persistent volume claims
+	GetPersistentVolumeClaims(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*corev1.PersistentVolumeClaimList, error)
 	// GetPods returns list of pods
 	GetPods(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*corev1.PodList, error)
+	// ForwardPort forwards the local port to the port of the pod. A random port is used if localPort is zero.
+	ForwardPort(ctx context.Context, namespace, pod string, localPort, port int) (*PortForward, error)
+	// Exec runs the command in the container of the pod until it exits.
+	Exec(ctx context.Context, namespace, pod, container string, opts ExecOptions) error
 	// GetNodes returns list of nodes
 	GetNodes(ctx context.Context) (*corev1.NodeList, error)
+	// GetOIDCIssuer returns the issuer of the service account tokens published in the
+	// OpenID discovery document of the API server.
+	GetOIDCIssuer(ctx context.Context) (string, error)
+	// GetNodeStatsSummary returns the raw stats summary of the node served by the kubelet
+	// through the /api/v1/nodes/<node-name>/proxy/stats/summary endpoint.
+	GetNodeStatsSummary(ctx context.Context, name string) ([]byte, error)
+	// GetNodeKubeletConfig returns the raw configuration of the kubelet of the node
+	// served through the /api/v1/nodes/<node-name>/proxy/configz endpoint.
+	GetNodeKubeletConfig(ctx context.Context, name string) ([]byte, error)
+	// CanI checks whether the current user is allowed to perform the verb on the resource.
+	CanI(ctx context.Context, verb, group, resource, namespace string) (bool, error)
 	// GetLogs returns logs for pod
 	GetLogs(ctx context.Context, pod, container string) (string, error)
 	GetEvents(ctx context.Context, name string) (string, error)
-	ApplyObject(obj runtime.Object) error
+	// ApplyObject applies the object with server-side apply, falling back to ThreeWayMerge
+	// if the API server does not support it.
+	ApplyObject(ctx context.Context, obj runtime.Object) error
+	// ServerSideApply applies the object with server-side apply owned by FieldManager.
+	ServerSideApply(ctx context.Context, obj runtime.Object) error
+	// ThreeWayMerge applies the object with a three-way JSON merge patch like client-side kubectl apply.
+	ThreeWayMerge(ctx context.Context, obj runtime.Object) error
+	// ObjectExists returns true if the object exists in the cluster.
+	ObjectExists(ctx context.Context, obj runtime.Object) (bool, error)
 	// ApplyFile accepts manifest file contents, parses into []runtime.Object
 	// and applies them against the cluster
-	ApplyFile(fileBytes []byte) error
+	ApplyFile(ctx context.Context, fileBytes []byte) error
 	// DoCSVWait waits until for a CSV to be applied.
 	DoCSVWait(ctx context.Context, key types.NamespacedName) error
 	// GetSubscriptionCSV retrieves a subscription CSV.
 	GetSubscriptionCSV(ctx context.Context, subKey types.NamespacedName) (types.NamespacedName, error)
 	// DoRolloutWait waits until a deployment has been rolled out susccessfully or there is an error.
 	DoRolloutWait(ctx context.Context, key types.NamespacedName) error
+	// DoCRDWait waits until a CRD is established and ready to serve custom resources.
+	DoCRDWait(ctx context.Context, name string) error
 	// GetOperatorGroup retrieves an operator group details by namespace and name.
 	GetOperatorGroup(ctx context.Context, namespace, name string) (*v1.OperatorGroup, error)
+	// ListOperatorGroups lists all operator groups in the namespace.
+	ListOperatorGroups(ctx context.Context, namespace string) (*v1.OperatorGroupList, error)
 	// CreateOperatorGroup creates an operator group to be used as part of a subscription.
 	CreateOperatorGroup(ctx context.Context, namespace, name string) (*v1.OperatorGroup, error)
 	// CreateSubscriptionForCatalog creates an OLM subscription.
 	CreateSubscriptionForCatalog(ctx context.Context, namespace, name, catalogNamespace, catalog, packageName, channel, startingCSV string, approval v1alpha1.Approval) (*v1alpha1.Subscription, error)
 	// GetSubscription retrieves an OLM subscription by namespace and name.
 	GetSubscription(ctx context.Context, namespace, name string) (*v1alpha1.Subscription, error)
+	// UpdateSubscription updates the existing subscription in the specified namespace.
+	UpdateSubscription(ctx context.Context, namespace string, subscription *v1alpha1.Subscription) (*v1alpha1.Subscription, error)
 	// ListSubscriptions all the subscriptions in the namespace.
 	ListSubscriptions(ctx context.Context, namespace string) (*v1alpha1.SubscriptionList, error)
 	// GetInstallPlan retrieves an OLM install plan by namespace and name.
 	GetInstallPlan(ctx context.Context, namespace string, name string) (*v1alpha1.InstallPlan, error)
+	// ListInstallPlans lists the OLM install plans in the namespace.
+	ListInstallPlans(ctx context.Context, namespace string) (*v1alpha1.InstallPlanList, error)
 	// UpdateInstallPlan updates the existing install plan in the specified namespace.
 	UpdateInstallPlan(ctx context.Context, namespace string, installPlan *v1alpha1.InstallPlan) (*v1alpha1.InstallPlan, error)
+	// GetCatalogSource retrieves an OLM catalog source by namespace and name.
+	GetCatalogSource(ctx context.Context, namespace, name string) (*v1alpha1.CatalogSource, error)
+	// ListPackageManifests lists the packages of the catalogs available in the namespace.
+	ListPackageManifests(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*packagev1.PackageManifestList, error)
 	// ListCRDs returns a list of CRDs.
 	ListCRDs(ctx context.Context, labelSelector *metav1.LabelSelector) (*apiextv1.CustomResourceDefinitionList, error)
 	// ListCRs returns a list of CRs.
 	ListCRs(ctx context.Context, namespace string, gvr schema.GroupVersionResource, labelSelector *metav1.LabelSelector) (*unstructured.UnstructuredList, error)
+	// UpdateCRStatus updates the status subresource of a CR.
+	UpdateCRStatus(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) (*unstructured.Unstructured, error)
 	// ListVMAgents retrieves all VM agents for a namespace.
 	ListVMAgents(ctx context.Context, namespace string, labels map[string]string) (*vmv1beta1.VMAgentList, error)
 	// DeleteVMAgent deletes a Victoria Metrics agent instance.
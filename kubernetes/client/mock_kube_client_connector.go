@@ -4,12 +4,17 @@ package client
 
 import (
 	context "context"
+	time "time"
 
+	v1beta1 "github.com/VictoriaMetrics/operator/api/victoriametrics/v1beta1"
+	database "github.com/gen1us2k/everest-provisioner/kubernetes/client/database"
 	v1 "github.com/operator-framework/api/pkg/operators/v1"
 	v1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	packagev1 "github.com/operator-framework/operator-lifecycle-manager/pkg/package-server/apis/operators/v1"
 	apiv1 "github.com/percona/dbaas-operator/api/v1"
 	mock "github.com/stretchr/testify/mock"
 	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -19,6 +24,7 @@ import (
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	types "k8s.io/apimachinery/pkg/types"
 	version "k8s.io/apimachinery/pkg/version"
+	watch "k8s.io/apimachinery/pkg/watch"
 )
 
 // MockKubeClientConnector is an autogenerated mock type for the KubeClientConnector type
@@ -26,13 +32,13 @@ type MockKubeClientConnector struct {
 	mock.Mock
 }
 
-// ApplyFile provides a mock function with given fields: fileBytes
-func (_m *MockKubeClientConnector) ApplyFile(fileBytes []byte) error {
-	ret := _m.Called(fileBytes)
+// ApplyFile provides a mock function with given fields: ctx, fileBytes
+func (_m *MockKubeClientConnector) ApplyFile(ctx context.Context, fileBytes []byte) error {
+	ret := _m.Called(ctx, fileBytes)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func([]byte) error); ok {
-		r0 = rf(fileBytes)
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) error); ok {
+		r0 = rf(ctx, fileBytes)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -40,13 +46,13 @@ func (_m *MockKubeClientConnector) ApplyFile(fileBytes []byte) error {
 	return r0
 }
 
-// ApplyObject provides a mock function with given fields: obj
-func (_m *MockKubeClientConnector) ApplyObject(obj runtime.Object) error {
-	ret := _m.Called(obj)
+// ApplyObject provides a mock function with given fields: ctx, obj
+func (_m *MockKubeClientConnector) ApplyObject(ctx context.Context, obj runtime.Object) error {
+	ret := _m.Called(ctx, obj)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(runtime.Object) error); ok {
-		r0 = rf(obj)
+	if rf, ok := ret.Get(0).(func(context.Context, runtime.Object) error); ok {
+		r0 = rf(ctx, obj)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -54,6 +60,64 @@ func (_m *MockKubeClientConnector) ApplyObject(obj runtime.Object) error {
 	return r0
 }
 
+// CanI provides a mock function with given fields: ctx, verb, group, resource, namespace
+func (_m *MockKubeClientConnector) CanI(ctx context.Context, verb string, group string, resource string, namespace string) (bool, error) {
+	ret := _m.Called(ctx, verb, group, resource, namespace)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) bool); ok {
+		r0 = rf(ctx, verb, group, resource, namespace)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) error); ok {
+		r1 = rf(ctx, verb, group, resource, namespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateEvent provides a mock function with given fields: ctx, event
+func (_m *MockKubeClientConnector) CreateEvent(ctx context.Context, event *corev1.Event) error {
+	ret := _m.Called(ctx, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *corev1.Event) error); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateLease provides a mock function with given fields: ctx, lease
+func (_m *MockKubeClientConnector) CreateLease(ctx context.Context, lease *coordinationv1.Lease) (*coordinationv1.Lease, error) {
+	ret := _m.Called(ctx, lease)
+
+	var r0 *coordinationv1.Lease
+	if rf, ok := ret.Get(0).(func(context.Context, *coordinationv1.Lease) *coordinationv1.Lease); ok {
+		r0 = rf(ctx, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coordinationv1.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *coordinationv1.Lease) error); ok {
+		r1 = rf(ctx, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateOperatorGroup provides a mock function with given fields: ctx, namespace, name
 func (_m *MockKubeClientConnector) CreateOperatorGroup(ctx context.Context, namespace string, name string) (*v1.OperatorGroup, error) {
 	ret := _m.Called(ctx, namespace, name)
@@ -100,13 +164,83 @@ func (_m *MockKubeClientConnector) CreateSubscriptionForCatalog(ctx context.Cont
 	return r0, r1
 }
 
-// DeleteObject provides a mock function with given fields: obj
-func (_m *MockKubeClientConnector) DeleteObject(obj runtime.Object) error {
-	ret := _m.Called(obj)
+// DeleteFile provides a mock function with given fields: ctx, fileBytes
+func (_m *MockKubeClientConnector) DeleteFile(ctx context.Context, fileBytes []byte) error {
+	ret := _m.Called(ctx, fileBytes)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) error); ok {
+		r0 = rf(ctx, fileBytes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteLease provides a mock function with given fields: ctx, lease
+func (_m *MockKubeClientConnector) DeleteLease(ctx context.Context, lease *coordinationv1.Lease) error {
+	ret := _m.Called(ctx, lease)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *coordinationv1.Lease) error); ok {
+		r0 = rf(ctx, lease)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteObject provides a mock function with given fields: ctx, obj
+func (_m *MockKubeClientConnector) DeleteObject(ctx context.Context, obj runtime.Object) error {
+	ret := _m.Called(ctx, obj)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, runtime.Object) error); ok {
+		r0 = rf(ctx, obj)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePod provides a mock function with given fields: ctx, namespace, name
+func (_m *MockKubeClientConnector) DeletePod(ctx context.Context, namespace string, name string) error {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteVMAgent provides a mock function with given fields: ctx, namespace, name
+func (_m *MockKubeClientConnector) DeleteVMAgent(ctx context.Context, namespace string, name string) error {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DoCRDWait provides a mock function with given fields: ctx, name
+func (_m *MockKubeClientConnector) DoCRDWait(ctx context.Context, name string) error {
+	ret := _m.Called(ctx, name)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(runtime.Object) error); ok {
-		r0 = rf(obj)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, name)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -142,6 +276,66 @@ func (_m *MockKubeClientConnector) DoRolloutWait(ctx context.Context, key types.
 	return r0
 }
 
+// Exec provides a mock function with given fields: ctx, namespace, pod, container, opts
+func (_m *MockKubeClientConnector) Exec(ctx context.Context, namespace string, pod string, container string, opts ExecOptions) error {
+	ret := _m.Called(ctx, namespace, pod, container, opts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, ExecOptions) error); ok {
+		r0 = rf(ctx, namespace, pod, container, opts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ForwardPort provides a mock function with given fields: ctx, namespace, pod, localPort, port
+func (_m *MockKubeClientConnector) ForwardPort(ctx context.Context, namespace string, pod string, localPort int, port int) (*PortForward, error) {
+	ret := _m.Called(ctx, namespace, pod, localPort, port)
+
+	var r0 *PortForward
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, int) *PortForward); ok {
+		r0 = rf(ctx, namespace, pod, localPort, port)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*PortForward)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int, int) error); ok {
+		r1 = rf(ctx, namespace, pod, localPort, port)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GenerateInClusterKubeConfig provides a mock function with given fields:
+func (_m *MockKubeClientConnector) GenerateInClusterKubeConfig() ([]byte, error) {
+	ret := _m.Called()
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func() []byte); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GenerateKubeConfig provides a mock function with given fields: secret
 func (_m *MockKubeClientConnector) GenerateKubeConfig(secret *corev1.Secret) ([]byte, error) {
 	ret := _m.Called(secret)
@@ -165,6 +359,98 @@ func (_m *MockKubeClientConnector) GenerateKubeConfig(secret *corev1.Secret) ([]
 	return r0, r1
 }
 
+// GenerateKubeConfigWithToken provides a mock function with given fields: user, secret
+func (_m *MockKubeClientConnector) GenerateKubeConfigWithToken(user string, secret *corev1.Secret) ([]byte, error) {
+	ret := _m.Called(user, secret)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string, *corev1.Secret) []byte); ok {
+		r0 = rf(user, secret)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, *corev1.Secret) error); ok {
+		r1 = rf(user, secret)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCatalogSource provides a mock function with given fields: ctx, namespace, name
+func (_m *MockKubeClientConnector) GetCatalogSource(ctx context.Context, namespace string, name string) (*v1alpha1.CatalogSource, error) {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 *v1alpha1.CatalogSource
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1alpha1.CatalogSource); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1alpha1.CatalogSource)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetClusterServiceVersion provides a mock function with given fields: ctx, key
+func (_m *MockKubeClientConnector) GetClusterServiceVersion(ctx context.Context, key types.NamespacedName) (*v1alpha1.ClusterServiceVersion, error) {
+	ret := _m.Called(ctx, key)
+
+	var r0 *v1alpha1.ClusterServiceVersion
+	if rf, ok := ret.Get(0).(func(context.Context, types.NamespacedName) *v1alpha1.ClusterServiceVersion); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1alpha1.ClusterServiceVersion)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, types.NamespacedName) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetConfigMap provides a mock function with given fields: ctx, namespace, name
+func (_m *MockKubeClientConnector) GetConfigMap(ctx context.Context, namespace string, name string) (*corev1.ConfigMap, error) {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 *corev1.ConfigMap
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *corev1.ConfigMap); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*corev1.ConfigMap)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDatabaseCluster provides a mock function with given fields: ctx, name
 func (_m *MockKubeClientConnector) GetDatabaseCluster(ctx context.Context, name string) (*apiv1.DatabaseCluster, error) {
 	ret := _m.Called(ctx, name)
@@ -188,6 +474,29 @@ func (_m *MockKubeClientConnector) GetDatabaseCluster(ctx context.Context, name
 	return r0, r1
 }
 
+// GetDatabaseClusterBackup provides a mock function with given fields: ctx, name
+func (_m *MockKubeClientConnector) GetDatabaseClusterBackup(ctx context.Context, name string) (*database.DatabaseClusterBackup, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 *database.DatabaseClusterBackup
+	if rf, ok := ret.Get(0).(func(context.Context, string) *database.DatabaseClusterBackup); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*database.DatabaseClusterBackup)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDeployment provides a mock function with given fields: ctx, name
 func (_m *MockKubeClientConnector) GetDeployment(ctx context.Context, name string) (*appsv1.Deployment, error) {
 	ret := _m.Called(ctx, name)
@@ -219,12 +528,524 @@ func (_m *MockKubeClientConnector) GetEvents(ctx context.Context, name string) (
 	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
 		r0 = rf(ctx, name)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInstallPlan provides a mock function with given fields: ctx, namespace, name
+func (_m *MockKubeClientConnector) GetInstallPlan(ctx context.Context, namespace string, name string) (*v1alpha1.InstallPlan, error) {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 *v1alpha1.InstallPlan
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1alpha1.InstallPlan); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1alpha1.InstallPlan)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLease provides a mock function with given fields: ctx, namespace, name
+func (_m *MockKubeClientConnector) GetLease(ctx context.Context, namespace string, name string) (*coordinationv1.Lease, error) {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 *coordinationv1.Lease
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *coordinationv1.Lease); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coordinationv1.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLogs provides a mock function with given fields: ctx, pod, container
+func (_m *MockKubeClientConnector) GetLogs(ctx context.Context, pod string, container string) (string, error) {
+	ret := _m.Called(ctx, pod, container)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, pod, container)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, pod, container)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNodeKubeletConfig provides a mock function with given fields: ctx, name
+func (_m *MockKubeClientConnector) GetNodeKubeletConfig(ctx context.Context, name string) ([]byte, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(context.Context, string) []byte); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNodeStatsSummary provides a mock function with given fields: ctx, name
+func (_m *MockKubeClientConnector) GetNodeStatsSummary(ctx context.Context, name string) ([]byte, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(context.Context, string) []byte); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNodes provides a mock function with given fields: ctx
+func (_m *MockKubeClientConnector) GetNodes(ctx context.Context) (*corev1.NodeList, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *corev1.NodeList
+	if rf, ok := ret.Get(0).(func(context.Context) *corev1.NodeList); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*corev1.NodeList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOIDCIssuer provides a mock function with given fields: ctx
+func (_m *MockKubeClientConnector) GetOIDCIssuer(ctx context.Context) (string, error) {
+	ret := _m.Called(ctx)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOperatorGroup provides a mock function with given fields: ctx, namespace, name
+func (_m *MockKubeClientConnector) GetOperatorGroup(ctx context.Context, namespace string, name string) (*v1.OperatorGroup, error) {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 *v1.OperatorGroup
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1.OperatorGroup); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.OperatorGroup)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPersistentVolumeClaims provides a mock function with given fields: ctx, namespace, labelSelector
+func (_m *MockKubeClientConnector) GetPersistentVolumeClaims(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*corev1.PersistentVolumeClaimList, error) {
+	ret := _m.Called(ctx, namespace, labelSelector)
+
+	var r0 *corev1.PersistentVolumeClaimList
+	if rf, ok := ret.Get(0).(func(context.Context, string, *metav1.LabelSelector) *corev1.PersistentVolumeClaimList); ok {
+		r0 = rf(ctx, namespace, labelSelector)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*corev1.PersistentVolumeClaimList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *metav1.LabelSelector) error); ok {
+		r1 = rf(ctx, namespace, labelSelector)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPersistentVolumes provides a mock function with given fields: ctx
+func (_m *MockKubeClientConnector) GetPersistentVolumes(ctx context.Context) (*corev1.PersistentVolumeList, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *corev1.PersistentVolumeList
+	if rf, ok := ret.Get(0).(func(context.Context) *corev1.PersistentVolumeList); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*corev1.PersistentVolumeList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPods provides a mock function with given fields: ctx, namespace, labelSelector
+func (_m *MockKubeClientConnector) GetPods(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*corev1.PodList, error) {
+	ret := _m.Called(ctx, namespace, labelSelector)
+
+	var r0 *corev1.PodList
+	if rf, ok := ret.Get(0).(func(context.Context, string, *metav1.LabelSelector) *corev1.PodList); ok {
+		r0 = rf(ctx, namespace, labelSelector)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*corev1.PodList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *metav1.LabelSelector) error); ok {
+		r1 = rf(ctx, namespace, labelSelector)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSecret provides a mock function with given fields: ctx, name
+func (_m *MockKubeClientConnector) GetSecret(ctx context.Context, name string) (*corev1.Secret, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 *corev1.Secret
+	if rf, ok := ret.Get(0).(func(context.Context, string) *corev1.Secret); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*corev1.Secret)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSecretsForServiceAccount provides a mock function with given fields: ctx, accountName
+func (_m *MockKubeClientConnector) GetSecretsForServiceAccount(ctx context.Context, accountName string) (*corev1.Secret, error) {
+	ret := _m.Called(ctx, accountName)
+
+	var r0 *corev1.Secret
+	if rf, ok := ret.Get(0).(func(context.Context, string) *corev1.Secret); ok {
+		r0 = rf(ctx, accountName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*corev1.Secret)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, accountName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetServerVersion provides a mock function with given fields: ctx
+func (_m *MockKubeClientConnector) GetServerVersion(ctx context.Context) (*version.Info, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *version.Info
+	if rf, ok := ret.Get(0).(func(context.Context) *version.Info); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*version.Info)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetService provides a mock function with given fields: ctx, namespace, name
+func (_m *MockKubeClientConnector) GetService(ctx context.Context, namespace string, name string) (*corev1.Service, error) {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 *corev1.Service
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *corev1.Service); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*corev1.Service)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetServiceAccount provides a mock function with given fields: ctx, namespace, name
+func (_m *MockKubeClientConnector) GetServiceAccount(ctx context.Context, namespace string, name string) (*corev1.ServiceAccount, error) {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 *corev1.ServiceAccount
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *corev1.ServiceAccount); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*corev1.ServiceAccount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStorageClasses provides a mock function with given fields: ctx
+func (_m *MockKubeClientConnector) GetStorageClasses(ctx context.Context) (*storagev1.StorageClassList, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *storagev1.StorageClassList
+	if rf, ok := ret.Get(0).(func(context.Context) *storagev1.StorageClassList); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storagev1.StorageClassList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscription provides a mock function with given fields: ctx, namespace, name
+func (_m *MockKubeClientConnector) GetSubscription(ctx context.Context, namespace string, name string) (*v1alpha1.Subscription, error) {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 *v1alpha1.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1alpha1.Subscription); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1alpha1.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptionCSV provides a mock function with given fields: ctx, subKey
+func (_m *MockKubeClientConnector) GetSubscriptionCSV(ctx context.Context, subKey types.NamespacedName) (types.NamespacedName, error) {
+	ret := _m.Called(ctx, subKey)
+
+	var r0 types.NamespacedName
+	if rf, ok := ret.Get(0).(func(context.Context, types.NamespacedName) types.NamespacedName); ok {
+		r0 = rf(ctx, subKey)
+	} else {
+		r0 = ret.Get(0).(types.NamespacedName)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, types.NamespacedName) error); ok {
+		r1 = rf(ctx, subKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HasAPIGroup provides a mock function with given fields: ctx, name
+func (_m *MockKubeClientConnector) HasAPIGroup(ctx context.Context, name string) (bool, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsecureTLS provides a mock function with given fields:
+func (_m *MockKubeClientConnector) InsecureTLS() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// ListCRDs provides a mock function with given fields: ctx, labelSelector
+func (_m *MockKubeClientConnector) ListCRDs(ctx context.Context, labelSelector *metav1.LabelSelector) (*apiextensionsv1.CustomResourceDefinitionList, error) {
+	ret := _m.Called(ctx, labelSelector)
+
+	var r0 *apiextensionsv1.CustomResourceDefinitionList
+	if rf, ok := ret.Get(0).(func(context.Context, *metav1.LabelSelector) *apiextensionsv1.CustomResourceDefinitionList); ok {
+		r0 = rf(ctx, labelSelector)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*apiextensionsv1.CustomResourceDefinitionList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *metav1.LabelSelector) error); ok {
+		r1 = rf(ctx, labelSelector)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCRs provides a mock function with given fields: ctx, namespace, gvr, labelSelector
+func (_m *MockKubeClientConnector) ListCRs(ctx context.Context, namespace string, gvr schema.GroupVersionResource, labelSelector *metav1.LabelSelector) (*unstructured.UnstructuredList, error) {
+	ret := _m.Called(ctx, namespace, gvr, labelSelector)
+
+	var r0 *unstructured.UnstructuredList
+	if rf, ok := ret.Get(0).(func(context.Context, string, schema.GroupVersionResource, *metav1.LabelSelector) *unstructured.UnstructuredList); ok {
+		r0 = rf(ctx, namespace, gvr, labelSelector)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*unstructured.UnstructuredList)
+		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, name)
+	if rf, ok := ret.Get(1).(func(context.Context, string, schema.GroupVersionResource, *metav1.LabelSelector) error); ok {
+		r1 = rf(ctx, namespace, gvr, labelSelector)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -232,22 +1053,22 @@ func (_m *MockKubeClientConnector) GetEvents(ctx context.Context, name string) (
 	return r0, r1
 }
 
-// GetInstallPlan provides a mock function with given fields: ctx, namespace, name
-func (_m *MockKubeClientConnector) GetInstallPlan(ctx context.Context, namespace string, name string) (*v1alpha1.InstallPlan, error) {
-	ret := _m.Called(ctx, namespace, name)
+// ListClusterServiceVersion provides a mock function with given fields: ctx, namespace
+func (_m *MockKubeClientConnector) ListClusterServiceVersion(ctx context.Context, namespace string) (*v1alpha1.ClusterServiceVersionList, error) {
+	ret := _m.Called(ctx, namespace)
 
-	var r0 *v1alpha1.InstallPlan
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1alpha1.InstallPlan); ok {
-		r0 = rf(ctx, namespace, name)
+	var r0 *v1alpha1.ClusterServiceVersionList
+	if rf, ok := ret.Get(0).(func(context.Context, string) *v1alpha1.ClusterServiceVersionList); ok {
+		r0 = rf(ctx, namespace)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*v1alpha1.InstallPlan)
+			r0 = ret.Get(0).(*v1alpha1.ClusterServiceVersionList)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, namespace, name)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, namespace)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -255,20 +1076,22 @@ func (_m *MockKubeClientConnector) GetInstallPlan(ctx context.Context, namespace
 	return r0, r1
 }
 
-// GetLogs provides a mock function with given fields: ctx, pod, container
-func (_m *MockKubeClientConnector) GetLogs(ctx context.Context, pod string, container string) (string, error) {
-	ret := _m.Called(ctx, pod, container)
+// ListDatabaseClusterBackups provides a mock function with given fields: ctx
+func (_m *MockKubeClientConnector) ListDatabaseClusterBackups(ctx context.Context) (*database.DatabaseClusterBackupList, error) {
+	ret := _m.Called(ctx)
 
-	var r0 string
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
-		r0 = rf(ctx, pod, container)
+	var r0 *database.DatabaseClusterBackupList
+	if rf, ok := ret.Get(0).(func(context.Context) *database.DatabaseClusterBackupList); ok {
+		r0 = rf(ctx)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*database.DatabaseClusterBackupList)
+		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, pod, container)
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -276,16 +1099,16 @@ func (_m *MockKubeClientConnector) GetLogs(ctx context.Context, pod string, cont
 	return r0, r1
 }
 
-// GetNodes provides a mock function with given fields: ctx
-func (_m *MockKubeClientConnector) GetNodes(ctx context.Context) (*corev1.NodeList, error) {
+// ListDatabaseClusters provides a mock function with given fields: ctx
+func (_m *MockKubeClientConnector) ListDatabaseClusters(ctx context.Context) (*apiv1.DatabaseClusterList, error) {
 	ret := _m.Called(ctx)
 
-	var r0 *corev1.NodeList
-	if rf, ok := ret.Get(0).(func(context.Context) *corev1.NodeList); ok {
+	var r0 *apiv1.DatabaseClusterList
+	if rf, ok := ret.Get(0).(func(context.Context) *apiv1.DatabaseClusterList); ok {
 		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*corev1.NodeList)
+			r0 = ret.Get(0).(*apiv1.DatabaseClusterList)
 		}
 	}
 
@@ -299,22 +1122,22 @@ func (_m *MockKubeClientConnector) GetNodes(ctx context.Context) (*corev1.NodeLi
 	return r0, r1
 }
 
-// GetOperatorGroup provides a mock function with given fields: ctx, namespace, name
-func (_m *MockKubeClientConnector) GetOperatorGroup(ctx context.Context, namespace string, name string) (*v1.OperatorGroup, error) {
-	ret := _m.Called(ctx, namespace, name)
+// ListInstallPlans provides a mock function with given fields: ctx, namespace
+func (_m *MockKubeClientConnector) ListInstallPlans(ctx context.Context, namespace string) (*v1alpha1.InstallPlanList, error) {
+	ret := _m.Called(ctx, namespace)
 
-	var r0 *v1.OperatorGroup
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1.OperatorGroup); ok {
-		r0 = rf(ctx, namespace, name)
+	var r0 *v1alpha1.InstallPlanList
+	if rf, ok := ret.Get(0).(func(context.Context, string) *v1alpha1.InstallPlanList); ok {
+		r0 = rf(ctx, namespace)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*v1.OperatorGroup)
+			r0 = ret.Get(0).(*v1alpha1.InstallPlanList)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, namespace, name)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, namespace)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -322,22 +1145,22 @@ func (_m *MockKubeClientConnector) GetOperatorGroup(ctx context.Context, namespa
 	return r0, r1
 }
 
-// GetPersistentVolumes provides a mock function with given fields: ctx
-func (_m *MockKubeClientConnector) GetPersistentVolumes(ctx context.Context) (*corev1.PersistentVolumeList, error) {
-	ret := _m.Called(ctx)
+// ListOperatorGroups provides a mock function with given fields: ctx, namespace
+func (_m *MockKubeClientConnector) ListOperatorGroups(ctx context.Context, namespace string) (*v1.OperatorGroupList, error) {
+	ret := _m.Called(ctx, namespace)
 
-	var r0 *corev1.PersistentVolumeList
-	if rf, ok := ret.Get(0).(func(context.Context) *corev1.PersistentVolumeList); ok {
-		r0 = rf(ctx)
+	var r0 *v1.OperatorGroupList
+	if rf, ok := ret.Get(0).(func(context.Context, string) *v1.OperatorGroupList); ok {
+		r0 = rf(ctx, namespace)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*corev1.PersistentVolumeList)
+			r0 = ret.Get(0).(*v1.OperatorGroupList)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, namespace)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -345,16 +1168,16 @@ func (_m *MockKubeClientConnector) GetPersistentVolumes(ctx context.Context) (*c
 	return r0, r1
 }
 
-// GetPods provides a mock function with given fields: ctx, namespace, labelSelector
-func (_m *MockKubeClientConnector) GetPods(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*corev1.PodList, error) {
+// ListPackageManifests provides a mock function with given fields: ctx, namespace, labelSelector
+func (_m *MockKubeClientConnector) ListPackageManifests(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*packagev1.PackageManifestList, error) {
 	ret := _m.Called(ctx, namespace, labelSelector)
 
-	var r0 *corev1.PodList
-	if rf, ok := ret.Get(0).(func(context.Context, string, *metav1.LabelSelector) *corev1.PodList); ok {
+	var r0 *packagev1.PackageManifestList
+	if rf, ok := ret.Get(0).(func(context.Context, string, *metav1.LabelSelector) *packagev1.PackageManifestList); ok {
 		r0 = rf(ctx, namespace, labelSelector)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*corev1.PodList)
+			r0 = ret.Get(0).(*packagev1.PackageManifestList)
 		}
 	}
 
@@ -368,22 +1191,22 @@ func (_m *MockKubeClientConnector) GetPods(ctx context.Context, namespace string
 	return r0, r1
 }
 
-// GetSecret provides a mock function with given fields: ctx, name
-func (_m *MockKubeClientConnector) GetSecret(ctx context.Context, name string) (*corev1.Secret, error) {
-	ret := _m.Called(ctx, name)
+// ListSecrets provides a mock function with given fields: ctx
+func (_m *MockKubeClientConnector) ListSecrets(ctx context.Context) (*corev1.SecretList, error) {
+	ret := _m.Called(ctx)
 
-	var r0 *corev1.Secret
-	if rf, ok := ret.Get(0).(func(context.Context, string) *corev1.Secret); ok {
-		r0 = rf(ctx, name)
+	var r0 *corev1.SecretList
+	if rf, ok := ret.Get(0).(func(context.Context) *corev1.SecretList); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*corev1.Secret)
+			r0 = ret.Get(0).(*corev1.SecretList)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, name)
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -391,22 +1214,22 @@ func (_m *MockKubeClientConnector) GetSecret(ctx context.Context, name string) (
 	return r0, r1
 }
 
-// GetSecretsForServiceAccount provides a mock function with given fields: ctx, accountName
-func (_m *MockKubeClientConnector) GetSecretsForServiceAccount(ctx context.Context, accountName string) (*corev1.Secret, error) {
-	ret := _m.Called(ctx, accountName)
+// ListSubscriptions provides a mock function with given fields: ctx, namespace
+func (_m *MockKubeClientConnector) ListSubscriptions(ctx context.Context, namespace string) (*v1alpha1.SubscriptionList, error) {
+	ret := _m.Called(ctx, namespace)
 
-	var r0 *corev1.Secret
-	if rf, ok := ret.Get(0).(func(context.Context, string) *corev1.Secret); ok {
-		r0 = rf(ctx, accountName)
+	var r0 *v1alpha1.SubscriptionList
+	if rf, ok := ret.Get(0).(func(context.Context, string) *v1alpha1.SubscriptionList); ok {
+		r0 = rf(ctx, namespace)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*corev1.Secret)
+			r0 = ret.Get(0).(*v1alpha1.SubscriptionList)
 		}
 	}
 
 	var r1 error
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, accountName)
+		r1 = rf(ctx, namespace)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -414,22 +1237,22 @@ func (_m *MockKubeClientConnector) GetSecretsForServiceAccount(ctx context.Conte
 	return r0, r1
 }
 
-// GetServerVersion provides a mock function with given fields:
-func (_m *MockKubeClientConnector) GetServerVersion() (*version.Info, error) {
-	ret := _m.Called()
+// ListVMAgents provides a mock function with given fields: ctx, namespace, labels
+func (_m *MockKubeClientConnector) ListVMAgents(ctx context.Context, namespace string, labels map[string]string) (*v1beta1.VMAgentList, error) {
+	ret := _m.Called(ctx, namespace, labels)
 
-	var r0 *version.Info
-	if rf, ok := ret.Get(0).(func() *version.Info); ok {
-		r0 = rf()
+	var r0 *v1beta1.VMAgentList
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) *v1beta1.VMAgentList); ok {
+		r0 = rf(ctx, namespace, labels)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*version.Info)
+			r0 = ret.Get(0).(*v1beta1.VMAgentList)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, string, map[string]string) error); ok {
+		r1 = rf(ctx, namespace, labels)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -437,22 +1260,34 @@ func (_m *MockKubeClientConnector) GetServerVersion() (*version.Info, error) {
 	return r0, r1
 }
 
-// GetStorageClasses provides a mock function with given fields: ctx
-func (_m *MockKubeClientConnector) GetStorageClasses(ctx context.Context) (*storagev1.StorageClassList, error) {
-	ret := _m.Called(ctx)
+// Namespace provides a mock function with given fields:
+func (_m *MockKubeClientConnector) Namespace() string {
+	ret := _m.Called()
 
-	var r0 *storagev1.StorageClassList
-	if rf, ok := ret.Get(0).(func(context.Context) *storagev1.StorageClassList); ok {
-		r0 = rf(ctx)
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*storagev1.StorageClassList)
-		}
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// ObjectExists provides a mock function with given fields: ctx, obj
+func (_m *MockKubeClientConnector) ObjectExists(ctx context.Context, obj runtime.Object) (bool, error) {
+	ret := _m.Called(ctx, obj)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, runtime.Object) bool); ok {
+		r0 = rf(ctx, obj)
+	} else {
+		r0 = ret.Get(0).(bool)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, runtime.Object) error); ok {
+		r1 = rf(ctx, obj)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -460,22 +1295,22 @@ func (_m *MockKubeClientConnector) GetStorageClasses(ctx context.Context) (*stor
 	return r0, r1
 }
 
-// GetSubscription provides a mock function with given fields: ctx, namespace, name
-func (_m *MockKubeClientConnector) GetSubscription(ctx context.Context, namespace string, name string) (*v1alpha1.Subscription, error) {
-	ret := _m.Called(ctx, namespace, name)
+// PatchDatabaseCluster provides a mock function with given fields: ctx, name, patchType, patch
+func (_m *MockKubeClientConnector) PatchDatabaseCluster(ctx context.Context, name string, patchType types.PatchType, patch []byte) (*apiv1.DatabaseCluster, error) {
+	ret := _m.Called(ctx, name, patchType, patch)
 
-	var r0 *v1alpha1.Subscription
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1alpha1.Subscription); ok {
-		r0 = rf(ctx, namespace, name)
+	var r0 *apiv1.DatabaseCluster
+	if rf, ok := ret.Get(0).(func(context.Context, string, types.PatchType, []byte) *apiv1.DatabaseCluster); ok {
+		r0 = rf(ctx, name, patchType, patch)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*v1alpha1.Subscription)
+			r0 = ret.Get(0).(*apiv1.DatabaseCluster)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, namespace, name)
+	if rf, ok := ret.Get(1).(func(context.Context, string, types.PatchType, []byte) error); ok {
+		r1 = rf(ctx, name, patchType, patch)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -483,43 +1318,79 @@ func (_m *MockKubeClientConnector) GetSubscription(ctx context.Context, namespac
 	return r0, r1
 }
 
-// GetSubscriptionCSV provides a mock function with given fields: ctx, subKey
-func (_m *MockKubeClientConnector) GetSubscriptionCSV(ctx context.Context, subKey types.NamespacedName) (types.NamespacedName, error) {
-	ret := _m.Called(ctx, subKey)
+// ServerSideApply provides a mock function with given fields: ctx, obj
+func (_m *MockKubeClientConnector) ServerSideApply(ctx context.Context, obj runtime.Object) error {
+	ret := _m.Called(ctx, obj)
 
-	var r0 types.NamespacedName
-	if rf, ok := ret.Get(0).(func(context.Context, types.NamespacedName) types.NamespacedName); ok {
-		r0 = rf(ctx, subKey)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, runtime.Object) error); ok {
+		r0 = rf(ctx, obj)
 	} else {
-		r0 = ret.Get(0).(types.NamespacedName)
+		r0 = ret.Error(0)
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, types.NamespacedName) error); ok {
-		r1 = rf(ctx, subKey)
+	return r0
+}
+
+// SetAuditor provides a mock function with given fields: auditor
+func (_m *MockKubeClientConnector) SetAuditor(auditor AuditFunc) {
+	_m.Called(auditor)
+}
+
+// SetCommonMetadata provides a mock function with given fields: labels, annotations
+func (_m *MockKubeClientConnector) SetCommonMetadata(labels map[string]string, annotations map[string]string) {
+	_m.Called(labels, annotations)
+}
+
+// SetRetries provides a mock function with given fields: enabled
+func (_m *MockKubeClientConnector) SetRetries(enabled bool) {
+	_m.Called(enabled)
+}
+
+// StartCache provides a mock function with given fields: ctx, resync
+func (_m *MockKubeClientConnector) StartCache(ctx context.Context, resync time.Duration) error {
+	ret := _m.Called(ctx, resync)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) error); ok {
+		r0 = rf(ctx, resync)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// ListCRDs provides a mock function with given fields: ctx, labelSelector
-func (_m *MockKubeClientConnector) ListCRDs(ctx context.Context, labelSelector *metav1.LabelSelector) (*apiextensionsv1.CustomResourceDefinitionList, error) {
-	ret := _m.Called(ctx, labelSelector)
+// ThreeWayMerge provides a mock function with given fields: ctx, obj
+func (_m *MockKubeClientConnector) ThreeWayMerge(ctx context.Context, obj runtime.Object) error {
+	ret := _m.Called(ctx, obj)
 
-	var r0 *apiextensionsv1.CustomResourceDefinitionList
-	if rf, ok := ret.Get(0).(func(context.Context, *metav1.LabelSelector) *apiextensionsv1.CustomResourceDefinitionList); ok {
-		r0 = rf(ctx, labelSelector)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, runtime.Object) error); ok {
+		r0 = rf(ctx, obj)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCRStatus provides a mock function with given fields: ctx, gvr, obj
+func (_m *MockKubeClientConnector) UpdateCRStatus(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	ret := _m.Called(ctx, gvr, obj)
+
+	var r0 *unstructured.Unstructured
+	if rf, ok := ret.Get(0).(func(context.Context, schema.GroupVersionResource, *unstructured.Unstructured) *unstructured.Unstructured); ok {
+		r0 = rf(ctx, gvr, obj)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*apiextensionsv1.CustomResourceDefinitionList)
+			r0 = ret.Get(0).(*unstructured.Unstructured)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *metav1.LabelSelector) error); ok {
-		r1 = rf(ctx, labelSelector)
+	if rf, ok := ret.Get(1).(func(context.Context, schema.GroupVersionResource, *unstructured.Unstructured) error); ok {
+		r1 = rf(ctx, gvr, obj)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -527,22 +1398,22 @@ func (_m *MockKubeClientConnector) ListCRDs(ctx context.Context, labelSelector *
 	return r0, r1
 }
 
-// ListCRs provides a mock function with given fields: ctx, namespace, gvr, labelSelector
-func (_m *MockKubeClientConnector) ListCRs(ctx context.Context, namespace string, gvr schema.GroupVersionResource, labelSelector *metav1.LabelSelector) (*unstructured.UnstructuredList, error) {
-	ret := _m.Called(ctx, namespace, gvr, labelSelector)
+// UpdateInstallPlan provides a mock function with given fields: ctx, namespace, installPlan
+func (_m *MockKubeClientConnector) UpdateInstallPlan(ctx context.Context, namespace string, installPlan *v1alpha1.InstallPlan) (*v1alpha1.InstallPlan, error) {
+	ret := _m.Called(ctx, namespace, installPlan)
 
-	var r0 *unstructured.UnstructuredList
-	if rf, ok := ret.Get(0).(func(context.Context, string, schema.GroupVersionResource, *metav1.LabelSelector) *unstructured.UnstructuredList); ok {
-		r0 = rf(ctx, namespace, gvr, labelSelector)
+	var r0 *v1alpha1.InstallPlan
+	if rf, ok := ret.Get(0).(func(context.Context, string, *v1alpha1.InstallPlan) *v1alpha1.InstallPlan); ok {
+		r0 = rf(ctx, namespace, installPlan)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*unstructured.UnstructuredList)
+			r0 = ret.Get(0).(*v1alpha1.InstallPlan)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, schema.GroupVersionResource, *metav1.LabelSelector) error); ok {
-		r1 = rf(ctx, namespace, gvr, labelSelector)
+	if rf, ok := ret.Get(1).(func(context.Context, string, *v1alpha1.InstallPlan) error); ok {
+		r1 = rf(ctx, namespace, installPlan)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -550,22 +1421,22 @@ func (_m *MockKubeClientConnector) ListCRs(ctx context.Context, namespace string
 	return r0, r1
 }
 
-// ListDatabaseClusters provides a mock function with given fields: ctx
-func (_m *MockKubeClientConnector) ListDatabaseClusters(ctx context.Context) (*apiv1.DatabaseClusterList, error) {
-	ret := _m.Called(ctx)
+// UpdateLease provides a mock function with given fields: ctx, lease
+func (_m *MockKubeClientConnector) UpdateLease(ctx context.Context, lease *coordinationv1.Lease) (*coordinationv1.Lease, error) {
+	ret := _m.Called(ctx, lease)
 
-	var r0 *apiv1.DatabaseClusterList
-	if rf, ok := ret.Get(0).(func(context.Context) *apiv1.DatabaseClusterList); ok {
-		r0 = rf(ctx)
+	var r0 *coordinationv1.Lease
+	if rf, ok := ret.Get(0).(func(context.Context, *coordinationv1.Lease) *coordinationv1.Lease); ok {
+		r0 = rf(ctx, lease)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*apiv1.DatabaseClusterList)
+			r0 = ret.Get(0).(*coordinationv1.Lease)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, *coordinationv1.Lease) error); ok {
+		r1 = rf(ctx, lease)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -573,22 +1444,22 @@ func (_m *MockKubeClientConnector) ListDatabaseClusters(ctx context.Context) (*a
 	return r0, r1
 }
 
-// ListSecrets provides a mock function with given fields: ctx
-func (_m *MockKubeClientConnector) ListSecrets(ctx context.Context) (*corev1.SecretList, error) {
-	ret := _m.Called(ctx)
+// UpdateServiceAccount provides a mock function with given fields: ctx, account
+func (_m *MockKubeClientConnector) UpdateServiceAccount(ctx context.Context, account *corev1.ServiceAccount) (*corev1.ServiceAccount, error) {
+	ret := _m.Called(ctx, account)
 
-	var r0 *corev1.SecretList
-	if rf, ok := ret.Get(0).(func(context.Context) *corev1.SecretList); ok {
-		r0 = rf(ctx)
+	var r0 *corev1.ServiceAccount
+	if rf, ok := ret.Get(0).(func(context.Context, *corev1.ServiceAccount) *corev1.ServiceAccount); ok {
+		r0 = rf(ctx, account)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*corev1.SecretList)
+			r0 = ret.Get(0).(*corev1.ServiceAccount)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, *corev1.ServiceAccount) error); ok {
+		r1 = rf(ctx, account)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -596,22 +1467,22 @@ func (_m *MockKubeClientConnector) ListSecrets(ctx context.Context) (*corev1.Sec
 	return r0, r1
 }
 
-// ListSubscriptions provides a mock function with given fields: ctx, namespace
-func (_m *MockKubeClientConnector) ListSubscriptions(ctx context.Context, namespace string) (*v1alpha1.SubscriptionList, error) {
-	ret := _m.Called(ctx, namespace)
+// UpdateSubscription provides a mock function with given fields: ctx, namespace, subscription
+func (_m *MockKubeClientConnector) UpdateSubscription(ctx context.Context, namespace string, subscription *v1alpha1.Subscription) (*v1alpha1.Subscription, error) {
+	ret := _m.Called(ctx, namespace, subscription)
 
-	var r0 *v1alpha1.SubscriptionList
-	if rf, ok := ret.Get(0).(func(context.Context, string) *v1alpha1.SubscriptionList); ok {
-		r0 = rf(ctx, namespace)
+	var r0 *v1alpha1.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, string, *v1alpha1.Subscription) *v1alpha1.Subscription); ok {
+		r0 = rf(ctx, namespace, subscription)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*v1alpha1.SubscriptionList)
+			r0 = ret.Get(0).(*v1alpha1.Subscription)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, namespace)
+	if rf, ok := ret.Get(1).(func(context.Context, string, *v1alpha1.Subscription) error); ok {
+		r1 = rf(ctx, namespace, subscription)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -619,22 +1490,22 @@ func (_m *MockKubeClientConnector) ListSubscriptions(ctx context.Context, namesp
 	return r0, r1
 }
 
-// UpdateInstallPlan provides a mock function with given fields: ctx, namespace, installPlan
-func (_m *MockKubeClientConnector) UpdateInstallPlan(ctx context.Context, namespace string, installPlan *v1alpha1.InstallPlan) (*v1alpha1.InstallPlan, error) {
-	ret := _m.Called(ctx, namespace, installPlan)
+// WatchDatabaseCluster provides a mock function with given fields: ctx, name, resourceVersion
+func (_m *MockKubeClientConnector) WatchDatabaseCluster(ctx context.Context, name string, resourceVersion string) (watch.Interface, error) {
+	ret := _m.Called(ctx, name, resourceVersion)
 
-	var r0 *v1alpha1.InstallPlan
-	if rf, ok := ret.Get(0).(func(context.Context, string, *v1alpha1.InstallPlan) *v1alpha1.InstallPlan); ok {
-		r0 = rf(ctx, namespace, installPlan)
+	var r0 watch.Interface
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) watch.Interface); ok {
+		r0 = rf(ctx, name, resourceVersion)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*v1alpha1.InstallPlan)
+			r0 = ret.Get(0).(watch.Interface)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, *v1alpha1.InstallPlan) error); ok {
-		r1 = rf(ctx, namespace, installPlan)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, name, resourceVersion)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,94 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// PortForward is a running forwarding of a local port to a port of a pod.
+type PortForward struct {
+	// Local is the forwarded local port.
+	Local uint16
+	stop  func()
+	done  chan error
+}
+
+// Stop stops the forwarding.
+func (f *PortForward) Stop() {
+	f.stop()
+}
+
+// Done returns a channel receiving the error the forwarding ended with, e.g. when
+// the connection to the pod is lost. Nil is received if the forwarding was stopped.
+func (f *PortForward) Done() <-chan error {
+	return f.done
+}
+
+// ForwardPort forwards the local port to the port of the pod. A random local port is
+// used if localPort is zero. The port is bound to the loopback interface only.
+func (c *Client) ForwardPort(ctx context.Context, namespace, pod string, localPort, port int) (*PortForward, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(c.restConfig)
+	if err != nil {
+		return nil, err
+	}
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	fw, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("%d:%d", localPort, port)},
+		stopCh, readyCh, io.Discard, io.Discard)
+	if err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	f := &PortForward{
+		stop: func() { once.Do(func() { close(stopCh) }) },
+		done: make(chan error, 1),
+	}
+	go func() {
+		f.done <- fw.ForwardPorts()
+	}()
+
+	select {
+	case <-readyCh:
+	case err := <-f.done:
+		return nil, errors.Wrapf(err, "cannot forward port %d of pod %s", port, pod)
+	case <-ctx.Done():
+		f.Stop()
+		return nil, ctx.Err()
+	}
+	ports, err := fw.GetPorts()
+	if err != nil {
+		f.Stop()
+		return nil, err
+	}
+	f.Local = ports[0].Local
+	return f, nil
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/pkg/metrics"
+	"github.com/pkg/errors"
+)
+
+const (
+	retryInitialInterval = 200 * time.Millisecond
+	retryMaxInterval     = 5 * time.Second
+	retryAttempts        = 5
+)
+
+// transientServerErrors are the messages of internal server errors which are
+// caused by a temporary state of etcd and succeed if the request is repeated.
+var transientServerErrors = []string{
+	"etcdserver: leader changed",
+	"etcdserver: request timed out",
+	"etcdserver: too many requests",
+}
+
+// retryTransport repeats requests failed with transient errors of the API server
+// with a bounded exponential backoff.
+type retryTransport struct {
+	next    http.RoundTripper
+	enabled *atomic.Bool
+}
+
+// newRetryTransport returns a transport repeating transient failures while enabled is set.
+func newRetryTransport(next http.RoundTripper, enabled *atomic.Bool) *retryTransport {
+	return &retryTransport{next: next, enabled: enabled}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.enabled.Load() || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return t.next.RoundTrip(req)
+	}
+	interval := retryInitialInterval
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt == retryAttempts || !t.retriable(req, resp, err) {
+			return resp, err
+		}
+		metrics.ObserveRetry(req.Method)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body) //nolint:errcheck
+			resp.Body.Close()              //nolint:errcheck
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		if interval *= 2; interval > retryMaxInterval {
+			interval = retryMaxInterval
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retriable returns true if the request failed with a transient error. Connection
+// failures are repeated for idempotent requests only because a create could have
+// been processed by the server before the connection broke.
+func (t *retryTransport) retriable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return idempotent(req.Method) && transientNetworkError(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusGatewayTimeout:
+		return idempotent(req.Method)
+	case http.StatusInternalServerError:
+		return transientServerError(resp)
+	}
+	return false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func transientNetworkError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// transientServerError checks the message of an internal server error. The body
+// is restored so the response can be decoded if it is returned to the caller.
+func transientServerError(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint:errcheck
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	for _, msg := range transientServerErrors {
+		if strings.Contains(string(body), msg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTransport(t *testing.T) {
+	t.Parallel()
+
+	newServer := func(failures int32, status int, body string) (*httptest.Server, *atomic.Int32) {
+		calls := &atomic.Int32{}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) <= failures {
+				w.WriteHeader(status)
+				w.Write([]byte(body)) //nolint:errcheck
+				return
+			}
+			w.Write([]byte("ok")) //nolint:errcheck
+		}))
+		return srv, calls
+	}
+	newClient := func(enabled bool) *http.Client {
+		e := &atomic.Bool{}
+		e.Store(enabled)
+		return &http.Client{Transport: newRetryTransport(http.DefaultTransport, e)}
+	}
+
+	t.Run("retries etcd leader changes", func(t *testing.T) {
+		t.Parallel()
+		srv, calls := newServer(2, http.StatusInternalServerError, `{"message":"etcdserver: leader changed"}`)
+		defer srv.Close()
+		resp, err := newClient(true).Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+	t.Run("replays the body of throttled requests", func(t *testing.T) {
+		t.Parallel()
+		srv, calls := newServer(1, http.StatusTooManyRequests, "")
+		defer srv.Close()
+		resp, err := newClient(true).Post(srv.URL, "application/json", strings.NewReader("{}"))
+		require.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+	t.Run("returns other server errors", func(t *testing.T) {
+		t.Parallel()
+		srv, calls := newServer(1, http.StatusInternalServerError, "internal error")
+		defer srv.Close()
+		resp, err := newClient(true).Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		srv, calls := newServer(1, http.StatusServiceUnavailable, "")
+		defer srv.Close()
+		resp, err := newClient(false).Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client/database"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CloneOptions selects the database cluster cloned and the overrides of the clone.
+type CloneOptions struct {
+	Source string
+	Target string
+	// Replicas is the number of nodes of the clone. The size of the source is kept if it is zero.
+	Replicas int32
+	// Resources override the resources of every node of the clone. The disk cannot be smaller
+	// than the disk of the source since the backup has to fit.
+	Resources DatabaseClusterResources
+}
+
+// CloneDatabaseCluster creates a new database cluster with the spec of the source cluster and
+// restores the latest succeeded backup of the source into it. The admin credentials of the
+// source are copied since the restored data keeps its users. Backup schedules and point-in-time
+// recovery are not copied, so the clone does not write to the backup storages of the source.
+func (k *Kubernetes) CloneDatabaseCluster(ctx context.Context, opts CloneOptions) (*dbaasv1.DatabaseCluster, *database.DatabaseClusterRestore, error) {
+	if opts.Source == opts.Target {
+		return nil, nil, errors.Errorf("database cluster %s cannot be cloned into itself", opts.Source)
+	}
+	source, err := k.GetDatabaseCluster(ctx, opts.Source)
+	if err != nil {
+		return nil, nil, classifyError(err)
+	}
+	_, err = k.GetDatabaseCluster(ctx, opts.Target)
+	switch {
+	case err == nil:
+		return nil, nil, errors.Errorf("database cluster %s already exists", opts.Target)
+	case !apierrors.IsNotFound(err):
+		return nil, nil, classifyError(err)
+	}
+	backups, err := k.ListDatabaseClusterBackups(ctx, BackupFilter{Cluster: opts.Source, State: backupStateSucceeded})
+	if err != nil {
+		return nil, nil, err
+	}
+	backup, err := restoredBackup(backups.Items, RestoreOptions{Cluster: opts.Source})
+	if err != nil {
+		return nil, nil, err
+	}
+	clone, err := clonedDatabaseCluster(source, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := k.cloneDatabaseCredentials(ctx, source, clone); err != nil {
+		return nil, nil, classifyError(err)
+	}
+	if err := k.CreateDatabaseCluster(ctx, clone); err != nil {
+		return nil, nil, errors.Wrapf(err, "cannot create database cluster %s", opts.Target)
+	}
+	restore := buildRestore(clone, backup, time.Time{}, time.Now())
+	if err := k.CreateRestore(ctx, restore); err != nil {
+		return clone, nil, classifyError(errors.Wrapf(err, "cannot restore backup %s into database cluster %s", backup.Name, opts.Target))
+	}
+	return clone, restore, nil
+}
+
+// clonedDatabaseCluster returns a new database cluster named after the target with the spec of
+// the source and the overrides of the options.
+func clonedDatabaseCluster(source *dbaasv1.DatabaseCluster, opts CloneOptions) (*dbaasv1.DatabaseCluster, error) {
+	scaled, err := ScaledDatabaseCluster(source, opts.Replicas, opts.Resources)
+	if err != nil {
+		return nil, err
+	}
+	clone := &dbaasv1.DatabaseCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      opts.Target,
+			Namespace: source.Namespace,
+		},
+		Spec: scaled.Spec,
+	}
+	clone.Spec.SecretsName = ""
+	clone.Spec.Pause = false
+	if clone.Spec.Backup != nil {
+		backup := *clone.Spec.Backup
+		backup.Schedule = nil
+		clone.Spec.Backup = &backup
+	}
+	return clone, nil
+}
+
+// cloneDatabaseCredentials copies the secret with the admin credentials of the source cluster to
+// a secret of the clone and references it from the clone. The operator generates new credentials
+// if the source has no secret yet or the engine does not support referenced secrets.
+func (k *Kubernetes) cloneDatabaseCredentials(ctx context.Context, source, clone *dbaasv1.DatabaseCluster) error {
+	engine, ok := databaseEngineCredentials[source.Spec.Database]
+	if !ok || engine.generatedUser == "" {
+		return nil
+	}
+	name := source.Spec.SecretsName
+	if name == "" {
+		name = fmt.Sprintf(engine.secrets[0], source.Name)
+	}
+	secret, err := k.client.GetSecret(ctx, name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot get secret %s", name)
+	}
+	copied := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf(engine.secrets[0], clone.Name),
+			Namespace:   secret.Namespace,
+			Annotations: secret.Annotations,
+		},
+		Type: secret.Type,
+		Data: secret.Data,
+	}
+	if err := k.client.ApplyObject(ctx, copied); err != nil {
+		return errors.Wrapf(err, "cannot copy credentials of database cluster %s", source.Name)
+	}
+	clone.Spec.SecretsName = copied.Name
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client/database"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/fake"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCloneDatabaseCluster(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	now := time.Now()
+	source := &dbaasv1.DatabaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"}}
+	source.Spec.Database = "pxc"
+	source.Spec.ClusterSize = 3
+	source.Spec.SecretsName = "orders-secrets"
+	source.Spec.DBInstance.DiskSize = resource.MustParse("10G")
+	source.Spec.Backup = &dbaasv1.BackupSpec{
+		Enabled:  true,
+		Schedule: []dbaasv1.BackupSchedule{{Name: "daily", Schedule: "@daily"}},
+	}
+	backup := func(name, state string, age time.Duration) *database.DatabaseClusterBackup {
+		b := &database.DatabaseClusterBackup{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now.Add(-age)),
+		}}
+		b.Spec.DBClusterName = "orders"
+		b.Status.State = database.BackupState(state)
+		return b
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders-secrets", Namespace: "default"},
+		Data:       map[string][]byte{"root": []byte("secret")},
+	}
+	c, err := fake.NewKubeClient("default", source, secret,
+		backup("orders-1", "Succeeded", 2*time.Hour), backup("orders-2", "Succeeded", time.Hour), backup("orders-3", "Failed", time.Minute))
+	require.NoError(t, err)
+	k := NewWithClient(c)
+
+	memory := resource.MustParse("4G")
+	clone, restore, err := k.CloneDatabaseCluster(ctx, CloneOptions{
+		Source:    "orders",
+		Target:    "orders-copy",
+		Replicas:  1,
+		Resources: DatabaseClusterResources{Memory: &memory},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "orders-2", restore.Spec.BackupName)
+	assert.Equal(t, "orders-copy", restore.Spec.DatabaseCluster)
+
+	created, err := k.GetDatabaseCluster(ctx, "orders-copy")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), created.Spec.ClusterSize)
+	assert.Equal(t, "4G", created.Spec.DBInstance.Memory.String())
+	assert.True(t, created.Spec.Backup.Enabled)
+	assert.Empty(t, created.Spec.Backup.Schedule)
+	assert.Len(t, source.Spec.Backup.Schedule, 1)
+	assert.Equal(t, "orders-copy-secrets", clone.Spec.SecretsName)
+	copied, err := c.GetSecret(ctx, "orders-copy-secrets")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), copied.Data["root"])
+
+	_, _, err = k.CloneDatabaseCluster(ctx, CloneOptions{Source: "orders", Target: "orders-copy"})
+	assert.ErrorContains(t, err, "already exists")
+	disk := resource.MustParse("5G")
+	_, _, err = k.CloneDatabaseCluster(ctx, CloneOptions{Source: "orders", Target: "small", Resources: DatabaseClusterResources{Disk: &disk}})
+	assert.ErrorContains(t, err, "cannot be reduced")
+}
@@ -0,0 +1,124 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultClusterDomain is the DNS domain of clusters with the default kubelet configuration.
+	DefaultClusterDomain = "cluster.local"
+
+	resolvConfFile = "/etc/resolv.conf"
+)
+
+// SetClusterDomain sets the DNS domain of the cluster used in the names of services.
+// The domain is detected if it is not set.
+func (k *Kubernetes) SetClusterDomain(domain string) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.clusterDomain = strings.Trim(domain, ".")
+}
+
+// ClusterDomain returns the DNS domain of the cluster. Unless it is set, it is detected
+// from the kubelet configuration or, in the cluster, from the DNS search domains of the pod.
+// DefaultClusterDomain is returned if the detection fails.
+func (k *Kubernetes) ClusterDomain(ctx context.Context) string {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	return k.getClusterDomain(ctx)
+}
+
+// getClusterDomain returns the cluster domain detecting it on the first call.
+// The caller must hold the write lock.
+func (k *Kubernetes) getClusterDomain(ctx context.Context) string {
+	if k.clusterDomain != "" {
+		return k.clusterDomain
+	}
+	domain, err := k.kubeletClusterDomain(ctx)
+	if err != nil {
+		k.l.WithError(err).Debug("Cannot detect the cluster domain from the kubelet configuration")
+	}
+	if domain == "" && k.inCluster {
+		domain = resolvConfClusterDomain(resolvConfFile)
+	}
+	if domain == "" {
+		domain = DefaultClusterDomain
+	}
+	k.l.WithField("domain", domain).Debug("Using cluster domain")
+	k.clusterDomain = domain
+	return domain
+}
+
+// kubeletClusterDomain returns the cluster domain the kubelet of the first node is configured with.
+func (k *Kubernetes) kubeletClusterDomain(ctx context.Context) (string, error) {
+	nodes, err := k.client.GetNodes(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot list nodes")
+	}
+	if len(nodes.Items) == 0 {
+		return "", nil
+	}
+	name := nodes.Items[0].Name
+	data, err := k.client.GetNodeKubeletConfig(ctx, name)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot get kubelet configuration of node %s", name)
+	}
+	var config struct {
+		KubeletConfig struct {
+			ClusterDomain string `json:"clusterDomain"`
+		} `json:"kubeletconfig"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", errors.Wrapf(err, "cannot decode kubelet configuration of node %s", name)
+	}
+	return strings.Trim(config.KubeletConfig.ClusterDomain, "."), nil
+}
+
+// resolvConfClusterDomain returns the cluster domain from the search domains of the
+// resolver configuration of a pod, e.g. default.svc.cluster.local.
+func resolvConfClusterDomain(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "search" {
+			continue
+		}
+		for _, domain := range fields[1:] {
+			if strings.HasPrefix(domain, "svc.") {
+				return strings.Trim(strings.TrimPrefix(domain, "svc."), ".")
+			}
+		}
+	}
+	return ""
+}
+
+// ServiceHost returns the fully qualified DNS name of the service in the cluster domain.
+func ServiceHost(service, namespace, domain string) string {
+	return service + "." + namespace + ".svc." + domain
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterDomain(t *testing.T) {
+	ctx := context.Background()
+	nodes := &corev1.NodeList{Items: []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "worker"}}}}
+
+	t.Run("kubelet", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetNodes", ctx).Return(nodes, nil).Once()
+		k8sclient.On("GetNodeKubeletConfig", ctx, "worker").
+			Return([]byte(`{"kubeletconfig":{"clusterDomain":"example.internal"}}`), nil).Once()
+
+		assert.Equal(t, "example.internal", k.ClusterDomain(ctx))
+		// The detected domain is cached.
+		assert.Equal(t, "example.internal", k.ClusterDomain(ctx))
+		k8sclient.AssertExpectations(t)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetNodes", ctx).Return(nodes, nil)
+		k8sclient.On("GetNodeKubeletConfig", ctx, "worker").Return(nil, errors.New("forbidden"))
+
+		assert.Equal(t, DefaultClusterDomain, k.ClusterDomain(ctx))
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		k := NewEmpty()
+		k.SetClusterDomain("example.internal.")
+		assert.Equal(t, "example.internal", k.ClusterDomain(ctx))
+	})
+}
+
+func TestResolvConfClusterDomain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resolv.conf")
+	require.NoError(t, os.WriteFile(path, []byte("search default.svc.example.internal svc.example.internal example.internal\nnameserver 10.96.0.10\n"), 0o600))
+	assert.Equal(t, "example.internal", resolvConfClusterDomain(path))
+	assert.Equal(t, "", resolvConfClusterDomain(filepath.Join(t.TempDir(), "missing")))
+}
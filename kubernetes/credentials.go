@@ -0,0 +1,212 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"strconv"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	generatedPasswordLength = 24
+	generatedPasswordChars  = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// DatabaseCredentials holds the connection details of the admin user of a database cluster.
+type DatabaseCredentials struct {
+	Engine           string `json:"engine"`
+	Host             string `json:"host"`
+	Port             int    `json:"port"`
+	User             string `json:"user"`
+	Password         string `json:"password"`
+	ConnectionString string `json:"connectionString"`
+}
+
+// engineCredentials describes where the operator of an engine keeps the admin user.
+type engineCredentials struct {
+	// secrets are formats of the names of the secrets the operator creates
+	// if the cluster does not reference a secret, tried in order.
+	secrets []string
+	// user is the fixed user name, userKey the key of the user name in the secret.
+	user    string
+	userKey string
+	// passwordKey is the key of the password in the secret.
+	passwordKey string
+	port        int
+	scheme      string
+	// generatedUser is the admin user of the credentials generated by the provisioner
+	// if a secrets backend is set. Credentials are not generated if it is empty.
+	generatedUser string
+}
+
+var databaseEngineCredentials = map[dbaasv1.EngineType]engineCredentials{
+	"pxc": {
+		secrets:       []string{"%s-secrets", "internal-%s"},
+		user:          "root",
+		passwordKey:   "root",
+		port:          mysqlPort,
+		scheme:        "mysql",
+		generatedUser: "root",
+	},
+	"psmdb": {
+		secrets:       []string{"%s-secrets", "internal-%s-users"},
+		userKey:       "MONGODB_DATABASE_ADMIN_USER",
+		passwordKey:   "MONGODB_DATABASE_ADMIN_PASSWORD",
+		port:          mongoDBPort,
+		scheme:        "mongodb",
+		generatedUser: "databaseAdmin",
+	},
+	"postgresql": {
+		secrets:     []string{"%[1]s-pguser-%[1]s"},
+		userKey:     "user",
+		passwordKey: "password",
+		port:        postgresPort,
+		scheme:      "postgres",
+	},
+}
+
+// GetDatabaseCredentials returns the connection details of the admin user of the
+// database cluster. They are read from the secret referenced by the cluster or
+// the secret named after the conventions of the engine operator.
+func (k *Kubernetes) GetDatabaseCredentials(ctx context.Context, name string) (*DatabaseCredentials, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	cluster, err := k.client.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return k.databaseCredentials(ctx, cluster)
+}
+
+// databaseCredentials returns the connection details of the admin user of the database cluster.
+func (k *Kubernetes) databaseCredentials(ctx context.Context, cluster *dbaasv1.DatabaseCluster) (*DatabaseCredentials, error) {
+	name := cluster.Name
+	engine, ok := databaseEngineCredentials[cluster.Spec.Database]
+	if !ok {
+		return nil, errors.Errorf("credentials of %q database engine are not supported", cluster.Spec.Database)
+	}
+	if cluster.Status.Host == "" {
+		return nil, errors.Errorf("database cluster %s has no host yet, retry when it is ready", name)
+	}
+
+	secrets := make([]string, 0, len(engine.secrets)+1)
+	if cluster.Spec.SecretsName != "" {
+		secrets = append(secrets, cluster.Spec.SecretsName)
+	}
+	for _, format := range engine.secrets {
+		secrets = append(secrets, fmt.Sprintf(format, name))
+	}
+	for _, secretName := range secrets {
+		secret, err := k.client.GetSecret(ctx, secretName)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, classifyError(errors.Wrapf(err, "cannot get secret %s", secretName))
+		}
+		user := engine.user
+		if engine.userKey != "" {
+			user = string(secret.Data[engine.userKey])
+		}
+		password, ok := secret.Data[engine.passwordKey]
+		if !ok || user == "" {
+			return nil, errors.Errorf("secret %s has no credentials of the admin user", secretName)
+		}
+		creds := &DatabaseCredentials{
+			Engine:   string(cluster.Spec.Database),
+			Host:     cluster.Status.Host,
+			Port:     engine.port,
+			User:     user,
+			Password: string(password),
+		}
+		creds.ConnectionString = (&url.URL{
+			Scheme: engine.scheme,
+			User:   url.UserPassword(creds.User, creds.Password),
+			Host:   net.JoinHostPort(creds.Host, strconv.Itoa(creds.Port)),
+		}).String()
+		return creds, nil
+	}
+	return nil, errors.Errorf("no secret with credentials of database cluster %s found, tried %v", name, secrets)
+}
+
+// createDatabaseCredentials generates the credentials of the admin user of the database
+// cluster, stores them in the secrets backend and references the secret created for them
+// from the cluster. Operators read the credentials from the Kubernetes API, so the secret
+// keeps the data and carries the annotations providing the credentials to other pods.
+// It does nothing if no secrets backend is set, the cluster references a secret or the
+// engine does not support generated credentials.
+func (k *Kubernetes) createDatabaseCredentials(ctx context.Context, cluster *dbaasv1.DatabaseCluster) error {
+	engine, ok := databaseEngineCredentials[cluster.Spec.Database]
+	if k.secrets == nil || cluster.Spec.SecretsName != "" || !ok || engine.generatedUser == "" {
+		return nil
+	}
+	password, err := generatePassword()
+	if err != nil {
+		return err
+	}
+	namespace := cluster.Namespace
+	if namespace == "" {
+		namespace = k.client.Namespace()
+	}
+	secret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf(engine.secrets[0], cluster.Name), Namespace: namespace},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{engine.passwordKey: []byte(password)},
+	}
+	if engine.userKey != "" {
+		secret.Data[engine.userKey] = []byte(engine.generatedUser)
+	}
+	if _, err := k.secrets.Store(ctx, secret); err != nil {
+		return errors.Wrapf(err, "cannot store credentials of database cluster %s", cluster.Name)
+	}
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	secret.Annotations = k.secrets.PodAnnotations(namespace, secret.Name, keys)
+	if err := k.client.ApplyObject(ctx, secret); err != nil {
+		return errors.Wrapf(err, "cannot apply credentials of database cluster %s", cluster.Name)
+	}
+	cluster.Spec.SecretsName = secret.Name
+	return nil
+}
+
+// generatePassword returns a random alphanumeric password.
+func generatePassword() (string, error) {
+	password := make([]byte, generatedPasswordLength)
+	max := big.NewInt(int64(len(generatedPasswordChars)))
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		password[i] = generatedPasswordChars[n.Int64()]
+	}
+	return string(password), nil
+}
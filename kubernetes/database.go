@@ -0,0 +1,102 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// DatabaseClusterStateReady is the state reported by a database cluster ready to accept connections.
+const DatabaseClusterStateReady dbaasv1.AppState = "ready"
+
+// DatabaseClusterCondition is called on every observed change of a database cluster
+// and reports whether the cluster reached the desired state.
+type DatabaseClusterCondition func(cluster *dbaasv1.DatabaseCluster) (bool, error)
+
+// DatabaseClusterReady is satisfied when the database cluster is ready.
+func DatabaseClusterReady(cluster *dbaasv1.DatabaseCluster) (bool, error) {
+	return cluster.Status.State == DatabaseClusterStateReady, nil
+}
+
+// WaitForDatabaseCluster watches the database cluster until the condition is satisfied
+// or the context is done. It returns the last observed state of the cluster.
+func (k *Kubernetes) WaitForDatabaseCluster(ctx context.Context, name string, condition DatabaseClusterCondition) (*dbaasv1.DatabaseCluster, error) {
+	k.lock.RLock()
+	c := k.client
+	k.lock.RUnlock()
+
+	for {
+		cluster, err := c.GetDatabaseCluster(ctx, name)
+		if err != nil {
+			return nil, classifyError(errors.Wrapf(err, "cannot get database cluster %s", name))
+		}
+		done, err := condition(cluster)
+		if err != nil || done {
+			return cluster, err
+		}
+		w, err := c.WatchDatabaseCluster(ctx, name, cluster.ResourceVersion)
+		if err != nil {
+			return cluster, errors.Wrapf(err, "cannot watch database cluster %s", name)
+		}
+		cluster, done, err = waitForDatabaseClusterEvents(ctx, w, cluster, condition)
+		w.Stop()
+		if err != nil || done {
+			return cluster, classifyError(err)
+		}
+		// The watch has been closed by the server. Start over.
+	}
+}
+
+func waitForDatabaseClusterEvents(ctx context.Context, w watch.Interface, last *dbaasv1.DatabaseCluster, condition DatabaseClusterCondition) (*dbaasv1.DatabaseCluster, bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return last, false, ctx.Err()
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return last, false, nil
+			}
+			switch event.Type {
+			case watch.Deleted:
+				return last, false, &typedError{
+					kind: ErrDatabaseClusterDeleted,
+					err:  errors.Errorf("database cluster %s has been deleted", last.Name),
+				}
+			case watch.Error:
+				err := apierrors.FromObject(event.Object)
+				if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+					return last, false, nil
+				}
+				return last, false, err
+			case watch.Added, watch.Modified:
+				cluster, ok := event.Object.(*dbaasv1.DatabaseCluster)
+				if !ok {
+					continue
+				}
+				last = cluster
+				done, err := condition(cluster)
+				if err != nil || done {
+					return cluster, done, err
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func TestPatchDatabaseClusterFields(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+
+	k := NewEmpty()
+	k.client = k8sclient
+
+	t.Run("Merge patch", func(t *testing.T) {
+		patch := []byte(`{"spec":{"clusterSize":5}}`)
+		k8sclient.On("PatchDatabaseCluster", ctx, "test", types.MergePatchType, patch).
+			Return(&dbaasv1.DatabaseCluster{Spec: dbaasv1.DatabaseSpec{ClusterSize: 5}}, nil)
+		cluster, err := k.PatchDatabaseClusterFields(ctx, "test", patch, types.MergePatchType)
+		require.NoError(t, err)
+		assert.Equal(t, int32(5), cluster.Spec.ClusterSize)
+	})
+
+	t.Run("Unsupported patch type", func(t *testing.T) {
+		_, err := k.PatchDatabaseClusterFields(ctx, "test", []byte(`{}`), types.StrategicMergePatchType)
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid patch", func(t *testing.T) {
+		_, err := k.PatchDatabaseClusterFields(ctx, "test", []byte(`{`), types.MergePatchType)
+		assert.Error(t, err)
+	})
+
+	k8sclient.AssertNumberOfCalls(t, "PatchDatabaseCluster", 1)
+}
+
+func TestReleasePatch(t *testing.T) {
+	cluster := &dbaasv1.DatabaseCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{managedByLabelKey: "everest", engineLabelKey: "pxc"},
+			Annotations: map[string]string{managedByKey: "pmm", restartAnnotationKey: "true"},
+		},
+	}
+	patch, ok := releasePatch(cluster)
+	require.True(t, ok)
+	assert.JSONEq(t, `{"metadata":{
+		"labels":{"app.kubernetes.io/managed-by":null},
+		"annotations":{"dbaas.percona.com/managed-by":null}
+	}}`, string(patch))
+
+	_, ok = releasePatch(&dbaasv1.DatabaseCluster{})
+	assert.False(t, ok)
+}
+
+func TestWaitForDatabaseCluster(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+
+	k := NewEmpty()
+	k.client = k8sclient
+
+	cluster := func(state dbaasv1.AppState) *dbaasv1.DatabaseCluster {
+		return &dbaasv1.DatabaseCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", ResourceVersion: "1"},
+			Status:     dbaasv1.DatabaseClusterStatus{State: state},
+		}
+	}
+	w := watch.NewFakeWithChanSize(2, false)
+	w.Modify(cluster("initializing"))
+	w.Modify(cluster(DatabaseClusterStateReady))
+
+	k8sclient.On("GetDatabaseCluster", ctx, "test").Return(cluster("initializing"), nil)
+	k8sclient.On("WatchDatabaseCluster", ctx, "test", "1").Return(w, nil)
+
+	var states []dbaasv1.AppState
+	result, err := k.WaitForDatabaseCluster(ctx, "test", func(c *dbaasv1.DatabaseCluster) (bool, error) {
+		states = append(states, c.Status.State)
+		return DatabaseClusterReady(c)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, DatabaseClusterStateReady, result.Status.State)
+	assert.Equal(t, []dbaasv1.AppState{"initializing", "initializing", DatabaseClusterStateReady}, states)
+}
@@ -0,0 +1,269 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// instanceLabelKey is set by the operators on resources belonging to a database cluster.
+const instanceLabelKey = "app.kubernetes.io/instance"
+
+type object interface {
+	runtime.Object
+	metav1.Object
+}
+
+// DeletionItem is a resource a destructive operation is going to delete.
+type DeletionItem struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Size is the size of the data stored in the resource, if known.
+	Size string
+
+	obj runtime.Object
+}
+
+// String returns a human readable representation of the item.
+func (i DeletionItem) String() string {
+	name := i.Name
+	if i.Namespace != "" {
+		name = i.Namespace + "/" + i.Name
+	}
+	if i.Size != "" {
+		return fmt.Sprintf("%s %s (%s)", i.Kind, name, i.Size)
+	}
+	return fmt.Sprintf("%s %s", i.Kind, name)
+}
+
+// DeletionPlan lists resources a destructive operation is going to delete.
+type DeletionPlan struct {
+	Items []DeletionItem
+	// Blockers explain why the operation is unsafe. Such operations should be forced explicitly.
+	Blockers []string
+}
+
+func (p *DeletionPlan) add(gvk schema.GroupVersionKind, obj object, size string) {
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	p.Items = append(p.Items, DeletionItem{
+		Kind:      gvk.Kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Size:      size,
+		obj:       obj,
+	})
+}
+
+// PlanDatabaseClusterDeletion lists the database cluster with its volumes and secrets.
+func (k *Kubernetes) PlanDatabaseClusterDeletion(ctx context.Context, name string) (*DeletionPlan, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	cluster, err := k.client.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get database cluster %s", name)
+	}
+	plan := &DeletionPlan{}
+	plan.add(dbaasv1.GroupVersion.WithKind(databaseClusterKind), cluster, "")
+
+	pvcs, err := k.client.GetPersistentVolumeClaims(ctx, cluster.Namespace, &metav1.LabelSelector{
+		MatchLabels: map[string]string{instanceLabelKey: name},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot list volumes of database cluster %s", name)
+	}
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		size, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+		if !ok {
+			size = pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		}
+		plan.add(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"), pvc, size.String())
+	}
+
+	if cluster.Spec.SecretsName != "" {
+		secret, err := k.client.GetSecret(ctx, cluster.Spec.SecretsName)
+		switch {
+		case err == nil:
+			plan.add(corev1.SchemeGroupVersion.WithKind("Secret"), secret, "")
+		case !apierrors.IsNotFound(err):
+			return nil, errors.Wrapf(err, "cannot get secret %s", cluster.Spec.SecretsName)
+		}
+	}
+	return plan, nil
+}
+
+// PlanUninstall lists database clusters, subscriptions and CSVs of the operators,
+// the operator group and CRDs of the operators. Existing database clusters block
+// the uninstallation since their data is lost together with the CRDs.
+func (k *Kubernetes) PlanUninstall(ctx context.Context, namespace, operatorGroup string, operators []string) (*DeletionPlan, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	plan := &DeletionPlan{}
+	clusters, err := k.client.ListDatabaseClusters(ctx)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "cannot list database clusters")
+	}
+	if clusters != nil && len(clusters.Items) != 0 {
+		for i := range clusters.Items {
+			plan.add(dbaasv1.GroupVersion.WithKind(databaseClusterKind), &clusters.Items[i], "")
+		}
+		plan.Blockers = append(plan.Blockers,
+			fmt.Sprintf("%d database clusters exist and will be deleted with all their data", len(clusters.Items)))
+	}
+
+	for _, name := range operators {
+		subs, err := k.client.GetSubscription(ctx, namespace, name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "cannot get subscription for %q operator", name)
+		}
+		plan.add(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.SubscriptionKind), subs, "")
+		if subs.Status.InstalledCSV == "" {
+			continue
+		}
+		csv, err := k.client.GetClusterServiceVersion(ctx, types.NamespacedName{Namespace: namespace, Name: subs.Status.InstalledCSV})
+		switch {
+		case err == nil:
+			plan.add(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.ClusterServiceVersionKind), csv, "")
+		case !apierrors.IsNotFound(err):
+			return nil, errors.Wrapf(err, "cannot get cluster service version %q", subs.Status.InstalledCSV)
+		}
+	}
+
+	og, err := k.client.GetOperatorGroup(ctx, namespace, operatorGroup)
+	switch {
+	case err == nil:
+		plan.add(schema.FromAPIVersionAndKind(APIVersionCoreosV1, LeftoverKindOperatorGroup), og, "")
+	case !apierrors.IsNotFound(err):
+		return nil, errors.Wrapf(err, "cannot get operator group %q", operatorGroup)
+	}
+
+	crds, err := k.client.ListCRDs(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list CRDs")
+	}
+	for i := range crds.Items {
+		if _, ok := operatorCRDGroups[crds.Items[i].Spec.Group]; !ok {
+			continue
+		}
+		plan.add(apiextv1.SchemeGroupVersion.WithKind(crdKind), &crds.Items[i], "")
+	}
+	return plan, nil
+}
+
+// ExecuteDeletionPlan deletes resources of the plan in order.
+// Resources which are already gone are skipped.
+func (k *Kubernetes) ExecuteDeletionPlan(ctx context.Context, plan *DeletionPlan) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	for _, item := range plan.Items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		k.l.Infof("deleting %s", item)
+		if err := k.client.DeleteObject(ctx, item.obj); err != nil && !apierrors.IsNotFound(err) {
+			return classifyError(errors.Wrapf(err, "cannot delete %s %s", item.Kind, item.Name))
+		}
+	}
+	return nil
+}
+
+// PlanOperatorUninstall lists the subscription and the CSV of the operator. If deleteCRDs is set,
+// the CRDs of the operator are listed too together with their custom resources; existing
+// custom resources block the uninstallation then since their data is lost.
+// Without deleteCRDs running databases are kept, they are not managed until the operator
+// is installed again.
+func (k *Kubernetes) PlanOperatorUninstall(ctx context.Context, namespace, name string, deleteCRDs bool) (*DeletionPlan, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	plan := &DeletionPlan{}
+	var crds []apiextv1.CustomResourceDefinition
+	if deleteCRDs {
+		list, err := k.client.ListCRDs(ctx, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot list CRDs")
+		}
+		for i := range list.Items {
+			if operatorCRDGroups[list.Items[i].Spec.Group] == name {
+				crds = append(crds, list.Items[i])
+			}
+		}
+	}
+	// Custom resources are deleted first so the operator can still process their finalizers.
+	for i := range crds {
+		crs, err := k.listCustomResources(ctx, &crds[i])
+		if err != nil {
+			return nil, err
+		}
+		for j := range crs {
+			plan.add(crs[j].GroupVersionKind(), &crs[j], "")
+		}
+		if len(crs) != 0 {
+			plan.Blockers = append(plan.Blockers,
+				fmt.Sprintf("%d %s exist and will be deleted with all their data", len(crs), crds[i].Spec.Names.Plural))
+		}
+	}
+
+	subs, err := k.client.GetSubscription(ctx, namespace, name)
+	switch {
+	case err == nil:
+		plan.add(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.SubscriptionKind), subs, "")
+		if subs.Status.InstalledCSV != "" {
+			csv, err := k.client.GetClusterServiceVersion(ctx, types.NamespacedName{Namespace: namespace, Name: subs.Status.InstalledCSV})
+			switch {
+			case err == nil:
+				plan.add(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.ClusterServiceVersionKind), csv, "")
+			case !apierrors.IsNotFound(err):
+				return nil, errors.Wrapf(err, "cannot get cluster service version %q", subs.Status.InstalledCSV)
+			}
+		}
+	case !apierrors.IsNotFound(err):
+		return nil, errors.Wrapf(err, "cannot get subscription for %q operator", name)
+	}
+
+	for i := range crds {
+		plan.add(apiextv1.SchemeGroupVersion.WithKind(crdKind), &crds[i], "")
+	}
+	return plan, nil
+}
+
+// UninstallOperator removes the subscription and the CSV of the operator and,
+// if deleteCRDs is set, its CRDs together with all custom resources.
+func (k *Kubernetes) UninstallOperator(ctx context.Context, namespace, name string, deleteCRDs bool) error {
+	plan, err := k.PlanOperatorUninstall(ctx, namespace, name, deleteCRDs)
+	if err != nil {
+		return classifyError(err)
+	}
+	return k.ExecuteDeletionPlan(ctx, plan)
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestPlanOperatorUninstall(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+
+	k := NewEmpty()
+	k.client = k8sclient
+
+	k8sclient.On("GetSubscription", ctx, "default", "percona-xtradb-cluster-operator").Return(&v1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "percona-xtradb-cluster-operator", Namespace: "default"},
+		Status:     v1alpha1.SubscriptionStatus{InstalledCSV: "percona-xtradb-cluster-operator.v1.12.0"},
+	}, nil)
+	k8sclient.On("GetClusterServiceVersion", ctx, types.NamespacedName{Namespace: "default", Name: "percona-xtradb-cluster-operator.v1.12.0"}).
+		Return(&v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "percona-xtradb-cluster-operator.v1.12.0", Namespace: "default"},
+		}, nil)
+	k8sclient.On("ListCRDs", ctx, (*metav1.LabelSelector)(nil)).Return(&apiextv1.CustomResourceDefinitionList{
+		Items: []apiextv1.CustomResourceDefinition{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "perconaxtradbclusters.pxc.percona.com"},
+				Spec: apiextv1.CustomResourceDefinitionSpec{
+					Group:    "pxc.percona.com",
+					Names:    apiextv1.CustomResourceDefinitionNames{Plural: "perconaxtradbclusters"},
+					Versions: []apiextv1.CustomResourceDefinitionVersion{{Name: "v1", Storage: true}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "databaseclusters.dbaas.percona.com"},
+				Spec:       apiextv1.CustomResourceDefinitionSpec{Group: "dbaas.percona.com"},
+			},
+		},
+	}, nil)
+	cr := unstructured.Unstructured{}
+	cr.SetAPIVersion("pxc.percona.com/v1")
+	cr.SetKind("PerconaXtraDBCluster")
+	cr.SetName("mysql")
+	k8sclient.On("ListCRs", ctx, "", mock.Anything, (*metav1.LabelSelector)(nil)).Return(&unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{cr},
+	}, nil)
+
+	t.Run("keep data", func(t *testing.T) {
+		plan, err := k.PlanOperatorUninstall(ctx, "default", "percona-xtradb-cluster-operator", false)
+		require.NoError(t, err)
+		assert.Empty(t, plan.Blockers)
+		kinds := make([]string, 0, len(plan.Items))
+		for _, item := range plan.Items {
+			kinds = append(kinds, item.Kind)
+		}
+		assert.Equal(t, []string{v1alpha1.SubscriptionKind, v1alpha1.ClusterServiceVersionKind}, kinds)
+	})
+
+	t.Run("delete CRDs", func(t *testing.T) {
+		plan, err := k.PlanOperatorUninstall(ctx, "default", "percona-xtradb-cluster-operator", true)
+		require.NoError(t, err)
+		require.Len(t, plan.Blockers, 1)
+		kinds := make([]string, 0, len(plan.Items))
+		for _, item := range plan.Items {
+			kinds = append(kinds, item.Kind)
+		}
+		assert.Equal(t, []string{"PerconaXtraDBCluster", v1alpha1.SubscriptionKind, v1alpha1.ClusterServiceVersionKind, crdKind}, kinds)
+		assert.Equal(t, "perconaxtradbclusters.pxc.percona.com", plan.Items[3].Name)
+	})
+}
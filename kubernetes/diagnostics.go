@@ -0,0 +1,220 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// operatorDeployments lists the deployments running the operators managed by the provisioner.
+var operatorDeployments = []string{pxcDeploymentName, psmdbDeploymentName, dbaasDeploymentName}
+
+// Diagnostics is a report on the health of the operators meant to be attached to support tickets.
+type Diagnostics struct {
+	CollectedAt            time.Time                 `json:"collected_at"`
+	ServerVersion          string                    `json:"server_version,omitempty"`
+	Deployments            []DeploymentDiagnostics   `json:"deployments"`
+	Pods                   []PodDiagnostics          `json:"pods"`
+	ClusterServiceVersions []CSVDiagnostics          `json:"cluster_service_versions"`
+	Subscriptions          []SubscriptionDiagnostics `json:"subscriptions"`
+	// Errors lists the parts of the report which could not be collected.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// DeploymentDiagnostics holds the rollout status of an operator deployment.
+type DeploymentDiagnostics struct {
+	Name              string                       `json:"name"`
+	Found             bool                         `json:"found"`
+	Replicas          int32                        `json:"replicas"`
+	ReadyReplicas     int32                        `json:"ready_replicas"`
+	UpdatedReplicas   int32                        `json:"updated_replicas"`
+	AvailableReplicas int32                        `json:"available_replicas"`
+	Conditions        []appsv1.DeploymentCondition `json:"conditions,omitempty"`
+}
+
+// PodDiagnostics holds the status, recent logs and events of an operator pod.
+type PodDiagnostics struct {
+	Name       string `json:"name"`
+	Deployment string `json:"deployment"`
+	Phase      string `json:"phase"`
+	Ready      bool   `json:"ready"`
+	Restarts   int32  `json:"restarts"`
+	// Logs maps container names to their recent log lines.
+	Logs   map[string][]string `json:"logs,omitempty"`
+	Events []string            `json:"events,omitempty"`
+}
+
+// CSVDiagnostics holds the phase of a cluster service version.
+type CSVDiagnostics struct {
+	Name    string `json:"name"`
+	Phase   string `json:"phase"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// SubscriptionDiagnostics holds the state and conditions of an operator subscription.
+type SubscriptionDiagnostics struct {
+	Name         string                           `json:"name"`
+	State        string                           `json:"state,omitempty"`
+	CurrentCSV   string                           `json:"current_csv,omitempty"`
+	InstalledCSV string                           `json:"installed_csv,omitempty"`
+	Conditions   []v1alpha1.SubscriptionCondition `json:"conditions,omitempty"`
+}
+
+// CollectDiagnostics gathers the status of the operator deployments, the logs and events of
+// their pods, the phases of the cluster service versions and the conditions of the subscriptions
+// in the namespace. Collection is best effort: parts which cannot be collected are recorded
+// in the Errors of the report so the rest of it is still useful.
+func (k *Kubernetes) CollectDiagnostics(ctx context.Context, namespace string) *Diagnostics {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	d := &Diagnostics{CollectedAt: time.Now().UTC()}
+	if info, err := k.client.GetServerVersion(ctx); err != nil {
+		d.addError(err, "cannot get server version")
+	} else {
+		d.ServerVersion = info.GitVersion
+	}
+	for _, name := range operatorDeployments {
+		k.collectDeployment(ctx, d, namespace, name)
+	}
+	k.collectCSVs(ctx, d, namespace)
+	k.collectSubscriptions(ctx, d, namespace)
+	return d
+}
+
+func (d *Diagnostics) addError(err error, format string, args ...interface{}) {
+	d.Errors = append(d.Errors, errors.Wrapf(err, format, args...).Error())
+}
+
+// collectDeployment adds the status of the deployment and of its pods to the report.
+func (k *Kubernetes) collectDeployment(ctx context.Context, d *Diagnostics, namespace, name string) {
+	deployment, err := k.client.GetDeployment(ctx, name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			d.addError(err, "cannot get %s deployment", name)
+		}
+		d.Deployments = append(d.Deployments, DeploymentDiagnostics{Name: name})
+		return
+	}
+	d.Deployments = append(d.Deployments, DeploymentDiagnostics{
+		Name:              name,
+		Found:             true,
+		Replicas:          deployment.Status.Replicas,
+		ReadyReplicas:     deployment.Status.ReadyReplicas,
+		UpdatedReplicas:   deployment.Status.UpdatedReplicas,
+		AvailableReplicas: deployment.Status.AvailableReplicas,
+		Conditions:        deployment.Status.Conditions,
+	})
+	pods, err := k.client.GetPods(ctx, namespace, deployment.Spec.Selector)
+	if err != nil {
+		d.addError(err, "cannot list pods of %s deployment", name)
+		return
+	}
+	for _, pod := range pods.Items {
+		diag := PodDiagnostics{
+			Name:       pod.Name,
+			Deployment: name,
+			Phase:      string(pod.Status.Phase),
+			Ready:      podReady(pod),
+			Logs:       make(map[string][]string),
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			diag.Restarts += status.RestartCount
+		}
+		for _, container := range pod.Spec.Containers {
+			logs, err := k.GetLogs(ctx, pod.Status.ContainerStatuses, pod.Name, container.Name)
+			if err != nil {
+				d.addError(err, "cannot get logs of %s container of %s pod", container.Name, pod.Name)
+				continue
+			}
+			diag.Logs[container.Name] = logs
+		}
+		events, err := k.GetEvents(ctx, pod.Name)
+		if err != nil {
+			d.addError(err, "cannot get events of %s pod", pod.Name)
+		} else {
+			diag.Events = events
+		}
+		d.Pods = append(d.Pods, diag)
+	}
+}
+
+// collectCSVs adds the phases of the cluster service versions to the report.
+func (k *Kubernetes) collectCSVs(ctx context.Context, d *Diagnostics, namespace string) {
+	csvs, err := k.client.ListClusterServiceVersion(ctx, namespace)
+	if err != nil {
+		d.addError(err, "cannot list cluster service versions")
+		return
+	}
+	for _, csv := range csvs.Items {
+		d.ClusterServiceVersions = append(d.ClusterServiceVersions, CSVDiagnostics{
+			Name:    csv.Name,
+			Phase:   string(csv.Status.Phase),
+			Reason:  string(csv.Status.Reason),
+			Message: csv.Status.Message,
+		})
+	}
+	sort.Slice(d.ClusterServiceVersions, func(i, j int) bool {
+		return d.ClusterServiceVersions[i].Name < d.ClusterServiceVersions[j].Name
+	})
+}
+
+// collectSubscriptions adds the state and conditions of the subscriptions to the report.
+func (k *Kubernetes) collectSubscriptions(ctx context.Context, d *Diagnostics, namespace string) {
+	subs, err := k.client.ListSubscriptions(ctx, namespace)
+	if err != nil {
+		d.addError(err, "cannot list subscriptions")
+		return
+	}
+	for _, sub := range subs.Items {
+		d.Subscriptions = append(d.Subscriptions, SubscriptionDiagnostics{
+			Name:         sub.Name,
+			State:        string(sub.Status.State),
+			CurrentCSV:   sub.Status.CurrentCSV,
+			InstalledCSV: sub.Status.InstalledCSV,
+			Conditions:   sub.Status.Conditions,
+		})
+	}
+	sort.Slice(d.Subscriptions, func(i, j int) bool {
+		return d.Subscriptions[i].Name < d.Subscriptions[j].Name
+	})
+}
+
+// Healthy returns true if all operator deployments are available and no part of the report is missing.
+func (d *Diagnostics) Healthy() bool {
+	if len(d.Errors) != 0 {
+		return false
+	}
+	for _, dep := range d.Deployments {
+		if dep.Found && dep.AvailableReplicas < dep.Replicas {
+			return false
+		}
+	}
+	for _, csv := range d.ClusterServiceVersions {
+		if csv.Phase != string(v1alpha1.CSVPhaseSucceeded) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+func TestCollectDiagnostics(t *testing.T) {
+	t.Parallel()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "pxc"}}
+	k8sclient.On("GetServerVersion", mock.Anything).Return(&version.Info{GitVersion: "v1.27.4"}, nil)
+	k8sclient.On("GetDeployment", mock.Anything, pxcDeploymentName).Return(&appsv1.Deployment{
+		Spec:   appsv1.DeploymentSpec{Selector: selector},
+		Status: appsv1.DeploymentStatus{Replicas: 1, ReadyReplicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+	}, nil)
+	k8sclient.On("GetDeployment", mock.Anything, psmdbDeploymentName).
+		Return(nil, apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, psmdbDeploymentName))
+	k8sclient.On("GetDeployment", mock.Anything, dbaasDeploymentName).Return(nil, errors.New("forbidden"))
+	k8sclient.On("GetPods", mock.Anything, "everest", selector).Return(&corev1.PodList{Items: []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "pxc-0"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: pxcOperatorContainerName}}},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			ContainerStatuses: []corev1.ContainerStatus{{RestartCount: 2, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+		},
+	}}}, nil)
+	k8sclient.On("GetLogs", mock.Anything, "pxc-0", pxcOperatorContainerName).Return("started\nreconciling", nil)
+	k8sclient.On("GetEvents", mock.Anything, "pxc-0").Return("Pulled", nil)
+	k8sclient.On("ListClusterServiceVersion", mock.Anything, "everest").Return(&v1alpha1.ClusterServiceVersionList{
+		Items: []v1alpha1.ClusterServiceVersion{{
+			ObjectMeta: metav1.ObjectMeta{Name: "percona-xtradb-cluster-operator.v1.12.0"},
+			Status:     v1alpha1.ClusterServiceVersionStatus{Phase: v1alpha1.CSVPhaseSucceeded},
+		}},
+	}, nil)
+	k8sclient.On("ListSubscriptions", mock.Anything, "everest").Return(&v1alpha1.SubscriptionList{
+		Items: []v1alpha1.Subscription{{
+			ObjectMeta: metav1.ObjectMeta{Name: "percona-xtradb-cluster-operator"},
+			Status:     v1alpha1.SubscriptionStatus{State: v1alpha1.SubscriptionStateAtLatest},
+		}},
+	}, nil)
+
+	d := k.CollectDiagnostics(context.Background(), "everest")
+	assert.Equal(t, "v1.27.4", d.ServerVersion)
+	assert.Len(t, d.Deployments, 3)
+	assert.True(t, d.Deployments[0].Found)
+	assert.False(t, d.Deployments[1].Found)
+	if assert.Len(t, d.Pods, 1) {
+		assert.True(t, d.Pods[0].Ready)
+		assert.Equal(t, int32(2), d.Pods[0].Restarts)
+		assert.Equal(t, []string{"started", "reconciling"}, d.Pods[0].Logs[pxcOperatorContainerName])
+		assert.Equal(t, []string{"Pulled"}, d.Pods[0].Events)
+	}
+	assert.Len(t, d.ClusterServiceVersions, 1)
+	assert.Len(t, d.Subscriptions, 1)
+	// Only the failed deployment is reported, a missing one is not an error.
+	assert.Len(t, d.Errors, 1)
+	assert.False(t, d.Healthy())
+}
@@ -0,0 +1,160 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// WarningCapacityLow is reported when little of a resource of the worker nodes is available.
+	WarningCapacityLow WarningCode = "capacity-low"
+
+	// capacityLowPercent is the share of allocatable resources below which capacity is reported low.
+	capacityLowPercent = 10
+)
+
+// DigestOptions selects what the maintenance digest covers.
+type DigestOptions struct {
+	// Namespace and Operators select the subscriptions checked for pending upgrades.
+	Namespace string
+	Operators []string
+	// Since is the beginning of the period failed backups are reported for.
+	Since time.Time
+}
+
+// Digest summarizes the state of the installation which needs attention of its operators.
+type Digest struct {
+	GeneratedAt      time.Time         `json:"generatedAt"`
+	Since            time.Time         `json:"since"`
+	OperatorUpgrades []OperatorUpgrade `json:"operatorUpgrades"`
+	FailedBackups    []DigestBackup    `json:"failedBackups"`
+	ClustersInError  []DigestCluster   `json:"clustersInError"`
+	CapacityWarnings Warnings          `json:"capacityWarnings"`
+}
+
+// DigestBackup describes a failed backup of a database cluster.
+type DigestBackup struct {
+	Name    string    `json:"name"`
+	Cluster string    `json:"cluster"`
+	Created time.Time `json:"created"`
+}
+
+// DigestCluster describes a database cluster in the error state.
+type DigestCluster struct {
+	Name    string `json:"name"`
+	Message string `json:"message,omitempty"`
+}
+
+// Empty returns true if nothing needs attention.
+func (d *Digest) Empty() bool {
+	return len(d.OperatorUpgrades) == 0 && len(d.FailedBackups) == 0 &&
+		len(d.ClustersInError) == 0 && len(d.CapacityWarnings) == 0
+}
+
+// MaintenanceDigest collects pending operator upgrades, backups failed since the given time,
+// database clusters in the error state and warnings about the capacity of the worker nodes.
+func (k *Kubernetes) MaintenanceDigest(ctx context.Context, opts DigestOptions) (*Digest, error) {
+	digest := &Digest{GeneratedAt: time.Now(), Since: opts.Since}
+	var err error
+	digest.OperatorUpgrades, err = k.ListOperatorUpgrades(ctx, opts.Namespace, opts.Operators)
+	if err != nil {
+		return nil, err
+	}
+
+	backups, err := k.ListDatabaseClusterBackups(ctx, BackupFilter{State: backupStateFailed})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		for _, backup := range backups.Items {
+			if backup.CreationTimestamp.Time.Before(opts.Since) {
+				continue
+			}
+			digest.FailedBackups = append(digest.FailedBackups, DigestBackup{
+				Name:    backup.Name,
+				Cluster: backup.Spec.DBClusterName,
+				Created: backup.CreationTimestamp.Time,
+			})
+		}
+	}
+
+	clusters, err := k.ListDatabaseClusters(ctx)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		for _, cluster := range clusters.Items {
+			if cluster.Status.State == databaseClusterStateError {
+				digest.ClustersInError = append(digest.ClustersInError, DigestCluster{
+					Name:    cluster.Name,
+					Message: cluster.Status.Message,
+				})
+			}
+		}
+	}
+
+	digest.CapacityWarnings, err = k.capacityWarnings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+// capacityWarnings reports node pressure and resources of the worker nodes running low.
+func (k *Kubernetes) capacityWarnings(ctx context.Context) (Warnings, error) {
+	clusterWarnings, err := k.ClusterWarnings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var warnings Warnings
+	for _, w := range clusterWarnings {
+		if w.Code == WarningNodeDiskPressure || w.Code == WarningNodeMemoryPressure {
+			warnings = append(warnings, w)
+		}
+	}
+	res, err := k.GetClusterResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	warnings.Merge(lowCapacity(*res))
+	return warnings, nil
+}
+
+// lowCapacity reports the resources of which less than capacityLowPercent of the allocatable amount is available.
+func lowCapacity(res ClusterResources) Warnings {
+	var warnings Warnings
+	available := res.Available()
+	for _, r := range []struct {
+		name                   string
+		allocatable, available uint64
+	}{
+		{"CPU", res.Allocatable.CPUMillis, available.CPUMillis},
+		{"memory", res.Allocatable.MemoryBytes, available.MemoryBytes},
+		{"disk", res.Allocatable.DiskBytes, available.DiskBytes},
+	} {
+		if r.allocatable == 0 {
+			continue
+		}
+		if percent := r.available * 100 / r.allocatable; percent < capacityLowPercent {
+			warnings.Add(WarningCapacityLow, "%d%% of %s of the worker nodes is available", percent, r.name)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,37 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLowCapacity(t *testing.T) {
+	t.Parallel()
+	warnings := lowCapacity(ClusterResources{
+		Nodes:       3,
+		Allocatable: Resources{CPUMillis: 12000, MemoryBytes: 48 << 30, DiskBytes: 300 << 30},
+		Used:        Resources{CPUMillis: 11500, MemoryBytes: 24 << 30, DiskBytes: 300 << 30},
+	})
+	assert.Equal(t, Warnings{
+		{Code: WarningCapacityLow, Message: "4% of CPU of the worker nodes is available"},
+		{Code: WarningCapacityLow, Message: "0% of disk of the worker nodes is available"},
+	}, warnings)
+
+	assert.Empty(t, lowCapacity(ClusterResources{}))
+}
@@ -0,0 +1,264 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/gen1us2k/everest-provisioner/data"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+)
+
+// removedAPIs maps API versions to the Kubernetes release which stopped serving them.
+// See https://kubernetes.io/docs/reference/using-api/deprecation-guide/.
+var removedAPIs = map[string]string{
+	"extensions/v1beta1":                   "1.22",
+	"apiextensions.k8s.io/v1beta1":         "1.22",
+	"apiregistration.k8s.io/v1beta1":       "1.22",
+	"admissionregistration.k8s.io/v1beta1": "1.22",
+	"rbac.authorization.k8s.io/v1beta1":    "1.22",
+	"scheduling.k8s.io/v1beta1":            "1.22",
+	"certificates.k8s.io/v1beta1":          "1.22",
+	"coordination.k8s.io/v1beta1":          "1.22",
+	"networking.k8s.io/v1beta1":            "1.22",
+	"batch/v1beta1":                        "1.25",
+	"discovery.k8s.io/v1beta1":             "1.25",
+	"events.k8s.io/v1beta1":                "1.25",
+	"autoscaling/v2beta1":                  "1.25",
+	"policy/v1beta1":                       "1.25",
+	"node.k8s.io/v1beta1":                  "1.25",
+	"autoscaling/v2beta2":                  "1.26",
+	"flowcontrol.apiserver.k8s.io/v1beta1": "1.26",
+	"storage.k8s.io/v1beta1":               "1.27",
+	"flowcontrol.apiserver.k8s.io/v1beta2": "1.29",
+	"flowcontrol.apiserver.k8s.io/v1beta3": "1.32",
+}
+
+// operatorCompatibility lists the newest Kubernetes release the operator versions were tested against.
+// Newer operator versions are assumed to support at least the same releases.
+var operatorCompatibility = map[string][]struct {
+	operator   string
+	kubernetes string
+}{
+	pxcDeploymentName: {
+		{operator: "1.11.0", kubernetes: "1.23"},
+		{operator: "1.12.0", kubernetes: "1.24"},
+		{operator: "1.13.0", kubernetes: "1.26"},
+	},
+	psmdbDeploymentName: {
+		{operator: "1.13.0", kubernetes: "1.24"},
+		{operator: "1.14.0", kubernetes: "1.25"},
+		{operator: "1.15.0", kubernetes: "1.27"},
+	},
+}
+
+// RunUpgradeChecks checks whether the installation keeps working after the Kubernetes
+// control plane is upgraded to the target version. Checks of the installed operators and
+// CRDs are skipped if they are not installed.
+// An error is returned only if the checks could not be run at all.
+func (k *Kubernetes) RunUpgradeChecks(ctx context.Context, target string) ([]PreflightCheck, error) {
+	targetVersion, err := utilversion.ParseGeneric(target)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid Kubernetes version %q", target)
+	}
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	checks := []PreflightCheck{k.checkUpgradePath(ctx, targetVersion)}
+	manifests, err := checkManifestAPIs(targetVersion)
+	if err != nil {
+		return nil, err
+	}
+	checks = append(checks, manifests)
+	crds, err := k.checkCRDStoredVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checks = append(checks, crds...)
+	for _, op := range []struct{ deployment, container string }{
+		{deployment: pxcDeploymentName, container: pxcOperatorContainerName},
+		{deployment: psmdbDeploymentName, container: psmdbOperatorContainerName},
+	} {
+		check, err := k.checkOperatorCompatibility(ctx, op.deployment, op.container, targetVersion)
+		if err != nil {
+			return nil, err
+		}
+		if check != nil {
+			checks = append(checks, *check)
+		}
+	}
+	return checks, nil
+}
+
+// checkUpgradePath checks that the target is newer than the current version and that
+// no minor version is skipped, which Kubernetes does not support for control planes.
+func (k *Kubernetes) checkUpgradePath(ctx context.Context, target *utilversion.Version) PreflightCheck {
+	check := PreflightCheck{Name: "Upgrade path"}
+	info, err := k.client.GetServerVersion(ctx)
+	if err != nil {
+		check.Message = fmt.Sprintf("cannot get server version: %s", err)
+		check.Remediation = "Make sure the kubeconfig points to a reachable cluster"
+		return check
+	}
+	current, err := utilversion.ParseGeneric(info.GitVersion)
+	if err != nil {
+		check.Message = fmt.Sprintf("cannot parse server version %q: %s", info.GitVersion, err)
+		return check
+	}
+	switch {
+	case current.Major() != target.Major() || current.Minor() >= target.Minor():
+		check.Message = fmt.Sprintf("%s is not newer than the current %s", target, info.GitVersion)
+	case target.Minor()-current.Minor() > 1:
+		check.Message = fmt.Sprintf("upgrading from %s to %s skips minor versions", info.GitVersion, target)
+		check.Remediation = fmt.Sprintf("Upgrade to %d.%d first", current.Major(), current.Minor()+1)
+	default:
+		check.Passed = true
+		check.Message = fmt.Sprintf("%s -> %s", info.GitVersion, target)
+	}
+	return check
+}
+
+// checkManifestAPIs looks for APIs removed in the target version among the manifests applied by the provisioner.
+func checkManifestAPIs(target *utilversion.Version) (PreflightCheck, error) {
+	check := PreflightCheck{Name: "APIs of applied manifests"}
+	var removed []string
+	err := fs.WalkDir(data.OLMCRDs, "crds", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		file, err := fs.ReadFile(data.OLMCRDs, path)
+		if err != nil {
+			return err
+		}
+		resources, err := decodeResources(file)
+		if err != nil {
+			return errors.Wrapf(err, "cannot decode %s", path)
+		}
+		for _, r := range resources {
+			if isRemoved(r.GetAPIVersion(), target) {
+				removed = append(removed, fmt.Sprintf("%s %s (%s)", r.GetKind(), r.GetName(), r.GetAPIVersion()))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return check, errors.Wrap(err, "cannot read embedded manifests")
+	}
+	for _, obj := range monitoringStack("") {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		if isRemoved(gvk.GroupVersion().String(), target) {
+			accessor, err := meta.Accessor(obj)
+			if err != nil {
+				return check, err
+			}
+			removed = append(removed, fmt.Sprintf("%s %s (%s)", gvk.Kind, accessor.GetName(), gvk.GroupVersion()))
+		}
+	}
+	if len(removed) != 0 {
+		check.Message = "uses removed APIs: " + strings.Join(removed, ", ")
+		check.Remediation = "Upgrade the provisioner to a release supporting the target Kubernetes version"
+		return check, nil
+	}
+	check.Passed = true
+	check.Message = "no removed APIs are used"
+	return check, nil
+}
+
+// checkCRDStoredVersions checks that objects of the operator CRDs are not stored in API versions
+// the target release stops serving. Such objects cannot be read after the upgrade.
+func (k *Kubernetes) checkCRDStoredVersions(ctx context.Context) ([]PreflightCheck, error) {
+	crds, err := k.client.ListCRDs(ctx, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, "cannot list CRDs"))
+	}
+	var checks []PreflightCheck
+	for _, crd := range crds.Items {
+		if _, ok := operatorCRDGroups[crd.Spec.Group]; !ok {
+			continue
+		}
+		check := PreflightCheck{Name: "CRD " + crd.Name, Passed: true}
+		served := make(map[string]bool, len(crd.Spec.Versions))
+		for _, v := range crd.Spec.Versions {
+			served[v.Name] = v.Served
+		}
+		var stale []string
+		for _, v := range crd.Status.StoredVersions {
+			if !served[v] {
+				stale = append(stale, v)
+			}
+		}
+		sort.Strings(stale)
+		if len(stale) != 0 {
+			check.Passed = false
+			check.Message = fmt.Sprintf("objects are stored in versions which are not served anymore: %s", strings.Join(stale, ", "))
+			check.Remediation = "Migrate the stored objects to a served version and update status.storedVersions of the CRD"
+		} else {
+			check.Message = "stored versions: " + strings.Join(crd.Status.StoredVersions, ", ")
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// checkOperatorCompatibility checks that the installed operator was tested against the target version.
+// It returns nil if the operator is not installed.
+func (k *Kubernetes) checkOperatorCompatibility(ctx context.Context, deployment, container string, target *utilversion.Version) (*PreflightCheck, error) {
+	check := &PreflightCheck{Name: "Operator " + deployment}
+	v, err := k.getOperatorVersion(ctx, deployment, container)
+	if err != nil {
+		if errors.Is(err, ErrOperatorNotFound) {
+			return nil, nil //nolint:nilnil
+		}
+		return nil, err
+	}
+	operatorVersion, err := utilversion.ParseGeneric(v)
+	if err != nil {
+		check.Message = fmt.Sprintf("cannot parse operator version %q: %s", v, err)
+		return check, nil
+	}
+	tested := ""
+	for _, c := range operatorCompatibility[deployment] {
+		if !operatorVersion.LessThan(utilversion.MustParseGeneric(c.operator)) {
+			tested = c.kubernetes
+		}
+	}
+	switch {
+	case tested == "":
+		check.Message = fmt.Sprintf("version %s is too old to be supported on any tracked Kubernetes release", v)
+		check.Remediation = "Upgrade the operator before upgrading Kubernetes"
+	case utilversion.MustParseGeneric(tested).LessThan(target):
+		check.Message = fmt.Sprintf("version %s was tested up to Kubernetes %s", v, tested)
+		check.Remediation = "Upgrade the operator before upgrading Kubernetes"
+	default:
+		check.Passed = true
+		check.Message = fmt.Sprintf("version %s supports Kubernetes %s", v, tested)
+	}
+	return check, nil
+}
+
+// isRemoved returns true if the API version is not served by the target release.
+func isRemoved(apiVersion string, target *utilversion.Version) bool {
+	removedIn, ok := removedAPIs[apiVersion]
+	if !ok {
+		return false
+	}
+	return !target.LessThan(utilversion.MustParseGeneric(removedIn))
+}
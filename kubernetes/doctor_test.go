@@ -0,0 +1,56 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+func TestCheckUpgradePath(t *testing.T) {
+	t.Parallel()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k8sclient.On("GetServerVersion", mock.Anything).Return(&version.Info{GitVersion: "v1.27.4"}, nil)
+
+	for target, passed := range map[string]bool{
+		"1.28": true,
+		"1.29": false,
+		"1.27": false,
+	} {
+		check := k.checkUpgradePath(context.Background(), utilversion.MustParseGeneric(target))
+		assert.Equal(t, passed, check.Passed, target)
+	}
+}
+
+func TestCheckManifestAPIs(t *testing.T) {
+	t.Parallel()
+	check, err := checkManifestAPIs(utilversion.MustParseGeneric("1.29"))
+	require.NoError(t, err)
+	assert.True(t, check.Passed, check.Message)
+
+	assert.True(t, isRemoved("policy/v1beta1", utilversion.MustParseGeneric("1.25")))
+	assert.False(t, isRemoved("policy/v1beta1", utilversion.MustParseGeneric("1.24")))
+	assert.False(t, isRemoved("apps/v1", utilversion.MustParseGeneric("1.29")))
+}
@@ -0,0 +1,171 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// engineOperator is the operator running database clusters of an engine and the
+// image repository of the database.
+type engineOperator struct {
+	operator   string
+	repository string
+}
+
+var engineOperators = map[string]engineOperator{
+	"pxc":   {operator: pxcDeploymentName, repository: "percona/percona-xtradb-cluster"},
+	"psmdb": {operator: psmdbDeploymentName, repository: "percona/percona-server-mongodb"},
+}
+
+// EngineVersion is a database version supported by the operator of the engine.
+type EngineVersion struct {
+	Version string `json:"version"`
+	Image   string `json:"image"`
+}
+
+// EngineVersions are the database versions supported by the installed operator of an engine.
+type EngineVersions struct {
+	Engine          string          `json:"engine"`
+	Operator        string          `json:"operator"`
+	OperatorVersion string          `json:"operatorVersion"`
+	Versions        []EngineVersion `json:"versions"`
+}
+
+// Image returns the image of the version or false if the operator does not support it.
+func (v *EngineVersions) Image(version string) (string, bool) {
+	for _, ev := range v.Versions {
+		if ev.Version == version {
+			return ev.Image, true
+		}
+	}
+	return "", false
+}
+
+// Engines returns the supported database engines.
+func Engines() []string {
+	engines := make([]string, 0, len(engineOperators))
+	for engine := range engineOperators {
+		engines = append(engines, engine)
+	}
+	sort.Strings(engines)
+	return engines
+}
+
+// ListEngineVersions returns the database versions supported by the installed operator of
+// the engine. They are read from the images the cluster service version of the operator
+// relates to, which are the database images the operator has been released with.
+func (k *Kubernetes) ListEngineVersions(ctx context.Context, engine string) (*EngineVersions, error) {
+	op, ok := engineOperators[engine]
+	if !ok {
+		return nil, errors.Errorf("unsupported database engine %q", engine)
+	}
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	namespace := k.client.Namespace()
+	sub, err := k.client.GetSubscription(ctx, namespace, op.operator)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, operatorNotFoundError(op.operator, err)
+		}
+		return nil, classifyError(errors.Wrapf(err, "cannot get subscription %s", op.operator))
+	}
+	if sub.Status.InstalledCSV == "" {
+		return nil, operatorNotFoundError(op.operator, errors.New("no cluster service version is installed"))
+	}
+	csv, err := k.client.GetClusterServiceVersion(ctx, types.NamespacedName{Namespace: namespace, Name: sub.Status.InstalledCSV})
+	if err != nil {
+		return nil, classifyError(errors.Wrapf(err, "cannot get cluster service version %s", sub.Status.InstalledCSV))
+	}
+	return &EngineVersions{
+		Engine:          engine,
+		Operator:        op.operator,
+		OperatorVersion: csv.Spec.Version.String(),
+		Versions:        relatedEngineVersions(csv.Spec.RelatedImages, op.repository),
+	}, nil
+}
+
+// relatedEngineVersions returns the tags of the related images of the repository, the
+// most recent first. Images referenced by digest only have no version and are skipped.
+func relatedEngineVersions(images []v1alpha1.RelatedImage, repository string) []EngineVersion {
+	seen := make(map[string]struct{})
+	var versions []EngineVersion
+	for _, related := range images {
+		repo, tag, ok := splitImageTag(related.Image)
+		if !ok {
+			continue
+		}
+		if repo != repository && !strings.HasSuffix(repo, "/"+repository) {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		versions = append(versions, EngineVersion{Version: tag, Image: related.Image})
+	}
+	sort.SliceStable(versions, func(i, j int) bool { return versionLess(versions[j].Version, versions[i].Version) })
+	return versions
+}
+
+// splitImageTag returns the repository and the tag of the image. The digest is ignored.
+// It returns false if the image has no tag.
+func splitImageTag(image string) (string, string, bool) {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+	i := strings.LastIndex(image, ":")
+	if i == -1 || strings.Contains(image[i:], "/") {
+		return "", "", false
+	}
+	return image[:i], image[i+1:], true
+}
+
+// versionLess compares dotted versions numerically, e.g. 8.0.9 is less than 8.0.27.
+func versionLess(a, b string) bool {
+	as := strings.FieldsFunc(a, isVersionSeparator)
+	bs := strings.FieldsFunc(b, isVersionSeparator)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		if len(as[i]) != len(bs[i]) && isDigits(as[i]) && isDigits(bs[i]) {
+			return len(as[i]) < len(bs[i])
+		}
+		return as[i] < bs[i]
+	}
+	return len(as) < len(bs)
+}
+
+func isVersionSeparator(r rune) bool {
+	return r == '.' || r == '-'
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/fake"
+	"github.com/operator-framework/api/pkg/lib/version"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestListEngineVersions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	sub := &v1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "percona-xtradb-cluster-operator", Namespace: "default"},
+		Spec:       &v1alpha1.SubscriptionSpec{Package: "percona-xtradb-cluster-operator"},
+		Status:     v1alpha1.SubscriptionStatus{InstalledCSV: "percona-xtradb-cluster-operator.v1.12.0"},
+	}
+	csv := &v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "percona-xtradb-cluster-operator.v1.12.0", Namespace: "default"},
+		Spec: v1alpha1.ClusterServiceVersionSpec{
+			Version: version.OperatorVersion{Version: semver.MustParse("1.12.0")},
+			RelatedImages: []v1alpha1.RelatedImage{
+				{Name: "pxc8.0.9", Image: "percona/percona-xtradb-cluster:8.0.9-18.1"},
+				{Name: "pxc8.0.27", Image: "docker.io/percona/percona-xtradb-cluster:8.0.27-18.1"},
+				{Name: "pxc-digest", Image: "percona/percona-xtradb-cluster@sha256:0123"},
+				{Name: "haproxy", Image: "percona/percona-xtradb-cluster-operator:1.12.0-haproxy"},
+			},
+		},
+	}
+	c, err := fake.NewKubeClient("default", sub, csv)
+	require.NoError(t, err)
+	k := NewWithClient(c)
+
+	versions, err := k.ListEngineVersions(ctx, "pxc")
+	require.NoError(t, err)
+	assert.Equal(t, "1.12.0", versions.OperatorVersion)
+	assert.Equal(t, []EngineVersion{
+		{Version: "8.0.27-18.1", Image: "docker.io/percona/percona-xtradb-cluster:8.0.27-18.1"},
+		{Version: "8.0.9-18.1", Image: "percona/percona-xtradb-cluster:8.0.9-18.1"},
+	}, versions.Versions)
+	image, ok := versions.Image("8.0.9-18.1")
+	assert.True(t, ok)
+	assert.Equal(t, "percona/percona-xtradb-cluster:8.0.9-18.1", image)
+
+	_, err = k.ListEngineVersions(ctx, "psmdb")
+	assert.True(t, errors.Is(err, ErrOperatorNotFound))
+	_, err = k.ListEngineVersions(ctx, "pg")
+	assert.Error(t, err)
+}
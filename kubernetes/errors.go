@@ -0,0 +1,86 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Typed errors returned by the package. Use errors.Is to check for them;
+// the original error is kept in the chain.
+var (
+	// ErrOperatorNotFound is returned when a required operator is not installed.
+	ErrOperatorNotFound = errors.New("operator is not installed")
+	// ErrTimeout is returned when an operation did not complete in time.
+	ErrTimeout = errors.New("operation timed out")
+	// ErrForbidden is returned when the user lacks permissions for an operation.
+	ErrForbidden = errors.New("insufficient permissions")
+	// ErrAlreadyExists is returned when a resource to be created already exists.
+	ErrAlreadyExists = errors.New("resource already exists")
+	// ErrDatabaseClusterDeleted is returned when a watched database cluster has been deleted.
+	ErrDatabaseClusterDeleted = errors.New("database cluster has been deleted")
+	// ErrRestartAborted is returned when a rolling restart is stopped because the database cluster is unhealthy.
+	ErrRestartAborted = errors.New("restart aborted, database cluster is unhealthy")
+)
+
+// typedError marks an error with one of the typed errors of the package.
+type typedError struct {
+	kind error
+	err  error
+}
+
+func (e *typedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *typedError) Unwrap() error {
+	return e.err
+}
+
+func (e *typedError) Is(target error) bool {
+	return target == e.kind
+}
+
+// classifyError marks API, wait and context errors with the matching typed error.
+// Other errors are returned as is.
+func classifyError(err error) error {
+	var typed *typedError
+	if err == nil || errors.As(err, &typed) {
+		return err
+	}
+	var kind error
+	switch {
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		kind = ErrForbidden
+	case apierrors.IsAlreadyExists(err):
+		kind = ErrAlreadyExists
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err),
+		errors.Is(err, wait.ErrWaitTimeout), errors.Is(err, context.DeadlineExceeded):
+		kind = ErrTimeout
+	default:
+		return err
+	}
+	return &typedError{kind: kind, err: err}
+}
+
+// operatorNotFoundError returns ErrOperatorNotFound for the operator.
+func operatorNotFoundError(name string, err error) error {
+	return &typedError{kind: ErrOperatorNotFound, err: errors.Wrapf(err, "operator %q is not installed", name)}
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestClassifyError(t *testing.T) {
+	gr := schema.GroupResource{Group: "dbaas.percona.com", Resource: "databaseclusters"}
+	for name, tc := range map[string]struct {
+		err  error
+		kind error
+	}{
+		"forbidden":      {err: apierrors.NewForbidden(gr, "test", errors.New("denied")), kind: ErrForbidden},
+		"already exists": {err: errors.Wrap(apierrors.NewAlreadyExists(gr, "test"), "cannot create"), kind: ErrAlreadyExists},
+		"wait timeout":   {err: wait.ErrWaitTimeout, kind: ErrTimeout},
+		"deadline":       {err: context.DeadlineExceeded, kind: ErrTimeout},
+		"not found":      {err: operatorNotFoundError("dbaas-operator", apierrors.NewNotFound(gr, "test")), kind: ErrOperatorNotFound},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := classifyError(tc.err)
+			assert.ErrorIs(t, err, tc.kind)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	plain := errors.New("plain")
+	assert.Equal(t, plain, classifyError(plain))
+	assert.NoError(t, classifyError(nil))
+}
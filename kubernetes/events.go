@@ -0,0 +1,71 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reasons of the events recorded for provisioning steps.
+const (
+	EventReasonOLMInstalled           = "OLMInstalled"
+	EventReasonCatalogInstalled       = "CatalogInstalled"
+	EventReasonOperatorInstalled      = "OperatorInstalled"
+	EventReasonMonitoringProvisioned  = "MonitoringProvisioned"
+	EventReasonCertManagerProvisioned = "CertManagerProvisioned"
+	EventReasonNetworkPoliciesApplied = "NetworkPoliciesApplied"
+	EventReasonBackendDeployed        = "BackendDeployed"
+	EventReasonProvisioningFailed     = "ProvisioningFailed"
+
+	eventSourceComponent = "everest-provisioner"
+)
+
+// RecordEvent records a Kubernetes event about a provisioning step so cluster admins can
+// audit what the provisioner changed and when. Events are attached to the state config map
+// of the installation in the namespace.
+func (k *Kubernetes) RecordEvent(ctx context.Context, namespace, eventType, reason, message string) error {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	now := metav1.NewTime(time.Now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: StateConfigMapName + ".",
+			Namespace:    namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Namespace:  namespace,
+			Name:       StateConfigMapName,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: eventSourceComponent},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if err := k.client.CreateEvent(ctx, event); err != nil {
+		return classifyError(errors.Wrapf(err, "cannot record %s event", reason))
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRecordEvent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	var event *corev1.Event
+	k8sclient.On("CreateEvent", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		event = args.Get(1).(*corev1.Event)
+	}).Return(nil)
+
+	err := k.RecordEvent(ctx, "default", corev1.EventTypeNormal, EventReasonOLMInstalled, "OLM has been installed")
+	require.NoError(t, err)
+	assert.Equal(t, "default", event.Namespace)
+	assert.Equal(t, StateConfigMapName, event.InvolvedObject.Name)
+	assert.Equal(t, EventReasonOLMInstalled, event.Reason)
+	assert.Equal(t, eventSourceComponent, event.Source.Component)
+}
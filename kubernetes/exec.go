@@ -0,0 +1,145 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// DatabaseExecOptions describes the command run by ExecInDatabaseCluster.
+type DatabaseExecOptions struct {
+	Command []string
+	// Pod and Container override the primary pod and the database container of the engine.
+	Pod       string
+	Container string
+	// Stdin is attached to the command if set.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// TTY allocates a terminal for the command. Stderr is merged into Stdout then.
+	TTY bool
+	// TerminalSizes reports the size of the local terminal if TTY is set.
+	TerminalSizes remotecommand.TerminalSizeQueue
+}
+
+// databaseExecTargets holds the container running the database and the labels selecting
+// the pods commands are run in. The operators of PXC and PSMDB do not label the primary.
+// The first ready PXC pod by name is used; it is the writer node HAProxy routes to. The
+// primary of a PSMDB replica set is asked from its first ready member.
+var databaseExecTargets = map[dbaasv1.EngineType]struct {
+	container string
+	labels    map[string]string
+}{
+	"pxc":        {container: "pxc", labels: map[string]string{"app.kubernetes.io/component": "pxc"}},
+	"psmdb":      {container: "mongod", labels: map[string]string{"app.kubernetes.io/component": "mongod"}},
+	"postgresql": {container: "database", labels: map[string]string{"postgres-operator.crunchydata.com/role": "master"}},
+}
+
+// ExecInDatabaseCluster runs the command in the database container of the primary pod of
+// the database cluster and streams its input and output until it exits. The exit status
+// of a failed command is reported by an error implementing k8s.io/client-go/util/exec.ExitError.
+func (k *Kubernetes) ExecInDatabaseCluster(ctx context.Context, name string, opts DatabaseExecOptions) error {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	cluster, err := k.client.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return classifyError(err)
+	}
+	target, ok := databaseExecTargets[cluster.Spec.Database]
+	if !ok && (opts.Pod == "" || opts.Container == "") {
+		return errors.Errorf("exec into %q database engine is not supported, set the pod and the container", cluster.Spec.Database)
+	}
+	pod, container := opts.Pod, opts.Container
+	if container == "" {
+		container = target.container
+	}
+	if pod == "" {
+		selector := map[string]string{instanceLabelKey: name}
+		for key, value := range target.labels {
+			selector[key] = value
+		}
+		pods, err := k.client.GetPods(ctx, cluster.Namespace, &metav1.LabelSelector{MatchLabels: selector})
+		if err != nil {
+			return classifyError(errors.Wrapf(err, "cannot list pods of database cluster %s", name))
+		}
+		if pod = primaryPod(pods.Items); pod == "" {
+			return errors.Errorf("database cluster %s has no ready primary pod", name)
+		}
+		if cluster.Spec.Database == "psmdb" {
+			if pod, err = k.mongoPrimary(ctx, cluster.Namespace, pod, target.container); err != nil {
+				return errors.Wrapf(err, "cannot find the primary of database cluster %s", name)
+			}
+		}
+	}
+
+	return k.client.Exec(ctx, cluster.Namespace, pod, container, client.ExecOptions{
+		Command:       opts.Command,
+		Stdin:         opts.Stdin,
+		Stdout:        opts.Stdout,
+		Stderr:        opts.Stderr,
+		TTY:           opts.TTY,
+		TerminalSizes: opts.TerminalSizes,
+	})
+}
+
+// primaryPod returns the name of the first running and ready pod by name.
+func primaryPod(pods []corev1.Pod) string {
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && podReady(pod) {
+			return pod.Name
+		}
+	}
+	return ""
+}
+
+// mongoPrimaryCommand prints the host of the replica set primary. The shell is mongosh
+// in MongoDB 6 and the legacy mongo shell before; hello and isMaster do not require
+// authentication.
+const mongoPrimaryCommand = `mongosh --quiet --eval 'db.hello().primary' 2>/dev/null || mongo --quiet --eval 'db.isMaster().primary'`
+
+// mongoPrimary returns the pod of the replica set primary asking the member pod for it.
+// The primary is reported by its host name which starts with the name of its pod.
+func (k *Kubernetes) mongoPrimary(ctx context.Context, namespace, member, container string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := k.client.Exec(ctx, namespace, member, container, client.ExecOptions{
+		Command: []string{"sh", "-c", mongoPrimaryCommand},
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot query %s: %s", member, strings.TrimSpace(stderr.String()))
+	}
+	host := strings.TrimSpace(stdout.String())
+	pod, _, _ := strings.Cut(host, ".")
+	pod, _, _ = strings.Cut(pod, ":")
+	if pod == "" {
+		return "", errors.New("the replica set has no primary")
+	}
+	return pod, nil
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExecInDatabaseCluster(t *testing.T) {
+	ctx := context.Background()
+	pod := func(name string, ready corev1.ConditionStatus) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+	cluster := &dbaasv1.DatabaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: "db"},
+		Spec:       dbaasv1.DatabaseSpec{Database: "pxc"},
+	}
+	command := []string{"mysql", "-uroot"}
+
+	t.Run("primary", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster, nil)
+		k8sclient.On("GetPods", ctx, "db", &metav1.LabelSelector{MatchLabels: map[string]string{
+			instanceLabelKey:              "mysql",
+			"app.kubernetes.io/component": "pxc",
+		}}).Return(&corev1.PodList{Items: []corev1.Pod{
+			pod("mysql-pxc-2", corev1.ConditionTrue),
+			pod("mysql-pxc-1", corev1.ConditionTrue),
+			pod("mysql-pxc-0", corev1.ConditionFalse),
+		}}, nil)
+		k8sclient.On("Exec", ctx, "db", "mysql-pxc-1", "pxc", mock.MatchedBy(func(opts client.ExecOptions) bool {
+			return len(opts.Command) == 2 && opts.Command[0] == "mysql"
+		})).Return(nil)
+
+		require.NoError(t, k.ExecInDatabaseCluster(ctx, "mysql", DatabaseExecOptions{Command: command}))
+		k8sclient.AssertExpectations(t)
+	})
+
+	t.Run("psmdb primary", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetDatabaseCluster", ctx, "mongo").Return(&dbaasv1.DatabaseCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "mongo", Namespace: "db"},
+			Spec:       dbaasv1.DatabaseSpec{Database: "psmdb"},
+		}, nil)
+		k8sclient.On("GetPods", ctx, "db", mock.Anything).Return(&corev1.PodList{Items: []corev1.Pod{
+			pod("mongo-rs0-0", corev1.ConditionTrue),
+			pod("mongo-rs0-1", corev1.ConditionTrue),
+		}}, nil)
+		k8sclient.On("Exec", ctx, "db", "mongo-rs0-0", "mongod", mock.MatchedBy(func(opts client.ExecOptions) bool {
+			return len(opts.Command) == 3 && opts.Command[2] == mongoPrimaryCommand
+		})).Return(nil).Run(func(args mock.Arguments) {
+			opts := args.Get(4).(client.ExecOptions)
+			_, _ = opts.Stdout.Write([]byte("mongo-rs0-1.mongo-rs0.db.svc.cluster.local:27017\n"))
+		}).Once()
+		k8sclient.On("Exec", ctx, "db", "mongo-rs0-1", "mongod", mock.MatchedBy(func(opts client.ExecOptions) bool {
+			return len(opts.Command) == 1 && opts.Command[0] == "mongosh"
+		})).Return(nil).Once()
+
+		require.NoError(t, k.ExecInDatabaseCluster(ctx, "mongo", DatabaseExecOptions{Command: []string{"mongosh"}}))
+		k8sclient.AssertExpectations(t)
+	})
+
+	t.Run("no ready pod", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster, nil)
+		k8sclient.On("GetPods", ctx, "db", mock.Anything).Return(&corev1.PodList{Items: []corev1.Pod{
+			pod("mysql-pxc-0", corev1.ConditionFalse),
+		}}, nil)
+
+		require.ErrorContains(t, k.ExecInDatabaseCluster(ctx, "mysql", DatabaseExecOptions{Command: command}), "no ready primary pod")
+	})
+
+	t.Run("pod override", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster, nil)
+		k8sclient.On("Exec", ctx, "db", "mysql-haproxy-0", "haproxy", mock.Anything).Return(nil)
+
+		require.NoError(t, k.ExecInDatabaseCluster(ctx, "mysql", DatabaseExecOptions{
+			Command:   []string{"sh"},
+			Pod:       "mysql-haproxy-0",
+			Container: "haproxy",
+		}))
+		k8sclient.AssertExpectations(t)
+	})
+}
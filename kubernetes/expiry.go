@@ -0,0 +1,73 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// expiresAtAnnotationKey holds the time after which an ephemeral database cluster is deleted.
+const expiresAtAnnotationKey = "dbaas.percona.com/expires-at"
+
+// SetExpiry marks the object to be deleted after the given time.
+func SetExpiry(obj metav1.Object, at time.Time) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[expiresAtAnnotationKey] = at.UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+}
+
+// ExpiresAt returns the expiry time of the object. It returns false if the object
+// has no expiry or the expiry cannot be parsed.
+func ExpiresAt(obj metav1.Object) (time.Time, bool) {
+	value, ok := obj.GetAnnotations()[expiresAtAnnotationKey]
+	if !ok {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// DeleteExpiredDatabaseClusters deletes the database clusters which expired before now.
+// It returns the names of the deleted clusters.
+func (k *Kubernetes) DeleteExpiredDatabaseClusters(ctx context.Context, now time.Time) ([]string, error) {
+	clusters, err := k.ListDatabaseClusters(ctx)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, "cannot list database clusters"))
+	}
+	var deleted []string
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		at, ok := ExpiresAt(cluster)
+		if !ok || at.After(now) || cluster.DeletionTimestamp != nil {
+			continue
+		}
+		if err := k.DeleteDatabaseCluster(ctx, cluster.Name); err != nil {
+			return deleted, errors.Wrapf(err, "cannot delete expired database cluster %s", cluster.Name)
+		}
+		deleted = append(deleted, cluster.Name)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeleteExpiredDatabaseClusters(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	expired := dbaasv1.DatabaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "expired"}}
+	SetExpiry(&expired, now.Add(-time.Minute))
+	active := dbaasv1.DatabaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "active"}}
+	SetExpiry(&active, now.Add(time.Hour))
+	permanent := dbaasv1.DatabaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "permanent"}}
+
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k8sclient.On("ListDatabaseClusters", ctx).Return(&dbaasv1.DatabaseClusterList{
+		Items: []dbaasv1.DatabaseCluster{expired, active, permanent},
+	}, nil)
+	k8sclient.On("GetDatabaseCluster", ctx, "expired").Return(&expired, nil)
+	k8sclient.On("DeleteObject", mock.Anything, mock.Anything).Return(nil)
+
+	deleted, err := k.DeleteExpiredDatabaseClusters(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"expired"}, deleted)
+	k8sclient.AssertNumberOfCalls(t, "DeleteObject", 1)
+}
@@ -0,0 +1,224 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+
+	"github.com/gen1us2k/everest-provisioner/data"
+	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// Names of the exported components in the order they are applied.
+const (
+	ExportComponentOLM        = "olm"
+	ExportComponentCatalog    = "catalog"
+	ExportComponentOperators  = "operators"
+	ExportComponentMonitoring = "monitoring"
+)
+
+// ExportOptions describe the installation rendered by ExportManifests.
+type ExportOptions struct {
+	// InstallOLM renders OLM tuned with OLMTuning. OLM is expected to be installed otherwise.
+	InstallOLM       bool
+	OLMTuning        OLMTuning
+	Catalog          string
+	CatalogNamespace string
+	CatalogImage     string
+	// Namespace is the namespace of the operators and the monitoring stack.
+	Namespace     string
+	OperatorGroup string
+	Operators     []InstallOperatorRequest
+	// MonitoringEndpoint is the PMM endpoint metrics are sent to. Monitoring is not rendered if it is empty.
+	MonitoringEndpoint string
+	// MonitoringCredentialsSecret names the secret with the credentials metrics are written with.
+	// It is not rendered; credentials are never stored in the exported manifests.
+	MonitoringCredentialsSecret string
+	MonitoringTLS               MonitoringTLS
+	Selective                   bool
+}
+
+// ExportComponent is a group of manifests applied together.
+type ExportComponent struct {
+	Name    string
+	Objects []unstructured.Unstructured
+}
+
+// ExportManifests renders the objects ProvisionCluster would apply as components in the
+// order they have to be applied. Subscriptions approve install plans automatically since
+// GitOps controllers cannot approve them.
+func (k *Kubernetes) ExportManifests(opts ExportOptions) ([]ExportComponent, error) {
+	var components []ExportComponent
+	if opts.InstallOLM {
+		objs, err := olmManifests(opts.OLMTuning)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, ExportComponent{Name: ExportComponentOLM, Objects: objs})
+	}
+
+	catalog, err := k.catalogSource(opts.Catalog, opts.CatalogImage, opts.CatalogNamespace)
+	if err != nil {
+		return nil, err
+	}
+	components = append(components, ExportComponent{Name: ExportComponentCatalog, Objects: []unstructured.Unstructured{*catalog}})
+
+	typed := []runtime.Object{operatorGroup(opts.Namespace, opts.OperatorGroup)}
+	for _, req := range opts.Operators {
+		typed = append(typed, exportedSubscription(req))
+	}
+	objs, err := k.exportObjects(typed)
+	if err != nil {
+		return nil, err
+	}
+	components = append(components, ExportComponent{Name: ExportComponentOperators, Objects: objs})
+
+	if opts.MonitoringEndpoint != "" {
+		if opts.MonitoringCredentialsSecret == "" {
+			return nil, errors.New("monitoring can be exported only with a credentials secret")
+		}
+		if opts.MonitoringTLS.hasCertificates() {
+			return nil, errors.New("monitoring with PMM certificates cannot be exported")
+		}
+		vmagent := vmAgentSpec(opts.MonitoringCredentialsSecret, opts.MonitoringEndpoint, opts.MonitoringTLS)
+		vmagent.Namespace = opts.Namespace
+		if opts.Selective {
+			restrictScrapeSelectors(&vmagent.Spec)
+		}
+		customizeScraping(&vmagent.Spec, k.scrape)
+		if k.hardened {
+			restrictVMAgent(&vmagent.Spec)
+		}
+		vmagent.Spec.Resources = overrideResources(vmagent.Spec.Resources, k.monitoringResources.VMAgent)
+		scheduleVMAgent(&vmagent.Spec, k.scheduling)
+		vmagent.Spec.ImagePullSecrets = k.imagePullSecretRefs()
+		objs, err := k.exportObjects(append(monitoringStack(opts.Namespace), vmagent))
+		if err != nil {
+			return nil, err
+		}
+		for i := range objs {
+			k.labelScrape(&objs[i], objs[i].GetKind())
+		}
+		components = append(components, ExportComponent{Name: ExportComponentMonitoring, Objects: objs})
+	}
+	return components, nil
+}
+
+// olmManifests returns the CRDs and the deployments of OLM.
+func olmManifests(tuning OLMTuning) ([]unstructured.Unstructured, error) {
+	var objs []unstructured.Unstructured
+	for _, file := range []string{"crds/olm/crds.yaml", "crds/olm/olm.yaml"} {
+		content, err := fs.ReadFile(data.OLMCRDs, file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", file)
+		}
+		resources, err := decodeResources(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot decode %s", file)
+		}
+		objs = append(objs, resources...)
+	}
+	if len(tuning) != 0 {
+		if err := tuneOLMResources(objs, tuning); err != nil {
+			return nil, err
+		}
+	}
+	return objs, nil
+}
+
+func operatorGroup(namespace, name string) *operatorsv1.OperatorGroup {
+	return &operatorsv1.OperatorGroup{
+		TypeMeta:   metav1.TypeMeta{APIVersion: operatorsv1.SchemeGroupVersion.String(), Kind: "OperatorGroup"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       operatorsv1.OperatorGroupSpec{TargetNamespaces: []string{namespace}},
+	}
+}
+
+func exportedSubscription(req InstallOperatorRequest) *v1alpha1.Subscription {
+	return &v1alpha1.Subscription{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SubscriptionCRDAPIVersion, Kind: v1alpha1.SubscriptionKind},
+		ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+		Spec: &v1alpha1.SubscriptionSpec{
+			CatalogSource:          req.CatalogSource,
+			CatalogSourceNamespace: req.CatalogSourceNamespace,
+			Package:                req.Name,
+			Channel:                req.Channel,
+			StartingCSV:            req.StartingCSV,
+			InstallPlanApproval:    v1alpha1.ApprovalAutomatic,
+		},
+	}
+}
+
+// exportObjects converts the objects to unstructured ones adjusted like applied objects.
+func (k *Kubernetes) exportObjects(typed []runtime.Object) ([]unstructured.Unstructured, error) {
+	objs := make([]unstructured.Unstructured, 0, len(typed))
+	for _, t := range typed {
+		// The objects are encoded as JSON since the unstructured converter cannot encode
+		// nil timestamps without omitempty, e.g. the status of operator groups.
+		data, err := json.Marshal(t)
+		if err != nil {
+			return nil, err
+		}
+		var content map[string]interface{}
+		if err := utiljson.Unmarshal(data, &content); err != nil {
+			return nil, err
+		}
+		obj := unstructured.Unstructured{Object: content}
+		unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+		unstructured.RemoveNestedField(obj.Object, "status")
+		if err := k.adjustObject(&obj); err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// MarshalManifests renders the objects as a multi-document YAML manifest.
+func MarshalManifests(objs []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, obj := range objs {
+		content, err := json.Marshal(obj)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot marshal the manifest")
+		}
+		var doc interface{}
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			return nil, errors.Wrap(err, "cannot marshal the manifest")
+		}
+		if i != 0 {
+			buf.WriteString("---\n")
+		}
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, errors.Wrap(err, "cannot marshal the manifest")
+		}
+		if err := enc.Close(); err != nil {
+			return nil, errors.Wrap(err, "cannot marshal the manifest")
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExportManifests(t *testing.T) {
+	k := NewEmpty()
+	opts := ExportOptions{
+		Catalog:          "percona-dbaas-catalog",
+		CatalogNamespace: "olm",
+		Namespace:        "default",
+		OperatorGroup:    "percona-operators-group",
+		Operators: []InstallOperatorRequest{{
+			Namespace:              "default",
+			Name:                   "dbaas-operator",
+			CatalogSource:          "percona-dbaas-catalog",
+			CatalogSourceNamespace: "olm",
+			Channel:                "stable-v0",
+		}},
+		MonitoringEndpoint:          "https://pmm.example.com",
+		MonitoringCredentialsSecret: "pmm-credentials",
+	}
+	components, err := k.ExportManifests(opts)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(components))
+	for _, c := range components {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{ExportComponentCatalog, ExportComponentOperators, ExportComponentMonitoring}, names)
+
+	sub := components[1].Objects[1]
+	assert.Equal(t, "Subscription", sub.GetKind())
+	approval, _, _ := unstructured.NestedString(sub.Object, "spec", "installPlanApproval")
+	assert.Equal(t, "Automatic", approval)
+
+	agents := 0
+	for _, obj := range components[2].Objects {
+		if obj.GetKind() == "VMAgent" {
+			agents++
+			assert.Equal(t, vmAgentNamePrefix+"pmm-credentials", obj.GetName())
+		}
+	}
+	assert.Equal(t, 1, agents)
+
+	manifest, err := MarshalManifests([]interface{}{sub.Object})
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), "channel: stable-v0")
+
+	opts.MonitoringCredentialsSecret = ""
+	_, err = k.ExportManifests(opts)
+	assert.Error(t, err)
+}
@@ -0,0 +1,1163 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package fake provides a KubeClientConnector backed by the fake clientset and the fake
+// dynamic client of client-go so code using the kubernetes package can be tested without a cluster.
+package fake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	vmv1beta1 "github.com/VictoriaMetrics/operator/api/victoriametrics/v1beta1"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client/database"
+	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	packagev1 "github.com/operator-framework/operator-lifecycle-manager/pkg/package-server/apis/operators/v1"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	deploymentutil "k8s.io/kubectl/pkg/util/deployment"
+)
+
+const pollInterval = 10 * time.Millisecond
+
+// ErrNotSupported is returned by the methods which need a real API server.
+var ErrNotSupported = errors.New("not supported by the fake client")
+
+var (
+	databaseClustersResource       = dbaasv1.GroupVersion.WithResource("databaseclusters")
+	databaseClusterBackupsResource = dbaasv1.GroupVersion.WithResource("databaseclusterbackups")
+	subscriptionsResource          = v1alpha1.SchemeGroupVersion.WithResource("subscriptions")
+	csvsResource                   = v1alpha1.SchemeGroupVersion.WithResource("clusterserviceversions")
+	installPlansResource           = v1alpha1.SchemeGroupVersion.WithResource("installplans")
+	catalogSourcesResource         = v1alpha1.SchemeGroupVersion.WithResource("catalogsources")
+	packageManifestsResource       = packagev1.SchemeGroupVersion.WithResource("packagemanifests")
+	operatorGroupsResource         = operatorsv1.SchemeGroupVersion.WithResource("operatorgroups")
+	crdsResource                   = apiextv1.SchemeGroupVersion.WithResource("customresourcedefinitions")
+	vmAgentsResource               = vmv1beta1.GroupVersion.WithResource("vmagents")
+)
+
+// builtinScheme recognizes the kinds served by the fake clientset.
+// The scheme of client-go is not used since other packages register custom types in it.
+var builtinScheme = func() *runtime.Scheme {
+	s := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(s))
+	return s
+}()
+
+// clusterScopedKinds lists the cluster scoped kinds applied by the provisioner.
+// Objects of other kinds without a namespace are placed in the namespace of the client.
+var clusterScopedKinds = map[string]bool{
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"StorageClass":                   true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"APIService":                     true,
+	"PriorityClass":                  true,
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// KubeClient implements client.KubeClientConnector. Built-in resources are served by
+// Clientset, database clusters, OLM objects, CRDs and other custom resources by Dynamic.
+// No controllers run, so statuses change only when they are updated by the test
+// unless ResolveSubscriptions is set.
+type KubeClient struct {
+	// Clientset serves the built-in resources. Prepend reactors to it to inject failures.
+	Clientset *k8sfake.Clientset
+	// Dynamic serves the custom resources. Prepend reactors to it to inject failures.
+	Dynamic *dynamicfake.FakeDynamicClient
+	// Logs maps "<pod>/<container>" to the logs returned by GetLogs.
+	Logs map[string]string
+	// NodeStats and KubeletConfigs map node names to the raw responses of their kubelets.
+	NodeStats      map[string][]byte
+	KubeletConfigs map[string][]byte
+	// OIDCIssuer is returned by GetOIDCIssuer.
+	OIDCIssuer string
+	// SkipWaits makes the wait methods check their condition once instead of polling
+	// until a controller, which does not run here, updates the status.
+	SkipWaits bool
+	// ResolveSubscriptions makes subscriptions resolve like OLM does: an install plan for the
+	// starting CSV is created with the subscription, and the CSV is installed with the
+	// Succeeded phase once the install plan is approved.
+	ResolveSubscriptions bool
+
+	scheme      *runtime.Scheme
+	namespace   string
+	labels      map[string]string
+	annotations map[string]string
+}
+
+var _ client.KubeClientConnector = (*KubeClient)(nil)
+
+// unstructuredKinds are the kinds of the custom resources read without Go types, e.g. the
+// clusters of the engine operators. They are registered to be listed by the dynamic client.
+var unstructuredKinds = []schema.GroupVersionKind{
+	{Group: "pxc.percona.com", Version: "v1", Kind: "PerconaXtraDBCluster"},
+	{Group: "psmdb.percona.com", Version: "v1", Kind: "PerconaServerMongoDB"},
+	{Group: "cert-manager.io", Version: "v1", Kind: "Issuer"},
+	{Group: "everest.percona.com", Version: "v1alpha1", Kind: "EverestInstallation"},
+}
+
+// NewKubeClient returns a fake client for the namespace holding the objects.
+// Objects without a namespace are placed in the namespace unless they are cluster scoped.
+// Access reviews are allowed unless a reactor denying them is prepended to Clientset.
+func NewKubeClient(namespace string, objects ...runtime.Object) (*KubeClient, error) {
+	s := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		apiextv1.AddToScheme,
+		dbaasv1.AddToScheme,
+		v1alpha1.AddToScheme,
+		operatorsv1.AddToScheme,
+		vmv1beta1.AddToScheme,
+		packagev1.AddToScheme,
+	} {
+		if err := add(s); err != nil {
+			return nil, err
+		}
+	}
+	s.AddKnownTypes(dbaasv1.GroupVersion, &database.DatabaseClusterBackup{}, &database.DatabaseClusterBackupList{})
+	for _, gvk := range unstructuredKinds {
+		s.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+	}
+	f := &KubeClient{
+		Clientset:      k8sfake.NewSimpleClientset(),
+		Dynamic:        dynamicfake.NewSimpleDynamicClient(s),
+		Logs:           make(map[string]string),
+		NodeStats:      make(map[string][]byte),
+		KubeletConfigs: make(map[string][]byte),
+		scheme:         s,
+		namespace:      namespace,
+	}
+	f.Clientset.PrependReactor("create", "selfsubjectaccessreviews", allowAccessReview)
+	for _, obj := range objects {
+		tracker, _, obj, err := f.normalize(obj)
+		if err != nil {
+			return nil, err
+		}
+		if err := tracker.Add(obj); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func allowAccessReview(action k8stesting.Action) (bool, runtime.Object, error) {
+	review, ok := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+	if !ok {
+		return false, nil, nil
+	}
+	review = review.DeepCopy()
+	review.Status.Allowed = true
+	return true, review, nil
+}
+
+// normalize returns the tracker serving the object, its resource and a copy of the object
+// in the representation used by the tracker with the namespace set.
+func (f *KubeClient) normalize(obj runtime.Object) (k8stesting.ObjectTracker, schema.GroupVersionResource, runtime.Object, error) {
+	gvk, err := f.kindOf(obj)
+	if err != nil {
+		return nil, schema.GroupVersionResource{}, nil, err
+	}
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+	var tracker k8stesting.ObjectTracker
+	if builtinScheme.Recognizes(gvk) {
+		tracker = f.Clientset.Tracker()
+		obj, err = toTyped(obj, gvk)
+	} else {
+		tracker = f.Dynamic.Tracker()
+		obj, err = toUnstructured(obj, gvk)
+	}
+	if err != nil {
+		return nil, gvr, nil, err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, gvr, nil, err
+	}
+	if accessor.GetNamespace() == "" && !clusterScopedKinds[gvk.Kind] {
+		accessor.SetNamespace(f.namespace)
+	}
+	return tracker, gvr, obj, nil
+}
+
+func (f *KubeClient) kindOf(obj runtime.Object) (schema.GroupVersionKind, error) {
+	if gvk := obj.GetObjectKind().GroupVersionKind(); !gvk.Empty() {
+		return gvk, nil
+	}
+	gvks, _, err := f.scheme.ObjectKinds(obj)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	return gvks[0], nil
+}
+
+func toTyped(obj runtime.Object, gvk schema.GroupVersionKind) (runtime.Object, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj.DeepCopyObject(), nil
+	}
+	typed, err := builtinScheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, typed); err != nil {
+		return nil, err
+	}
+	return typed, nil
+}
+
+func toUnstructured(obj runtime.Object, gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	// The object is encoded as JSON like by the API server. The unstructured converter cannot
+	// encode nil timestamps without omitempty, e.g. the status of operator groups.
+	encoded, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]interface{}
+	if err := utiljson.Unmarshal(encoded, &data); err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: data}
+	u.SetGroupVersionKind(gvk)
+	return u, nil
+}
+
+func (f *KubeClient) get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, into runtime.Object) error {
+	u, err := f.Dynamic.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into)
+}
+
+func (f *KubeClient) list(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions, into runtime.Object) error {
+	l, err := f.Dynamic.Resource(gvr).Namespace(namespace).List(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(l.UnstructuredContent(), into)
+}
+
+// write creates or updates the custom resource and decodes the stored object into the object.
+func (f *KubeClient) write(ctx context.Context, gvr schema.GroupVersionResource, obj runtime.Object, update bool) error {
+	gvk, err := f.kindOf(obj)
+	if err != nil {
+		return err
+	}
+	u, err := toUnstructured(obj, gvk)
+	if err != nil {
+		return err
+	}
+	resource := f.Dynamic.Resource(gvr).Namespace(u.GetNamespace())
+	if update {
+		u, err = resource.Update(ctx, u, metav1.UpdateOptions{})
+	} else {
+		u, err = resource.Create(ctx, u, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj)
+}
+
+func (f *KubeClient) addCommonMetadata(obj metav1.Object) {
+	if len(f.labels) != 0 {
+		obj.SetLabels(mergeMetadata(obj.GetLabels(), f.labels))
+	}
+	if len(f.annotations) != 0 {
+		obj.SetAnnotations(mergeMetadata(obj.GetAnnotations(), f.annotations))
+	}
+}
+
+func mergeMetadata(current, common map[string]string) map[string]string {
+	if current == nil {
+		current = make(map[string]string, len(common))
+	}
+	for k, v := range common {
+		if _, ok := current[k]; !ok {
+			current[k] = v
+		}
+	}
+	return current
+}
+
+func listOptions(labelSelector *metav1.LabelSelector) metav1.ListOptions {
+	options := metav1.ListOptions{}
+	if labelSelector != nil && (labelSelector.MatchLabels != nil || labelSelector.MatchExpressions != nil) {
+		options.LabelSelector = metav1.FormatLabelSelector(labelSelector)
+	}
+	return options
+}
+
+// poll waits until the condition is met. With SkipWaits the condition is checked only once.
+func (f *KubeClient) poll(ctx context.Context, condition wait.ConditionFunc) error {
+	if f.SkipWaits {
+		_, err := condition()
+		return err
+	}
+	return wait.PollImmediateUntil(pollInterval, condition, ctx.Done())
+}
+
+// Namespace returns the namespace used for namespaced resources
+func (f *KubeClient) Namespace() string {
+	return f.namespace
+}
+
+// SetCommonMetadata sets labels and annotations added to every object created or applied by the client.
+func (f *KubeClient) SetCommonMetadata(labels, annotations map[string]string) {
+	f.labels = labels
+	f.annotations = annotations
+}
+
+// SetAuditor does nothing since the fake client does not send requests.
+func (f *KubeClient) SetAuditor(auditor client.AuditFunc) {}
+
+// SetRetries does nothing since the fake client never fails transiently.
+func (f *KubeClient) SetRetries(enabled bool) {}
+
+// StartCache does nothing since the fake client serves all requests from memory.
+func (f *KubeClient) StartCache(ctx context.Context, resync time.Duration) error {
+	return nil
+}
+
+// GetSecretsForServiceAccount returns secret by given service account name
+func (f *KubeClient) GetSecretsForServiceAccount(ctx context.Context, accountName string) (*corev1.Secret, error) {
+	serviceAccount, err := f.Clientset.CoreV1().ServiceAccounts(f.namespace).Get(ctx, accountName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(serviceAccount.Secrets) == 0 {
+		return nil, errors.Errorf("no secrets available for namespace %s", f.namespace)
+	}
+	return f.Clientset.CoreV1().Secrets(f.namespace).Get(ctx, serviceAccount.Secrets[0].Name, metav1.GetOptions{})
+}
+
+// GenerateKubeConfig returns ErrNotSupported.
+func (f *KubeClient) GenerateKubeConfig(secret *corev1.Secret) ([]byte, error) {
+	return nil, ErrNotSupported
+}
+
+// GenerateKubeConfigWithToken returns ErrNotSupported.
+func (f *KubeClient) GenerateKubeConfigWithToken(user string, secret *corev1.Secret) ([]byte, error) {
+	return nil, ErrNotSupported
+}
+
+// GenerateInClusterKubeConfig returns ErrNotSupported.
+func (f *KubeClient) GenerateInClusterKubeConfig() ([]byte, error) {
+	return nil, ErrNotSupported
+}
+
+// GetServerVersion returns the version set in the FakedServerVersion of the fake discovery of Clientset.
+func (f *KubeClient) GetServerVersion(ctx context.Context) (*version.Info, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.Clientset.Discovery().ServerVersion()
+}
+
+// InsecureTLS returns false.
+func (f *KubeClient) InsecureTLS() bool {
+	return false
+}
+
+// HasAPIGroup returns true if the group is among the Resources of Clientset.
+func (f *KubeClient) HasAPIGroup(ctx context.Context, name string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	groups, err := f.Clientset.Discovery().ServerGroups()
+	if err != nil {
+		return false, err
+	}
+	for _, group := range groups.Groups {
+		if group.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListDatabaseClusters returns list of managed PCX clusters.
+func (f *KubeClient) ListDatabaseClusters(ctx context.Context) (*dbaasv1.DatabaseClusterList, error) {
+	list := &dbaasv1.DatabaseClusterList{}
+	return list, f.list(ctx, databaseClustersResource, f.namespace, metav1.ListOptions{}, list)
+}
+
+// GetDatabaseCluster returns PXC clusters by provided name.
+func (f *KubeClient) GetDatabaseCluster(ctx context.Context, name string) (*dbaasv1.DatabaseCluster, error) {
+	cluster := &dbaasv1.DatabaseCluster{}
+	if err := f.get(ctx, databaseClustersResource, f.namespace, name, cluster); err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
+
+// PatchDatabaseCluster applies the patch of the given type to the database cluster.
+// Strategic merge patches are not supported by the fake dynamic client.
+func (f *KubeClient) PatchDatabaseCluster(ctx context.Context, name string, patchType types.PatchType, patch []byte) (*dbaasv1.DatabaseCluster, error) {
+	u, err := f.Dynamic.Resource(databaseClustersResource).Namespace(f.namespace).Patch(ctx, name, patchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return nil, err
+	}
+	cluster := &dbaasv1.DatabaseCluster{}
+	return cluster, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, cluster)
+}
+
+// ListDatabaseClusterBackups returns backups of database clusters.
+func (f *KubeClient) ListDatabaseClusterBackups(ctx context.Context) (*database.DatabaseClusterBackupList, error) {
+	list := &database.DatabaseClusterBackupList{}
+	return list, f.list(ctx, databaseClusterBackupsResource, f.namespace, metav1.ListOptions{}, list)
+}
+
+// GetDatabaseClusterBackup returns the database cluster backup by name.
+func (f *KubeClient) GetDatabaseClusterBackup(ctx context.Context, name string) (*database.DatabaseClusterBackup, error) {
+	backup := &database.DatabaseClusterBackup{}
+	if err := f.get(ctx, databaseClusterBackupsResource, f.namespace, name, backup); err != nil {
+		return nil, err
+	}
+	return backup, nil
+}
+
+// WatchDatabaseCluster watches changes of the database cluster.
+// The resource version is ignored since the fake client does not keep the history of objects.
+func (f *KubeClient) WatchDatabaseCluster(ctx context.Context, name, resourceVersion string) (watch.Interface, error) {
+	w, err := f.Dynamic.Resource(databaseClustersResource).Namespace(f.namespace).Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		u, ok := in.Object.(*unstructured.Unstructured)
+		if !ok || u.GetName() != name {
+			return in, false
+		}
+		cluster := &dbaasv1.DatabaseCluster{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, cluster); err != nil {
+			return in, false
+		}
+		in.Object = cluster
+		return in, true
+	}), nil
+}
+
+// GetStorageClasses returns all storage classes available in the cluster
+func (f *KubeClient) GetStorageClasses(ctx context.Context) (*storagev1.StorageClassList, error) {
+	return f.Clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+}
+
+// GetDeployment returns deployment by name
+func (f *KubeClient) GetDeployment(ctx context.Context, name string) (*appsv1.Deployment, error) {
+	return f.Clientset.AppsV1().Deployments(f.namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// GetSecret returns secret by name
+func (f *KubeClient) GetSecret(ctx context.Context, name string) (*corev1.Secret, error) {
+	return f.Clientset.CoreV1().Secrets(f.namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// CreateEvent creates the event in its namespace.
+func (f *KubeClient) CreateEvent(ctx context.Context, event *corev1.Event) error {
+	_, err := f.Clientset.CoreV1().Events(event.Namespace).Create(ctx, event, metav1.CreateOptions{})
+	return err
+}
+
+// GetConfigMap returns the config map by namespace and name
+func (f *KubeClient) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	return f.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// GetLease returns the lease by namespace and name.
+func (f *KubeClient) GetLease(ctx context.Context, namespace, name string) (*coordinationv1.Lease, error) {
+	return f.Clientset.CoordinationV1().Leases(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// CreateLease creates the lease in its namespace.
+func (f *KubeClient) CreateLease(ctx context.Context, lease *coordinationv1.Lease) (*coordinationv1.Lease, error) {
+	lease = lease.DeepCopy()
+	f.addCommonMetadata(lease)
+	lease.ResourceVersion = "1"
+	return f.Clientset.CoordinationV1().Leases(lease.Namespace).Create(ctx, lease, metav1.CreateOptions{})
+}
+
+// UpdateLease updates the lease in its namespace. The fake clientset does not maintain
+// resource versions, so they are bumped and compared here to report conflicts like the
+// API server.
+func (f *KubeClient) UpdateLease(ctx context.Context, lease *coordinationv1.Lease) (*coordinationv1.Lease, error) {
+	current, err := f.GetLease(ctx, lease.Namespace, lease.Name)
+	if err != nil {
+		return nil, err
+	}
+	if lease.ResourceVersion != "" && lease.ResourceVersion != current.ResourceVersion {
+		return nil, apierrors.NewConflict(coordinationv1.Resource("leases"), lease.Name, errors.New("the lease has been modified"))
+	}
+	version, _ := strconv.Atoi(current.ResourceVersion)
+	lease = lease.DeepCopy()
+	lease.ResourceVersion = strconv.Itoa(version + 1)
+	return f.Clientset.CoordinationV1().Leases(lease.Namespace).Update(ctx, lease, metav1.UpdateOptions{})
+}
+
+// DeleteLease deletes the lease unless it has been changed since it was read.
+func (f *KubeClient) DeleteLease(ctx context.Context, lease *coordinationv1.Lease) error {
+	current, err := f.GetLease(ctx, lease.Namespace, lease.Name)
+	if err != nil {
+		return err
+	}
+	if lease.ResourceVersion != "" && lease.ResourceVersion != current.ResourceVersion {
+		return apierrors.NewConflict(coordinationv1.Resource("leases"), lease.Name, errors.New("the lease has been modified"))
+	}
+	return f.Clientset.CoordinationV1().Leases(lease.Namespace).Delete(ctx, lease.Name, metav1.DeleteOptions{})
+}
+
+// GetService returns the service by namespace and name.
+func (f *KubeClient) GetService(ctx context.Context, namespace, name string) (*corev1.Service, error) {
+	return f.Clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// GetServiceAccount returns the service account by namespace and name.
+func (f *KubeClient) GetServiceAccount(ctx context.Context, namespace, name string) (*corev1.ServiceAccount, error) {
+	return f.Clientset.CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// UpdateServiceAccount updates the service account in its namespace.
+func (f *KubeClient) UpdateServiceAccount(ctx context.Context, account *corev1.ServiceAccount) (*corev1.ServiceAccount, error) {
+	return f.Clientset.CoreV1().ServiceAccounts(account.Namespace).Update(ctx, account, metav1.UpdateOptions{})
+}
+
+// DeletePod deletes the pod by namespace and name.
+func (f *KubeClient) DeletePod(ctx context.Context, namespace, name string) error {
+	return f.Clientset.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// ListSecrets returns secrets
+func (f *KubeClient) ListSecrets(ctx context.Context) (*corev1.SecretList, error) {
+	return f.Clientset.CoreV1().Secrets(f.namespace).List(ctx, metav1.ListOptions{})
+}
+
+// ApplyObject creates the object or replaces the existing one.
+func (f *KubeClient) ApplyObject(ctx context.Context, obj runtime.Object) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	tracker, gvr, obj, err := f.normalize(obj)
+	if err != nil {
+		return err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	f.addCommonMetadata(accessor)
+	if _, err := tracker.Get(gvr, accessor.GetNamespace(), accessor.GetName()); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return tracker.Create(gvr, obj, accessor.GetNamespace())
+	}
+	return tracker.Update(gvr, obj, accessor.GetNamespace())
+}
+
+// ServerSideApply creates the object or replaces the existing one. The tracker does
+// not merge fields of different managers.
+func (f *KubeClient) ServerSideApply(ctx context.Context, obj runtime.Object) error {
+	return f.ApplyObject(ctx, obj)
+}
+
+// ThreeWayMerge creates the object or replaces the existing one. The tracker does
+// not keep fields missing from the object.
+func (f *KubeClient) ThreeWayMerge(ctx context.Context, obj runtime.Object) error {
+	return f.ApplyObject(ctx, obj)
+}
+
+// ObjectExists returns true if the object exists.
+func (f *KubeClient) ObjectExists(ctx context.Context, obj runtime.Object) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	tracker, gvr, obj, err := f.normalize(obj)
+	if err != nil {
+		return false, err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false, err
+	}
+	_, err = tracker.Get(gvr, accessor.GetNamespace(), accessor.GetName())
+	switch {
+	case err == nil:
+		return true, nil
+	case apierrors.IsNotFound(err):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// DeleteObject deletes the object if it exists.
+func (f *KubeClient) DeleteObject(ctx context.Context, obj runtime.Object) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	tracker, gvr, obj, err := f.normalize(obj)
+	if err != nil {
+		return err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	err = tracker.Delete(gvr, accessor.GetNamespace(), accessor.GetName())
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// ApplyFile accepts manifest file contents, parses into []runtime.Object
+// and applies them against the cluster
+func (f *KubeClient) ApplyFile(ctx context.Context, fileBytes []byte) error {
+	objs, err := client.DecodeObjects(fileBytes)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if err := f.ApplyObject(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteFile accepts manifest file contents parses into []runtime.Object
+// and deletes them from the cluster
+func (f *KubeClient) DeleteFile(ctx context.Context, fileBytes []byte) error {
+	objs, err := client.DecodeObjects(fileBytes)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if err := f.DeleteObject(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPersistentVolumes returns Persistent Volumes available in the cluster
+func (f *KubeClient) GetPersistentVolumes(ctx context.Context) (*corev1.PersistentVolumeList, error) {
+	return f.Clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+}
+
+// GetPersistentVolumeClaims returns list of persistent volume claims
+func (f *KubeClient) GetPersistentVolumeClaims(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*corev1.PersistentVolumeClaimList, error) {
+	return f.Clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, listOptions(labelSelector))
+}
+
+// GetPods returns list of pods
+func (f *KubeClient) GetPods(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*corev1.PodList, error) {
+	return f.Clientset.CoreV1().Pods(namespace).List(ctx, listOptions(labelSelector))
+}
+
+// ForwardPort returns ErrNotSupported.
+func (f *KubeClient) ForwardPort(ctx context.Context, namespace, pod string, localPort, port int) (*client.PortForward, error) {
+	return nil, ErrNotSupported
+}
+
+// Exec returns ErrNotSupported.
+func (f *KubeClient) Exec(ctx context.Context, namespace, pod, container string, opts client.ExecOptions) error {
+	return ErrNotSupported
+}
+
+// GetNodes returns list of nodes
+func (f *KubeClient) GetNodes(ctx context.Context) (*corev1.NodeList, error) {
+	return f.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+}
+
+// GetOIDCIssuer returns OIDCIssuer.
+func (f *KubeClient) GetOIDCIssuer(ctx context.Context) (string, error) {
+	return f.OIDCIssuer, nil
+}
+
+// GetNodeStatsSummary returns the stats summary of the node from NodeStats.
+func (f *KubeClient) GetNodeStatsSummary(ctx context.Context, name string) ([]byte, error) {
+	stats, ok := f.NodeStats[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(corev1.Resource("nodes"), name)
+	}
+	return stats, nil
+}
+
+// GetNodeKubeletConfig returns the kubelet configuration of the node from KubeletConfigs.
+func (f *KubeClient) GetNodeKubeletConfig(ctx context.Context, name string) ([]byte, error) {
+	config, ok := f.KubeletConfigs[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(corev1.Resource("nodes"), name)
+	}
+	return config, nil
+}
+
+// CanI checks whether the current user is allowed to perform the verb on the resource.
+func (f *KubeClient) CanI(ctx context.Context, verb, group, resource, namespace string) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      verb,
+				Group:     group,
+				Resource:  resource,
+			},
+		},
+	}
+	resp, err := f.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return resp.Status.Allowed, nil
+}
+
+// GetLogs returns the logs of the container of the pod from Logs.
+func (f *KubeClient) GetLogs(ctx context.Context, pod, container string) (string, error) {
+	if _, err := f.Clientset.CoreV1().Pods(f.namespace).Get(ctx, pod, metav1.GetOptions{}); err != nil {
+		return "", err
+	}
+	return f.Logs[fmt.Sprintf("%s/%s", pod, container)], nil
+}
+
+// GetEvents returns the events of the object described the same way as the real client does.
+func (f *KubeClient) GetEvents(ctx context.Context, name string) (string, error) {
+	events, err := f.Clientset.CoreV1().Events(f.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	involved := &corev1.EventList{}
+	for _, event := range events.Items {
+		if event.InvolvedObject.Name == name {
+			involved.Items = append(involved.Items, event)
+		}
+	}
+	buf := &bytes.Buffer{}
+	out := tabwriter.NewWriter(buf, 0, 8, 2, ' ', 0)
+	w := client.NewPrefixWriter(out)
+	w.Writef(client.LEVEL_0, name+" ")
+	client.DescribeEvents(involved, w)
+	if err := out.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// DoCSVWait waits until for a CSV to be applied.
+func (f *KubeClient) DoCSVWait(ctx context.Context, key types.NamespacedName) error {
+	return f.poll(ctx, func() (bool, error) {
+		csv, err := f.GetClusterServiceVersion(ctx, key)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		switch csv.Status.Phase {
+		case v1alpha1.CSVPhaseFailed:
+			return false, fmt.Errorf("csv failed: reason: %q, message: %q", csv.Status.Reason, csv.Status.Message)
+		case v1alpha1.CSVPhaseSucceeded:
+			return true, nil
+		default:
+			return false, nil
+		}
+	})
+}
+
+// GetSubscriptionCSV retrieves a subscription CSV. With SkipWaits the starting CSV
+// of the subscription is returned if no CSV has been installed.
+func (f *KubeClient) GetSubscriptionCSV(ctx context.Context, subKey types.NamespacedName) (types.NamespacedName, error) {
+	var csvKey types.NamespacedName
+	err := f.poll(ctx, func() (bool, error) {
+		sub, err := f.GetSubscription(ctx, subKey.Namespace, subKey.Name)
+		if err != nil {
+			return false, err
+		}
+		csv := sub.Status.InstalledCSV
+		if csv == "" && f.SkipWaits && sub.Spec != nil {
+			csv = sub.Spec.StartingCSV
+		}
+		if csv == "" {
+			return false, nil
+		}
+		csvKey = types.NamespacedName{Namespace: subKey.Namespace, Name: csv}
+		return true, nil
+	})
+	return csvKey, err
+}
+
+// DoRolloutWait waits until a deployment has been rolled out susccessfully or there is an error.
+func (f *KubeClient) DoRolloutWait(ctx context.Context, key types.NamespacedName) error {
+	return f.poll(ctx, func() (bool, error) {
+		deployment, err := f.Clientset.AppsV1().Deployments(key.Namespace).Get(ctx, key.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		if deployment.Generation > deployment.Status.ObservedGeneration {
+			return false, nil
+		}
+		cond := deploymentutil.GetDeploymentCondition(deployment.Status, appsv1.DeploymentProgressing)
+		if cond != nil && cond.Reason == deploymentutil.TimedOutReason {
+			return false, errors.New("progress deadline exceeded")
+		}
+		if deployment.Spec.Replicas != nil && deployment.Status.UpdatedReplicas < *deployment.Spec.Replicas {
+			return false, nil
+		}
+		if deployment.Status.Replicas > deployment.Status.UpdatedReplicas {
+			return false, nil
+		}
+		return deployment.Status.AvailableReplicas >= deployment.Status.UpdatedReplicas, nil
+	})
+}
+
+// DoCRDWait waits until a CRD is established and ready to serve custom resources.
+func (f *KubeClient) DoCRDWait(ctx context.Context, name string) error {
+	return f.poll(ctx, func() (bool, error) {
+		crd := &apiextv1.CustomResourceDefinition{}
+		if err := f.get(ctx, crdsResource, "", name, crd); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		for _, cond := range crd.Status.Conditions {
+			switch cond.Type {
+			case apiextv1.Established:
+				if cond.Status == apiextv1.ConditionTrue {
+					return true, nil
+				}
+			case apiextv1.NamesAccepted:
+				if cond.Status == apiextv1.ConditionFalse {
+					return false, fmt.Errorf("names of crd %s are not accepted: %s", name, cond.Message)
+				}
+			}
+		}
+		return false, nil
+	})
+}
+
+// GetOperatorGroup retrieves an operator group details by namespace and name.
+func (f *KubeClient) GetOperatorGroup(ctx context.Context, namespace, name string) (*operatorsv1.OperatorGroup, error) {
+	if namespace == "" {
+		namespace = f.namespace
+	}
+	og := &operatorsv1.OperatorGroup{}
+	if err := f.get(ctx, operatorGroupsResource, namespace, name, og); err != nil {
+		return nil, err
+	}
+	return og, nil
+}
+
+// ListOperatorGroups lists all operator groups in the namespace.
+func (f *KubeClient) ListOperatorGroups(ctx context.Context, namespace string) (*operatorsv1.OperatorGroupList, error) {
+	if namespace == "" {
+		namespace = f.namespace
+	}
+	list := &operatorsv1.OperatorGroupList{}
+	return list, f.list(ctx, operatorGroupsResource, namespace, metav1.ListOptions{}, list)
+}
+
+// CreateOperatorGroup creates an operator group to be used as part of a subscription.
+func (f *KubeClient) CreateOperatorGroup(ctx context.Context, namespace, name string) (*operatorsv1.OperatorGroup, error) {
+	if namespace == "" {
+		namespace = f.namespace
+	}
+	og := &operatorsv1.OperatorGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: operatorsv1.OperatorGroupSpec{
+			TargetNamespaces: []string{namespace},
+		},
+	}
+	f.addCommonMetadata(og)
+	if err := f.write(ctx, operatorGroupsResource, og, false); err != nil {
+		return nil, err
+	}
+	return og, nil
+}
+
+// CreateSubscriptionForCatalog creates an OLM subscription.
+// The existing subscription is returned if it has already been created.
+func (f *KubeClient) CreateSubscriptionForCatalog(ctx context.Context, namespace, name, catalogNamespace, catalog,
+	packageName, channel, startingCSV string, approval v1alpha1.Approval,
+) (*v1alpha1.Subscription, error) {
+	sub := &v1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: &v1alpha1.SubscriptionSpec{
+			CatalogSource:          catalog,
+			CatalogSourceNamespace: catalogNamespace,
+			Package:                packageName,
+			Channel:                channel,
+			StartingCSV:            startingCSV,
+			InstallPlanApproval:    approval,
+		},
+	}
+	f.addCommonMetadata(sub)
+	if err := f.write(ctx, subscriptionsResource, sub, false); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return f.GetSubscription(ctx, namespace, name)
+		}
+		return nil, err
+	}
+	if f.ResolveSubscriptions {
+		return f.resolveSubscription(ctx, sub)
+	}
+	return sub, nil
+}
+
+// resolveSubscription creates the install plan of the starting CSV and references it
+// in the status of the subscription. Without a starting CSV, the CSV is named after
+// the package and the channel.
+func (f *KubeClient) resolveSubscription(ctx context.Context, sub *v1alpha1.Subscription) (*v1alpha1.Subscription, error) {
+	csv := sub.Spec.StartingCSV
+	if csv == "" {
+		csv = sub.Spec.Package + "." + sub.Spec.Channel
+	}
+	plan := &v1alpha1.InstallPlan{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: sub.Namespace,
+			Name:      "install-" + sub.Name,
+		},
+		Spec: v1alpha1.InstallPlanSpec{
+			CatalogSource:              sub.Spec.CatalogSource,
+			CatalogSourceNamespace:     sub.Spec.CatalogSourceNamespace,
+			ClusterServiceVersionNames: []string{csv},
+			Approval:                   sub.Spec.InstallPlanApproval,
+			Approved:                   sub.Spec.InstallPlanApproval == v1alpha1.ApprovalAutomatic,
+		},
+	}
+	if err := f.write(ctx, installPlansResource, plan, false); err != nil {
+		return nil, err
+	}
+	sub.Status.Install = &v1alpha1.InstallPlanReference{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       v1alpha1.InstallPlanKind,
+		Name:       plan.Name,
+		UID:        plan.UID,
+	}
+	sub.Status.CurrentCSV = csv
+	sub.Status.State = v1alpha1.SubscriptionStateUpgradePending
+	if err := f.write(ctx, subscriptionsResource, sub, true); err != nil {
+		return nil, err
+	}
+	if plan.Spec.Approved {
+		return sub, f.installPlan(ctx, plan)
+	}
+	return sub, nil
+}
+
+// installPlan installs the CSVs of the approved install plan and marks the subscriptions
+// referencing it as installed.
+func (f *KubeClient) installPlan(ctx context.Context, plan *v1alpha1.InstallPlan) error {
+	for _, name := range plan.Spec.ClusterServiceVersionNames {
+		csv := &v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: plan.Namespace,
+				Name:      name,
+			},
+			Status: v1alpha1.ClusterServiceVersionStatus{
+				Phase:  v1alpha1.CSVPhaseSucceeded,
+				Reason: v1alpha1.CSVReasonInstallSuccessful,
+			},
+		}
+		if err := f.write(ctx, csvsResource, csv, false); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	subs, err := f.ListSubscriptions(ctx, plan.Namespace)
+	if err != nil {
+		return err
+	}
+	for i := range subs.Items {
+		sub := &subs.Items[i]
+		if sub.Status.Install == nil || sub.Status.Install.Name != plan.Name {
+			continue
+		}
+		sub.Status.InstalledCSV = sub.Status.CurrentCSV
+		sub.Status.State = v1alpha1.SubscriptionStateAtLatest
+		if err := f.write(ctx, subscriptionsResource, sub, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSubscription retrieves an OLM subscription by namespace and name.
+func (f *KubeClient) GetSubscription(ctx context.Context, namespace, name string) (*v1alpha1.Subscription, error) {
+	sub := &v1alpha1.Subscription{}
+	if err := f.get(ctx, subscriptionsResource, namespace, name, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// UpdateSubscription updates the existing subscription in the specified namespace.
+func (f *KubeClient) UpdateSubscription(ctx context.Context, namespace string, subscription *v1alpha1.Subscription) (*v1alpha1.Subscription, error) {
+	sub := subscription.DeepCopy()
+	sub.Namespace = namespace
+	if err := f.write(ctx, subscriptionsResource, sub, true); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ListSubscriptions all the subscriptions in the namespace.
+func (f *KubeClient) ListSubscriptions(ctx context.Context, namespace string) (*v1alpha1.SubscriptionList, error) {
+	list := &v1alpha1.SubscriptionList{}
+	return list, f.list(ctx, subscriptionsResource, namespace, metav1.ListOptions{}, list)
+}
+
+// GetInstallPlan retrieves an OLM install plan by namespace and name.
+func (f *KubeClient) GetInstallPlan(ctx context.Context, namespace string, name string) (*v1alpha1.InstallPlan, error) {
+	plan := &v1alpha1.InstallPlan{}
+	if err := f.get(ctx, installPlansResource, namespace, name, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ListInstallPlans lists the OLM install plans in the namespace.
+func (f *KubeClient) ListInstallPlans(ctx context.Context, namespace string) (*v1alpha1.InstallPlanList, error) {
+	list := &v1alpha1.InstallPlanList{}
+	return list, f.list(ctx, installPlansResource, namespace, metav1.ListOptions{}, list)
+}
+
+// UpdateInstallPlan updates the existing install plan in the specified namespace.
+func (f *KubeClient) UpdateInstallPlan(ctx context.Context, namespace string, installPlan *v1alpha1.InstallPlan) (*v1alpha1.InstallPlan, error) {
+	plan := installPlan.DeepCopy()
+	plan.Namespace = namespace
+	if err := f.write(ctx, installPlansResource, plan, true); err != nil {
+		return nil, err
+	}
+	if f.ResolveSubscriptions && plan.Spec.Approved {
+		return plan, f.installPlan(ctx, plan)
+	}
+	return plan, nil
+}
+
+// GetCatalogSource retrieves an OLM catalog source by namespace and name.
+func (f *KubeClient) GetCatalogSource(ctx context.Context, namespace, name string) (*v1alpha1.CatalogSource, error) {
+	catalog := &v1alpha1.CatalogSource{}
+	if err := f.get(ctx, catalogSourcesResource, namespace, name, catalog); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// ListPackageManifests lists the package manifests added to the fake client. Unlike the
+// package server, packages of the global catalog namespace are not included.
+func (f *KubeClient) ListPackageManifests(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector) (*packagev1.PackageManifestList, error) {
+	list := &packagev1.PackageManifestList{}
+	return list, f.list(ctx, packageManifestsResource, namespace, listOptions(labelSelector), list)
+}
+
+// ListCRDs returns a list of CRDs.
+func (f *KubeClient) ListCRDs(ctx context.Context, labelSelector *metav1.LabelSelector) (*apiextv1.CustomResourceDefinitionList, error) {
+	list := &apiextv1.CustomResourceDefinitionList{}
+	return list, f.list(ctx, crdsResource, "", listOptions(labelSelector), list)
+}
+
+// ListCRs returns a list of CRs. Resources of kinds unknown to the fake client
+// can be listed only after objects of the kind have been added.
+func (f *KubeClient) ListCRs(
+	ctx context.Context,
+	namespace string,
+	gvr schema.GroupVersionResource,
+	labelSelector *metav1.LabelSelector,
+) (*unstructured.UnstructuredList, error) {
+	return f.Dynamic.Resource(gvr).Namespace(namespace).List(ctx, listOptions(labelSelector))
+}
+
+// UpdateCRStatus updates the status subresource of a CR.
+func (f *KubeClient) UpdateCRStatus(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	obj *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	return f.Dynamic.Resource(gvr).Namespace(obj.GetNamespace()).UpdateStatus(ctx, obj, metav1.UpdateOptions{})
+}
+
+// GetClusterServiceVersion retrieve a CSV by namespaced name.
+func (f *KubeClient) GetClusterServiceVersion(ctx context.Context, key types.NamespacedName) (*v1alpha1.ClusterServiceVersion, error) {
+	csv := &v1alpha1.ClusterServiceVersion{}
+	if err := f.get(ctx, csvsResource, key.Namespace, key.Name, csv); err != nil {
+		return nil, err
+	}
+	return csv, nil
+}
+
+// ListClusterServiceVersion list all CSVs for the given namespace.
+func (f *KubeClient) ListClusterServiceVersion(ctx context.Context, namespace string) (*v1alpha1.ClusterServiceVersionList, error) {
+	list := &v1alpha1.ClusterServiceVersionList{}
+	return list, f.list(ctx, csvsResource, namespace, metav1.ListOptions{}, list)
+}
+
+// ListVMAgents retrieves all VM agents for a namespace.
+func (f *KubeClient) ListVMAgents(ctx context.Context, namespace string, labels map[string]string) (*vmv1beta1.VMAgentList, error) {
+	opts := metav1.ListOptions{}
+	if labels != nil {
+		opts.LabelSelector = metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: labels})
+	}
+	list := &vmv1beta1.VMAgentList{}
+	return list, f.list(ctx, vmAgentsResource, namespace, opts, list)
+}
+
+// DeleteVMAgent deletes a Victoria Metrics agent instance.
+func (f *KubeClient) DeleteVMAgent(ctx context.Context, namespace, name string) error {
+	return f.Dynamic.Resource(vmAgentsResource).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
@@ -0,0 +1,184 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package fake_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/fake"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const manifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  key: value
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: databaseclusters.dbaas.percona.com
+  labels:
+    app: dbaas
+`
+
+func TestApplyFile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	f, err := fake.NewKubeClient("everest")
+	require.NoError(t, err)
+	f.SetCommonMetadata(map[string]string{"managed-by": "everest"}, nil)
+
+	require.NoError(t, f.ApplyFile(ctx, []byte(manifest)))
+	cm, err := f.GetConfigMap(ctx, "everest", "settings")
+	require.NoError(t, err)
+	assert.Equal(t, "value", cm.Data["key"])
+	assert.Equal(t, "everest", cm.Labels["managed-by"])
+
+	crds, err := f.ListCRDs(ctx, &metav1.LabelSelector{MatchLabels: map[string]string{"app": "dbaas"}})
+	require.NoError(t, err)
+	assert.Len(t, crds.Items, 1)
+
+	require.NoError(t, f.DeleteFile(ctx, []byte(manifest)))
+	_, err = f.GetConfigMap(ctx, "everest", "settings")
+	assert.Error(t, err)
+	// Deleting missing objects is not an error.
+	require.NoError(t, f.DeleteFile(ctx, []byte(manifest)))
+}
+
+func TestDatabaseClusters(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	f, err := fake.NewKubeClient("everest", &dbaasv1.DatabaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "db"},
+		Spec:       dbaasv1.DatabaseSpec{Database: "pxc"},
+	})
+	require.NoError(t, err)
+
+	k := kubernetes.NewWithClient(f)
+	require.NoError(t, k.PauseDatabaseCluster(ctx, "db"))
+	cluster, err := f.GetDatabaseCluster(ctx, "db")
+	require.NoError(t, err)
+	assert.True(t, cluster.Spec.Pause)
+	assert.Equal(t, "everest", cluster.Namespace)
+
+	cluster, err = f.PatchDatabaseCluster(ctx, "db", types.MergePatchType, []byte(`{"spec":{"pause":false}}`))
+	require.NoError(t, err)
+	assert.False(t, cluster.Spec.Pause)
+
+	clusters, err := f.ListDatabaseClusters(ctx)
+	require.NoError(t, err)
+	assert.Len(t, clusters.Items, 1)
+}
+
+func TestCanI(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	f, err := fake.NewKubeClient("everest")
+	require.NoError(t, err)
+
+	allowed, err := f.CanI(ctx, "create", "", "secrets", "everest")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	f.Clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &authorizationv1.SelfSubjectAccessReview{}, nil
+	})
+	allowed, err = f.CanI(ctx, "create", "", "secrets", "everest")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestWaits(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	f, err := fake.NewKubeClient("everest", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "operator"}})
+	require.NoError(t, err)
+	f.Logs["operator/manager"] = "started"
+
+	sub, err := f.CreateSubscriptionForCatalog(ctx, "everest", "pxc", "olm", "catalog", "pxc", "stable", "pxc.v1.12.0", v1alpha1.ApprovalManual)
+	require.NoError(t, err)
+	assert.Equal(t, "pxc.v1.12.0", sub.Spec.StartingCSV)
+
+	f.SkipWaits = true
+	csv, err := f.GetSubscriptionCSV(ctx, types.NamespacedName{Namespace: "everest", Name: "pxc"})
+	require.NoError(t, err)
+	assert.Equal(t, "pxc.v1.12.0", csv.Name)
+	require.NoError(t, f.DoCSVWait(ctx, csv))
+
+	f.SkipWaits = false
+	assert.Error(t, f.DoCSVWait(ctx, csv), "the CSV never succeeds without OLM")
+
+	logs, err := f.GetLogs(ctx, "operator", "manager")
+	require.NoError(t, err)
+	assert.Equal(t, "started", logs)
+}
+
+func TestInstallOperator(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	f, err := fake.NewKubeClient("everest")
+	require.NoError(t, err)
+	f.ResolveSubscriptions = true
+
+	k := kubernetes.NewWithClient(f)
+	_, err = k.InstallOperator(ctx, kubernetes.InstallOperatorRequest{
+		Namespace:              "everest",
+		Name:                   "percona-xtradb-cluster-operator",
+		CatalogSource:          "percona-dbaas-catalog",
+		CatalogSourceNamespace: "olm",
+		Channel:                "stable-v1",
+		InstallPlanApproval:    v1alpha1.ApprovalManual,
+		StartingCSV:            "percona-xtradb-cluster-operator.v1.12.0",
+	})
+	require.NoError(t, err)
+
+	sub, err := f.GetSubscription(ctx, "everest", "percona-xtradb-cluster-operator")
+	require.NoError(t, err)
+	assert.Equal(t, "percona-xtradb-cluster-operator.v1.12.0", sub.Status.InstalledCSV)
+	plan, err := f.GetInstallPlan(ctx, "everest", sub.Status.Install.Name)
+	require.NoError(t, err)
+	assert.True(t, plan.Spec.Approved)
+	csv, err := f.GetClusterServiceVersion(ctx, types.NamespacedName{Namespace: "everest", Name: sub.Status.InstalledCSV})
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.CSVPhaseSucceeded, csv.Status.Phase)
+
+	// The installed operator is not installed again.
+	_, err = k.InstallOperator(ctx, kubernetes.InstallOperatorRequest{
+		Namespace:              "everest",
+		Name:                   "percona-xtradb-cluster-operator",
+		CatalogSource:          "percona-dbaas-catalog",
+		CatalogSourceNamespace: "olm",
+		Channel:                "stable-v1",
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,241 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// StepNetworkPolicies is the provisioning step applying the network policies of the hardened profile.
+	StepNetworkPolicies = "network-policies"
+
+	// credentialsRotationAnnotationKey holds how often the PMM credentials of a VM agent are rotated.
+	credentialsRotationAnnotationKey = "dbaas.percona.com/credentials-rotation-period"
+	// pmmAPIKeyAnnotationKey names the PMM API key the credentials of a VM agent were created from.
+	pmmAPIKeyAnnotationKey = "dbaas.percona.com/pmm-api-key"
+
+	// webhookPort serves the admission and conversion webhooks of the operators.
+	webhookPort = 9443
+)
+
+// vmAgentContainers are the containers of VM agent pods created by the VictoriaMetrics operator.
+var vmAgentContainers = []string{"vmagent", "config-reloader"}
+
+// EnableHardening makes the applied monitoring components run with security contexts
+// compatible with the restricted pod security standard and read-only root filesystems.
+func (k *Kubernetes) EnableHardening() {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.hardened = true
+}
+
+// APIServerTLSVerified returns false if the certificate of the API server is not verified,
+// e.g. because the kubeconfig sets insecure-skip-tls-verify.
+func (k *Kubernetes) APIServerTLSVerified() bool {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	return !k.client.InsecureTLS()
+}
+
+// ApplyNetworkPolicies denies ingress to the pods of the namespace except from pods of
+// the same namespace and to the webhooks of the operators called by the API server.
+func (k *Kubernetes) ApplyNetworkPolicies(ctx context.Context, namespace string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	for _, policy := range NetworkPolicies(namespace) {
+		if err := k.client.ApplyObject(ctx, policy); err != nil {
+			return classifyError(errors.Wrapf(err, "cannot apply network policy %s", policy.Name))
+		}
+	}
+	return nil
+}
+
+// NetworkPolicies returns the network policies of the hardened profile for the namespace.
+func NetworkPolicies(namespace string) []*networkingv1.NetworkPolicy {
+	policy := func(name string, ingress []networkingv1.NetworkPolicyIngressRule) *networkingv1.NetworkPolicy {
+		return &networkingv1.NetworkPolicy{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: networkingv1.SchemeGroupVersion.String(),
+				Kind:       "NetworkPolicy",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{},
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+				Ingress:     ingress,
+			},
+		}
+	}
+	tcp := corev1.ProtocolTCP
+	port := intstr.FromInt(webhookPort)
+	return []*networkingv1.NetworkPolicy{
+		policy("everest-default-deny-ingress", nil),
+		policy("everest-allow-same-namespace", []networkingv1.NetworkPolicyIngressRule{
+			{From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}},
+		}),
+		policy("everest-allow-webhooks", []networkingv1.NetworkPolicyIngressRule{
+			{Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &port}}},
+		}),
+	}
+}
+
+// restrictWorkload sets security contexts compatible with the restricted pod security
+// standard on the pods of a workload. Root filesystems of the containers are made
+// read-only if requested.
+func restrictWorkload(obj *unstructured.Unstructured, readOnlyRoot bool) error {
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "DaemonSet", "Job":
+	default:
+		return nil
+	}
+	podSpec := []string{"spec", "template", "spec"}
+	if err := unstructured.SetNestedField(obj.Object, true, append(podSpec, "securityContext", "runAsNonRoot")...); err != nil {
+		return err
+	}
+	seccomp := append(podSpec, "securityContext", "seccompProfile", "type")
+	if err := unstructured.SetNestedField(obj.Object, string(corev1.SeccompProfileTypeRuntimeDefault), seccomp...); err != nil {
+		return err
+	}
+	for _, list := range []string{"containers", "initContainers"} {
+		containers, found, err := unstructured.NestedSlice(obj.Object, append(podSpec, list)...)
+		if err != nil || !found {
+			continue
+		}
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if err := restrictContainer(container, readOnlyRoot); err != nil {
+				return err
+			}
+		}
+		if err := unstructured.SetNestedSlice(obj.Object, containers, append(podSpec, list)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restrictContainer(container map[string]interface{}, readOnlyRoot bool) error {
+	if err := unstructured.SetNestedField(container, false, "securityContext", "allowPrivilegeEscalation"); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedStringSlice(container, []string{"ALL"}, "securityContext", "capabilities", "drop"); err != nil {
+		return err
+	}
+	if readOnlyRoot {
+		return unstructured.SetNestedField(container, true, "securityContext", "readOnlyRootFilesystem")
+	}
+	return nil
+}
+
+// restrictVMAgent runs the VM agent with a restricted security context and read-only root
+// filesystems. The containers are patched by name by the VictoriaMetrics operator. The agent
+// writes its configuration and the remote write queue to volumes mounted by the operator.
+func restrictVMAgent(spec *victoriametricsv1beta1.VMAgentSpec) {
+	runAsNonRoot, allowPrivilegeEscalation, readOnlyRoot := true, false, true
+	spec.SecurityContext = &corev1.PodSecurityContext{
+		RunAsNonRoot:   &runAsNonRoot,
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	for _, name := range vmAgentContainers {
+		spec.Containers = append(spec.Containers, corev1.Container{
+			Name: name,
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+				ReadOnlyRootFilesystem:   &readOnlyRoot,
+			},
+		})
+	}
+}
+
+// CredentialsRotation describes the PMM credentials a VM agent writes metrics with.
+type CredentialsRotation struct {
+	// Agent is the name of the VM agent.
+	Agent string
+	// APIKey names the PMM API key the credentials were created from.
+	APIKey string
+	// Period is how often the credentials are rotated. It is zero if they are not rotated.
+	Period time.Duration
+	// Due is the time after which the credentials are rotated.
+	Due time.Time
+}
+
+// CredentialsRotations returns the PMM credentials created from API keys by the
+// provisioner and when they are rotated.
+func (k *Kubernetes) CredentialsRotations(ctx context.Context) ([]CredentialsRotation, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	list, err := k.client.ListVMAgents(ctx, useDefaultNamespace, nil)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, classifyError(errors.Wrap(err, "cannot list VM agents"))
+	}
+	var rotations []CredentialsRotation
+	for _, agent := range list.Items {
+		apiKey := agent.Annotations[pmmAPIKeyAnnotationKey]
+		if !strings.HasPrefix(agent.Name, vmAgentNamePrefix) || apiKey == "" {
+			continue
+		}
+		rotation := CredentialsRotation{Agent: agent.Name, APIKey: apiKey}
+		if value, ok := agent.Annotations[credentialsRotationAnnotationKey]; ok {
+			period, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid credentials rotation period of VM agent %s", agent.Name)
+			}
+			rotation.Period = period
+			rotation.Due = agent.CreationTimestamp.Add(period)
+		}
+		rotations = append(rotations, rotation)
+	}
+	return rotations, nil
+}
+
+// annotateCredentials records the PMM API key the credentials of the VM agent were
+// created from and how often they are rotated. Provided keys are not recorded.
+func annotateCredentials(obj metav1.Object, creds MonitoringCredentials) {
+	if creds.Secret != "" || creds.Username == "" || creds.Provided {
+		return
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[pmmAPIKeyAnnotationKey] = creds.Username
+	if creds.RotationPeriod > 0 {
+		annotations[credentialsRotationAnnotationKey] = creds.RotationPeriod.String()
+	}
+	obj.SetAnnotations(annotations)
+}
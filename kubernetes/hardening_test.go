@@ -0,0 +1,112 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	vmv1beta1 "github.com/VictoriaMetrics/operator/api/victoriametrics/v1beta1"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRestrictWorkload(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "exporter"}},
+		}}},
+	}}
+	require.NoError(t, restrictWorkload(deployment, true))
+
+	runAsNonRoot, _, _ := unstructured.NestedBool(deployment.Object, "spec", "template", "spec", "securityContext", "runAsNonRoot")
+	assert.True(t, runAsNonRoot)
+	seccomp, _, _ := unstructured.NestedString(deployment.Object, "spec", "template", "spec", "securityContext", "seccompProfile", "type")
+	assert.Equal(t, "RuntimeDefault", seccomp)
+	containers, _, err := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	container := containers[0].(map[string]interface{})
+	escalation, found, _ := unstructured.NestedBool(container, "securityContext", "allowPrivilegeEscalation")
+	assert.True(t, found)
+	assert.False(t, escalation)
+	drop, _, _ := unstructured.NestedStringSlice(container, "securityContext", "capabilities", "drop")
+	assert.Equal(t, []string{"ALL"}, drop)
+	readOnly, _, _ := unstructured.NestedBool(container, "securityContext", "readOnlyRootFilesystem")
+	assert.True(t, readOnly)
+
+	service := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "Service"}}
+	require.NoError(t, restrictWorkload(service, true))
+	assert.Equal(t, map[string]interface{}{"kind": "Service"}, service.Object)
+}
+
+func TestCredentialsRotations(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	rotated := vmAgentSpec("vm-operator-1", "https://pmm.example.com", MonitoringTLS{})
+	rotated.CreationTimestamp = metav1.NewTime(created)
+	annotateCredentials(rotated, MonitoringCredentials{Username: "dbaas-service-account-1", RotationPeriod: 720 * time.Hour})
+	external := vmAgentSpec("vm-operator-2", "https://pmm.example.com", MonitoringTLS{})
+	annotateCredentials(external, MonitoringCredentials{Secret: "pmm-credentials"})
+	provided := vmAgentSpec("vm-operator-3", "https://pmm.example.com", MonitoringTLS{})
+	annotateCredentials(provided, MonitoringCredentials{Username: "api_key", Password: "glsa_token", Provided: true})
+
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k8sclient.On("ListVMAgents", ctx, useDefaultNamespace, map[string]string(nil)).Return(vmAgentList(t, rotated, external, provided), nil)
+
+	rotations, err := k.CredentialsRotations(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []CredentialsRotation{{
+		Agent:  rotated.Name,
+		APIKey: "dbaas-service-account-1",
+		Period: 720 * time.Hour,
+		// Timestamps are decoded in the local time zone.
+		Due: created.Add(720 * time.Hour).Local(),
+	}}, rotations)
+}
+
+// vmAgentList returns the agents in the list type returned by the client.
+func vmAgentList(t *testing.T, agents ...*victoriametricsv1beta1.VMAgent) *vmv1beta1.VMAgentList {
+	t.Helper()
+	list := &vmv1beta1.VMAgentList{}
+	for _, agent := range agents {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(agent)
+		require.NoError(t, err)
+		var item vmv1beta1.VMAgent
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(content, &item))
+		list.Items = append(list.Items, item)
+	}
+	return list
+}
+
+func TestNetworkPolicies(t *testing.T) {
+	policies := NetworkPolicies("default")
+	require.Len(t, policies, 3)
+	for _, policy := range policies {
+		assert.Equal(t, "default", policy.Namespace)
+		assert.Empty(t, policy.Spec.PodSelector.MatchLabels)
+	}
+	assert.Empty(t, policies[0].Spec.Ingress)
+}
@@ -0,0 +1,272 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/AlekSi/pointer"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// IngressController is the ingress controller the annotations of an Ingress are generated for.
+type IngressController string
+
+// Supported ingress controllers.
+const (
+	IngressControllerNginx   IngressController = "nginx"
+	IngressControllerTraefik IngressController = "traefik"
+	IngressControllerALB     IngressController = "alb"
+)
+
+const (
+	// WarningIngressBackendHTTPS is reported if the ingress controller cannot be told by an
+	// annotation of the Ingress that the service is served with HTTPS.
+	WarningIngressBackendHTTPS WarningCode = "ingress-backend-https"
+
+	nginxAnnotationPrefix   = "nginx.ingress.kubernetes.io/"
+	traefikAnnotationPrefix = "traefik.ingress.kubernetes.io/"
+	albAnnotationPrefix     = "alb.ingress.kubernetes.io/"
+)
+
+// ExposeOptions describes a service exposed at a host name by an Ingress, or a Route on OpenShift.
+type ExposeOptions struct {
+	// Name of the Ingress or the Route.
+	Name string
+	// Namespace of the service, the namespace of the client if empty.
+	Namespace string
+	Service   string
+	Port      int32
+	// BackendHTTPS is set if the service is served with HTTPS, e.g. PMM.
+	BackendHTTPS bool
+	Host         string
+	// TLSSecret holds the certificate and the key served for the host. A Route uses the default
+	// certificate of the router if it is empty.
+	TLSSecret string
+	// CertificateARN is the ACM certificate served by an AWS load balancer for the host.
+	CertificateARN string
+	// Class is the ingress class of the Ingress, the default class of the cluster if empty.
+	Class string
+	// Controller the annotations are generated for. It is detected if it is empty.
+	Controller IngressController
+}
+
+// TLS returns true if the host is served with HTTPS.
+func (o ExposeOptions) TLS() bool {
+	return o.TLSSecret != "" || o.CertificateARN != ""
+}
+
+// DetectIngressController returns the ingress controller of the ingress class, if its name
+// tells it, or the one usually used by the cluster type: the AWS load balancer controller on
+// EKS and ingress-nginx elsewhere.
+func (k *Kubernetes) DetectIngressController(ctx context.Context, class string) (IngressController, error) {
+	for _, controller := range []IngressController{IngressControllerNginx, IngressControllerTraefik, IngressControllerALB} {
+		if strings.Contains(class, string(controller)) {
+			return controller, nil
+		}
+	}
+	clusterType, err := k.GetClusterType(ctx)
+	if err != nil {
+		return "", err
+	}
+	if clusterType == ClusterTypeEKS {
+		return IngressControllerALB, nil
+	}
+	return IngressControllerNginx, nil
+}
+
+// Expose creates or updates the Ingress, or the Route on OpenShift, exposing the service.
+func (k *Kubernetes) Expose(ctx context.Context, opts ExposeOptions) (Warnings, error) {
+	obj, warnings, err := k.exposeObject(ctx, opts)
+	if err != nil {
+		return warnings, err
+	}
+	applyWarnings, err := k.applyStackObjects(ctx, []runtime.Object{obj})
+	warnings.Merge(applyWarnings)
+	return warnings, err
+}
+
+// ExposedURL returns the URL of the host exposed with the options.
+func (k *Kubernetes) ExposedURL(opts ExposeOptions) string {
+	if opts.TLS() || k.openShift {
+		return "https://" + opts.Host
+	}
+	return "http://" + opts.Host
+}
+
+// exposeObject returns the Route exposing the service on OpenShift and the Ingress elsewhere.
+func (k *Kubernetes) exposeObject(ctx context.Context, opts ExposeOptions) (runtime.Object, Warnings, error) {
+	if opts.Namespace == "" {
+		opts.Namespace = k.client.Namespace()
+	}
+	if k.openShift {
+		route, err := k.routeObject(ctx, opts)
+		return route, nil, err
+	}
+	if opts.Controller == "" {
+		controller, err := k.DetectIngressController(ctx, opts.Class)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts.Controller = controller
+	}
+	ingress, warnings := ingressObject(opts)
+	return ingress, warnings, nil
+}
+
+// ingressObject routes the host to the service, terminating TLS with the certificate of the
+// TLS secret if it is set.
+func ingressObject(opts ExposeOptions) (*networkingv1.Ingress, Warnings) {
+	annotations, warnings := ingressAnnotations(opts)
+	pathType := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{APIVersion: networkingv1.SchemeGroupVersion.String(), Kind: "Ingress"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        opts.Name,
+			Namespace:   opts.Namespace,
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{
+				Host: opts.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+							Name: opts.Service,
+							Port: networkingv1.ServiceBackendPort{Number: opts.Port},
+						}},
+					}},
+				}},
+			}},
+		},
+	}
+	class := opts.Class
+	if class == "" && opts.Controller == IngressControllerALB {
+		class = string(IngressControllerALB)
+	}
+	if class != "" {
+		ingress.Spec.IngressClassName = pointer.ToString(class)
+	}
+	if opts.TLSSecret != "" {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{opts.Host}, SecretName: opts.TLSSecret}}
+	}
+	return ingress, warnings
+}
+
+// ingressAnnotations returns the annotations making the ingress controller redirect HTTP to
+// HTTPS if TLS is enabled and connect to services served with HTTPS. The AWS load balancer
+// controller gets an internet-facing load balancer routing to the pods directly.
+func ingressAnnotations(opts ExposeOptions) (map[string]string, Warnings) {
+	var warnings Warnings
+	annotations := make(map[string]string)
+	switch opts.Controller {
+	case IngressControllerNginx:
+		if opts.TLS() {
+			annotations[nginxAnnotationPrefix+"ssl-redirect"] = "true"
+		}
+		if opts.BackendHTTPS {
+			annotations[nginxAnnotationPrefix+"backend-protocol"] = "HTTPS"
+		}
+	case IngressControllerTraefik:
+		if opts.TLS() {
+			annotations[traefikAnnotationPrefix+"router.entrypoints"] = "websecure"
+			annotations[traefikAnnotationPrefix+"router.tls"] = "true"
+		}
+		if opts.BackendHTTPS {
+			warnings.Add(WarningIngressBackendHTTPS,
+				"traefik connects to service %s with HTTP unless it is annotated with %sservice.serversscheme=https",
+				opts.Service, traefikAnnotationPrefix)
+		}
+	case IngressControllerALB:
+		annotations[albAnnotationPrefix+"scheme"] = "internet-facing"
+		annotations[albAnnotationPrefix+"target-type"] = "ip"
+		if opts.TLS() {
+			annotations[albAnnotationPrefix+"listen-ports"] = `[{"HTTP": 80}, {"HTTPS": 443}]`
+			annotations[albAnnotationPrefix+"ssl-redirect"] = "443"
+		}
+		if opts.CertificateARN != "" {
+			annotations[albAnnotationPrefix+"certificate-arn"] = opts.CertificateARN
+		}
+		if opts.BackendHTTPS {
+			annotations[albAnnotationPrefix+"backend-protocol"] = "HTTPS"
+		}
+	}
+	return annotations, warnings
+}
+
+// routeObject routes the host to the service on OpenShift. Routes cannot reference secrets,
+// so the certificate of the TLS secret is copied into the Route. Services served with HTTPS
+// are re-encrypted, others are terminated at the router. The Route targets the pod port the
+// port of the service forwards to, so the service must exist.
+func (k *Kubernetes) routeObject(ctx context.Context, opts ExposeOptions) (*unstructured.Unstructured, error) {
+	svc, err := k.client.GetService(ctx, opts.Namespace, opts.Service)
+	if err != nil {
+		return nil, classifyError(errors.Wrapf(err, "cannot get service %s", opts.Service))
+	}
+	var targetPort interface{}
+	for _, port := range svc.Spec.Ports {
+		if port.Port == opts.Port {
+			switch {
+			case port.TargetPort.Type == intstr.String:
+				targetPort = port.TargetPort.StrVal
+			case port.TargetPort.IntVal != 0:
+				targetPort = int64(port.TargetPort.IntVal)
+			default:
+				targetPort = int64(port.Port)
+			}
+		}
+	}
+	if targetPort == nil {
+		return nil, errors.Errorf("service %s has no port %d", opts.Service, opts.Port)
+	}
+
+	tls := map[string]interface{}{
+		"termination":                   "edge",
+		"insecureEdgeTerminationPolicy": "Redirect",
+	}
+	if opts.BackendHTTPS {
+		tls["termination"] = "reencrypt"
+	}
+	if opts.TLSSecret != "" {
+		secret, err := k.client.GetSecret(ctx, opts.TLSSecret)
+		if err != nil {
+			return nil, classifyError(errors.Wrapf(err, "cannot get TLS secret %s", opts.TLSSecret))
+		}
+		tls["certificate"] = string(secret.Data[corev1.TLSCertKey])
+		tls["key"] = string(secret.Data[corev1.TLSPrivateKeyKey])
+	}
+	route := &unstructured.Unstructured{}
+	route.SetAPIVersion("route.openshift.io/v1")
+	route.SetKind("Route")
+	route.SetName(opts.Name)
+	route.SetNamespace(opts.Namespace)
+	route.Object["spec"] = map[string]interface{}{
+		"host": opts.Host,
+		"to":   map[string]interface{}{"kind": "Service", "name": opts.Service},
+		"port": map[string]interface{}{"targetPort": targetPort},
+		"tls":  tls,
+	}
+	return route, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+func TestDetectIngressController(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k8sclient.On("HasAPIGroup", ctx, openShiftSecurityAPIGroup).Return(false, nil)
+	k8sclient.On("GetStorageClasses", ctx).Return(&storagev1.StorageClassList{
+		Items: []storagev1.StorageClass{{Provisioner: "ebs.csi.aws.com"}},
+	}, nil)
+
+	controller, err := k.DetectIngressController(ctx, "traefik-internal")
+	require.NoError(t, err)
+	assert.Equal(t, IngressControllerTraefik, controller)
+	k8sclient.AssertNotCalled(t, "GetStorageClasses", ctx)
+
+	controller, err = k.DetectIngressController(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, IngressControllerALB, controller)
+}
+
+func TestIngressObject(t *testing.T) {
+	t.Parallel()
+	opts := ExposeOptions{
+		Name:           "monitoring-service",
+		Namespace:      "pmm",
+		Service:        "monitoring-service",
+		Port:           443,
+		BackendHTTPS:   true,
+		Host:           "pmm.example.com",
+		CertificateARN: "arn:aws:acm:us-east-1:123456789012:certificate/pmm",
+		Controller:     IngressControllerALB,
+	}
+	ingress, warnings := ingressObject(opts)
+	assert.Empty(t, warnings)
+	require.NotNil(t, ingress.Spec.IngressClassName)
+	assert.Equal(t, "alb", *ingress.Spec.IngressClassName)
+	assert.Empty(t, ingress.Spec.TLS)
+	assert.Equal(t, "HTTPS", ingress.Annotations[albAnnotationPrefix+"backend-protocol"])
+	assert.Equal(t, opts.CertificateARN, ingress.Annotations[albAnnotationPrefix+"certificate-arn"])
+	assert.Equal(t, "443", ingress.Annotations[albAnnotationPrefix+"ssl-redirect"])
+	backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+	assert.Equal(t, "monitoring-service", backend.Name)
+	assert.Equal(t, int32(443), backend.Port.Number)
+
+	opts.Controller = IngressControllerNginx
+	opts.CertificateARN = ""
+	opts.TLSSecret = "pmm-tls"
+	ingress, _ = ingressObject(opts)
+	assert.Nil(t, ingress.Spec.IngressClassName)
+	require.Len(t, ingress.Spec.TLS, 1)
+	assert.Equal(t, "pmm-tls", ingress.Spec.TLS[0].SecretName)
+	assert.Equal(t, map[string]string{
+		nginxAnnotationPrefix + "ssl-redirect":     "true",
+		nginxAnnotationPrefix + "backend-protocol": "HTTPS",
+	}, ingress.Annotations)
+
+	opts.Controller = IngressControllerTraefik
+	ingress, warnings = ingressObject(opts)
+	assert.Equal(t, "true", ingress.Annotations[traefikAnnotationPrefix+"router.tls"])
+	require.Len(t, warnings, 1)
+	assert.Equal(t, WarningIngressBackendHTTPS, warnings[0].Code)
+}
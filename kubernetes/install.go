@@ -0,0 +1,64 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// InstallOperators installs the operators concurrently. Operator groups the
+// operators share are created once upfront so the installations do not race on them.
+// Warnings of all installations are returned even if one of them fails.
+func (k *Kubernetes) InstallOperators(ctx context.Context, reqs []InstallOperatorRequest) (Warnings, error) {
+	var (
+		mu       sync.Mutex
+		warnings Warnings
+	)
+	created := make(map[string]struct{})
+	for _, req := range reqs {
+		if _, ok := created[req.OperatorGroup]; ok {
+			continue
+		}
+		if err := createOperatorGroupIfNeeded(ctx, k.client, req.OperatorGroup); err != nil {
+			return warnings, errors.Wrapf(err, "cannot create operator group %q", req.OperatorGroup)
+		}
+		created[req.OperatorGroup] = struct{}{}
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+	for _, req := range reqs {
+		req := req
+		g.Go(func() error {
+			k.l.Infof("Installing %s operator", req.Name)
+			w, err := k.installOperator(gCtx, req)
+			mu.Lock()
+			warnings.Merge(w)
+			mu.Unlock()
+			if err != nil {
+				k.l.Errorf("failed installing %s operator", req.Name)
+				return errors.Wrapf(classifyError(err), "cannot install %s operator", req.Name)
+			}
+			k.l.Infof("%s operator has been installed", req.Name)
+			return nil
+		})
+	}
+	err := g.Wait()
+	return warnings, err
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestInstallOperators(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	var reqs []InstallOperatorRequest
+	for _, name := range []string{"percona-xtradb-cluster-operator", "percona-server-mongodb-operator"} {
+		reqs = append(reqs, InstallOperatorRequest{
+			Namespace:     "default",
+			Name:          name,
+			OperatorGroup: "percona-operators-group",
+			CatalogSource: "percona-dbaas-catalog",
+			Channel:       "stable-v1",
+		})
+		k8sclient.On("GetSubscription", mock.Anything, "default", name).Return(&v1alpha1.Subscription{
+			Spec:   &v1alpha1.SubscriptionSpec{CatalogSource: "percona-dbaas-catalog", Channel: "stable-v1"},
+			Status: v1alpha1.SubscriptionStatus{InstalledCSV: name + ".v1.0.0"},
+		}, nil)
+		k8sclient.On("GetClusterServiceVersion", mock.Anything, types.NamespacedName{Namespace: "default", Name: name + ".v1.0.0"}).
+			Return(&v1alpha1.ClusterServiceVersion{Status: v1alpha1.ClusterServiceVersionStatus{Phase: v1alpha1.CSVPhaseSucceeded}}, nil)
+	}
+	k8sclient.On("GetOperatorGroup", ctx, useDefaultNamespace, "percona-operators-group").Return(&v1.OperatorGroup{}, nil)
+
+	_, err := k.InstallOperators(ctx, reqs)
+	require.NoError(t, err)
+	k8sclient.AssertExpectations(t)
+	k8sclient.AssertNumberOfCalls(t, "GetOperatorGroup", 1)
+}
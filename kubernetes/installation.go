@@ -0,0 +1,188 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/data"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const everestInstallationCRDFile = "crds/everest/everestinstallation.yaml"
+
+// Phases of an EverestInstallation.
+const (
+	InstallationPhaseReconciling = "Reconciling"
+	InstallationPhaseReady       = "Ready"
+	InstallationPhaseFailed      = "Failed"
+)
+
+// EverestInstallationGVR identifies EverestInstallation custom resources.
+var EverestInstallationGVR = schema.GroupVersionResource{
+	Group:    "everest.percona.com",
+	Version:  "v1alpha1",
+	Resource: "everestinstallations",
+}
+
+// EverestInstallation describes the desired installation the controller reconciles the cluster to.
+type EverestInstallation struct {
+	Name       string
+	Namespace  string
+	Generation int64
+	Spec       EverestInstallationSpec
+	Status     EverestInstallationStatus
+	// object is the custom resource the installation was read from.
+	object *unstructured.Unstructured
+}
+
+// EverestInstallationSpec holds the desired settings of the installation.
+type EverestInstallationSpec struct {
+	Catalog          InstallationCatalog    `json:"catalog,omitempty"`
+	OperatorChannels map[string]string      `json:"operatorChannels,omitempty"`
+	Monitoring       InstallationMonitoring `json:"monitoring,omitempty"`
+	Backup           InstallationBackup     `json:"backup,omitempty"`
+}
+
+// InstallationCatalog points the installation at a catalog source. Empty fields keep the Percona catalog.
+type InstallationCatalog struct {
+	Name      string `json:"name,omitempty"`
+	Image     string `json:"image,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// InstallationMonitoring configures the PMM server the metrics are written to.
+type InstallationMonitoring struct {
+	Enabled   bool            `json:"enabled,omitempty"`
+	Selective bool            `json:"selective,omitempty"`
+	PMM       InstallationPMM `json:"pmm,omitempty"`
+}
+
+// InstallationPMM holds the endpoint of the PMM server and the secret with the credentials
+// the metrics are written with. The controller never creates API keys in PMM.
+type InstallationPMM struct {
+	Endpoint           string `json:"endpoint,omitempty"`
+	CredentialsSecret  string `json:"credentialsSecret,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// InstallationBackup configures backups of the database clusters.
+type InstallationBackup struct {
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// EverestInstallationStatus reports the result of the last reconciliation.
+type EverestInstallationStatus struct {
+	Phase              string      `json:"phase,omitempty"`
+	Message            string      `json:"message,omitempty"`
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	LastReconcileTime  metav1.Time `json:"lastReconcileTime,omitempty"`
+}
+
+// InstallEverestInstallationCRD applies the EverestInstallation CRD and waits for it to be established.
+func (k *Kubernetes) InstallEverestInstallationCRD(ctx context.Context) error {
+	file, err := data.OLMCRDs.ReadFile(everestInstallationCRDFile)
+	if err != nil {
+		return errors.Wrap(err, "cannot read EverestInstallation CRD")
+	}
+	if err := k.client.ApplyFile(ctx, file); err != nil {
+		return classifyError(errors.Wrap(err, "cannot apply EverestInstallation CRD"))
+	}
+	resources, err := decodeResources(file)
+	if err != nil {
+		return errors.Wrap(err, "cannot decode EverestInstallation CRD")
+	}
+	return classifyError(k.waitForCRDsEstablished(ctx, resources))
+}
+
+// ListEverestInstallations returns the installations in the namespace sorted by creation time.
+func (k *Kubernetes) ListEverestInstallations(ctx context.Context, namespace string) ([]EverestInstallation, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	list, err := k.client.ListCRs(ctx, namespace, EverestInstallationGVR, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, "cannot list EverestInstallations"))
+	}
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		return list.Items[i].GetCreationTimestamp().Time.Before(list.Items[j].GetCreationTimestamp().Time)
+	})
+	installations := make([]EverestInstallation, 0, len(list.Items))
+	for i := range list.Items {
+		inst, err := everestInstallationFromUnstructured(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		installations = append(installations, inst)
+	}
+	return installations, nil
+}
+
+// UpdateEverestInstallationStatus records the phase of the installation observed at its current generation.
+func (k *Kubernetes) UpdateEverestInstallationStatus(ctx context.Context, inst EverestInstallation, phase, message string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if inst.object == nil {
+		return errors.Errorf("EverestInstallation %s was not read from the cluster", inst.Name)
+	}
+	status := EverestInstallationStatus{
+		Phase:              phase,
+		Message:            message,
+		ObservedGeneration: inst.Generation,
+		LastReconcileTime:  metav1.NewTime(time.Now()),
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return errors.Wrap(err, "cannot convert the EverestInstallation status")
+	}
+	obj := inst.object.DeepCopy()
+	if err := unstructured.SetNestedMap(obj.Object, content, "status"); err != nil {
+		return errors.Wrap(err, "cannot set the EverestInstallation status")
+	}
+	updated, err := k.client.UpdateCRStatus(ctx, EverestInstallationGVR, obj)
+	if err != nil {
+		return classifyError(errors.Wrapf(err, "cannot update status of EverestInstallation %s", inst.Name))
+	}
+	// Copies of the installation share the object so following updates use the new resource version.
+	*inst.object = *updated
+	return nil
+}
+
+func everestInstallationFromUnstructured(obj *unstructured.Unstructured) (EverestInstallation, error) {
+	inst := EverestInstallation{
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+		Generation: obj.GetGeneration(),
+		object:     obj,
+	}
+	if spec, ok := obj.Object["spec"].(map[string]interface{}); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, &inst.Spec); err != nil {
+			return inst, errors.Wrapf(err, "invalid spec of EverestInstallation %s", inst.Name)
+		}
+	}
+	if status, ok := obj.Object["status"].(map[string]interface{}); ok {
+		// The status is informational, an unreadable one is overwritten on the next update.
+		_ = runtime.DefaultUnstructuredConverter.FromUnstructured(status, &inst.Status)
+	}
+	return inst, nil
+}
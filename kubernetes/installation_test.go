@@ -0,0 +1,81 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestEverestInstallations(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	installation := func(name string, created time.Time) unstructured.Unstructured {
+		u := unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "everest.percona.com/v1alpha1",
+			"kind":       "EverestInstallation",
+			"spec": map[string]interface{}{
+				"operatorChannels": map[string]interface{}{"dbaas-operator": "stable-v0"},
+				"monitoring": map[string]interface{}{
+					"enabled": true,
+					"pmm":     map[string]interface{}{"endpoint": "https://pmm.example.com", "credentialsSecret": "pmm"},
+				},
+			},
+		}}
+		u.SetName(name)
+		u.SetNamespace("default")
+		u.SetGeneration(2)
+		u.SetCreationTimestamp(metav1.NewTime(created))
+		return u
+	}
+	now := time.Now()
+	k8sclient.On("ListCRs", ctx, "default", EverestInstallationGVR, (*metav1.LabelSelector)(nil)).Return(&unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{installation("newer", now), installation("older", now.Add(-time.Hour))},
+	}, nil)
+
+	installations, err := k.ListEverestInstallations(ctx, "default")
+	require.NoError(t, err)
+	require.Len(t, installations, 2)
+	inst := installations[0]
+	assert.Equal(t, "older", inst.Name)
+	assert.Equal(t, "stable-v0", inst.Spec.OperatorChannels["dbaas-operator"])
+	assert.True(t, inst.Spec.Monitoring.Enabled)
+	assert.Equal(t, "pmm", inst.Spec.Monitoring.PMM.CredentialsSecret)
+
+	var updated *unstructured.Unstructured
+	k8sclient.On("UpdateCRStatus", ctx, EverestInstallationGVR, mock.Anything).Return(&unstructured.Unstructured{Object: map[string]interface{}{}}, nil).
+		Run(func(args mock.Arguments) {
+			updated = args.Get(2).(*unstructured.Unstructured)
+		})
+	require.NoError(t, k.UpdateEverestInstallationStatus(ctx, inst, InstallationPhaseReady, ""))
+	require.NotNil(t, updated)
+	phase, _, _ := unstructured.NestedString(updated.Object, "status", "phase")
+	assert.Equal(t, InstallationPhaseReady, phase)
+	generation, _, _ := unstructured.NestedInt64(updated.Object, "status", "observedGeneration")
+	assert.Equal(t, int64(2), generation)
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"path"
+	"sort"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// ProvisionerJobConfigDir is the directory the files of a provisioner Job are mounted to.
+	ProvisionerJobConfigDir = "/etc/everest"
+	// ProvisionerJobConfigFile is the name of the configuration file among the files of the Job.
+	ProvisionerJobConfigFile = "config.yaml"
+
+	provisionerJobBackoffLimit = 2
+	// clusterAdminRole is bound to the service account of the Job because the
+	// provisioner installs CRDs, OLM and cluster roles of the operators.
+	clusterAdminRole = "cluster-admin"
+)
+
+// ProvisionerJob describes a Job running the provisioner inside the cluster.
+type ProvisionerJob struct {
+	Name      string
+	Namespace string
+	Image     string
+	// Args are passed to the provisioner, e.g. install.
+	Args []string
+	// Files are stored in a secret mounted to ProvisionerJobConfigDir.
+	// The configuration is expected in ProvisionerJobConfigFile.
+	Files map[string][]byte
+}
+
+// ProvisionerJobManifest renders the namespace, the service account, its RBAC,
+// the secret with the files and the Job as a multi-document YAML manifest
+// which can be applied with kubectl.
+func ProvisionerJobManifest(job ProvisionerJob) ([]byte, error) {
+	if job.Name == "" || job.Namespace == "" || job.Image == "" {
+		return nil, errors.New("name, namespace and image of the job are required")
+	}
+	if _, ok := job.Files[ProvisionerJobConfigFile]; !ok {
+		return nil, errors.Errorf("%s is missing from the files of the job", ProvisionerJobConfigFile)
+	}
+	typed := provisionerJobObjects(job)
+	objs := make([]interface{}, 0, len(typed))
+	for _, obj := range typed {
+		objs = append(objs, obj)
+	}
+	manifest, err := MarshalManifests(objs)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot render the job")
+	}
+	return manifest, nil
+}
+
+func provisionerJobObjects(job ProvisionerJob) []runtime.Object {
+	labels := map[string]string{"app.kubernetes.io/name": job.Name}
+	meta := metav1.ObjectMeta{Name: job.Name, Namespace: job.Namespace, Labels: labels}
+
+	files := make([]string, 0, len(job.Files))
+	for name := range job.Files {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	items := make([]corev1.KeyToPath, 0, len(files))
+	for _, name := range files {
+		items = append(items, corev1.KeyToPath{Key: name, Path: name})
+	}
+
+	backoffLimit := int32(provisionerJobBackoffLimit)
+	return []runtime.Object{
+		&corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: job.Namespace},
+		},
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: meta,
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: job.Name, Labels: labels},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     clusterAdminRole,
+			},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: job.Name, Namespace: job.Namespace},
+			},
+		},
+		&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: meta,
+			Type:       corev1.SecretTypeOpaque,
+			Data:       job.Files,
+		},
+		&batchv1.Job{
+			TypeMeta:   metav1.TypeMeta{APIVersion: batchv1.SchemeGroupVersion.String(), Kind: "Job"},
+			ObjectMeta: meta,
+			Spec: batchv1.JobSpec{
+				BackoffLimit: &backoffLimit,
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						ServiceAccountName: job.Name,
+						RestartPolicy:      corev1.RestartPolicyNever,
+						Containers: []corev1.Container{
+							{
+								Name:  "provisioner",
+								Image: job.Image,
+								Args:  job.Args,
+								Env: []corev1.EnvVar{
+									{Name: "EVEREST_CONFIG", Value: path.Join(ProvisionerJobConfigDir, ProvisionerJobConfigFile)},
+								},
+								VolumeMounts: []corev1.VolumeMount{
+									{Name: "config", MountPath: ProvisionerJobConfigDir, ReadOnly: true},
+								},
+							},
+						},
+						Volumes: []corev1.Volume{
+							{
+								Name: "config",
+								VolumeSource: corev1.VolumeSource{
+									Secret: &corev1.SecretVolumeSource{SecretName: job.Name, Items: items},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
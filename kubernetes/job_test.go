@@ -0,0 +1,49 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisionerJobManifest(t *testing.T) {
+	t.Parallel()
+
+	_, err := ProvisionerJobManifest(ProvisionerJob{Name: "everest", Namespace: "default", Image: "provisioner"})
+	assert.Error(t, err)
+
+	manifest, err := ProvisionerJobManifest(ProvisionerJob{
+		Name:      "everest",
+		Namespace: "everest-system",
+		Image:     "provisioner:v1",
+		Args:      []string{"install"},
+		Files:     map[string][]byte{ProvisionerJobConfigFile: []byte("in_cluster: true\n")},
+	})
+	require.NoError(t, err)
+	docs := strings.Split(string(manifest), "---\n")
+	require.Len(t, docs, 5)
+	for i, kind := range []string{"Namespace", "ServiceAccount", "ClusterRoleBinding", "Secret", "Job"} {
+		assert.Contains(t, docs[i], "kind: "+kind)
+	}
+	assert.Contains(t, docs[2], "name: "+clusterAdminRole)
+	assert.Contains(t, docs[4], "image: provisioner:v1")
+	assert.Contains(t, docs[4], "serviceAccountName: everest")
+	assert.Contains(t, docs[4], "value: /etc/everest/config.yaml")
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// KubeStateMetricsUpgrade describes a change of the image of the deployed kube-state-metrics.
+type KubeStateMetricsUpgrade struct {
+	From string
+	To   string
+}
+
+// SetKubeStateMetricsImage overrides the image of kube-state-metrics. The image replaces
+// the default one, keeping the default tag if it has none, and the tag replaces its tag.
+// The defaults are kept if both are empty.
+func (k *Kubernetes) SetKubeStateMetricsImage(image, tag string) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.kubeStateMetricsImage = overrideImage(kubeStateMetricsImage, image, tag)
+}
+
+// desiredKubeStateMetricsImage returns the image kube-state-metrics is deployed with.
+func (k *Kubernetes) desiredKubeStateMetricsImage() string {
+	if k.kubeStateMetricsImage == "" {
+		return kubeStateMetricsImage
+	}
+	return k.kubeStateMetricsImage
+}
+
+// overrideImage returns the default image with the image and the tag overrides applied.
+func overrideImage(defaultImage, image, tag string) string {
+	_, defaultTag, _ := splitImageTag(defaultImage)
+	res := defaultImage
+	if image != "" {
+		res = image
+		if _, _, ok := splitImageTag(image); !ok && !strings.Contains(image, "@") {
+			res = image + ":" + defaultTag
+		}
+	}
+	if tag != "" {
+		if repo, _, ok := splitImageTag(res); ok {
+			res = repo + ":" + tag
+		} else {
+			res = strings.SplitN(res, "@", 2)[0] + ":" + tag
+		}
+	}
+	return res
+}
+
+// setKubeStateMetricsImage sets the image of the kube-state-metrics container and the
+// version labels of its deployment to the tag of the image.
+func setKubeStateMetricsImage(obj *unstructured.Unstructured, image string) error {
+	if obj.GetKind() != "Deployment" || obj.GetName() != kubeStateMetricsName || image == "" {
+		return nil
+	}
+	path := []string{"spec", "template", "spec", "containers"}
+	containers, _, err := unstructured.NestedSlice(obj.Object, path...)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		if container, ok := c.(map[string]interface{}); ok && container["name"] == kubeStateMetricsName {
+			container["image"] = image
+		}
+	}
+	if err := unstructured.SetNestedSlice(obj.Object, containers, path...); err != nil {
+		return err
+	}
+	_, tag, ok := splitImageTag(image)
+	if !ok {
+		return nil
+	}
+	version := strings.TrimPrefix(tag, "v")
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels["app.kubernetes.io/version"] = version
+	obj.SetLabels(labels)
+	return unstructured.SetNestedField(obj.Object, version, "spec", "template", "metadata", "labels", "app.kubernetes.io/version")
+}
+
+// KubeStateMetricsUpgrade returns the change of the image of the deployed kube-state-metrics
+// to the desired one. It returns nil if kube-state-metrics is not deployed or up to date.
+func (k *Kubernetes) KubeStateMetricsUpgrade(ctx context.Context) (*KubeStateMetricsUpgrade, error) {
+	deployment, err := k.client.GetDeployment(ctx, kubeStateMetricsName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, classifyError(errors.Wrapf(err, "cannot get %s deployment", kubeStateMetricsName))
+	}
+	desired := k.desiredKubeStateMetricsImage()
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name == kubeStateMetricsName && container.Image != desired {
+			return &KubeStateMetricsUpgrade{From: container.Image, To: desired}, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpgradeKubeStateMetrics re-applies the monitoring stack so kube-state-metrics is deployed
+// with the desired image and resources.
+func (k *Kubernetes) UpgradeKubeStateMetrics(ctx context.Context) (Warnings, error) {
+	return k.applyMonitoringStack(ctx)
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestOverrideImage(t *testing.T) {
+	for _, tc := range []struct {
+		image, tag, expected string
+	}{
+		{"", "", kubeStateMetricsImage},
+		{"", "v2.8.2", "k8s.gcr.io/kube-state-metrics/kube-state-metrics:v2.8.2"},
+		{"registry.example.com/kube-state-metrics", "", "registry.example.com/kube-state-metrics:v2.5.0"},
+		{"registry.example.com:5000/kube-state-metrics:v2.6.0", "", "registry.example.com:5000/kube-state-metrics:v2.6.0"},
+		{"registry.example.com:5000/kube-state-metrics:v2.6.0", "v2.7.0", "registry.example.com:5000/kube-state-metrics:v2.7.0"},
+	} {
+		assert.Equal(t, tc.expected, overrideImage(kubeStateMetricsImage, tc.image, tc.tag), tc.image+" "+tc.tag)
+	}
+}
+
+func TestUpgradeKubeStateMetrics(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k.SetKubeStateMetricsImage("", "v2.8.2")
+
+	var deployment *appsv1.Deployment
+	for _, obj := range monitoringStack("everest") {
+		if d, ok := obj.(*appsv1.Deployment); ok {
+			deployment = d
+		}
+	}
+	require.NotNil(t, deployment)
+	k8sclient.On("GetDeployment", ctx, kubeStateMetricsName).Return(deployment, nil)
+
+	upgrade, err := k.KubeStateMetricsUpgrade(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &KubeStateMetricsUpgrade{
+		From: kubeStateMetricsImage,
+		To:   "k8s.gcr.io/kube-state-metrics/kube-state-metrics:v2.8.2",
+	}, upgrade)
+
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployment)
+	require.NoError(t, err)
+	obj := &unstructured.Unstructured{Object: data}
+	require.NoError(t, k.adjustObject(obj))
+	var adjusted appsv1.Deployment
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &adjusted))
+	assert.Equal(t, upgrade.To, adjusted.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, "2.8.2", adjusted.Labels["app.kubernetes.io/version"])
+	assert.Equal(t, "2.8.2", adjusted.Spec.Template.Labels["app.kubernetes.io/version"])
+	assert.Equal(t, map[string]string{corev1.LabelOSStable: "linux"}, adjusted.Spec.Template.Spec.NodeSelector)
+}
@@ -24,8 +24,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
-	"log"
-	"net/http"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -34,6 +33,7 @@ import (
 	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
 	"github.com/gen1us2k/everest-provisioner/data"
 	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client/database"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 
 	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
@@ -41,7 +41,7 @@ import (
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -59,6 +59,9 @@ const (
 	ClusterTypeMinikube        ClusterType = "minikube"
 	ClusterTypeEKS             ClusterType = "eks"
 	ClusterTypeGeneric         ClusterType = "generic"
+	ClusterTypeOpenShift       ClusterType = "openshift"
+	ClusterTypeGKE             ClusterType = "gke"
+	ClusterTypeAKS             ClusterType = "aks"
 	pxcDeploymentName                      = "percona-xtradb-cluster-operator"
 	psmdbDeploymentName                    = "percona-server-mongodb-operator"
 	dbaasDeploymentName                    = "dbaas-operator-controller-manager"
@@ -69,6 +72,7 @@ const (
 	databaseClusterAPIVersion              = "dbaas.percona.com/v1"
 	restartAnnotationKey                   = "dbaas.percona.com/restart"
 	managedByKey                           = "dbaas.percona.com/managed-by"
+	managedByLabelKey                      = "app.kubernetes.io/managed-by"
 	templateLabelKey                       = "dbaas.percona.com/template"
 	engineLabelKey                         = "dbaas.percona.com/engine"
 
@@ -79,6 +83,11 @@ const (
 	// then either ran to completion or failed for some reason.
 	ContainerStateTerminated ContainerState = "terminated"
 
+	// Max size of volume for Google Compute Engine persistent disks is 64TiB.
+	maxVolumeSizeGCEPD uint64 = 64 * 1024 * 1024 * 1024 * 1024
+	// Max size of volume for Azure managed disks is 32767GiB.
+	maxVolumeSizeAzureDisk uint64 = 32767 * 1024 * 1024 * 1024
+
 	// Max size of volume for AWS Elastic Block Storage service is 16TiB.
 	maxVolumeSizeEBS    uint64 = 16 * 1024 * 1024 * 1024 * 1024
 	olmNamespace               = "olm"
@@ -89,6 +98,11 @@ const (
 
 	pollInterval = 1 * time.Second
 	pollDuration = 5 * time.Minute
+
+	crdKind             = "CustomResourceDefinition"
+	crdEstablishTimeout = 2 * time.Minute
+
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token" //nolint:gosec
 )
 
 // ErrEmptyVersionTag Got an empty version tag from GitHub API.
@@ -99,8 +113,31 @@ type Kubernetes struct {
 	lock       *sync.RWMutex
 	client     client.KubeClientConnector
 	l          *logrus.Entry
-	httpClient *http.Client
 	kubeconfig string
+	// kubeconfigData is set if the client was created from a kubeconfig string.
+	kubeconfigData []byte
+	inCluster      bool
+	openShift      bool
+	// hardened restricts the security contexts of the applied monitoring components.
+	hardened bool
+	// force re-applies components which are installed already.
+	force bool
+	// imagePullSecrets are referenced by the installed workloads.
+	imagePullSecrets []string
+	// monitoringResources overrides the resources of the VM agents and kube-state-metrics.
+	monitoringResources MonitoringResources
+	// scrape customizes the scraping of the VM agents writing to PMM.
+	scrape ScrapeOptions
+	// kubeStateMetricsImage overrides the image of kube-state-metrics.
+	kubeStateMetricsImage string
+	// scheduling constrains the nodes of the VM agents, kube-state-metrics and the operators.
+	scheduling Scheduling
+	// secrets keeps the PMM credentials outside of the cluster if it is set.
+	secrets SecretsBackend
+	// clusterDomain is the DNS domain of the cluster, detected if it is not set.
+	clusterDomain string
+	// recorder records the created objects if TrackCreatedObjects has been called.
+	recorder *creationRecorder
 }
 
 // ContainerState describes container's state - waiting, running, terminated.
@@ -125,44 +162,143 @@ type NodeFileSystemSummary struct {
 
 // New returns new Kubernetes object.
 func New(kubeconfig string) (*Kubernetes, error) {
+	return NewFromContext(kubeconfig, "")
+}
+
+// NewFromContext returns new Kubernetes object for the named context of the kubeconfig.
+// The current context of the kubeconfig is used if contextName is empty.
+func NewFromContext(kubeconfig, contextName string) (*Kubernetes, error) {
 	l := logrus.WithField("component", "kubernetes")
 
-	client, err := client.NewFromKubeConfig(kubeconfig)
+	client, err := client.NewFromKubeConfigContext(kubeconfig, contextName)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Kubernetes{
-		client: client,
-		l:      l,
-		lock:   &sync.RWMutex{},
-		httpClient: &http.Client{
-			Timeout: time.Second * 5,
-			Transport: &http.Transport{
-				MaxIdleConns:    1,
-				IdleConnTimeout: 10 * time.Second,
-			},
-		},
+		client:     client,
+		l:          l,
+		lock:       &sync.RWMutex{},
 		kubeconfig: kubeconfig,
 	}, nil
 }
 
+// NewInCluster returns new Kubernetes object authenticating with the service account
+// of the pod it runs in. It is used to run the provisioner as a Job or a Deployment.
+func NewInCluster() (*Kubernetes, error) {
+	client, err := client.NewInCluster()
+	if err != nil {
+		return nil, err
+	}
+	k := NewEmpty()
+	k.client = client
+	k.inCluster = true
+	return k, nil
+}
+
+// NewFromString returns new Kubernetes object for the kubeconfig passed as a string
+// so callers holding kubeconfigs in memory do not need to write them to disk.
+func NewFromString(kubeconfig []byte) (*Kubernetes, error) {
+	return NewFromStringContext(kubeconfig, "")
+}
+
+// NewFromStringContext returns new Kubernetes object for the named context of the kubeconfig
+// passed as a string. The current context of the kubeconfig is used if contextName is empty.
+func NewFromStringContext(kubeconfig []byte, contextName string) (*Kubernetes, error) {
+	client, err := client.NewFromKubeConfigData(kubeconfig, contextName)
+	if err != nil {
+		return nil, err
+	}
+	k := NewEmpty()
+	k.client = client
+	k.kubeconfigData = kubeconfig
+	return k, nil
+}
+
+// RunningInCluster returns true if the process runs in a pod with a mounted service account.
+func RunningInCluster() bool {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return false
+	}
+	_, err := os.Stat(serviceAccountTokenFile)
+	return err == nil
+}
+
+// Kubeconfig returns the kubeconfig the client was created from. A kubeconfig
+// of the service account is generated for clients created in the cluster.
+func (k *Kubernetes) Kubeconfig() ([]byte, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	if k.inCluster {
+		return k.client.GenerateInClusterKubeConfig()
+	}
+	if k.kubeconfigData != nil {
+		return k.kubeconfigData, nil
+	}
+	data, err := os.ReadFile(strings.ReplaceAll(k.kubeconfig, "~", os.Getenv("HOME")))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read kubeconfig")
+	}
+	return data, nil
+}
+
 // NewEmpty returns new Kubernetes object.
 func NewEmpty() *Kubernetes {
 	return &Kubernetes{
 		client: &client.Client{},
 		lock:   &sync.RWMutex{},
 		l:      logrus.WithField("component", "kubernetes"),
-		httpClient: &http.Client{
-			Timeout: time.Second * 5,
-			Transport: &http.Transport{
-				MaxIdleConns:    1,
-				IdleConnTimeout: 10 * time.Second,
-			},
-		},
 	}
 }
 
+// NewWithClient returns new Kubernetes object using the client,
+// e.g. the fake one of the kubernetes/fake package in tests.
+func NewWithClient(c client.KubeClientConnector) *Kubernetes {
+	k := NewEmpty()
+	k.client = c
+	return k
+}
+
+// EnableCache switches reads of database clusters, secrets and deployments to
+// shared informers so high-frequency callers do not hit the API server on every call.
+// The cache is kept up to date until the context is done.
+func (k *Kubernetes) EnableCache(ctx context.Context, resync time.Duration) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if err := k.client.StartCache(ctx, resync); err != nil {
+		return errors.Wrap(err, "cannot start informer cache")
+	}
+	return nil
+}
+
+// SetCommonMetadata sets labels and annotations added to every object created by the provisioner.
+func (k *Kubernetes) SetCommonMetadata(labels, annotations map[string]string) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.client.SetCommonMetadata(labels, annotations)
+}
+
+// SetRetries switches repeating of API requests failed with transient errors so
+// long provisioning runs do not abort on a single failure. Retries are enabled by default.
+func (k *Kubernetes) SetRetries(enabled bool) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.client.SetRetries(enabled)
+}
+
+// AuditRecord describes a mutating request sent to the API server.
+type AuditRecord = client.AuditRecord
+
+// AuditFunc receives the records of the mutating requests.
+type AuditFunc = client.AuditFunc
+
+// SetAuditor sets the function receiving the records of the mutating API requests.
+func (k *Kubernetes) SetAuditor(auditor AuditFunc) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.client.SetAuditor(auditor)
+}
+
 // GetKubeconfig generates kubeconfig compatible with kubectl for incluster created clients.
 func (k *Kubernetes) GetKubeconfig(ctx context.Context) (string, error) {
 	k.lock.RLock()
@@ -202,7 +338,7 @@ func (k *Kubernetes) RestartDatabaseCluster(ctx context.Context, name string) er
 	defer k.lock.Unlock()
 	cluster, err := k.client.GetDatabaseCluster(ctx, name)
 	if err != nil {
-		return err
+		return classifyError(err)
 	}
 	cluster.TypeMeta.APIVersion = databaseClusterAPIVersion
 	cluster.TypeMeta.Kind = databaseClusterKind
@@ -210,25 +346,97 @@ func (k *Kubernetes) RestartDatabaseCluster(ctx context.Context, name string) er
 		cluster.ObjectMeta.Annotations = make(map[string]string)
 	}
 	cluster.ObjectMeta.Annotations[restartAnnotationKey] = "true"
-	return k.client.ApplyObject(cluster)
+	return classifyError(k.client.ApplyObject(ctx, cluster))
+}
+
+// ReleaseDatabaseCluster removes the managed-by markers from the database cluster
+// so it can be managed by other tools. The cluster itself is left intact.
+// It returns false if the cluster carries no markers.
+func (k *Kubernetes) ReleaseDatabaseCluster(ctx context.Context, name string) (bool, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	cluster, err := k.client.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return false, classifyError(err)
+	}
+	patch, ok := releasePatch(cluster)
+	if !ok {
+		return false, nil
+	}
+	if _, err := k.client.PatchDatabaseCluster(ctx, name, types.MergePatchType, patch); err != nil {
+		return false, classifyError(errors.Wrapf(err, "cannot release database cluster %s", name))
+	}
+	return true, nil
+}
+
+// releasePatch returns a JSON merge patch removing the managed-by markers present on the object.
+func releasePatch(obj metav1.Object) ([]byte, bool) {
+	labels := make(map[string]interface{})
+	annotations := make(map[string]interface{})
+	for _, key := range []string{managedByKey, managedByLabelKey} {
+		if _, ok := obj.GetLabels()[key]; ok {
+			labels[key] = nil
+		}
+		if _, ok := obj.GetAnnotations()[key]; ok {
+			annotations[key] = nil
+		}
+	}
+	if len(labels) == 0 && len(annotations) == 0 {
+		return nil, false
+	}
+	metadata := make(map[string]interface{})
+	if len(labels) != 0 {
+		metadata["labels"] = labels
+	}
+	if len(annotations) != 0 {
+		metadata["annotations"] = annotations
+	}
+	patch, _ := json.Marshal(map[string]interface{}{"metadata": metadata})
+	return patch, true
 }
 
 // PatchDatabaseCluster patches CR of managed Database cluster.
-func (k *Kubernetes) PatchDatabaseCluster(cluster *dbaasv1.DatabaseCluster) error {
+func (k *Kubernetes) PatchDatabaseCluster(ctx context.Context, cluster *dbaasv1.DatabaseCluster) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	return k.client.ApplyObject(ctx, cluster)
+}
+
+// PatchDatabaseClusterFields updates only the fields of the database cluster present in the patch.
+// Only JSON merge patches and JSON patches are supported since DatabaseCluster is a custom resource.
+func (k *Kubernetes) PatchDatabaseClusterFields(ctx context.Context, name string, patch []byte, patchType types.PatchType) (*dbaasv1.DatabaseCluster, error) {
+	switch patchType {
+	case types.MergePatchType, types.JSONPatchType:
+	default:
+		return nil, errors.Errorf("unsupported patch type %q", patchType)
+	}
+	if !json.Valid(patch) {
+		return nil, errors.New("patch is not a valid JSON document")
+	}
 	k.lock.Lock()
 	defer k.lock.Unlock()
-	return k.client.ApplyObject(cluster)
+	cluster, err := k.client.PatchDatabaseCluster(ctx, name, patchType, patch)
+	if err != nil {
+		return nil, classifyError(errors.Wrapf(err, "cannot patch database cluster %s", name))
+	}
+	return cluster, nil
 }
 
-// CreateDatabaseCluster creates database cluster
-func (k *Kubernetes) CreateDatabaseCluster(cluster *dbaasv1.DatabaseCluster) error {
+// CreateDatabaseCluster creates database cluster. Credentials of the admin user are
+// generated and stored in the secrets backend if one is set.
+func (k *Kubernetes) CreateDatabaseCluster(ctx context.Context, cluster *dbaasv1.DatabaseCluster) error {
 	k.lock.Lock()
 	defer k.lock.Unlock()
+	cluster.TypeMeta.APIVersion = databaseClusterAPIVersion
+	cluster.TypeMeta.Kind = databaseClusterKind
 	if cluster.ObjectMeta.Annotations == nil {
 		cluster.ObjectMeta.Annotations = make(map[string]string)
 	}
 	cluster.ObjectMeta.Annotations[managedByKey] = "pmm"
-	return k.client.ApplyObject(cluster)
+	if err := k.createDatabaseCredentials(ctx, cluster); err != nil {
+		return classifyError(err)
+	}
+	return classifyError(k.client.ApplyObject(ctx, cluster))
 }
 
 // DeleteDatabaseCluster deletes database cluster
@@ -237,14 +445,19 @@ func (k *Kubernetes) DeleteDatabaseCluster(ctx context.Context, name string) err
 	defer k.lock.Unlock()
 	cluster, err := k.client.GetDatabaseCluster(ctx, name)
 	if err != nil {
-		return err
+		return classifyError(err)
 	}
 	cluster.TypeMeta.APIVersion = databaseClusterAPIVersion
 	cluster.TypeMeta.Kind = databaseClusterKind
-	return k.client.DeleteObject(cluster)
+	if err := k.client.DeleteObject(ctx, cluster); err != nil {
+		return classifyError(err)
+	}
+	return classifyError(k.deleteDatabaseClusterCertificates(ctx, cluster))
 }
 
-// GetDefaultStorageClassName returns first storageClassName from kubernetes cluster
+// GetDefaultStorageClassName returns the storage class marked as default, the class
+// preferred for the cluster type or the first storage class of the cluster which can
+// provision volumes of database clusters.
 func (k *Kubernetes) GetDefaultStorageClassName(ctx context.Context) (string, error) {
 	k.lock.RLock()
 	defer k.lock.RUnlock()
@@ -252,38 +465,100 @@ func (k *Kubernetes) GetDefaultStorageClassName(ctx context.Context) (string, er
 	if err != nil {
 		return "", err
 	}
-	if len(storageClasses.Items) != 0 {
-		return storageClasses.Items[0].Name, nil
+	if len(storageClasses.Items) == 0 {
+		return "", errors.New("no storage classes available")
+	}
+	for _, sc := range storageClasses.Items {
+		if sc.Annotations[defaultStorageClassAnnotation] == "true" {
+			return sc.Name, nil
+		}
+	}
+	clusterType := clusterTypeFromStorageClasses(storageClasses.Items)
+	for _, name := range preferredStorageClasses[clusterType] {
+		for _, sc := range storageClasses.Items {
+			if sc.Name == name {
+				return sc.Name, nil
+			}
+		}
+	}
+	for _, sc := range storageClasses.Items {
+		if checkDatabaseStorageClass(sc) == nil {
+			return sc.Name, nil
+		}
 	}
-	return "", errors.New("no storage classes available")
+	return "", errors.New("no storage class can provision volumes of database clusters")
+}
+
+// preferredStorageClasses lists storage classes created by the providers in the order of preference.
+var preferredStorageClasses = map[ClusterType][]string{
+	ClusterTypeEKS:      {"gp3", "gp2"},
+	ClusterTypeGKE:      {"standard-rwo", "premium-rwo", "standard"},
+	ClusterTypeAKS:      {"managed-csi", "managed-csi-premium", "managed-premium", "default"},
+	ClusterTypeMinikube: {"standard"},
+}
+
+// maxVolumeSizes holds the maximum volume sizes of the cloud providers.
+var maxVolumeSizes = map[ClusterType]uint64{
+	ClusterTypeEKS: maxVolumeSizeEBS,
+	ClusterTypeGKE: maxVolumeSizeGCEPD,
+	ClusterTypeAKS: maxVolumeSizeAzureDisk,
+}
+
+// MaxVolumeSize returns the maximum size of a volume in bytes for the cluster type.
+// It returns zero if the limit is unknown.
+func MaxVolumeSize(clusterType ClusterType) uint64 {
+	return maxVolumeSizes[clusterType]
 }
 
-// GetClusterType tries to guess the underlying kubernetes cluster based on storage class
+// GetClusterType tries to guess the underlying kubernetes cluster based on served API groups
+// and storage class
 func (k *Kubernetes) GetClusterType(ctx context.Context) (ClusterType, error) {
 	k.lock.RLock()
 	defer k.lock.RUnlock()
+	openShift, err := k.client.HasAPIGroup(ctx, openShiftSecurityAPIGroup)
+	if err != nil {
+		return ClusterTypeUnknown, err
+	}
+	if openShift {
+		return ClusterTypeOpenShift, nil
+	}
 	storageClasses, err := k.client.GetStorageClasses(ctx)
 	if err != nil {
 		return ClusterTypeUnknown, err
 	}
-	for _, storageClass := range storageClasses.Items {
-		if strings.Contains(storageClass.Provisioner, "aws") {
-			return ClusterTypeEKS, nil
-		}
-		if strings.Contains(storageClass.Provisioner, "minikube") ||
-			strings.Contains(storageClass.Provisioner, "kubevirt.io/hostpath-provisioner") ||
-			strings.Contains(storageClass.Provisioner, "standard") {
-			return ClusterTypeMinikube, nil
+	return clusterTypeFromStorageClasses(storageClasses.Items), nil
+}
+
+// clusterTypeFromStorageClasses guesses the cluster type based on storage class provisioners.
+func clusterTypeFromStorageClasses(storageClasses []storagev1.StorageClass) ClusterType {
+	for _, storageClass := range storageClasses {
+		provisioner := storageClass.Provisioner
+		switch {
+		case strings.Contains(provisioner, "aws"):
+			return ClusterTypeEKS
+		case strings.Contains(provisioner, "pd.csi.storage.gke.io") ||
+			strings.Contains(provisioner, "kubernetes.io/gce-pd"):
+			return ClusterTypeGKE
+		case strings.Contains(provisioner, "disk.csi.azure.com") ||
+			strings.Contains(provisioner, "kubernetes.io/azure-disk"):
+			return ClusterTypeAKS
+		case strings.Contains(provisioner, "minikube") ||
+			strings.Contains(provisioner, "kubevirt.io/hostpath-provisioner") ||
+			strings.Contains(provisioner, "standard"):
+			return ClusterTypeMinikube
 		}
 	}
-	return ClusterTypeGeneric, nil
+	return ClusterTypeGeneric
 }
 
 // getOperatorVersion parses operator version from operator deployment
 func (k *Kubernetes) getOperatorVersion(ctx context.Context, deploymentName, containerName string) (string, error) {
 	deployment, err := k.client.GetDeployment(ctx, deploymentName)
 	if err != nil {
-		return "", err
+		if apierrors.IsNotFound(err) {
+			return "", operatorNotFoundError(deploymentName, err)
+		}
+		return "", classifyError(err)
 	}
 	for _, container := range deployment.Spec.Template.Spec.Containers {
 		if container.Name == containerName {
@@ -328,8 +603,8 @@ func (k *Kubernetes) ListSecrets(ctx context.Context) (*corev1.SecretList, error
 	return k.client.ListSecrets(ctx)
 }
 
-// CreatePMMSecret creates pmm secret in kubernetes.
-func (k *Kubernetes) CreatePMMSecret(secretName string, secrets map[string][]byte) error {
+// CreatePMMSecret creates a basic auth secret with the PMM credentials in kubernetes.
+func (k *Kubernetes) CreatePMMSecret(ctx context.Context, secretName string, secrets map[string][]byte) error {
 	k.lock.Lock()
 	defer k.lock.Unlock()
 	secret := &corev1.Secret{ //nolint: exhaustruct
@@ -340,16 +615,16 @@ func (k *Kubernetes) CreatePMMSecret(secretName string, secrets map[string][]byt
 		ObjectMeta: metav1.ObjectMeta{
 			Name: secretName,
 		},
-		Type: corev1.SecretTypeOpaque,
+		Type: corev1.SecretTypeBasicAuth,
 		Data: secrets,
 	}
-	return k.client.ApplyObject(secret)
+	return k.applySecret(ctx, secret)
 }
 
-func (k *Kubernetes) CreateRestore(restore *dbaasv1.DatabaseClusterRestore) error {
+func (k *Kubernetes) CreateRestore(ctx context.Context, restore *database.DatabaseClusterRestore) error {
 	k.lock.Lock()
 	defer k.lock.Unlock()
-	return k.client.ApplyObject(restore)
+	return k.client.ApplyObject(ctx, restore)
 }
 
 // GetPods returns list of pods.
@@ -462,39 +737,61 @@ func (k *Kubernetes) GetStorageClasses(ctx context.Context) (*storagev1.StorageC
 }
 
 // InstallOLMOperator installs the OLM in the Kubernetes cluster.
-func (k *Kubernetes) InstallOLMOperator(ctx context.Context) error {
+// The replicas and resources of the OLM components are overridden by the tuning if it is set.
+// An existing installation is left intact. Catalog sources are created by CreateCatalogSource.
+func (k *Kubernetes) InstallOLMOperator(ctx context.Context, tuning OLMTuning) error {
+	return classifyError(k.installOLMOperator(ctx, tuning))
+}
+
+func (k *Kubernetes) installOLMOperator(ctx context.Context, tuning OLMTuning) error {
 	deployment, err := k.client.GetDeployment(ctx, "olm-operator")
-	if err == nil && deployment != nil && deployment.ObjectMeta.Name != "" {
+	if err == nil && deployment != nil && deployment.ObjectMeta.Name != "" && !k.force {
 		return nil // already installed
 	}
 
-	var crdFile, olmFile, perconaCatalog []byte
+	var crdFile, olmFile []byte
 
 	crdFile, err = fs.ReadFile(data.OLMCRDs, "crds/olm/crds.yaml")
 	if err != nil {
 		return errors.Wrapf(err, "failed to read OLM CRDs file")
 	}
 
-	if err := k.client.ApplyFile(crdFile); err != nil {
+	if err := k.client.ApplyFile(ctx, crdFile); err != nil {
 		return errors.Wrapf(err, "cannot apply %q file", crdFile)
 	}
 
-	olmFile, err = fs.ReadFile(data.OLMCRDs, "crds/olm/olm.yaml")
+	crdResources, err := decodeResources(crdFile)
 	if err != nil {
-		return errors.Wrapf(err, "failed to read OLM file")
+		return errors.Wrap(err, "cannot decode crd resources")
 	}
 
-	if err := k.client.ApplyFile(olmFile); err != nil {
-		return errors.Wrapf(err, "cannot apply %q file", crdFile)
+	if err := k.waitForCRDsEstablished(ctx, crdResources); err != nil {
+		return err
 	}
 
-	perconaCatalog, err = fs.ReadFile(data.OLMCRDs, "crds/olm/percona-dbaas-catalog.yaml")
+	olmFile, err = fs.ReadFile(data.OLMCRDs, "crds/olm/olm.yaml")
 	if err != nil {
-		return errors.Wrapf(err, "failed to read percona catalog yaml file")
+		return errors.Wrapf(err, "failed to read OLM file")
 	}
 
-	if err := k.client.ApplyFile(perconaCatalog); err != nil {
-		return errors.Wrapf(err, "cannot apply %q file", crdFile)
+	olmResources, err := decodeResources(olmFile)
+	if err != nil {
+		return errors.Wrap(err, "cannot decode olm resources")
+	}
+
+	if len(tuning) == 0 {
+		if err := k.client.ApplyFile(ctx, olmFile); err != nil {
+			return errors.Wrapf(err, "cannot apply %q file", crdFile)
+		}
+	} else {
+		if err := tuneOLMResources(olmResources, tuning); err != nil {
+			return err
+		}
+		for i := range olmResources {
+			if err := k.client.ApplyObject(ctx, &olmResources[i]); err != nil {
+				return errors.Wrapf(err, "cannot apply %s %s", olmResources[i].GetKind(), olmResources[i].GetName())
+			}
+		}
 	}
 
 	if err := k.client.DoRolloutWait(ctx, types.NamespacedName{Namespace: olmNamespace, Name: "olm-operator"}); err != nil {
@@ -504,16 +801,6 @@ func (k *Kubernetes) InstallOLMOperator(ctx context.Context) error {
 		return errors.Wrap(err, "error while waiting for deployment rollout")
 	}
 
-	crdResources, err := decodeResources(crdFile)
-	if err != nil {
-		return errors.Wrap(err, "cannot decode crd resources")
-	}
-
-	olmResources, err := decodeResources(olmFile)
-	if err != nil {
-		return errors.Wrap(err, "cannot decode olm resources")
-	}
-
 	resources := append(crdResources, olmResources...)
 
 	subscriptions := filterResources(resources, func(r unstructured.Unstructured) bool {
@@ -526,12 +813,12 @@ func (k *Kubernetes) InstallOLMOperator(ctx context.Context) error {
 
 	for _, sub := range subscriptions {
 		subscriptionKey := types.NamespacedName{Namespace: sub.GetNamespace(), Name: sub.GetName()}
-		log.Printf("Waiting for subscription/%s to install CSV", subscriptionKey.Name)
+		k.l.Debugf("Waiting for subscription/%s to install CSV", subscriptionKey.Name)
 		csvKey, err := k.client.GetSubscriptionCSV(ctx, subscriptionKey)
 		if err != nil {
 			return fmt.Errorf("subscription/%s failed to install CSV: %v", subscriptionKey.Name, err)
 		}
-		log.Printf("Waiting for clusterserviceversion/%s to reach 'Succeeded' phase", csvKey.Name)
+		k.l.Debugf("Waiting for clusterserviceversion/%s to reach 'Succeeded' phase", csvKey.Name)
 		if err := k.client.DoCSVWait(ctx, csvKey); err != nil {
 			return fmt.Errorf("clusterserviceversion/%s failed to reach 'Succeeded' phase", csvKey.Name)
 		}
@@ -544,6 +831,24 @@ func (k *Kubernetes) InstallOLMOperator(ctx context.Context) error {
 	return nil
 }
 
+// waitForCRDsEstablished waits for the CRDs among resources to be established
+// so custom resources depending on them can be applied.
+func (k *Kubernetes) waitForCRDsEstablished(ctx context.Context, resources []unstructured.Unstructured) error {
+	ctx, cancel := context.WithTimeout(ctx, crdEstablishTimeout)
+	defer cancel()
+
+	crds := filterResources(resources, func(r unstructured.Unstructured) bool {
+		return r.GetKind() == crdKind
+	})
+	for _, crd := range crds {
+		k.l.Debugf("Waiting for customresourcedefinition/%s to be established", crd.GetName())
+		if err := k.client.DoCRDWait(ctx, crd.GetName()); err != nil {
+			return errors.Wrapf(err, "customresourcedefinition/%s was not established", crd.GetName())
+		}
+	}
+	return nil
+}
+
 func decodeResources(f []byte) (objs []unstructured.Unstructured, err error) {
 	dec := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(f), 8)
 	for {
@@ -584,22 +889,30 @@ type InstallOperatorRequest struct {
 }
 
 // InstallOperator installs an operator via OLM.
-func (k *Kubernetes) InstallOperator(ctx context.Context, req InstallOperatorRequest) error {
+// Non-fatal issues found during the installation are returned as warnings.
+func (k *Kubernetes) InstallOperator(ctx context.Context, req InstallOperatorRequest) (Warnings, error) {
 	if err := createOperatorGroupIfNeeded(ctx, k.client, req.OperatorGroup); err != nil {
-		return err
+		return nil, classifyError(err)
 	}
+	warnings, err := k.installOperator(ctx, req)
+	return warnings, classifyError(err)
+}
 
-	subs, err := k.client.CreateSubscriptionForCatalog(ctx, req.Namespace, req.Name, "olm", req.CatalogSource,
-		req.Name, req.Channel, req.StartingCSV, v1alpha1.ApprovalManual)
-	if err != nil {
-		return errors.Wrap(err, "cannot create a susbcription to install the operator")
+// installOperator installs the operator into an existing operator group.
+func (k *Kubernetes) installOperator(ctx context.Context, req InstallOperatorRequest) (Warnings, error) {
+	var warnings Warnings
+	installed, w, err := k.reconcileSubscription(ctx, req)
+	warnings.Merge(w)
+	if err != nil || installed {
+		return warnings, err
 	}
 
+	var subs *v1alpha1.Subscription
+	started := time.Now()
 	err = wait.Poll(pollInterval, pollDuration, func() (bool, error) {
-		k.lock.Lock()
-		defer k.lock.Unlock()
-
+		k.lock.RLock()
 		subs, err = k.client.GetSubscription(ctx, req.Namespace, req.Name)
+		k.lock.RUnlock()
 		if err != nil || subs == nil || (subs != nil && subs.Status.Install == nil) {
 			return false, err
 		}
@@ -608,24 +921,36 @@ func (k *Kubernetes) InstallOperator(ctx context.Context, req InstallOperatorReq
 	})
 
 	if err != nil {
-		return err
+		return warnings, err
 	}
 	if subs == nil {
-		return fmt.Errorf("cannot get an install plan for the operator subscription: %q", req.Name)
+		return warnings, fmt.Errorf("cannot get an install plan for the operator subscription: %q", req.Name)
+	}
+	if elapsed := time.Since(started); elapsed > catalogSlowThreshold {
+		warnings.Add(WarningCatalogSlow, "catalog %q took %s to resolve %q operator", req.CatalogSource, elapsed.Round(time.Second), req.Name)
 	}
 
 	ip, err := k.client.GetInstallPlan(ctx, req.Namespace, subs.Status.Install.Name)
 	if err != nil {
-		return err
+		return warnings, err
 	}
 
 	ip.Spec.Approved = true
 	_, err = k.client.UpdateInstallPlan(ctx, req.Namespace, ip)
+	if err != nil || len(k.imagePullSecrets) == 0 {
+		return warnings, err
+	}
 
-	return err
+	return warnings, k.propagateOperatorPullSecrets(ctx, req)
 }
 
+// createOperatorGroupIfNeeded creates the operator group in the default namespace.
+// Nothing is created if the name is empty, e.g. for operators using the global
+// operators group created by OLM.
 func createOperatorGroupIfNeeded(ctx context.Context, client client.KubeClientConnector, name string) error {
+	if name == "" {
+		return nil
+	}
 	_, err := client.GetOperatorGroup(ctx, useDefaultNamespace, name)
 	if err == nil {
 		return nil
@@ -650,6 +975,9 @@ func (k *Kubernetes) UpgradeOperator(ctx context.Context, namespace, name string
 		var err error
 		subs, err = k.client.GetSubscription(ctx, namespace, name)
 		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, operatorNotFoundError(name, err)
+			}
 			return false, err
 		}
 		if subs == nil || subs.Status.Install == nil || subs.Status.Install.Name == "" {
@@ -659,7 +987,7 @@ func (k *Kubernetes) UpgradeOperator(ctx context.Context, namespace, name string
 		return true, nil
 	})
 	if err != nil {
-		return err
+		return classifyError(err)
 	}
 	if subs == nil || subs.Status.Install == nil || subs.Status.Install.Name == "" {
 		return fmt.Errorf("cannot get subscription for %q operator", name)
@@ -667,7 +995,7 @@ func (k *Kubernetes) UpgradeOperator(ctx context.Context, namespace, name string
 
 	ip, err := k.client.GetInstallPlan(ctx, namespace, subs.Status.Install.Name)
 	if err != nil {
-		return errors.Wrapf(err, "cannot get install plan to upgrade %q", name)
+		return classifyError(errors.Wrapf(err, "cannot get install plan to upgrade %q", name))
 	}
 
 	if ip.Spec.Approved == true {
@@ -678,12 +1006,12 @@ func (k *Kubernetes) UpgradeOperator(ctx context.Context, namespace, name string
 
 	_, err = k.client.UpdateInstallPlan(ctx, namespace, ip)
 
-	return err
+	return classifyError(err)
 }
 
 // GetServerVersion returns server version
-func (k *Kubernetes) GetServerVersion() (*version.Info, error) {
-	return k.client.GetServerVersion()
+func (k *Kubernetes) GetServerVersion(ctx context.Context) (*version.Info, error) {
+	return k.client.GetServerVersion(ctx)
 }
 
 // GetClusterServiceVersion retrieves a ClusterServiceVersion by namespaced name.
@@ -701,101 +1029,125 @@ func (k *Kubernetes) ListClusterServiceVersion(ctx context.Context, namespace st
 }
 
 // DeleteObject deletes an object.
-func (k *Kubernetes) DeleteObject(obj runtime.Object) error {
+func (k *Kubernetes) DeleteObject(ctx context.Context, obj runtime.Object) error {
 	k.lock.RLock()
 	defer k.lock.RUnlock()
-	return k.client.DeleteObject(obj)
+	return k.client.DeleteObject(ctx, obj)
 }
 
-// and creates a VM Agent instance.
-func (k *Kubernetes) ProvisionMonitoring(login, password, pmmPublicAddress string) error {
+// ProvisionMonitoring stores the PMM credentials in a secret unless an existing
+// secret is passed and creates a VM Agent instance writing metrics to PMM.
+// If selective is set, only database clusters with monitoring enabled are scraped.
+// Non-fatal issues found during provisioning are returned as warnings.
+func (k *Kubernetes) ProvisionMonitoring(ctx context.Context, creds MonitoringCredentials, pmmPublicAddress string, selective bool, tls MonitoringTLS) (Warnings, error) {
+	var warnings Warnings
 	randomCrypto, err := rand.Prime(rand.Reader, 64)
 	if err != nil {
-		return err
+		return warnings, err
 	}
 
-	secretName := fmt.Sprintf("vm-operator-%d", randomCrypto)
-	err = k.CreatePMMSecret(secretName, map[string][]byte{
-		"username": []byte(login),
-		"password": []byte(password),
-	})
-	if err != nil {
-		return err
-	}
-
-	vmagent := vmAgentSpec(secretName, pmmPublicAddress)
-	err = k.client.ApplyObject(vmagent)
-	if err != nil {
-		return errors.Wrap(err, "cannot apply vm agent spec")
-	}
-
-	files := []string{
-		"crds/victoriametrics/crs/vmagent_rbac.yaml",
-		"crds/victoriametrics/crs/vmnodescrape.yaml",
-		"crds/victoriametrics/crs/vmpodscrape.yaml",
-		"crds/victoriametrics/kube-state-metrics/service-account.yaml",
-		"crds/victoriametrics/kube-state-metrics/cluster-role.yaml",
-		"crds/victoriametrics/kube-state-metrics/cluster-role-binding.yaml",
-		"crds/victoriametrics/kube-state-metrics/deployment.yaml",
-		"crds/victoriametrics/kube-state-metrics/service.yaml",
-		"crds/victoriametrics/kube-state-metrics.yaml",
-	}
-	for _, path := range files {
-		file, err := data.OLMCRDs.ReadFile(path)
-		if err != nil {
-			return err
-		}
-		// retry 3 times because applying vmagent spec might take some time.
-		for i := 0; i < 3; i++ {
-			err = k.client.ApplyFile(file)
-			if err != nil {
-				time.Sleep(10 * time.Second)
-				continue
-			}
-			break
+	secretName := fmt.Sprintf("%s%d", pmmSecretPrefix, randomCrypto)
+	credentialsSecret := secretName
+	if creds.Secret != "" {
+		if err := k.checkCredentialsSecret(ctx, creds.Secret); err != nil {
+			return warnings, err
 		}
+		credentialsSecret = creds.Secret
+	} else {
+		err = k.CreatePMMSecret(ctx, secretName, map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte(creds.Username),
+			corev1.BasicAuthPasswordKey: []byte(creds.Password),
+		})
 		if err != nil {
-			return errors.Wrapf(err, "cannot apply file: %q", path)
+			return warnings, err
 		}
 	}
-	return nil
+	if err := k.applyMonitoringTLS(ctx, secretName, tls, nil); err != nil {
+		return warnings, err
+	}
+
+	// Agents of previous runs are replaced so metrics are not sent twice.
+	previous, err := k.pmmVMAgents(ctx)
+	if err != nil {
+		return warnings, err
+	}
+	vmagent := vmAgentSpec(secretName, pmmPublicAddress, tls)
+	useCredentialsSecret(&vmagent.Spec, credentialsSecret)
+	if creds.Secret == "" {
+		k.injectSecret(&vmagent.Spec, "", secretName)
+	}
+	annotateCredentials(vmagent, creds)
+	if selective {
+		restrictScrapeSelectors(&vmagent.Spec)
+	}
+	customizeScraping(&vmagent.Spec, k.scrape)
+	if k.hardened {
+		restrictVMAgent(&vmagent.Spec)
+	}
+	vmagent.Spec.Resources = overrideResources(vmagent.Spec.Resources, k.monitoringResources.VMAgent)
+	scheduleVMAgent(&vmagent.Spec, k.scheduling)
+	vmagent.Spec.ImagePullSecrets = k.imagePullSecretRefs()
+	err = k.client.ApplyObject(ctx, vmagent)
+	if err != nil {
+		return warnings, errors.Wrap(err, "cannot apply vm agent spec")
+	}
+	if err := k.removeVMAgents(ctx, previous); err != nil {
+		return warnings, err
+	}
+
+	stackWarnings, err := k.applyMonitoringStack(ctx)
+	warnings.Merge(stackWarnings)
+	return warnings, err
 }
 
-// CleanupMonitoring remove all files installed by ProvisionMonitoring.
-func (k *Kubernetes) CleanupMonitoring() error {
-	files := []string{
-		"crds/victoriametrics/kube-state-metrics.yaml",
-		"crds/victoriametrics/kube-state-metrics/cluster-role-binding.yaml",
-		"crds/victoriametrics/kube-state-metrics/cluster-role.yaml",
-		"crds/victoriametrics/kube-state-metrics/deployment.yaml",
-		"crds/victoriametrics/kube-state-metrics/service-account.yaml",
-		"crds/victoriametrics/kube-state-metrics/service.yaml",
-		"crds/victoriametrics/crs/vmagent_rbac.yaml",
-		"crds/victoriametrics/crs/vmnodescrape.yaml",
-		"crds/victoriametrics/crs/vmpodscrape.yaml",
-	}
-	for _, path := range files {
-		file, err := data.OLMCRDs.ReadFile(path)
-		if err != nil {
-			return err
-		}
-		err = k.client.DeleteFile(file)
-		if err != nil {
-			return errors.Wrapf(err, "cannot apply file: %q", path)
-		}
+// CleanupMonitoring removes the objects applied by ProvisionMonitoring besides the VM agents
+// and the ones applied by ProvisionAlerting.
+func (k *Kubernetes) CleanupMonitoring(ctx context.Context) error {
+	if err := k.RemoveAlerting(ctx); err != nil {
+		return err
 	}
+	return k.deleteMonitoringStack(ctx)
+}
 
-	return nil
+// DisableMonitoring removes the VM agents created by ProvisionMonitoring together with the
+// secrets holding their credentials and certificates, the monitoring stack and alerting.
+// It returns the names of the PMM API keys the removed agents wrote metrics with so they can
+// be revoked. Credentials secrets supplied by the user are kept.
+func (k *Kubernetes) DisableMonitoring(ctx context.Context) ([]string, error) {
+	rotations, err := k.CredentialsRotations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	agents, err := k.pmmVMAgents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.removeVMAgents(ctx, agents); err != nil {
+		return nil, err
+	}
+	if err := k.CleanupMonitoring(ctx); err != nil {
+		return nil, err
+	}
+	apiKeys := make([]string, 0, len(rotations))
+	for _, r := range rotations {
+		apiKeys = append(apiKeys, r.APIKey)
+	}
+	return apiKeys, nil
 }
 
-func vmAgentSpec(secretName, address string) *victoriametricsv1beta1.VMAgent {
+// remoteWriteURL returns the URL VM agents send the metrics to PMM at.
+func remoteWriteURL(address string) string {
+	return fmt.Sprintf("%s/victoriametrics/api/v1/write", address)
+}
+
+func vmAgentSpec(secretName, address string, tls MonitoringTLS) *victoriametricsv1beta1.VMAgent {
 	return &victoriametricsv1beta1.VMAgent{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "VMAgent",
 			APIVersion: "operator.victoriametrics.com/v1beta1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "pmm-vmagent-" + secretName,
+			Name: vmAgentNamePrefix + secretName,
 		},
 		Spec: victoriametricsv1beta1.VMAgentSpec{
 			ServiceScrapeNamespaceSelector: &metav1.LabelSelector{},
@@ -808,25 +1160,14 @@ func vmAgentSpec(secretName, address string) *victoriametricsv1beta1.VMAgent {
 			StaticScrapeNamespaceSelector:  &metav1.LabelSelector{},
 			ReplicaCount:                   pointer.ToInt32(1),
 			SelectAllByDefault:             true,
-			Resources: corev1.ResourceRequirements{
-				Requests: corev1.ResourceList{
-					corev1.ResourceCPU:    resource.MustParse("250m"),
-					corev1.ResourceMemory: resource.MustParse("350Mi"),
-				},
-				Limits: corev1.ResourceList{
-					corev1.ResourceCPU:    resource.MustParse("500m"),
-					corev1.ResourceMemory: resource.MustParse("850Mi"),
-				},
-			},
+			Resources:                      DefaultVMAgentResources(),
 			ExtraArgs: map[string]string{
 				"memory.allowedPercent": "40",
 			},
 			RemoteWrite: []victoriametricsv1beta1.VMAgentRemoteWriteSpec{
 				{
-					URL: fmt.Sprintf("%s/victoriametrics/api/v1/write", address),
-					TLSConfig: &victoriametricsv1beta1.TLSConfig{
-						InsecureSkipVerify: true,
-					},
+					URL:       remoteWriteURL(address),
+					TLSConfig: remoteWriteTLSConfig(secretName, tls),
 					BasicAuth: &victoriametricsv1beta1.BasicAuth{
 						Username: corev1.SecretKeySelector{
 							LocalObjectReference: corev1.LocalObjectReference{
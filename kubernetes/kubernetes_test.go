@@ -0,0 +1,118 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterTypeFromStorageClasses(t *testing.T) {
+	for provisioner, expected := range map[string]ClusterType{
+		"ebs.csi.aws.com":          ClusterTypeEKS,
+		"pd.csi.storage.gke.io":    ClusterTypeGKE,
+		"kubernetes.io/gce-pd":     ClusterTypeGKE,
+		"disk.csi.azure.com":       ClusterTypeAKS,
+		"kubernetes.io/azure-disk": ClusterTypeAKS,
+		"k8s.io/minikube-hostpath": ClusterTypeMinikube,
+		"rancher.io/local-path":    ClusterTypeGeneric,
+	} {
+		storageClasses := []storagev1.StorageClass{{Provisioner: provisioner}}
+		assert.Equal(t, expected, clusterTypeFromStorageClasses(storageClasses), provisioner)
+	}
+}
+
+func TestGetDefaultStorageClassName(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name           string
+		storageClasses []storagev1.StorageClass
+		expected       string
+	}{
+		{
+			name: "annotated default",
+			storageClasses: []storagev1.StorageClass{
+				{ObjectMeta: metav1.ObjectMeta{Name: "standard"}, Provisioner: "pd.csi.storage.gke.io"},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "fast",
+						Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+					},
+					Provisioner: "pd.csi.storage.gke.io",
+				},
+			},
+			expected: "fast",
+		},
+		{
+			name: "gke preferred",
+			storageClasses: []storagev1.StorageClass{
+				{ObjectMeta: metav1.ObjectMeta{Name: "premium-rwo"}, Provisioner: "pd.csi.storage.gke.io"},
+				{ObjectMeta: metav1.ObjectMeta{Name: "standard-rwo"}, Provisioner: "pd.csi.storage.gke.io"},
+			},
+			expected: "standard-rwo",
+		},
+		{
+			name: "aks preferred",
+			storageClasses: []storagev1.StorageClass{
+				{ObjectMeta: metav1.ObjectMeta{Name: "azurefile"}, Provisioner: "file.csi.azure.com"},
+				{ObjectMeta: metav1.ObjectMeta{Name: "managed-csi"}, Provisioner: "disk.csi.azure.com"},
+			},
+			expected: "managed-csi",
+		},
+		{
+			name: "first class",
+			storageClasses: []storagev1.StorageClass{
+				{ObjectMeta: metav1.ObjectMeta{Name: "local-storage"}, Provisioner: noProvisioner},
+				{ObjectMeta: metav1.ObjectMeta{Name: "local-path"}, Provisioner: "rancher.io/local-path"},
+			},
+			expected: "local-path",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			k8sclient := &client.MockKubeClientConnector{}
+			k := NewEmpty()
+			k.client = k8sclient
+			k8sclient.On("GetStorageClasses", context.Background()).
+				Return(&storagev1.StorageClassList{Items: tc.storageClasses}, nil)
+
+			name, err := k.GetDefaultStorageClassName(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, name)
+		})
+	}
+}
+
+func TestKubeconfigInCluster(t *testing.T) {
+	t.Parallel()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k.inCluster = true
+	k8sclient.On("GenerateInClusterKubeConfig").Return([]byte("kubeconfig"), nil)
+
+	data, err := k.Kubeconfig()
+	require.NoError(t, err)
+	assert.Equal(t, "kubeconfig", string(data))
+}
@@ -0,0 +1,243 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// LeftoverKindCRD is a custom resource definition left by a removed operator.
+	LeftoverKindCRD = "CustomResourceDefinition"
+	// LeftoverKindCSV is a cluster service version without a subscription.
+	LeftoverKindCSV = "ClusterServiceVersion"
+	// LeftoverKindOperatorGroup is an operator group conflicting with ours.
+	LeftoverKindOperatorGroup = "OperatorGroup"
+
+	crdAPIVersion = "apiextensions.k8s.io/v1"
+)
+
+// operatorCRDGroups maps API groups of CRDs to the OLM packages owning them.
+var operatorCRDGroups = map[string]string{
+	"dbaas.percona.com":            "dbaas-operator",
+	"pxc.percona.com":              "percona-xtradb-cluster-operator",
+	"psmdb.percona.com":            "percona-server-mongodb-operator",
+	"operator.victoriametrics.com": "victoriametrics-operator",
+}
+
+// Leftover describes a resource left in the cluster by a previous installation.
+type Leftover struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Reason    string
+	// Removable is false when removing the resource would destroy user data.
+	Removable bool
+
+	obj runtime.Object
+}
+
+// String returns a human readable representation of the leftover.
+func (l Leftover) String() string {
+	name := l.Name
+	if l.Namespace != "" {
+		name = l.Namespace + "/" + l.Name
+	}
+	return fmt.Sprintf("%s %s: %s", l.Kind, name, l.Reason)
+}
+
+// FindLeftovers scans the cluster for resources left by previous Everest/DBaaS installs
+// which make a reinstall fail: CRDs of operators that are not subscribed anymore,
+// CSVs without a subscription and operator groups conflicting with the given one.
+func (k *Kubernetes) FindLeftovers(ctx context.Context, namespace, operatorGroup string) ([]Leftover, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	subs, err := k.client.ListSubscriptions(ctx, namespace)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "cannot list subscriptions")
+	}
+	packages := make(map[string]struct{})
+	csvs := make(map[string]struct{})
+	if subs != nil {
+		for _, sub := range subs.Items {
+			if sub.Spec != nil {
+				packages[sub.Spec.Package] = struct{}{}
+			}
+			csvs[sub.Status.InstalledCSV] = struct{}{}
+			csvs[sub.Status.CurrentCSV] = struct{}{}
+		}
+	}
+
+	var leftovers []Leftover
+
+	crds, err := k.client.ListCRDs(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list CRDs")
+	}
+	for i := range crds.Items {
+		crd := crds.Items[i]
+		pkg, ok := operatorCRDGroups[crd.Spec.Group]
+		if !ok {
+			continue
+		}
+		if _, ok := packages[pkg]; ok {
+			continue
+		}
+		leftover := Leftover{
+			Kind:      LeftoverKindCRD,
+			Name:      crd.Name,
+			Reason:    fmt.Sprintf("operator %q is not installed", pkg),
+			Removable: true,
+			obj:       crdObject(&crd),
+		}
+		count, err := k.countCustomResources(ctx, &crd)
+		if err != nil {
+			return nil, err
+		}
+		if count != 0 {
+			leftover.Reason = fmt.Sprintf("%s, but %d custom resources still exist", leftover.Reason, count)
+			leftover.Removable = false
+		}
+		leftovers = append(leftovers, leftover)
+	}
+
+	csvList, err := k.client.ListClusterServiceVersion(ctx, namespace)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "cannot list cluster service versions")
+	}
+	if csvList != nil {
+		for i := range csvList.Items {
+			csv := csvList.Items[i]
+			if csv.Status.Reason == v1alpha1.CSVReasonCopied {
+				continue
+			}
+			if _, ok := csvs[csv.Name]; ok {
+				continue
+			}
+			csv.TypeMeta = metav1.TypeMeta{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       v1alpha1.ClusterServiceVersionKind,
+			}
+			leftovers = append(leftovers, Leftover{
+				Kind:      LeftoverKindCSV,
+				Namespace: csv.Namespace,
+				Name:      csv.Name,
+				Reason:    "no subscription references it",
+				Removable: true,
+				obj:       &csv,
+			})
+		}
+	}
+
+	groups, err := k.client.ListOperatorGroups(ctx, namespace)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "cannot list operator groups")
+	}
+	if groups != nil {
+		for i := range groups.Items {
+			og := groups.Items[i]
+			if og.Name == operatorGroup {
+				continue
+			}
+			og.TypeMeta = metav1.TypeMeta{
+				APIVersion: APIVersionCoreosV1,
+				Kind:       LeftoverKindOperatorGroup,
+			}
+			leftovers = append(leftovers, Leftover{
+				Kind:      LeftoverKindOperatorGroup,
+				Namespace: og.Namespace,
+				Name:      og.Name,
+				Reason:    fmt.Sprintf("conflicts with operator group %q", operatorGroup),
+				Removable: true,
+				obj:       &og,
+			})
+		}
+	}
+
+	return leftovers, nil
+}
+
+// CleanupLeftovers removes leftovers found by FindLeftovers.
+// Leftovers which are not removable are skipped.
+func (k *Kubernetes) CleanupLeftovers(ctx context.Context, leftovers []Leftover) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	for _, leftover := range leftovers {
+		if !leftover.Removable || leftover.obj == nil {
+			k.l.Warnf("skipping removal of %s", leftover)
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		k.l.Infof("removing %s %s", leftover.Kind, leftover.Name)
+		if err := k.client.DeleteObject(ctx, leftover.obj); err != nil {
+			return classifyError(errors.Wrapf(err, "cannot remove %s %s", leftover.Kind, leftover.Name))
+		}
+	}
+	return nil
+}
+
+func (k *Kubernetes) countCustomResources(ctx context.Context, crd *apiextv1.CustomResourceDefinition) (int, error) {
+	crs, err := k.listCustomResources(ctx, crd)
+	return len(crs), err
+}
+
+// listCustomResources returns custom resources of the CRD in all namespaces.
+func (k *Kubernetes) listCustomResources(ctx context.Context, crd *apiextv1.CustomResourceDefinition) ([]unstructured.Unstructured, error) {
+	version := ""
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			version = v.Name
+			break
+		}
+	}
+	if version == "" {
+		return nil, nil
+	}
+	gvr := schema.GroupVersionResource{
+		Group:    crd.Spec.Group,
+		Version:  version,
+		Resource: crd.Spec.Names.Plural,
+	}
+	list, err := k.client.ListCRs(ctx, useDefaultNamespace, gvr, nil)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "cannot list %s", crd.Name)
+	}
+	return list.Items, nil
+}
+
+func crdObject(crd *apiextv1.CustomResourceDefinition) *apiextv1.CustomResourceDefinition {
+	crd.TypeMeta = metav1.TypeMeta{
+		APIVersion: crdAPIVersion,
+		Kind:       LeftoverKindCRD,
+	}
+	return crd
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFindLeftovers(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+
+	k := NewEmpty()
+	k.client = k8sclient
+
+	k8sclient.On("ListSubscriptions", ctx, "default").Return(&v1alpha1.SubscriptionList{
+		Items: []v1alpha1.Subscription{
+			{
+				Spec:   &v1alpha1.SubscriptionSpec{Package: "dbaas-operator"},
+				Status: v1alpha1.SubscriptionStatus{InstalledCSV: "dbaas-operator.v0.1.0"},
+			},
+		},
+	}, nil)
+	k8sclient.On("ListCRDs", ctx, (*metav1.LabelSelector)(nil)).Return(&apiextv1.CustomResourceDefinitionList{
+		Items: []apiextv1.CustomResourceDefinition{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "databaseclusters.dbaas.percona.com"},
+				Spec:       apiextv1.CustomResourceDefinitionSpec{Group: "dbaas.percona.com"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "perconaxtradbclusters.pxc.percona.com"},
+				Spec: apiextv1.CustomResourceDefinitionSpec{
+					Group:    "pxc.percona.com",
+					Names:    apiextv1.CustomResourceDefinitionNames{Plural: "perconaxtradbclusters"},
+					Versions: []apiextv1.CustomResourceDefinitionVersion{{Name: "v1", Storage: true}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "certificates.cert-manager.io"},
+				Spec:       apiextv1.CustomResourceDefinitionSpec{Group: "cert-manager.io"},
+			},
+		},
+	}, nil)
+	k8sclient.On("ListCRs", ctx, "", mock.Anything, (*metav1.LabelSelector)(nil)).Return(&unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{{}},
+	}, nil)
+	k8sclient.On("ListClusterServiceVersion", ctx, "default").Return(&v1alpha1.ClusterServiceVersionList{
+		Items: []v1alpha1.ClusterServiceVersion{
+			{ObjectMeta: metav1.ObjectMeta{Name: "dbaas-operator.v0.1.0", Namespace: "default"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "percona-xtradb-cluster-operator.v1.12.0", Namespace: "default"}},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "packageserver", Namespace: "default"},
+				Status:     v1alpha1.ClusterServiceVersionStatus{Reason: v1alpha1.CSVReasonCopied},
+			},
+		},
+	}, nil)
+	k8sclient.On("ListOperatorGroups", ctx, "default").Return(&v1.OperatorGroupList{
+		Items: []v1.OperatorGroup{
+			{ObjectMeta: metav1.ObjectMeta{Name: "percona-operators-group", Namespace: "default"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "old-group", Namespace: "default"}},
+		},
+	}, nil)
+
+	leftovers, err := k.FindLeftovers(ctx, "default", "percona-operators-group")
+	require.NoError(t, err)
+	require.Len(t, leftovers, 3)
+
+	assert.Equal(t, LeftoverKindCRD, leftovers[0].Kind)
+	assert.Equal(t, "perconaxtradbclusters.pxc.percona.com", leftovers[0].Name)
+	assert.False(t, leftovers[0].Removable)
+
+	assert.Equal(t, LeftoverKindCSV, leftovers[1].Kind)
+	assert.Equal(t, "percona-xtradb-cluster-operator.v1.12.0", leftovers[1].Name)
+	assert.True(t, leftovers[1].Removable)
+
+	assert.Equal(t, LeftoverKindOperatorGroup, leftovers[2].Kind)
+	assert.Equal(t, "old-group", leftovers[2].Name)
+
+	k8sclient.On("DeleteObject", mock.Anything, mock.Anything).Return(nil)
+	require.NoError(t, k.CleanupLeftovers(ctx, leftovers))
+	k8sclient.AssertNumberOfCalls(t, "DeleteObject", 2)
+}
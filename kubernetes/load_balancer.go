@@ -0,0 +1,62 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Annotations of LoadBalancer services understood by the cloud providers.
+const (
+	awsLoadBalancerTypeAnnotation       = "service.beta.kubernetes.io/aws-load-balancer-type"
+	awsLoadBalancerSchemeAnnotation     = "service.beta.kubernetes.io/aws-load-balancer-scheme"
+	awsLoadBalancerInternalAnnotation   = "service.beta.kubernetes.io/aws-load-balancer-internal"
+	gkeLoadBalancerTypeAnnotation       = "networking.gke.io/load-balancer-type"
+	azureLoadBalancerInternalAnnotation = "service.beta.kubernetes.io/azure-load-balancer-internal"
+)
+
+// LoadBalancerAnnotations returns the annotations making the cloud provider create a load
+// balancer for a database cluster. Internal load balancers are reachable only from the
+// network of the cluster, others from the internet. On EKS a network load balancer is
+// created instead of a classic one since it passes TCP connections through with a lower
+// latency. Other cluster types get no annotations and do not support internal load balancers.
+func LoadBalancerAnnotations(clusterType ClusterType, internal bool) (map[string]string, error) {
+	switch clusterType {
+	case ClusterTypeEKS:
+		annotations := map[string]string{awsLoadBalancerTypeAnnotation: "nlb"}
+		if internal {
+			annotations[awsLoadBalancerSchemeAnnotation] = "internal"
+			annotations[awsLoadBalancerInternalAnnotation] = "true"
+		} else {
+			annotations[awsLoadBalancerSchemeAnnotation] = "internet-facing"
+		}
+		return annotations, nil
+	case ClusterTypeGKE:
+		if internal {
+			return map[string]string{gkeLoadBalancerTypeAnnotation: "Internal"}, nil
+		}
+		return map[string]string{}, nil
+	case ClusterTypeAKS:
+		if internal {
+			return map[string]string{azureLoadBalancerInternalAnnotation: "true"}, nil
+		}
+		return map[string]string{}, nil
+	}
+	if internal {
+		return nil, errors.Errorf("internal load balancers are not supported on %s clusters", clusterType)
+	}
+	return map[string]string{}, nil
+}
@@ -0,0 +1,49 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBalancerAnnotations(t *testing.T) {
+	t.Parallel()
+	annotations, err := LoadBalancerAnnotations(ClusterTypeEKS, true)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		awsLoadBalancerTypeAnnotation:     "nlb",
+		awsLoadBalancerSchemeAnnotation:   "internal",
+		awsLoadBalancerInternalAnnotation: "true",
+	}, annotations)
+
+	annotations, err = LoadBalancerAnnotations(ClusterTypeEKS, false)
+	require.NoError(t, err)
+	assert.Equal(t, "internet-facing", annotations[awsLoadBalancerSchemeAnnotation])
+
+	annotations, err = LoadBalancerAnnotations(ClusterTypeGKE, true)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{gkeLoadBalancerTypeAnnotation: "Internal"}, annotations)
+
+	annotations, err = LoadBalancerAnnotations(ClusterTypeAKS, false)
+	require.NoError(t, err)
+	assert.Empty(t, annotations)
+
+	_, err = LoadBalancerAnnotations(ClusterTypeGeneric, true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,233 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// LockName is the name of the lease serializing the mutating commands run against a cluster.
+	LockName = "everest-provisioner-lock"
+	// DefaultLockDuration is how long the lock is held without being renewed, e.g. after its
+	// holder has crashed, before another run may take it over.
+	DefaultLockDuration = time.Minute
+
+	lockCommandAnnotation = "everest.percona.com/command"
+)
+
+// ErrLocked is returned by AcquireLock if another run holds the lock.
+var ErrLocked = errors.New("the cluster is locked by another run")
+
+// LockedError describes the run holding the lock. It wraps ErrLocked.
+type LockedError struct {
+	Holder  string
+	Command string
+	Since   time.Time
+	// Expires is when the lock may be taken over unless it is renewed.
+	Expires time.Time
+}
+
+// Error implements the error interface.
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("the cluster is locked by %s running %s until %s", e.Holder, e.Command, e.Expires.Format(time.RFC3339))
+}
+
+// Unwrap returns ErrLocked.
+func (e *LockedError) Unwrap() error {
+	return ErrLocked
+}
+
+// LockOptions configures the lock acquired by AcquireLock.
+type LockOptions struct {
+	// Identity of the run, e.g. user@host/pid.
+	Identity string
+	// Command is recorded in the lease to tell other runs what holds the lock.
+	Command string
+	// Duration the lock is held without being renewed, DefaultLockDuration if zero.
+	Duration time.Duration
+	// Force takes the lock over even if another run holds it.
+	Force bool
+}
+
+// Lock is a lock held by the run. It is renewed in the background until it is released.
+type Lock struct {
+	k        *Kubernetes
+	duration time.Duration
+	// Previous is the holder the lock was taken over from because it expired or it was
+	// forced, empty if the lock was free.
+	Previous string
+
+	mu     sync.Mutex
+	lease  *coordinationv1.Lease
+	cancel context.CancelFunc
+	done   chan struct{}
+	lost   chan struct{}
+}
+
+// AcquireLock acquires the lock of the cluster, a coordination.k8s.io Lease in the namespace
+// of the client. A LockedError is returned if another run holds it and it has not expired.
+// Once acquired, the lock is renewed until it is released; Lost is closed if it cannot be
+// renewed or another run has taken it over.
+func (k *Kubernetes) AcquireLock(ctx context.Context, opts LockOptions) (*Lock, error) {
+	if opts.Duration == 0 {
+		opts.Duration = DefaultLockDuration
+	}
+	namespace := k.client.Namespace()
+	now := metav1.NewMicroTime(time.Now())
+	lock := &Lock{k: k, duration: opts.Duration}
+
+	lease, err := k.client.GetLease(ctx, namespace, LockName)
+	switch {
+	case apierrors.IsNotFound(err):
+		lease = &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: LockName, Namespace: namespace}}
+		holdLease(lease, opts, now)
+		lease, err = k.client.CreateLease(ctx, lease)
+	case err != nil:
+		return nil, classifyError(errors.Wrap(err, "cannot get the lock"))
+	default:
+		holder := pointer.GetString(lease.Spec.HolderIdentity)
+		if holder != "" && holder != opts.Identity {
+			if !opts.Force && !leaseExpired(lease, now.Time) {
+				return nil, lockedError(lease)
+			}
+			lock.Previous = holder
+		}
+		if holder != opts.Identity {
+			lease.Spec.LeaseTransitions = pointer.ToInt32(pointer.GetInt32(lease.Spec.LeaseTransitions) + 1)
+		}
+		holdLease(lease, opts, now)
+		lease, err = k.client.UpdateLease(ctx, lease)
+	}
+	if apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err) {
+		// Another run has acquired the lock since it was read.
+		if lease, err := k.client.GetLease(ctx, namespace, LockName); err == nil {
+			return nil, lockedError(lease)
+		}
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, "cannot acquire the lock"))
+	}
+
+	lock.lease = lease
+	renewCtx, cancel := context.WithCancel(context.Background())
+	lock.cancel = cancel
+	lock.done = make(chan struct{})
+	lock.lost = make(chan struct{})
+	go lock.renew(renewCtx)
+	return lock, nil
+}
+
+// Lost is closed if the lock could not be renewed before it expired or another run has
+// taken it over.
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Release stops renewing the lock and deletes the lease unless another run has taken it over.
+func (l *Lock) Release(ctx context.Context) error {
+	l.cancel()
+	<-l.done
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := l.k.client.DeleteLease(ctx, l.lease)
+	if err == nil || apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return nil
+	}
+	return classifyError(errors.Wrap(err, "cannot release the lock"))
+}
+
+// renew renews the lock a few times per duration until the context is done. Lost is closed
+// and renewing stops if the lease has been changed by another run or the lock has expired.
+func (l *Lock) renew(ctx context.Context) {
+	defer close(l.done)
+	ticker := time.NewTicker(l.duration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		l.mu.Lock()
+		lease := l.lease.DeepCopy()
+		lease.Spec.RenewTime = &metav1.MicroTime{Time: time.Now()}
+		updated, err := l.k.client.UpdateLease(ctx, lease)
+		switch {
+		case err == nil:
+			l.lease = updated
+		case ctx.Err() != nil:
+		case apierrors.IsConflict(err) || apierrors.IsNotFound(err) || leaseExpired(l.lease, time.Now()):
+			l.k.l.Errorf("lost the lock: %v", err)
+			close(l.lost)
+			l.mu.Unlock()
+			return
+		default:
+			l.k.l.Warnf("failed renewing the lock: %v", err)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// holdLease makes the run of the options the holder of the lease from now on.
+func holdLease(lease *coordinationv1.Lease, opts LockOptions, now metav1.MicroTime) {
+	if lease.Annotations == nil {
+		lease.Annotations = map[string]string{}
+	}
+	lease.Annotations[lockCommandAnnotation] = opts.Command
+	lease.Spec.HolderIdentity = pointer.ToString(opts.Identity)
+	lease.Spec.LeaseDurationSeconds = pointer.ToInt32(int32(opts.Duration / time.Second))
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+}
+
+// leaseExpired returns true if the lease has not been renewed within its duration.
+func leaseExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	return now.After(leaseExpiry(lease))
+}
+
+// leaseExpiry returns when the lease expires unless it is renewed.
+func leaseExpiry(lease *coordinationv1.Lease) time.Time {
+	return lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+}
+
+// lockedError describes the holder of the lease.
+func lockedError(lease *coordinationv1.Lease) error {
+	e := &LockedError{
+		Holder:  pointer.GetString(lease.Spec.HolderIdentity),
+		Command: lease.Annotations[lockCommandAnnotation],
+	}
+	if lease.Spec.AcquireTime != nil {
+		e.Since = lease.Spec.AcquireTime.Time
+	}
+	if lease.Spec.RenewTime != nil && lease.Spec.LeaseDurationSeconds != nil {
+		e.Expires = leaseExpiry(lease)
+	}
+	return e
+}
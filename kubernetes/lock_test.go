@@ -0,0 +1,98 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAcquireLock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c, err := fake.NewKubeClient("everest")
+	require.NoError(t, err)
+	k := NewWithClient(c)
+
+	first, err := k.AcquireLock(ctx, LockOptions{Identity: "alice@laptop/1", Command: "install"})
+	require.NoError(t, err)
+	assert.Empty(t, first.Previous)
+
+	_, err = k.AcquireLock(ctx, LockOptions{Identity: "bob@laptop/2", Command: "upgrade"})
+	require.ErrorIs(t, err, ErrLocked)
+	var locked *LockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Equal(t, "alice@laptop/1", locked.Holder)
+	assert.Equal(t, "install", locked.Command)
+
+	forced, err := k.AcquireLock(ctx, LockOptions{Identity: "bob@laptop/2", Command: "upgrade", Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@laptop/1", forced.Previous)
+	lease, err := c.GetLease(ctx, "everest", LockName)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), pointer.GetInt32(lease.Spec.LeaseTransitions))
+
+	// The first run notices the takeover when it renews the lock and keeps the lease on release.
+	first.duration = 30 * time.Millisecond
+	first.cancel()
+	<-first.done
+	renewCtx, cancel := context.WithCancel(context.Background())
+	first.cancel, first.done = cancel, make(chan struct{})
+	go first.renew(renewCtx)
+	select {
+	case <-first.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("the lock was not lost")
+	}
+	require.NoError(t, first.Release(ctx))
+	_, err = c.GetLease(ctx, "everest", LockName)
+	require.NoError(t, err)
+
+	require.NoError(t, forced.Release(ctx))
+	_, err = c.GetLease(ctx, "everest", LockName)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestAcquireExpiredLock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c, err := fake.NewKubeClient("everest")
+	require.NoError(t, err)
+	k := NewWithClient(c)
+
+	stale, err := k.AcquireLock(ctx, LockOptions{Identity: "alice@laptop/1", Command: "install", Duration: time.Second})
+	require.NoError(t, err)
+	stale.cancel()
+	<-stale.done
+	lease, err := c.GetLease(ctx, "everest", LockName)
+	require.NoError(t, err)
+	lease.Spec.RenewTime = &metav1.MicroTime{Time: time.Now().Add(-time.Minute)}
+	_, err = c.UpdateLease(ctx, lease)
+	require.NoError(t, err)
+
+	lock, err := k.AcquireLock(ctx, LockOptions{Identity: "bob@laptop/2", Command: "upgrade"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@laptop/1", lock.Previous)
+	require.NoError(t, lock.Release(ctx))
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/gen1us2k/everest-provisioner/pkg/metrics"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// instrumentedClient records the applied objects in the metrics of the provisioner.
+type instrumentedClient struct {
+	client.KubeClientConnector
+}
+
+// ApplyObject applies the object and records the result and the duration.
+func (c *instrumentedClient) ApplyObject(ctx context.Context, obj runtime.Object) error {
+	started := time.Now()
+	err := c.KubeClientConnector.ApplyObject(ctx, obj)
+	metrics.ObserveApply(obj.GetObjectKind().GroupVersionKind().Kind, started, err)
+	return err
+}
+
+// ServerSideApply applies the object and records the result and the duration.
+func (c *instrumentedClient) ServerSideApply(ctx context.Context, obj runtime.Object) error {
+	started := time.Now()
+	err := c.KubeClientConnector.ServerSideApply(ctx, obj)
+	metrics.ObserveApply(obj.GetObjectKind().GroupVersionKind().Kind, started, err)
+	return err
+}
+
+// ThreeWayMerge applies the object and records the result and the duration.
+func (c *instrumentedClient) ThreeWayMerge(ctx context.Context, obj runtime.Object) error {
+	started := time.Now()
+	err := c.KubeClientConnector.ThreeWayMerge(ctx, obj)
+	metrics.ObserveApply(obj.GetObjectKind().GroupVersionKind().Kind, started, err)
+	return err
+}
+
+// ApplyFile applies the objects of the manifest one by one so every object is recorded.
+func (c *instrumentedClient) ApplyFile(ctx context.Context, fileBytes []byte) error {
+	objs, err := client.DecodeObjects(fileBytes)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if err := c.ApplyObject(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnableMetrics records the objects applied from now on in the metrics of the provisioner.
+func (k *Kubernetes) EnableMetrics() {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if _, ok := k.client.(*instrumentedClient); ok {
+		return
+	}
+	k.client = &instrumentedClient{KubeClientConnector: k.client}
+}
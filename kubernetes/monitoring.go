@@ -0,0 +1,135 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// scrapeLabelKey marks the scrape objects picked up by the VM agent in the selective monitoring mode.
+	scrapeLabelKey = "dbaas.percona.com/scrape"
+
+	databaseClusterPodScrapePrefix = "dbaas-"
+)
+
+// restrictScrapeSelectors makes the VM agent scrape only the objects labeled with scrapeLabelKey
+// instead of every service and pod scrape of the cluster. Node metrics are scraped as before.
+func restrictScrapeSelectors(spec *victoriametricsv1beta1.VMAgentSpec) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{scrapeLabelKey: "true"}}
+	spec.SelectAllByDefault = false
+	spec.ServiceScrapeSelector = selector
+	spec.PodScrapeSelector = selector
+	spec.NodeScrapeSelector = &metav1.LabelSelector{}
+	spec.NodeScrapeNamespaceSelector = &metav1.LabelSelector{}
+}
+
+// EnableDatabaseClusterMonitoring creates a pod scrape for the pods of the database cluster.
+// It is required for the cluster to be monitored in the selective monitoring mode.
+// The pod scrape is owned by the cluster and is removed together with it.
+// If instance is set, the cluster reports to the monitoring instance instead.
+func (k *Kubernetes) EnableDatabaseClusterMonitoring(ctx context.Context, name, instance string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if instance != "" {
+		if err := k.monitoringInstanceExists(ctx, instance); err != nil {
+			return classifyError(err)
+		}
+	}
+	cluster, err := k.client.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return classifyError(err)
+	}
+	scrape := databaseClusterPodScrape(cluster)
+	if instance != "" {
+		scrape.Labels = map[string]string{monitoringInstanceLabelKey: instance}
+	} else {
+		k.labelScrape(scrape, scrape.Kind)
+	}
+	if err := k.client.ApplyObject(ctx, scrape); err != nil {
+		return classifyError(errors.Wrapf(err, "cannot enable monitoring of database cluster %s", name))
+	}
+	return classifyError(k.setMonitoringInstance(ctx, cluster, instance))
+}
+
+// DisableDatabaseClusterMonitoring removes the pod scrape of the database cluster.
+func (k *Kubernetes) DisableDatabaseClusterMonitoring(ctx context.Context, name string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	cluster, err := k.client.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return classifyError(err)
+	}
+	err = k.client.DeleteObject(ctx, databaseClusterPodScrape(cluster))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return classifyError(errors.Wrapf(err, "cannot disable monitoring of database cluster %s", name))
+	}
+	return classifyError(k.setMonitoringInstance(ctx, cluster, ""))
+}
+
+// setMonitoringInstance records the monitoring instance the cluster reports to so instances
+// in use are not deleted. The annotation is removed if instance is empty.
+func (k *Kubernetes) setMonitoringInstance(ctx context.Context, cluster *dbaasv1.DatabaseCluster, instance string) error {
+	if cluster.Annotations[monitoringInstanceLabelKey] == instance {
+		return nil
+	}
+	if instance == "" {
+		delete(cluster.Annotations, monitoringInstanceLabelKey)
+	} else {
+		if cluster.Annotations == nil {
+			cluster.Annotations = make(map[string]string)
+		}
+		cluster.Annotations[monitoringInstanceLabelKey] = instance
+	}
+	cluster.TypeMeta.APIVersion = databaseClusterAPIVersion
+	cluster.TypeMeta.Kind = databaseClusterKind
+	return errors.Wrapf(k.client.ApplyObject(ctx, cluster), "cannot update database cluster %s", cluster.Name)
+}
+
+func databaseClusterPodScrape(cluster *dbaasv1.DatabaseCluster) *victoriametricsv1beta1.VMPodScrape {
+	return &victoriametricsv1beta1.VMPodScrape{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "operator.victoriametrics.com/v1beta1",
+			Kind:       "VMPodScrape",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      databaseClusterPodScrapePrefix + cluster.Name,
+			Namespace: cluster.Namespace,
+			Labels:    map[string]string{scrapeLabelKey: "true"},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: databaseClusterAPIVersion,
+				Kind:       databaseClusterKind,
+				Name:       cluster.Name,
+				UID:        cluster.UID,
+			}},
+		},
+		Spec: victoriametricsv1beta1.VMPodScrapeSpec{
+			PodMetricsEndpoints: []victoriametricsv1beta1.PodMetricsEndpoint{{
+				Port:   "metrics",
+				Scheme: "http",
+			}},
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{instanceLabelKey: cluster.Name},
+			},
+		},
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AlekSi/pointer"
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	vmv1beta1 "github.com/VictoriaMetrics/operator/api/victoriametrics/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	alertRulesName      = "everest-database-alerts"
+	vmAlertName         = "everest-alerts"
+	volumeStatsScrape   = "pmm-vm-kubelet-volume-stats"
+	alertRulesLabelKey  = "everest.percona.com/alerts"
+	pmmAlertmanagerPath = "/alertmanager"
+	pmmQueryPath        = "/victoriametrics"
+
+	// DefaultAlertEvaluationInterval is how often the alerting rules are evaluated by default.
+	DefaultAlertEvaluationInterval = "1m"
+)
+
+// AlertingOptions configures the alerting provisioned by ProvisionAlerting.
+type AlertingOptions struct {
+	// AlertmanagerURLs receive the alerts. The Alertmanager of PMM is used if it is empty.
+	AlertmanagerURLs []string
+	// EvaluationInterval is how often the rules are evaluated, DefaultAlertEvaluationInterval if empty.
+	EvaluationInterval string
+}
+
+// ProvisionAlerting installs the alerting rules of database clusters and a VMAlert evaluating
+// them against the metrics in PMM. PMM is queried with the credentials of the VM agent writing
+// to it, so alerting must be provisioned again once the agent is replaced. Alerts are sent to
+// the Alertmanager of PMM unless Alertmanager URLs are set and their state is written to PMM.
+func (k *Kubernetes) ProvisionAlerting(ctx context.Context, opts AlertingOptions) (Warnings, error) {
+	agent, err := k.pmmVMAgent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return k.applyStackObjects(ctx, alertingStack(k.client.Namespace(), agent, opts))
+}
+
+// RemoveAlerting removes the objects applied by ProvisionAlerting. Missing objects are ignored.
+func (k *Kubernetes) RemoveAlerting(ctx context.Context) error {
+	return k.deleteStackObjects(ctx, alertingStack(k.client.Namespace(), nil, AlertingOptions{}))
+}
+
+// pmmVMAgent returns the VM agent writing metrics to PMM.
+func (k *Kubernetes) pmmVMAgent(ctx context.Context) (*victoriametricsv1beta1.VMAgent, error) {
+	list, err := k.client.ListVMAgents(ctx, useDefaultNamespace, nil)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, ErrMonitoringNotProvisioned
+		}
+		return nil, classifyError(errors.Wrap(err, "cannot list VM agents"))
+	}
+	for i, agent := range list.Items {
+		if strings.HasPrefix(agent.Name, vmAgentNamePrefix) && len(agent.Spec.RemoteWrite) != 0 {
+			return convertVMAgent(&list.Items[i])
+		}
+	}
+	return nil, ErrMonitoringNotProvisioned
+}
+
+// convertVMAgent converts a VM agent returned by the client to the API types the monitoring
+// stack is built with. Both packages declare the same custom resource.
+func convertVMAgent(agent *vmv1beta1.VMAgent) (*victoriametricsv1beta1.VMAgent, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(agent)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot convert VM agent")
+	}
+	converted := &victoriametricsv1beta1.VMAgent{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, converted); err != nil {
+		return nil, errors.Wrap(err, "cannot convert VM agent")
+	}
+	return converted, nil
+}
+
+// alertingStack returns the objects applied by ProvisionAlerting: the scrape of the volume
+// statistics of the kubelets, the alerting rules and the VMAlert evaluating them. Only the
+// names of the objects are set if agent is nil.
+func alertingStack(namespace string, agent *victoriametricsv1beta1.VMAgent, opts AlertingOptions) []runtime.Object {
+	scrape := &victoriametricsv1beta1.VMNodeScrape{
+		TypeMeta:   metav1.TypeMeta{APIVersion: victoriametricsv1beta1.GroupVersion.String(), Kind: "VMNodeScrape"},
+		ObjectMeta: metav1.ObjectMeta{Name: volumeStatsScrape},
+	}
+	rules := &victoriametricsv1beta1.VMRule{
+		TypeMeta:   metav1.TypeMeta{APIVersion: victoriametricsv1beta1.GroupVersion.String(), Kind: "VMRule"},
+		ObjectMeta: metav1.ObjectMeta{Name: alertRulesName, Namespace: namespace},
+	}
+	alert := &victoriametricsv1beta1.VMAlert{
+		TypeMeta:   metav1.TypeMeta{APIVersion: victoriametricsv1beta1.GroupVersion.String(), Kind: "VMAlert"},
+		ObjectMeta: metav1.ObjectMeta{Name: vmAlertName, Namespace: namespace},
+	}
+	if agent != nil {
+		scrape.Spec = volumeStatsScrapeSpec()
+		rules.Labels = map[string]string{alertRulesLabelKey: "true"}
+		rules.Spec = databaseAlertRules(namespace)
+		alert.Spec = vmAlertSpec(agent, opts)
+	}
+	return []runtime.Object{scrape, rules, alert}
+}
+
+// volumeStatsScrapeSpec scrapes the usage of persistent volumes from the kubelets through the
+// API server. Only the volume statistics are kept.
+func volumeStatsScrapeSpec() victoriametricsv1beta1.VMNodeScrapeSpec {
+	return victoriametricsv1beta1.VMNodeScrapeSpec{
+		Scheme:        "https",
+		Interval:      "60s",
+		ScrapeTimeout: "10s",
+		TLSConfig: &victoriametricsv1beta1.TLSConfig{
+			InsecureSkipVerify: true,
+			CAFile:             serviceAccountDir + "/ca.crt",
+		},
+		BearerTokenFile: serviceAccountDir + "/token",
+		RelabelConfigs: []*victoriametricsv1beta1.RelabelConfig{
+			{TargetLabel: "__address__", Replacement: "kubernetes.default.svc:443"},
+			{
+				SourceLabels: []string{"__meta_kubernetes_node_name"},
+				Regex:        "(.+)",
+				TargetLabel:  "__metrics_path__",
+				Replacement:  "/api/v1/nodes/$1/proxy/metrics",
+			},
+		},
+		MetricRelabelConfigs: []*victoriametricsv1beta1.RelabelConfig{
+			{Action: "keep", SourceLabels: []string{"__name__"}, Regex: "kubelet_volume_stats_.+"},
+		},
+	}
+}
+
+// databaseAlertRules returns the curated alerting rules of the database clusters in the
+// namespace: replication lag of the engines, nearly full volumes and restarting pods.
+func databaseAlertRules(namespace string) victoriametricsv1beta1.VMRuleSpec {
+	rule := func(name, expr, duration, severity, summary string) victoriametricsv1beta1.Rule {
+		return victoriametricsv1beta1.Rule{
+			Alert:       name,
+			Expr:        expr,
+			For:         duration,
+			Labels:      map[string]string{"severity": severity},
+			Annotations: map[string]string{"summary": summary},
+		}
+	}
+	return victoriametricsv1beta1.VMRuleSpec{
+		Groups: []victoriametricsv1beta1.RuleGroup{
+			{
+				Name: "database-replication",
+				Rules: []victoriametricsv1beta1.Rule{
+					rule("MySQLReplicationLag", `mysql_slave_status_seconds_behind_master > 300`, "5m", "warning",
+						"MySQL replica {{ $labels.service_name }} is {{ $value }}s behind its source"),
+					rule("MongoDBReplicationLag", `mongodb_mongod_replset_member_replication_lag > 300`, "5m", "warning",
+						"MongoDB member {{ $labels.name }} of {{ $labels.set }} is {{ $value }}s behind the primary"),
+					rule("PostgreSQLReplicationLag", `pg_replication_lag > 300`, "5m", "warning",
+						"PostgreSQL replica {{ $labels.service_name }} is {{ $value }}s behind the primary"),
+				},
+			},
+			{
+				Name: "database-storage",
+				Rules: []victoriametricsv1beta1.Rule{
+					rule("DatabaseVolumeNearlyFull",
+						fmt.Sprintf(`kubelet_volume_stats_available_bytes{namespace=%[1]q} / kubelet_volume_stats_capacity_bytes{namespace=%[1]q} < 0.1`, namespace),
+						"5m", "critical",
+						"Volume {{ $labels.persistentvolumeclaim }} has less than 10% free space"),
+				},
+			},
+			{
+				Name: "database-pods",
+				Rules: []victoriametricsv1beta1.Rule{
+					rule("DatabasePodRestarting",
+						fmt.Sprintf(`increase(kube_pod_container_status_restarts_total{namespace=%q}[15m]) > 3`, namespace),
+						"", "warning",
+						"Container {{ $labels.container }} of pod {{ $labels.pod }} restarted {{ $value }} times in 15 minutes"),
+				},
+			},
+		},
+	}
+}
+
+// vmAlertSpec returns the spec of the VMAlert evaluating the alerting rules against the PMM
+// the agent writes to, with the credentials and certificates of the agent.
+func vmAlertSpec(agent *victoriametricsv1beta1.VMAgent, opts AlertingOptions) victoriametricsv1beta1.VMAlertSpec {
+	remoteWrite := agent.Spec.RemoteWrite[0]
+	address := strings.TrimSuffix(remoteWrite.URL, remoteWriteURL(""))
+	auth := victoriametricsv1beta1.HTTPAuth{BasicAuth: remoteWrite.BasicAuth, TLSConfig: remoteWrite.TLSConfig}
+
+	var notifiers []victoriametricsv1beta1.VMAlertNotifierSpec
+	for _, url := range opts.AlertmanagerURLs {
+		notifiers = append(notifiers, victoriametricsv1beta1.VMAlertNotifierSpec{URL: url})
+	}
+	if len(notifiers) == 0 {
+		notifiers = append(notifiers, victoriametricsv1beta1.VMAlertNotifierSpec{URL: address + pmmAlertmanagerPath, HTTPAuth: auth})
+	}
+	interval := opts.EvaluationInterval
+	if interval == "" {
+		interval = DefaultAlertEvaluationInterval
+	}
+	spec := victoriametricsv1beta1.VMAlertSpec{
+		ReplicaCount:       pointer.ToInt32(1),
+		EvaluationInterval: interval,
+		RuleSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{alertRulesLabelKey: "true"}},
+		Datasource:         victoriametricsv1beta1.VMAlertDatasourceSpec{URL: address + pmmQueryPath, HTTPAuth: auth},
+		RemoteWrite:        &victoriametricsv1beta1.VMAlertRemoteWriteSpec{URL: address + pmmQueryPath, HTTPAuth: auth},
+		RemoteRead:         &victoriametricsv1beta1.VMAlertRemoteReadSpec{URL: address + pmmQueryPath, HTTPAuth: auth},
+		Notifiers:          notifiers,
+		ImagePullSecrets:   agent.Spec.ImagePullSecrets,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("50m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("200m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+		},
+	}
+	// The annotations make the secrets backend inject the password file of the agent.
+	if agent.Spec.PodMetadata != nil {
+		spec.PodMetadata = agent.Spec.PodMetadata.DeepCopy()
+	}
+	return spec
+}
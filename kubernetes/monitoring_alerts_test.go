@@ -0,0 +1,73 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestProvisionAlerting(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	agent := vmAgentSpec("vm-operator-1", "https://pmm.example.com", MonitoringTLS{})
+	k8sclient.On("Namespace").Return("everest")
+	k8sclient.On("ListVMAgents", ctx, useDefaultNamespace, map[string]string(nil)).Return(vmAgentList(t, agent), nil).Once()
+	applied := map[string]*unstructured.Unstructured{}
+	k8sclient.On("ServerSideApply", ctx, mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+		obj := args.Get(1).(*unstructured.Unstructured)
+		applied[obj.GetKind()+"/"+obj.GetName()] = obj
+	})
+
+	warnings, err := k.ProvisionAlerting(ctx, AlertingOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	require.Len(t, applied, 3)
+
+	rules := applied["VMRule/"+alertRulesName]
+	require.NotNil(t, rules)
+	assert.Equal(t, "true", rules.GetLabels()[alertRulesLabelKey])
+
+	alert := applied["VMAlert/"+vmAlertName]
+	require.NotNil(t, alert)
+	datasource, _, _ := unstructured.NestedString(alert.Object, "spec", "datasource", "url")
+	assert.Equal(t, "https://pmm.example.com/victoriametrics", datasource)
+	secret, _, _ := unstructured.NestedString(alert.Object, "spec", "datasource", "basicAuth", "password", "name")
+	assert.Equal(t, "vm-operator-1", secret)
+	notifiers, _, _ := unstructured.NestedSlice(alert.Object, "spec", "notifiers")
+	require.Len(t, notifiers, 1)
+	assert.Equal(t, "https://pmm.example.com/alertmanager", notifiers[0].(map[string]interface{})["url"])
+
+	k8sclient.On("ListVMAgents", ctx, useDefaultNamespace, map[string]string(nil)).Return(vmAgentList(t, agent), nil).Once()
+	_, err = k.ProvisionAlerting(ctx, AlertingOptions{AlertmanagerURLs: []string{"http://alertmanager:9093"}})
+	require.NoError(t, err)
+	notifiers, _, _ = unstructured.NestedSlice(applied["VMAlert/"+vmAlertName].Object, "spec", "notifiers")
+	require.Len(t, notifiers, 1)
+	assert.Equal(t, map[string]interface{}{"url": "http://alertmanager:9093"}, notifiers[0])
+
+	k8sclient.On("ListVMAgents", ctx, useDefaultNamespace, map[string]string(nil)).Return(vmAgentList(t), nil).Once()
+	_, err = k.ProvisionAlerting(ctx, AlertingOptions{})
+	assert.ErrorIs(t, err, ErrMonitoringNotProvisioned)
+}
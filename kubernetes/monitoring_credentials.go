@@ -0,0 +1,65 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// MonitoringCredentials are the credentials the VM agent writes metrics to PMM with.
+type MonitoringCredentials struct {
+	Username string
+	Password string
+	// Secret names an existing secret holding the username and password keys.
+	// It is used instead of creating a secret from Username and Password, which
+	// keeps the credentials managed outside of the provisioner.
+	Secret string
+	// RotationPeriod is how often credentials created from a PMM API key are replaced
+	// with a new key. They are not rotated if it is zero.
+	RotationPeriod time.Duration
+	// Provided is true if the password is an API key supplied by the user rather than one
+	// created by the provisioner. Provided keys are never rotated or revoked.
+	Provided bool
+}
+
+// checkCredentialsSecret returns an error if the secret does not exist or misses credentials.
+func (k *Kubernetes) checkCredentialsSecret(ctx context.Context, name string) error {
+	secret, err := k.client.GetSecret(ctx, name)
+	if err != nil {
+		return classifyError(errors.Wrapf(err, "cannot get PMM credentials secret %s", name))
+	}
+	for _, key := range []string{corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey} {
+		if len(secret.Data[key]) == 0 {
+			return errors.Errorf("PMM credentials secret %s has no %s", name, key)
+		}
+	}
+	return nil
+}
+
+// useCredentialsSecret makes the remote writes of the VM agent authenticate with the secret.
+func useCredentialsSecret(spec *victoriametricsv1beta1.VMAgentSpec, name string) {
+	for i := range spec.RemoteWrite {
+		if auth := spec.RemoteWrite[i].BasicAuth; auth != nil {
+			auth.Username.Name = name
+			auth.Password.Name = name
+		}
+	}
+}
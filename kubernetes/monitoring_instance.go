@@ -0,0 +1,186 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// monitoringInstanceLabelKey marks the secrets and VM agents of monitoring instances and the
+	// pod scrapes picked up by them. The annotation with the same key on a database cluster
+	// names the instance the cluster reports to.
+	monitoringInstanceLabelKey = "dbaas.percona.com/monitoring-instance"
+	// monitoringInstancePrefix prefixes the names of the secret and the VM agent of an instance.
+	monitoringInstancePrefix = "everest-monitoring-"
+)
+
+// MonitoringInstance is a named PMM server database clusters can report to
+// instead of the one configured on installation.
+type MonitoringInstance struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	// Password is not returned by ListMonitoringInstances.
+	Password string `json:"-"`
+	// TLS configures the connection to the PMM server. It is not returned by ListMonitoringInstances.
+	TLS MonitoringTLS `json:"-"`
+}
+
+// CreateMonitoringInstance creates or updates the secret with the credentials of the instance
+// and a VM agent writing the metrics of the database clusters assigned to it to the PMM server.
+func (k *Kubernetes) CreateMonitoringInstance(ctx context.Context, instance MonitoringInstance) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	name := monitoringInstancePrefix + instance.Name
+	labels := map[string]string{monitoringInstanceLabelKey: instance.Name}
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"url":      []byte(instance.URL),
+			"username": []byte(instance.Username),
+			"password": []byte(instance.Password),
+		},
+	}
+	if err := k.applySecret(ctx, secret); err != nil {
+		return classifyError(errors.Wrapf(err, "cannot create secret of monitoring instance %s", instance.Name))
+	}
+
+	if err := k.applyMonitoringTLS(ctx, name, instance.TLS, labels); err != nil {
+		return classifyError(err)
+	}
+
+	vmagent := vmAgentSpec(name, instance.URL, instance.TLS)
+	k.injectSecret(&vmagent.Spec, "", name)
+	vmagent.Name = name
+	vmagent.Labels = labels
+	selectInstanceScrapes(&vmagent.Spec, instance.Name)
+	if k.hardened {
+		restrictVMAgent(&vmagent.Spec)
+	}
+	vmagent.Spec.Resources = overrideResources(vmagent.Spec.Resources, k.monitoringResources.VMAgent)
+	scheduleVMAgent(&vmagent.Spec, k.scheduling)
+	vmagent.Spec.ImagePullSecrets = k.imagePullSecretRefs()
+	if err := k.client.ApplyObject(ctx, vmagent); err != nil {
+		return classifyError(errors.Wrapf(err, "cannot create VM agent of monitoring instance %s", instance.Name))
+	}
+	return nil
+}
+
+// ListMonitoringInstances returns the monitoring instances sorted by name.
+func (k *Kubernetes) ListMonitoringInstances(ctx context.Context) ([]MonitoringInstance, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	secrets, err := k.client.ListSecrets(ctx)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, "cannot list secrets"))
+	}
+	var instances []MonitoringInstance
+	for _, secret := range secrets.Items {
+		name, ok := secret.Labels[monitoringInstanceLabelKey]
+		if !ok || secret.Name != monitoringInstancePrefix+name {
+			continue
+		}
+		instances = append(instances, MonitoringInstance{
+			Name:     name,
+			URL:      string(secret.Data["url"]),
+			Username: string(secret.Data["username"]),
+		})
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].Name < instances[j].Name })
+	return instances, nil
+}
+
+// DeleteMonitoringInstance removes the VM agent and the secret of the instance. Instances
+// database clusters report to are not removed; the clusters must be moved first.
+func (k *Kubernetes) DeleteMonitoringInstance(ctx context.Context, name string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	clusters, err := k.client.ListDatabaseClusters(ctx)
+	if err != nil {
+		return classifyError(errors.Wrap(err, "cannot list database clusters"))
+	}
+	var users []string
+	for _, cluster := range clusters.Items {
+		if cluster.Annotations[monitoringInstanceLabelKey] == name {
+			users = append(users, cluster.Name)
+		}
+	}
+	if len(users) != 0 {
+		return errors.Errorf("monitoring instance %s is used by database clusters %s", name, strings.Join(users, ", "))
+	}
+
+	secret, err := k.client.GetSecret(ctx, monitoringInstancePrefix+name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return errors.Errorf("monitoring instance %s does not exist", name)
+		}
+		return classifyError(errors.Wrapf(err, "cannot get monitoring instance %s", name))
+	}
+	if err := k.client.DeleteVMAgent(ctx, secret.Namespace, secret.Name); err != nil && !apierrors.IsNotFound(err) {
+		return classifyError(errors.Wrapf(err, "cannot delete VM agent of monitoring instance %s", name))
+	}
+	if err := k.deleteMonitoringTLS(ctx, secret.Namespace, secret.Name); err != nil {
+		return classifyError(err)
+	}
+	if err := k.deleteSecret(ctx, secret.Namespace, secret.Name); err != nil {
+		return classifyError(errors.Wrapf(err, "cannot delete secret of monitoring instance %s", name))
+	}
+	return nil
+}
+
+// monitoringInstanceExists returns an error if the monitoring instance has not been created.
+func (k *Kubernetes) monitoringInstanceExists(ctx context.Context, name string) error {
+	_, err := k.client.GetSecret(ctx, monitoringInstancePrefix+name)
+	if apierrors.IsNotFound(err) {
+		return errors.Errorf("monitoring instance %s does not exist", name)
+	}
+	return err
+}
+
+// selectInstanceScrapes makes the VM agent of a monitoring instance scrape only the pods
+// of the database clusters assigned to the instance. Nodes are scraped by the main agent.
+func selectInstanceScrapes(spec *victoriametricsv1beta1.VMAgentSpec, instance string) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{monitoringInstanceLabelKey: instance}}
+	spec.SelectAllByDefault = false
+	spec.ServiceScrapeSelector = selector
+	spec.PodScrapeSelector = selector
+	spec.NodeScrapeSelector = nil
+	spec.NodeScrapeNamespaceSelector = nil
+	spec.ProbeSelector = nil
+	spec.ProbeNamespaceSelector = nil
+	spec.StaticScrapeSelector = nil
+	spec.StaticScrapeNamespaceSelector = nil
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestCreateMonitoringInstance(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	var agent *victoriametricsv1beta1.VMAgent
+	k8sclient.On("ApplyObject", mock.Anything, mock.AnythingOfType("*v1.Secret")).Return(nil)
+	k8sclient.On("DeleteObject", mock.Anything, mock.AnythingOfType("*v1.Secret")).Return(nil)
+	k8sclient.On("ApplyObject", mock.Anything, mock.AnythingOfType("*v1beta1.VMAgent")).Return(nil).Run(func(args mock.Arguments) {
+		agent = args.Get(1).(*victoriametricsv1beta1.VMAgent)
+	})
+	err := k.CreateMonitoringInstance(ctx, MonitoringInstance{
+		Name: "pmm-eu", URL: "https://pmm-eu.example.com", Username: "admin", Password: "secret",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, agent)
+	assert.Equal(t, "everest-monitoring-pmm-eu", agent.Name)
+	assert.Equal(t, "https://pmm-eu.example.com/victoriametrics/api/v1/write", agent.Spec.RemoteWrite[0].URL)
+	assert.Equal(t, "everest-monitoring-pmm-eu", agent.Spec.RemoteWrite[0].BasicAuth.Password.Name)
+	assert.Nil(t, agent.Spec.NodeScrapeSelector)
+
+	cluster := &dbaasv1.DatabaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}}
+	scrape := databaseClusterPodScrape(cluster)
+	selector, err := metav1.LabelSelectorAsSelector(agent.Spec.PodScrapeSelector)
+	require.NoError(t, err)
+	assert.False(t, selector.Matches(labels.Set(scrape.Labels)))
+	assert.True(t, selector.Matches(labels.Set{monitoringInstanceLabelKey: "pmm-eu"}))
+}
+
+func TestListMonitoringInstances(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	secret := func(name, instance string) corev1.Secret {
+		return corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{monitoringInstanceLabelKey: instance}},
+			Data: map[string][]byte{
+				"url":      []byte("https://" + instance + ".example.com"),
+				"username": []byte("admin"),
+				"password": []byte("secret"),
+			},
+		}
+	}
+	k8sclient.On("ListSecrets", ctx).Return(&corev1.SecretList{Items: []corev1.Secret{
+		secret("everest-monitoring-pmm-us", "pmm-us"),
+		{ObjectMeta: metav1.ObjectMeta{Name: "vm-operator-123"}},
+		secret("everest-monitoring-pmm-eu", "pmm-eu"),
+	}}, nil)
+
+	instances, err := k.ListMonitoringInstances(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []MonitoringInstance{
+		{Name: "pmm-eu", URL: "https://pmm-eu.example.com", Username: "admin"},
+		{Name: "pmm-us", URL: "https://pmm-us.example.com", Username: "admin"},
+	}, instances)
+}
+
+func TestDeleteMonitoringInstanceInUse(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	k8sclient.On("ListDatabaseClusters", ctx).Return(&dbaasv1.DatabaseClusterList{Items: []dbaasv1.DatabaseCluster{{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Annotations: map[string]string{monitoringInstanceLabelKey: "pmm-eu"}},
+	}}}, nil)
+	err := k.DeleteMonitoringInstance(ctx, "pmm-eu")
+	assert.ErrorContains(t, err, "used by database clusters db")
+	k8sclient.AssertNotCalled(t, "DeleteVMAgent", mock.Anything, mock.Anything, mock.Anything)
+}
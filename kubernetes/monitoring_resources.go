@@ -0,0 +1,163 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const kubeStateMetricsName = "kube-state-metrics"
+
+// MonitoringResources overrides the resource requests and limits of the monitoring stack.
+// Resources which are not set keep their defaults.
+type MonitoringResources struct {
+	VMAgent          corev1.ResourceRequirements
+	KubeStateMetrics corev1.ResourceRequirements
+}
+
+// DefaultVMAgentResources returns the resources of VM agents which are not overridden.
+func DefaultVMAgentResources() corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("250m"),
+			corev1.ResourceMemory: resource.MustParse("350Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("850Mi"),
+		},
+	}
+}
+
+// SetMonitoringResources overrides the resources of the VM agents and kube-state-metrics.
+func (k *Kubernetes) SetMonitoringResources(r MonitoringResources) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.monitoringResources = r
+}
+
+// overrideResources returns the defaults with the overrides applied. A limit lower than
+// the overridden request is raised to the request.
+func overrideResources(defaults, overrides corev1.ResourceRequirements) corev1.ResourceRequirements {
+	res := corev1.ResourceRequirements{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+	for name, q := range defaults.Requests {
+		res.Requests[name] = q
+	}
+	for name, q := range defaults.Limits {
+		res.Limits[name] = q
+	}
+	for name, q := range overrides.Requests {
+		res.Requests[name] = q
+		if limit, ok := res.Limits[name]; ok && limit.Cmp(q) < 0 {
+			if _, overridden := overrides.Limits[name]; !overridden {
+				res.Limits[name] = q
+			}
+		}
+	}
+	for name, q := range overrides.Limits {
+		res.Limits[name] = q
+	}
+	if len(res.Requests) == 0 {
+		res.Requests = nil
+	}
+	if len(res.Limits) == 0 {
+		res.Limits = nil
+	}
+	return res
+}
+
+// setKubeStateMetricsResources sets the resources of the kube-state-metrics container.
+func setKubeStateMetricsResources(obj *unstructured.Unstructured, overrides corev1.ResourceRequirements) error {
+	if obj.GetKind() != "Deployment" || obj.GetName() != kubeStateMetricsName {
+		return nil
+	}
+	if len(overrides.Requests) == 0 && len(overrides.Limits) == 0 {
+		return nil
+	}
+	path := []string{"spec", "template", "spec", "containers"}
+	containers, _, err := unstructured.NestedSlice(obj.Object, path...)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok || container["name"] != kubeStateMetricsName {
+			continue
+		}
+		var defaults corev1.ResourceRequirements
+		if current, found, _ := unstructured.NestedMap(container, "resources"); found {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(current, &defaults); err != nil {
+				return err
+			}
+		}
+		res := overrideResources(defaults, overrides)
+		resources, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&res)
+		if err != nil {
+			return err
+		}
+		container["resources"] = resources
+	}
+	return unstructured.SetNestedSlice(obj.Object, containers, path...)
+}
+
+// checkMonitoringResources verifies that the pods of the monitoring stack fit on a worker node.
+func (k *Kubernetes) checkMonitoringResources(ctx context.Context, _ string) PreflightCheck {
+	check := PreflightCheck{Name: "Monitoring resources"}
+	nodes, err := k.GetWorkerNodes(ctx)
+	if err != nil {
+		check.Message = err.Error()
+		return check
+	}
+	var maxCPU, maxMemory resource.Quantity
+	for _, node := range nodes {
+		if cpu := node.Status.Allocatable[corev1.ResourceCPU]; cpu.Cmp(maxCPU) > 0 {
+			maxCPU = cpu
+		}
+		if memory := node.Status.Allocatable[corev1.ResourceMemory]; memory.Cmp(maxMemory) > 0 {
+			maxMemory = memory
+		}
+	}
+	components := []struct {
+		name      string
+		resources corev1.ResourceRequirements
+	}{
+		{"vmagent", overrideResources(DefaultVMAgentResources(), k.monitoringResources.VMAgent)},
+		{kubeStateMetricsName, k.monitoringResources.KubeStateMetrics},
+	}
+	var oversized []string
+	for _, c := range components {
+		cpu, memory := c.resources.Requests[corev1.ResourceCPU], c.resources.Requests[corev1.ResourceMemory]
+		if cpu.Cmp(maxCPU) > 0 || memory.Cmp(maxMemory) > 0 {
+			oversized = append(oversized, fmt.Sprintf("%s requests %s CPU and %s memory", c.name, &cpu, &memory))
+		}
+	}
+	if len(oversized) != 0 {
+		check.Message = fmt.Sprintf("%s, the largest worker node has %s CPU and %s memory allocatable",
+			strings.Join(oversized, ", "), &maxCPU, &maxMemory)
+		check.Remediation = "Lower the requests in monitoring.resources of the configuration or add larger worker nodes"
+		return check
+	}
+	check.Passed = true
+	check.Message = fmt.Sprintf("monitoring stack fits on worker nodes with %s CPU and %s memory allocatable", &maxCPU, &maxMemory)
+	return check
+}
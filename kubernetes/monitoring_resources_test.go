@@ -0,0 +1,104 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestOverrideResources(t *testing.T) {
+	t.Parallel()
+	res := overrideResources(DefaultVMAgentResources(), corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	})
+	assert.Equal(t, "100m", res.Requests.Cpu().String())
+	assert.Equal(t, "1Gi", res.Requests.Memory().String())
+	assert.Equal(t, "500m", res.Limits.Cpu().String())
+	// The default limit is raised to the overridden request.
+	assert.Equal(t, "1Gi", res.Limits.Memory().String())
+}
+
+func TestSetKubeStateMetricsResources(t *testing.T) {
+	t.Parallel()
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "kube-state-metrics"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "kube-state-metrics"}},
+		}}},
+	}}
+	err := setKubeStateMetricsResources(deployment, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+	})
+	require.NoError(t, err)
+	containers, _, err := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"requests": map[string]interface{}{"memory": "64Mi"},
+		"limits":   map[string]interface{}{"memory": "256Mi"},
+	}, containers[0].(map[string]interface{})["resources"])
+}
+
+func TestCheckMonitoringResources(t *testing.T) {
+	ctx := context.Background()
+	nodes := &corev1.NodeList{Items: []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			}},
+		},
+	}}
+
+	t.Run("fits", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetNodes", ctx).Return(nodes, nil)
+
+		check := k.checkMonitoringResources(ctx, "default")
+		assert.True(t, check.Passed, check.Message)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k.SetMonitoringResources(MonitoringResources{VMAgent: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+		}})
+		k8sclient.On("GetNodes", ctx).Return(nodes, nil)
+
+		check := k.checkMonitoringResources(ctx, "default")
+		assert.False(t, check.Passed)
+		assert.Contains(t, check.Message, "vmagent requests 250m CPU and 8Gi memory")
+		assert.NotEmpty(t, check.Remediation)
+	})
+}
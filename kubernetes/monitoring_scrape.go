@@ -0,0 +1,109 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"strconv"
+
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScrapeOptions customize the scraping of the VM agents writing to PMM. Empty fields keep the defaults.
+type ScrapeOptions struct {
+	// Interval is how often targets without an interval of their own are scraped, e.g. 30s.
+	Interval string
+	// ServiceScrapeLabels and PodScrapeLabels limit the VMServiceScrapes and VMPodScrapes
+	// scraped to the ones with all the labels. The scrapes of the provisioner get them too.
+	ServiceScrapeLabels map[string]string
+	PodScrapeLabels     map[string]string
+	// ExternalLabels are added to all metrics.
+	ExternalLabels map[string]string
+	// MemoryAllowedPercent is the share of the memory of the VM agent used for caches.
+	MemoryAllowedPercent int
+}
+
+// SetScrapeOptions customizes the scraping of the VM agents created by ProvisionMonitoring.
+func (k *Kubernetes) SetScrapeOptions(o ScrapeOptions) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.scrape = o
+}
+
+// customizeScraping applies the scrape options to the VM agent. Selectors of the scrapes
+// are narrowed, so it is applied after restrictScrapeSelectors.
+func customizeScraping(spec *victoriametricsv1beta1.VMAgentSpec, o ScrapeOptions) {
+	if o.Interval != "" {
+		spec.ScrapeInterval = o.Interval
+	}
+	if o.MemoryAllowedPercent > 0 {
+		if spec.ExtraArgs == nil {
+			spec.ExtraArgs = make(map[string]string)
+		}
+		spec.ExtraArgs["memory.allowedPercent"] = strconv.Itoa(o.MemoryAllowedPercent)
+	}
+	if len(o.ExternalLabels) != 0 {
+		if spec.ExternalLabels == nil {
+			spec.ExternalLabels = make(map[string]string, len(o.ExternalLabels))
+		}
+		for name, value := range o.ExternalLabels {
+			spec.ExternalLabels[name] = value
+		}
+	}
+	spec.ServiceScrapeSelector = withMatchLabels(spec.ServiceScrapeSelector, o.ServiceScrapeLabels)
+	spec.PodScrapeSelector = withMatchLabels(spec.PodScrapeSelector, o.PodScrapeLabels)
+}
+
+// withMatchLabels returns a copy of the selector requiring the labels too.
+func withMatchLabels(selector *metav1.LabelSelector, labels map[string]string) *metav1.LabelSelector {
+	if len(labels) == 0 {
+		return selector
+	}
+	res := &metav1.LabelSelector{}
+	if selector != nil {
+		res = selector.DeepCopy()
+	}
+	if res.MatchLabels == nil {
+		res.MatchLabels = make(map[string]string, len(labels))
+	}
+	for key, value := range labels {
+		res.MatchLabels[key] = value
+	}
+	return res
+}
+
+// labelScrape adds the labels the VM agents select the scrape by, so scrapes of the
+// provisioner are scraped with the scrape selectors customized.
+func (k *Kubernetes) labelScrape(obj metav1.Object, kind string) {
+	var labels map[string]string
+	switch kind {
+	case "VMServiceScrape":
+		labels = k.scrape.ServiceScrapeLabels
+	case "VMPodScrape":
+		labels = k.scrape.PodScrapeLabels
+	}
+	if len(labels) == 0 {
+		return
+	}
+	merged := obj.GetLabels()
+	if merged == nil {
+		merged = make(map[string]string, len(labels))
+	}
+	for key, value := range labels {
+		merged[key] = value
+	}
+	obj.SetLabels(merged)
+}
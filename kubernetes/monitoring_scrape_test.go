@@ -0,0 +1,53 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCustomizeScraping(t *testing.T) {
+	vmagent := vmAgentSpec("vm-operator-1", "https://pmm.example.com", MonitoringTLS{})
+	restrictScrapeSelectors(&vmagent.Spec)
+	customizeScraping(&vmagent.Spec, ScrapeOptions{
+		Interval:             "1m",
+		PodScrapeLabels:      map[string]string{"team": "db"},
+		ExternalLabels:       map[string]string{"environment": "prod"},
+		MemoryAllowedPercent: 60,
+	})
+	assert.Equal(t, "1m", vmagent.Spec.ScrapeInterval)
+	assert.Equal(t, "60", vmagent.Spec.ExtraArgs["memory.allowedPercent"])
+	assert.Equal(t, map[string]string{"environment": "prod"}, vmagent.Spec.ExternalLabels)
+	assert.Equal(t, map[string]string{scrapeLabelKey: "true", "team": "db"}, vmagent.Spec.PodScrapeSelector.MatchLabels)
+	assert.Equal(t, map[string]string{scrapeLabelKey: "true"}, vmagent.Spec.ServiceScrapeSelector.MatchLabels)
+
+	k := NewEmpty()
+	k.SetScrapeOptions(ScrapeOptions{ServiceScrapeLabels: map[string]string{"team": "db"}})
+	for _, kind := range []string{"VMServiceScrape", "VMPodScrape"} {
+		obj := &unstructured.Unstructured{}
+		obj.SetKind(kind)
+		obj.SetLabels(map[string]string{scrapeLabelKey: "true"})
+		k.labelScrape(obj, obj.GetKind())
+		if kind == "VMServiceScrape" {
+			assert.Equal(t, map[string]string{scrapeLabelKey: "true", "team": "db"}, obj.GetLabels())
+		} else {
+			assert.Equal(t, map[string]string{scrapeLabelKey: "true"}, obj.GetLabels())
+		}
+	}
+}
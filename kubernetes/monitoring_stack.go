@@ -0,0 +1,317 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	vmAgentServiceAccount   = "vmagent"
+	kubeStateMetricsImage   = "k8s.gcr.io/kube-state-metrics/kube-state-metrics:v2.5.0"
+	kubeStateMetricsVersion = "2.5.0"
+	serviceAccountDir       = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// maxApplyRetries and applyRetryInterval bound waiting for the APIs of the VictoriaMetrics operator.
+	maxApplyRetries    = 5
+	applyRetryInterval = 10 * time.Second
+)
+
+// monitoringStack returns the objects applied by ProvisionMonitoring besides the VM agent:
+// the RBAC of the VM agent, the node and pod scrapes and kube-state-metrics with its scrape.
+// Namespaced objects are created in the namespace.
+func monitoringStack(namespace string) []runtime.Object {
+	objs := vmAgentRBAC(namespace)
+	objs = append(objs, nodeScrape(), podScrape())
+	return append(objs, kubeStateMetrics(namespace)...)
+}
+
+func vmAgentRBAC(namespace string) []runtime.Object {
+	return []runtime.Object{
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{Name: vmAgentServiceAccount, Namespace: namespace},
+		},
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: vmAgentServiceAccount},
+			Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups: []string{"", "networking.k8s.io", "extensions"},
+					Resources: []string{"nodes", "nodes/metrics", "services", "endpoints", "endpointslices", "pods", "app", "ingresses"},
+					Verbs:     []string{"get", "list", "watch"},
+				},
+				{APIGroups: []string{""}, Resources: []string{"namespaces", "configmaps"}, Verbs: []string{"get"}},
+				{NonResourceURLs: []string{"/metrics", "/metrics/resources"}, Verbs: []string{"get"}},
+				{
+					APIGroups: []string{"route.openshift.io", "image.openshift.io"},
+					Resources: []string{"routers/metrics", "registry/metrics"},
+					Verbs:     []string{"get"},
+				},
+			},
+		},
+		clusterRoleBinding(vmAgentServiceAccount, namespace, nil),
+	}
+}
+
+// nodeScrape scrapes cAdvisor metrics of the nodes through the API server.
+func nodeScrape() *victoriametricsv1beta1.VMNodeScrape {
+	return &victoriametricsv1beta1.VMNodeScrape{
+		TypeMeta:   metav1.TypeMeta{APIVersion: victoriametricsv1beta1.GroupVersion.String(), Kind: "VMNodeScrape"},
+		ObjectMeta: metav1.ObjectMeta{Name: "pmm-vm-cadvisor-metrics"},
+		Spec: victoriametricsv1beta1.VMNodeScrapeSpec{
+			Scheme:        "https",
+			Interval:      "10s",
+			HonorLabels:   true,
+			ScrapeTimeout: "2s",
+			TLSConfig: &victoriametricsv1beta1.TLSConfig{
+				InsecureSkipVerify: true,
+				CAFile:             serviceAccountDir + "/ca.crt",
+			},
+			BearerTokenFile: serviceAccountDir + "/token",
+			RelabelConfigs: []*victoriametricsv1beta1.RelabelConfig{
+				{Action: "labelmap", Regex: "__meta_kubernetes_node_label_(.+)"},
+				{TargetLabel: "__address__", Replacement: "kubernetes.default.svc:443"},
+				{
+					SourceLabels: []string{"__meta_kubernetes_node_name"},
+					Regex:        "(.+)",
+					TargetLabel:  "__metrics_path__",
+					Replacement:  "/api/v1/nodes/$1/proxy/metrics/cadvisor",
+				},
+			},
+			MetricRelabelConfigs: []*victoriametricsv1beta1.RelabelConfig{
+				{
+					SourceLabels: []string{"namespace", "pod"},
+					TargetLabel:  "node_name",
+					Regex:        "(.+);(.+)",
+					Replacement:  "$1-$2",
+				},
+			},
+		},
+	}
+}
+
+// podScrape scrapes the pods labeled to be monitored by the VictoriaMetrics operator.
+func podScrape() *victoriametricsv1beta1.VMPodScrape {
+	return &victoriametricsv1beta1.VMPodScrape{
+		TypeMeta:   metav1.TypeMeta{APIVersion: victoriametricsv1beta1.GroupVersion.String(), Kind: "VMPodScrape"},
+		ObjectMeta: metav1.ObjectMeta{Name: "pmm-vm-pod-scrape"},
+		Spec: victoriametricsv1beta1.VMPodScrapeSpec{
+			PodMetricsEndpoints: []victoriametricsv1beta1.PodMetricsEndpoint{{Port: "metrics", Scheme: "http"}},
+			Selector:            metav1.LabelSelector{MatchLabels: map[string]string{"monitored-by": "vm-operator"}},
+		},
+	}
+}
+
+func kubeStateMetrics(namespace string) []runtime.Object {
+	labels := map[string]string{
+		"app.kubernetes.io/component": "exporter",
+		"app.kubernetes.io/name":      kubeStateMetricsName,
+		"app.kubernetes.io/version":   kubeStateMetricsVersion,
+	}
+	objectMeta := func(namespace string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: kubeStateMetricsName, Namespace: namespace, Labels: labels}
+	}
+	selector := map[string]string{"app.kubernetes.io/name": kubeStateMetricsName}
+	listWatch := []string{"list", "watch"}
+	probe := func(path string, port int) *corev1.Probe {
+		return &corev1.Probe{
+			ProbeHandler:        corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: path, Port: intstr.FromInt(port)}},
+			InitialDelaySeconds: 5,
+			TimeoutSeconds:      5,
+		}
+	}
+	return []runtime.Object{
+		&corev1.ServiceAccount{
+			TypeMeta:                     metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta:                   objectMeta(namespace),
+			AutomountServiceAccountToken: pointer.ToBool(false),
+		},
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: objectMeta(""),
+			Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups: []string{""},
+					Resources: []string{
+						"configmaps", "secrets", "nodes", "pods", "services", "resourcequotas", "replicationcontrollers",
+						"limitranges", "persistentvolumeclaims", "persistentvolumes", "namespaces", "endpoints",
+					},
+					Verbs: listWatch,
+				},
+				{APIGroups: []string{"apps"}, Resources: []string{"statefulsets", "daemonsets", "deployments", "replicasets"}, Verbs: listWatch},
+				{APIGroups: []string{"batch"}, Resources: []string{"cronjobs", "jobs"}, Verbs: listWatch},
+				{APIGroups: []string{"autoscaling"}, Resources: []string{"horizontalpodautoscalers"}, Verbs: listWatch},
+				{APIGroups: []string{"authentication.k8s.io"}, Resources: []string{"tokenreviews"}, Verbs: []string{"create"}},
+				{APIGroups: []string{"authorization.k8s.io"}, Resources: []string{"subjectaccessreviews"}, Verbs: []string{"create"}},
+				{APIGroups: []string{"policy"}, Resources: []string{"poddisruptionbudgets"}, Verbs: listWatch},
+				{APIGroups: []string{"certificates.k8s.io"}, Resources: []string{"certificatesigningrequests"}, Verbs: listWatch},
+				{APIGroups: []string{"storage.k8s.io"}, Resources: []string{"storageclasses", "volumeattachments"}, Verbs: listWatch},
+				{
+					APIGroups: []string{"admissionregistration.k8s.io"},
+					Resources: []string{"mutatingwebhookconfigurations", "validatingwebhookconfigurations"},
+					Verbs:     listWatch,
+				},
+				{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"networkpolicies", "ingresses"}, Verbs: listWatch},
+				{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: listWatch},
+			},
+		},
+		clusterRoleBinding(kubeStateMetricsName, namespace, labels),
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+			ObjectMeta: objectMeta(namespace),
+			Spec: appsv1.DeploymentSpec{
+				Replicas: pointer.ToInt32(1),
+				Selector: &metav1.LabelSelector{MatchLabels: selector},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						AutomountServiceAccountToken: pointer.ToBool(true),
+						Containers: []corev1.Container{{
+							Name:  kubeStateMetricsName,
+							Image: kubeStateMetricsImage,
+							Ports: []corev1.ContainerPort{
+								{Name: "http-metrics", ContainerPort: 8080},
+								{Name: "telemetry", ContainerPort: 8081},
+							},
+							LivenessProbe:  probe("/healthz", 8080),
+							ReadinessProbe: probe("/", 8081),
+							SecurityContext: &corev1.SecurityContext{
+								AllowPrivilegeEscalation: pointer.ToBool(false),
+								Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+								ReadOnlyRootFilesystem:   pointer.ToBool(true),
+								RunAsUser:                pointer.ToInt64(65534),
+							},
+						}},
+						NodeSelector:       map[string]string{corev1.LabelOSStable: "linux"},
+						ServiceAccountName: kubeStateMetricsName,
+					},
+				},
+			},
+		},
+		&corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: objectMeta(namespace),
+			Spec: corev1.ServiceSpec{
+				ClusterIP: corev1.ClusterIPNone,
+				Ports: []corev1.ServicePort{
+					{Name: "http-metrics", Port: 8080, TargetPort: intstr.FromString("http-metrics")},
+					{Name: "telemetry", Port: 8081, TargetPort: intstr.FromString("telemetry")},
+				},
+				Selector: selector,
+			},
+		},
+		&victoriametricsv1beta1.VMServiceScrape{
+			TypeMeta: metav1.TypeMeta{APIVersion: victoriametricsv1beta1.GroupVersion.String(), Kind: "VMServiceScrape"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      kubeStateMetricsName,
+				Namespace: namespace,
+				Labels:    map[string]string{scrapeLabelKey: "true"},
+			},
+			Spec: victoriametricsv1beta1.VMServiceScrapeSpec{
+				JobLabel: "app.kubernetes.io/name",
+				Endpoints: []victoriametricsv1beta1.Endpoint{
+					{Scheme: "http", Port: "http-metrics", HonorLabels: true},
+				},
+				Selector: metav1.LabelSelector{MatchLabels: selector},
+			},
+		},
+	}
+}
+
+// clusterRoleBinding binds the cluster role to the service account of the same name.
+func clusterRoleBinding(name, namespace string, labels map[string]string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: name},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace}},
+	}
+}
+
+// applyMonitoringStack applies the objects of the monitoring stack.
+func (k *Kubernetes) applyMonitoringStack(ctx context.Context) (Warnings, error) {
+	return k.applyStackObjects(ctx, monitoringStack(k.client.Namespace()))
+}
+
+// applyStackObjects applies the objects in order with server-side apply. The VictoriaMetrics
+// APIs are served only once the operator has started, so objects are retried until they are
+// applied.
+func (k *Kubernetes) applyStackObjects(ctx context.Context, objs []runtime.Object) (Warnings, error) {
+	var warnings Warnings
+	for _, typed := range objs {
+		data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(typed)
+		if err != nil {
+			return warnings, err
+		}
+		obj := &unstructured.Unstructured{Object: data}
+		if err := k.adjustObject(obj); err != nil {
+			return warnings, err
+		}
+		k.labelScrape(obj, obj.GetKind())
+		name := fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName())
+		retries := 0
+		for {
+			err = k.client.ServerSideApply(ctx, obj)
+			if err == nil || retries == maxApplyRetries {
+				break
+			}
+			retries++
+			select {
+			case <-ctx.Done():
+				return warnings, ctx.Err()
+			case <-time.After(applyRetryInterval):
+			}
+		}
+		if err != nil {
+			return warnings, errors.Wrapf(err, "cannot apply %s", name)
+		}
+		if retries != 0 {
+			warnings.Add(WarningApplyRetried, "%s was applied after %d retries", name, retries)
+		}
+	}
+	return warnings, nil
+}
+
+// deleteMonitoringStack deletes the objects of the monitoring stack in the reverse order.
+func (k *Kubernetes) deleteMonitoringStack(ctx context.Context) error {
+	return k.deleteStackObjects(ctx, monitoringStack(k.client.Namespace()))
+}
+
+// deleteStackObjects deletes the objects in the reverse order.
+func (k *Kubernetes) deleteStackObjects(ctx context.Context, objs []runtime.Object) error {
+	for i := len(objs) - 1; i >= 0; i-- {
+		if err := k.client.DeleteObject(ctx, objs[i]); err != nil {
+			accessor, _ := meta.Accessor(objs[i])
+			return errors.Wrapf(err, "cannot delete %s %s", objs[i].GetObjectKind().GroupVersionKind().Kind, accessor.GetName())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestApplyMonitoringStack(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k.SetScheduling(Scheduling{NodeSelector: map[string]string{"pool": "infra"}})
+
+	applied := map[string]*unstructured.Unstructured{}
+	k8sclient.On("Namespace").Return("everest")
+	k8sclient.On("ServerSideApply", ctx, mock.AnythingOfType("*unstructured.Unstructured")).Return(nil).Run(func(args mock.Arguments) {
+		obj := args.Get(1).(*unstructured.Unstructured)
+		applied[obj.GetKind()+"/"+obj.GetName()] = obj
+	})
+
+	warnings, err := k.applyMonitoringStack(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Len(t, applied, len(monitoringStack("everest")))
+
+	deployment := applied["Deployment/kube-state-metrics"]
+	require.NotNil(t, deployment)
+	assert.Equal(t, "everest", deployment.GetNamespace())
+	selector, _, _ := unstructured.NestedStringMap(deployment.Object, "spec", "template", "spec", "nodeSelector")
+	assert.Equal(t, map[string]string{"kubernetes.io/os": "linux", "pool": "infra"}, selector)
+
+	binding := applied["ClusterRoleBinding/vmagent"]
+	require.NotNil(t, binding)
+	subjects, _, _ := unstructured.NestedSlice(binding.Object, "subjects")
+	require.Len(t, subjects, 1)
+	assert.Equal(t, "everest", subjects[0].(map[string]interface{})["namespace"])
+}
+
+func TestDisableMonitoring(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	agent := vmAgentSpec("vm-operator-1", "https://pmm.example.com", MonitoringTLS{})
+	agent.Namespace = "everest"
+	annotateCredentials(agent, MonitoringCredentials{Username: "dbaas-service-account-1"})
+	k8sclient.On("Namespace").Return("everest")
+	k8sclient.On("ListVMAgents", ctx, useDefaultNamespace, map[string]string(nil)).Return(vmAgentList(t, agent), nil)
+	k8sclient.On("DeleteVMAgent", ctx, "everest", agent.Name).Return(nil).Once()
+	var deleted []string
+	k8sclient.On("DeleteObject", ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		obj := args.Get(1).(runtime.Object)
+		accessor, err := meta.Accessor(obj)
+		require.NoError(t, err)
+		deleted = append(deleted, obj.GetObjectKind().GroupVersionKind().Kind+"/"+accessor.GetName())
+	})
+
+	apiKeys, err := k.DisableMonitoring(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dbaas-service-account-1"}, apiKeys)
+	assert.Contains(t, deleted, "Secret/vm-operator-1")
+	assert.Contains(t, deleted, "Deployment/kube-state-metrics")
+	k8sclient.AssertExpectations(t)
+}
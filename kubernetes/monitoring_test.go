@@ -0,0 +1,89 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestSelectiveMonitoring(t *testing.T) {
+	t.Parallel()
+	vmagent := vmAgentSpec("secret", "http://127.0.0.1", MonitoringTLS{})
+	restrictScrapeSelectors(&vmagent.Spec)
+	assert.False(t, vmagent.Spec.SelectAllByDefault)
+
+	cluster := &dbaasv1.DatabaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: "uid"}}
+	scrape := databaseClusterPodScrape(cluster)
+	selector, err := metav1.LabelSelectorAsSelector(vmagent.Spec.PodScrapeSelector)
+	require.NoError(t, err)
+	assert.True(t, selector.Matches(labels.Set(scrape.Labels)))
+	assert.Equal(t, "db", scrape.Spec.Selector.MatchLabels[instanceLabelKey])
+	assert.Equal(t, cluster.UID, scrape.OwnerReferences[0].UID)
+}
+
+func TestMonitoringTLS(t *testing.T) {
+	t.Parallel()
+	certs := MonitoringTLS{CA: []byte("ca"), Cert: []byte("cert"), Key: []byte("key")}
+	vmagent := vmAgentSpec("secret", "https://pmm.example.com", certs)
+	cfg := vmagent.Spec.RemoteWrite[0].TLSConfig
+	assert.False(t, cfg.InsecureSkipVerify)
+	assert.Equal(t, "secret-tls", cfg.CA.Secret.Name)
+	assert.Equal(t, "ca.crt", cfg.CA.Secret.Key)
+	assert.Equal(t, "tls.crt", cfg.Cert.Secret.Key)
+	assert.Equal(t, "tls.key", cfg.KeySecret.Key)
+
+	secret := monitoringTLSSecret("secret", certs, nil)
+	require.NotNil(t, secret)
+	assert.Equal(t, "secret-tls", secret.Name)
+	assert.Len(t, secret.Data, 3)
+
+	assert.Nil(t, monitoringTLSSecret("secret", MonitoringTLS{InsecureSkipVerify: true}, nil))
+	cfg = remoteWriteTLSConfig("secret", MonitoringTLS{InsecureSkipVerify: true})
+	assert.True(t, cfg.InsecureSkipVerify)
+	assert.Nil(t, cfg.CA.Secret)
+}
+
+func TestMonitoringCredentialsSecret(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k8sclient.On("GetSecret", ctx, "pmm-credentials").Return(&corev1.Secret{Data: map[string][]byte{
+		corev1.BasicAuthUsernameKey: []byte("api_key"),
+		corev1.BasicAuthPasswordKey: []byte("key"),
+	}}, nil)
+	k8sclient.On("GetSecret", ctx, "incomplete").Return(&corev1.Secret{Data: map[string][]byte{
+		corev1.BasicAuthUsernameKey: []byte("api_key"),
+	}}, nil)
+	assert.NoError(t, k.checkCredentialsSecret(ctx, "pmm-credentials"))
+	assert.Error(t, k.checkCredentialsSecret(ctx, "incomplete"))
+
+	vmagent := vmAgentSpec("vm-operator-1", "https://pmm.example.com", MonitoringTLS{})
+	useCredentialsSecret(&vmagent.Spec, "pmm-credentials")
+	auth := vmagent.Spec.RemoteWrite[0].BasicAuth
+	assert.Equal(t, "pmm-credentials", auth.Username.Name)
+	assert.Equal(t, "pmm-credentials", auth.Password.Name)
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// monitoringTLSSecretSuffix is appended to the name of the credentials secret of a VM agent
+	// to name the secret holding the certificates of the PMM server connection.
+	monitoringTLSSecretSuffix = "-tls"
+	monitoringTLSCAKey        = "ca.crt"
+)
+
+// MonitoringTLS holds the TLS settings of the connection to a PMM server.
+// Certificates and the key are PEM encoded. The system CAs are used if CA is empty.
+type MonitoringTLS struct {
+	CA   []byte
+	Cert []byte
+	Key  []byte
+	// InsecureSkipVerify disables the verification of the PMM server certificate.
+	InsecureSkipVerify bool
+}
+
+func (t MonitoringTLS) hasCertificates() bool {
+	return len(t.CA) != 0 || len(t.Cert) != 0
+}
+
+// monitoringTLSSecret returns the secret holding the certificates of the connection
+// or nil if there are no certificates to store.
+func monitoringTLSSecret(secretName string, t MonitoringTLS, labels map[string]string) *corev1.Secret {
+	if !t.hasCertificates() {
+		return nil
+	}
+	data := make(map[string][]byte)
+	if len(t.CA) != 0 {
+		data[monitoringTLSCAKey] = t.CA
+	}
+	if len(t.Cert) != 0 {
+		data[corev1.TLSCertKey] = t.Cert
+		data[corev1.TLSPrivateKeyKey] = t.Key
+	}
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   secretName + monitoringTLSSecretSuffix,
+			Labels: labels,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
+// remoteWriteTLSConfig returns the TLS settings of the remote write referencing the secret
+// created by monitoringTLSSecret.
+func remoteWriteTLSConfig(secretName string, t MonitoringTLS) *victoriametricsv1beta1.TLSConfig {
+	cfg := &victoriametricsv1beta1.TLSConfig{InsecureSkipVerify: t.InsecureSkipVerify}
+	key := func(k string) *corev1.SecretKeySelector {
+		return &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secretName + monitoringTLSSecretSuffix},
+			Key:                  k,
+		}
+	}
+	if len(t.CA) != 0 {
+		cfg.CA.Secret = key(monitoringTLSCAKey)
+	}
+	if len(t.Cert) != 0 {
+		cfg.Cert.Secret = key(corev1.TLSCertKey)
+		cfg.KeySecret = key(corev1.TLSPrivateKeyKey)
+	}
+	return cfg
+}
+
+// applyMonitoringTLS creates the secret with the certificates of the PMM server connection
+// of the VM agent using the credentials secret. A secret left by previous settings is removed.
+func (k *Kubernetes) applyMonitoringTLS(ctx context.Context, secretName string, t MonitoringTLS, labels map[string]string) error {
+	secret := monitoringTLSSecret(secretName, t, labels)
+	if secret == nil {
+		return k.deleteMonitoringTLS(ctx, useDefaultNamespace, secretName)
+	}
+	return errors.Wrapf(k.client.ApplyObject(ctx, secret), "cannot apply secret %s", secret.Name)
+}
+
+func (k *Kubernetes) deleteMonitoringTLS(ctx context.Context, namespace, secretName string) error {
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: secretName + monitoringTLSSecretSuffix, Namespace: namespace},
+	}
+	if err := k.client.DeleteObject(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "cannot delete secret %s", secret.Name)
+	}
+	return nil
+}
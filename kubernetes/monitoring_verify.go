@@ -0,0 +1,112 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrMonitoringNotProvisioned is returned by VerifyMonitoring if no VM agent writes to PMM.
+var ErrMonitoringNotProvisioned = errors.New("monitoring is not provisioned")
+
+// VMAgentStatus is the state of a VM agent writing metrics to PMM.
+type VMAgentStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// URL is the remote write URL of PMM the agent writes to.
+	URL string `json:"url"`
+	// Pods are the names of the running and ready pods of the agent.
+	Pods  []string `json:"pods"`
+	Ready bool     `json:"ready"`
+	// Problem describes why the agent is not ready.
+	Problem string `json:"problem,omitempty"`
+}
+
+// VerifyMonitoring returns the state of the VM agents ProvisionMonitoring created. An agent
+// is ready if one of its pods is running and ready. ErrMonitoringNotProvisioned is returned
+// if there are no agents.
+func (k *Kubernetes) VerifyMonitoring(ctx context.Context) ([]VMAgentStatus, error) {
+	list, err := k.client.ListVMAgents(ctx, useDefaultNamespace, nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, "cannot list VM agents"))
+	}
+	var statuses []VMAgentStatus
+	for _, agent := range list.Items {
+		if !strings.HasPrefix(agent.Name, vmAgentNamePrefix) {
+			continue
+		}
+		status := VMAgentStatus{Name: agent.Name, Namespace: agent.Namespace}
+		if len(agent.Spec.RemoteWrite) != 0 {
+			status.URL = agent.Spec.RemoteWrite[0].URL
+		}
+		pods, err := k.client.GetPods(ctx, agent.Namespace, &metav1.LabelSelector{
+			MatchLabels: vmAgentPodLabels(agent.Name),
+		})
+		if err != nil {
+			return nil, classifyError(errors.Wrapf(err, "cannot list pods of VM agent %s", agent.Name))
+		}
+		status.Pods, status.Problem = vmAgentPods(pods.Items)
+		status.Ready = len(status.Pods) != 0
+		statuses = append(statuses, status)
+	}
+	if len(statuses) == 0 {
+		return nil, ErrMonitoringNotProvisioned
+	}
+	return statuses, nil
+}
+
+// vmAgentPodLabels returns the labels the VM operator sets on the pods of a VM agent.
+func vmAgentPodLabels(name string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":     "vmagent",
+		"app.kubernetes.io/instance": name,
+	}
+}
+
+// vmAgentPods returns the running and ready pods or, if there are none, why they are not ready.
+func vmAgentPods(pods []corev1.Pod) ([]string, string) {
+	if len(pods) == 0 {
+		return nil, "no pods, check that the VM operator is running"
+	}
+	var ready []string
+	problem := ""
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && podReady(pod) {
+			ready = append(ready, pod.Name)
+			continue
+		}
+		if problem != "" {
+			continue
+		}
+		problem = fmt.Sprintf("pod %s is %s", pod.Name, pod.Status.Phase)
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+				problem = fmt.Sprintf("container %s of pod %s is waiting: %s", status.Name, pod.Name, status.State.Waiting.Reason)
+				break
+			}
+		}
+	}
+	if len(ready) != 0 {
+		return ready, ""
+	}
+	return nil, problem
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVerifyMonitoring(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	ready := vmAgentSpec("vm-operator-1", "https://pmm.example.com", MonitoringTLS{})
+	ready.Namespace = "everest"
+	crashing := vmAgentSpec("vm-operator-2", "https://pmm.example.com", MonitoringTLS{})
+	crashing.Namespace = "everest"
+	k8sclient.On("ListVMAgents", ctx, useDefaultNamespace, map[string]string(nil)).Return(vmAgentList(t, ready, crashing), nil)
+	k8sclient.On("GetPods", ctx, "everest", &metav1.LabelSelector{MatchLabels: vmAgentPodLabels(ready.Name)}).Return(&corev1.PodList{Items: []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "vmagent-1"},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}}}, nil)
+	k8sclient.On("GetPods", ctx, "everest", &metav1.LabelSelector{MatchLabels: vmAgentPodLabels(crashing.Name)}).Return(&corev1.PodList{Items: []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "vmagent-2"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "vmagent",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}},
+		},
+	}}}, nil)
+
+	statuses, err := k.VerifyMonitoring(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Ready)
+	assert.Equal(t, []string{"vmagent-1"}, statuses[0].Pods)
+	assert.Equal(t, "https://pmm.example.com/victoriametrics/api/v1/write", statuses[0].URL)
+	assert.False(t, statuses[1].Ready)
+	assert.Equal(t, "container vmagent of pod vmagent-2 is waiting: CrashLoopBackOff", statuses[1].Problem)
+}
@@ -42,11 +42,12 @@ func TestInstallOlmOperator(t *testing.T) {
 			mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 			Return(&v1alpha1.Subscription{}, nil)
 		k8sclient.On("GetDeployment", ctx, mock.Anything).Return(&appsv1.Deployment{}, nil)
-		k8sclient.On("ApplyFile", mock.Anything).Return(nil)
+		k8sclient.On("ApplyFile", mock.Anything, mock.Anything).Return(nil)
+		k8sclient.On("DoCRDWait", mock.Anything, mock.Anything).Return(nil)
 		k8sclient.On("DoRolloutWait", ctx, mock.Anything).Return(nil)
 		k8sclient.On("GetSubscriptionCSV", ctx, mock.Anything).Return(types.NamespacedName{}, nil)
 		k8sclient.On("DoRolloutWait", ctx, mock.Anything).Return(nil)
-		err := olms.InstallOLMOperator(ctx)
+		err := olms.InstallOLMOperator(ctx, nil)
 		assert.NoError(t, err)
 	})
 
@@ -68,6 +69,13 @@ func TestInstallOlmOperator(t *testing.T) {
 
 		k8sclient.On("GetOperatorGroup", ctx, "", operatorGroup).Return(&v1.OperatorGroup{}, nil)
 		mockSubscription := &v1alpha1.Subscription{
+			Spec: &v1alpha1.SubscriptionSpec{
+				Package:                operatorName,
+				Channel:                params.Channel,
+				CatalogSource:          params.CatalogSource,
+				CatalogSourceNamespace: params.CatalogSourceNamespace,
+				InstallPlanApproval:    params.InstallPlanApproval,
+			},
 			Status: v1alpha1.SubscriptionStatus{
 				Install: &v1alpha1.InstallPlanReference{
 					Name: "abcd1234",
@@ -78,7 +86,7 @@ func TestInstallOlmOperator(t *testing.T) {
 		mockInstallPlan := &v1alpha1.InstallPlan{}
 		k8sclient.On("GetInstallPlan", ctx, subscriptionNamespace, mockSubscription.Status.Install.Name).Return(mockInstallPlan, nil)
 		k8sclient.On("UpdateInstallPlan", ctx, subscriptionNamespace, mockInstallPlan).Return(mockInstallPlan, nil)
-		err := olms.InstallOperator(ctx, params)
+		_, err := olms.InstallOperator(ctx, params)
 		assert.NoError(t, err)
 	})
 }
@@ -0,0 +1,203 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"github.com/AlekSi/pointer"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// OLM components whose replicas and resources can be tuned.
+const (
+	OLMOperatorComponent     = "olm-operator"
+	CatalogOperatorComponent = "catalog-operator"
+	PackageServerComponent   = "packageserver"
+)
+
+// OLMComponentTuning overrides the replica count and the resources of an OLM component.
+// Unset fields keep the values of the bundled manifests.
+type OLMComponentTuning struct {
+	Replicas *int32
+	Requests corev1.ResourceList
+	Limits   corev1.ResourceList
+}
+
+// OLMTuning holds the overrides of OLM components keyed by component name.
+type OLMTuning map[string]OLMComponentTuning
+
+// OLMTuningPresets are the predefined tunings of OLM.
+var OLMTuningPresets = map[string]OLMTuning{
+	// minikube fits OLM into small single-node clusters.
+	"minikube": {
+		OLMOperatorComponent: {
+			Replicas: pointer.ToInt32(1),
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("100Mi"),
+			},
+		},
+		CatalogOperatorComponent: {
+			Replicas: pointer.ToInt32(1),
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("60Mi"),
+			},
+		},
+		PackageServerComponent: {
+			Replicas: pointer.ToInt32(1),
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("50Mi"),
+			},
+		},
+	},
+	// production reserves enough resources for OLM to keep up with many operators
+	// and keeps the package server highly available.
+	"production": {
+		OLMOperatorComponent: {
+			Replicas: pointer.ToInt32(1),
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+		},
+		CatalogOperatorComponent: {
+			Replicas: pointer.ToInt32(1),
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+		},
+		PackageServerComponent: {
+			Replicas: pointer.ToInt32(2),
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("50m"),
+				corev1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+		},
+	},
+}
+
+// tuneOLMResources applies the tuning to the OLM deployments among the resources.
+// The package server is deployed by OLM from its cluster service version, so it is
+// tuned there; changes made to its deployment would be reverted by OLM.
+func tuneOLMResources(resources []unstructured.Unstructured, tuning OLMTuning) error {
+	for i := range resources {
+		r := &resources[i]
+		switch r.GetKind() {
+		case "Deployment":
+			t, ok := tuning[r.GetName()]
+			if !ok {
+				continue
+			}
+			spec, _, err := unstructured.NestedMap(r.Object, "spec")
+			if err != nil {
+				return errors.Wrapf(err, "cannot read deployment %s", r.GetName())
+			}
+			if err := tuneDeploymentSpec(spec, t); err != nil {
+				return errors.Wrapf(err, "cannot tune deployment %s", r.GetName())
+			}
+			if err := unstructured.SetNestedMap(r.Object, spec, "spec"); err != nil {
+				return err
+			}
+		case v1alpha1.ClusterServiceVersionKind:
+			if err := tuneCSVDeployments(r, tuning); err != nil {
+				return errors.Wrapf(err, "cannot tune cluster service version %s", r.GetName())
+			}
+		}
+	}
+	return nil
+}
+
+func tuneCSVDeployments(csv *unstructured.Unstructured, tuning OLMTuning) error {
+	deployments, found, err := unstructured.NestedSlice(csv.Object, "spec", "install", "spec", "deployments")
+	if err != nil || !found {
+		return err
+	}
+	for _, d := range deployments {
+		deployment, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(deployment, "name")
+		t, ok := tuning[name]
+		if !ok {
+			continue
+		}
+		spec, _, err := unstructured.NestedMap(deployment, "spec")
+		if err != nil {
+			return err
+		}
+		if err := tuneDeploymentSpec(spec, t); err != nil {
+			return errors.Wrapf(err, "cannot tune deployment %s", name)
+		}
+		deployment["spec"] = spec
+	}
+	return unstructured.SetNestedSlice(csv.Object, deployments, "spec", "install", "spec", "deployments")
+}
+
+func tuneDeploymentSpec(spec map[string]interface{}, t OLMComponentTuning) error {
+	if t.Replicas != nil {
+		spec["replicas"] = int64(*t.Replicas)
+	}
+	containers, _, err := unstructured.NestedSlice(spec, "template", "spec", "containers")
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := setResourceList(container, t.Requests, "resources", "requests"); err != nil {
+			return err
+		}
+		if err := setResourceList(container, t.Limits, "resources", "limits"); err != nil {
+			return err
+		}
+	}
+	return unstructured.SetNestedSlice(spec, containers, "template", "spec", "containers")
+}
+
+func setResourceList(obj map[string]interface{}, list corev1.ResourceList, fields ...string) error {
+	if len(list) == 0 {
+		return nil
+	}
+	current, _, err := unstructured.NestedMap(obj, fields...)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		current = make(map[string]interface{}, len(list))
+	}
+	for name, quantity := range list {
+		current[string(name)] = quantity.String()
+	}
+	return unstructured.SetNestedMap(obj, current, fields...)
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestTuneOLMResources(t *testing.T) {
+	olmFile, err := fs.ReadFile(data.OLMCRDs, "crds/olm/olm.yaml")
+	require.NoError(t, err)
+	resources, err := decodeResources(olmFile)
+	require.NoError(t, err)
+
+	require.NoError(t, tuneOLMResources(resources, OLMTuningPresets["minikube"]))
+
+	for _, r := range resources {
+		switch {
+		case r.GetKind() == "Deployment" && r.GetName() == OLMOperatorComponent:
+			replicas, _, _ := unstructured.NestedInt64(r.Object, "spec", "replicas")
+			assert.Equal(t, int64(1), replicas)
+			containers, _, _ := unstructured.NestedSlice(r.Object, "spec", "template", "spec", "containers")
+			require.NotEmpty(t, containers)
+			memory, _, _ := unstructured.NestedString(containers[0].(map[string]interface{}), "resources", "requests", "memory")
+			assert.Equal(t, "100Mi", memory)
+		case r.GetKind() == "ClusterServiceVersion" && r.GetName() == PackageServerComponent:
+			deployments, _, _ := unstructured.NestedSlice(r.Object, "spec", "install", "spec", "deployments")
+			require.Len(t, deployments, 1)
+			replicas, _, _ := unstructured.NestedInt64(deployments[0].(map[string]interface{}), "spec", "replicas")
+			assert.Equal(t, int64(1), replicas)
+		}
+	}
+}
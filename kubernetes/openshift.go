@@ -0,0 +1,156 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/gen1us2k/everest-provisioner/data"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// openShiftSecurityAPIGroup is served only by OpenShift clusters. It holds security context constraints.
+	openShiftSecurityAPIGroup = "security.openshift.io"
+
+	// OpenShiftMarketplaceNamespace is the namespace of catalog sources of the OLM built into OpenShift.
+	OpenShiftMarketplaceNamespace = "openshift-marketplace"
+)
+
+// EnableOpenShiftCompatibility makes the applied workloads compatible with the restricted
+// security context constraints of OpenShift, which assign user and group IDs to pods.
+func (k *Kubernetes) EnableOpenShiftCompatibility() {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.openShift = true
+}
+
+// CreateCatalogSource creates or updates a catalog source serving the operators from the image.
+// It points the installation at mirrored or internal catalogs. The Percona catalog image is
+// used if image is empty. The catalog is polled for updates of the image tag.
+func (k *Kubernetes) CreateCatalogSource(ctx context.Context, name, image, namespace string) error {
+	catalog, err := k.catalogSource(name, image, namespace)
+	if err != nil {
+		return err
+	}
+	return k.applyCatalogSource(ctx, catalog)
+}
+
+// PinCatalogSource replaces the image of the catalog source with the image pinned by digest.
+// The catalog is not polled for updates then.
+func (k *Kubernetes) PinCatalogSource(ctx context.Context, name, image, namespace string) error {
+	catalog, err := k.catalogSource(name, image, namespace)
+	if err != nil {
+		return err
+	}
+	unstructured.RemoveNestedField(catalog.Object, "spec", "updateStrategy")
+	return k.applyCatalogSource(ctx, catalog)
+}
+
+// catalogSource builds the catalog source from the Percona catalog manifest.
+func (k *Kubernetes) catalogSource(name, image, namespace string) (*unstructured.Unstructured, error) {
+	file, err := fs.ReadFile(data.OLMCRDs, "crds/olm/percona-dbaas-catalog.yaml")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read percona catalog yaml file")
+	}
+	resources, err := decodeResources(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode percona catalog")
+	}
+	if len(resources) != 1 {
+		return nil, errors.Errorf("percona catalog yaml file holds %d objects instead of one", len(resources))
+	}
+	catalog := &resources[0]
+	catalog.SetName(name)
+	catalog.SetNamespace(namespace)
+	if image != "" {
+		if err := unstructured.SetNestedField(catalog.Object, image, "spec", "image"); err != nil {
+			return nil, err
+		}
+	}
+	if len(k.imagePullSecrets) != 0 {
+		if err := setImagePullSecrets(catalog, k.imagePullSecrets); err != nil {
+			return nil, err
+		}
+	}
+	return catalog, nil
+}
+
+func (k *Kubernetes) applyCatalogSource(ctx context.Context, catalog *unstructured.Unstructured) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if err := k.client.ApplyObject(ctx, catalog); err != nil {
+		return classifyError(errors.Wrapf(err, "cannot apply catalog source %s", catalog.GetName()))
+	}
+	return nil
+}
+
+// adjustObject adjusts the object to the cluster type and the hardened profile, references
+// the image pull secrets, constrains the nodes and resources of workloads and overrides the
+// image of kube-state-metrics.
+func (k *Kubernetes) adjustObject(obj *unstructured.Unstructured) error {
+	if k.openShift {
+		removeFixedIDs(obj)
+	}
+	if k.hardened {
+		if err := restrictWorkload(obj, true); err != nil {
+			return err
+		}
+	}
+	if len(k.imagePullSecrets) != 0 {
+		if err := setImagePullSecrets(obj, k.imagePullSecrets); err != nil {
+			return err
+		}
+	}
+	if err := scheduleWorkload(obj, k.scheduling); err != nil {
+		return err
+	}
+	if err := setKubeStateMetricsImage(obj, k.kubeStateMetricsImage); err != nil {
+		return err
+	}
+	return setKubeStateMetricsResources(obj, k.monitoringResources.KubeStateMetrics)
+}
+
+// removeFixedIDs removes user and group IDs from the security contexts of workloads
+// so OpenShift can assign IDs from the range of the namespace.
+func removeFixedIDs(obj *unstructured.Unstructured) {
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "DaemonSet", "Job":
+	default:
+		return
+	}
+	podSpec := []string{"spec", "template", "spec"}
+	for _, field := range []string{"runAsUser", "runAsGroup", "fsGroup"} {
+		unstructured.RemoveNestedField(obj.Object, append(podSpec, "securityContext", field)...)
+	}
+	for _, list := range []string{"containers", "initContainers"} {
+		containers, found, err := unstructured.NestedSlice(obj.Object, append(podSpec, list)...)
+		if err != nil || !found {
+			continue
+		}
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			unstructured.RemoveNestedField(container, "securityContext", "runAsUser")
+			unstructured.RemoveNestedField(container, "securityContext", "runAsGroup")
+		}
+		_ = unstructured.SetNestedSlice(obj.Object, containers, append(podSpec, list)...)
+	}
+}
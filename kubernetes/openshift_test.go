@@ -0,0 +1,81 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestGetClusterTypeOpenShift(t *testing.T) {
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	k8sclient.On("HasAPIGroup", mock.Anything, openShiftSecurityAPIGroup).Return(true, nil)
+	clusterType, err := k.GetClusterType(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ClusterTypeOpenShift, clusterType)
+}
+
+func TestRemoveFixedIDs(t *testing.T) {
+	var deployment *unstructured.Unstructured
+	for _, obj := range monitoringStack("default") {
+		if obj.GetObjectKind().GroupVersionKind().Kind != "Deployment" {
+			continue
+		}
+		data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		require.NoError(t, err)
+		deployment = &unstructured.Unstructured{Object: data}
+	}
+	require.NotNil(t, deployment)
+
+	removeFixedIDs(deployment)
+
+	containers, _, err := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	for _, c := range containers {
+		_, found, _ := unstructured.NestedFieldNoCopy(c.(map[string]interface{}), "securityContext", "runAsUser")
+		assert.False(t, found)
+		readOnly, _, _ := unstructured.NestedBool(c.(map[string]interface{}), "securityContext", "readOnlyRootFilesystem")
+		assert.True(t, readOnly)
+	}
+}
+
+func TestCatalogSource(t *testing.T) {
+	k := NewEmpty()
+
+	catalog, err := k.catalogSource("mirror-catalog", "registry.example.com/percona/dbaas-catalog:1.0", "mirrors")
+	require.NoError(t, err)
+	assert.Equal(t, "mirror-catalog", catalog.GetName())
+	assert.Equal(t, "mirrors", catalog.GetNamespace())
+	image, _, _ := unstructured.NestedString(catalog.Object, "spec", "image")
+	assert.Equal(t, "registry.example.com/percona/dbaas-catalog:1.0", image)
+	_, polled, _ := unstructured.NestedMap(catalog.Object, "spec", "updateStrategy")
+	assert.True(t, polled)
+
+	catalog, err = k.catalogSource("percona-dbaas-catalog", "", "olm")
+	require.NoError(t, err)
+	image, _, _ = unstructured.NestedString(catalog.Object, "spec", "image")
+	assert.Equal(t, "docker.io/percona/dbaas-catalog:latest", image)
+}
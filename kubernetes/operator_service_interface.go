@@ -13,9 +13,9 @@ type OperatorServiceManager interface {
 	// SetKubeConfig receives a new config and establish a new connection to the K8 cluster.
 	SetKubeConfig(kubeConfig string) error
 	// InstallOLMOperator installs the OLM in the Kubernetes cluster.
-	InstallOLMOperator(ctx context.Context) error
+	InstallOLMOperator(ctx context.Context, tuning OLMTuning) error
 	// InstallOperator installs an operator via OLM.
-	InstallOperator(ctx context.Context, req InstallOperatorRequest) error
+	InstallOperator(ctx context.Context, req InstallOperatorRequest) (Warnings, error)
 	// ListSubscriptions all the subscriptions in the namespace.
 	ListSubscriptions(ctx context.Context, namespace string) (*v1alpha1.SubscriptionList, error)
 	// UpgradeOperator upgrades an operator to the next available version.
@@ -0,0 +1,123 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	packagev1 "github.com/operator-framework/operator-lifecycle-manager/pkg/package-server/apis/operators/v1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The package server labels the package manifests with the catalog they come from.
+const (
+	packageCatalogLabel          = "catalog"
+	packageCatalogNamespaceLabel = "catalog-namespace"
+)
+
+// OperatorVersionsOptions selects the subscription and the catalog of an operator.
+type OperatorVersionsOptions struct {
+	// Namespace is the namespace of the subscription of the operator.
+	Namespace string
+	// CatalogSource and CatalogSourceNamespace select the catalog if the operator is not
+	// installed. The catalog of the subscription is used otherwise.
+	CatalogSource          string
+	CatalogSourceNamespace string
+}
+
+// OperatorVersions are the versions of an operator available in a catalog.
+type OperatorVersions struct {
+	Name           string `json:"name"`
+	CatalogSource  string `json:"catalogSource"`
+	DefaultChannel string `json:"defaultChannel"`
+	// InstalledVersion and InstalledChannel are empty if the operator is not installed.
+	InstalledVersion string            `json:"installedVersion,omitempty"`
+	InstalledChannel string            `json:"installedChannel,omitempty"`
+	Channels         []OperatorChannel `json:"channels"`
+}
+
+// OperatorChannel lists the versions of a channel, the latest first.
+type OperatorChannel struct {
+	Name     string   `json:"name"`
+	Latest   string   `json:"latest"`
+	Versions []string `json:"versions"`
+}
+
+// ListAvailableOperatorVersions returns the versions of the operator available per channel
+// in the catalog, read from the package manifests of the package server, and the version
+// the subscription of the operator has installed.
+func (k *Kubernetes) ListAvailableOperatorVersions(ctx context.Context, operatorName string, opts OperatorVersionsOptions) (*OperatorVersions, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	versions := &OperatorVersions{Name: operatorName}
+	catalog, catalogNamespace := opts.CatalogSource, opts.CatalogSourceNamespace
+	sub, err := k.client.GetSubscription(ctx, opts.Namespace, operatorName)
+	switch {
+	case err == nil:
+		catalog, catalogNamespace = sub.Spec.CatalogSource, sub.Spec.CatalogSourceNamespace
+		versions.InstalledChannel = sub.Spec.Channel
+		if sub.Status.InstalledCSV != "" {
+			csv, err := k.client.GetClusterServiceVersion(ctx, types.NamespacedName{Namespace: opts.Namespace, Name: sub.Status.InstalledCSV})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, classifyError(errors.Wrapf(err, "cannot get installed version of %s operator", operatorName))
+			}
+			if err == nil {
+				versions.InstalledVersion = csv.Spec.Version.String()
+			}
+		}
+	case !apierrors.IsNotFound(err):
+		return nil, classifyError(errors.Wrapf(err, "cannot get subscription of %s operator", operatorName))
+	}
+	versions.CatalogSource = catalog
+
+	packages, err := k.client.ListPackageManifests(ctx, opts.Namespace, &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			packageCatalogLabel:          catalog,
+			packageCatalogNamespaceLabel: catalogNamespace,
+		},
+	})
+	if err != nil {
+		return nil, classifyError(errors.Wrapf(err, "cannot list packages of %s catalog", catalog))
+	}
+	var manifest *packagev1.PackageManifest
+	for i, p := range packages.Items {
+		if p.Status.PackageName == operatorName {
+			manifest = &packages.Items[i]
+			break
+		}
+	}
+	if manifest == nil {
+		return nil, errors.Errorf("%s operator is not available in %s catalog", operatorName, catalog)
+	}
+
+	versions.DefaultChannel = manifest.Status.DefaultChannel
+	for _, ch := range manifest.Status.Channels {
+		channel := OperatorChannel{
+			Name:     ch.Name,
+			Latest:   ch.CurrentCSVDesc.Version.String(),
+			Versions: make([]string, 0, len(ch.Entries)),
+		}
+		for _, entry := range ch.Entries {
+			channel.Versions = append(channel.Versions, entry.Version)
+		}
+		versions.Channels = append(versions.Channels, channel)
+	}
+	return versions, nil
+}
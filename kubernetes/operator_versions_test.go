@@ -0,0 +1,106 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/fake"
+	"github.com/operator-framework/api/pkg/lib/version"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	packagev1 "github.com/operator-framework/operator-lifecycle-manager/pkg/package-server/apis/operators/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestListAvailableOperatorVersions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	operatorVersion := func(v string) version.OperatorVersion {
+		return version.OperatorVersion{Version: semver.MustParse(v)}
+	}
+	manifest := func(catalog string) *packagev1.PackageManifest {
+		return &packagev1.PackageManifest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "percona-xtradb-cluster-operator",
+				Namespace: "default",
+				Labels:    map[string]string{packageCatalogLabel: catalog, packageCatalogNamespaceLabel: "olm"},
+			},
+			Status: packagev1.PackageManifestStatus{
+				CatalogSource:  catalog,
+				PackageName:    "percona-xtradb-cluster-operator",
+				DefaultChannel: "stable-v1",
+				Channels: []packagev1.PackageChannel{{
+					Name:           "stable-v1",
+					CurrentCSV:     "percona-xtradb-cluster-operator.v1.13.0",
+					CurrentCSVDesc: packagev1.CSVDescription{Version: operatorVersion("1.13.0")},
+					Entries: []packagev1.ChannelEntry{
+						{Name: "percona-xtradb-cluster-operator.v1.13.0", Version: "1.13.0"},
+						{Name: "percona-xtradb-cluster-operator.v1.12.0", Version: "1.12.0"},
+					},
+				}},
+			},
+		}
+	}
+	sub := &v1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "percona-xtradb-cluster-operator", Namespace: "default"},
+		Spec: &v1alpha1.SubscriptionSpec{
+			CatalogSource:          "percona-dbaas-catalog",
+			CatalogSourceNamespace: "olm",
+			Package:                "percona-xtradb-cluster-operator",
+			Channel:                "stable-v1",
+		},
+		Status: v1alpha1.SubscriptionStatus{InstalledCSV: "percona-xtradb-cluster-operator.v1.12.0"},
+	}
+	csv := &v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "percona-xtradb-cluster-operator.v1.12.0", Namespace: "default"},
+		Spec:       v1alpha1.ClusterServiceVersionSpec{Version: operatorVersion("1.12.0")},
+	}
+	other := manifest("operatorhubio-catalog")
+	other.Name = "percona-xtradb-cluster-operator-operatorhubio"
+	c, err := fake.NewKubeClient("default", sub, csv, manifest("percona-dbaas-catalog"), other)
+	require.NoError(t, err)
+	k := NewWithClient(c)
+
+	versions, err := k.ListAvailableOperatorVersions(ctx, "percona-xtradb-cluster-operator", OperatorVersionsOptions{
+		Namespace:              "default",
+		CatalogSource:          "operatorhubio-catalog",
+		CatalogSourceNamespace: "olm",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &OperatorVersions{
+		Name:             "percona-xtradb-cluster-operator",
+		CatalogSource:    "percona-dbaas-catalog",
+		DefaultChannel:   "stable-v1",
+		InstalledVersion: "1.12.0",
+		InstalledChannel: "stable-v1",
+		Channels: []OperatorChannel{{
+			Name:     "stable-v1",
+			Latest:   "1.13.0",
+			Versions: []string{"1.13.0", "1.12.0"},
+		}},
+	}, versions)
+
+	_, err = k.ListAvailableOperatorVersions(ctx, "dbaas-operator", OperatorVersionsOptions{
+		Namespace:              "default",
+		CatalogSource:          "percona-dbaas-catalog",
+		CatalogSourceNamespace: "olm",
+	})
+	assert.ErrorContains(t, err, "dbaas-operator operator is not available in percona-dbaas-catalog catalog")
+}
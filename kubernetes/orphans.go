@@ -0,0 +1,142 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// CreatedByLabel marks the objects created by the provisioner.
+	CreatedByLabel = "everest.percona.com/created-by"
+	// RunIDLabel holds the ID of the provisioner run which applied the object last.
+	RunIDLabel = "everest.percona.com/run-id"
+
+	createdByProvisioner = "everest-provisioner"
+	// pmmSecretPrefix prefixes the names of the secrets holding the PMM credentials of VM agents.
+	pmmSecretPrefix = "vm-operator-"
+)
+
+// NewRunID returns an ID for the objects applied by a provisioner run. IDs sort by
+// the time the run started at.
+func NewRunID() string {
+	return fmt.Sprintf("%s-%04x", time.Now().UTC().Format("20060102-150405"), rand.Intn(1<<16)) //nolint:gosec
+}
+
+// OwnershipLabels returns the labels marking the objects applied by the provisioner run.
+func OwnershipLabels(runID string) map[string]string {
+	return map[string]string{
+		CreatedByLabel: createdByProvisioner,
+		RunIDLabel:     runID,
+	}
+}
+
+// PlanOrphanCleanup lists the resources created by the provisioner which are not used anymore:
+// subscriptions OLM failed to resolve or install, PMM VM agents replaced by a newer one and the
+// secrets holding PMM credentials and certificates of VM agents which do not exist anymore.
+// Only resources with the CreatedByLabel are listed.
+func (k *Kubernetes) PlanOrphanCleanup(ctx context.Context, namespace string) (*DeletionPlan, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	selector := labels.SelectorFromSet(labels.Set{CreatedByLabel: createdByProvisioner})
+	plan := &DeletionPlan{}
+
+	subs, err := k.client.ListSubscriptions(ctx, namespace)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "cannot list subscriptions")
+	}
+	if subs != nil {
+		for i := range subs.Items {
+			sub := &subs.Items[i]
+			if selector.Matches(labels.Set(sub.Labels)) && subscriptionFailed(sub) {
+				plan.add(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.SubscriptionKind), sub, "")
+			}
+		}
+	}
+
+	agents, err := k.client.ListVMAgents(ctx, useDefaultNamespace, map[string]string{CreatedByLabel: createdByProvisioner})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "cannot list VM agents")
+	}
+	// Provisioning monitoring replaces the PMM agent, so only the newest one is in use.
+	used := make(map[string]struct{})
+	if agents != nil {
+		pmmAgents := agents.Items[:0]
+		for _, agent := range agents.Items {
+			if strings.HasPrefix(agent.Name, vmAgentNamePrefix) {
+				pmmAgents = append(pmmAgents, agent)
+			}
+		}
+		sort.Slice(pmmAgents, func(i, j int) bool {
+			return pmmAgents[i].CreationTimestamp.Before(&pmmAgents[j].CreationTimestamp)
+		})
+		for i := range pmmAgents {
+			agent := &pmmAgents[i]
+			if i == len(pmmAgents)-1 {
+				used[strings.TrimPrefix(agent.Name, vmAgentNamePrefix)] = struct{}{}
+				break
+			}
+			plan.add(victoriametricsv1beta1.GroupVersion.WithKind("VMAgent"), agent, "")
+		}
+	}
+
+	secrets, err := k.client.ListSecrets(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list secrets")
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if !selector.Matches(labels.Set(secret.Labels)) || !strings.HasPrefix(secret.Name, pmmSecretPrefix) {
+			continue
+		}
+		if _, ok := used[strings.TrimSuffix(secret.Name, monitoringTLSSecretSuffix)]; ok {
+			continue
+		}
+		plan.add(corev1.SchemeGroupVersion.WithKind("Secret"), secret, "")
+	}
+	return plan, nil
+}
+
+// subscriptionFailed returns true if OLM failed to resolve or install the subscription
+// and no version of the operator has been installed for it.
+func subscriptionFailed(sub *v1alpha1.Subscription) bool {
+	if sub.Status.InstalledCSV != "" {
+		return false
+	}
+	for _, cond := range sub.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case v1alpha1.SubscriptionResolutionFailed, v1alpha1.SubscriptionInstallPlanFailed:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/fake"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestPlanOrphanCleanup(t *testing.T) {
+	t.Parallel()
+	owned := OwnershipLabels("20230601-120000-0001")
+	created := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	secret := func(name string, labels map[string]string) runtime.Object {
+		return &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+		}
+	}
+	agent := func(name string, age time.Duration) runtime.Object {
+		return &victoriametricsv1beta1.VMAgent{
+			TypeMeta: metav1.TypeMeta{APIVersion: "operator.victoriametrics.com/v1beta1", Kind: "VMAgent"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "default", Labels: owned,
+				CreationTimestamp: metav1.NewTime(created.Add(-age)),
+			},
+		}
+	}
+	subscription := func(name, installedCSV string, condition v1alpha1.SubscriptionConditionType) runtime.Object {
+		return &v1alpha1.Subscription{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SubscriptionCRDAPIVersion, Kind: v1alpha1.SubscriptionKind},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: owned},
+			Status: v1alpha1.SubscriptionStatus{
+				InstalledCSV: installedCSV,
+				Conditions:   []v1alpha1.SubscriptionCondition{{Type: condition, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	c, err := fake.NewKubeClient("default",
+		subscription("dbaas-operator", "", v1alpha1.SubscriptionResolutionFailed),
+		subscription("percona-xtradb-cluster-operator", "percona-xtradb-cluster-operator.v1.12.0", v1alpha1.SubscriptionCatalogSourcesUnhealthy),
+		agent(vmAgentNamePrefix+"vm-operator-1", time.Hour),
+		agent(vmAgentNamePrefix+"vm-operator-2", 0),
+		secret("vm-operator-1", owned),
+		secret("vm-operator-1"+monitoringTLSSecretSuffix, owned),
+		secret("vm-operator-2", owned),
+		secret("vm-operator-3", owned),
+		secret("vm-operator-4", nil),
+	)
+	require.NoError(t, err)
+	k := NewWithClient(c)
+
+	plan, err := k.PlanOrphanCleanup(context.Background(), "default")
+	require.NoError(t, err)
+	var items []string
+	for _, item := range plan.Items {
+		items = append(items, item.String())
+	}
+	assert.ElementsMatch(t, []string{
+		"Subscription default/dbaas-operator",
+		"VMAgent default/pmm-vmagent-vm-operator-1",
+		"Secret default/vm-operator-1",
+		"Secret default/vm-operator-1-tls",
+		"Secret default/vm-operator-3",
+	}, items)
+}
+
+func TestOwnershipLabels(t *testing.T) {
+	t.Parallel()
+	labels := OwnershipLabels("run")
+	assert.Equal(t, createdByProvisioner, labels[CreatedByLabel])
+	assert.Equal(t, "run", labels[RunIDLabel])
+	assert.Regexp(t, `^\d{8}-\d{6}-[0-9a-f]{4}$`, NewRunID())
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+)
+
+// DatabaseClusterStatePaused is reported by a database cluster whose pods were stopped by the operator.
+const DatabaseClusterStatePaused dbaasv1.AppState = "paused"
+
+// PauseDatabaseCluster makes the operator stop the pods of the database cluster.
+// The volumes and secrets of the cluster are kept so it can be resumed later.
+func (k *Kubernetes) PauseDatabaseCluster(ctx context.Context, name string) error {
+	return k.setDatabaseClusterPause(ctx, name, true)
+}
+
+// ResumeDatabaseCluster makes the operator start the pods of a paused database cluster again.
+func (k *Kubernetes) ResumeDatabaseCluster(ctx context.Context, name string) error {
+	return k.setDatabaseClusterPause(ctx, name, false)
+}
+
+// setDatabaseClusterPause sets the pause field of the cluster spec. The cluster is not
+// updated if the field is set already.
+func (k *Kubernetes) setDatabaseClusterPause(ctx context.Context, name string, pause bool) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	cluster, err := k.client.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return classifyError(err)
+	}
+	if cluster.Spec.Pause == pause {
+		return nil
+	}
+	cluster.Spec.Pause = pause
+	cluster.TypeMeta.APIVersion = databaseClusterAPIVersion
+	cluster.TypeMeta.Kind = databaseClusterKind
+	action := "resume"
+	if pause {
+		action = "pause"
+	}
+	return classifyError(errors.Wrapf(k.client.ApplyObject(ctx, cluster), "cannot %s database cluster %s", action, name))
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPauseDatabaseCluster(t *testing.T) {
+	ctx := context.Background()
+	cluster := func(pause bool) *dbaasv1.DatabaseCluster {
+		return &dbaasv1.DatabaseCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: "default"},
+			Spec:       dbaasv1.DatabaseSpec{ClusterSize: 3, Pause: pause},
+		}
+	}
+
+	t.Run("pauses", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster(false), nil)
+		k8sclient.On("ApplyObject", mock.Anything, mock.MatchedBy(func(c *dbaasv1.DatabaseCluster) bool {
+			return c.Spec.Pause && c.Spec.ClusterSize == 3 && c.Kind == databaseClusterKind
+		})).Return(nil)
+
+		require.NoError(t, k.PauseDatabaseCluster(ctx, "mysql"))
+		k8sclient.AssertExpectations(t)
+	})
+
+	t.Run("resumes", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster(true), nil)
+		k8sclient.On("ApplyObject", mock.Anything, mock.MatchedBy(func(c *dbaasv1.DatabaseCluster) bool {
+			return !c.Spec.Pause
+		})).Return(nil)
+
+		require.NoError(t, k.ResumeDatabaseCluster(ctx, "mysql"))
+		k8sclient.AssertExpectations(t)
+	})
+
+	t.Run("paused already", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster(true), nil)
+
+		require.NoError(t, k.PauseDatabaseCluster(ctx, "mysql"))
+		k8sclient.AssertNotCalled(t, "ApplyObject", mock.Anything, mock.Anything)
+	})
+}
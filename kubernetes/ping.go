@@ -0,0 +1,206 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	mysqlPort    = 3306
+	mongoDBPort  = 27017
+	postgresPort = 5432
+
+	// pingTimeout limits connecting to the database and running the ping.
+	pingTimeout = 10 * time.Second
+)
+
+// DatabasePing is the result of connecting to a database cluster.
+type DatabasePing struct {
+	Engine  string        `json:"engine"`
+	Pod     string        `json:"pod"`
+	Version string        `json:"version,omitempty"`
+	Latency time.Duration `json:"latency"`
+}
+
+// databasePing connects to the database listening on the address as the user with the
+// engine driver and returns the server version.
+type databasePing func(ctx context.Context, addr string, creds *DatabaseCredentials) (string, error)
+
+var databaseEnginePings = map[dbaasv1.EngineType]databasePing{
+	"pxc":        pingMySQL,
+	"psmdb":      pingMongoDB,
+	"postgresql": pingPostgres,
+}
+
+// PingDatabaseCluster forwards a local port to a running pod of the database cluster
+// serving the engine port and connects to the database with the driver of the engine as
+// the admin user, so it is known that the cluster accepts connections and not only that
+// it reports readiness.
+func (k *Kubernetes) PingDatabaseCluster(ctx context.Context, name string) (*DatabasePing, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	cluster, err := k.client.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	ping, ok := databaseEnginePings[cluster.Spec.Database]
+	if !ok {
+		return nil, errors.Errorf("connection check of %q database engine is not supported", cluster.Spec.Database)
+	}
+	creds, err := k.databaseCredentials(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := k.client.GetPods(ctx, cluster.Namespace, &metav1.LabelSelector{
+		MatchLabels: map[string]string{instanceLabelKey: name},
+	})
+	if err != nil {
+		return nil, classifyError(errors.Wrapf(err, "cannot list pods of database cluster %s", name))
+	}
+	pod := servingPod(pods.Items, creds.Port)
+	if pod == "" {
+		return nil, errors.Errorf("database cluster %s has no running pod serving port %d", name, creds.Port)
+	}
+
+	fw, err := k.client.ForwardPort(ctx, cluster.Namespace, pod, 0, creds.Port)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	defer fw.Stop()
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	started := time.Now()
+	version, err := ping(ctx, net.JoinHostPort("127.0.0.1", strconv.Itoa(int(fw.Local))), creds)
+	if err != nil {
+		return nil, errors.Wrapf(err, "database on pod %s does not accept connections", pod)
+	}
+	return &DatabasePing{
+		Engine:  string(cluster.Spec.Database),
+		Pod:     pod,
+		Version: version,
+		Latency: time.Since(started),
+	}, nil
+}
+
+// servingPod returns the name of a running and ready pod with a container serving the port.
+func servingPod(pods []corev1.Pod, port int) string {
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning || !podReady(pod) {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			for _, p := range container.Ports {
+				if int(p.ContainerPort) == port {
+					return pod.Name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// mysqlDSN returns the data source name of the go-sql-driver for the address.
+func mysqlDSN(addr string, creds *DatabaseCredentials) string {
+	cfg := mysql.NewConfig()
+	cfg.Net = "tcp"
+	cfg.Addr = addr
+	cfg.User = creds.User
+	cfg.Passwd = creds.Password
+	cfg.Timeout = pingTimeout
+	return cfg.FormatDSN()
+}
+
+func pingMySQL(ctx context.Context, addr string, creds *DatabaseCredentials) (string, error) {
+	db, err := sql.Open("mysql", mysqlDSN(addr, creds))
+	if err != nil {
+		return "", err
+	}
+	defer db.Close() //nolint:errcheck
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// mongoDBURI returns the URI connecting directly to the member listening on the address.
+func mongoDBURI(addr string, creds *DatabaseCredentials) string {
+	return (&url.URL{
+		Scheme:   "mongodb",
+		User:     url.UserPassword(creds.User, creds.Password),
+		Host:     addr,
+		Path:     "/",
+		RawQuery: "authSource=admin&directConnection=true",
+	}).String()
+}
+
+func pingMongoDB(ctx context.Context, addr string, creds *DatabaseCredentials) (string, error) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoDBURI(addr, creds)))
+	if err != nil {
+		return "", err
+	}
+	defer client.Disconnect(context.Background()) //nolint:errcheck
+	var info struct {
+		Version string `bson:"version"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+// postgresURL returns the connection URL of the postgres database on the address.
+// The address is a forwarded local port, so TLS is not required.
+func postgresURL(addr string, creds *DatabaseCredentials) string {
+	return (&url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(creds.User, creds.Password),
+		Host:     addr,
+		Path:     "/postgres",
+		RawQuery: "sslmode=prefer",
+	}).String()
+}
+
+func pingPostgres(ctx context.Context, addr string, creds *DatabaseCredentials) (string, error) {
+	conn, err := pgx.Connect(ctx, postgresURL(addr, creds))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close(context.Background()) //nolint:errcheck
+	var version string
+	if err := conn.QueryRow(ctx, "SHOW server_version").Scan(&version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConnectionStrings(t *testing.T) {
+	creds := &DatabaseCredentials{User: "root", Password: "p@ss:w/rd"}
+
+	cfg, err := mysql.ParseDSN(mysqlDSN("127.0.0.1:33060", creds))
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:33060", cfg.Addr)
+	assert.Equal(t, "p@ss:w/rd", cfg.Passwd)
+
+	for _, uri := range []string{mongoDBURI("127.0.0.1:27018", creds), postgresURL("127.0.0.1:5433", creds)} {
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+		password, _ := u.User.Password()
+		assert.Equal(t, "p@ss:w/rd", password)
+	}
+	u, err := url.Parse(mongoDBURI("127.0.0.1:27018", creds))
+	require.NoError(t, err)
+	assert.Equal(t, "true", u.Query().Get("directConnection"))
+}
+
+func TestServingPod(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase, ready corev1.ConditionStatus, port int32) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Ports: []corev1.ContainerPort{{ContainerPort: port}},
+			}}},
+			Status: corev1.PodStatus{
+				Phase:      phase,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+	pods := []corev1.Pod{
+		pod("mysql-pxc-0", corev1.PodRunning, corev1.ConditionFalse, mysqlPort),
+		pod("mysql-backup", corev1.PodRunning, corev1.ConditionTrue, 8080),
+		pod("mysql-haproxy-0", corev1.PodRunning, corev1.ConditionTrue, mysqlPort),
+	}
+	assert.Equal(t, "mysql-haproxy-0", servingPod(pods, mysqlPort))
+	assert.Equal(t, "", servingPod(pods, mongoDBPort))
+}
@@ -0,0 +1,213 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client/database"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	databaseClusterRestoreKind = "DatabaseClusterRestore"
+
+	// pitrTypeDate restores the cluster to a point in time.
+	pitrTypeDate = "date"
+	// pitrDateLayout is the format of the point in time expected by the operators, in UTC.
+	pitrDateLayout = "2006-01-02 15:04:05"
+)
+
+// PITRSettings configures point-in-time recovery of a database cluster. The binary
+// logs of PXC or the oplog of PSMDB are uploaded continuously to the backup storage,
+// so the cluster can be restored to any point in time after a backup.
+type PITRSettings struct {
+	Enabled bool
+	// Storage is the backup storage of the cluster the logs are uploaded to.
+	Storage string
+	// UploadInterval is the time between uploads of the binary logs of PXC clusters.
+	// The default of the operator is used if it is zero.
+	UploadInterval time.Duration
+}
+
+// RestoreOptions selects the backup and the point in time a database cluster is restored to.
+type RestoreOptions struct {
+	Cluster string
+	// Backup is the name of the backup restored. The latest succeeded backup of the cluster
+	// taken before the point in time is restored if it is empty.
+	Backup string
+	// PointInTime is the time the cluster is restored to using the uploaded logs. The backup
+	// is restored as is if it is zero.
+	PointInTime time.Time
+}
+
+// ConfigureDatabaseClusterPITR enables or disables point-in-time recovery of the database cluster.
+// The settings are patched into the spec since the Go types of the cluster do not carry them.
+func (k *Kubernetes) ConfigureDatabaseClusterPITR(ctx context.Context, name string, settings PITRSettings) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	cluster, err := k.client.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return classifyError(err)
+	}
+	pitr, err := pitrSpec(cluster, settings)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"backup": map[string]interface{}{"pitr": pitr},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = k.client.PatchDatabaseCluster(ctx, name, types.MergePatchType, patch)
+	return classifyError(errors.Wrapf(err, "cannot configure point-in-time recovery of database cluster %s", name))
+}
+
+// pitrSpec validates the settings against the backup configuration of the cluster and returns
+// the point-in-time recovery spec of the cluster.
+func pitrSpec(cluster *dbaasv1.DatabaseCluster, settings PITRSettings) (*database.PITRSpec, error) {
+	if !settings.Enabled {
+		return &database.PITRSpec{}, nil
+	}
+	switch cluster.Spec.Database {
+	case "pxc", "psmdb":
+	default:
+		return nil, errors.Errorf("point-in-time recovery of %q database engine is not supported", cluster.Spec.Database)
+	}
+	if cluster.Spec.Backup == nil || !cluster.Spec.Backup.Enabled {
+		return nil, errors.Errorf("backups of database cluster %s are disabled, point-in-time recovery needs a backup to start from", cluster.Name)
+	}
+	if settings.Storage == "" {
+		return nil, errors.New("backup storage of point-in-time recovery is required")
+	}
+	if _, ok := cluster.Spec.Backup.Storages[settings.Storage]; !ok {
+		return nil, errors.Errorf("database cluster %s has no backup storage %q", cluster.Name, settings.Storage)
+	}
+	if settings.UploadInterval < 0 {
+		return nil, errors.Errorf("invalid upload interval %s", settings.UploadInterval)
+	}
+	if settings.UploadInterval > 0 && cluster.Spec.Database != "pxc" {
+		return nil, errors.Errorf("upload interval is supported by pxc database clusters only, %s uploads the oplog continuously", cluster.Spec.Database)
+	}
+	pitr := &database.PITRSpec{Enabled: true, StorageName: settings.Storage}
+	if settings.UploadInterval > 0 {
+		pitr.TimeBetweenUploads = settings.UploadInterval.Seconds()
+	}
+	return pitr, nil
+}
+
+// RestoreDatabaseCluster creates a restore of the database cluster from a backup and, if a point
+// in time is requested, the logs uploaded after the backup.
+func (k *Kubernetes) RestoreDatabaseCluster(ctx context.Context, opts RestoreOptions) (*database.DatabaseClusterRestore, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	cluster, err := k.client.GetDatabaseCluster(ctx, opts.Cluster)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	backups, err := k.client.ListDatabaseClusterBackups(ctx)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, "cannot list database cluster backups"))
+	}
+	backup, err := restoredBackup(backups.Items, opts)
+	if err != nil {
+		return nil, err
+	}
+	restore := buildRestore(cluster, backup, opts.PointInTime, time.Now())
+	if err := k.client.ApplyObject(ctx, restore); err != nil {
+		return nil, classifyError(errors.Wrapf(err, "cannot restore database cluster %s", opts.Cluster))
+	}
+	return restore, nil
+}
+
+// restoredBackup returns the backup of the options. If no backup is selected, the latest
+// succeeded backup of the cluster taken before the point in time is returned.
+func restoredBackup(backups []database.DatabaseClusterBackup, opts RestoreOptions) (*database.DatabaseClusterBackup, error) {
+	var latest *database.DatabaseClusterBackup
+	for i := range backups {
+		b := &backups[i]
+		if b.Spec.DBClusterName != opts.Cluster {
+			continue
+		}
+		if opts.Backup != "" {
+			if b.Name != opts.Backup {
+				continue
+			}
+			if string(b.Status.State) != backupStateSucceeded {
+				return nil, errors.Errorf("backup %s is in %q state, only succeeded backups can be restored", b.Name, b.Status.State)
+			}
+			if !opts.PointInTime.IsZero() && !b.CreationTimestamp.Time.Before(opts.PointInTime) {
+				return nil, errors.Errorf("backup %s was taken after %s", b.Name, opts.PointInTime.Format(time.RFC3339))
+			}
+			return b, nil
+		}
+		if string(b.Status.State) != backupStateSucceeded {
+			continue
+		}
+		if !opts.PointInTime.IsZero() && !b.CreationTimestamp.Time.Before(opts.PointInTime) {
+			continue
+		}
+		if latest == nil || b.CreationTimestamp.After(latest.CreationTimestamp.Time) {
+			latest = b
+		}
+	}
+	switch {
+	case opts.Backup != "":
+		return nil, errors.Errorf("database cluster %s has no backup %s", opts.Cluster, opts.Backup)
+	case latest == nil && !opts.PointInTime.IsZero():
+		return nil, errors.Errorf("database cluster %s has no succeeded backup taken before %s", opts.Cluster, opts.PointInTime.Format(time.RFC3339))
+	case latest == nil:
+		return nil, errors.Errorf("database cluster %s has no succeeded backup", opts.Cluster)
+	}
+	return latest, nil
+}
+
+// buildRestore returns the restore of the cluster from the backup and up to the point in time if it is set.
+func buildRestore(cluster *dbaasv1.DatabaseCluster, backup *database.DatabaseClusterBackup, pointInTime, now time.Time) *database.DatabaseClusterRestore {
+	restore := &database.DatabaseClusterRestore{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: databaseClusterAPIVersion,
+			Kind:       databaseClusterRestoreKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-restore-%d", cluster.Name, now.Unix()),
+			Namespace: cluster.Namespace,
+		},
+		Spec: database.DatabaseClusterRestoreSpec{
+			DatabaseClusterRestoreSpec: dbaasv1.DatabaseClusterRestoreSpec{
+				DatabaseCluster: cluster.Name,
+				DatabaseType:    cluster.Spec.Database,
+				BackupName:      backup.Name,
+			},
+		},
+	}
+	if !pointInTime.IsZero() {
+		restore.Spec.PITR = &database.PITR{
+			Type: pitrTypeDate,
+			Date: pointInTime.UTC().Format(pitrDateLayout),
+		}
+	}
+	return restore
+}
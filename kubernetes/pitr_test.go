@@ -0,0 +1,102 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client/database"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPITRSpec(t *testing.T) {
+	t.Parallel()
+	cluster := func(engine dbaasv1.EngineType) *dbaasv1.DatabaseCluster {
+		c := &dbaasv1.DatabaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "db"}}
+		c.Spec.Database = engine
+		c.Spec.Backup = &dbaasv1.BackupSpec{
+			Enabled:  true,
+			Storages: map[string]*dbaasv1.BackupStorageSpec{"s3": {}},
+		}
+		return c
+	}
+
+	pitr, err := pitrSpec(cluster("pxc"), PITRSettings{Enabled: true, Storage: "s3", UploadInterval: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, &database.PITRSpec{Enabled: true, StorageName: "s3", TimeBetweenUploads: 60}, pitr)
+
+	pitr, err = pitrSpec(cluster("pxc"), PITRSettings{})
+	require.NoError(t, err)
+	assert.False(t, pitr.Enabled)
+
+	_, err = pitrSpec(cluster("pxc"), PITRSettings{Enabled: true, Storage: "gcs"})
+	assert.ErrorContains(t, err, `no backup storage "gcs"`)
+	_, err = pitrSpec(cluster("psmdb"), PITRSettings{Enabled: true, Storage: "s3", UploadInterval: time.Minute})
+	assert.ErrorContains(t, err, "pxc database clusters only")
+	disabled := cluster("psmdb")
+	disabled.Spec.Backup.Enabled = false
+	_, err = pitrSpec(disabled, PITRSettings{Enabled: true, Storage: "s3"})
+	assert.ErrorContains(t, err, "backups of database cluster db are disabled")
+	_, err = pitrSpec(&dbaasv1.DatabaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "db"}, Spec: dbaasv1.DatabaseSpec{Database: "pxc"}}, PITRSettings{Enabled: true, Storage: "s3"})
+	assert.ErrorContains(t, err, "backups of database cluster db are disabled")
+}
+
+func TestRestoredBackup(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+	backup := func(name, cluster, state string, age time.Duration) database.DatabaseClusterBackup {
+		b := database.DatabaseClusterBackup{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(now.Add(-age)),
+		}}
+		b.Spec.DBClusterName = cluster
+		b.Status.State = database.BackupState(state)
+		return b
+	}
+	backups := []database.DatabaseClusterBackup{
+		backup("db-1", "db", "Succeeded", 48*time.Hour),
+		backup("db-2", "db", "Succeeded", 24*time.Hour),
+		backup("db-3", "db", "Failed", 12*time.Hour),
+		backup("other-1", "other", "Succeeded", time.Hour),
+	}
+
+	b, err := restoredBackup(backups, RestoreOptions{Cluster: "db"})
+	require.NoError(t, err)
+	assert.Equal(t, "db-2", b.Name)
+
+	b, err = restoredBackup(backups, RestoreOptions{Cluster: "db", PointInTime: now.Add(-30 * time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, "db-1", b.Name)
+
+	_, err = restoredBackup(backups, RestoreOptions{Cluster: "db", Backup: "db-3"})
+	assert.ErrorContains(t, err, "only succeeded backups")
+	_, err = restoredBackup(backups, RestoreOptions{Cluster: "db", Backup: "db-2", PointInTime: now.Add(-30 * time.Hour)})
+	assert.ErrorContains(t, err, "was taken after")
+	_, err = restoredBackup(backups, RestoreOptions{Cluster: "db", PointInTime: now.Add(-72 * time.Hour)})
+	assert.Error(t, err)
+
+	cluster := &dbaasv1.DatabaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}}
+	cluster.Spec.Database = "pxc"
+	restore := buildRestore(cluster, &backups[1], time.Date(2023, 5, 10, 14, 30, 0, 0, time.FixedZone("CEST", 2*60*60)), now)
+	assert.Equal(t, "db-restore-1683720000", restore.Name)
+	assert.Equal(t, "db-2", restore.Spec.BackupName)
+	require.NotNil(t, restore.Spec.PITR)
+	assert.Equal(t, "2023-05-10 12:30:00", restore.Spec.PITR.Date)
+}
@@ -0,0 +1,208 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	componentLabelKey = "app.kubernetes.io/component"
+	replsetLabelKey   = "app.kubernetes.io/replset"
+)
+
+// Placement spreads the pods of a database cluster over failure domains.
+type Placement struct {
+	// TopologyKey is the node label of the failure domain, e.g. TopologyKeyZone.
+	TopologyKey string
+	// Required refuses scheduling two pods into the same failure domain. Distinct
+	// domains are only preferred otherwise.
+	Required bool
+	// MaxSkew is the maximum difference of the number of pods between failure domains.
+	// It defaults to 1.
+	MaxSkew int32
+}
+
+// engineCluster describes the custom resource the operator of an engine creates for
+// a database cluster.
+type engineCluster struct {
+	gvr schema.GroupVersionResource
+	// components are the fields of the spec holding the pod specs of the database and the
+	// proxies. The field names are the component labels of the pods.
+	components []string
+	// replsets is true if the database pods are specified per replica set in spec.replsets.
+	replsets bool
+}
+
+var engineClusters = map[dbaasv1.EngineType]engineCluster{
+	"pxc": {
+		gvr:        schema.GroupVersionResource{Group: "pxc.percona.com", Version: "v1", Resource: "perconaxtradbclusters"},
+		components: []string{"pxc", "haproxy", "proxysql"},
+	},
+	"psmdb": {
+		gvr:      schema.GroupVersionResource{Group: "psmdb.percona.com", Version: "v1", Resource: "perconaservermongodbs"},
+		replsets: true,
+	},
+}
+
+// SetDatabaseClusterPlacement spreads the pods of the database cluster with anti-affinity and
+// topology spread constraints set in the custom resource the engine operator created for the
+// cluster. It waits for the operator to create the resource until the context is done.
+func (k *Kubernetes) SetDatabaseClusterPlacement(ctx context.Context, name string, placement Placement) error {
+	cluster, err := k.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return classifyError(err)
+	}
+	engine, ok := engineClusters[cluster.Spec.Database]
+	if !ok {
+		return errors.Errorf("placement of %q database engine is not supported", cluster.Spec.Database)
+	}
+	namespace := k.clusterNamespace(cluster)
+
+	var obj *unstructured.Unstructured
+	err = wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+		list, err := k.client.ListCRs(ctx, namespace, engine.gvr, nil)
+		if err != nil {
+			return false, errors.Wrapf(err, "cannot list %s", engine.gvr.Resource)
+		}
+		for i := range list.Items {
+			if list.Items[i].GetName() == name {
+				obj = &list.Items[i]
+				return true, nil
+			}
+		}
+		return false, nil
+	}, ctx.Done())
+	if err != nil {
+		return classifyError(errors.Wrapf(err, "the operator did not create %s of database cluster %s", engine.gvr.Resource, name))
+	}
+	if err := spreadEngineCluster(obj, engine, placement); err != nil {
+		return err
+	}
+	unstructured.RemoveNestedField(obj.Object, "status")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "resourceVersion")
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	return classifyError(errors.Wrapf(k.client.ApplyObject(ctx, obj), "cannot set placement of database cluster %s", name))
+}
+
+// spreadEngineCluster sets the placement in the pod specs of the database and proxy pods
+// present in the engine cluster.
+func spreadEngineCluster(obj *unstructured.Unstructured, engine engineCluster, placement Placement) error {
+	name := obj.GetName()
+	for _, component := range engine.components {
+		spec, ok, err := unstructured.NestedMap(obj.Object, "spec", component)
+		if err != nil || !ok {
+			continue
+		}
+		if enabled, ok := spec["enabled"].(bool); ok && !enabled {
+			continue
+		}
+		if err := setPodPlacement(spec, placement, map[string]string{instanceLabelKey: name, componentLabelKey: component}); err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedMap(obj.Object, spec, "spec", component); err != nil {
+			return err
+		}
+	}
+	if !engine.replsets {
+		return nil
+	}
+	replsets, _, err := unstructured.NestedSlice(obj.Object, "spec", "replsets")
+	if err != nil {
+		return errors.Wrapf(err, "invalid replica sets of database cluster %s", name)
+	}
+	for _, rs := range replsets {
+		spec, ok := rs.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rsName, _ := spec["name"].(string)
+		labels := map[string]string{instanceLabelKey: name, componentLabelKey: "mongod", replsetLabelKey: rsName}
+		if err := setPodPlacement(spec, placement, labels); err != nil {
+			return err
+		}
+	}
+	return unstructured.SetNestedSlice(obj.Object, replsets, "spec", "replsets")
+}
+
+// setPodPlacement sets the anti-affinity and the topology spread constraints of the pods with
+// the labels in the pod spec of a Percona operator. The operators take the affinity of the Kubernetes
+// API in the advanced field.
+func setPodPlacement(spec map[string]interface{}, placement Placement, labels map[string]string) error {
+	affinity, err := runtime.DefaultUnstructuredConverter.ToUnstructured(placement.affinity(labels))
+	if err != nil {
+		return err
+	}
+	spec["affinity"] = map[string]interface{}{"advanced": affinity}
+	constraints := placement.topologySpreadConstraints(labels)
+	spread := make([]interface{}, 0, len(constraints))
+	for i := range constraints {
+		c, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&constraints[i])
+		if err != nil {
+			return err
+		}
+		spread = append(spread, c)
+	}
+	spec["topologySpreadConstraints"] = spread
+	return nil
+}
+
+// affinity keeps the pods with the labels out of the failure domains running one of them already.
+func (p Placement) affinity(labels map[string]string) *corev1.Affinity {
+	term := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: labels},
+		TopologyKey:   p.TopologyKey,
+	}
+	antiAffinity := &corev1.PodAntiAffinity{}
+	if p.Required {
+		antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = []corev1.PodAffinityTerm{term}
+	} else {
+		antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = []corev1.WeightedPodAffinityTerm{{
+			Weight:          100,
+			PodAffinityTerm: term,
+		}}
+	}
+	return &corev1.Affinity{PodAntiAffinity: antiAffinity}
+}
+
+// topologySpreadConstraints balance the pods with the labels over the failure domains.
+func (p Placement) topologySpreadConstraints(labels map[string]string) []corev1.TopologySpreadConstraint {
+	maxSkew := p.MaxSkew
+	if maxSkew <= 0 {
+		maxSkew = 1
+	}
+	whenUnsatisfiable := corev1.ScheduleAnyway
+	if p.Required {
+		whenUnsatisfiable = corev1.DoNotSchedule
+	}
+	return []corev1.TopologySpreadConstraint{{
+		MaxSkew:           maxSkew,
+		TopologyKey:       p.TopologyKey,
+		WhenUnsatisfiable: whenUnsatisfiable,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+	}}
+}
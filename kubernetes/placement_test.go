@@ -0,0 +1,85 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/fake"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSetDatabaseClusterPlacement(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	cluster := &dbaasv1.DatabaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"}}
+	cluster.Spec.Database = "pxc"
+	pxc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "pxc.percona.com/v1",
+		"kind":       "PerconaXtraDBCluster",
+		"metadata":   map[string]interface{}{"name": "orders", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"pxc":      map[string]interface{}{"size": int64(3)},
+			"haproxy":  map[string]interface{}{"enabled": true, "size": int64(2)},
+			"proxysql": map[string]interface{}{"enabled": false},
+		},
+	}}
+	c, err := fake.NewKubeClient("default", cluster, pxc)
+	require.NoError(t, err)
+	k := NewWithClient(c)
+
+	require.NoError(t, k.SetDatabaseClusterPlacement(ctx, "orders", Placement{TopologyKey: TopologyKeyZone, Required: true}))
+	list, err := c.ListCRs(ctx, "default", engineClusters["pxc"].gvr, nil)
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	obj := list.Items[0].Object
+
+	terms, _, _ := unstructured.NestedSlice(obj, "spec", "pxc", "affinity", "advanced", "podAntiAffinity",
+		"requiredDuringSchedulingIgnoredDuringExecution")
+	require.Len(t, terms, 1)
+	assert.Equal(t, TopologyKeyZone, terms[0].(map[string]interface{})["topologyKey"])
+	spread, _, _ := unstructured.NestedSlice(obj, "spec", "haproxy", "topologySpreadConstraints")
+	require.Len(t, spread, 1)
+	assert.Equal(t, "DoNotSchedule", spread[0].(map[string]interface{})["whenUnsatisfiable"])
+	_, ok, _ := unstructured.NestedMap(obj, "spec", "proxysql", "affinity")
+	assert.False(t, ok)
+}
+
+func TestSpreadEngineClusterReplsets(t *testing.T) {
+	t.Parallel()
+	psmdb := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "sessions"},
+		"spec": map[string]interface{}{
+			"replsets": []interface{}{map[string]interface{}{"name": "rs0", "size": int64(3)}},
+		},
+	}}
+	require.NoError(t, spreadEngineCluster(psmdb, engineClusters["psmdb"], Placement{TopologyKey: TopologyKeyHostname, MaxSkew: 2}))
+	replsets, _, _ := unstructured.NestedSlice(psmdb.Object, "spec", "replsets")
+	rs := replsets[0].(map[string]interface{})
+	terms, _, _ := unstructured.NestedSlice(rs, "affinity", "advanced", "podAntiAffinity", "preferredDuringSchedulingIgnoredDuringExecution")
+	require.Len(t, terms, 1)
+	labels, _, _ := unstructured.NestedStringMap(terms[0].(map[string]interface{}), "podAffinityTerm", "labelSelector", "matchLabels")
+	assert.Equal(t, map[string]string{instanceLabelKey: "sessions", componentLabelKey: "mongod", replsetLabelKey: "rs0"}, labels)
+	spread, _, _ := unstructured.NestedSlice(rs, "topologySpreadConstraints")
+	require.Len(t, spread, 1)
+	assert.Equal(t, int64(2), spread[0].(map[string]interface{})["maxSkew"])
+	assert.Equal(t, "ScheduleAnyway", spread[0].(map[string]interface{})["whenUnsatisfiable"])
+}
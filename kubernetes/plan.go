@@ -0,0 +1,220 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PlanAction is what provisioning does to bring an object to the desired state.
+type PlanAction string
+
+const (
+	// PlanCreate creates a missing object.
+	PlanCreate PlanAction = "create"
+	// PlanUpdate changes an object which differs from the desired state.
+	PlanUpdate PlanAction = "update"
+	// PlanApprove approves a pending install plan.
+	PlanApprove PlanAction = "approve"
+	// PlanRepair reinstalls an object which exists but is not healthy.
+	PlanRepair PlanAction = "repair"
+)
+
+// DesiredState is the state of the cluster provisioning converges to.
+type DesiredState struct {
+	CatalogNamespace string
+	Catalog          string
+	// CatalogImage is the image the catalog source is pinned to. The image is not compared if it is empty.
+	CatalogImage string
+	Operators    []InstallOperatorRequest
+	// MonitoringEndpoint is the PMM endpoint metrics are sent to. Monitoring is not compared if it is empty.
+	MonitoringEndpoint string
+}
+
+// PlanChange is a difference between the desired and the current state of an object.
+type PlanChange struct {
+	Action  PlanAction `json:"action"`
+	Kind    string     `json:"kind"`
+	Name    string     `json:"name"`
+	Current string     `json:"current,omitempty"`
+	Desired string     `json:"desired,omitempty"`
+	Reason  string     `json:"reason"`
+}
+
+// ProvisionPlan lists the changes provisioning makes to the cluster.
+type ProvisionPlan struct {
+	Changes []PlanChange `json:"changes"`
+}
+
+func (p *ProvisionPlan) add(action PlanAction, kind, name, current, desired, reason string) {
+	p.Changes = append(p.Changes, PlanChange{
+		Action:  action,
+		Kind:    kind,
+		Name:    name,
+		Current: current,
+		Desired: desired,
+		Reason:  reason,
+	})
+}
+
+// PlanProvisioning compares the desired state with the catalog source, the subscriptions,
+// cluster service versions and deployments of the operators and the VM agents in the cluster.
+// Nothing is changed; the plan lists what provisioning would do.
+func (k *Kubernetes) PlanProvisioning(ctx context.Context, desired DesiredState) (*ProvisionPlan, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	plan := &ProvisionPlan{Changes: []PlanChange{}}
+	if err := k.planCatalog(ctx, plan, desired); err != nil {
+		return nil, classifyError(err)
+	}
+	for _, req := range desired.Operators {
+		if err := k.planOperator(ctx, plan, req); err != nil {
+			return nil, classifyError(err)
+		}
+	}
+	if desired.MonitoringEndpoint != "" {
+		if err := k.planMonitoring(ctx, plan, desired.MonitoringEndpoint); err != nil {
+			return nil, classifyError(err)
+		}
+	}
+	return plan, nil
+}
+
+func (k *Kubernetes) planCatalog(ctx context.Context, plan *ProvisionPlan, desired DesiredState) error {
+	name := desired.CatalogNamespace + "/" + desired.Catalog
+	cs, err := k.client.GetCatalogSource(ctx, desired.CatalogNamespace, desired.Catalog)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			plan.add(PlanCreate, "CatalogSource", name, "", desired.CatalogImage, "catalog source does not exist")
+			return nil
+		}
+		return errors.Wrap(err, "cannot get catalog source")
+	}
+	if desired.CatalogImage != "" && cs.Spec.Image != desired.CatalogImage {
+		plan.add(PlanUpdate, "CatalogSource", name, cs.Spec.Image, desired.CatalogImage, "catalog image differs from the snapshot")
+	}
+	return nil
+}
+
+func (k *Kubernetes) planOperator(ctx context.Context, plan *ProvisionPlan, req InstallOperatorRequest) error {
+	sub, err := k.client.GetSubscription(ctx, req.Namespace, req.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			plan.add(PlanCreate, "Subscription", req.Name, "", subscriptionTarget(req.Channel, req.StartingCSV), "operator is not installed")
+			return nil
+		}
+		return errors.Wrapf(err, "cannot get subscription for %q operator", req.Name)
+	}
+	if sub.Spec == nil || sub.Spec.Channel != req.Channel || sub.Spec.CatalogSource != req.CatalogSource ||
+		sub.Spec.CatalogSourceNamespace != req.CatalogSourceNamespace {
+		current := ""
+		if sub.Spec != nil {
+			current = fmt.Sprintf("%s from %s/%s", sub.Spec.Channel, sub.Spec.CatalogSourceNamespace, sub.Spec.CatalogSource)
+		}
+		plan.add(PlanUpdate, "Subscription", req.Name, current,
+			fmt.Sprintf("%s from %s/%s", req.Channel, req.CatalogSourceNamespace, req.CatalogSource), "channel or catalog differs")
+	}
+
+	installed := sub.Status.InstalledCSV
+	if sub.Status.Install != nil && sub.Status.Install.Name != "" {
+		ip, err := k.client.GetInstallPlan(ctx, req.Namespace, sub.Status.Install.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "cannot get install plan for %q operator", req.Name)
+		}
+		if err == nil && !ip.Spec.Approved && ip.Status.Phase != v1alpha1.InstallPlanPhaseComplete {
+			plan.add(PlanApprove, "InstallPlan", ip.Name, installed, strings.Join(ip.Spec.ClusterServiceVersionNames, ", "),
+				"install plan waits for approval")
+		}
+	}
+	if installed == "" {
+		plan.add(PlanCreate, "ClusterServiceVersion", req.Name, "", req.StartingCSV, "no cluster service version is installed")
+		return nil
+	}
+	if req.StartingCSV != "" && installed != req.StartingCSV {
+		plan.add(PlanUpdate, "ClusterServiceVersion", req.Name, installed, req.StartingCSV, "installed version differs from the snapshot")
+	}
+	csv, err := k.client.GetClusterServiceVersion(ctx, types.NamespacedName{Namespace: req.Namespace, Name: installed})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			plan.add(PlanRepair, "ClusterServiceVersion", installed, "missing", string(v1alpha1.CSVPhaseSucceeded),
+				"installed cluster service version does not exist")
+			return nil
+		}
+		return errors.Wrapf(err, "cannot get cluster service version %q", installed)
+	}
+	if csv.Status.Phase != v1alpha1.CSVPhaseSucceeded {
+		plan.add(PlanRepair, "ClusterServiceVersion", installed, string(csv.Status.Phase), string(v1alpha1.CSVPhaseSucceeded),
+			csv.Status.Message)
+	}
+	for _, spec := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		deployment, err := k.client.GetDeployment(ctx, spec.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				plan.add(PlanRepair, "Deployment", spec.Name, "missing", "", "deployment of the operator does not exist")
+				continue
+			}
+			return errors.Wrapf(err, "cannot get %s deployment", spec.Name)
+		}
+		if deployment.Status.ReadyReplicas < deployment.Status.Replicas || deployment.Status.Replicas == 0 {
+			plan.add(PlanRepair, "Deployment", spec.Name,
+				fmt.Sprintf("%d/%d ready", deployment.Status.ReadyReplicas, deployment.Status.Replicas), "",
+				"operator is not ready")
+		}
+	}
+	return nil
+}
+
+// subscriptionTarget describes the channel and the pinned version of a subscription.
+func subscriptionTarget(channel, csv string) string {
+	if csv == "" {
+		return channel
+	}
+	return channel + " at " + csv
+}
+
+func (k *Kubernetes) planMonitoring(ctx context.Context, plan *ProvisionPlan, endpoint string) error {
+	list, err := k.client.ListVMAgents(ctx, useDefaultNamespace, nil)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "cannot list VM agents")
+	}
+	desired := remoteWriteURL(endpoint)
+	found := false
+	if list != nil {
+		for _, agent := range list.Items {
+			if !strings.HasPrefix(agent.Name, vmAgentNamePrefix) {
+				continue
+			}
+			found = true
+			for _, rw := range agent.Spec.RemoteWrite {
+				if rw.URL != desired {
+					plan.add(PlanUpdate, "VMAgent", agent.Name, rw.URL, desired, "metrics are sent to another PMM endpoint, it is switched by provisioning with --force")
+				}
+			}
+		}
+	}
+	if !found {
+		plan.add(PlanCreate, "VMAgent", vmAgentNamePrefix+"*", "", desired, "monitoring is not provisioned")
+	}
+	return nil
+}
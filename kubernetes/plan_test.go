@@ -0,0 +1,99 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	vmv1beta1 "github.com/VictoriaMetrics/operator/api/victoriametrics/v1beta1"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestPlanProvisioning(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+
+	k := NewEmpty()
+	k.client = k8sclient
+
+	notFound := apierrors.NewNotFound(schema.GroupResource{Group: "operators.coreos.com", Resource: "subscriptions"}, "")
+	k8sclient.On("GetCatalogSource", ctx, "olm", "percona-dbaas-catalog").Return(&v1alpha1.CatalogSource{
+		Spec: v1alpha1.CatalogSourceSpec{Image: "percona/dbaas-catalog:latest"},
+	}, nil)
+	k8sclient.On("GetSubscription", ctx, "default", "victoriametrics-operator").Return(nil, notFound)
+	k8sclient.On("GetSubscription", ctx, "default", "dbaas-operator").Return(&v1alpha1.Subscription{
+		Spec: &v1alpha1.SubscriptionSpec{Channel: "stable-v0", CatalogSource: "percona-dbaas-catalog", CatalogSourceNamespace: "olm"},
+		Status: v1alpha1.SubscriptionStatus{
+			InstalledCSV: "dbaas-operator.v0.1.9",
+			Install:      &v1alpha1.InstallPlanReference{Name: "install-abcde"},
+		},
+	}, nil)
+	k8sclient.On("GetInstallPlan", ctx, "default", "install-abcde").Return(&v1alpha1.InstallPlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "install-abcde"},
+		Spec:       v1alpha1.InstallPlanSpec{ClusterServiceVersionNames: []string{"dbaas-operator.v0.1.10"}},
+	}, nil)
+	csv := &v1alpha1.ClusterServiceVersion{Status: v1alpha1.ClusterServiceVersionStatus{Phase: v1alpha1.CSVPhaseSucceeded}}
+	csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{{Name: "dbaas-operator"}}
+	k8sclient.On("GetClusterServiceVersion", ctx, types.NamespacedName{Namespace: "default", Name: "dbaas-operator.v0.1.9"}).Return(csv, nil)
+	k8sclient.On("GetDeployment", ctx, "dbaas-operator").Return(&appsv1.Deployment{
+		Status: appsv1.DeploymentStatus{Replicas: 1, ReadyReplicas: 0},
+	}, nil)
+	k8sclient.On("ListVMAgents", ctx, "", map[string]string(nil)).Return(&vmv1beta1.VMAgentList{
+		Items: []vmv1beta1.VMAgent{{
+			ObjectMeta: metav1.ObjectMeta{Name: vmAgentNamePrefix + "pmm"},
+			Spec: vmv1beta1.VMAgentSpec{RemoteWrite: []vmv1beta1.VMAgentRemoteWriteSpec{
+				{URL: "https://old-pmm.example.com/victoriametrics/api/v1/write"},
+			}},
+		}},
+	}, nil)
+
+	req := InstallOperatorRequest{Namespace: "default", CatalogSource: "percona-dbaas-catalog", CatalogSourceNamespace: "olm"}
+	vmReq, dbaasReq := req, req
+	vmReq.Name, vmReq.Channel = "victoriametrics-operator", "stable-v0"
+	dbaasReq.Name, dbaasReq.Channel, dbaasReq.StartingCSV = "dbaas-operator", "stable-v0", "dbaas-operator.v0.1.10"
+	plan, err := k.PlanProvisioning(ctx, DesiredState{
+		CatalogNamespace:   "olm",
+		Catalog:            "percona-dbaas-catalog",
+		CatalogImage:       "percona/dbaas-catalog@sha256:0123",
+		Operators:          []InstallOperatorRequest{vmReq, dbaasReq},
+		MonitoringEndpoint: "https://pmm.example.com",
+	})
+	require.NoError(t, err)
+
+	var got []string
+	for _, change := range plan.Changes {
+		got = append(got, string(change.Action)+" "+change.Kind+" "+change.Name)
+	}
+	assert.Equal(t, []string{
+		"update CatalogSource olm/percona-dbaas-catalog",
+		"create Subscription victoriametrics-operator",
+		"approve InstallPlan install-abcde",
+		"update ClusterServiceVersion dbaas-operator",
+		"repair Deployment dbaas-operator",
+		"update VMAgent pmm-vmagent-pmm",
+	}, got)
+	assert.Equal(t, "https://pmm.example.com/victoriametrics/api/v1/write", plan.Changes[5].Desired)
+	k8sclient.AssertExpectations(t)
+}
@@ -0,0 +1,181 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// portForwardMinBackoff and portForwardMaxBackoff limit the wait before reconnecting.
+	portForwardMinBackoff = time.Second
+	portForwardMaxBackoff = 30 * time.Second
+)
+
+// PortForwardOptions selects the service forwarded by ForwardService.
+type PortForwardOptions struct {
+	Namespace string
+	Service   string
+	// Port is the port of the service. It can be omitted if the service has a single port.
+	Port int
+	// LocalPort is the forwarded local port. A random port is chosen if it is zero
+	// and kept when reconnecting.
+	LocalPort int
+}
+
+// PortForwardEvent reports a change of the forwarding of a service.
+type PortForwardEvent struct {
+	Pod       string
+	Port      int
+	LocalPort uint16
+	// Err is set if the connection to the pod was lost. The forwarding is reconnected
+	// to a ready pod of the service after Retry.
+	Err   error
+	Retry time.Duration
+}
+
+// ForwardService forwards a local port to a ready pod of the service until the context is
+// done. The pod is selected with the label selector of the service. If the connection to the
+// pod is lost, e.g. because the pod was restarted, another ready pod is selected and the same
+// local port is forwarded to it. Errors of the first connection are returned.
+func (k *Kubernetes) ForwardService(ctx context.Context, opts PortForwardOptions, notify func(PortForwardEvent)) error {
+	localPort := opts.LocalPort
+	backoff := portForwardMinBackoff
+	connected := false
+	for {
+		event, fw, err := k.forwardService(ctx, opts, localPort)
+		if err != nil {
+			if !connected || ctx.Err() != nil {
+				return err
+			}
+			notify(PortForwardEvent{LocalPort: uint16(localPort), Err: err, Retry: backoff})
+		} else {
+			connected = true
+			localPort = int(fw.Local)
+			backoff = portForwardMinBackoff
+			notify(event)
+			select {
+			case err = <-fw.Done():
+			case <-ctx.Done():
+				fw.Stop()
+				return nil
+			}
+			if err == nil {
+				err = errors.New("forwarding stopped")
+			}
+			event.Err, event.Retry = err, backoff
+			notify(event)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		if backoff *= 2; backoff > portForwardMaxBackoff {
+			backoff = portForwardMaxBackoff
+		}
+	}
+}
+
+// forwardService selects a pod of the service and forwards the local port to it.
+func (k *Kubernetes) forwardService(ctx context.Context, opts PortForwardOptions, localPort int) (PortForwardEvent, *client.PortForward, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	pod, port, err := k.serviceTarget(ctx, opts)
+	if err != nil {
+		return PortForwardEvent{}, nil, err
+	}
+	fw, err := k.client.ForwardPort(ctx, opts.Namespace, pod, localPort, port)
+	if err != nil {
+		return PortForwardEvent{}, nil, classifyError(err)
+	}
+	return PortForwardEvent{Pod: pod, Port: port, LocalPort: fw.Local}, fw, nil
+}
+
+// serviceTarget returns a ready pod of the service and the pod port the service port is served on.
+func (k *Kubernetes) serviceTarget(ctx context.Context, opts PortForwardOptions) (string, int, error) {
+	svc, err := k.client.GetService(ctx, opts.Namespace, opts.Service)
+	if err != nil {
+		return "", 0, classifyError(errors.Wrapf(err, "cannot get service %s", opts.Service))
+	}
+	servicePort, err := selectServicePort(svc, opts.Port)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return "", 0, errors.Errorf("service %s has no selector to find its pods", svc.Name)
+	}
+	pods, err := k.client.GetPods(ctx, opts.Namespace, &metav1.LabelSelector{MatchLabels: svc.Spec.Selector})
+	if err != nil {
+		return "", 0, classifyError(errors.Wrapf(err, "cannot list pods of service %s", svc.Name))
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || !podReady(pod) || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if port, ok := targetPort(pod, servicePort); ok {
+			return pod.Name, port, nil
+		}
+	}
+	return "", 0, errors.Errorf("service %s has no ready pod serving port %d", svc.Name, servicePort.Port)
+}
+
+// selectServicePort returns the port of the service. The port can be omitted if the service has one port only.
+func selectServicePort(svc *corev1.Service, port int) (corev1.ServicePort, error) {
+	if port == 0 && len(svc.Spec.Ports) == 1 {
+		return svc.Spec.Ports[0], nil
+	}
+	ports := make([]int32, 0, len(svc.Spec.Ports))
+	for _, p := range svc.Spec.Ports {
+		if int(p.Port) == port {
+			return p, nil
+		}
+		ports = append(ports, p.Port)
+	}
+	if port == 0 {
+		return corev1.ServicePort{}, errors.Errorf("service %s has several ports %v, select one", svc.Name, ports)
+	}
+	return corev1.ServicePort{}, errors.Errorf("service %s has no port %d, its ports are %v", svc.Name, port, ports)
+}
+
+// targetPort resolves the target port of the service port in the pod. Named ports are
+// looked up in the container ports of the pod.
+func targetPort(pod corev1.Pod, servicePort corev1.ServicePort) (int, bool) {
+	switch {
+	case servicePort.TargetPort.Type == intstr.String:
+		for _, container := range pod.Spec.Containers {
+			for _, p := range container.Ports {
+				if p.Name == servicePort.TargetPort.StrVal {
+					return int(p.ContainerPort), true
+				}
+			}
+		}
+		return 0, false
+	case servicePort.TargetPort.IntVal != 0:
+		return int(servicePort.TargetPort.IntVal), true
+	default:
+		return int(servicePort.Port), true
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestServiceTarget(t *testing.T) {
+	ctx := context.Background()
+	selector := map[string]string{"app": "pmm"}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "monitoring-service", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromString("http")},
+				{Name: "https", Port: 443, TargetPort: intstr.FromInt(8443)},
+			},
+		},
+	}
+	pod := func(name string, ready corev1.ConditionStatus) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+			}}},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k8sclient.On("GetService", ctx, "default", "monitoring-service").Return(svc, nil)
+	k8sclient.On("GetPods", ctx, "default", &metav1.LabelSelector{MatchLabels: selector}).Return(&corev1.PodList{
+		Items: []corev1.Pod{pod("pmm-0", corev1.ConditionFalse), pod("pmm-1", corev1.ConditionTrue)},
+	}, nil)
+
+	opts := PortForwardOptions{Namespace: "default", Service: "monitoring-service"}
+	_, _, err := k.serviceTarget(ctx, opts)
+	assert.ErrorContains(t, err, "several ports")
+
+	opts.Port = 80
+	name, port, err := k.serviceTarget(ctx, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "pmm-1", name)
+	assert.Equal(t, 8080, port)
+
+	opts.Port = 443
+	_, port, err = k.serviceTarget(ctx, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 8443, port)
+
+	opts.Port = 22
+	_, _, err = k.serviceTarget(ctx, opts)
+	assert.ErrorContains(t, err, "no port 22")
+}
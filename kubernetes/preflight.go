@@ -0,0 +1,189 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+)
+
+const (
+	minKubernetesVersion = "1.21.0"
+	minWorkerCPU         = "2"
+	minWorkerMemory      = "4Gi"
+)
+
+// olmPackageServerKey is the CSV installed together with OLM.
+var olmPackageServerKey = types.NamespacedName{Namespace: olmNamespace, Name: "packageserver"}
+
+// requiredPermissions lists permissions needed to provision the cluster.
+var requiredPermissions = []struct {
+	verb     string
+	group    string
+	resource string
+}{
+	{verb: "create", group: "apiextensions.k8s.io", resource: "customresourcedefinitions"},
+	{verb: "create", group: "rbac.authorization.k8s.io", resource: "clusterroles"},
+	{verb: "create", group: "rbac.authorization.k8s.io", resource: "clusterrolebindings"},
+	{verb: "create", group: "", resource: "namespaces"},
+	{verb: "create", group: "", resource: "secrets"},
+	{verb: "create", group: "apps", resource: "deployments"},
+}
+
+// PreflightCheck holds the result of a single preflight check.
+type PreflightCheck struct {
+	Name    string
+	Passed  bool
+	Message string
+	// Remediation holds a hint on fixing a failed check.
+	Remediation string
+}
+
+// RunPreflightChecks validates that the cluster is compatible with Everest.
+// An error is returned only if the checks could not be run at all.
+func (k *Kubernetes) RunPreflightChecks(ctx context.Context, namespace string) ([]PreflightCheck, error) {
+	checks := []func(context.Context, string) PreflightCheck{
+		k.checkServerVersion,
+		k.checkStorageClasses,
+		k.checkNodeResources,
+		k.checkMonitoringResources,
+		k.checkPermissions,
+		k.checkExistingInstallation,
+	}
+	results := make([]PreflightCheck, 0, len(checks))
+	for _, check := range checks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		results = append(results, check(ctx, namespace))
+	}
+	return results, nil
+}
+
+func (k *Kubernetes) checkServerVersion(ctx context.Context, _ string) PreflightCheck {
+	check := PreflightCheck{Name: "Kubernetes version"}
+	info, err := k.client.GetServerVersion(ctx)
+	if err != nil {
+		check.Message = fmt.Sprintf("cannot get server version: %s", err)
+		check.Remediation = "Make sure the kubeconfig points to a reachable cluster"
+		return check
+	}
+	v, err := utilversion.ParseGeneric(info.GitVersion)
+	if err != nil {
+		check.Message = fmt.Sprintf("cannot parse server version %q: %s", info.GitVersion, err)
+		return check
+	}
+	check.Message = info.GitVersion
+	if v.LessThan(utilversion.MustParseGeneric(minKubernetesVersion)) {
+		check.Message = fmt.Sprintf("%s is older than the minimum supported %s", info.GitVersion, minKubernetesVersion)
+		check.Remediation = fmt.Sprintf("Upgrade the cluster to Kubernetes %s or newer", minKubernetesVersion)
+		return check
+	}
+	check.Passed = true
+	return check
+}
+
+func (k *Kubernetes) checkStorageClasses(ctx context.Context, _ string) PreflightCheck {
+	check := PreflightCheck{Name: "Storage classes"}
+	storageClasses, err := k.client.GetStorageClasses(ctx)
+	if err != nil {
+		check.Message = fmt.Sprintf("cannot list storage classes: %s", err)
+		return check
+	}
+	if len(storageClasses.Items) == 0 {
+		check.Message = "no storage classes available"
+		check.Remediation = "Install a storage provisioner and create a storage class for database volumes"
+		return check
+	}
+	names := make([]string, 0, len(storageClasses.Items))
+	for _, sc := range storageClasses.Items {
+		names = append(names, sc.Name)
+	}
+	check.Passed = true
+	check.Message = strings.Join(names, ", ")
+	return check
+}
+
+func (k *Kubernetes) checkNodeResources(ctx context.Context, _ string) PreflightCheck {
+	check := PreflightCheck{Name: "Node resources"}
+	nodes, err := k.GetWorkerNodes(ctx)
+	if err != nil {
+		check.Message = err.Error()
+		return check
+	}
+	cpu := resource.NewQuantity(0, resource.DecimalSI)
+	memory := resource.NewQuantity(0, resource.BinarySI)
+	for _, node := range nodes {
+		cpu.Add(node.Status.Allocatable[corev1.ResourceCPU])
+		memory.Add(node.Status.Allocatable[corev1.ResourceMemory])
+	}
+	check.Message = fmt.Sprintf("%d worker nodes, %s CPU, %s memory allocatable", len(nodes), cpu, memory)
+	if cpu.Cmp(resource.MustParse(minWorkerCPU)) < 0 || memory.Cmp(resource.MustParse(minWorkerMemory)) < 0 {
+		check.Remediation = fmt.Sprintf("Add worker nodes to have at least %s CPU and %s memory allocatable", minWorkerCPU, minWorkerMemory)
+		return check
+	}
+	check.Passed = true
+	return check
+}
+
+func (k *Kubernetes) checkPermissions(ctx context.Context, namespace string) PreflightCheck {
+	check := PreflightCheck{Name: "RBAC permissions"}
+	var missing []string
+	for _, p := range requiredPermissions {
+		allowed, err := k.client.CanI(ctx, p.verb, p.group, p.resource, namespace)
+		if err != nil {
+			check.Message = fmt.Sprintf("cannot check permissions: %s", err)
+			return check
+		}
+		if !allowed {
+			missing = append(missing, fmt.Sprintf("%s %s", p.verb, p.resource))
+		}
+	}
+	if len(missing) != 0 {
+		check.Message = fmt.Sprintf("missing permissions: %s", strings.Join(missing, ", "))
+		check.Remediation = "Use a kubeconfig of a user bound to the cluster-admin role"
+		return check
+	}
+	check.Passed = true
+	check.Message = "current user has all required permissions"
+	return check
+}
+
+func (k *Kubernetes) checkExistingInstallation(ctx context.Context, namespace string) PreflightCheck {
+	check := PreflightCheck{Name: "Existing installation", Passed: true}
+	var found []string
+	if _, err := k.client.GetClusterServiceVersion(ctx, olmPackageServerKey); err == nil {
+		found = append(found, "OLM")
+	}
+	subs, err := k.client.ListSubscriptions(ctx, namespace)
+	if err == nil {
+		for _, sub := range subs.Items {
+			found = append(found, sub.Name)
+		}
+	}
+	if len(found) == 0 {
+		check.Message = "no existing installation found"
+		return check
+	}
+	check.Message = fmt.Sprintf("already installed: %s; existing components will be reused", strings.Join(found, ", "))
+	return check
+}
@@ -0,0 +1,159 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+func preflightNode(name, cpu, memory string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func TestRunPreflightChecks(t *testing.T) {
+	t.Parallel()
+	notFound := apierrors.NewNotFound(schema.GroupResource{Group: "operators.coreos.com", Resource: "clusterserviceversions"}, "packageserver")
+	tests := []struct {
+		name           string
+		version        string
+		storageClasses []storagev1.StorageClass
+		nodes          []corev1.Node
+		denied         string
+		olm            bool
+		subscriptions  []v1alpha1.Subscription
+		// failed is the check expected to fail, all checks pass if it is empty.
+		failed  string
+		message string
+	}{
+		{
+			name:    "compatible cluster",
+			version: "v1.27.4",
+		},
+		{
+			name:    "old server",
+			version: "v1.20.15",
+			failed:  "Kubernetes version",
+			message: "v1.20.15 is older than the minimum supported 1.21.0",
+		},
+		{
+			name:           "no storage class",
+			version:        "v1.27.4",
+			storageClasses: []storagev1.StorageClass{},
+			failed:         "Storage classes",
+			message:        "no storage classes available",
+		},
+		{
+			name:    "insufficient nodes",
+			version: "v1.27.4",
+			nodes:   []corev1.Node{preflightNode("worker", "1", "2Gi")},
+			failed:  "Node resources",
+			message: "1 worker nodes, 1 CPU, 2Gi memory allocatable",
+		},
+		{
+			name:    "denied permission",
+			version: "v1.27.4",
+			denied:  "clusterroles",
+			failed:  "RBAC permissions",
+			message: "missing permissions: create clusterroles",
+		},
+		{
+			name:          "existing installation",
+			version:       "v1.27.4",
+			olm:           true,
+			subscriptions: []v1alpha1.Subscription{{ObjectMeta: metav1.ObjectMeta{Name: "dbaas-operator"}}},
+			message:       "already installed: OLM, dbaas-operator; existing components will be reused",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			k8sclient := &client.MockKubeClientConnector{}
+			k := NewEmpty()
+			k.client = k8sclient
+
+			storageClasses := tt.storageClasses
+			if storageClasses == nil {
+				storageClasses = []storagev1.StorageClass{{ObjectMeta: metav1.ObjectMeta{Name: "standard"}}}
+			}
+			nodes := tt.nodes
+			if nodes == nil {
+				nodes = []corev1.Node{preflightNode("worker-1", "4", "8Gi"), preflightNode("worker-2", "4", "8Gi")}
+			}
+			k8sclient.On("GetServerVersion", mock.Anything).Return(&version.Info{GitVersion: tt.version}, nil)
+			k8sclient.On("GetStorageClasses", mock.Anything).Return(&storagev1.StorageClassList{Items: storageClasses}, nil)
+			k8sclient.On("GetNodes", mock.Anything).Return(&corev1.NodeList{Items: nodes}, nil)
+			k8sclient.On("CanI", mock.Anything, "create", mock.Anything, mock.Anything, "everest").Return(
+				func(_ context.Context, _, _, resource, _ string) bool { return resource != tt.denied },
+				nil,
+			)
+			if tt.olm {
+				k8sclient.On("GetClusterServiceVersion", mock.Anything, olmPackageServerKey).Return(&v1alpha1.ClusterServiceVersion{}, nil)
+			} else {
+				k8sclient.On("GetClusterServiceVersion", mock.Anything, olmPackageServerKey).Return(nil, notFound)
+			}
+			k8sclient.On("ListSubscriptions", mock.Anything, "everest").Return(&v1alpha1.SubscriptionList{Items: tt.subscriptions}, nil)
+
+			checks, err := k.RunPreflightChecks(context.Background(), "everest")
+			require.NoError(t, err)
+			require.Len(t, checks, 6)
+			for _, check := range checks {
+				if check.Name != tt.failed {
+					assert.True(t, check.Passed, "%s: %s", check.Name, check.Message)
+					if check.Name == "Existing installation" && tt.failed == "" && tt.message != "" {
+						assert.Equal(t, tt.message, check.Message)
+					}
+					continue
+				}
+				assert.False(t, check.Passed, check.Name)
+				assert.Equal(t, tt.message, check.Message)
+				assert.NotEmpty(t, check.Remediation)
+			}
+		})
+	}
+}
+
+func TestRunPreflightChecksCanceled(t *testing.T) {
+	t.Parallel()
+	k := NewEmpty()
+	k.client = &client.MockKubeClientConnector{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := k.RunPreflightChecks(ctx, "everest")
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,168 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const progressKey = "progress.json"
+
+// Provisioning steps recorded once they are completed.
+const (
+	StepOLM        = "olm"
+	StepMonitoring = "monitoring"
+)
+
+// OperatorStep returns the provisioning step of the operator installation.
+func OperatorStep(name string) string {
+	return "operator/" + name
+}
+
+// ProvisionProgress holds the provisioning steps completed so far so an
+// interrupted installation can resume from the failed step.
+type ProvisionProgress struct {
+	// Completed maps the completed steps to the time they were completed at.
+	Completed map[string]time.Time `json:"completed"`
+}
+
+// Done returns true if the step has been completed.
+func (p *ProvisionProgress) Done(step string) bool {
+	_, ok := p.Completed[step]
+	return ok
+}
+
+// Steps returns the completed steps sorted by name.
+func (p *ProvisionProgress) Steps() []string {
+	steps := make([]string, 0, len(p.Completed))
+	for step := range p.Completed {
+		steps = append(steps, step)
+	}
+	sort.Strings(steps)
+	return steps
+}
+
+// GetProvisionProgress returns the provisioning progress stored in the state config map.
+// The progress is empty if nothing has been provisioned yet.
+func (k *Kubernetes) GetProvisionProgress(ctx context.Context, namespace string) (*ProvisionProgress, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	return k.getProvisionProgress(ctx, namespace)
+}
+
+func (k *Kubernetes) getProvisionProgress(ctx context.Context, namespace string) (*ProvisionProgress, error) {
+	progress := &ProvisionProgress{Completed: make(map[string]time.Time)}
+	cm, err := k.client.GetConfigMap(ctx, namespace, StateConfigMapName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return progress, nil
+		}
+		return nil, classifyError(errors.Wrap(err, "cannot get provisioning progress"))
+	}
+	data, ok := cm.Data[progressKey]
+	if !ok {
+		return progress, nil
+	}
+	if err := json.Unmarshal([]byte(data), progress); err != nil {
+		return nil, errors.Wrap(err, "cannot decode provisioning progress")
+	}
+	if progress.Completed == nil {
+		progress.Completed = make(map[string]time.Time)
+	}
+	return progress, nil
+}
+
+// CompleteProvisionStep records the step as completed in the state config map.
+func (k *Kubernetes) CompleteProvisionStep(ctx context.Context, namespace, step string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	progress, err := k.getProvisionProgress(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	progress.Completed[step] = time.Now().UTC()
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	return k.updateStateConfigMap(ctx, namespace, progressKey, string(data))
+}
+
+// ForgetProvisionSteps removes the steps from the progress so they are repeated on the
+// next run. All steps are removed if none are given.
+func (k *Kubernetes) ForgetProvisionSteps(ctx context.Context, namespace string, steps ...string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	progress, err := k.getProvisionProgress(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		steps = progress.Steps()
+	}
+	removed := false
+	for _, step := range steps {
+		if progress.Done(step) {
+			delete(progress.Completed, step)
+			removed = true
+		}
+	}
+	if !removed {
+		return nil
+	}
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	return k.updateStateConfigMap(ctx, namespace, progressKey, string(data))
+}
+
+// updateStateConfigMap sets the key of the state config map keeping the other keys.
+// The caller must hold the write lock.
+func (k *Kubernetes) updateStateConfigMap(ctx context.Context, namespace, key, value string) error {
+	data := map[string]string{}
+	cm, err := k.client.GetConfigMap(ctx, namespace, StateConfigMapName)
+	switch {
+	case err == nil:
+		for name, v := range cm.Data {
+			data[name] = v
+		}
+	case !apierrors.IsNotFound(err):
+		return classifyError(errors.Wrap(err, "cannot get state config map"))
+	}
+	data[key] = value
+	return classifyError(k.client.ApplyObject(ctx, &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      StateConfigMapName,
+			Namespace: namespace,
+		},
+		Data: data,
+	}))
+}
@@ -0,0 +1,159 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCompleteProvisionStep(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+
+	k := NewEmpty()
+	k.client = k8sclient
+
+	k8sclient.On("GetConfigMap", ctx, "default", StateConfigMapName).Return(&corev1.ConfigMap{
+		Data: map[string]string{snapshotKey: "{}"},
+	}, nil)
+	k8sclient.On("ApplyObject", mock.Anything, mock.MatchedBy(func(cm *corev1.ConfigMap) bool {
+		// The snapshot stored in the same config map must be kept.
+		return cm.Data[snapshotKey] == "{}" && cm.Data[progressKey] != ""
+	})).Return(nil)
+
+	require.NoError(t, k.CompleteProvisionStep(ctx, "default", StepOLM))
+	k8sclient.AssertExpectations(t)
+}
+
+func TestGetProvisionProgress(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("nothing provisioned", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetConfigMap", ctx, "default", StateConfigMapName).
+			Return(nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, StateConfigMapName))
+
+		progress, err := k.GetProvisionProgress(ctx, "default")
+		require.NoError(t, err)
+		assert.Empty(t, progress.Steps())
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetConfigMap", ctx, "default", StateConfigMapName).Return(&corev1.ConfigMap{
+			Data: map[string]string{progressKey: `{"completed":{"olm":"2023-01-02T15:04:05Z","operator/dbaas-operator":"2023-01-02T15:05:05Z"}}`},
+		}, nil)
+
+		progress, err := k.GetProvisionProgress(ctx, "default")
+		require.NoError(t, err)
+		assert.True(t, progress.Done(StepOLM))
+		assert.True(t, progress.Done(OperatorStep("dbaas-operator")))
+		assert.False(t, progress.Done(StepMonitoring))
+		assert.Equal(t, []string{"olm", "operator/dbaas-operator"}, progress.Steps())
+	})
+}
+
+func TestReconcileSubscription(t *testing.T) {
+	ctx := context.Background()
+	req := InstallOperatorRequest{
+		Namespace:              "default",
+		Name:                   "dbaas-operator",
+		CatalogSource:          "percona-dbaas-catalog",
+		CatalogSourceNamespace: "olm",
+		Channel:                "stable-v0",
+	}
+	installed := func() *v1alpha1.Subscription {
+		return &v1alpha1.Subscription{
+			Spec:   &v1alpha1.SubscriptionSpec{CatalogSource: "percona-dbaas-catalog", CatalogSourceNamespace: "olm", Channel: "stable-v0"},
+			Status: v1alpha1.SubscriptionStatus{InstalledCSV: "dbaas-operator.v0.1.10"},
+		}
+	}
+
+	t.Run("installed", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetSubscription", ctx, "default", "dbaas-operator").Return(installed(), nil)
+		k8sclient.On("GetClusterServiceVersion", ctx, types.NamespacedName{Namespace: "default", Name: "dbaas-operator.v0.1.10"}).
+			Return(&v1alpha1.ClusterServiceVersion{Status: v1alpha1.ClusterServiceVersionStatus{Phase: v1alpha1.CSVPhaseSucceeded}}, nil)
+
+		done, warnings, err := k.reconcileSubscription(ctx, req)
+		require.NoError(t, err)
+		assert.True(t, done)
+		assert.Empty(t, warnings)
+		k8sclient.AssertNotCalled(t, "CreateSubscriptionForCatalog")
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k.ForceReapply()
+		k8sclient.On("GetSubscription", ctx, "default", "dbaas-operator").Return(installed(), nil)
+
+		done, _, err := k.reconcileSubscription(ctx, req)
+		require.NoError(t, err)
+		assert.False(t, done)
+	})
+
+	t.Run("channel changed", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		sub := installed()
+		sub.Spec.Channel = "fast-v0"
+		k8sclient.On("GetSubscription", ctx, "default", "dbaas-operator").Return(sub, nil)
+		k8sclient.On("UpdateSubscription", ctx, "default", mock.MatchedBy(func(s *v1alpha1.Subscription) bool {
+			return s.Spec.Channel == "stable-v0"
+		})).Return(sub, nil)
+
+		done, warnings, err := k.reconcileSubscription(ctx, req)
+		require.NoError(t, err)
+		assert.True(t, done)
+		require.Len(t, warnings, 1)
+		assert.Equal(t, WarningSubscriptionUpdated, warnings[0].Code)
+	})
+
+	t.Run("not subscribed", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetSubscription", ctx, "default", "dbaas-operator").
+			Return(nil, apierrors.NewNotFound(schema.GroupResource{Resource: "subscriptions"}, "dbaas-operator"))
+		k8sclient.On("CreateSubscriptionForCatalog", ctx, "default", "dbaas-operator", "olm", "percona-dbaas-catalog",
+			"dbaas-operator", "stable-v0", "", v1alpha1.ApprovalManual).Return(&v1alpha1.Subscription{}, nil)
+
+		done, _, err := k.reconcileSubscription(ctx, req)
+		require.NoError(t, err)
+		assert.False(t, done)
+		k8sclient.AssertExpectations(t)
+	})
+}
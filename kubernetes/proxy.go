@@ -0,0 +1,143 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// proxyTypes lists the load balancers supported by the engines.
+var proxyTypes = map[dbaasv1.EngineType][]dbaasv1.LoadBalancerType{
+	"pxc":   {"haproxy", "proxysql"},
+	"psmdb": {"mongos"},
+}
+
+// ExposeTypes are the service types the load balancer of a database cluster can be exposed with.
+var ExposeTypes = []corev1.ServiceType{corev1.ServiceTypeClusterIP, corev1.ServiceTypeLoadBalancer, corev1.ServiceTypeNodePort}
+
+// ProxyOptions configure the load balancer of a database cluster. Zero fields and a nil
+// SourceRanges are left unchanged.
+type ProxyOptions struct {
+	Type     dbaasv1.LoadBalancerType
+	Replicas int32
+	CPU      *resource.Quantity
+	Memory   *resource.Quantity
+	// ExposeType is the type of the service clients connect to the cluster through.
+	ExposeType corev1.ServiceType
+	// SourceRanges are the CIDRs of the clients allowed to connect to a LoadBalancer service.
+	// An empty slice allows all clients.
+	SourceRanges []string
+}
+
+// ConfigureProxy changes the load balancer of the database cluster with a merge patch of
+// the changed fields.
+func (k *Kubernetes) ConfigureProxy(ctx context.Context, name string, opts ProxyOptions) error {
+	cluster, err := k.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return classifyError(err)
+	}
+	configured, err := ConfiguredProxy(cluster, opts)
+	if err != nil {
+		return err
+	}
+	return k.patchDatabaseClusterChanges(ctx, cluster, configured)
+}
+
+// ConfiguredProxy returns a copy of the cluster with the load balancer of ConfigureProxy.
+func ConfiguredProxy(cluster *dbaasv1.DatabaseCluster, opts ProxyOptions) (*dbaasv1.DatabaseCluster, error) {
+	if opts.Replicas < 0 {
+		return nil, errors.Errorf("invalid number of proxies %d", opts.Replicas)
+	}
+	configured := cluster.DeepCopy()
+	lb := &configured.Spec.LoadBalancer
+	if opts.Type != "" {
+		supported := proxyTypes[cluster.Spec.Database]
+		if !hasProxyType(supported, opts.Type) {
+			return nil, errors.Errorf("%s engine does not support %s proxy, supported proxies: %s",
+				cluster.Spec.Database, opts.Type, joinProxyTypes(supported))
+		}
+		if lb.Type != opts.Type {
+			// The image of the previous proxy cannot run the new one.
+			lb.Image = ""
+		}
+		lb.Type = opts.Type
+	}
+	if opts.Replicas != 0 {
+		lb.Size = opts.Replicas
+	}
+	if opts.CPU != nil || opts.Memory != nil {
+		if lb.Resources.Requests == nil {
+			lb.Resources.Requests = corev1.ResourceList{}
+		}
+		if opts.CPU != nil {
+			lb.Resources.Requests[corev1.ResourceCPU] = *opts.CPU
+		}
+		if opts.Memory != nil {
+			lb.Resources.Requests[corev1.ResourceMemory] = *opts.Memory
+		}
+	}
+	if opts.ExposeType != "" {
+		if !hasExposeType(opts.ExposeType) {
+			return nil, errors.Errorf("unsupported expose type %s", opts.ExposeType)
+		}
+		lb.ExposeType = opts.ExposeType
+	}
+	if opts.SourceRanges != nil {
+		for _, cidr := range opts.SourceRanges {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return nil, errors.Errorf("invalid source range %q, use a CIDR, e.g. 203.0.113.0/24", cidr)
+			}
+		}
+		lb.LoadBalancerSourceRanges = opts.SourceRanges
+	}
+	if len(lb.LoadBalancerSourceRanges) != 0 && lb.ExposeType != corev1.ServiceTypeLoadBalancer {
+		return nil, errors.Errorf("source ranges restrict only clients of %s services", corev1.ServiceTypeLoadBalancer)
+	}
+	return configured, nil
+}
+
+func hasProxyType(types []dbaasv1.LoadBalancerType, t dbaasv1.LoadBalancerType) bool {
+	for _, s := range types {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
+
+func joinProxyTypes(types []dbaasv1.LoadBalancerType) string {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, string(t))
+	}
+	return strings.Join(names, ", ")
+}
+
+func hasExposeType(t corev1.ServiceType) bool {
+	for _, s := range ExposeTypes {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
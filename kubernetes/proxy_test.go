@@ -0,0 +1,89 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestConfigureProxy(t *testing.T) {
+	ctx := context.Background()
+	cluster := &dbaasv1.DatabaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: "default"},
+		Spec: dbaasv1.DatabaseSpec{
+			Database:    "pxc",
+			ClusterSize: 3,
+			LoadBalancer: dbaasv1.LoadBalancerSpec{
+				Type:       "haproxy",
+				Image:      "percona/percona-xtradb-cluster-operator:1.11.0-haproxy",
+				Size:       3,
+				ExposeType: corev1.ServiceTypeClusterIP,
+			},
+		},
+	}
+
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster.DeepCopy(), nil)
+	var patch map[string]interface{}
+	k8sclient.On("PatchDatabaseCluster", ctx, "mysql", types.MergePatchType, mock.Anything).Return(cluster, nil).Run(func(args mock.Arguments) {
+		require.NoError(t, json.Unmarshal(args.Get(3).([]byte), &patch))
+	})
+
+	cpu := resource.MustParse("500m")
+	require.NoError(t, k.ConfigureProxy(ctx, "mysql", ProxyOptions{
+		Type:         "proxysql",
+		Replicas:     2,
+		CPU:          &cpu,
+		ExposeType:   corev1.ServiceTypeLoadBalancer,
+		SourceRanges: []string{"203.0.113.0/24"},
+	}))
+	assert.Equal(t, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"loadBalancer": map[string]interface{}{
+				"type":                     "proxysql",
+				"image":                    nil,
+				"size":                     float64(2),
+				"exposeType":               "LoadBalancer",
+				"loadBalancerSourceRanges": []interface{}{"203.0.113.0/24"},
+				"resources":                map[string]interface{}{"requests": map[string]interface{}{"cpu": "500m"}},
+			},
+		},
+	}, patch)
+
+	for name, opts := range map[string]ProxyOptions{
+		"proxy of another engine": {Type: "mongos"},
+		"expose type":             {ExposeType: "ExternalName"},
+		"source range":            {ExposeType: corev1.ServiceTypeLoadBalancer, SourceRanges: []string{"203.0.113.1"}},
+		"ranges of cluster ip":    {SourceRanges: []string{"203.0.113.0/24"}},
+	} {
+		_, err := ConfiguredProxy(cluster, opts)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// PlanOperatorPrune lists the leftovers of operator upgrades: cluster service versions of the
+// operators superseded by the installed ones and failed install plans. CSVs and install plans
+// referenced by subscriptions are never listed since OLM still relies on them.
+func (k *Kubernetes) PlanOperatorPrune(ctx context.Context, namespace string, operators []string) (*DeletionPlan, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	referencedCSVs := make(map[string]struct{})
+	referencedPlans := make(map[string]struct{})
+	subscribed := make(map[string]struct{})
+	for _, name := range operators {
+		subs, err := k.client.GetSubscription(ctx, namespace, name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "cannot get subscription for %q operator", name)
+		}
+		subscribed[name] = struct{}{}
+		referencedCSVs[subs.Status.InstalledCSV] = struct{}{}
+		referencedCSVs[subs.Status.CurrentCSV] = struct{}{}
+		if subs.Status.Install != nil {
+			referencedPlans[subs.Status.Install.Name] = struct{}{}
+		}
+	}
+
+	plan := &DeletionPlan{}
+	csvs, err := k.client.ListClusterServiceVersion(ctx, namespace)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "cannot list cluster service versions")
+	}
+	if csvs != nil {
+		for i := range csvs.Items {
+			csv := &csvs.Items[i]
+			if csv.Status.Reason == v1alpha1.CSVReasonCopied {
+				continue
+			}
+			// OLM is still moving away from these, removing them would interrupt an upgrade.
+			if csv.Status.Phase == v1alpha1.CSVPhaseReplacing || csv.Status.Phase == v1alpha1.CSVPhaseDeleting {
+				continue
+			}
+			if _, ok := referencedCSVs[csv.Name]; ok {
+				continue
+			}
+			// CSVs of operators without a subscription are leftovers of removed
+			// operators rather than of upgrades; they are cleaned up by install.
+			if _, ok := subscribed[csvOperator(csv.Name)]; !ok {
+				continue
+			}
+			plan.add(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.ClusterServiceVersionKind), csv, "")
+		}
+	}
+
+	ips, err := k.client.ListInstallPlans(ctx, namespace)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "cannot list install plans")
+	}
+	if ips != nil {
+		for i := range ips.Items {
+			ip := &ips.Items[i]
+			if ip.Status.Phase != v1alpha1.InstallPlanPhaseFailed {
+				continue
+			}
+			if _, ok := referencedPlans[ip.Name]; ok {
+				continue
+			}
+			plan.add(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.InstallPlanKind), ip, "")
+		}
+	}
+	return plan, nil
+}
+
+// csvOperator returns the name of the operator from the name of its cluster service version,
+// e.g. percona-xtradb-cluster-operator from percona-xtradb-cluster-operator.v1.12.0.
+func csvOperator(csv string) string {
+	name, _, _ := strings.Cut(csv, ".v")
+	return name
+}
+
+// PruneOperators removes superseded cluster service versions and failed install plans
+// listed by PlanOperatorPrune.
+func (k *Kubernetes) PruneOperators(ctx context.Context, namespace string, operators []string) (*DeletionPlan, error) {
+	plan, err := k.PlanOperatorPrune(ctx, namespace, operators)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return plan, k.ExecuteDeletionPlan(ctx, plan)
+}
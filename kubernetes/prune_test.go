@@ -0,0 +1,93 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestPlanOperatorPrune(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+
+	k := NewEmpty()
+	k.client = k8sclient
+
+	k8sclient.On("GetSubscription", ctx, "default", "percona-xtradb-cluster-operator").Return(&v1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "percona-xtradb-cluster-operator", Namespace: "default"},
+		Status: v1alpha1.SubscriptionStatus{
+			InstalledCSV: "percona-xtradb-cluster-operator.v1.12.0",
+			CurrentCSV:   "percona-xtradb-cluster-operator.v1.12.0",
+			Install:      &v1alpha1.InstallPlanReference{Name: "install-current"},
+		},
+	}, nil)
+	k8sclient.On("GetSubscription", ctx, "default", "percona-server-mongodb-operator").
+		Return((*v1alpha1.Subscription)(nil), apierrors.NewNotFound(schema.GroupResource{Resource: "subscriptions"}, "percona-server-mongodb-operator"))
+	csv := func(name string, phase v1alpha1.ClusterServiceVersionPhase, reason v1alpha1.ConditionReason) v1alpha1.ClusterServiceVersion {
+		return v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     v1alpha1.ClusterServiceVersionStatus{Phase: phase, Reason: reason},
+		}
+	}
+	k8sclient.On("ListClusterServiceVersion", ctx, "default").Return(&v1alpha1.ClusterServiceVersionList{
+		Items: []v1alpha1.ClusterServiceVersion{
+			csv("percona-xtradb-cluster-operator.v1.12.0", v1alpha1.CSVPhaseSucceeded, ""),
+			csv("percona-xtradb-cluster-operator.v1.11.0", v1alpha1.CSVPhaseSucceeded, ""),
+			csv("percona-xtradb-cluster-operator.v1.10.0", v1alpha1.CSVPhaseReplacing, ""),
+			csv("percona-xtradb-cluster-operator.v1.9.0", v1alpha1.CSVPhaseSucceeded, v1alpha1.CSVReasonCopied),
+			csv("percona-server-mongodb-operator.v1.13.0", v1alpha1.CSVPhaseSucceeded, ""),
+		},
+	}, nil)
+	ip := func(name string, phase v1alpha1.InstallPlanPhase) v1alpha1.InstallPlan {
+		return v1alpha1.InstallPlan{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     v1alpha1.InstallPlanStatus{Phase: phase},
+		}
+	}
+	k8sclient.On("ListInstallPlans", ctx, "default").Return(&v1alpha1.InstallPlanList{
+		Items: []v1alpha1.InstallPlan{
+			ip("install-current", v1alpha1.InstallPlanPhaseFailed),
+			ip("install-failed", v1alpha1.InstallPlanPhaseFailed),
+			ip("install-complete", v1alpha1.InstallPlanPhaseComplete),
+		},
+	}, nil)
+
+	plan, err := k.PlanOperatorPrune(ctx, "default", []string{"percona-xtradb-cluster-operator", "percona-server-mongodb-operator"})
+	require.NoError(t, err)
+	assert.Empty(t, plan.Blockers)
+	names := make([]string, 0, len(plan.Items))
+	for _, item := range plan.Items {
+		names = append(names, item.Kind+"/"+item.Name)
+	}
+	assert.Equal(t, []string{
+		v1alpha1.ClusterServiceVersionKind + "/percona-xtradb-cluster-operator.v1.11.0",
+		v1alpha1.InstallPlanKind + "/install-failed",
+	}, names)
+}
+
+func TestCSVOperator(t *testing.T) {
+	assert.Equal(t, "percona-xtradb-cluster-operator", csvOperator("percona-xtradb-cluster-operator.v1.12.0"))
+	assert.Equal(t, "everest-operator", csvOperator("everest-operator"))
+}
@@ -0,0 +1,220 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// ImagePullSecret holds the credentials of a private registry.
+type ImagePullSecret struct {
+	Name     string
+	Registry string
+	Username string
+	Password string
+}
+
+// SetImagePullSecrets makes the operators, the monitoring workloads and the catalog
+// installed by the client pull their images with the named secrets.
+func (k *Kubernetes) SetImagePullSecrets(names []string) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.imagePullSecrets = names
+}
+
+// CreateImagePullSecrets creates or updates the pull secrets in the namespace.
+func (k *Kubernetes) CreateImagePullSecrets(ctx context.Context, namespace string, secrets []ImagePullSecret) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	for _, s := range secrets {
+		secret, err := imagePullSecret(namespace, s)
+		if err != nil {
+			return err
+		}
+		if err := k.client.ApplyObject(ctx, secret); err != nil {
+			return classifyError(errors.Wrapf(err, "cannot apply image pull secret %s", s.Name))
+		}
+	}
+	return nil
+}
+
+func imagePullSecret(namespace string, s ImagePullSecret) (*corev1.Secret, error) {
+	auth := base64.StdEncoding.EncodeToString([]byte(s.Username + ":" + s.Password))
+	config, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			s.Registry: map[string]string{
+				"username": s.Username,
+				"password": s.Password,
+				"auth":     auth,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.Name,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: config},
+	}, nil
+}
+
+// AddImagePullSecrets adds the pull secrets to the service account so pods running
+// as the account pull their images with them.
+func (k *Kubernetes) AddImagePullSecrets(ctx context.Context, namespace, account string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	return classifyError(k.addImagePullSecrets(ctx, namespace, account))
+}
+
+func (k *Kubernetes) addImagePullSecrets(ctx context.Context, namespace, account string) error {
+	sa, err := k.client.GetServiceAccount(ctx, namespace, account)
+	if err != nil {
+		return err
+	}
+	referenced := make(map[string]struct{}, len(sa.ImagePullSecrets))
+	for _, ref := range sa.ImagePullSecrets {
+		referenced[ref.Name] = struct{}{}
+	}
+	changed := false
+	for _, name := range k.imagePullSecrets {
+		if _, ok := referenced[name]; !ok {
+			sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	_, err = k.client.UpdateServiceAccount(ctx, sa)
+	return errors.Wrapf(err, "cannot add image pull secrets to service account %s", account)
+}
+
+func (k *Kubernetes) imagePullSecretRefs() []corev1.LocalObjectReference {
+	refs := make([]corev1.LocalObjectReference, 0, len(k.imagePullSecrets))
+	for _, name := range k.imagePullSecrets {
+		refs = append(refs, corev1.LocalObjectReference{Name: name})
+	}
+	return refs
+}
+
+// setImagePullSecrets references the pull secrets from the pods of workloads and from catalog sources.
+func setImagePullSecrets(obj *unstructured.Unstructured, names []string) error {
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "DaemonSet", "Job":
+		refs := make([]interface{}, 0, len(names))
+		for _, name := range names {
+			refs = append(refs, map[string]interface{}{"name": name})
+		}
+		return unstructured.SetNestedSlice(obj.Object, refs, "spec", "template", "spec", "imagePullSecrets")
+	case v1alpha1.CatalogSourceKind:
+		return unstructured.SetNestedStringSlice(obj.Object, names, "spec", "secrets")
+	}
+	return nil
+}
+
+// propagateOperatorPullSecrets adds the pull secrets to the service accounts of the operator.
+// OLM creates the accounts from the cluster service version, which gives no way to reference
+// pull secrets, so the pods which have failed pulling images meanwhile are recreated.
+func (k *Kubernetes) propagateOperatorPullSecrets(ctx context.Context, req InstallOperatorRequest) error {
+	var csvName string
+	err := wait.Poll(pollInterval, pollDuration, func() (bool, error) {
+		sub, err := k.client.GetSubscription(ctx, req.Namespace, req.Name)
+		if err != nil {
+			return false, err
+		}
+		csvName = sub.Status.InstalledCSV
+		return csvName != "", nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "cluster service version of %q operator was not created", req.Name)
+	}
+	csv, err := k.client.GetClusterServiceVersion(ctx, types.NamespacedName{Namespace: req.Namespace, Name: csvName})
+	if err != nil {
+		return errors.Wrapf(err, "cannot get cluster service version %s", csvName)
+	}
+	for _, deployment := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		account := deployment.Spec.Template.Spec.ServiceAccountName
+		if account == "" {
+			account = "default"
+		}
+		err := wait.Poll(pollInterval, pollDuration, func() (bool, error) {
+			err := k.addImagePullSecrets(ctx, req.Namespace, account)
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return err == nil, err
+		})
+		if err != nil {
+			return errors.Wrapf(err, "cannot add image pull secrets to service account %s", account)
+		}
+		if err := k.restartPodsFailingToPull(ctx, req.Namespace, deployment.Spec.Selector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restartPodsFailingToPull deletes the pods which cannot pull their images so they are
+// recreated with the pull secrets of their service account.
+func (k *Kubernetes) restartPodsFailingToPull(ctx context.Context, namespace string, selector *metav1.LabelSelector) error {
+	pods, err := k.client.GetPods(ctx, namespace, selector)
+	if err != nil {
+		return errors.Wrap(err, "cannot list pods")
+	}
+	for _, pod := range pods.Items {
+		if !failedToPullImage(pod) {
+			continue
+		}
+		if err := k.client.DeletePod(ctx, namespace, pod.Name); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "cannot delete pod %s", pod.Name)
+		}
+	}
+	return nil
+}
+
+func failedToPullImage(pod corev1.Pod) bool {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if status.State.Waiting == nil {
+				continue
+			}
+			switch status.State.Waiting.Reason {
+			case "ErrImagePull", "ImagePullBackOff":
+				return true
+			}
+		}
+	}
+	return false
+}
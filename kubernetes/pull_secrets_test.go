@@ -0,0 +1,95 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestImagePullSecret(t *testing.T) {
+	secret, err := imagePullSecret("default", ImagePullSecret{
+		Name:     "registry",
+		Registry: "registry.example.com",
+		Username: "user",
+		Password: "secret",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeDockerConfigJson, secret.Type)
+
+	var config struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	require.NoError(t, json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config))
+	assert.Equal(t, "dXNlcjpzZWNyZXQ=", config.Auths["registry.example.com"].Auth)
+}
+
+func TestSetImagePullSecrets(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Deployment",
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{}}},
+	}}
+	require.NoError(t, setImagePullSecrets(deployment, []string{"registry"}))
+	refs, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "imagePullSecrets")
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "registry"}}, refs)
+
+	catalog := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "CatalogSource"}}
+	require.NoError(t, setImagePullSecrets(catalog, []string{"registry"}))
+	secrets, _, _ := unstructured.NestedStringSlice(catalog.Object, "spec", "secrets")
+	assert.Equal(t, []string{"registry"}, secrets)
+}
+
+func TestAddImagePullSecrets(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+
+	k := NewEmpty()
+	k.client = k8sclient
+	k.SetImagePullSecrets([]string{"registry", "mirror"})
+
+	k8sclient.On("GetServiceAccount", ctx, "default", "default").Return(&corev1.ServiceAccount{
+		ObjectMeta:       metav1.ObjectMeta{Name: "default", Namespace: "default"},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+	}, nil)
+	k8sclient.On("UpdateServiceAccount", ctx, mock.MatchedBy(func(sa *corev1.ServiceAccount) bool {
+		return assert.ObjectsAreEqual([]corev1.LocalObjectReference{{Name: "registry"}, {Name: "mirror"}}, sa.ImagePullSecrets)
+	})).Return(&corev1.ServiceAccount{}, nil)
+
+	require.NoError(t, k.AddImagePullSecrets(ctx, "default", "default"))
+	k8sclient.AssertExpectations(t)
+}
+
+func TestFailedToPullImage(t *testing.T) {
+	waiting := func(reason string) corev1.PodStatus {
+		return corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+		}}}
+	}
+	assert.True(t, failedToPullImage(corev1.Pod{Status: waiting("ImagePullBackOff")}))
+	assert.False(t, failedToPullImage(corev1.Pod{Status: waiting("ContainerCreating")}))
+	assert.False(t, failedToPullImage(corev1.Pod{}))
+}
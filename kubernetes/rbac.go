@@ -0,0 +1,191 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// RBACRole is a persona granted access to database clusters with a cluster role.
+type RBACRole string
+
+// Supported RBAC roles.
+const (
+	// RBACRoleViewer reads database clusters, their backups and restores, pods and their logs.
+	RBACRoleViewer RBACRole = "viewer"
+	// RBACRoleOperator additionally changes database clusters, creates backups and restores
+	// and forwards ports to the pods.
+	RBACRoleOperator RBACRole = "operator"
+	// RBACRoleAdmin additionally creates and deletes database clusters and manages their
+	// credentials.
+	RBACRoleAdmin RBACRole = "admin"
+
+	rbacRolePrefix = "everest-"
+)
+
+// RBACOptions configures the cluster role and the role bindings rendered by RBACObjects.
+type RBACOptions struct {
+	Role RBACRole
+	// Subjects are bound to the cluster role in every namespace. Only the cluster role is
+	// rendered if there are none.
+	Subjects []rbacv1.Subject
+	// Namespaces the role is granted in.
+	Namespaces []string
+}
+
+// RBACRoleName returns the name of the cluster role of the role.
+func RBACRoleName(role RBACRole) string {
+	return rbacRolePrefix + string(role)
+}
+
+// RBACPolicyRules returns the rules of the role. Each role includes the rules of the previous
+// one. They cover namespaced resources only since the cluster role is bound in namespaces.
+func RBACPolicyRules(role RBACRole) ([]rbacv1.PolicyRule, error) {
+	switch role {
+	case RBACRoleViewer, RBACRoleOperator, RBACRoleAdmin:
+	default:
+		return nil, errors.Errorf("unsupported role %q, use %s, %s or %s", role, RBACRoleViewer, RBACRoleOperator, RBACRoleAdmin)
+	}
+	read := []string{"get", "list", "watch"}
+	rules := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{"dbaas.percona.com"},
+			Resources: []string{"databaseclusters", "databaseclusterbackups", "databaseclusterrestores"},
+			Verbs:     read,
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods", "pods/log", "services", "events", "persistentvolumeclaims"},
+			Verbs:     read,
+		},
+	}
+	if role == RBACRoleViewer {
+		return rules, nil
+	}
+
+	rules = append(rules,
+		rbacv1.PolicyRule{
+			APIGroups: []string{"dbaas.percona.com"},
+			Resources: []string{"databaseclusters"},
+			Verbs:     []string{"update", "patch"},
+		},
+		rbacv1.PolicyRule{
+			APIGroups: []string{"dbaas.percona.com"},
+			Resources: []string{"databaseclusterbackups", "databaseclusterrestores"},
+			Verbs:     []string{"create", "update", "patch", "delete"},
+		},
+		rbacv1.PolicyRule{
+			APIGroups: []string{""},
+			Resources: []string{"pods/portforward"},
+			Verbs:     []string{"create"},
+		},
+	)
+	if role == RBACRoleOperator {
+		return rules, nil
+	}
+
+	return append(rules,
+		rbacv1.PolicyRule{
+			APIGroups: []string{"dbaas.percona.com"},
+			Resources: []string{"databaseclusters"},
+			Verbs:     []string{"create", "delete"},
+		},
+		rbacv1.PolicyRule{
+			APIGroups: []string{""},
+			Resources: []string{"pods/exec"},
+			Verbs:     []string{"create"},
+		},
+		rbacv1.PolicyRule{
+			APIGroups: []string{""},
+			Resources: []string{"secrets"},
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+		},
+	), nil
+}
+
+// RBACObjects returns the cluster role of the role and a role binding of the subjects to it
+// in every namespace.
+func RBACObjects(opts RBACOptions) ([]runtime.Object, error) {
+	rules, err := RBACPolicyRules(opts.Role)
+	if err != nil {
+		return nil, err
+	}
+	name := RBACRoleName(opts.Role)
+	labels := map[string]string{managedByLabelKey: "everest"}
+	objs := []runtime.Object{
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Rules:      rules,
+		},
+	}
+	if len(opts.Subjects) == 0 {
+		return objs, nil
+	}
+	for _, namespace := range opts.Namespaces {
+		objs = append(objs, &rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     name,
+			},
+			Subjects: opts.Subjects,
+		})
+	}
+	return objs, nil
+}
+
+// RBACManifest renders the objects of RBACObjects as a multi-document YAML manifest which
+// can be applied with kubectl.
+func RBACManifest(opts RBACOptions) ([]byte, error) {
+	typed, err := RBACObjects(opts)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]interface{}, 0, len(typed))
+	for _, obj := range typed {
+		objs = append(objs, obj)
+	}
+	manifest, err := MarshalManifests(objs)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot render the RBAC objects")
+	}
+	return manifest, nil
+}
+
+// ApplyRBAC applies the objects of RBACObjects.
+func (k *Kubernetes) ApplyRBAC(ctx context.Context, opts RBACOptions) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	objs, err := RBACObjects(opts)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if err := k.client.ApplyObject(ctx, obj); err != nil {
+			return classifyError(errors.Wrapf(err, "cannot apply %s", obj.GetObjectKind().GroupVersionKind().Kind))
+		}
+	}
+	return nil
+}
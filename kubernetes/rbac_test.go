@@ -0,0 +1,86 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestRBACPolicyRules(t *testing.T) {
+	t.Parallel()
+	grants := func(rules []rbacv1.PolicyRule, resource, verb string) bool {
+		for _, rule := range rules {
+			for _, r := range rule.Resources {
+				for _, v := range rule.Verbs {
+					if r == resource && v == verb {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}
+
+	viewer, err := RBACPolicyRules(RBACRoleViewer)
+	require.NoError(t, err)
+	assert.True(t, grants(viewer, "databaseclusters", "list"))
+	assert.False(t, grants(viewer, "databaseclusterbackups", "create"))
+
+	operator, err := RBACPolicyRules(RBACRoleOperator)
+	require.NoError(t, err)
+	assert.True(t, grants(operator, "databaseclusterbackups", "create"))
+	assert.True(t, grants(operator, "pods/portforward", "create"))
+	assert.False(t, grants(operator, "databaseclusters", "delete"))
+	assert.False(t, grants(operator, "secrets", "get"))
+
+	admin, err := RBACPolicyRules(RBACRoleAdmin)
+	require.NoError(t, err)
+	assert.True(t, grants(admin, "databaseclusters", "delete"))
+	assert.True(t, grants(admin, "secrets", "get"))
+
+	_, err = RBACPolicyRules("owner")
+	assert.Error(t, err)
+}
+
+func TestRBACManifest(t *testing.T) {
+	t.Parallel()
+	opts := RBACOptions{
+		Role:       RBACRoleViewer,
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "developers"}},
+		Namespaces: []string{"team-a", "team-b"},
+	}
+	objs, err := RBACObjects(opts)
+	require.NoError(t, err)
+	require.Len(t, objs, 3)
+	binding, ok := objs[2].(*rbacv1.RoleBinding)
+	require.True(t, ok)
+	assert.Equal(t, "team-b", binding.Namespace)
+	assert.Equal(t, "everest-viewer", binding.RoleRef.Name)
+
+	manifest, err := RBACManifest(opts)
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), "kind: ClusterRole\n")
+	assert.Contains(t, string(manifest), "name: developers")
+
+	opts.Subjects = nil
+	objs, err = RBACObjects(opts)
+	require.NoError(t, err)
+	assert.Len(t, objs, 1)
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const vmAgentNamePrefix = "pmm-vmagent-"
+
+// ForceReapply makes the installation re-apply components which are already installed
+// instead of skipping them.
+func (k *Kubernetes) ForceReapply() {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.force = true
+}
+
+// reconcileSubscription creates the subscription of the operator or brings an existing
+// one in line with the request. It returns true if the operator is installed already
+// and there is nothing left to do.
+func (k *Kubernetes) reconcileSubscription(ctx context.Context, req InstallOperatorRequest) (bool, Warnings, error) {
+	var warnings Warnings
+	sub, err := k.client.GetSubscription(ctx, req.Namespace, req.Name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, warnings, errors.Wrap(err, "cannot get the subscription of the operator")
+		}
+		sub, err = k.client.CreateSubscriptionForCatalog(ctx, req.Namespace, req.Name, req.CatalogSourceNamespace, req.CatalogSource,
+			req.Name, req.Channel, req.StartingCSV, v1alpha1.ApprovalManual)
+		if err != nil {
+			return false, warnings, errors.Wrap(err, "cannot create a susbcription to install the operator")
+		}
+		if sub != nil && sub.Name != "" && scheduleSubscription(sub, k.scheduling) {
+			if _, err := k.client.UpdateSubscription(ctx, req.Namespace, sub); err != nil {
+				return false, warnings, errors.Wrap(err, "cannot set the scheduling of the operator")
+			}
+		}
+		return false, warnings, nil
+	}
+	if sub.Spec == nil {
+		sub.Spec = &v1alpha1.SubscriptionSpec{Package: req.Name, InstallPlanApproval: v1alpha1.ApprovalManual}
+	}
+	// The scheduling is updated together with the channel. OLM rolls out the operator
+	// deployment with the new constraints.
+	scheduled := scheduleSubscription(sub, k.scheduling)
+	if sub.Spec.Channel != req.Channel || sub.Spec.CatalogSource != req.CatalogSource ||
+		sub.Spec.CatalogSourceNamespace != req.CatalogSourceNamespace {
+		sub.Spec.Channel = req.Channel
+		sub.Spec.CatalogSource = req.CatalogSource
+		sub.Spec.CatalogSourceNamespace = req.CatalogSourceNamespace
+		if _, err := k.client.UpdateSubscription(ctx, req.Namespace, sub); err != nil {
+			return false, warnings, errors.Wrap(err, "cannot update the subscription of the operator")
+		}
+		if sub.Status.InstalledCSV != "" {
+			// The new version is installed by an install plan approved by the upgrade command.
+			warnings.Add(WarningSubscriptionUpdated, "subscription of %q operator was switched to channel %q, run the upgrade command to install the new version",
+				req.Name, req.Channel)
+			return true, warnings, nil
+		}
+		return false, warnings, nil
+	}
+	if scheduled {
+		if _, err := k.client.UpdateSubscription(ctx, req.Namespace, sub); err != nil {
+			return false, warnings, errors.Wrap(err, "cannot set the scheduling of the operator")
+		}
+	}
+	if k.force || sub.Status.InstalledCSV == "" {
+		return false, warnings, nil
+	}
+	csv, err := k.client.GetClusterServiceVersion(ctx, types.NamespacedName{Namespace: req.Namespace, Name: sub.Status.InstalledCSV})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, warnings, nil
+		}
+		return false, warnings, errors.Wrapf(err, "cannot get cluster service version %s", sub.Status.InstalledCSV)
+	}
+	return csv.Status.Phase == v1alpha1.CSVPhaseSucceeded, warnings, nil
+}
+
+// MonitoringProvisioned returns true if a VM agent created by ProvisionMonitoring exists.
+func (k *Kubernetes) MonitoringProvisioned(ctx context.Context) (bool, error) {
+	agents, err := k.pmmVMAgents(ctx)
+	if err != nil {
+		return false, err
+	}
+	return len(agents) != 0, nil
+}
+
+// pmmVMAgents returns the VM agents created by ProvisionMonitoring.
+func (k *Kubernetes) pmmVMAgents(ctx context.Context) ([]types.NamespacedName, error) {
+	list, err := k.client.ListVMAgents(ctx, useDefaultNamespace, nil)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, classifyError(errors.Wrap(err, "cannot list VM agents"))
+	}
+	var agents []types.NamespacedName
+	for _, agent := range list.Items {
+		if strings.HasPrefix(agent.Name, vmAgentNamePrefix) {
+			agents = append(agents, types.NamespacedName{Namespace: agent.Namespace, Name: agent.Name})
+		}
+	}
+	return agents, nil
+}
+
+// removeVMAgents deletes the VM agents and the secrets holding their PMM credentials and certificates.
+func (k *Kubernetes) removeVMAgents(ctx context.Context, agents []types.NamespacedName) error {
+	for _, agent := range agents {
+		if err := k.client.DeleteVMAgent(ctx, agent.Namespace, agent.Name); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "cannot delete VM agent %s", agent.Name)
+		}
+		secretName := strings.TrimPrefix(agent.Name, vmAgentNamePrefix)
+		if err := k.deleteSecret(ctx, agent.Namespace, secretName); err != nil {
+			return err
+		}
+		if err := k.deleteMonitoringTLS(ctx, agent.Namespace, secretName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
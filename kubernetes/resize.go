@@ -0,0 +1,124 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// ResizeDatabaseVolume grows the volumes of every node of the database cluster to the size.
+// It is refused if the storage class of the volumes does not allow expansion or the size
+// exceeds the maximum volume size of the cloud provider. Volumes cannot shrink.
+func (k *Kubernetes) ResizeDatabaseVolume(ctx context.Context, name string, newSize resource.Quantity) error {
+	cluster, err := k.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return classifyError(err)
+	}
+	current := cluster.Spec.DBInstance.DiskSize
+	switch newSize.Cmp(current) {
+	case 0:
+		return nil
+	case -1:
+		return errors.Errorf("disk size of database cluster %s cannot be reduced from %s to %s",
+			name, current.String(), newSize.String())
+	}
+	k.lock.RLock()
+	storageClasses, err := k.client.GetStorageClasses(ctx)
+	k.lock.RUnlock()
+	if err != nil {
+		return classifyError(errors.Wrap(err, "cannot list storage classes"))
+	}
+	if err := checkVolumeExpansion(cluster, storageClasses.Items, newSize); err != nil {
+		return err
+	}
+	patch := fmt.Sprintf(`{"spec":{"dbInstance":{"diskSize":%q}}}`, newSize.String())
+	_, err = k.PatchDatabaseClusterFields(ctx, name, []byte(patch), types.MergePatchType)
+	return err
+}
+
+// checkVolumeExpansion checks that the storage class of the cluster, the default one if
+// the cluster has none, allows expansion and that the size fits into the volume size limit.
+func checkVolumeExpansion(cluster *dbaasv1.DatabaseCluster, storageClasses []storagev1.StorageClass, newSize resource.Quantity) error {
+	var class *storagev1.StorageClass
+	for i := range storageClasses {
+		sc := &storageClasses[i]
+		if cluster.Spec.DBInstance.StorageClassName != nil {
+			if sc.Name == *cluster.Spec.DBInstance.StorageClassName {
+				class = sc
+				break
+			}
+		} else if sc.Annotations[defaultStorageClassAnnotation] == "true" {
+			class = sc
+			break
+		}
+	}
+	if class == nil {
+		return errors.Errorf("cannot find the storage class of database cluster %s", cluster.Name)
+	}
+	if class.AllowVolumeExpansion == nil || !*class.AllowVolumeExpansion {
+		return errors.Errorf("storage class %s does not allow volume expansion", class.Name)
+	}
+	clusterType := clusterTypeFromStorageClasses(storageClasses)
+	if limit := MaxVolumeSize(clusterType); limit != 0 && uint64(newSize.Value()) > limit {
+		return errors.Errorf("disk size %s exceeds the maximum volume size of %s clusters (%s)",
+			newSize.String(), clusterType, resource.NewQuantity(int64(limit), resource.BinarySI))
+	}
+	return nil
+}
+
+// WaitForVolumeResize waits until all volumes of the database cluster report a capacity
+// of at least the size or the context is done.
+func (k *Kubernetes) WaitForVolumeResize(ctx context.Context, name string, size resource.Quantity) error {
+	k.lock.RLock()
+	c := k.client
+	k.lock.RUnlock()
+
+	var pending string
+	err := wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+		cluster, err := c.GetDatabaseCluster(ctx, name)
+		if err != nil {
+			return false, errors.Wrapf(err, "cannot get database cluster %s", name)
+		}
+		pvcs, err := c.GetPersistentVolumeClaims(ctx, cluster.Namespace, &metav1.LabelSelector{
+			MatchLabels: map[string]string{instanceLabelKey: name},
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "cannot list volumes of database cluster %s", name)
+		}
+		for _, pvc := range pvcs.Items {
+			capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+			if capacity.Cmp(size) < 0 {
+				pending = pvc.Name
+				return false, nil
+			}
+		}
+		return len(pvcs.Items) != 0, nil
+	}, ctx.Done())
+	if err != nil && pending != "" {
+		err = errors.Wrapf(err, "volume %s has not been resized", pending)
+	}
+	return classifyError(err)
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestResizeDatabaseVolume(t *testing.T) {
+	ctx := context.Background()
+	cluster := &dbaasv1.DatabaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: "default"},
+		Spec: dbaasv1.DatabaseSpec{
+			DBInstance: dbaasv1.DBInstanceSpec{DiskSize: resource.MustParse("25G")},
+		},
+	}
+	expandable := true
+	storageClasses := &storagev1.StorageClassList{Items: []storagev1.StorageClass{
+		{ObjectMeta: metav1.ObjectMeta{Name: "gp2"}, Provisioner: "kubernetes.io/aws-ebs"},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "gp3",
+				Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+			},
+			Provisioner:          "ebs.csi.aws.com",
+			AllowVolumeExpansion: &expandable,
+		},
+	}}
+	newClient := func() (*Kubernetes, *client.MockKubeClientConnector) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster.DeepCopy(), nil)
+		k8sclient.On("GetStorageClasses", ctx).Return(storageClasses, nil).Maybe()
+		return k, k8sclient
+	}
+
+	t.Run("patches the disk size", func(t *testing.T) {
+		k, k8sclient := newClient()
+		patch := []byte(`{"spec":{"dbInstance":{"diskSize":"50G"}}}`)
+		k8sclient.On("PatchDatabaseCluster", ctx, "mysql", types.MergePatchType, patch).Return(cluster, nil)
+		require.NoError(t, k.ResizeDatabaseVolume(ctx, "mysql", resource.MustParse("50G")))
+		k8sclient.AssertExpectations(t)
+	})
+
+	t.Run("refuses", func(t *testing.T) {
+		for size, msg := range map[string]string{
+			"10G": "cannot be reduced",
+			"20T": "exceeds the maximum volume size",
+		} {
+			k, k8sclient := newClient()
+			assert.ErrorContains(t, k.ResizeDatabaseVolume(ctx, "mysql", resource.MustParse(size)), msg)
+			k8sclient.AssertNotCalled(t, "PatchDatabaseCluster", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("storage class without expansion", func(t *testing.T) {
+		gp2 := "gp2"
+		withClass := cluster.DeepCopy()
+		withClass.Spec.DBInstance.StorageClassName = &gp2
+		err := checkVolumeExpansion(withClass, storageClasses.Items, resource.MustParse("50G"))
+		assert.ErrorContains(t, err, "does not allow volume expansion")
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Resources holds amounts of CPU, memory and disk.
+type Resources struct {
+	CPUMillis   uint64 `json:"cpu_millis"`
+	MemoryBytes uint64 `json:"memory_bytes"`
+	DiskBytes   uint64 `json:"disk_bytes"`
+}
+
+// Fits returns true if the requested resources are not bigger than r.
+func (r Resources) Fits(requested Resources) bool {
+	return requested.CPUMillis <= r.CPUMillis &&
+		requested.MemoryBytes <= r.MemoryBytes &&
+		requested.DiskBytes <= r.DiskBytes
+}
+
+// ClusterResources holds the resources of the worker nodes of the cluster.
+type ClusterResources struct {
+	Nodes       int       `json:"nodes"`
+	Allocatable Resources `json:"allocatable"`
+	Used        Resources `json:"used"`
+}
+
+// Available returns the resources not used yet.
+func (r ClusterResources) Available() Resources {
+	return Resources{
+		CPUMillis:   subtract(r.Allocatable.CPUMillis, r.Used.CPUMillis),
+		MemoryBytes: subtract(r.Allocatable.MemoryBytes, r.Used.MemoryBytes),
+		DiskBytes:   subtract(r.Allocatable.DiskBytes, r.Used.DiskBytes),
+	}
+}
+
+func subtract(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
+
+// GetClusterResources aggregates allocatable and used resources of the worker nodes.
+// CPU and memory usage is the sum of requests of the pods running on the workers
+// since that is what the scheduler accounts for. Disk usage is the usage of the node
+// filesystems reported by the kubelets.
+func (k *Kubernetes) GetClusterResources(ctx context.Context) (*ClusterResources, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	nodes, err := k.GetWorkerNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := &ClusterResources{Nodes: len(nodes)}
+	workers := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		workers[node.Name] = struct{}{}
+		res.Allocatable.add(node.Status.Allocatable)
+		summary, err := k.getNodeSummary(ctx, node.Name)
+		if err != nil {
+			return nil, classifyError(err)
+		}
+		res.Used.DiskBytes += summary.Node.FileSystem.UsedBytes
+	}
+
+	pods, err := k.client.GetPods(ctx, "", nil)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, "cannot list pods"))
+	}
+	for _, pod := range pods.Items {
+		if _, ok := workers[pod.Spec.NodeName]; !ok {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			res.Used.CPUMillis += uint64(container.Resources.Requests.Cpu().MilliValue())
+			res.Used.MemoryBytes += uint64(container.Resources.Requests.Memory().Value())
+		}
+	}
+	return res, nil
+}
+
+func (r *Resources) add(list corev1.ResourceList) {
+	r.CPUMillis += uint64(list.Cpu().MilliValue())
+	r.MemoryBytes += uint64(list.Memory().Value())
+	r.DiskBytes += uint64(list.StorageEphemeral().Value())
+}
+
+// getNodeSummary returns the summary of the node from the stats/summary endpoint of its kubelet.
+func (k *Kubernetes) getNodeSummary(ctx context.Context, name string) (*NodeSummary, error) {
+	data, err := k.client.GetNodeStatsSummary(ctx, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get stats summary of node %s", name)
+	}
+	summary := &NodeSummary{}
+	if err := json.Unmarshal(data, summary); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode stats summary of node %s", name)
+	}
+	return summary, nil
+}
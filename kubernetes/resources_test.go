@@ -0,0 +1,76 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetClusterResources(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+
+	k := NewEmpty()
+	k.client = k8sclient
+
+	nodes := &corev1.NodeList{Items: []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("4"),
+				corev1.ResourceMemory:           resource.MustParse("8Gi"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("100Gi"),
+			}},
+		},
+	}}
+	requests := corev1.ResourceRequirements{Requests: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("500m"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	}}
+	pods := &corev1.PodList{Items: []corev1.Pod{
+		{
+			Spec:   corev1.PodSpec{NodeName: "worker", Containers: []corev1.Container{{Resources: requests}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			Spec:   corev1.PodSpec{NodeName: "worker", Containers: []corev1.Container{{Resources: requests}}},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+		{
+			Spec:   corev1.PodSpec{NodeName: "master", Containers: []corev1.Container{{Resources: requests}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	}}
+	k8sclient.On("GetNodes", ctx).Return(nodes, nil)
+	k8sclient.On("GetNodeStatsSummary", ctx, "worker").Return([]byte(`{"node":{"fs":{"usedBytes":1073741824}}}`), nil)
+	k8sclient.On("GetPods", ctx, "", (*metav1.LabelSelector)(nil)).Return(pods, nil)
+
+	res, err := k.GetClusterResources(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Nodes)
+	assert.Equal(t, Resources{CPUMillis: 4000, MemoryBytes: 8 << 30, DiskBytes: 100 << 30}, res.Allocatable)
+	assert.Equal(t, Resources{CPUMillis: 500, MemoryBytes: 1 << 30, DiskBytes: 1 << 30}, res.Used)
+	assert.True(t, res.Available().Fits(Resources{CPUMillis: 3500, MemoryBytes: 7 << 30}))
+	assert.False(t, res.Available().Fits(Resources{CPUMillis: 3600}))
+}
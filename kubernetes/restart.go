@@ -0,0 +1,207 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// RestartStrategy selects how the pods of a database cluster are restarted.
+type RestartStrategy string
+
+const (
+	// RestartStrategyFull makes the operator restart all pods of the cluster at once.
+	RestartStrategyFull RestartStrategy = "full"
+	// RestartStrategyRolling restarts the pods one at a time keeping the cluster available.
+	RestartStrategyRolling RestartStrategy = "rolling"
+
+	// databaseClusterStateError is reported by a database cluster the operator failed to reconcile.
+	databaseClusterStateError dbaasv1.AppState = "error"
+)
+
+// RestartStrategies lists the supported restart strategies.
+var RestartStrategies = []RestartStrategy{RestartStrategyRolling, RestartStrategyFull}
+
+// RestartProgress reports the pod a rolling restart is working on.
+type RestartProgress struct {
+	Pod string
+	// Restarted is the number of pods restarted so far out of Total.
+	Restarted int
+	Total     int
+}
+
+// RollingRestartDatabaseCluster deletes the pods of the database cluster one at a time. The next
+// pod is deleted only after the replacement of the previous one is ready and the cluster reports
+// the ready state again. The restart stops with ErrRestartAborted if the cluster does not recover
+// in time or the operator reports an error. Pods restarted before remain restarted.
+func (k *Kubernetes) RollingRestartDatabaseCluster(ctx context.Context, name string, progress func(RestartProgress)) error {
+	pods, err := k.databaseClusterPods(ctx, name)
+	if err != nil {
+		return classifyError(err)
+	}
+	if len(pods) == 0 {
+		return errors.Errorf("database cluster %s has no running pods", name)
+	}
+	// Restart in the reverse order of ordinals as StatefulSet rolling updates do,
+	// so the first pod, usually the primary, is restarted last.
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name > pods[j].Name })
+
+	for i, pod := range pods {
+		timeout := pollDuration
+		if i == 0 {
+			// Do not start restarting a cluster which is unhealthy already.
+			timeout = 0
+		}
+		if err := k.waitForHealthyDatabaseCluster(ctx, name, timeout); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(RestartProgress{Pod: pod.Name, Restarted: i, Total: len(pods)})
+		}
+		if err := k.client.DeletePod(ctx, pod.Namespace, pod.Name); err != nil && !apierrors.IsNotFound(err) {
+			return classifyError(errors.Wrapf(err, "cannot delete pod %s", pod.Name))
+		}
+		if err := k.waitForPodReplaced(ctx, name, pod); err != nil {
+			return err
+		}
+	}
+	if err := k.waitForHealthyDatabaseCluster(ctx, name, pollDuration); err != nil {
+		return err
+	}
+	if progress != nil {
+		progress(RestartProgress{Restarted: len(pods), Total: len(pods)})
+	}
+	return nil
+}
+
+// databaseClusterPods returns the running pods of the database and proxy StatefulSets of the cluster.
+// Pods of backup jobs are skipped.
+func (k *Kubernetes) databaseClusterPods(ctx context.Context, name string) ([]corev1.Pod, error) {
+	cluster, err := k.client.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get database cluster %s", name)
+	}
+	list, err := k.client.GetPods(ctx, cluster.Namespace, &metav1.LabelSelector{
+		MatchLabels: map[string]string{instanceLabelKey: name},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot list pods of database cluster %s", name)
+	}
+	pods := make([]corev1.Pod, 0, len(list.Items))
+	for _, pod := range list.Items {
+		if ownedBy(pod.OwnerReferences, "StatefulSet") && pod.DeletionTimestamp == nil {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// waitForHealthyDatabaseCluster waits up to the timeout for the cluster to report the ready state
+// with all of its pods ready. The cluster is checked once if the timeout is zero.
+func (k *Kubernetes) waitForHealthyDatabaseCluster(ctx context.Context, name string, timeout time.Duration) error {
+	var reason string
+	healthy := func() (bool, error) {
+		cluster, err := k.client.GetDatabaseCluster(ctx, name)
+		if err != nil {
+			return false, errors.Wrapf(err, "cannot get database cluster %s", name)
+		}
+		if cluster.Status.State != DatabaseClusterStateReady {
+			reason = "database cluster is " + string(cluster.Status.State)
+			return false, nil
+		}
+		pods, err := k.databaseClusterPods(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		for _, pod := range pods {
+			if !podReady(pod) {
+				reason = "pod " + pod.Name + " is not ready"
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	ok, err := healthy()
+	if err != nil || ok {
+		return classifyError(err)
+	}
+	if timeout > 0 {
+		err = wait.PollImmediate(pollInterval, timeout, healthy)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, wait.ErrWaitTimeout) {
+			return classifyError(err)
+		}
+	}
+	return errors.Wrap(ErrRestartAborted, reason)
+}
+
+// waitForPodReplaced waits for the pod to be recreated and become ready. It gives up if the
+// operator reports an error for the cluster meanwhile.
+func (k *Kubernetes) waitForPodReplaced(ctx context.Context, name string, old corev1.Pod) error {
+	err := wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+		cluster, err := k.client.GetDatabaseCluster(ctx, name)
+		if err != nil {
+			return false, errors.Wrapf(err, "cannot get database cluster %s", name)
+		}
+		if cluster.Status.State == databaseClusterStateError {
+			return false, errors.Wrapf(ErrRestartAborted, "database cluster reported an error while pod %s was restarting: %s",
+				old.Name, cluster.Status.Message)
+		}
+		pods, err := k.databaseClusterPods(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		for _, pod := range pods {
+			if pod.Name == old.Name && pod.UID != old.UID {
+				return podReady(pod), nil
+			}
+		}
+		return false, nil
+	}, ctx.Done())
+	if err != nil {
+		return classifyError(errors.Wrapf(err, "pod %s/%s was not replaced", old.Namespace, old.Name))
+	}
+	return nil
+}
+
+func podReady(pod corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func ownedBy(refs []metav1.OwnerReference, kind string) bool {
+	for _, ref := range refs {
+		if ref.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func databaseClusterPod(name string, uid types.UID, ownerKind string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			UID:             uid,
+			OwnerReferences: []metav1.OwnerReference{{Kind: ownerKind, Name: "mysql"}},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestRollingRestartDatabaseCluster(t *testing.T) {
+	ctx := context.Background()
+	cluster := func(state dbaasv1.AppState) *dbaasv1.DatabaseCluster {
+		return &dbaasv1.DatabaseCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: "default"},
+			Status:     dbaasv1.DatabaseClusterStatus{State: state},
+		}
+	}
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{instanceLabelKey: "mysql"}}
+
+	t.Run("restarts pods one at a time", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+
+		k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster(DatabaseClusterStateReady), nil)
+		k8sclient.On("GetPods", ctx, "default", selector).Return(&corev1.PodList{Items: []corev1.Pod{
+			databaseClusterPod("mysql-pxc-0", "a", "StatefulSet"),
+			databaseClusterPod("mysql-pxc-1", "b", "StatefulSet"),
+			databaseClusterPod("mysql-backup-xyz", "c", "Job"),
+		}}, nil).Once()
+		k8sclient.On("GetPods", ctx, "default", selector).Return(&corev1.PodList{Items: []corev1.Pod{
+			databaseClusterPod("mysql-pxc-0", "d", "StatefulSet"),
+			databaseClusterPod("mysql-pxc-1", "e", "StatefulSet"),
+		}}, nil)
+		k8sclient.On("DeletePod", ctx, "default", mock.Anything).Return(nil)
+
+		var restarted []string
+		err := k.RollingRestartDatabaseCluster(ctx, "mysql", func(p RestartProgress) {
+			if p.Pod != "" {
+				restarted = append(restarted, p.Pod)
+			}
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mysql-pxc-1", "mysql-pxc-0"}, restarted)
+		k8sclient.AssertNumberOfCalls(t, "DeletePod", 2)
+	})
+
+	t.Run("unhealthy cluster", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+
+		k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster("initializing"), nil)
+		k8sclient.On("GetPods", ctx, "default", selector).Return(&corev1.PodList{Items: []corev1.Pod{
+			databaseClusterPod("mysql-pxc-0", "a", "StatefulSet"),
+		}}, nil)
+
+		err := k.RollingRestartDatabaseCluster(ctx, "mysql", nil)
+		assert.True(t, errors.Is(err, ErrRestartAborted))
+		k8sclient.AssertNotCalled(t, "DeletePod", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
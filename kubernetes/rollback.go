@@ -0,0 +1,185 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// creationRecorder records the objects created through the client in the order
+// of their creation. Objects which existed before they were applied are not recorded.
+type creationRecorder struct {
+	client.KubeClientConnector
+
+	mu      sync.Mutex
+	created []*unstructured.Unstructured
+}
+
+func (r *creationRecorder) record(gvk schema.GroupVersionKind, namespace, name string) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created = append(r.created, obj)
+}
+
+func (r *creationRecorder) objects() []*unstructured.Unstructured {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*unstructured.Unstructured(nil), r.created...)
+}
+
+// ApplyObject applies the object and records it unless it existed already.
+func (r *creationRecorder) ApplyObject(ctx context.Context, obj runtime.Object) error {
+	return r.recordApply(ctx, obj, r.KubeClientConnector.ApplyObject)
+}
+
+// ServerSideApply applies the object and records it unless it existed already.
+func (r *creationRecorder) ServerSideApply(ctx context.Context, obj runtime.Object) error {
+	return r.recordApply(ctx, obj, r.KubeClientConnector.ServerSideApply)
+}
+
+// ThreeWayMerge applies the object and records it unless it existed already.
+func (r *creationRecorder) ThreeWayMerge(ctx context.Context, obj runtime.Object) error {
+	return r.recordApply(ctx, obj, r.KubeClientConnector.ThreeWayMerge)
+}
+
+func (r *creationRecorder) recordApply(ctx context.Context, obj runtime.Object, apply func(context.Context, runtime.Object) error) error {
+	exists, err := r.KubeClientConnector.ObjectExists(ctx, obj)
+	if err != nil {
+		return err
+	}
+	if err := apply(ctx, obj); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	r.record(obj.GetObjectKind().GroupVersionKind(), accessor.GetNamespace(), accessor.GetName())
+	return nil
+}
+
+// ApplyFile applies the objects of the manifest one by one so every created object is recorded.
+func (r *creationRecorder) ApplyFile(ctx context.Context, fileBytes []byte) error {
+	objs, err := client.DecodeObjects(fileBytes)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if err := r.ApplyObject(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateOperatorGroup creates the operator group and records it.
+func (r *creationRecorder) CreateOperatorGroup(ctx context.Context, namespace, name string) (*v1.OperatorGroup, error) {
+	og, err := r.KubeClientConnector.CreateOperatorGroup(ctx, namespace, name)
+	if err != nil {
+		return og, err
+	}
+	r.record(schema.FromAPIVersionAndKind(APIVersionCoreosV1, LeftoverKindOperatorGroup), namespace, name)
+	return og, nil
+}
+
+// CreateSubscriptionForCatalog creates the subscription and records it unless it existed already.
+func (r *creationRecorder) CreateSubscriptionForCatalog(ctx context.Context, namespace, name, catalogNamespace, catalog,
+	packageName, channel, startingCSV string, approval v1alpha1.Approval,
+) (*v1alpha1.Subscription, error) {
+	_, err := r.KubeClientConnector.GetSubscription(ctx, namespace, name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	existed := err == nil
+	sub, err := r.KubeClientConnector.CreateSubscriptionForCatalog(ctx, namespace, name, catalogNamespace, catalog,
+		packageName, channel, startingCSV, approval)
+	if err != nil {
+		return sub, err
+	}
+	if !existed {
+		r.record(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.SubscriptionKind), namespace, name)
+	}
+	return sub, nil
+}
+
+// TrackCreatedObjects records the objects created from now on so they can be
+// removed with the plan returned by PlanRollback if the installation fails.
+func (k *Kubernetes) TrackCreatedObjects() {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if k.recorder != nil {
+		return
+	}
+	k.recorder = &creationRecorder{KubeClientConnector: k.client}
+	k.client = k.recorder
+}
+
+// PlanRollback lists the objects created since TrackCreatedObjects was called in
+// the reverse order of their creation. The CSV installed for a created subscription
+// is listed right after the subscription.
+func (k *Kubernetes) PlanRollback(ctx context.Context) (*DeletionPlan, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	plan := &DeletionPlan{}
+	if k.recorder == nil {
+		return plan, nil
+	}
+	created := k.recorder.objects()
+	for i := len(created) - 1; i >= 0; i-- {
+		obj := created[i]
+		plan.add(obj.GroupVersionKind(), obj, "")
+		if obj.GetKind() != v1alpha1.SubscriptionKind {
+			continue
+		}
+		sub, err := k.client.GetSubscription(ctx, obj.GetNamespace(), obj.GetName())
+		switch {
+		case apierrors.IsNotFound(err):
+			continue
+		case err != nil:
+			return nil, errors.Wrapf(err, "cannot get subscription %q", obj.GetName())
+		case sub.Status.InstalledCSV == "":
+			continue
+		}
+		csv, err := k.client.GetClusterServiceVersion(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: sub.Status.InstalledCSV})
+		switch {
+		case err == nil:
+			plan.add(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.ClusterServiceVersionKind), csv, "")
+		case !apierrors.IsNotFound(err):
+			return nil, errors.Wrapf(err, "cannot get cluster service version %q", sub.Status.InstalledCSV)
+		}
+	}
+	return plan, nil
+}
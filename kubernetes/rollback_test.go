@@ -0,0 +1,58 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPlanRollback(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	state := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: StateConfigMapName, Namespace: "default"},
+	}
+	c, err := fake.NewKubeClient("default", state)
+	require.NoError(t, err)
+	k := NewWithClient(c)
+	k.TrackCreatedObjects()
+
+	require.NoError(t, k.CompleteProvisionStep(ctx, "default", StepOLM))
+	require.NoError(t, k.CreatePMMSecret(ctx, "dbaas-pmm", map[string][]byte{"username": []byte("admin")}))
+	_, err = k.client.CreateOperatorGroup(ctx, "default", "percona-operators-group")
+	require.NoError(t, err)
+
+	plan, err := k.PlanRollback(ctx)
+	require.NoError(t, err)
+	require.Len(t, plan.Items, 2)
+	assert.Equal(t, "OperatorGroup default/percona-operators-group", plan.Items[0].String())
+	assert.Equal(t, "Secret dbaas-pmm", plan.Items[1].String())
+
+	require.NoError(t, k.ExecuteDeletionPlan(ctx, plan))
+	_, err = c.Clientset.CoreV1().Secrets("default").Get(ctx, "dbaas-pmm", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = c.Clientset.CoreV1().ConfigMaps("default").Get(ctx, StateConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+}
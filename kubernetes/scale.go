@@ -0,0 +1,100 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DatabaseClusterResources holds the resources of every node of a database cluster.
+// Nil fields are left unchanged by ScaleDatabaseCluster.
+type DatabaseClusterResources struct {
+	CPU    *resource.Quantity
+	Memory *resource.Quantity
+	Disk   *resource.Quantity
+}
+
+// ScaleDatabaseCluster sets the number of nodes of the database cluster and the resources of
+// every node with a merge patch of the changed fields. The size is kept if replicas is zero.
+// The load balancer is scaled together with the cluster if it had the same size. Disks cannot
+// shrink since volumes cannot be reduced.
+func (k *Kubernetes) ScaleDatabaseCluster(ctx context.Context, name string, replicas int32, resources DatabaseClusterResources) error {
+	cluster, err := k.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		return classifyError(err)
+	}
+	scaled, err := ScaledDatabaseCluster(cluster, replicas, resources)
+	if err != nil {
+		return err
+	}
+	return k.patchDatabaseClusterChanges(ctx, cluster, scaled)
+}
+
+// patchDatabaseClusterChanges patches the fields of the cluster which differ in the modified
+// copy with a merge patch, so concurrent changes of other fields are kept.
+func (k *Kubernetes) patchDatabaseClusterChanges(ctx context.Context, cluster, modified *dbaasv1.DatabaseCluster) error {
+	originalJSON, err := json.Marshal(cluster)
+	if err != nil {
+		return err
+	}
+	modifiedJSON, err := json.Marshal(modified)
+	if err != nil {
+		return err
+	}
+	patch, err := jsonpatch.CreateMergePatch(originalJSON, modifiedJSON)
+	if err != nil {
+		return errors.Wrapf(err, "cannot create patch of database cluster %s", cluster.Name)
+	}
+	_, err = k.PatchDatabaseClusterFields(ctx, cluster.Name, patch, types.MergePatchType)
+	return err
+}
+
+// ScaledDatabaseCluster returns a copy of the cluster with the number of nodes and the
+// resources of ScaleDatabaseCluster, e.g. to check that it fits before scaling.
+func ScaledDatabaseCluster(cluster *dbaasv1.DatabaseCluster, replicas int32, resources DatabaseClusterResources) (*dbaasv1.DatabaseCluster, error) {
+	if replicas < 0 {
+		return nil, errors.Errorf("invalid number of nodes %d", replicas)
+	}
+	scaled := cluster.DeepCopy()
+	instance := &scaled.Spec.DBInstance
+	if resources.Disk != nil && resources.Disk.Cmp(instance.DiskSize) < 0 {
+		return nil, errors.Errorf("disk size of database cluster %s cannot be reduced from %s to %s",
+			cluster.Name, instance.DiskSize.String(), resources.Disk.String())
+	}
+	if replicas != 0 {
+		if scaled.Spec.LoadBalancer.Size == scaled.Spec.ClusterSize {
+			scaled.Spec.LoadBalancer.Size = replicas
+		}
+		scaled.Spec.ClusterSize = replicas
+	}
+	if resources.CPU != nil {
+		instance.CPU = *resources.CPU
+	}
+	if resources.Memory != nil {
+		instance.Memory = *resources.Memory
+	}
+	if resources.Disk != nil {
+		instance.DiskSize = *resources.Disk
+	}
+	return scaled, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestScaleDatabaseCluster(t *testing.T) {
+	ctx := context.Background()
+	cluster := func() *dbaasv1.DatabaseCluster {
+		return &dbaasv1.DatabaseCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: "default"},
+			Spec: dbaasv1.DatabaseSpec{
+				ClusterSize: 3,
+				DBInstance: dbaasv1.DBInstanceSpec{
+					CPU:      resource.MustParse("1"),
+					Memory:   resource.MustParse("2G"),
+					DiskSize: resource.MustParse("25G"),
+				},
+				LoadBalancer: dbaasv1.LoadBalancerSpec{Size: 3},
+			},
+		}
+	}
+
+	t.Run("scales size and resources", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster(), nil)
+		var patch map[string]interface{}
+		k8sclient.On("PatchDatabaseCluster", ctx, "mysql", types.MergePatchType, mock.Anything).Return(cluster(), nil).Run(func(args mock.Arguments) {
+			require.NoError(t, json.Unmarshal(args.Get(3).([]byte), &patch))
+		})
+
+		memory := resource.MustParse("4G")
+		require.NoError(t, k.ScaleDatabaseCluster(ctx, "mysql", 5, DatabaseClusterResources{Memory: &memory}))
+		k8sclient.AssertExpectations(t)
+		assert.Equal(t, map[string]interface{}{
+			"spec": map[string]interface{}{
+				"clusterSize":  float64(5),
+				"loadBalancer": map[string]interface{}{"size": float64(5)},
+				"dbInstance":   map[string]interface{}{"memory": "4G"},
+			},
+		}, patch, "only the changed fields are patched")
+	})
+
+	t.Run("disk cannot shrink", func(t *testing.T) {
+		k8sclient := &client.MockKubeClientConnector{}
+		k := NewEmpty()
+		k.client = k8sclient
+		k8sclient.On("GetDatabaseCluster", ctx, "mysql").Return(cluster(), nil)
+
+		disk := resource.MustParse("10G")
+		err := k.ScaleDatabaseCluster(ctx, "mysql", 0, DatabaseClusterResources{Disk: &disk})
+		assert.ErrorContains(t, err, "cannot be reduced")
+		k8sclient.AssertNotCalled(t, "PatchDatabaseCluster", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
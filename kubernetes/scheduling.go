@@ -0,0 +1,168 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Scheduling constrains the nodes the VM agents, kube-state-metrics and the operators run on,
+// e.g. to pin them to an infra node pool.
+type Scheduling struct {
+	NodeSelector map[string]string
+	Tolerations  []corev1.Toleration
+	Affinity     *corev1.Affinity
+}
+
+// IsZero returns true if the scheduling does not constrain the nodes.
+func (s Scheduling) IsZero() bool {
+	return len(s.NodeSelector) == 0 && len(s.Tolerations) == 0 && s.Affinity == nil
+}
+
+// SetScheduling applies the scheduling constraints to the installed components.
+func (k *Kubernetes) SetScheduling(s Scheduling) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.scheduling = s
+}
+
+// ScheduleOperators applies the scheduling constraints to the subscriptions of installed
+// operators. OLM rolls out the operator deployments with the new constraints. It returns
+// the names of the updated operators; operators which are not installed are skipped.
+func (k *Kubernetes) ScheduleOperators(ctx context.Context, namespace string, names []string) ([]string, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	var updated []string
+	for _, name := range names {
+		sub, err := k.client.GetSubscription(ctx, namespace, name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return updated, classifyError(errors.Wrapf(err, "cannot get the subscription of %s operator", name))
+		}
+		if !scheduleSubscription(sub, k.scheduling) {
+			continue
+		}
+		if _, err := k.client.UpdateSubscription(ctx, namespace, sub); err != nil {
+			return updated, classifyError(errors.Wrapf(err, "cannot update the subscription of %s operator", name))
+		}
+		updated = append(updated, name)
+	}
+	return updated, nil
+}
+
+// scheduleSubscription sets the scheduling constraints of the operator deployment in the
+// subscription. It returns false if the subscription has them already or there are none.
+func scheduleSubscription(sub *v1alpha1.Subscription, s Scheduling) bool {
+	if s.IsZero() {
+		return false
+	}
+	if sub.Spec == nil {
+		sub.Spec = &v1alpha1.SubscriptionSpec{}
+	}
+	if sub.Spec.Config == nil {
+		sub.Spec.Config = &v1alpha1.SubscriptionConfig{}
+	}
+	config := sub.Spec.Config
+	if equality.Semantic.DeepEqual(config.NodeSelector, s.NodeSelector) &&
+		equality.Semantic.DeepEqual(config.Tolerations, s.Tolerations) &&
+		equality.Semantic.DeepEqual(config.Affinity, s.Affinity) {
+		return false
+	}
+	config.NodeSelector = s.NodeSelector
+	config.Tolerations = s.Tolerations
+	config.Affinity = s.Affinity
+	return true
+}
+
+// scheduleVMAgent adds the scheduling constraints to the pods of the VM agent.
+func scheduleVMAgent(spec *victoriametricsv1beta1.VMAgentSpec, s Scheduling) {
+	if len(s.NodeSelector) != 0 {
+		spec.NodeSelector = mergeNodeSelector(spec.NodeSelector, s.NodeSelector)
+	}
+	spec.Tolerations = append(spec.Tolerations, s.Tolerations...)
+	if s.Affinity != nil {
+		spec.Affinity = s.Affinity
+	}
+}
+
+// scheduleWorkload adds the scheduling constraints to the pods of deployments, stateful sets
+// and jobs. Daemon sets run on every node and are left alone.
+func scheduleWorkload(obj *unstructured.Unstructured, s Scheduling) error {
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "Job":
+	default:
+		return nil
+	}
+	podSpec := []string{"spec", "template", "spec"}
+	if len(s.NodeSelector) != 0 {
+		selector, _, err := unstructured.NestedStringMap(obj.Object, append(podSpec, "nodeSelector")...)
+		if err != nil {
+			return errors.Wrapf(err, "invalid node selector of %s %s", obj.GetKind(), obj.GetName())
+		}
+		err = unstructured.SetNestedStringMap(obj.Object, mergeNodeSelector(selector, s.NodeSelector), append(podSpec, "nodeSelector")...)
+		if err != nil {
+			return err
+		}
+	}
+	if len(s.Tolerations) != 0 {
+		tolerations, _, err := unstructured.NestedSlice(obj.Object, append(podSpec, "tolerations")...)
+		if err != nil {
+			return errors.Wrapf(err, "invalid tolerations of %s %s", obj.GetKind(), obj.GetName())
+		}
+		for i := range s.Tolerations {
+			toleration, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&s.Tolerations[i])
+			if err != nil {
+				return errors.Wrap(err, "cannot convert toleration")
+			}
+			tolerations = append(tolerations, toleration)
+		}
+		if err := unstructured.SetNestedSlice(obj.Object, tolerations, append(podSpec, "tolerations")...); err != nil {
+			return err
+		}
+	}
+	if s.Affinity != nil {
+		affinity, err := runtime.DefaultUnstructuredConverter.ToUnstructured(s.Affinity)
+		if err != nil {
+			return errors.Wrap(err, "cannot convert affinity")
+		}
+		return unstructured.SetNestedMap(obj.Object, affinity, append(podSpec, "affinity")...)
+	}
+	return nil
+}
+
+// mergeNodeSelector returns the node selector of a pod with the configured labels. The
+// configured labels take precedence.
+func mergeNodeSelector(selector, labels map[string]string) map[string]string {
+	merged := make(map[string]string, len(selector)+len(labels))
+	for k, v := range selector {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var infraScheduling = Scheduling{
+	NodeSelector: map[string]string{"pool": "infra"},
+	Tolerations:  []corev1.Toleration{{Key: "infra", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+	Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{{
+			Weight:     1,
+			Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+		}},
+	}},
+}
+
+func TestScheduleWorkload(t *testing.T) {
+	t.Parallel()
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Deployment",
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"nodeSelector": map[string]interface{}{"kubernetes.io/os": "linux"},
+		}}},
+	}}
+	require.NoError(t, scheduleWorkload(deployment, infraScheduling))
+
+	selector, _, err := unstructured.NestedStringMap(deployment.Object, "spec", "template", "spec", "nodeSelector")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"kubernetes.io/os": "linux", "pool": "infra"}, selector)
+	tolerations, _, err := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "tolerations")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"key": "infra", "operator": "Exists", "effect": "NoSchedule"}}, tolerations)
+	_, found, err := unstructured.NestedMap(deployment.Object, "spec", "template", "spec", "affinity", "nodeAffinity")
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	daemonSet := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "DaemonSet"}}
+	require.NoError(t, scheduleWorkload(daemonSet, infraScheduling))
+	assert.Equal(t, map[string]interface{}{"kind": "DaemonSet"}, daemonSet.Object)
+}
+
+func TestScheduleOperators(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k.SetScheduling(infraScheduling)
+
+	scheduled := &v1alpha1.Subscription{Spec: &v1alpha1.SubscriptionSpec{Config: &v1alpha1.SubscriptionConfig{
+		NodeSelector: infraScheduling.NodeSelector,
+		Tolerations:  infraScheduling.Tolerations,
+		Affinity:     infraScheduling.Affinity,
+	}}}
+	k8sclient.On("GetSubscription", ctx, "default", "dbaas-operator").Return(&v1alpha1.Subscription{Spec: &v1alpha1.SubscriptionSpec{}}, nil)
+	k8sclient.On("GetSubscription", ctx, "default", "victoriametrics-operator").Return(scheduled, nil)
+	k8sclient.On("GetSubscription", ctx, "default", "percona-server-mongodb-operator").
+		Return(nil, apierrors.NewNotFound(schema.GroupResource{Resource: "subscriptions"}, "percona-server-mongodb-operator"))
+	k8sclient.On("UpdateSubscription", ctx, "default", mock.MatchedBy(func(s *v1alpha1.Subscription) bool {
+		return s.Spec.Config != nil && s.Spec.Config.NodeSelector["pool"] == "infra" && len(s.Spec.Config.Tolerations) == 1
+	})).Return(&v1alpha1.Subscription{}, nil).Once()
+
+	updated, err := k.ScheduleOperators(ctx, "default", []string{"dbaas-operator", "victoriametrics-operator", "percona-server-mongodb-operator"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dbaas-operator"}, updated)
+	k8sclient.AssertExpectations(t)
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	victoriametricsv1beta1 "github.com/VictoriaMetrics/operator/api/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretsBackend keeps the credentials of the secrets created by the provisioner
+// outside of the cluster. Pods consuming the secrets get the credentials from the backend.
+type SecretsBackend interface {
+	// Store stores the data of the secret and returns the secret to apply in its place.
+	Store(ctx context.Context, secret *corev1.Secret) (*corev1.Secret, error)
+	// Remove deletes the data of the secret.
+	Remove(ctx context.Context, namespace, name string) error
+	// PodAnnotations returns the annotations making the backend provide the keys of
+	// the secret to the pods annotated with them.
+	PodAnnotations(namespace, name string, keys []string) map[string]string
+}
+
+// SetSecretsBackend makes the PMM credentials and the generated credentials of database
+// clusters be stored in the backend instead of the data of Kubernetes secrets.
+func (k *Kubernetes) SetSecretsBackend(backend SecretsBackend) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.secrets = backend
+}
+
+// applySecret applies the secret or, if a secrets backend is set, stores its data
+// in the backend and applies the secret returned by the backend. Secrets without
+// a namespace are stored under the namespace of the client.
+func (k *Kubernetes) applySecret(ctx context.Context, secret *corev1.Secret) error {
+	if k.secrets == nil {
+		return errors.Wrapf(k.client.ApplyObject(ctx, secret), "cannot apply secret %s", secret.Name)
+	}
+	if secret.Namespace == "" {
+		secret = secret.DeepCopy()
+		secret.Namespace = k.client.Namespace()
+	}
+	stored, err := k.secrets.Store(ctx, secret)
+	if err != nil {
+		return errors.Wrapf(err, "cannot store secret %s", secret.Name)
+	}
+	return errors.Wrapf(k.client.ApplyObject(ctx, stored), "cannot apply secret %s", secret.Name)
+}
+
+// deleteSecret deletes the secret and its data kept by the secrets backend.
+func (k *Kubernetes) deleteSecret(ctx context.Context, namespace, name string) error {
+	if namespace == "" {
+		namespace = k.client.Namespace()
+	}
+	if k.secrets != nil {
+		if err := k.secrets.Remove(ctx, namespace, name); err != nil {
+			return errors.Wrapf(err, "cannot remove secret %s", name)
+		}
+	}
+	secret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+	if err := k.client.DeleteObject(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "cannot delete secret %s", name)
+	}
+	return nil
+}
+
+// injectSecret makes the remote writes of the VM agent read the password of the secret
+// from the file rendered by the secrets backend and annotates its pods accordingly.
+// It does nothing if no secrets backend is set.
+func (k *Kubernetes) injectSecret(spec *victoriametricsv1beta1.VMAgentSpec, namespace, name string) {
+	if k.secrets == nil {
+		return
+	}
+	if namespace == "" {
+		namespace = k.client.Namespace()
+	}
+	if spec.PodMetadata == nil {
+		spec.PodMetadata = &victoriametricsv1beta1.EmbeddedObjectMetadata{}
+	}
+	if spec.PodMetadata.Annotations == nil {
+		spec.PodMetadata.Annotations = make(map[string]string)
+	}
+	for key, value := range k.secrets.PodAnnotations(namespace, name, []string{corev1.BasicAuthPasswordKey}) {
+		spec.PodMetadata.Annotations[key] = value
+	}
+	for i := range spec.RemoteWrite {
+		if auth := spec.RemoteWrite[i].BasicAuth; auth != nil {
+			auth.Password = corev1.SecretKeySelector{}
+			auth.PasswordFile = VaultSecretFile(name, corev1.BasicAuthPasswordKey)
+		}
+	}
+}
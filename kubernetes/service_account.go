@@ -0,0 +1,158 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const tokenSecretSuffix = "-token"
+
+// EverestPolicyRules are the cluster-wide rules required by the Everest backend. Access to
+// secrets is granted by EverestNamespacePolicyRules in the namespace of Everest only.
+var EverestPolicyRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{"dbaas.percona.com"},
+		Resources: []string{"*"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"pods", "pods/log", "events", "nodes", "persistentvolumes"},
+		Verbs:     []string{"get", "list"},
+	},
+	{
+		APIGroups: []string{"storage.k8s.io"},
+		Resources: []string{"storageclasses"},
+		Verbs:     []string{"get", "list"},
+	},
+	{
+		APIGroups: []string{"apps"},
+		Resources: []string{"deployments"},
+		Verbs:     []string{"get", "list"},
+	},
+	{
+		APIGroups: []string{"operators.coreos.com"},
+		Resources: []string{"subscriptions", "clusterserviceversions", "installplans"},
+		Verbs:     []string{"get", "list"},
+	},
+}
+
+// EverestNamespacePolicyRules are the rules required by the Everest backend in its namespace,
+// where the credentials of the database clusters are kept.
+var EverestNamespacePolicyRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"secrets"},
+		Verbs:     []string{"get", "list", "create", "update", "delete"},
+	},
+}
+
+// ProvisionServiceAccount creates a service account bound to a cluster role with the cluster
+// rules and to a role with the namespace rules in the namespace of the client, and a token
+// secret for it. It returns a kubeconfig authenticating as the service account.
+func (k *Kubernetes) ProvisionServiceAccount(ctx context.Context, name string, clusterRules, namespaceRules []rbacv1.PolicyRule) (string, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	namespace := k.client.Namespace()
+	secretName := name + tokenSecretSuffix
+	objs := []runtime.Object{
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		},
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Rules:      clusterRules,
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     name,
+			},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace},
+			},
+		},
+		&rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Rules:      namespaceRules,
+		},
+		&rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Role",
+				Name:     name,
+			},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace},
+			},
+		},
+		&corev1.Secret{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        secretName,
+				Namespace:   namespace,
+				Annotations: map[string]string{corev1.ServiceAccountNameKey: name},
+			},
+			Type: corev1.SecretTypeServiceAccountToken,
+		},
+	}
+	for _, obj := range objs {
+		if err := k.client.ApplyObject(ctx, obj); err != nil {
+			return "", classifyError(errors.Wrapf(err, "cannot apply %s", obj.GetObjectKind().GroupVersionKind().Kind))
+		}
+	}
+
+	var secret *corev1.Secret
+	err := wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+		var err error
+		secret, err = k.client.GetSecret(ctx, secretName)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		// The token controller populates the secret asynchronously.
+		return len(secret.Data[corev1.ServiceAccountTokenKey]) != 0, nil
+	}, ctx.Done())
+	if err != nil {
+		return "", classifyError(errors.Wrapf(err, "token of service account %s was not issued", name))
+	}
+
+	kubeConfig, err := k.client.GenerateKubeConfigWithToken(name, secret)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot generate kubeconfig")
+	}
+	return string(kubeConfig), nil
+}
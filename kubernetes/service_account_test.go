@@ -0,0 +1,75 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestProvisionServiceAccount(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	applied := make(map[string]runtime.Object)
+	k8sclient.On("Namespace").Return("everest")
+	k8sclient.On("ApplyObject", ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		obj := args.Get(1).(runtime.Object)
+		applied[obj.GetObjectKind().GroupVersionKind().Kind] = obj
+	})
+	secret := &corev1.Secret{Data: map[string][]byte{corev1.ServiceAccountTokenKey: []byte("token")}}
+	k8sclient.On("GetSecret", ctx, "everest"+tokenSecretSuffix).Return(secret, nil)
+	k8sclient.On("GenerateKubeConfigWithToken", "everest", secret).Return([]byte("kubeconfig"), nil)
+
+	kubeconfig, err := k.ProvisionServiceAccount(ctx, "everest", EverestPolicyRules, EverestNamespacePolicyRules)
+	require.NoError(t, err)
+	assert.Equal(t, "kubeconfig", kubeconfig)
+
+	clusterRole := applied["ClusterRole"].(*rbacv1.ClusterRole)
+	assert.Equal(t, "everest", clusterRole.Name)
+	assert.Equal(t, EverestPolicyRules, clusterRole.Rules)
+	for _, rule := range clusterRole.Rules {
+		assert.NotContains(t, rule.Resources, "secrets", "secrets must not be accessible cluster-wide")
+	}
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "everest", Namespace: "everest"}}
+	clusterBinding := applied["ClusterRoleBinding"].(*rbacv1.ClusterRoleBinding)
+	assert.Equal(t, rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "everest"}, clusterBinding.RoleRef)
+	assert.Equal(t, subjects, clusterBinding.Subjects)
+
+	role := applied["Role"].(*rbacv1.Role)
+	assert.Equal(t, "everest", role.Namespace)
+	assert.Equal(t, EverestNamespacePolicyRules, role.Rules)
+	binding := applied["RoleBinding"].(*rbacv1.RoleBinding)
+	assert.Equal(t, "everest", binding.Namespace)
+	assert.Equal(t, rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "everest"}, binding.RoleRef)
+	assert.Equal(t, subjects, binding.Subjects)
+
+	account := applied["ServiceAccount"].(*corev1.ServiceAccount)
+	assert.Equal(t, "everest", account.Namespace)
+	token := applied["Secret"].(*corev1.Secret)
+	assert.Equal(t, corev1.SecretTypeServiceAccountToken, token.Type)
+	assert.Equal(t, "everest", token.Annotations[corev1.ServiceAccountNameKey])
+}
@@ -0,0 +1,159 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// StateConfigMapName is the name of the config map holding the state of the installation.
+	StateConfigMapName = "everest-provisioner-state"
+	snapshotKey        = "snapshot.json"
+
+	catalogSourceLabel = "olm.catalogSource"
+)
+
+// InstallSnapshot records the exact versions installed by the provisioner
+// so the installation can be reproduced on another cluster.
+type InstallSnapshot struct {
+	Catalog   CatalogSnapshot    `json:"catalog"`
+	Operators []OperatorSnapshot `json:"operators"`
+}
+
+// CatalogSnapshot holds the catalog source the operators were installed from.
+type CatalogSnapshot struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Image is the catalog image pinned by digest.
+	Image string `json:"image"`
+}
+
+// OperatorSnapshot holds the installed version of an operator.
+type OperatorSnapshot struct {
+	Name    string `json:"name"`
+	Channel string `json:"channel"`
+	CSV     string `json:"csv"`
+}
+
+// Operator returns the snapshot of the operator.
+func (s *InstallSnapshot) Operator(name string) (OperatorSnapshot, bool) {
+	for _, op := range s.Operators {
+		if op.Name == name {
+			return op, true
+		}
+	}
+	return OperatorSnapshot{}, false
+}
+
+// CreateInstallSnapshot records the digest of the catalog image and the installed CSVs of the operators.
+func (k *Kubernetes) CreateInstallSnapshot(ctx context.Context, namespace, catalogNamespace, catalog string, names []string) (*InstallSnapshot, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	image, err := k.catalogImageDigest(ctx, catalogNamespace, catalog)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &InstallSnapshot{
+		Catalog: CatalogSnapshot{Namespace: catalogNamespace, Name: catalog, Image: image},
+	}
+	for _, name := range names {
+		sub, err := k.client.GetSubscription(ctx, namespace, name)
+		if err != nil {
+			return nil, classifyError(errors.Wrapf(err, "cannot get subscription for %q operator", name))
+		}
+		op := OperatorSnapshot{Name: name, CSV: sub.Status.InstalledCSV}
+		if sub.Spec != nil {
+			op.Channel = sub.Spec.Channel
+		}
+		if op.CSV == "" {
+			return nil, errors.Errorf("operator %q has no installed cluster service version", name)
+		}
+		snapshot.Operators = append(snapshot.Operators, op)
+	}
+	return snapshot, nil
+}
+
+// catalogImageDigest returns the image of the catalog registry pod pinned by digest.
+func (k *Kubernetes) catalogImageDigest(ctx context.Context, namespace, catalog string) (string, error) {
+	pods, err := k.client.GetPods(ctx, namespace, &metav1.LabelSelector{
+		MatchLabels: map[string]string{catalogSourceLabel: catalog},
+	})
+	if err != nil {
+		return "", classifyError(errors.Wrapf(err, "cannot list pods of catalog %s", catalog))
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if strings.Contains(status.ImageID, "@sha256:") {
+				return strings.TrimPrefix(status.ImageID, "docker-pullable://"), nil
+			}
+		}
+	}
+	return "", errors.Errorf("cannot find the image digest of catalog %s", catalog)
+}
+
+// SaveInstallSnapshot stores the snapshot in the state config map.
+func (k *Kubernetes) SaveInstallSnapshot(ctx context.Context, namespace string, snapshot *InstallSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	return k.updateStateConfigMap(ctx, namespace, snapshotKey, string(data))
+}
+
+// GetInstallSnapshot returns the snapshot stored in the state config map.
+func (k *Kubernetes) GetInstallSnapshot(ctx context.Context, namespace string) (*InstallSnapshot, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	cm, err := k.client.GetConfigMap(ctx, namespace, StateConfigMapName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.Wrap(err, "no installation snapshot has been recorded")
+		}
+		return nil, classifyError(err)
+	}
+	data, ok := cm.Data[snapshotKey]
+	if !ok {
+		return nil, errors.New("no installation snapshot has been recorded")
+	}
+	return ParseInstallSnapshot([]byte(data))
+}
+
+// ParseInstallSnapshot parses and validates the JSON encoded snapshot.
+func ParseInstallSnapshot(data []byte) (*InstallSnapshot, error) {
+	snapshot := &InstallSnapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, errors.Wrap(err, "cannot decode installation snapshot")
+	}
+	if snapshot.Catalog.Image == "" {
+		return nil, errors.New("installation snapshot has no catalog image")
+	}
+	for _, op := range snapshot.Operators {
+		if op.Name == "" || op.CSV == "" {
+			return nil, errors.Errorf("installation snapshot has an incomplete operator %q", op.Name)
+		}
+	}
+	return snapshot, nil
+}
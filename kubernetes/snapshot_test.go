@@ -0,0 +1,64 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCreateInstallSnapshot(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+
+	k := NewEmpty()
+	k.client = k8sclient
+
+	pods := &corev1.PodList{Items: []corev1.Pod{{
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			ImageID: "docker-pullable://docker.io/percona/dbaas-catalog@sha256:0123",
+		}}},
+	}}}
+	k8sclient.On("GetPods", ctx, "olm", mock.Anything).Return(pods, nil)
+	k8sclient.On("GetSubscription", ctx, "default", "dbaas-operator").Return(&v1alpha1.Subscription{
+		Spec:   &v1alpha1.SubscriptionSpec{Channel: "stable-v0"},
+		Status: v1alpha1.SubscriptionStatus{InstalledCSV: "dbaas-operator.v0.1.10"},
+	}, nil)
+
+	snapshot, err := k.CreateInstallSnapshot(ctx, "default", "olm", "percona-dbaas-catalog", []string{"dbaas-operator"})
+	require.NoError(t, err)
+	assert.Equal(t, "docker.io/percona/dbaas-catalog@sha256:0123", snapshot.Catalog.Image)
+	assert.Equal(t, []OperatorSnapshot{{Name: "dbaas-operator", Channel: "stable-v0", CSV: "dbaas-operator.v0.1.10"}}, snapshot.Operators)
+}
+
+func TestParseInstallSnapshot(t *testing.T) {
+	_, err := ParseInstallSnapshot([]byte(`{"catalog":{"image":""}}`))
+	assert.Error(t, err)
+	_, err = ParseInstallSnapshot([]byte(`{"catalog":{"image":"catalog@sha256:0123"},"operators":[{"name":"dbaas-operator"}]}`))
+	assert.Error(t, err)
+	snapshot, err := ParseInstallSnapshot([]byte(`{"catalog":{"image":"catalog@sha256:0123"},"operators":[{"name":"dbaas-operator","csv":"dbaas-operator.v0.1.10"}]}`))
+	require.NoError(t, err)
+	op, ok := snapshot.Operator("dbaas-operator")
+	assert.True(t, ok)
+	assert.Equal(t, "dbaas-operator.v0.1.10", op.CSV)
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// catalogStateReady is the gRPC connection state of a healthy catalog source.
+const catalogStateReady = "READY"
+
+// InstallStatus describes the health of an Everest installation.
+type InstallStatus struct {
+	Catalog   CatalogStatus    `json:"catalog"`
+	Operators []OperatorStatus `json:"operators"`
+	// Timings hold how long the phases of the last installation took.
+	Timings *InstallTimings `json:"timings,omitempty"`
+	// ClusterDomain is the DNS domain used in the names of services.
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+}
+
+// CatalogStatus describes the health of the catalog source operators are installed from.
+type CatalogStatus struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Found     bool   `json:"found"`
+	Healthy   bool   `json:"healthy"`
+	// State is the last observed state of the connection to the catalog registry.
+	State string `json:"state,omitempty"`
+}
+
+// OperatorStatus describes the subscription of an operator and the state of its CSV.
+type OperatorStatus struct {
+	Name               string `json:"name"`
+	Namespace          string `json:"namespace"`
+	Subscribed         bool   `json:"subscribed"`
+	Package            string `json:"package,omitempty"`
+	Channel            string `json:"channel,omitempty"`
+	SubscriptionState  string `json:"subscriptionState,omitempty"`
+	InstalledCSV       string `json:"installedCSV,omitempty"`
+	CurrentCSV         string `json:"currentCSV,omitempty"`
+	CSVPhase           string `json:"csvPhase,omitempty"`
+	PendingInstallPlan string `json:"pendingInstallPlan,omitempty"`
+}
+
+// GetInstallStatus collects the state of the catalog source and subscriptions of the given operators.
+func (k *Kubernetes) GetInstallStatus(ctx context.Context, namespace, catalogNamespace, catalog string, names []string) (*InstallStatus, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	status := &InstallStatus{
+		Catalog: CatalogStatus{Namespace: catalogNamespace, Name: catalog},
+	}
+	cs, err := k.client.GetCatalogSource(ctx, catalogNamespace, catalog)
+	switch {
+	case err == nil:
+		status.Catalog.Found = true
+		if cs.Status.GRPCConnectionState != nil {
+			status.Catalog.State = cs.Status.GRPCConnectionState.LastObservedState
+			status.Catalog.Healthy = status.Catalog.State == catalogStateReady
+		}
+	case !apierrors.IsNotFound(err):
+		return nil, errors.Wrap(err, "cannot get catalog source")
+	}
+
+	for _, name := range names {
+		op, err := k.operatorStatus(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		status.Operators = append(status.Operators, op)
+	}
+	status.Timings, err = k.getInstallTimings(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (k *Kubernetes) operatorStatus(ctx context.Context, namespace, name string) (OperatorStatus, error) {
+	op := OperatorStatus{Name: name, Namespace: namespace}
+	subs, err := k.client.GetSubscription(ctx, namespace, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return op, nil
+		}
+		return op, errors.Wrapf(err, "cannot get subscription for %q operator", name)
+	}
+	op.Subscribed = true
+	if subs.Spec != nil {
+		op.Package = subs.Spec.Package
+		op.Channel = subs.Spec.Channel
+	}
+	op.SubscriptionState = string(subs.Status.State)
+	op.InstalledCSV = subs.Status.InstalledCSV
+	op.CurrentCSV = subs.Status.CurrentCSV
+
+	if op.InstalledCSV != "" {
+		csv, err := k.client.GetClusterServiceVersion(ctx, types.NamespacedName{Namespace: namespace, Name: op.InstalledCSV})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return op, errors.Wrapf(err, "cannot get cluster service version %q", op.InstalledCSV)
+		}
+		if err == nil {
+			op.CSVPhase = string(csv.Status.Phase)
+		}
+	}
+
+	if subs.Status.Install != nil && subs.Status.Install.Name != "" {
+		ip, err := k.client.GetInstallPlan(ctx, namespace, subs.Status.Install.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return op, errors.Wrapf(err, "cannot get install plan for %q operator", name)
+		}
+		if err == nil && !ip.Spec.Approved && ip.Status.Phase != v1alpha1.InstallPlanPhaseComplete {
+			op.PendingInstallPlan = ip.Name
+		}
+	}
+	return op, nil
+}
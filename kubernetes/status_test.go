@@ -0,0 +1,144 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestGetInstallStatus(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	notFound := func(resource, name string) error {
+		return apierrors.NewNotFound(schema.GroupResource{Group: v1alpha1.GroupName, Resource: resource}, name)
+	}
+
+	k8sclient.On("GetCatalogSource", ctx, "olm", "percona-dbaas-catalog").Return(&v1alpha1.CatalogSource{
+		Status: v1alpha1.CatalogSourceStatus{
+			GRPCConnectionState: &v1alpha1.GRPCConnectionState{LastObservedState: catalogStateReady},
+		},
+	}, nil)
+	// The installed operator.
+	k8sclient.On("GetSubscription", ctx, "default", "dbaas-operator").Return(&v1alpha1.Subscription{
+		Spec: &v1alpha1.SubscriptionSpec{Package: "dbaas-operator", Channel: "stable-v0"},
+		Status: v1alpha1.SubscriptionStatus{
+			State:        v1alpha1.SubscriptionStateAtLatest,
+			InstalledCSV: "dbaas-operator.v0.1.10",
+			CurrentCSV:   "dbaas-operator.v0.1.10",
+			Install:      &v1alpha1.InstallPlanReference{Name: "install-dbaas"},
+		},
+	}, nil)
+	k8sclient.On("GetClusterServiceVersion", ctx, types.NamespacedName{Namespace: "default", Name: "dbaas-operator.v0.1.10"}).
+		Return(&v1alpha1.ClusterServiceVersion{Status: v1alpha1.ClusterServiceVersionStatus{Phase: v1alpha1.CSVPhaseSucceeded}}, nil)
+	k8sclient.On("GetInstallPlan", ctx, "default", "install-dbaas").Return(&v1alpha1.InstallPlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "install-dbaas"},
+		Spec:       v1alpha1.InstallPlanSpec{Approved: true},
+		Status:     v1alpha1.InstallPlanStatus{Phase: v1alpha1.InstallPlanPhaseComplete},
+	}, nil)
+	// The operator with an upgrade waiting for the approval of its install plan.
+	k8sclient.On("GetSubscription", ctx, "default", "percona-xtradb-cluster-operator").Return(&v1alpha1.Subscription{
+		Spec: &v1alpha1.SubscriptionSpec{Package: "percona-xtradb-cluster-operator", Channel: "stable-v1"},
+		Status: v1alpha1.SubscriptionStatus{
+			State:        v1alpha1.SubscriptionStateUpgradePending,
+			InstalledCSV: "percona-xtradb-cluster-operator.v1.11.0",
+			CurrentCSV:   "percona-xtradb-cluster-operator.v1.12.0",
+			Install:      &v1alpha1.InstallPlanReference{Name: "install-pxc"},
+		},
+	}, nil)
+	k8sclient.On("GetClusterServiceVersion", ctx, types.NamespacedName{Namespace: "default", Name: "percona-xtradb-cluster-operator.v1.11.0"}).
+		Return(&v1alpha1.ClusterServiceVersion{Status: v1alpha1.ClusterServiceVersionStatus{Phase: v1alpha1.CSVPhaseSucceeded}}, nil)
+	k8sclient.On("GetInstallPlan", ctx, "default", "install-pxc").Return(&v1alpha1.InstallPlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "install-pxc"},
+		Status:     v1alpha1.InstallPlanStatus{Phase: v1alpha1.InstallPlanPhaseRequiresApproval},
+	}, nil)
+	// The operator which is not installed.
+	k8sclient.On("GetSubscription", ctx, "default", "percona-server-mongodb-operator").
+		Return(nil, notFound("subscriptions", "percona-server-mongodb-operator"))
+	k8sclient.On("GetConfigMap", ctx, "default", StateConfigMapName).Return(nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, StateConfigMapName))
+
+	status, err := k.GetInstallStatus(ctx, "default", "olm", "percona-dbaas-catalog",
+		[]string{"dbaas-operator", "percona-xtradb-cluster-operator", "percona-server-mongodb-operator"})
+	require.NoError(t, err)
+	assert.Equal(t, CatalogStatus{Namespace: "olm", Name: "percona-dbaas-catalog", Found: true, Healthy: true, State: catalogStateReady}, status.Catalog)
+	assert.Equal(t, []OperatorStatus{
+		{
+			Name:              "dbaas-operator",
+			Namespace:         "default",
+			Subscribed:        true,
+			Package:           "dbaas-operator",
+			Channel:           "stable-v0",
+			SubscriptionState: string(v1alpha1.SubscriptionStateAtLatest),
+			InstalledCSV:      "dbaas-operator.v0.1.10",
+			CurrentCSV:        "dbaas-operator.v0.1.10",
+			CSVPhase:          string(v1alpha1.CSVPhaseSucceeded),
+		},
+		{
+			Name:               "percona-xtradb-cluster-operator",
+			Namespace:          "default",
+			Subscribed:         true,
+			Package:            "percona-xtradb-cluster-operator",
+			Channel:            "stable-v1",
+			SubscriptionState:  string(v1alpha1.SubscriptionStateUpgradePending),
+			InstalledCSV:       "percona-xtradb-cluster-operator.v1.11.0",
+			CurrentCSV:         "percona-xtradb-cluster-operator.v1.12.0",
+			CSVPhase:           string(v1alpha1.CSVPhaseSucceeded),
+			PendingInstallPlan: "install-pxc",
+		},
+		{
+			Name:      "percona-server-mongodb-operator",
+			Namespace: "default",
+		},
+	}, status.Operators)
+	assert.Nil(t, status.Timings)
+	k8sclient.AssertExpectations(t)
+}
+
+func TestGetInstallStatusMissingCatalog(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	k8sclient.On("GetCatalogSource", ctx, "olm", "percona-dbaas-catalog").
+		Return(nil, apierrors.NewNotFound(schema.GroupResource{Group: v1alpha1.GroupName, Resource: "catalogsources"}, "percona-dbaas-catalog"))
+	k8sclient.On("GetConfigMap", ctx, "default", StateConfigMapName).Return(nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, StateConfigMapName))
+
+	status, err := k.GetInstallStatus(ctx, "default", "olm", "percona-dbaas-catalog", nil)
+	require.NoError(t, err)
+	assert.False(t, status.Catalog.Found)
+	assert.False(t, status.Catalog.Healthy)
+	assert.Empty(t, status.Operators)
+
+	k8sclient.On("GetCatalogSource", ctx, "olm", "broken").Return(nil, assert.AnError)
+	_, err = k.GetInstallStatus(ctx, "default", "olm", "broken", nil)
+	assert.ErrorIs(t, err, assert.AnError)
+	k8sclient.AssertNotCalled(t, "GetSubscription", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// noProvisioner is the provisioner of storage classes of statically created volumes.
+const noProvisioner = "kubernetes.io/no-provisioner"
+
+// databaseAccessModes are the access modes the volumes of database clusters are claimed with.
+var databaseAccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+
+var (
+	blockAccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadWriteOncePod}
+	fileAccessModes  = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadOnlyMany, corev1.ReadWriteMany}
+)
+
+// provisionerAccessModes lists the access modes supported by the provisioners of the cloud providers.
+var provisionerAccessModes = map[string][]corev1.PersistentVolumeAccessMode{
+	"ebs.csi.aws.com":              blockAccessModes,
+	"kubernetes.io/aws-ebs":        blockAccessModes,
+	"pd.csi.storage.gke.io":        blockAccessModes,
+	"kubernetes.io/gce-pd":         blockAccessModes,
+	"disk.csi.azure.com":           blockAccessModes,
+	"kubernetes.io/azure-disk":     blockAccessModes,
+	"efs.csi.aws.com":              fileAccessModes,
+	"filestore.csi.storage.gke.io": fileAccessModes,
+	"file.csi.azure.com":           fileAccessModes,
+	"kubernetes.io/azure-file":     fileAccessModes,
+}
+
+// StorageClass describes a storage class database volumes can be provisioned with.
+type StorageClass struct {
+	Name                 string `json:"name"`
+	Provisioner          string `json:"provisioner"`
+	Default              bool   `json:"default"`
+	AllowVolumeExpansion bool   `json:"allowVolumeExpansion"`
+	ReclaimPolicy        string `json:"reclaimPolicy,omitempty"`
+	VolumeBindingMode    string `json:"volumeBindingMode,omitempty"`
+	// AccessModes is empty if the access modes of the provisioner are unknown.
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+}
+
+// ListStorageClasses returns the storage classes of the cluster sorted by name.
+func (k *Kubernetes) ListStorageClasses(ctx context.Context) ([]StorageClass, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	storageClasses, err := k.client.GetStorageClasses(ctx)
+	if err != nil {
+		return nil, classifyError(errors.Wrap(err, "cannot list storage classes"))
+	}
+	classes := make([]StorageClass, 0, len(storageClasses.Items))
+	for _, sc := range storageClasses.Items {
+		class := StorageClass{
+			Name:                 sc.Name,
+			Provisioner:          sc.Provisioner,
+			Default:              sc.Annotations[defaultStorageClassAnnotation] == "true",
+			AllowVolumeExpansion: sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion,
+			AccessModes:          provisionerAccessModes[sc.Provisioner],
+		}
+		if sc.ReclaimPolicy != nil {
+			class.ReclaimPolicy = string(*sc.ReclaimPolicy)
+		}
+		if sc.VolumeBindingMode != nil {
+			class.VolumeBindingMode = string(*sc.VolumeBindingMode)
+		}
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Name < classes[j].Name })
+	return classes, nil
+}
+
+// ValidateStorageClass checks that the storage class exists and provisions volumes
+// with the access modes of database clusters.
+func (k *Kubernetes) ValidateStorageClass(ctx context.Context, name string) error {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	storageClasses, err := k.client.GetStorageClasses(ctx)
+	if err != nil {
+		return classifyError(errors.Wrap(err, "cannot list storage classes"))
+	}
+	names := make([]string, 0, len(storageClasses.Items))
+	for _, sc := range storageClasses.Items {
+		if sc.Name == name {
+			return checkDatabaseStorageClass(sc)
+		}
+		names = append(names, sc.Name)
+	}
+	sort.Strings(names)
+	return errors.Errorf("storage class %s does not exist, available storage classes: %s", name, strings.Join(names, ", "))
+}
+
+// checkDatabaseStorageClass reports storage classes which cannot provision volumes of database clusters.
+// Provisioners with unknown access modes are assumed to support them.
+func checkDatabaseStorageClass(sc storagev1.StorageClass) error {
+	if sc.Provisioner == noProvisioner {
+		return errors.Errorf("storage class %s does not provision volumes dynamically", sc.Name)
+	}
+	modes, ok := provisionerAccessModes[sc.Provisioner]
+	if !ok {
+		return nil
+	}
+	for _, required := range databaseAccessModes {
+		if !hasAccessMode(modes, required) {
+			return errors.Errorf("storage class %s does not support the %s access mode", sc.Name, required)
+		}
+	}
+	return nil
+}
+
+func hasAccessMode(modes []corev1.PersistentVolumeAccessMode, mode corev1.PersistentVolumeAccessMode) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
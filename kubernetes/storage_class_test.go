@@ -0,0 +1,64 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStorageClasses(t *testing.T) {
+	ctx := context.Background()
+	expandable := true
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+	k8sclient.On("GetStorageClasses", ctx).Return(&storagev1.StorageClassList{Items: []storagev1.StorageClass{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "gp3",
+				Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+			},
+			Provisioner:          "ebs.csi.aws.com",
+			AllowVolumeExpansion: &expandable,
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "efs"}, Provisioner: "efs.csi.aws.com"},
+		{ObjectMeta: metav1.ObjectMeta{Name: "local"}, Provisioner: noProvisioner},
+	}}, nil)
+
+	classes, err := k.ListStorageClasses(ctx)
+	require.NoError(t, err)
+	require.Len(t, classes, 3)
+	assert.Equal(t, "efs", classes[0].Name)
+	assert.Equal(t, StorageClass{
+		Name:                 "gp3",
+		Provisioner:          "ebs.csi.aws.com",
+		Default:              true,
+		AllowVolumeExpansion: true,
+		AccessModes:          []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadWriteOncePod},
+	}, classes[1])
+
+	assert.NoError(t, k.ValidateStorageClass(ctx, "gp3"))
+	assert.ErrorContains(t, k.ValidateStorageClass(ctx, "local"), "does not provision volumes dynamically")
+	assert.ErrorContains(t, k.ValidateStorageClass(ctx, "gp2"), "available storage classes: efs, gp3, local")
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const timingsKey = "timings.json"
+
+// StepOperators is the phase installing all operators concurrently.
+const StepOperators = "operators"
+
+// InstallTimings holds how long the phases of the last installation took.
+type InstallTimings struct {
+	Started time.Time     `json:"started"`
+	Seconds float64       `json:"seconds"`
+	Phases  []PhaseTiming `json:"phases"`
+}
+
+// PhaseTiming holds the duration of an installation phase. Phases completed by
+// a previous run are not timed.
+type PhaseTiming struct {
+	Phase   string    `json:"phase"`
+	Started time.Time `json:"started"`
+	Seconds float64   `json:"seconds"`
+	Failed  bool      `json:"failed,omitempty"`
+}
+
+// NewInstallTimings returns timings of an installation started now.
+func NewInstallTimings() *InstallTimings {
+	return &InstallTimings{Started: time.Now().UTC()}
+}
+
+// Add records the phase started at the given time and ending now.
+func (t *InstallTimings) Add(phase string, started time.Time, failed bool) {
+	t.Phases = append(t.Phases, PhaseTiming{
+		Phase:   phase,
+		Started: started.UTC(),
+		Seconds: roundSeconds(time.Since(started)),
+		Failed:  failed,
+	})
+}
+
+// Finish sets the total duration of the installation.
+func (t *InstallTimings) Finish() {
+	t.Seconds = roundSeconds(time.Since(t.Started))
+}
+
+// roundSeconds returns the duration in seconds rounded to milliseconds.
+func roundSeconds(d time.Duration) float64 {
+	return d.Round(time.Millisecond).Seconds()
+}
+
+// GetInstallTimings returns the timings of the last installation stored in the
+// state config map or nil if none are stored.
+func (k *Kubernetes) GetInstallTimings(ctx context.Context, namespace string) (*InstallTimings, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	return k.getInstallTimings(ctx, namespace)
+}
+
+func (k *Kubernetes) getInstallTimings(ctx context.Context, namespace string) (*InstallTimings, error) {
+	cm, err := k.client.GetConfigMap(ctx, namespace, StateConfigMapName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, classifyError(errors.Wrap(err, "cannot get installation timings"))
+	}
+	data, ok := cm.Data[timingsKey]
+	if !ok {
+		return nil, nil
+	}
+	timings := &InstallTimings{}
+	if err := json.Unmarshal([]byte(data), timings); err != nil {
+		return nil, errors.Wrap(err, "cannot decode installation timings")
+	}
+	return timings, nil
+}
+
+// RecordInstallTimings stores the timings in the state config map replacing the
+// timings of the previous installation.
+func (k *Kubernetes) RecordInstallTimings(ctx context.Context, namespace string, timings *InstallTimings) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	data, err := json.MarshalIndent(timings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return k.updateStateConfigMap(ctx, namespace, timingsKey, string(data))
+}
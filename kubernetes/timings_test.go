@@ -0,0 +1,68 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestInstallTimings(t *testing.T) {
+	ctx := context.Background()
+	k8sclient := &client.MockKubeClientConnector{}
+	k := NewEmpty()
+	k.client = k8sclient
+
+	timings := NewInstallTimings()
+	timings.Add(StepOLM, time.Now().Add(-2*time.Second), false)
+	timings.Add(OperatorStep("dbaas-operator"), time.Now().Add(-time.Second), true)
+	timings.Finish()
+	assert.InDelta(t, 2, timings.Phases[0].Seconds, 0.5)
+	assert.True(t, timings.Phases[1].Failed)
+
+	var stored string
+	k8sclient.On("GetConfigMap", ctx, "default", StateConfigMapName).Return(&corev1.ConfigMap{
+		Data: map[string]string{progressKey: "{}"},
+	}, nil).Once()
+	k8sclient.On("ApplyObject", mock.Anything, mock.MatchedBy(func(cm *corev1.ConfigMap) bool {
+		stored = cm.Data[timingsKey]
+		// The progress stored in the same config map must be kept.
+		return cm.Data[progressKey] == "{}"
+	})).Return(nil)
+	require.NoError(t, k.RecordInstallTimings(ctx, "default", timings))
+
+	k8sclient.On("GetConfigMap", ctx, "default", StateConfigMapName).Return(&corev1.ConfigMap{
+		Data: map[string]string{timingsKey: stored},
+	}, nil)
+	got, err := k.GetInstallTimings(ctx, "default")
+	require.NoError(t, err)
+	require.Len(t, got.Phases, 2)
+	assert.Equal(t, "operator/dbaas-operator", got.Phases[1].Phase)
+	assert.True(t, got.Phases[1].Failed)
+
+	data, err := json.Marshal(got.Phases[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "failed")
+	k8sclient.AssertExpectations(t)
+}
@@ -0,0 +1,153 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Topology keys the pods of database clusters can be spread by.
+const (
+	TopologyKeyZone     = corev1.LabelTopologyZone
+	TopologyKeyHostname = corev1.LabelHostname
+)
+
+// NodePlacement describes where a worker node runs.
+type NodePlacement struct {
+	Name         string    `json:"name"`
+	Zone         string    `json:"zone,omitempty"`
+	Region       string    `json:"region,omitempty"`
+	InstanceType string    `json:"instance_type,omitempty"`
+	Allocatable  Resources `json:"allocatable"`
+}
+
+// TopologyGroup summarizes the worker nodes sharing a zone, a region and an instance type.
+type TopologyGroup struct {
+	Zone         string    `json:"zone,omitempty"`
+	Region       string    `json:"region,omitempty"`
+	InstanceType string    `json:"instance_type,omitempty"`
+	Nodes        int       `json:"nodes"`
+	Allocatable  Resources `json:"allocatable"`
+}
+
+// NodeTopology describes the placement of the worker nodes of the cluster.
+type NodeTopology struct {
+	Nodes  []NodePlacement `json:"nodes"`
+	Groups []TopologyGroup `json:"groups"`
+}
+
+// Zones returns the distinct zones of the worker nodes.
+func (t *NodeTopology) Zones() []string {
+	seen := make(map[string]struct{})
+	var zones []string
+	for _, node := range t.Nodes {
+		if _, ok := seen[node.Zone]; ok || node.Zone == "" {
+			continue
+		}
+		seen[node.Zone] = struct{}{}
+		zones = append(zones, node.Zone)
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+// MultiAZ returns true if the worker nodes span more than one zone.
+func (t *NodeTopology) MultiAZ() bool {
+	return len(t.Zones()) > 1
+}
+
+// AntiAffinityTopologyKey returns the topology key the members of a database cluster of the
+// given size can be spread by: zones if every member can run in its own zone, nodes if every
+// member can run on its own node. It returns an empty string if the members cannot be spread.
+func (t *NodeTopology) AntiAffinityTopologyKey(size int) string {
+	switch {
+	case size <= len(t.Zones()):
+		return TopologyKeyZone
+	case size <= len(t.Nodes):
+		return TopologyKeyHostname
+	default:
+		return ""
+	}
+}
+
+// GetNodeTopology summarizes the worker nodes by zone, region and instance type.
+func (k *Kubernetes) GetNodeTopology(ctx context.Context) (*NodeTopology, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	nodes, err := k.GetWorkerNodes(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return nodeTopology(nodes), nil
+}
+
+func nodeTopology(nodes []corev1.Node) *NodeTopology {
+	topology := &NodeTopology{}
+	type groupKey struct{ region, zone, instanceType string }
+	groups := make(map[groupKey]int)
+	for _, node := range nodes {
+		placement := NodePlacement{
+			Name:         node.Name,
+			Zone:         nodeLabel(node, corev1.LabelTopologyZone, corev1.LabelFailureDomainBetaZone),
+			Region:       nodeLabel(node, corev1.LabelTopologyRegion, corev1.LabelFailureDomainBetaRegion),
+			InstanceType: nodeLabel(node, corev1.LabelInstanceTypeStable, corev1.LabelInstanceType),
+		}
+		placement.Allocatable.add(node.Status.Allocatable)
+		topology.Nodes = append(topology.Nodes, placement)
+
+		key := groupKey{placement.Region, placement.Zone, placement.InstanceType}
+		i, ok := groups[key]
+		if !ok {
+			i = len(topology.Groups)
+			groups[key] = i
+			topology.Groups = append(topology.Groups, TopologyGroup{
+				Zone:         placement.Zone,
+				Region:       placement.Region,
+				InstanceType: placement.InstanceType,
+			})
+		}
+		topology.Groups[i].Nodes++
+		topology.Groups[i].Allocatable.CPUMillis += placement.Allocatable.CPUMillis
+		topology.Groups[i].Allocatable.MemoryBytes += placement.Allocatable.MemoryBytes
+		topology.Groups[i].Allocatable.DiskBytes += placement.Allocatable.DiskBytes
+	}
+	sort.Slice(topology.Groups, func(i, j int) bool {
+		a, b := topology.Groups[i], topology.Groups[j]
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Zone != b.Zone {
+			return a.Zone < b.Zone
+		}
+		return a.InstanceType < b.InstanceType
+	})
+	return topology
+}
+
+// nodeLabel returns the value of the first label set on the node. Deprecated labels
+// are still set by older clusters.
+func nodeLabel(node corev1.Node, keys ...string) string {
+	for _, key := range keys {
+		if value := node.Labels[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
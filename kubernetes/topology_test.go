@@ -0,0 +1,68 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeTopology(t *testing.T) {
+	node := func(name string, labels map[string]string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			}},
+		}
+	}
+	topology := nodeTopology([]corev1.Node{
+		node("a1", map[string]string{corev1.LabelTopologyZone: "us-east-1a", corev1.LabelTopologyRegion: "us-east-1", corev1.LabelInstanceTypeStable: "m5.large"}),
+		node("a2", map[string]string{corev1.LabelTopologyZone: "us-east-1a", corev1.LabelTopologyRegion: "us-east-1", corev1.LabelInstanceTypeStable: "m5.large"}),
+		// Older clusters set only the deprecated labels.
+		node("b1", map[string]string{corev1.LabelFailureDomainBetaZone: "us-east-1b", corev1.LabelFailureDomainBetaRegion: "us-east-1", corev1.LabelInstanceType: "m5.large"}),
+	})
+
+	require.Len(t, topology.Nodes, 3)
+	assert.Equal(t, "us-east-1b", topology.Nodes[2].Zone)
+	assert.Equal(t, []string{"us-east-1a", "us-east-1b"}, topology.Zones())
+	assert.True(t, topology.MultiAZ())
+	require.Len(t, topology.Groups, 2)
+	assert.Equal(t, TopologyGroup{
+		Zone:         "us-east-1a",
+		Region:       "us-east-1",
+		InstanceType: "m5.large",
+		Nodes:        2,
+		Allocatable:  Resources{CPUMillis: 4000, MemoryBytes: 8 << 30},
+	}, topology.Groups[0])
+
+	assert.Equal(t, TopologyKeyZone, topology.AntiAffinityTopologyKey(2))
+	assert.Equal(t, TopologyKeyHostname, topology.AntiAffinityTopologyKey(3))
+	assert.Equal(t, "", topology.AntiAffinityTopologyKey(5))
+}
+
+func TestNodeTopologySingleNode(t *testing.T) {
+	topology := nodeTopology([]corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "minikube"}}})
+	assert.Empty(t, topology.Zones())
+	assert.False(t, topology.MultiAZ())
+	assert.Equal(t, "", topology.AntiAffinityTopologyKey(3))
+}
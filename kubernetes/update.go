@@ -0,0 +1,213 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/pkg/errors"
+)
+
+// UpdateStrategy selects how a changed spec is rolled out to the pods of a database cluster.
+type UpdateStrategy string
+
+const (
+	// UpdateStrategyRolling lets the operator replace the pods one at a time keeping the cluster available.
+	UpdateStrategyRolling UpdateStrategy = "rolling"
+	// UpdateStrategyRecreate pauses the cluster with the new spec and resumes it, so all pods
+	// are recreated at once. The cluster is unavailable meanwhile.
+	UpdateStrategyRecreate UpdateStrategy = "recreate"
+)
+
+// UpdateStrategies lists the supported update strategies.
+var UpdateStrategies = []UpdateStrategy{UpdateStrategyRolling, UpdateStrategyRecreate}
+
+// SpecChange is a field of the database cluster spec changed by an update.
+type SpecChange struct {
+	// Field is the path of the field in the spec, e.g. dbInstance.cpu.
+	Field string
+	// From and To are the current and the desired values. They are empty if the field is unset.
+	From string
+	To   string
+}
+
+func (c SpecChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Field, valueOrUnset(c.From), valueOrUnset(c.To))
+}
+
+func valueOrUnset(v string) string {
+	if v == "" {
+		return "<unset>"
+	}
+	return v
+}
+
+// DatabaseClusterUpdate is the difference between the live database cluster and the desired one.
+type DatabaseClusterUpdate struct {
+	Current *dbaasv1.DatabaseCluster
+	Desired *dbaasv1.DatabaseCluster
+	Changes []SpecChange
+	// Warnings explain changes which lose data or which the operators cannot apply.
+	Warnings []string
+}
+
+// PlanDatabaseClusterUpdate compares the spec of the desired database cluster with the live
+// cluster of the same name and reports the changed fields and the destructive changes.
+func (k *Kubernetes) PlanDatabaseClusterUpdate(ctx context.Context, desired *dbaasv1.DatabaseCluster) (*DatabaseClusterUpdate, error) {
+	current, err := k.GetDatabaseCluster(ctx, desired.Name)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	changes, err := diffDatabaseClusterSpecs(&current.Spec, &desired.Spec)
+	if err != nil {
+		return nil, err
+	}
+	return &DatabaseClusterUpdate{
+		Current:  current,
+		Desired:  desired,
+		Changes:  changes,
+		Warnings: destructiveChanges(current, desired),
+	}, nil
+}
+
+// UpdateDatabaseCluster applies the changed fields of the update with a merge patch, so
+// concurrent changes of other fields are kept. With the recreate strategy the cluster is
+// paused with the new spec and resumed once the operator stopped its pods; the wait is
+// bound by the context.
+func (k *Kubernetes) UpdateDatabaseCluster(ctx context.Context, update *DatabaseClusterUpdate, strategy UpdateStrategy) error {
+	if len(update.Changes) == 0 {
+		return nil
+	}
+	modified := update.Current.DeepCopy()
+	modified.Spec = *update.Desired.Spec.DeepCopy()
+	name := update.Current.Name
+	switch strategy {
+	case UpdateStrategyRolling:
+		return k.patchDatabaseClusterChanges(ctx, update.Current, modified)
+	case UpdateStrategyRecreate:
+	default:
+		return errors.Errorf("unsupported update strategy %q", strategy)
+	}
+	if modified.Spec.Pause {
+		// The cluster stays paused, so its pods are recreated when it is resumed.
+		return k.patchDatabaseClusterChanges(ctx, update.Current, modified)
+	}
+	modified.Spec.Pause = true
+	if err := k.patchDatabaseClusterChanges(ctx, update.Current, modified); err != nil {
+		return err
+	}
+	_, err := k.WaitForDatabaseCluster(ctx, name, func(cluster *dbaasv1.DatabaseCluster) (bool, error) {
+		return cluster.Status.State == DatabaseClusterStatePaused, nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "database cluster %s did not pause, resume it with db resume", name)
+	}
+	return k.ResumeDatabaseCluster(ctx, name)
+}
+
+// diffDatabaseClusterSpecs returns the fields of the specs which differ, sorted by their path.
+// Lists are compared as a whole.
+func diffDatabaseClusterSpecs(current, desired *dbaasv1.DatabaseSpec) ([]SpecChange, error) {
+	from, err := flattenSpec(current)
+	if err != nil {
+		return nil, err
+	}
+	to, err := flattenSpec(desired)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]struct{}, len(from)+len(to))
+	for field := range from {
+		fields[field] = struct{}{}
+	}
+	for field := range to {
+		fields[field] = struct{}{}
+	}
+	var changes []SpecChange
+	for field := range fields {
+		if reflect.DeepEqual(from[field], to[field]) {
+			continue
+		}
+		changes = append(changes, SpecChange{Field: field, From: specValue(from[field]), To: specValue(to[field])})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// flattenSpec returns the fields of the spec set in its JSON representation by their dotted path.
+func flattenSpec(spec *dbaasv1.DatabaseSpec) (map[string]interface{}, error) {
+	content, err := json.Marshal(spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot marshal database cluster spec")
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(content, &obj); err != nil {
+		return nil, errors.Wrap(err, "cannot marshal database cluster spec")
+	}
+	fields := make(map[string]interface{})
+	var flatten func(prefix string, obj map[string]interface{})
+	flatten = func(prefix string, obj map[string]interface{}) {
+		for key, value := range obj {
+			if nested, ok := value.(map[string]interface{}); ok {
+				flatten(prefix+key+".", nested)
+				continue
+			}
+			fields[prefix+key] = value
+		}
+	}
+	flatten("", obj)
+	return fields, nil
+}
+
+func specValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	}
+	content, _ := json.Marshal(v)
+	return string(content)
+}
+
+// destructiveChanges returns warnings about changes of the desired cluster which lose data
+// or which the operators cannot apply to a running cluster.
+func destructiveChanges(current, desired *dbaasv1.DatabaseCluster) []string {
+	var warnings []string
+	if current.Spec.Database != desired.Spec.Database {
+		warnings = append(warnings, fmt.Sprintf("the database engine cannot be changed from %s to %s, the data of the cluster is not converted",
+			current.Spec.Database, desired.Spec.Database))
+	}
+	currentDisk, desiredDisk := current.Spec.DBInstance.DiskSize, desired.Spec.DBInstance.DiskSize
+	if desiredDisk.Cmp(currentDisk) < 0 {
+		warnings = append(warnings, fmt.Sprintf("volumes cannot shrink from %s to %s, the operator fails to apply the spec",
+			currentDisk.String(), desiredDisk.String()))
+	}
+	_, from, currentTagged := splitImageTag(current.Spec.DatabaseImage)
+	_, to, desiredTagged := splitImageTag(desired.Spec.DatabaseImage)
+	if currentTagged && desiredTagged && versionLess(to, from) {
+		warnings = append(warnings, fmt.Sprintf("the database engine is downgraded from %s to %s, downgrades are not supported and may corrupt the data",
+			from, to))
+	}
+	return warnings
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/fake"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPlanDatabaseClusterUpdate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	current := &dbaasv1.DatabaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"}}
+	current.Spec.Database = "pxc"
+	current.Spec.DatabaseImage = "percona/percona-xtradb-cluster:8.0.27-18.1"
+	current.Spec.ClusterSize = 3
+	current.Spec.DBInstance.CPU = resource.MustParse("1")
+	current.Spec.DBInstance.DiskSize = resource.MustParse("25G")
+	current.Status.State = DatabaseClusterStateReady
+	c, err := fake.NewKubeClient("default", current)
+	require.NoError(t, err)
+	k := NewWithClient(c)
+
+	desired := current.DeepCopy()
+	desired.Status = dbaasv1.DatabaseClusterStatus{}
+	desired.Spec.ClusterSize = 5
+	update, err := k.PlanDatabaseClusterUpdate(ctx, desired)
+	require.NoError(t, err)
+	assert.Equal(t, []SpecChange{{Field: "clusterSize", From: "3", To: "5"}}, update.Changes)
+	assert.Empty(t, update.Warnings)
+
+	require.NoError(t, k.UpdateDatabaseCluster(ctx, update, UpdateStrategyRolling))
+	updated, err := k.GetDatabaseCluster(ctx, "orders")
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), updated.Spec.ClusterSize)
+	assert.Equal(t, DatabaseClusterStateReady, updated.Status.State)
+
+	unchanged, err := k.PlanDatabaseClusterUpdate(ctx, updated)
+	require.NoError(t, err)
+	assert.Empty(t, unchanged.Changes)
+
+	assert.Error(t, k.UpdateDatabaseCluster(ctx, update, "blue-green"))
+}
+
+func TestDestructiveChanges(t *testing.T) {
+	t.Parallel()
+	current := &dbaasv1.DatabaseCluster{}
+	current.Spec.Database = "pxc"
+	current.Spec.DatabaseImage = "percona/percona-xtradb-cluster:8.0.27-18.1"
+	current.Spec.DBInstance.DiskSize = resource.MustParse("25G")
+
+	desired := current.DeepCopy()
+	desired.Spec.DatabaseImage = "percona/percona-xtradb-cluster:8.0.29-21.1"
+	desired.Spec.DBInstance.DiskSize = resource.MustParse("50G")
+	assert.Empty(t, destructiveChanges(current, desired))
+
+	desired.Spec.Database = "psmdb"
+	desired.Spec.DatabaseImage = "percona/percona-xtradb-cluster:8.0.9-18.1"
+	desired.Spec.DBInstance.DiskSize = resource.MustParse("10G")
+	warnings := destructiveChanges(current, desired)
+	require.Len(t, warnings, 3)
+	assert.Contains(t, warnings[0], "cannot be changed from pxc to psmdb")
+	assert.Contains(t, warnings[1], "cannot shrink from 25G to 10G")
+	assert.Contains(t, warnings[2], "downgraded from 8.0.27-18.1 to 8.0.9-18.1")
+}
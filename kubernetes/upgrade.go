@@ -0,0 +1,78 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// OperatorUpgrade holds information about a pending upgrade of an operator.
+type OperatorUpgrade struct {
+	Namespace    string
+	Name         string
+	InstalledCSV string
+	TargetCSV    string
+	InstallPlan  string
+}
+
+// ListOperatorUpgrades returns pending upgrades for the given operators.
+// Operators without a subscription in the namespace are skipped.
+func (k *Kubernetes) ListOperatorUpgrades(ctx context.Context, namespace string, names []string) ([]OperatorUpgrade, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	var upgrades []OperatorUpgrade
+	for _, name := range names {
+		subs, err := k.client.GetSubscription(ctx, namespace, name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "cannot get subscription for %q operator", name)
+		}
+		if subs.Status.Install == nil || subs.Status.Install.Name == "" {
+			continue
+		}
+		ip, err := k.client.GetInstallPlan(ctx, namespace, subs.Status.Install.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get install plan for %q operator", name)
+		}
+		if ip.Spec.Approved {
+			continue
+		}
+		target := subs.Status.CurrentCSV
+		if len(ip.Spec.ClusterServiceVersionNames) != 0 {
+			target = ip.Spec.ClusterServiceVersionNames[0]
+		}
+		upgrades = append(upgrades, OperatorUpgrade{
+			Namespace:    namespace,
+			Name:         name,
+			InstalledCSV: subs.Status.InstalledCSV,
+			TargetCSV:    target,
+			InstallPlan:  ip.Name,
+		})
+	}
+	return upgrades, nil
+}
+
+// WaitForClusterServiceVersion waits until the CSV reaches the Succeeded phase.
+func (k *Kubernetes) WaitForClusterServiceVersion(ctx context.Context, key types.NamespacedName) error {
+	return classifyError(k.client.DoCSVWait(ctx, key))
+}
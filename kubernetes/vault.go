@@ -0,0 +1,285 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/pkg/httpclient"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// VaultAuthToken authenticates with a Vault token.
+	VaultAuthToken = "token"
+	// VaultAuthKubernetes authenticates with the token of the service account
+	// the provisioner runs with.
+	VaultAuthKubernetes = "kubernetes"
+
+	// Annotations of the Vault Agent Injector rendering secrets into the files of pods.
+	vaultInjectAnnotation         = "vault.hashicorp.com/agent-inject"
+	vaultRoleAnnotation           = "vault.hashicorp.com/role"
+	vaultInjectSecretAnnotation   = "vault.hashicorp.com/agent-inject-secret-"
+	vaultInjectTemplateAnnotation = "vault.hashicorp.com/agent-inject-template-"
+	// vaultSecretsDir is where the Vault Agent renders the injected secrets.
+	vaultSecretsDir = "/vault/secrets"
+)
+
+// vaultPlainKeys are keys of secrets which are not sensitive and are kept in the Kubernetes
+// secret because their consumers cannot read them from files, e.g. the user name of the
+// remote write of VM agents.
+var vaultPlainKeys = map[string]bool{
+	corev1.BasicAuthUsernameKey: true,
+	"url":                       true,
+}
+
+// VaultOptions configures the Vault secrets backend.
+type VaultOptions struct {
+	// Address is the URL of the Vault server.
+	Address string
+	// AuthMethod is either VaultAuthToken or VaultAuthKubernetes.
+	AuthMethod string
+	// Token is used with the token auth method.
+	Token string
+	// Role and AuthMount are used with the kubernetes auth method.
+	Role      string
+	AuthMount string
+	// Mount is the KV version 2 secrets engine the secrets are stored in.
+	Mount string
+	// Path prefixes the paths of the secrets in the secrets engine.
+	Path string
+	// InjectorRole is the role the Vault Agent injected into pods authenticates with.
+	// Role is used if it is empty.
+	InjectorRole string
+	// HTTPClient sends the requests to Vault. A client with a 30s timeout is used if it is nil.
+	HTTPClient *http.Client
+}
+
+// VaultBackend stores the data of secrets in a KV version 2 secrets engine of
+// HashiCorp Vault. The Kubernetes secrets carry the annotations of the Vault Agent
+// Injector instead of the sensitive data, and pods consuming them get the values
+// rendered into files under /vault/secrets.
+type VaultBackend struct {
+	opts       VaultOptions
+	httpClient *http.Client
+
+	lock  sync.Mutex
+	token string
+}
+
+// NewVaultBackend returns a Vault secrets backend. Empty options get defaults.
+func NewVaultBackend(opts VaultOptions) *VaultBackend {
+	if opts.AuthMethod == "" {
+		opts.AuthMethod = VaultAuthToken
+	}
+	if opts.AuthMount == "" {
+		opts.AuthMount = VaultAuthKubernetes
+	}
+	if opts.Mount == "" {
+		opts.Mount = "secret"
+	}
+	if opts.Path == "" {
+		opts.Path = "everest"
+	}
+	if opts.InjectorRole == "" {
+		opts.InjectorRole = opts.Role
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &VaultBackend{
+		opts:       opts,
+		httpClient: opts.HTTPClient,
+	}
+}
+
+// Store writes the data of the secret to Vault and returns the secret to apply in its
+// place: the sensitive data is replaced with the injector annotations naming the Vault
+// path of every key.
+func (v *VaultBackend) Store(ctx context.Context, secret *corev1.Secret) (*corev1.Secret, error) {
+	data := make(map[string]string, len(secret.Data)+len(secret.StringData))
+	for key, value := range secret.Data {
+		data[key] = string(value)
+	}
+	for key, value := range secret.StringData {
+		data[key] = value
+	}
+	body := map[string]interface{}{"data": data}
+	if err := v.do(ctx, http.MethodPost, v.secretPath("data", secret.Namespace, secret.Name), body, nil); err != nil {
+		return nil, errors.Wrapf(err, "cannot write secret %s to vault", secret.Name)
+	}
+
+	stored := secret.DeepCopy()
+	stored.Data = make(map[string][]byte)
+	stored.StringData = nil
+	if stored.Annotations == nil {
+		stored.Annotations = make(map[string]string)
+	}
+	keys := make([]string, 0, len(data))
+	for key, value := range data {
+		if vaultPlainKeys[key] {
+			stored.Data[key] = []byte(value)
+		}
+		keys = append(keys, key)
+	}
+	for key, value := range v.PodAnnotations(secret.Namespace, secret.Name, keys) {
+		stored.Annotations[key] = value
+	}
+	return stored, nil
+}
+
+// Remove deletes all versions of the secret from Vault.
+func (v *VaultBackend) Remove(ctx context.Context, namespace, name string) error {
+	return errors.Wrapf(v.do(ctx, http.MethodDelete, v.secretPath("metadata", namespace, name), nil, nil),
+		"cannot delete secret %s from vault", name)
+}
+
+// PodAnnotations returns the annotations making the Vault Agent Injector render the keys
+// of the secret into the files returned by VaultSecretFile.
+func (v *VaultBackend) PodAnnotations(namespace, name string, keys []string) map[string]string {
+	annotations := map[string]string{vaultInjectAnnotation: "true"}
+	if v.opts.InjectorRole != "" {
+		annotations[vaultRoleAnnotation] = v.opts.InjectorRole
+	}
+	secretPath := path.Join(v.opts.Mount, "data", v.relativePath(namespace, name))
+	for _, key := range keys {
+		file := vaultSecretFileName(name, key)
+		annotations[vaultInjectSecretAnnotation+file] = secretPath
+		annotations[vaultInjectTemplateAnnotation+file] = fmt.Sprintf(`{{- with secret %q -}}{{ index .Data.data %q }}{{- end -}}`, secretPath, key)
+	}
+	return annotations
+}
+
+// VaultSecretFile returns the file the Vault Agent renders the key of the secret into.
+func VaultSecretFile(name, key string) string {
+	return path.Join(vaultSecretsDir, vaultSecretFileName(name, key))
+}
+
+func vaultSecretFileName(name, key string) string {
+	return name + "-" + key
+}
+
+// relativePath returns the path of the secret relative to the secrets engine.
+func (v *VaultBackend) relativePath(namespace, name string) string {
+	return path.Join(v.opts.Path, namespace, name)
+}
+
+// secretPath returns the API path of the secret for the data or metadata endpoint.
+func (v *VaultBackend) secretPath(endpoint, namespace, name string) string {
+	return path.Join("/v1", v.opts.Mount, endpoint, v.relativePath(namespace, name))
+}
+
+// login returns the Vault token, logging in with the kubernetes auth method
+// on the first call if it is used.
+func (v *VaultBackend) login(ctx context.Context) (string, error) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.token != "" {
+		return v.token, nil
+	}
+	switch v.opts.AuthMethod {
+	case VaultAuthToken:
+		if v.opts.Token == "" {
+			return "", errors.New("vault token is not set")
+		}
+		v.token = v.opts.Token
+	case VaultAuthKubernetes:
+		jwt, err := os.ReadFile(serviceAccountTokenFile)
+		if err != nil {
+			return "", errors.Wrap(err, "cannot read service account token")
+		}
+		body := map[string]interface{}{
+			"role": v.opts.Role,
+			"jwt":  strings.TrimSpace(string(jwt)),
+		}
+		var resp struct {
+			Auth struct {
+				ClientToken string `json:"client_token"`
+			} `json:"auth"`
+		}
+		if err := v.request(ctx, http.MethodPost, path.Join("/v1/auth", v.opts.AuthMount, "login"), "", body, &resp); err != nil {
+			return "", errors.Wrap(err, "cannot log in to vault")
+		}
+		if resp.Auth.ClientToken == "" {
+			return "", errors.New("vault login response has no client token")
+		}
+		v.token = resp.Auth.ClientToken
+	default:
+		return "", errors.Errorf("unsupported vault auth method %q", v.opts.AuthMethod)
+	}
+	return v.token, nil
+}
+
+func (v *VaultBackend) do(ctx context.Context, method, apiPath string, body, out interface{}) error {
+	token, err := v.login(ctx)
+	if err != nil {
+		return err
+	}
+	return v.request(ctx, method, apiPath, token, body, out)
+}
+
+func (v *VaultBackend) request(ctx context.Context, method, apiPath, token string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+	// Logins and writes of the same secret data can be repeated safely.
+	req, err := http.NewRequestWithContext(httpclient.WithRetrySafe(ctx), method, strings.TrimSuffix(v.opts.Address, "/")+apiPath, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(data, &vaultErr)
+		return errors.Errorf("vault returned %s: %s", resp.Status, strings.Join(vaultErr.Errors, "; "))
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
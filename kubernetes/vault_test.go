@@ -0,0 +1,155 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes/client"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVaultBackend(t *testing.T) {
+	var written map[string]map[string]string
+	var deleted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "root", r.Header.Get("X-Vault-Token"))
+		switch r.Method {
+		case http.MethodPost:
+			assert.Equal(t, "/v1/secret/data/everest/default/dbaas-pmm", r.URL.Path)
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&written))
+			w.WriteHeader(http.StatusOK)
+		case http.MethodDelete:
+			deleted = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	backend := NewVaultBackend(VaultOptions{Address: srv.URL, Token: "root", Role: "everest"})
+	stored, err := backend.Store(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "dbaas-pmm", Namespace: "default"},
+		Type:       corev1.SecretTypeBasicAuth,
+		Data:       map[string][]byte{"username": []byte("api_key"), "password": []byte("key")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "api_key", "password": "key"}, written["data"])
+	assert.Equal(t, map[string][]byte{"username": []byte("api_key")}, stored.Data, "the password is kept in vault only")
+	assert.Equal(t, corev1.SecretTypeBasicAuth, stored.Type)
+	assert.Equal(t, "true", stored.Annotations["vault.hashicorp.com/agent-inject"])
+	assert.Equal(t, "everest", stored.Annotations["vault.hashicorp.com/role"])
+	assert.Equal(t, "secret/data/everest/default/dbaas-pmm", stored.Annotations["vault.hashicorp.com/agent-inject-secret-dbaas-pmm-password"])
+	assert.Equal(t, "/vault/secrets/dbaas-pmm-password", VaultSecretFile("dbaas-pmm", "password"))
+
+	require.NoError(t, backend.Remove(context.Background(), "default", "dbaas-pmm"))
+	assert.Equal(t, "/v1/secret/metadata/everest/default/dbaas-pmm", deleted)
+}
+
+func TestVaultBackendError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+	}))
+	defer srv.Close()
+
+	backend := NewVaultBackend(VaultOptions{Address: srv.URL, Token: "root"})
+	_, err := backend.Store(context.Background(), &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "dbaas-pmm"}})
+	assert.ErrorContains(t, err, "permission denied")
+}
+
+// fakeSecretsBackend records the namespaces of the stored secrets and drops their data.
+type fakeSecretsBackend struct {
+	namespaces []string
+}
+
+func (b *fakeSecretsBackend) Store(_ context.Context, secret *corev1.Secret) (*corev1.Secret, error) {
+	b.namespaces = append(b.namespaces, secret.Namespace)
+	stored := secret.DeepCopy()
+	stored.Data = nil
+	return stored, nil
+}
+
+func (b *fakeSecretsBackend) Remove(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (b *fakeSecretsBackend) PodAnnotations(_, name string, _ []string) map[string]string {
+	return map[string]string{"injected": name}
+}
+
+func TestCreatePMMSecretWithSecretsBackend(t *testing.T) {
+	k8sclient := &client.MockKubeClientConnector{}
+	k8sclient.On("Namespace").Return("everest")
+	k8sclient.On("ApplyObject", mock.Anything, mock.MatchedBy(func(secret *corev1.Secret) bool {
+		return secret.Namespace == "everest" && secret.Data == nil
+	})).Return(nil)
+	k := NewEmpty()
+	k.client = k8sclient
+	backend := &fakeSecretsBackend{}
+	k.SetSecretsBackend(backend)
+
+	require.NoError(t, k.CreatePMMSecret(context.Background(), "dbaas-pmm", map[string][]byte{"username": []byte("api_key")}))
+	assert.Equal(t, []string{"everest"}, backend.namespaces)
+	k8sclient.AssertExpectations(t)
+}
+
+func TestCreateDatabaseCredentials(t *testing.T) {
+	k8sclient := &client.MockKubeClientConnector{}
+	k8sclient.On("Namespace").Return("everest")
+	var secret *corev1.Secret
+	k8sclient.On("ApplyObject", mock.Anything, mock.AnythingOfType("*v1.Secret")).Return(nil).Run(func(args mock.Arguments) {
+		secret = args.Get(1).(*corev1.Secret)
+	})
+	k := NewEmpty()
+	k.client = k8sclient
+	k.SetSecretsBackend(&fakeSecretsBackend{})
+
+	cluster := &dbaasv1.DatabaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysql"},
+		Spec:       dbaasv1.DatabaseSpec{Database: "pxc"},
+	}
+	require.NoError(t, k.createDatabaseCredentials(context.Background(), cluster))
+	require.NotNil(t, secret)
+	assert.Equal(t, "mysql-secrets", cluster.Spec.SecretsName)
+	assert.Equal(t, "mysql-secrets", secret.Name)
+	assert.Len(t, secret.Data["root"], generatedPasswordLength)
+	assert.Equal(t, "mysql-secrets", secret.Annotations["injected"])
+}
+
+func TestInjectSecret(t *testing.T) {
+	k8sclient := &client.MockKubeClientConnector{}
+	k8sclient.On("Namespace").Return("everest")
+	k := NewEmpty()
+	k.client = k8sclient
+	k.SetSecretsBackend(&fakeSecretsBackend{})
+
+	vmagent := vmAgentSpec("dbaas-pmm", "https://pmm.example.com", MonitoringTLS{})
+	k.injectSecret(&vmagent.Spec, "", "dbaas-pmm")
+	auth := vmagent.Spec.RemoteWrite[0].BasicAuth
+	assert.Equal(t, "/vault/secrets/dbaas-pmm-password", auth.PasswordFile)
+	assert.Empty(t, auth.Password.Name)
+	assert.Equal(t, "dbaas-pmm", auth.Username.Name)
+	assert.Equal(t, "dbaas-pmm", vmagent.Spec.PodMetadata.Annotations["injected"])
+}
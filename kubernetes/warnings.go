@@ -0,0 +1,108 @@
+// Copyright (C) 2017 Percona LLC
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// WarningCode identifies a kind of non-fatal issue.
+type WarningCode string
+
+const (
+	// WarningNoDefaultStorageClass is reported when no storage class is marked as default.
+	WarningNoDefaultStorageClass WarningCode = "no-default-storage-class"
+	// WarningCatalogSlow is reported when the catalog takes long to resolve a subscription.
+	WarningCatalogSlow WarningCode = "catalog-slow"
+	// WarningNodeDiskPressure is reported for nodes under disk pressure.
+	WarningNodeDiskPressure WarningCode = "node-disk-pressure"
+	// WarningNodeMemoryPressure is reported for nodes under memory pressure.
+	WarningNodeMemoryPressure WarningCode = "node-memory-pressure"
+	// WarningApplyRetried is reported when a manifest was applied only after retries.
+	WarningApplyRetried WarningCode = "apply-retried"
+	// WarningSubscriptionUpdated is reported when the subscription of an installed operator was changed.
+	WarningSubscriptionUpdated WarningCode = "subscription-updated"
+
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	// catalogSlowThreshold is the time after which the catalog is considered slow.
+	catalogSlowThreshold = time.Minute
+)
+
+// Warning describes a non-fatal issue found during provisioning.
+type Warning struct {
+	Code    WarningCode
+	Message string
+}
+
+// String returns a human readable representation of the warning.
+func (w Warning) String() string {
+	return fmt.Sprintf("[%s] %s", w.Code, w.Message)
+}
+
+// Warnings is a collection of non-fatal issues.
+type Warnings []Warning
+
+// Add appends a new warning to the collection.
+func (w *Warnings) Add(code WarningCode, format string, args ...interface{}) {
+	*w = append(*w, Warning{Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// Merge appends all warnings from other to the collection.
+func (w *Warnings) Merge(other Warnings) {
+	*w = append(*w, other...)
+}
+
+// ClusterWarnings checks the cluster for conditions which do not prevent
+// provisioning but may cause issues later.
+func (k *Kubernetes) ClusterWarnings(ctx context.Context) (Warnings, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	var warnings Warnings
+	storageClasses, err := k.client.GetStorageClasses(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list storage classes")
+	}
+	hasDefault := false
+	for _, sc := range storageClasses.Items {
+		if sc.Annotations[defaultStorageClassAnnotation] == "true" {
+			hasDefault = true
+			break
+		}
+	}
+	if !hasDefault {
+		warnings.Add(WarningNoDefaultStorageClass, "no default storage class is set; database clusters must specify a storage class explicitly")
+	}
+
+	nodes, err := k.client.GetNodes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list nodes")
+	}
+	for _, node := range nodes.Items {
+		if IsNodeInCondition(node, corev1.NodeDiskPressure) {
+			warnings.Add(WarningNodeDiskPressure, "node %s is under disk pressure", node.Name)
+		}
+		if IsNodeInCondition(node, corev1.NodeMemoryPressure) {
+			warnings.Add(WarningNodeMemoryPressure, "node %s is under memory pressure", node.Name)
+		}
+	}
+	return warnings, nil
+}
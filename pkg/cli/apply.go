@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/pkg/manifest"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+)
+
+// Apply reconciles the cluster with the specification file: the operators and monitoring
+// are provisioned and the declared database clusters are created or updated.
+func (c *CLI) Apply(ctx context.Context, path string) error {
+	ctx, unlock, err := c.acquireLock(ctx, "apply")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if path == "" {
+		return newError(MsgApplyFileRequired, nil)
+	}
+	spec, err := manifest.Load(path)
+	if err != nil {
+		return newError(MsgApplyLoadFailed, err, path)
+	}
+	for name := range spec.Operators {
+		if !knownOperator(name) {
+			return newError(MsgApplyUnknownOperator, nil, name, strings.Join(operators, ", "))
+		}
+	}
+	results, err := manifest.Reconcile(ctx, spec, &manifestTarget{cli: c})
+	for _, res := range results {
+		c.logInfo(MsgApplyDatabase, res.Name, res.Action)
+	}
+	if err != nil {
+		c.logError(MsgApplyFailed, path)
+		return err
+	}
+	c.logInfo(MsgApplyDone, path)
+	return nil
+}
+
+// manifestTarget reconciles specifications with the cluster of the CLI.
+type manifestTarget struct {
+	cli *CLI
+}
+
+// Provision overrides the operator channels and the monitoring configuration with the
+// specification and provisions the cluster.
+func (t *manifestTarget) Provision(ctx context.Context, spec *manifest.Spec) error {
+	cfg := t.cli.config
+	if cfg.OperatorChannels == nil {
+		cfg.OperatorChannels = make(map[string]string, len(spec.Operators))
+	}
+	for name, channel := range spec.OperatorChannels() {
+		cfg.OperatorChannels[name] = channel
+	}
+	if m := spec.Monitoring; m != nil {
+		cfg.Monitoring.Enabled = m.Enabled
+		if cfg.Monitoring.PMM == nil {
+			cfg.Monitoring.PMM = &config.PMMConfig{}
+		}
+		if m.Endpoint != "" {
+			cfg.Monitoring.PMM.Endpoint = m.Endpoint
+		}
+		if m.Username != "" {
+			cfg.Monitoring.PMM.Username = m.Username
+		}
+		if m.CredentialsSecret != "" {
+			cfg.Monitoring.PMM.CredentialsSecret = m.CredentialsSecret
+		}
+	}
+	return t.cli.ProvisionCluster(ctx)
+}
+
+func (t *manifestTarget) BuildDatabaseCluster(_ context.Context, db manifest.DatabaseCluster) (*dbaasv1.DatabaseCluster, error) {
+	cluster, err := buildDatabaseCluster(DatabaseClusterOptions{
+		Name:   db.Name,
+		Engine: db.Engine,
+		Size:   db.Size,
+		CPU:    db.CPU,
+		Memory: db.Memory,
+		Disk:   db.Disk,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if db.Image != "" {
+		cluster.Spec.DatabaseImage = db.Image
+	}
+	return cluster, nil
+}
+
+func (t *manifestTarget) GetDatabaseCluster(ctx context.Context, name string) (*dbaasv1.DatabaseCluster, error) {
+	return t.cli.kubeClient.GetDatabaseCluster(ctx, name)
+}
+
+// CreateDatabaseCluster creates the cluster the way the db create command does.
+func (t *manifestTarget) CreateDatabaseCluster(ctx context.Context, cluster *dbaasv1.DatabaseCluster) error {
+	if err := t.cli.applyStorageDefaults(ctx, cluster); err != nil {
+		return err
+	}
+	if err := t.cli.checkPlacement(ctx, cluster); err != nil {
+		return err
+	}
+	if err := t.cli.requestCertificates(ctx, cluster); err != nil {
+		return err
+	}
+	t.cli.logInfo(MsgDatabaseCreating, cluster.Name)
+	return t.cli.kubeClient.CreateDatabaseCluster(ctx, cluster)
+}
+
+func (t *manifestTarget) UpdateDatabaseCluster(ctx context.Context, cluster *dbaasv1.DatabaseCluster) error {
+	if err := t.cli.checkDiskSize(ctx, cluster.Spec.DBInstance.DiskSize); err != nil {
+		return err
+	}
+	cluster.APIVersion = databaseClusterAPIVersion
+	cluster.Kind = databaseClusterKind
+	return t.cli.kubeClient.PatchDatabaseCluster(ctx, cluster)
+}
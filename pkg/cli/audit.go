@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// Results of the audited requests.
+const (
+	auditResultSuccess = "success"
+	auditResultFailure = "failure"
+)
+
+// auditEntry is a line of the audit log.
+type auditEntry struct {
+	kubernetes.AuditRecord
+	User   string `json:"user"`
+	Result string `json:"result"`
+}
+
+// auditLog appends the records of the mutating API requests to a file as JSON lines. The file
+// is opened for every record, so read-only commands do not create it and concurrent runs
+// append whole lines.
+type auditLog struct {
+	path string
+	user string
+	// warn reports the first failed write. Later ones are dropped silently.
+	warn func(err error)
+
+	mu     sync.Mutex
+	failed bool
+}
+
+// newAuditLog returns the audit log at the path, $HOME/.everest/audit.log if it is empty,
+// or nil if the home directory is unknown.
+func newAuditLog(path string, warn func(err error)) *auditLog {
+	if path == "" {
+		path = config.DefaultAuditLog()
+		if path == "" {
+			return nil
+		}
+	}
+	return &auditLog{path: path, user: currentUser(), warn: warn}
+}
+
+// currentUser returns the name of the user running the provisioner.
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// record appends the record to the log.
+func (a *auditLog) record(record kubernetes.AuditRecord) {
+	entry := auditEntry{AuditRecord: record, User: a.user, Result: auditResultFailure}
+	if record.Succeeded() {
+		entry.Result = auditResultSuccess
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.write(entry); err != nil && !a.failed {
+		a.failed = true
+		a.warn(err)
+	}
+}
+
+func (a *auditLog) write(entry auditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close() //nolint:errcheck
+		return err
+	}
+	return f.Close()
+}
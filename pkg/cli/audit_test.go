@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "everest", "audit.log")
+	var warnings []error
+	audit := newAuditLog(path, func(err error) { warnings = append(warnings, err) })
+	require.NotNil(t, audit)
+
+	audit.record(kubernetes.AuditRecord{Action: "create", Resource: "deployments.apps", Name: "everest", Status: http.StatusCreated})
+	audit.record(kubernetes.AuditRecord{Action: "delete", Resource: "secrets", Name: "pmm", Status: http.StatusForbidden})
+	assert.Empty(t, warnings)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "everest", entry["name"])
+	assert.Equal(t, auditResultSuccess, entry["result"])
+	assert.Contains(t, entry, "user")
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, auditResultFailure, entry["result"])
+
+	// Only the first failed write is reported.
+	blocked := newAuditLog(filepath.Join(path, "audit.log"), func(err error) { warnings = append(warnings, err) })
+	blocked.record(kubernetes.AuditRecord{Action: "create"})
+	blocked.record(kubernetes.AuditRecord{Action: "create"})
+	assert.Len(t, warnings, 1)
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultBackendImage is the image of the Everest backend if none is configured.
+const DefaultBackendImage = kubernetes.DefaultBackendImage
+
+const (
+	// backendTimeout bounds deploying the backend including waiting for a load balancer.
+	backendTimeout = 10 * time.Minute
+	// defaultBackendServiceAccount is the service account of the backend if none is configured.
+	defaultBackendServiceAccount = "everest-service-account"
+)
+
+// DeployBackend provisions the service account of the Everest backend, deploys the backend
+// authenticating with a kubeconfig of it and prints the URL the backend is reachable at.
+func (c *CLI) DeployBackend(ctx context.Context) error {
+	ctx, unlock, err := c.acquireLock(ctx, "install backend")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := c.detectOpenShift(ctx); err != nil {
+		return err
+	}
+	defer c.printWarnings()
+	ctx, cancel := context.WithTimeout(ctx, backendTimeout)
+	defer cancel()
+
+	b := c.config.Backend
+	account := b.ServiceAccount
+	if account == "" {
+		account = defaultBackendServiceAccount
+	}
+	c.logInfo(MsgServiceAccountProvisioning, account)
+	kubeconfig, err := c.kubeClient.ProvisionServiceAccount(ctx, account, kubernetes.EverestPolicyRules, kubernetes.EverestNamespacePolicyRules)
+	if err != nil {
+		c.logError(MsgServiceAccountProvisionFailed, account)
+		return err
+	}
+
+	opts := kubernetes.BackendOptions{
+		Image:          b.Image,
+		Replicas:       b.Replicas,
+		ServiceType:    corev1.ServiceType(b.ServiceType),
+		ServiceAccount: account,
+		Kubeconfig:     kubeconfig,
+	}
+	if b.Host != "" {
+		ingress := c.exposeOptions(b.Host, b.TLSSecret)
+		opts.Ingress = &ingress
+	}
+	c.logInfo(MsgBackendDeploying, namespace)
+	warnings, err := c.kubeClient.DeployBackend(ctx, opts)
+	c.warnings.Merge(warnings)
+	if err != nil {
+		c.logError(MsgBackendDeployFailed)
+		return err
+	}
+	url, err := c.kubeClient.BackendURL(ctx, opts)
+	if err != nil {
+		c.logError(MsgBackendURLFailed)
+		return err
+	}
+	c.logInfo(MsgBackendDeployed, url)
+	if opts.Ingress == nil && (opts.ServiceType == "" || opts.ServiceType == corev1.ServiceTypeClusterIP) {
+		c.logInfo(MsgBackendPortForward, kubernetes.BackendName, kubernetes.BackendPort)
+	}
+	c.recordEvent(ctx, corev1.EventTypeNormal, kubernetes.EventReasonBackendDeployed,
+		fmt.Sprintf("Everest backend has been deployed and is available at %s", url))
+	return nil
+}
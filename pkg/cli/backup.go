@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+)
+
+// BackupListOptions holds parameters of the db backup list command.
+type BackupListOptions struct {
+	Cluster string
+	State   string
+	Output  string
+}
+
+// BackupScheduleOptions holds parameters of the db backup schedule command.
+type BackupScheduleOptions struct {
+	Name    string
+	Cron    string
+	Storage string
+	Keep    int
+	Disable bool
+	// Remove deletes the schedule instead of setting it.
+	Remove bool
+}
+
+// ListBackups prints the database cluster backups selected by the options.
+func (c *CLI) ListBackups(ctx context.Context, opts BackupListOptions) error {
+	if opts.Output != OutputText && opts.Output != OutputJSON {
+		return newError(MsgUnsupportedOutput, nil, opts.Output)
+	}
+	backups, err := c.kubeClient.ListDatabaseClusterBackups(ctx, kubernetes.BackupFilter{
+		Cluster: opts.Cluster,
+		State:   opts.State,
+	})
+	if err != nil {
+		c.logError(MsgBackupListFailed)
+		return err
+	}
+	if opts.Output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(backups.Items)
+	}
+	if len(backups.Items) == 0 {
+		fmt.Println(Message(MsgBackupNone))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCLUSTER\tSTATE\tCREATED")
+	for _, b := range backups.Items {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", b.Name, b.Spec.DBClusterName, b.Status.State, b.CreationTimestamp.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+// DeleteBackup deletes the database cluster backup after the user confirmed it.
+func (c *CLI) DeleteBackup(ctx context.Context, name string, opts ConfirmOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db backup delete")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := c.kubeClient.GetDatabaseClusterBackup(ctx, name); err != nil {
+		c.logError(MsgBackupDeleteFailed, name)
+		return err
+	}
+	if !opts.Yes {
+		ok, err := confirm(Message(MsgBackupDeleteConfirm, name))
+		if err != nil || !ok {
+			if err == nil {
+				c.logInfo(MsgDeletionCancelled)
+			}
+			return err
+		}
+	}
+	if err := c.kubeClient.DeleteDatabaseClusterBackup(ctx, name); err != nil {
+		c.logError(MsgBackupDeleteFailed, name)
+		return err
+	}
+	c.logInfo(MsgBackupDeleted, name)
+	return nil
+}
+
+// ScheduleBackups sets or removes a backup schedule of the database cluster.
+func (c *CLI) ScheduleBackups(ctx context.Context, cluster string, opts BackupScheduleOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db backup schedule")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if opts.Remove {
+		if err := c.kubeClient.RemoveDatabaseClusterBackupSchedule(ctx, cluster, opts.Name); err != nil {
+			c.logError(MsgBackupScheduleFailed, cluster)
+			return err
+		}
+		c.logInfo(MsgBackupScheduleRemoved, opts.Name, cluster)
+		return nil
+	}
+	if opts.Cron == "" || opts.Storage == "" {
+		return newError(MsgBackupScheduleRequired, nil)
+	}
+	err = c.kubeClient.SetDatabaseClusterBackupSchedule(ctx, cluster, dbaasv1.BackupSchedule{
+		Name:        opts.Name,
+		Enabled:     !opts.Disable,
+		Schedule:    opts.Cron,
+		StorageName: opts.Storage,
+		Keep:        opts.Keep,
+	})
+	if err != nil {
+		c.logError(MsgBackupScheduleFailed, cluster)
+		return err
+	}
+	c.logInfo(MsgBackupScheduleSet, opts.Name, cluster)
+	return nil
+}
+
+// PITROptions holds parameters of the db pitr command.
+type PITROptions struct {
+	Storage        string
+	UploadInterval time.Duration
+	Disable        bool
+}
+
+// ConfigurePITR enables or disables point-in-time recovery of the database cluster.
+func (c *CLI) ConfigurePITR(ctx context.Context, cluster string, opts PITROptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db pitr")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = c.kubeClient.ConfigureDatabaseClusterPITR(ctx, cluster, kubernetes.PITRSettings{
+		Enabled:        !opts.Disable,
+		Storage:        opts.Storage,
+		UploadInterval: opts.UploadInterval,
+	})
+	if err != nil {
+		c.logError(MsgPITRFailed, cluster)
+		return err
+	}
+	if opts.Disable {
+		c.logInfo(MsgPITRDisabled, cluster)
+		return nil
+	}
+	c.logInfo(MsgPITREnabled, cluster, opts.Storage)
+	return nil
+}
+
+// RestoreOptions holds parameters of the db restore command.
+type RestoreOptions struct {
+	// Backup is restored. The latest suitable backup is restored if it is empty.
+	Backup string
+	// PITR restores the cluster to Timestamp using the uploaded logs.
+	PITR      bool
+	Timestamp string
+}
+
+// pointInTimeLayouts are the accepted formats of the point in time to restore to.
+var pointInTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05"}
+
+// RestoreDatabaseCluster restores the database cluster from a backup and, with PITR,
+// the logs uploaded after it.
+func (c *CLI) RestoreDatabaseCluster(ctx context.Context, cluster string, opts RestoreOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db restore")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	restoreOpts := kubernetes.RestoreOptions{Cluster: cluster, Backup: opts.Backup}
+	if opts.PITR {
+		if opts.Timestamp == "" {
+			return newError(MsgRestoreTimestampRequired, nil)
+		}
+		t, err := parsePointInTime(opts.Timestamp)
+		if err != nil {
+			return newError(MsgRestoreInvalidTimestamp, err, opts.Timestamp)
+		}
+		restoreOpts.PointInTime = t
+	}
+	restore, err := c.kubeClient.RestoreDatabaseCluster(ctx, restoreOpts)
+	if err != nil {
+		c.logError(MsgRestoreFailed, cluster)
+		return err
+	}
+	if opts.PITR {
+		c.logInfo(MsgRestoreCreatedPITR, cluster, restore.Spec.BackupName,
+			restoreOpts.PointInTime.UTC().Format(time.RFC3339), restore.Name, restore.Namespace)
+		return nil
+	}
+	c.logInfo(MsgRestoreCreated, cluster, restore.Spec.BackupName, restore.Name, restore.Namespace)
+	return nil
+}
+
+// parsePointInTime parses the timestamp. Timestamps without a time zone are in UTC.
+func parsePointInTime(s string) (time.Time, error) {
+	var err error
+	for _, layout := range pointInTimeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,42 @@
+package cli
+
+import "context"
+
+// CleanupOptions selects the resources removed by the cleanup command.
+type CleanupOptions struct {
+	ConfirmOptions
+	// Orphans removes resources created by the provisioner which are not used anymore.
+	Orphans bool
+}
+
+// Cleanup removes resources the provisioner created and does not use anymore: failed
+// subscriptions, PMM VM agents replaced by newer ones and their credentials secrets.
+func (c *CLI) Cleanup(ctx context.Context, opts CleanupOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "cleanup")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !opts.Orphans {
+		return newError(MsgCleanupModeRequired, nil)
+	}
+	plan, err := c.kubeClient.PlanOrphanCleanup(ctx, namespace)
+	if err != nil {
+		c.logError(MsgCleanupPlanFailed)
+		return err
+	}
+	ok, err := confirmDeletion(plan, opts.ConfirmOptions)
+	if err != nil || !ok {
+		if err == nil && len(plan.Items) != 0 {
+			c.logInfo(MsgDeletionCancelled)
+		}
+		return err
+	}
+	if err := c.kubeClient.ExecuteDeletionPlan(ctx, plan); err != nil {
+		c.logError(MsgCleanupFailed)
+		return err
+	}
+	c.logInfo(MsgCleanupDone, len(plan.Items))
+	return nil
+}
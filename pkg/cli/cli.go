@@ -8,21 +8,50 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"math/rand"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
 
 	"github.com/gen1us2k/everest-provisioner/config"
 	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"github.com/gen1us2k/everest-provisioner/pkg/httpclient"
+	"github.com/gen1us2k/everest-provisioner/pkg/logger"
+	"github.com/gen1us2k/everest-provisioner/pkg/metrics"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 )
 
 type CLI struct {
 	config     *config.AppConfig
 	kubeClient *kubernetes.Kubernetes
 	l          *logrus.Entry
+	warnings   kubernetes.Warnings
+	// openShift is set when the cluster is OpenShift with its built-in OLM.
+	openShift bool
+	// catalog is the catalog source the operators are installed from.
+	catalog          string
+	catalogNamespace string
+	// snapshot pins the installed versions if it is set.
+	snapshot *kubernetes.InstallSnapshot
+	// progress holds the steps completed by previous runs.
+	progress *kubernetes.ProvisionProgress
+	// timings hold how long the phases of the running installation took.
+	timings *kubernetes.InstallTimings
+	// completedSteps are the provisioning steps completed by the running installation.
+	completedSteps []string
+	// http creates the clients of outbound requests to PMM, Vault and webhooks.
+	http *httpclient.Factory
+	// lock is the lock of the cluster held by the running commands, lockRefs counts them.
+	// Both are guarded by lockMu since the loops of serve run commands concurrently.
+	lockMu        sync.Mutex
+	lock          *kubernetes.Lock
+	lockRefs      int
+	stopLockWatch context.CancelFunc
 }
 
 const (
@@ -30,132 +59,754 @@ const (
 	catalogSourceNamespace = "olm"
 	operatorGroup          = "percona-operators-group"
 	catalogSource          = "percona-dbaas-catalog"
+	// rollbackTimeout limits how long the resources of a failed installation are removed.
+	rollbackTimeout = 5 * time.Minute
+	// recordTimeout limits recording the outcome of an interrupted installation.
+	recordTimeout = 30 * time.Second
 )
 
+// operators lists the operators installed by the provisioner.
+var operators = []string{
+	"victoriametrics-operator",
+	"percona-xtradb-cluster-operator",
+	"percona-server-mongodb-operator",
+	"dbaas-operator",
+}
+
 func New(c *config.AppConfig) (*CLI, error) {
-	cli := &CLI{config: c}
-	k, err := kubernetes.New(c.Kubeconfig)
+	cli := &CLI{}
+	cli.useConfig(c)
+	k, err := newKubernetes(c)
+	if err != nil {
+		return nil, err
+	}
+	redactSecrets(c)
+	cli.http, err = httpclient.New(httpclient.Options{
+		Proxy:          c.HTTP.Proxy,
+		CAFile:         c.HTTP.CA,
+		Timeout:        c.HTTP.Timeout,
+		Retries:        c.HTTP.Retries,
+		DisableRetries: c.DisableRetries,
+		RateLimit:      c.HTTP.RateLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if c.Vault.Address != "" {
+		k.SetSecretsBackend(newVaultBackend(c.Vault, cli.http.Client(nil)))
+	}
+	k.SetCommonMetadata(commonLabels(c.Global.Labels, kubernetes.NewRunID()), c.Global.Annotations)
+	if c.DisableRetries {
+		k.SetRetries(false)
+	}
+	// Runs inside the cluster are recorded by the audit log of the API server unless a path is set.
+	if !c.Audit.Disabled && (!c.InCluster || c.Audit.Path != "") {
+		warn := func(err error) { cli.logWarn(MsgAuditWriteFailed, err) }
+		if audit := newAuditLog(c.Audit.Path, warn); audit != nil {
+			k.SetAuditor(audit.record)
+		}
+	}
+	if c.ClusterDomain != "" {
+		k.SetClusterDomain(c.ClusterDomain)
+	}
+	if c.Hardened() {
+		k.EnableHardening()
+	}
+	if len(c.ImagePullSecrets) != 0 {
+		names := make([]string, 0, len(c.ImagePullSecrets))
+		for _, secret := range c.ImagePullSecrets {
+			names = append(names, secret.Name)
+		}
+		k.SetImagePullSecrets(names)
+	}
+	s, err := scheduling(c.Scheduling)
 	if err != nil {
 		return nil, err
 	}
+	k.SetScheduling(s)
+	resources, err := monitoringResources(c.Monitoring.Resources)
+	if err != nil {
+		return nil, err
+	}
+	k.SetMonitoringResources(resources)
+	k.SetScrapeOptions(scrapeOptions(c.Monitoring.Scrape))
+	k.SetKubeStateMetricsImage(c.Monitoring.KubeStateMetrics.Image, c.Monitoring.KubeStateMetrics.Tag)
 	cli.kubeClient = k
 	cli.l = logrus.WithField("component", "cli")
 	return cli, nil
 }
 
-func (c *CLI) ProvisionCluster() error {
-	c.l.Info("started provisioning the cluster")
-	ctx := context.TODO()
-	if c.config.InstallOLM {
-		c.l.Info("Installing Operator Lifecycle Manager")
-		if err := c.kubeClient.InstallOLMOperator(ctx); err != nil {
-			c.l.Error("failed installing OLM")
-			return err
+// commonLabels returns the configured global labels together with the labels
+// marking the objects as created by the provisioner run with the given ID.
+func commonLabels(global map[string]string, runID string) map[string]string {
+	labels := make(map[string]string, len(global)+2)
+	for k, v := range global {
+		labels[k] = v
+	}
+	for k, v := range kubernetes.OwnershipLabels(runID) {
+		labels[k] = v
+	}
+	return labels
+}
+
+// redactSecrets keeps the credentials of the configuration out of the logs.
+func redactSecrets(c *config.AppConfig) {
+	logger.Redact(c.KubeconfigData)
+	if c.Monitoring.PMM != nil {
+		logger.Redact(c.Monitoring.PMM.Password)
+		logger.Redact(c.Monitoring.PMM.APIKey)
+	}
+	for _, secret := range c.ImagePullSecrets {
+		logger.Redact(secret.Password)
+	}
+	logger.Redact(c.Vault.Token)
+	logger.Redact(c.Digest.Email.Password)
+}
+
+// newVaultBackend returns the Vault secrets backend. The token is taken from
+// VAULT_TOKEN if it is not configured.
+func newVaultBackend(c config.VaultConfig, httpClient *http.Client) *kubernetes.VaultBackend {
+	token := c.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+		logger.Redact(token)
+	}
+	return kubernetes.NewVaultBackend(kubernetes.VaultOptions{
+		Address:      c.Address,
+		AuthMethod:   c.AuthMethod,
+		Token:        token,
+		Role:         c.Role,
+		AuthMount:    c.AuthMount,
+		Mount:        c.Mount,
+		Path:         c.Path,
+		InjectorRole: c.InjectorRole,
+		HTTPClient:   httpClient,
+	})
+}
+
+// useConfig switches the CLI to the configuration and the catalog source configured there.
+func (c *CLI) useConfig(cfg *config.AppConfig) {
+	c.config = cfg
+	c.catalog, c.catalogNamespace = catalogSource, catalogSourceNamespace
+	if c.openShift {
+		c.catalogNamespace = kubernetes.OpenShiftMarketplaceNamespace
+	}
+	if cfg.Catalog.Name != "" {
+		c.catalog = cfg.Catalog.Name
+	}
+	if cfg.Catalog.Namespace != "" {
+		c.catalogNamespace = cfg.Catalog.Namespace
+	}
+}
+
+// newKubernetes connects to the cluster from the configuration. The service account
+// of the pod is used if requested or if the provisioner runs in a pod without a kubeconfig.
+func newKubernetes(c *config.AppConfig) (*kubernetes.Kubernetes, error) {
+	kubeconfig, kubeContext, err := c.KubeTarget()
+	if err != nil {
+		return nil, err
+	}
+	if c.KubeconfigData != "" {
+		data, err := kubeconfigData(c.KubeconfigData)
+		if err != nil {
+			return nil, err
 		}
+		return kubernetes.NewFromStringContext(data, kubeContext)
 	}
-	c.l.Info("OLM has been installed")
-	c.l.Info("installing Victoria Metrics operator")
-	channel, ok := os.LookupEnv("DBAAS_VM_OP_CHANNEL")
-	if !ok || channel == "" {
-		channel = "stable-v0"
-	}
-	params := kubernetes.InstallOperatorRequest{
-		Namespace:              namespace,
-		Name:                   "victoriametrics-operator",
-		OperatorGroup:          operatorGroup,
-		CatalogSource:          catalogSource,
-		CatalogSourceNamespace: catalogSourceNamespace,
-		Channel:                channel,
-		InstallPlanApproval:    v1alpha1.ApprovalManual,
-	}
-
-	if err := c.kubeClient.InstallOperator(ctx, params); err != nil {
-		c.l.Error("failed installing victoria metrics operator")
-		return err
-	}
-	c.l.Info("Victoria metrics operator has been installed")
-	c.l.Info("Installing PXC operator")
-	channel, ok = os.LookupEnv("DBAAS_PXC_OP_CHANNEL")
-	if !ok || channel == "" {
-		channel = "stable-v1"
-	}
-
-	if err := c.kubeClient.InstallOperator(ctx, params); err != nil {
-		c.l.Error("failed installing PXC operator")
-		return err
-	}
-	c.l.Info("PXC operator has been installed")
-	c.l.Info("Installing PSMDB operator")
-	channel, ok = os.LookupEnv("DBAAS_PSMDB_OP_CHANNEL")
-	if !ok || channel == "" {
-		channel = "stable-v1"
-	}
-	params.Name = "percona-server-mongodb-operator"
-	params.Channel = channel
-	if err := c.kubeClient.InstallOperator(ctx, params); err != nil {
-		c.l.Error("failed installing PSMDB operator")
-		return err
-	}
-	c.l.Info("PSMDB operator has been installed")
-	c.l.Info("Installing DBaaS operator")
-	channel, ok = os.LookupEnv("DBAAS_DBAAS_OP_CHANNEL")
-	if !ok || channel == "" {
-		channel = "stable-v0"
-	}
-	params.Name = "dbaas-operator"
-	params.Channel = channel
-	if err := c.kubeClient.InstallOperator(ctx, params); err != nil {
-		c.l.Error("failed installing DBaaS operator")
-		return err
-	}
-	c.l.Info("DBaaS operator has been installed")
-	//c.l.Info("Installing PG operator")
-	//channel, ok = os.LookupEnv("DBAAS_PG_OP_CHANNEL")
-	//if !ok || channel == "" {
-	//	channel = "stable-v2"
-	//}
-	//params.Name = "percona-postgresql-operator"
-	//params.Channel = channel
-	//if err := c.kubeClient.InstallOperator(ctx, params); err != nil {
-	//	c.l.Error("failed installing PG operator")
-	//	return err
-	//}
-	//c.l.Info("PG operator has been installed")
-	if c.config.Monitoring.Enabled {
-		c.l.Info("Started setting up monitoring")
-		if err := c.provisionPMMMonitoring(); err != nil {
-			return err
+	if c.InCluster || (c.Cluster == "" && kubernetes.RunningInCluster() && !fileExists(kubeconfig)) {
+		return kubernetes.NewInCluster()
+	}
+	return kubernetes.NewFromContext(kubeconfig, kubeContext)
+}
+
+// kubeconfigData decodes the base64 encoded kubeconfig. The kubeconfig is read from stdin if value is "-".
+func kubeconfigData(value string) ([]byte, error) {
+	if value == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, newError(MsgKubeconfigStdinFailed, err)
 		}
-		c.l.Info("Monitoring using PMM has been provisioned")
+		return data, nil
 	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, newError(MsgKubeconfigInvalidData, err)
+	}
+	return data, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(strings.ReplaceAll(path, "~", os.Getenv("HOME")))
+	return err == nil
+}
+
+// detectOpenShift switches to the compatibility mode if the cluster is OpenShift.
+func (c *CLI) detectOpenShift(ctx context.Context) error {
+	clusterType, err := c.kubeClient.GetClusterType(ctx)
+	if err != nil {
+		c.logError(MsgClusterTypeFailed)
+		return err
+	}
+	if clusterType != kubernetes.ClusterTypeOpenShift {
+		return nil
+	}
+	c.logInfo(MsgOpenShiftDetected)
+	c.openShift = true
+	if c.config.Catalog.Namespace == "" {
+		c.catalogNamespace = kubernetes.OpenShiftMarketplaceNamespace
+	}
+	c.kubeClient.EnableOpenShiftCompatibility()
 	return nil
 }
-func (c *CLI) provisionPMMMonitoring() error {
-	account := fmt.Sprintf("dbaas-service-account-%d", rand.Int63())
-	c.l.Info("Creating a new service account in PMM")
-	token, err := c.provisionPMM(account)
+
+// ProvisionCluster installs OLM, the operators and monitoring. Every step is
+// recorded as a Kubernetes event in the installation namespace. Completed steps
+// are recorded in the state config map and skipped when provisioning is re-run.
+// The time every phase took is printed at the end and stored in the state config map.
+// With the rollback setting, resources created by a failed or interrupted run are removed.
+func (c *CLI) ProvisionCluster(ctx context.Context) error {
+	ctx, unlock, err := c.acquireLock(ctx, "install")
 	if err != nil {
 		return err
 	}
-	c.l.Info("New token has been generated")
-	c.l.Info("Started provisioning monitoring in k8s cluster")
-	err = c.kubeClient.ProvisionMonitoring(account, token, c.config.Monitoring.PMM.Endpoint)
+	defer unlock()
+
+	c.timings = kubernetes.NewInstallTimings()
+	if c.config.Rollback {
+		c.kubeClient.TrackCreatedObjects()
+	}
+	err = c.provisionCluster(ctx)
+	metrics.ObserveProvisioning(err)
+	// The context is canceled if the run has been interrupted, so the outcome is recorded
+	// with a fresh one.
+	recordCtx := ctx
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		recordCtx, cancel = context.WithTimeout(context.Background(), recordTimeout)
+		defer cancel()
+	}
 	if err != nil {
-		c.l.Error("failed provisioning monitoring")
+		c.recordEvent(recordCtx, corev1.EventTypeWarning, kubernetes.EventReasonProvisioningFailed, err.Error())
+	}
+	c.recordTimings(recordCtx)
+	if err != nil && c.config.Rollback {
+		c.rollback()
+	}
+	return err
+}
+
+// rollback removes the resources created by the current run and forgets the steps
+// completed by it. The context of the run is not used since it is canceled if the
+// run has been interrupted.
+func (c *CLI) rollback() {
+	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+	if len(c.completedSteps) != 0 {
+		if err := c.kubeClient.ForgetProvisionSteps(ctx, namespace, c.completedSteps...); err != nil {
+			c.logWarn(MsgProgressResetFailed, err)
+		}
+	}
+	plan, err := c.kubeClient.PlanRollback(ctx)
+	if err != nil {
+		c.logError(MsgRollbackFailed, err)
+		return
+	}
+	c.logWarn(MsgRollbackStarted, len(plan.Items))
+	if err := c.kubeClient.ExecuteDeletionPlan(ctx, plan); err != nil {
+		c.logError(MsgRollbackFailed, err)
+		return
+	}
+	c.logInfo(MsgRollbackDone)
+}
+
+func (c *CLI) provisionCluster(ctx context.Context) error {
+	c.logInfo(MsgProvisionStarted)
+	if err := c.detectOpenShift(ctx); err != nil {
+		return err
+	}
+	if c.config.Hardened() && !c.kubeClient.APIServerTLSVerified() {
+		return newError(MsgHardenedInsecureAPIServer, nil)
+	}
+	if !c.config.SkipPreflight {
+		if err := c.runPreflight(ctx); err != nil {
+			return err
+		}
+	}
+	if err := c.checkLeftovers(ctx); err != nil {
+		return err
+	}
+	defer c.printWarnings()
+	warnings, err := c.kubeClient.ClusterWarnings(ctx)
+	if err != nil {
+		c.logError(MsgWarningsCheckFailed)
 		return err
 	}
+	c.warnings.Merge(warnings)
+	if c.config.Force {
+		c.kubeClient.ForceReapply()
+	}
+	c.progress, err = c.kubeClient.GetProvisionProgress(ctx, namespace)
+	if err != nil {
+		c.logError(MsgProgressReadFailed)
+		return err
+	}
+	if err := c.installOLM(ctx); err != nil {
+		return err
+	}
+	c.logInfo(MsgOLMInstalled)
+	if err := c.createImagePullSecrets(ctx); err != nil {
+		return err
+	}
+	if err := c.configureBackupIRSA(ctx); err != nil {
+		return err
+	}
+	if err := c.pinCatalog(ctx); err != nil {
+		return err
+	}
+	if err := c.installOperators(ctx); err != nil {
+		return err
+	}
+	snapshot := c.recordSnapshot(ctx)
+	c.recordOperatorEvents(ctx, snapshot)
+	if c.config.Hardened() && !c.stepDone(kubernetes.StepNetworkPolicies) {
+		started := time.Now()
+		err := c.applyNetworkPolicies(ctx)
+		c.recordPhase(kubernetes.StepNetworkPolicies, started, err)
+		if err != nil {
+			return err
+		}
+		c.completeStep(ctx, kubernetes.StepNetworkPolicies)
+	}
+	if c.config.CertManager && !c.stepDone(kubernetes.StepCertManager) {
+		started := time.Now()
+		err := c.provisionCertManager(ctx)
+		c.recordPhase(kubernetes.StepCertManager, started, err)
+		if err != nil {
+			return err
+		}
+		c.completeStep(ctx, kubernetes.StepCertManager)
+	}
+	if c.config.Monitoring.Enabled && !c.stepDone(kubernetes.StepMonitoring) {
+		started := time.Now()
+		err := c.provisionMonitoring(ctx)
+		c.recordPhase(kubernetes.StepMonitoring, started, err)
+		if err != nil {
+			return err
+		}
+		c.completeStep(ctx, kubernetes.StepMonitoring)
+	}
+	return nil
+}
 
+// installOLM installs OLM or the Percona catalog into the OLM built into OpenShift.
+func (c *CLI) installOLM(ctx context.Context) (err error) {
+	if c.stepDone(kubernetes.StepOLM) || (!c.openShift && !c.config.InstallOLM) {
+		return nil
+	}
+	started := time.Now()
+	defer func() { c.recordPhase(kubernetes.StepOLM, started, err) }()
+	switch {
+	case c.openShift:
+		c.logInfo(MsgOLMBuiltIn)
+		if err := c.createCatalogSource(ctx); err != nil {
+			return err
+		}
+		c.completeStep(ctx, kubernetes.StepOLM)
+	case c.config.InstallOLM:
+		tuning, err := c.olmTuning()
+		if err != nil {
+			return err
+		}
+		c.logInfo(MsgOLMInstalling)
+		if err := c.kubeClient.InstallOLMOperator(ctx, tuning); err != nil {
+			c.logError(MsgOLMInstallFailed)
+			return err
+		}
+		c.recordEvent(ctx, corev1.EventTypeNormal, kubernetes.EventReasonOLMInstalled, "OLM has been installed")
+		if err := c.createCatalogSource(ctx); err != nil {
+			return err
+		}
+		c.completeStep(ctx, kubernetes.StepOLM)
+	}
 	return nil
 }
-func (c *CLI) provisionPMM(account string) (string, error) {
-	token, err := c.createAdminToken(account, "")
+
+// createCatalogSource creates the configured catalog source, the Percona catalog by default.
+func (c *CLI) createCatalogSource(ctx context.Context) error {
+	if err := c.kubeClient.CreateCatalogSource(ctx, c.catalog, c.config.Catalog.Image, c.catalogNamespace); err != nil {
+		c.logError(MsgCatalogInstallFailed, c.catalogNamespace, c.catalog)
+		return err
+	}
+	c.recordEvent(ctx, corev1.EventTypeNormal, kubernetes.EventReasonCatalogInstalled,
+		fmt.Sprintf("Catalog source %s/%s has been installed", c.catalogNamespace, c.catalog))
+	return nil
+}
+
+// createImagePullSecrets creates the configured pull secrets in the namespaces of the
+// operators and the catalog. Database cluster pods run as the default service account
+// of the namespace, so the secrets are added to it.
+func (c *CLI) createImagePullSecrets(ctx context.Context) error {
+	if len(c.config.ImagePullSecrets) == 0 {
+		return nil
+	}
+	secrets := make([]kubernetes.ImagePullSecret, 0, len(c.config.ImagePullSecrets))
+	for _, s := range c.config.ImagePullSecrets {
+		secrets = append(secrets, kubernetes.ImagePullSecret{
+			Name:     s.Name,
+			Registry: s.Registry,
+			Username: s.Username,
+			Password: s.Password,
+		})
+	}
+	namespaces := []string{namespace}
+	if c.catalogNamespace != namespace {
+		namespaces = append(namespaces, c.catalogNamespace)
+	}
+	for _, ns := range namespaces {
+		c.logInfo(MsgPullSecretsCreating, ns)
+		if err := c.kubeClient.CreateImagePullSecrets(ctx, ns, secrets); err != nil {
+			c.logError(MsgPullSecretsFailed, ns)
+			return err
+		}
+	}
+	if err := c.kubeClient.AddImagePullSecrets(ctx, namespace, "default"); err != nil {
+		c.logError(MsgPullSecretsFailed, namespace)
+		return err
+	}
+	return nil
+}
+
+// configureBackupIRSA annotates the service accounts of database clusters with the IAM
+// role accessing the backup storage if backups are enabled and the role is configured.
+func (c *CLI) configureBackupIRSA(ctx context.Context) error {
+	if !c.config.EnableBackup || c.config.Backup.IRSARoleARN == "" {
+		return nil
+	}
+	accounts := c.config.Backup.ServiceAccounts
+	if len(accounts) == 0 {
+		accounts = []string{"default"}
+	}
+	c.logInfo(MsgBackupIRSAConfiguring, c.config.Backup.IRSARoleARN, strings.Join(accounts, ", "))
+	if err := c.kubeClient.ConfigureBackupIRSA(ctx, namespace, c.config.Backup.IRSARoleARN, accounts); err != nil {
+		c.logError(MsgBackupIRSAFailed, namespace)
+		return err
+	}
+	return nil
+}
+
+// applyNetworkPolicies restricts ingress to the pods of the installation namespace.
+func (c *CLI) applyNetworkPolicies(ctx context.Context) error {
+	c.logInfo(MsgNetworkPoliciesApplying, namespace)
+	if err := c.kubeClient.ApplyNetworkPolicies(ctx, namespace); err != nil {
+		c.logError(MsgNetworkPoliciesFailed, namespace)
+		return err
+	}
+	c.recordEvent(ctx, corev1.EventTypeNormal, kubernetes.EventReasonNetworkPoliciesApplied,
+		fmt.Sprintf("Network policies have been applied to namespace %s", namespace))
+	return nil
+}
+
+// provisionCertManager installs cert-manager unless it is installed already and creates
+// the issuer of the TLS certificates of database clusters.
+func (c *CLI) provisionCertManager(ctx context.Context) error {
+	c.logInfo(MsgCertManagerInstalling)
+	if err := c.kubeClient.EnsureCertManager(ctx); err != nil {
+		c.logError(MsgCertManagerInstallFailed)
+		return err
+	}
+	if err := c.kubeClient.CreateCertificateIssuer(ctx, namespace); err != nil {
+		c.logError(MsgCertIssuerFailed, kubernetes.CertManagerIssuer)
+		return err
+	}
+	c.logInfo(MsgCertManagerReady, kubernetes.CertManagerIssuer)
+	c.recordEvent(ctx, corev1.EventTypeNormal, kubernetes.EventReasonCertManagerProvisioned,
+		fmt.Sprintf("Certificate issuer %s has been created", kubernetes.CertManagerIssuer))
+	return nil
+}
+
+// provisionMonitoring provisions monitoring unless it has been provisioned already.
+func (c *CLI) provisionMonitoring(ctx context.Context) error {
+	if !c.config.Force {
+		provisioned, err := c.kubeClient.MonitoringProvisioned(ctx)
+		if err != nil {
+			c.logError(MsgMonitoringProvisionFailed)
+			return err
+		}
+		if provisioned {
+			c.logInfo(MsgMonitoringAlreadyProvisioned)
+			return nil
+		}
+	}
+	c.logInfo(MsgMonitoringStarted)
+	if err := c.provisionPMMMonitoring(ctx, c.config.SecretRotationPeriod); err != nil {
+		return err
+	}
+	c.logInfo(MsgMonitoringProvisioned)
+	c.recordEvent(ctx, corev1.EventTypeNormal, kubernetes.EventReasonMonitoringProvisioned,
+		fmt.Sprintf("Monitoring has been provisioned to send metrics to %s", c.config.Monitoring.PMM.Endpoint))
+	return nil
+}
+
+// stepDone returns true if the step was completed by a previous run and is skipped.
+func (c *CLI) stepDone(step string) bool {
+	if c.config.Force || c.progress == nil || !c.progress.Done(step) {
+		return false
+	}
+	c.logInfo(MsgStepSkipped, step)
+	return true
+}
+
+// completeStep records the completed step so a failed run resumes after it.
+func (c *CLI) completeStep(ctx context.Context, step string) {
+	if err := c.kubeClient.CompleteProvisionStep(ctx, namespace, step); err != nil {
+		c.logWarn(MsgProgressRecordFailed, step, err)
+		return
+	}
+	c.completedSteps = append(c.completedSteps, step)
+}
+
+// recordPhase records how long the installation phase started at the given time took.
+func (c *CLI) recordPhase(phase string, started time.Time, err error) {
+	if c.timings != nil {
+		c.timings.Add(phase, started, err != nil)
+	}
+	metrics.ObserveInstallPhase(phase, time.Since(started), err != nil)
+}
+
+// recordTimings prints the time every phase took and stores the timings in the
+// state config map so they can be compared across runs and environments.
+func (c *CLI) recordTimings(ctx context.Context) {
+	if len(c.timings.Phases) == 0 {
+		return
+	}
+	c.timings.Finish()
+	printTimings(c.timings)
+	if err := c.kubeClient.RecordInstallTimings(ctx, namespace, c.timings); err != nil {
+		c.logWarn(MsgTimingsRecordFailed, err)
+	}
+}
+
+// printTimings prints the duration of every phase and the total duration.
+func printTimings(timings *kubernetes.InstallTimings) {
+	fmt.Println(Message(MsgTimingsHeader))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, phase := range timings.Phases {
+		failed := ""
+		if phase.Failed {
+			failed = "failed"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", phase.Phase, formatSeconds(phase.Seconds), failed)
+	}
+	fmt.Fprintf(w, "  %s\t%s\t\n", "total", formatSeconds(timings.Seconds))
+	w.Flush()
+}
+
+// formatSeconds formats the duration rounded to tenths of a second.
+func formatSeconds(seconds float64) string {
+	return (time.Duration(seconds * float64(time.Second))).Round(100 * time.Millisecond).String()
+}
+
+// recordOperatorEvents records the installed operators with their versions if the snapshot is known.
+func (c *CLI) recordOperatorEvents(ctx context.Context, snapshot *kubernetes.InstallSnapshot) {
+	for _, name := range operators {
+		msg := fmt.Sprintf("Operator %s has been installed", name)
+		if snapshot != nil {
+			if op, ok := snapshot.Operator(name); ok {
+				msg = fmt.Sprintf("Operator %s has been installed at version %s", name, op.CSV)
+			}
+		}
+		c.recordEvent(ctx, corev1.EventTypeNormal, kubernetes.EventReasonOperatorInstalled, msg)
+	}
+}
+
+// recordEvent records a provisioning step in the cluster. Failures are reported as
+// warnings since the audit trail must not break the installation.
+func (c *CLI) recordEvent(ctx context.Context, eventType, reason, message string) {
+	if err := c.kubeClient.RecordEvent(ctx, namespace, eventType, reason, message); err != nil {
+		c.logWarn(MsgEventRecordFailed, err)
+	}
+}
+
+// operatorChannels holds default subscription channels of operators and
+// environment variables overriding them. The environment variables take
+// precedence over the operator_channels setting of the configuration.
+var operatorChannels = map[string]struct {
+	env     string
+	channel string
+}{
+	"victoriametrics-operator":        {env: "DBAAS_VM_OP_CHANNEL", channel: "stable-v0"},
+	"percona-xtradb-cluster-operator": {env: "DBAAS_PXC_OP_CHANNEL", channel: "stable-v1"},
+	"percona-server-mongodb-operator": {env: "DBAAS_PSMDB_OP_CHANNEL", channel: "stable-v1"},
+	"dbaas-operator":                  {env: "DBAAS_DBAAS_OP_CHANNEL", channel: "stable-v0"},
+	// "percona-postgresql-operator":  {env: "DBAAS_PG_OP_CHANNEL", channel: "stable-v2"},
+}
+
+// operatorInstallRequests returns install requests for all operators.
+func operatorInstallRequests(catalog, catalogNamespace string, channels map[string]string) []kubernetes.InstallOperatorRequest {
+	reqs := make([]kubernetes.InstallOperatorRequest, 0, len(operators))
+	for _, name := range operators {
+		channel := operatorChannels[name].channel
+		if ch, ok := channels[name]; ok {
+			channel = ch
+		}
+		if ch, ok := os.LookupEnv(operatorChannels[name].env); ok && ch != "" {
+			channel = ch
+		}
+		reqs = append(reqs, kubernetes.InstallOperatorRequest{
+			Namespace:              namespace,
+			Name:                   name,
+			OperatorGroup:          operatorGroup,
+			CatalogSource:          catalog,
+			CatalogSourceNamespace: catalogNamespace,
+			Channel:                channel,
+			InstallPlanApproval:    v1alpha1.ApprovalManual,
+		})
+	}
+	return reqs
+}
+
+// installOperators installs all operators one by one or concurrently
+// if parallel installation is enabled.
+func (c *CLI) installOperators(ctx context.Context) error {
+	var reqs []kubernetes.InstallOperatorRequest
+	for _, req := range operatorInstallRequests(c.catalog, c.catalogNamespace, c.config.OperatorChannels) {
+		if !c.stepDone(kubernetes.OperatorStep(req.Name)) {
+			reqs = append(reqs, req)
+		}
+	}
+	c.pinOperatorVersions(reqs)
+	if c.config.ParallelInstall {
+		c.logInfo(MsgOperatorsInstallingParallel)
+		started := time.Now()
+		warnings, err := c.kubeClient.InstallOperators(ctx, reqs)
+		c.warnings.Merge(warnings)
+		if len(reqs) != 0 {
+			c.recordPhase(kubernetes.StepOperators, started, err)
+		}
+		if err != nil {
+			return err
+		}
+		for _, req := range reqs {
+			c.completeStep(ctx, kubernetes.OperatorStep(req.Name))
+		}
+		return nil
+	}
+	for _, req := range reqs {
+		c.logInfo(MsgOperatorInstalling, req.Name)
+		started := time.Now()
+		err := c.installOperator(ctx, req)
+		c.recordPhase(kubernetes.OperatorStep(req.Name), started, err)
+		if err != nil {
+			c.logError(MsgOperatorInstallFailed, req.Name)
+			return err
+		}
+		c.logInfo(MsgOperatorInstalled, req.Name)
+		c.completeStep(ctx, kubernetes.OperatorStep(req.Name))
+	}
+	return nil
+}
+
+// installOperator installs an operator and collects warnings of the installation.
+func (c *CLI) installOperator(ctx context.Context, params kubernetes.InstallOperatorRequest) error {
+	warnings, err := c.kubeClient.InstallOperator(ctx, params)
+	c.warnings.Merge(warnings)
+	return err
+}
+
+// Warnings returns non-fatal issues found during provisioning.
+func (c *CLI) Warnings() kubernetes.Warnings {
+	return c.warnings
+}
+
+// printWarnings prints collected warnings in a dedicated section.
+func (c *CLI) printWarnings() {
+	if len(c.warnings) == 0 {
+		return
+	}
+	fmt.Println(Message(MsgWarningsHeader))
+	for _, w := range c.warnings {
+		fmt.Printf("  - %s\n", w)
+	}
+}
+
+// checkLeftovers looks for resources left by previous installations
+// and removes them if it was requested.
+func (c *CLI) checkLeftovers(ctx context.Context) error {
+	c.logInfo(MsgLeftoversChecking)
+	leftovers, err := c.kubeClient.FindLeftovers(ctx, namespace, operatorGroup)
+	if err != nil {
+		c.logError(MsgLeftoversCheckFailed)
+		return err
+	}
+	if len(leftovers) == 0 {
+		return nil
+	}
+	for _, leftover := range leftovers {
+		c.logWarn(MsgLeftoverFound, leftover)
+	}
+	if !c.config.CleanupLeftovers {
+		return newError(MsgLeftoversRemain, nil, len(leftovers))
+	}
+	c.logInfo(MsgLeftoversRemoving)
+	if err := c.kubeClient.CleanupLeftovers(ctx, leftovers); err != nil {
+		c.logError(MsgLeftoversRemoveFailed)
+		return err
+	}
+	c.logInfo(MsgLeftoversRemoved)
+	return nil
+}
+
+// provisionPMMMonitoring creates a PMM API key unless a credentials secret or an API key
+// is configured and a VM agent writing metrics with it, reconciles alerting and verifies
+// the metrics reach PMM. A created key is rotated after the rotation period if it is set.
+func (c *CLI) provisionPMMMonitoring(ctx context.Context, rotation time.Duration) error {
+	certs, err := loadMonitoringTLS(c.config.Monitoring.PMM.TLS)
+	if err != nil {
+		return err
+	}
+	creds := kubernetes.MonitoringCredentials{
+		Secret:         c.config.Monitoring.PMM.CredentialsSecret,
+		RotationPeriod: rotation,
+	}
+	switch {
+	case creds.Secret != "":
+		c.logInfo(MsgPMMCredentialsSecret, creds.Secret)
+	case c.config.Monitoring.PMM.APIKey != "":
+		// PMM accepts API keys and service account tokens as the password of the api_key user.
+		c.logInfo(MsgPMMAPIKeyProvided)
+		creds.Username, creds.Password = "api_key", c.config.Monitoring.PMM.APIKey
+		creds.Provided = true
+	default:
+		account := fmt.Sprintf("dbaas-service-account-%d", rand.Int63())
+		c.logInfo(MsgPMMAccountCreating)
+		token, err := c.provisionPMM(account, certs)
+		if err != nil {
+			return err
+		}
+		c.logInfo(MsgPMMTokenGenerated)
+		creds.Username, creds.Password = account, token
+	}
+	c.logInfo(MsgMonitoringProvisioning)
+	warnings, err := c.kubeClient.ProvisionMonitoring(ctx, creds, c.config.Monitoring.PMM.Endpoint, c.config.Monitoring.Selective, certs)
+	c.warnings.Merge(warnings)
+	if err != nil {
+		c.logError(MsgMonitoringProvisionFailed)
+		return err
+	}
+	if err := c.reconcileAlerting(ctx); err != nil {
+		return err
+	}
+	return c.VerifyMonitoring(ctx, monitoringVerifyTimeout)
+}
+func (c *CLI) provisionPMM(account string, certs kubernetes.MonitoringTLS) (string, error) {
+	token, err := c.createAdminToken(account, "", certs)
 	return token, err
 }
-func (c *CLI) ConnectDBaaS() error {
-	c.l.Info("Generating service account and connecting with DBaaS")
-	data, err := ioutil.ReadFile("/Users/gen1us2k/.kube/config")
+func (c *CLI) ConnectDBaaS(ctx context.Context) error {
+	c.logInfo(MsgDBaaSConnecting)
+	data, err := c.kubeClient.Kubeconfig()
 	if err != nil {
-		c.l.Error("failed generating kubeconfig")
+		c.logError(MsgKubeconfigFailed)
 		return err
 	}
 	enc := base64.StdEncoding.EncodeToString(data)
@@ -165,62 +816,42 @@ func (c *CLI) ConnectDBaaS() error {
 	}
 	b, err := json.Marshal(payload)
 	if err != nil {
-		c.l.Error("failed marshaling JSON")
+		c.logError(MsgJSONMarshalFailed)
 		return err
 	}
-	req, err := http.NewRequest(http.MethodPost, "http://localhost:8080/k8s", bytes.NewReader(b))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://localhost:8080/k8s", bytes.NewReader(b))
 	if err != nil {
 		return err
 	}
 	//req.Header.Set("Content-Type", "application/json; charset=utf-8")
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.http.Client(nil).Do(req)
 	if err != nil {
 		return err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return errors.New("non 200 status code")
+		return newError(MsgDBaaSBadStatus, nil)
 	}
-	c.l.Info("DBaaS has been connected")
+	c.logInfo(MsgDBaaSConnected)
 	return nil
 
 }
-func (c *CLI) createAdminToken(name string, token string) (string, error) {
-	apiKey := map[string]string{
-		"name": name,
-		"role": "Admin",
-	}
-	b, err := json.Marshal(apiKey)
+
+// createAdminToken creates a Grafana service account with the Admin role and returns a
+// token of it. A legacy API key is created instead if PMM does not support service accounts.
+// Requests are authenticated with the bearer token if it is set or the admin credentials.
+func (c *CLI) createAdminToken(name string, token string, certs kubernetes.MonitoringTLS) (string, error) {
+	client, err := c.monitoringHTTPClient(certs)
 	if err != nil {
 		return "", err
 	}
-	fmt.Println(string(b))
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/graph/api/auth/keys", c.config.Monitoring.PMM.Endpoint), bytes.NewReader(b))
-	if err != nil {
-		return "", err
+	key, err := c.createServiceAccountToken(client, name, token)
+	if errors.Is(err, errServiceAccountsUnsupported) {
+		c.logInfo(MsgPMMServiceAccountsMissing)
+		key, err = c.createAPIKey(client, name, token)
 	}
-	req.Header.Set("Content-Type", "application/json; charset=utf-8")
-	if token == "" {
-		req.SetBasicAuth(c.config.Monitoring.PMM.Username, c.config.Monitoring.PMM.Password)
-	} else {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	fmt.Println(resp.StatusCode)
-	defer resp.Body.Close()
-	data, err := io.ReadAll(resp.Body)
-	fmt.Println(string(data))
 	if err != nil {
 		return "", err
 	}
-	var m map[string]interface{}
-	if err := json.Unmarshal(data, &m); err != nil {
-		return "", err
-	}
-	return m["key"].(string), nil
-
+	logger.Redact(key)
+	return key, nil
 }
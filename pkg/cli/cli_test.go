@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/fake"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOperatorInstallRequests(t *testing.T) {
+	for _, env := range []string{"DBAAS_VM_OP_CHANNEL", "DBAAS_PSMDB_OP_CHANNEL", "DBAAS_DBAAS_OP_CHANNEL"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("DBAAS_PXC_OP_CHANNEL", "fast-v1")
+
+	reqs := operatorInstallRequests("catalog", "olm", map[string]string{"dbaas-operator": "fast-v0"})
+	require.Len(t, reqs, len(operators))
+	channels := make(map[string]string, len(reqs))
+	for _, req := range reqs {
+		channels[req.Name] = req.Channel
+	}
+	// Every operator is installed under its own name, the PXC operator used to be
+	// installed with the name of the VictoriaMetrics operator.
+	assert.Equal(t, map[string]string{
+		"victoriametrics-operator":        "stable-v0",
+		"percona-xtradb-cluster-operator": "fast-v1",
+		"percona-server-mongodb-operator": "stable-v1",
+		"dbaas-operator":                  "fast-v0",
+	}, channels)
+}
+
+func TestProvisionCluster(t *testing.T) {
+	for _, env := range []string{"DBAAS_VM_OP_CHANNEL", "DBAAS_PXC_OP_CHANNEL", "DBAAS_PSMDB_OP_CHANNEL", "DBAAS_DBAAS_OP_CHANNEL"} {
+		t.Setenv(env, "")
+	}
+	ctx := context.Background()
+	const image = "percona/dbaas-catalog@sha256:0123456789abcdef"
+	f, err := fake.NewKubeClient(namespace, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: catalogSourceNamespace,
+			Name:      catalogSource + "-registry",
+			Labels:    map[string]string{"olm.catalogSource": catalogSource},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{ImageID: "docker-pullable://" + image}},
+		},
+	})
+	require.NoError(t, err)
+	f.ResolveSubscriptions = true
+	f.SkipWaits = true
+
+	c := &CLI{kubeClient: kubernetes.NewWithClient(f), l: logrus.WithField("component", "cli")}
+	c.useConfig(&config.AppConfig{SkipPreflight: true, ParallelInstall: true})
+	require.NoError(t, c.ProvisionCluster(ctx))
+
+	for _, name := range operators {
+		sub, err := f.GetSubscription(ctx, namespace, name)
+		require.NoError(t, err)
+		assert.Equal(t, name+"."+operatorChannels[name].channel, sub.Status.InstalledCSV)
+	}
+	progress, err := c.kubeClient.GetProvisionProgress(ctx, namespace)
+	require.NoError(t, err)
+	for _, name := range operators {
+		assert.True(t, progress.Done(kubernetes.OperatorStep(name)), name)
+	}
+	snapshot, err := c.kubeClient.GetInstallSnapshot(ctx, namespace)
+	require.NoError(t, err)
+	assert.Equal(t, image, snapshot.Catalog.Image)
+	op, ok := snapshot.Operator("dbaas-operator")
+	require.True(t, ok)
+	assert.Equal(t, "dbaas-operator.stable-v0", op.CSV)
+
+	// The completed steps are skipped when provisioning is re-run.
+	c.progress = nil
+	require.NoError(t, c.ProvisionCluster(ctx))
+}
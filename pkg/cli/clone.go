@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// CloneDatabaseCluster creates the target database cluster with the spec of the source cluster
+// and restores the latest succeeded backup of the source into it. The size and the resources
+// of the options override the ones of the source.
+func (c *CLI) CloneDatabaseCluster(ctx context.Context, source, target string, opts DatabaseScaleOptions, waitOpts WaitOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db clone")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if source == target {
+		return newError(MsgCloneSameName, nil, source)
+	}
+	if opts.Size < 0 {
+		return newError(MsgScaleInvalidSize, nil, opts.Size)
+	}
+	res, err := opts.resources()
+	if err != nil {
+		return err
+	}
+
+	c.logInfo(MsgDatabaseCloning, source, target)
+	_, restore, err := c.kubeClient.CloneDatabaseCluster(ctx, kubernetes.CloneOptions{
+		Source:    source,
+		Target:    target,
+		Replicas:  opts.Size,
+		Resources: res,
+	})
+	if err != nil {
+		c.logError(MsgCloneFailed, source, target)
+		return err
+	}
+	c.logInfo(MsgRestoreCreated, target, restore.Spec.BackupName, restore.Name, restore.Namespace)
+	if !waitOpts.Wait {
+		c.printCheckStatusHint(target)
+		return nil
+	}
+	c.logInfo(MsgDatabaseWaitingReady, target)
+	if err := c.waitForDatabaseClusterReady(ctx, target, waitOpts); err != nil {
+		return newError(MsgDatabaseNotReady, err, target)
+	}
+	c.logInfo(MsgDatabaseCloned, target, restore.Spec.BackupName, source)
+	return nil
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// ErrForceRequired is returned when an unsafe destructive operation was not forced.
+var ErrForceRequired error = &Error{ID: MsgForceRequired}
+
+// ConfirmOptions controls confirmation of destructive operations.
+type ConfirmOptions struct {
+	// Yes skips the confirmation prompt.
+	Yes bool
+	// Force allows operations the preview found unsafe.
+	Force bool
+}
+
+// confirmDeletion prints resources the plan is going to delete and asks the user
+// to confirm. It returns false if the user declined the deletion.
+func confirmDeletion(plan *kubernetes.DeletionPlan, opts ConfirmOptions) (bool, error) {
+	if len(plan.Items) == 0 {
+		fmt.Println(Message(MsgDeletionNothing))
+		return false, nil
+	}
+	fmt.Println(Message(MsgDeletionHeader))
+	for _, item := range plan.Items {
+		fmt.Printf("  - %s\n", item)
+	}
+	if len(plan.Blockers) != 0 {
+		fmt.Println(Message(MsgDeletionWarningsHeader))
+		for _, b := range plan.Blockers {
+			fmt.Printf("  - %s\n", b)
+		}
+		if !opts.Force {
+			return false, ErrForceRequired
+		}
+	}
+	if opts.Yes {
+		return true, nil
+	}
+	return confirm(Message(MsgDeletionConfirm))
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"github.com/gen1us2k/everest-provisioner/pkg/metrics"
+)
+
+// ControllerOptions holds parameters of the installation controller.
+type ControllerOptions struct {
+	// Interval is how often the cluster is reconciled to the EverestInstallation.
+	Interval time.Duration
+	// MetricsAddress is the address Prometheus metrics are served on. Metrics are not served if it is empty.
+	MetricsAddress string
+}
+
+// RunController installs the EverestInstallation CRD and reconciles the cluster to the
+// oldest EverestInstallation until the context is canceled. Components removed from
+// the cluster are installed again on the next reconciliation.
+func (c *CLI) RunController(ctx context.Context, opts ControllerOptions) error {
+	if opts.Interval <= 0 {
+		return newError(MsgControllerInvalidInterval, nil, opts.Interval)
+	}
+	c.logInfo(MsgControllerStarting, namespace, opts.Interval)
+	if opts.MetricsAddress != "" {
+		c.kubeClient.EnableMetrics()
+		go c.serveMetrics(ctx, opts.MetricsAddress)
+	}
+	if err := c.kubeClient.InstallEverestInstallationCRD(ctx); err != nil {
+		c.logError(MsgControllerCRDFailed)
+		return err
+	}
+	if err := c.detectOpenShift(ctx); err != nil {
+		return err
+	}
+	base := c.config
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	for {
+		c.reconcileInstallation(ctx, base)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			c.logInfo(MsgControllerStopped)
+			return nil
+		}
+	}
+}
+
+// reconcileInstallation reconciles the cluster to the oldest EverestInstallation. The result
+// is recorded in its status and errors are retried on the next tick.
+func (c *CLI) reconcileInstallation(ctx context.Context, base *config.AppConfig) {
+	installations, err := c.kubeClient.ListEverestInstallations(ctx, namespace)
+	if err != nil {
+		c.logWarn(MsgControllerListFailed, err)
+		return
+	}
+	if len(installations) == 0 {
+		return
+	}
+	inst := installations[0]
+	for _, ignored := range installations[1:] {
+		c.updateInstallationStatus(ctx, ignored, kubernetes.InstallationPhaseFailed, Message(MsgControllerDuplicate, inst.Name))
+	}
+
+	cfg := installationConfig(base, inst)
+	if err := cfg.Validate(); err != nil {
+		c.logWarn(MsgControllerReconcileFailed, inst.Name, err)
+		c.updateInstallationStatus(ctx, inst, kubernetes.InstallationPhaseFailed, err.Error())
+		return
+	}
+	c.useConfig(cfg)
+	// A command run against the cluster holds the lock; the cluster is reconciled on the next tick.
+	ctx, unlock, err := c.acquireLock(ctx, "controller")
+	if err != nil {
+		if id, _ := MessageIDOf(err); id == MsgLockHeld {
+			c.logInfo(MsgControllerLocked, err)
+			return
+		}
+		c.logWarn(MsgControllerReconcileFailed, inst.Name, err)
+		return
+	}
+	defer unlock()
+	if inst.Status.ObservedGeneration != inst.Generation {
+		c.logInfo(MsgControllerReconciling, inst.Name)
+		c.updateInstallationStatus(ctx, inst, kubernetes.InstallationPhaseReconciling, "")
+	}
+	err = c.reconcileCluster(ctx)
+	metrics.ObserveProvisioning(err)
+	if err != nil {
+		c.logWarn(MsgControllerReconcileFailed, inst.Name, err)
+		c.updateInstallationStatus(ctx, inst, kubernetes.InstallationPhaseFailed, err.Error())
+		return
+	}
+	if inst.Status.Phase != kubernetes.InstallationPhaseReady || inst.Status.ObservedGeneration != inst.Generation {
+		c.logInfo(MsgControllerReconciled, inst.Name)
+	}
+	c.updateInstallationStatus(ctx, inst, kubernetes.InstallationPhaseReady, "")
+}
+
+// serveMetrics serves Prometheus metrics until the context is done. The controller keeps
+// running if the metrics cannot be served.
+func (c *CLI) serveMetrics(ctx context.Context, addr string) {
+	c.logInfo(MsgControllerMetrics, addr)
+	if err := metrics.ListenAndServe(ctx, addr); err != nil {
+		c.logError(MsgControllerMetricsFailed, err)
+	}
+}
+
+// reconcileCluster installs the missing components. Unlike ProvisionCluster it does not
+// skip the steps recorded by previous runs so removed components are installed again.
+func (c *CLI) reconcileCluster(ctx context.Context) error {
+	c.progress = nil
+	if err := c.installOLM(ctx); err != nil {
+		return err
+	}
+	if err := c.createImagePullSecrets(ctx); err != nil {
+		return err
+	}
+	if err := c.installOperators(ctx); err != nil {
+		return err
+	}
+	if c.config.Monitoring.Enabled {
+		return c.provisionMonitoring(ctx)
+	}
+	return nil
+}
+
+// installationConfig overlays the settings of the installation on the configuration the
+// controller was started with. Monitoring is provisioned again if the spec has changed.
+func installationConfig(base *config.AppConfig, inst kubernetes.EverestInstallation) *config.AppConfig {
+	cfg := *base
+	spec := inst.Spec
+	cfg.Catalog = config.CatalogConfig{Name: spec.Catalog.Name, Image: spec.Catalog.Image, Namespace: spec.Catalog.Namespace}
+	cfg.OperatorChannels = spec.OperatorChannels
+	cfg.EnableBackup = spec.Backup.Enabled
+	cfg.Force = inst.Status.ObservedGeneration != inst.Generation
+	pmm := config.PMMConfig{}
+	if base.Monitoring.PMM != nil {
+		pmm = *base.Monitoring.PMM
+	}
+	pmm.Endpoint = spec.Monitoring.PMM.Endpoint
+	pmm.TLS.InsecureSkipVerify = spec.Monitoring.PMM.InsecureSkipVerify
+	if spec.Monitoring.PMM.CredentialsSecret != "" {
+		pmm.CredentialsSecret = spec.Monitoring.PMM.CredentialsSecret
+	}
+	cfg.Monitoring = config.MonitoringConfig{
+		Enabled:   spec.Monitoring.Enabled,
+		Type:      config.MonitoringTypePMM,
+		Selective: spec.Monitoring.Selective,
+		PMM:       &pmm,
+	}
+	return &cfg
+}
+
+func (c *CLI) updateInstallationStatus(ctx context.Context, inst kubernetes.EverestInstallation, phase, message string) {
+	if err := c.kubeClient.UpdateEverestInstallationStatus(ctx, inst, phase, message); err != nil {
+		c.logWarn(MsgControllerStatusFailed, inst.Name, err)
+	}
+}
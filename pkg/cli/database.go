@@ -0,0 +1,704 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	enginePXC   dbaasv1.EngineType = "pxc"
+	enginePSMDB dbaasv1.EngineType = "psmdb"
+
+	databaseClusterKind       = "DatabaseCluster"
+	databaseClusterAPIVersion = "dbaas.percona.com/v1"
+
+	// exposeExternal exposes a database cluster with a load balancer of the cloud provider.
+	exposeExternal = "external"
+
+	// restartGracePeriod is how long a restarted cluster may report ready
+	// before the operator picks up the restart.
+	restartGracePeriod = time.Minute
+)
+
+var (
+	defaultEngineImages = map[dbaasv1.EngineType]string{
+		enginePXC:   "percona/percona-xtradb-cluster:8.0.27-18.1",
+		enginePSMDB: "percona/percona-server-mongodb:5.0.14-12",
+	}
+	defaultLoadBalancers = map[dbaasv1.EngineType]dbaasv1.LoadBalancerType{
+		enginePXC:   "haproxy",
+		enginePSMDB: "mongos",
+	}
+)
+
+// DatabaseClusterOptions holds parameters of a new database cluster.
+type DatabaseClusterOptions struct {
+	// File is a path to a DatabaseCluster manifest. Other options are ignored if it is set.
+	File   string
+	Name   string
+	Engine string
+	Size   int32
+	CPU    string
+	Memory string
+	Disk   string
+	// Version is the database version. The version of the default image is used if it is empty.
+	Version string
+	// Expose is either empty to keep the cluster reachable only from Kubernetes or external
+	// to expose it with a load balancer of the cloud provider.
+	Expose string
+	// Internal makes the load balancer of an exposed cluster reachable only from the network of the cluster.
+	Internal bool
+	// StorageClass provisions the volumes. The default storage class is used if it is empty.
+	StorageClass string
+	// TTL makes the cluster ephemeral. It is deleted once the TTL passes.
+	TTL time.Duration
+	// Spread is the failure domain the pods are spread over: auto, zone, hostname or none.
+	// The placement.spread setting is used if it is empty.
+	Spread string
+	// SpreadRequired refuses scheduling two pods into the same failure domain.
+	SpreadRequired bool
+}
+
+// CreateDatabaseCluster creates a new database cluster.
+func (c *CLI) CreateDatabaseCluster(ctx context.Context, opts DatabaseClusterOptions, waitOpts WaitOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db create")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cluster, err := buildDatabaseCluster(opts)
+	if err != nil {
+		return err
+	}
+	if opts.File == "" && opts.Version != "" {
+		image, err := c.engineImage(ctx, cluster.Spec.Database, opts.Version)
+		if err != nil {
+			return err
+		}
+		cluster.Spec.DatabaseImage = image
+	}
+	if err := c.applyStorageDefaults(ctx, cluster); err != nil {
+		return err
+	}
+	if err := c.exposeDatabaseCluster(ctx, cluster, opts); err != nil {
+		return err
+	}
+	placement, err := c.databasePlacement(ctx, cluster, opts)
+	if err != nil {
+		return err
+	}
+	if opts.TTL > 0 {
+		expiresAt := time.Now().Add(opts.TTL)
+		kubernetes.SetExpiry(cluster, expiresAt)
+		c.logInfo(MsgDatabaseExpires, cluster.Name, expiresAt.Format(time.RFC3339))
+	}
+	if err := c.requestCertificates(ctx, cluster); err != nil {
+		return err
+	}
+	c.logInfo(MsgDatabaseCreating, cluster.Name)
+	if err := c.kubeClient.CreateDatabaseCluster(ctx, cluster); err != nil {
+		c.logError(MsgDatabaseCreateFailed, cluster.Name)
+		return err
+	}
+	if placement != nil {
+		placementCtx, cancel := context.WithTimeout(ctx, waitOpts.timeout())
+		err := c.kubeClient.SetDatabaseClusterPlacement(placementCtx, cluster.Name, *placement)
+		cancel()
+		if err != nil {
+			return newError(MsgPlacementApplyFailed, err, cluster.Name)
+		}
+	}
+	if !waitOpts.Wait {
+		c.printCheckStatusHint(cluster.Name)
+		return nil
+	}
+	c.logInfo(MsgDatabaseWaitingReady, cluster.Name)
+	if err := c.waitForDatabaseClusterReady(ctx, cluster.Name, waitOpts); err != nil {
+		return newError(MsgDatabaseNotReady, err, cluster.Name)
+	}
+	c.logInfo(MsgDatabaseReady, cluster.Name)
+	return nil
+}
+
+// exposeDatabaseCluster exposes the cluster with a load balancer annotated for the cloud provider
+// if the options request external access.
+func (c *CLI) exposeDatabaseCluster(ctx context.Context, cluster *dbaasv1.DatabaseCluster, opts DatabaseClusterOptions) error {
+	switch opts.Expose {
+	case "":
+		if opts.Internal {
+			return newError(MsgInternalWithoutExpose, nil)
+		}
+		return nil
+	case exposeExternal:
+	default:
+		return newError(MsgExposeUnknown, nil, opts.Expose, exposeExternal)
+	}
+	clusterType, err := c.kubeClient.GetClusterType(ctx)
+	if err != nil {
+		c.logError(MsgClusterTypeFailed)
+		return err
+	}
+	annotations, err := kubernetes.LoadBalancerAnnotations(clusterType, opts.Internal)
+	if err != nil {
+		return newError(MsgExposeFailed, err, cluster.Name)
+	}
+	lb := &cluster.Spec.LoadBalancer
+	lb.ExposeType = corev1.ServiceTypeLoadBalancer
+	if lb.Annotations == nil {
+		lb.Annotations = map[string]string{}
+	}
+	for k, v := range annotations {
+		lb.Annotations[k] = v
+	}
+	if !opts.Internal {
+		c.logWarn(MsgExposedToInternet, cluster.Name)
+	}
+	return nil
+}
+
+// requestCertificates requests the TLS certificates of the cluster from cert-manager
+// if the cluster was provisioned with it. Otherwise the engine operator generates them.
+func (c *CLI) requestCertificates(ctx context.Context, cluster *dbaasv1.DatabaseCluster) error {
+	exists, err := c.kubeClient.CertificateIssuerExists(ctx, namespace)
+	if err != nil {
+		c.logError(MsgCertificatesFailed, cluster.Name)
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	if err := c.kubeClient.CreateDatabaseClusterCertificates(ctx, cluster); err != nil {
+		c.logError(MsgCertificatesFailed, cluster.Name)
+		return err
+	}
+	c.logInfo(MsgCertificatesRequested, cluster.Name, kubernetes.CertManagerIssuer)
+	return nil
+}
+
+// DatabaseRestartOptions holds parameters of the database cluster restart.
+type DatabaseRestartOptions struct {
+	// Strategy is one of kubernetes.RestartStrategies. The full restart is used if it is empty.
+	Strategy string
+}
+
+// RestartDatabaseCluster restarts a database cluster. The rolling restart always waits
+// for the pods to be restarted and stops if the cluster becomes unhealthy.
+func (c *CLI) RestartDatabaseCluster(ctx context.Context, name string, opts DatabaseRestartOptions, waitOpts WaitOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db restart")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	switch kubernetes.RestartStrategy(opts.Strategy) {
+	case "", kubernetes.RestartStrategyFull:
+	case kubernetes.RestartStrategyRolling:
+		return c.rollingRestartDatabaseCluster(ctx, name, waitOpts)
+	default:
+		strategies := make([]string, 0, len(kubernetes.RestartStrategies))
+		for _, s := range kubernetes.RestartStrategies {
+			strategies = append(strategies, string(s))
+		}
+		return newError(MsgRestartStrategyUnknown, nil, opts.Strategy, strings.Join(strategies, ", "))
+	}
+	c.logInfo(MsgDatabaseRestarting, name)
+	if err := c.kubeClient.RestartDatabaseCluster(ctx, name); err != nil {
+		c.logError(MsgDatabaseRestartFailed, name)
+		return err
+	}
+	if !waitOpts.Wait {
+		c.printCheckStatusHint(name)
+		return nil
+	}
+	c.logInfo(MsgDatabaseWaitingRestart, name)
+	if err := c.waitForDatabaseClusterRestart(ctx, name, waitOpts); err != nil {
+		return newError(MsgDatabaseNotRestarted, err, name)
+	}
+	c.logInfo(MsgDatabaseRestarted, name)
+	return nil
+}
+
+func (c *CLI) rollingRestartDatabaseCluster(ctx context.Context, name string, waitOpts WaitOptions) error {
+	ctx, cancel := context.WithTimeout(ctx, waitOpts.timeout())
+	defer cancel()
+	c.logInfo(MsgDatabaseRestarting, name)
+	err := c.kubeClient.RollingRestartDatabaseCluster(ctx, name, func(p kubernetes.RestartProgress) {
+		if p.Pod != "" {
+			c.logInfo(MsgDatabaseRestartingPod, p.Pod, p.Restarted+1, p.Total)
+		}
+	})
+	if err != nil {
+		if errors.Is(err, kubernetes.ErrRestartAborted) {
+			c.logError(MsgDatabaseRestartAborted, name)
+		}
+		return newError(MsgDatabaseNotRestarted, err, name)
+	}
+	c.logInfo(MsgDatabaseRestarted, name)
+	return nil
+}
+
+// PauseDatabaseCluster stops the pods of a database cluster keeping its data.
+// It waits for the cluster to report the paused state if requested.
+func (c *CLI) PauseDatabaseCluster(ctx context.Context, name string, waitOpts WaitOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db pause")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c.logInfo(MsgDatabasePausing, name)
+	if err := c.kubeClient.PauseDatabaseCluster(ctx, name); err != nil {
+		c.logError(MsgDatabasePauseFailed, name)
+		return err
+	}
+	if !waitOpts.Wait {
+		c.printCheckStatusHint(name)
+		return nil
+	}
+	c.logInfo(MsgDatabaseWaitingPause, name)
+	ctx, cancel := context.WithTimeout(ctx, waitOpts.timeout())
+	defer cancel()
+	_, err = c.kubeClient.WaitForDatabaseCluster(ctx, name, printStateTransitions(
+		func(cluster *dbaasv1.DatabaseCluster) (bool, error) {
+			return cluster.Status.State == kubernetes.DatabaseClusterStatePaused, nil
+		}))
+	if err != nil {
+		return newError(MsgDatabaseNotPaused, err, name)
+	}
+	c.logInfo(MsgDatabasePaused, name)
+	return nil
+}
+
+// ResumeDatabaseCluster starts the pods of a paused database cluster again.
+// It waits for the cluster to be ready if requested.
+func (c *CLI) ResumeDatabaseCluster(ctx context.Context, name string, waitOpts WaitOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db resume")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c.logInfo(MsgDatabaseResuming, name)
+	if err := c.kubeClient.ResumeDatabaseCluster(ctx, name); err != nil {
+		c.logError(MsgDatabaseResumeFailed, name)
+		return err
+	}
+	if !waitOpts.Wait {
+		c.printCheckStatusHint(name)
+		return nil
+	}
+	c.logInfo(MsgDatabaseWaitingReady, name)
+	if err := c.waitForDatabaseClusterReady(ctx, name, waitOpts); err != nil {
+		return newError(MsgDatabaseNotResumed, err, name)
+	}
+	c.logInfo(MsgDatabaseResumed, name)
+	return nil
+}
+
+// ReleaseDatabaseCluster removes the managed-by markers from a database cluster
+// to hand it over to another tool without deleting it.
+func (c *CLI) ReleaseDatabaseCluster(ctx context.Context, name string) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db release")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	released, err := c.kubeClient.ReleaseDatabaseCluster(ctx, name)
+	if err != nil {
+		c.logError(MsgDatabaseReleaseFailed, name)
+		return err
+	}
+	if !released {
+		c.logWarn(MsgDatabaseNotManaged, name)
+		return nil
+	}
+	c.logInfo(MsgDatabaseReleased, name)
+	return nil
+}
+
+// SetDatabaseClusterMonitoring enables or disables monitoring of a database cluster
+// in the selective monitoring mode. If instance is set, the cluster reports to the monitoring instance.
+func (c *CLI) SetDatabaseClusterMonitoring(ctx context.Context, name string, enabled bool, instance string) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db monitoring")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !enabled {
+		if err := c.kubeClient.DisableDatabaseClusterMonitoring(ctx, name); err != nil {
+			c.logError(MsgMonitoringDisableFailed, name)
+			return err
+		}
+		c.logInfo(MsgMonitoringDisabled, name)
+		return nil
+	}
+	if err := c.kubeClient.EnableDatabaseClusterMonitoring(ctx, name, instance); err != nil {
+		c.logError(MsgMonitoringEnableFailed, name)
+		return err
+	}
+	if instance != "" {
+		c.logInfo(MsgMonitoringInstanceAssigned, name, instance)
+		return nil
+	}
+	c.logInfo(MsgMonitoringEnabled, name)
+	return nil
+}
+
+// DeleteExpiredDatabaseClusters deletes the ephemeral database clusters whose TTL has passed.
+func (c *CLI) DeleteExpiredDatabaseClusters(ctx context.Context) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db expire")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	deleted, err := c.kubeClient.DeleteExpiredDatabaseClusters(ctx, time.Now())
+	for _, name := range deleted {
+		c.logInfo(MsgDatabaseExpired, name)
+	}
+	if err != nil {
+		c.logError(MsgDatabaseExpireFailed)
+		return err
+	}
+	return nil
+}
+
+func (c *CLI) printCheckStatusHint(name string) {
+	fmt.Println(Message(MsgDatabaseCheckLater, name, namespace))
+}
+
+// waitForDatabaseClusterReady waits for the cluster to report the ready state.
+func (c *CLI) waitForDatabaseClusterReady(ctx context.Context, name string, opts WaitOptions) error {
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout())
+	defer cancel()
+	_, err := c.kubeClient.WaitForDatabaseCluster(ctx, name, printStateTransitions(kubernetes.DatabaseClusterReady))
+	return err
+}
+
+// waitForDatabaseClusterRestart waits for the cluster to leave the ready
+// state and to become ready again.
+func (c *CLI) waitForDatabaseClusterRestart(ctx context.Context, name string, opts WaitOptions) error {
+	graceCtx, cancel := context.WithTimeout(ctx, restartGracePeriod)
+	_, err := c.kubeClient.WaitForDatabaseCluster(graceCtx, name, printStateTransitions(
+		func(cluster *dbaasv1.DatabaseCluster) (bool, error) {
+			return cluster.Status.State != kubernetes.DatabaseClusterStateReady, nil
+		}))
+	graceExpired := graceCtx.Err() != nil && ctx.Err() == nil
+	cancel()
+	if err != nil && !graceExpired {
+		return err
+	}
+	return c.waitForDatabaseClusterReady(ctx, name, opts)
+}
+
+// printStateTransitions wraps the condition to print every change of the cluster state.
+func printStateTransitions(condition kubernetes.DatabaseClusterCondition) kubernetes.DatabaseClusterCondition {
+	var last dbaasv1.AppState
+	var seen bool
+	return func(cluster *dbaasv1.DatabaseCluster) (bool, error) {
+		state := cluster.Status.State
+		if !seen || state != last {
+			msg := fmt.Sprintf("%s: %s (%d/%d ready)", cluster.Name, state, cluster.Status.Ready, cluster.Status.Size)
+			if cluster.Status.Message != "" {
+				msg += ": " + cluster.Status.Message
+			}
+			fmt.Println(msg)
+			last, seen = state, true
+		}
+		return condition(cluster)
+	}
+}
+
+// applyStorageDefaults validates the disk size against the volume size limit of the
+// cloud provider and the storage class of the cluster. It sets the default storage class
+// if the cluster has none.
+func (c *CLI) applyStorageDefaults(ctx context.Context, cluster *dbaasv1.DatabaseCluster) error {
+	if err := c.checkDiskSize(ctx, cluster.Spec.DBInstance.DiskSize); err != nil {
+		return err
+	}
+	if cluster.Spec.DBInstance.StorageClassName != nil {
+		if err := c.kubeClient.ValidateStorageClass(ctx, *cluster.Spec.DBInstance.StorageClassName); err != nil {
+			return newError(MsgStorageClassInvalid, err)
+		}
+		return nil
+	}
+	storageClass, err := c.kubeClient.GetDefaultStorageClassName(ctx)
+	if err != nil {
+		return newError(MsgStorageClassFailed, err)
+	}
+	cluster.Spec.DBInstance.StorageClassName = &storageClass
+	return nil
+}
+
+// checkDiskSize validates the disk size against the volume size limit of the cloud provider.
+func (c *CLI) checkDiskSize(ctx context.Context, disk resource.Quantity) error {
+	clusterType, err := c.kubeClient.GetClusterType(ctx)
+	if err != nil {
+		c.logError(MsgClusterTypeFailed)
+		return err
+	}
+	if limit := kubernetes.MaxVolumeSize(clusterType); limit != 0 && uint64(disk.Value()) > limit {
+		return newError(MsgDiskTooLarge, nil, disk.String(), clusterType, resource.NewQuantity(int64(limit), resource.BinarySI))
+	}
+	return nil
+}
+
+// checkPlacement reports how the members of the cluster can be spread over the worker nodes.
+// The operators place members of a cluster on distinct nodes by default, so members without
+// a node of their own cannot be scheduled.
+func (c *CLI) checkPlacement(ctx context.Context, cluster *dbaasv1.DatabaseCluster) error {
+	size := int(cluster.Spec.ClusterSize)
+	if size <= 1 {
+		return nil
+	}
+	topology, err := c.kubeClient.GetNodeTopology(ctx)
+	if err != nil {
+		c.logError(MsgPlacementFailed, cluster.Name)
+		return err
+	}
+	switch topology.AntiAffinityTopologyKey(size) {
+	case kubernetes.TopologyKeyZone:
+		c.logInfo(MsgPlacementZones, cluster.Name, len(topology.Zones()))
+	case kubernetes.TopologyKeyHostname:
+		c.logInfo(MsgPlacementNodes, cluster.Name)
+	default:
+		c.logWarn(MsgPlacementImpossible, cluster.Name, size, len(topology.Nodes))
+	}
+	return nil
+}
+
+// databasePlacement returns how the pods of the new cluster are spread over failure domains
+// detected from the node labels. It returns nil if the pods are not spread.
+func (c *CLI) databasePlacement(ctx context.Context, cluster *dbaasv1.DatabaseCluster, opts DatabaseClusterOptions) (*kubernetes.Placement, error) {
+	spread := opts.Spread
+	if spread == "" {
+		spread = c.config.Placement.Spread
+	}
+	if spread == "" {
+		spread = config.PlacementSpreadAuto
+	}
+	required := opts.SpreadRequired || c.config.Placement.Required
+	size := int(cluster.Spec.ClusterSize)
+	switch spread {
+	case config.PlacementSpreadNone:
+		if required {
+			return nil, newError(MsgPlacementRequiredNone, nil)
+		}
+		return nil, nil
+	case config.PlacementSpreadAuto, config.PlacementSpreadZone, config.PlacementSpreadHostname:
+	default:
+		return nil, newError(MsgPlacementSpreadUnknown, nil, spread, strings.Join([]string{
+			config.PlacementSpreadAuto, config.PlacementSpreadZone, config.PlacementSpreadHostname, config.PlacementSpreadNone,
+		}, ", "))
+	}
+	if size <= 1 {
+		return nil, nil
+	}
+	topology, err := c.kubeClient.GetNodeTopology(ctx)
+	if err != nil {
+		c.logError(MsgPlacementFailed, cluster.Name)
+		return nil, err
+	}
+	placement := &kubernetes.Placement{Required: required, MaxSkew: c.config.Placement.MaxSkew}
+	switch spread {
+	case config.PlacementSpreadZone:
+		placement.TopologyKey = kubernetes.TopologyKeyZone
+	case config.PlacementSpreadHostname:
+		placement.TopologyKey = kubernetes.TopologyKeyHostname
+	default:
+		placement.TopologyKey = topology.AntiAffinityTopologyKey(size)
+		if placement.TopologyKey == "" {
+			placement.TopologyKey = kubernetes.TopologyKeyHostname
+		}
+	}
+	domains := len(topology.Nodes)
+	if placement.TopologyKey == kubernetes.TopologyKeyZone {
+		domains = len(topology.Zones())
+	}
+	switch {
+	case size > domains && required:
+		return nil, newError(MsgPlacementUnsatisfiable, nil, cluster.Name, size, domains, placement.TopologyKey)
+	case size > domains && placement.TopologyKey == kubernetes.TopologyKeyZone:
+		c.logWarn(MsgPlacementZonesShared, cluster.Name, size, domains)
+	case size > domains:
+		c.logWarn(MsgPlacementNodesShared, cluster.Name, size, domains)
+	case placement.TopologyKey == kubernetes.TopologyKeyZone:
+		c.logInfo(MsgPlacementZones, cluster.Name, domains)
+	default:
+		c.logInfo(MsgPlacementNodes, cluster.Name)
+	}
+	return placement, nil
+}
+
+func buildDatabaseCluster(opts DatabaseClusterOptions) (*dbaasv1.DatabaseCluster, error) {
+	if opts.File != "" {
+		return loadDatabaseCluster(opts.File)
+	}
+	if opts.Name == "" {
+		return nil, newError(MsgDatabaseNameRequired, nil)
+	}
+	engine := dbaasv1.EngineType(opts.Engine)
+	image, ok := defaultEngineImages[engine]
+	if !ok {
+		return nil, newError(MsgUnsupportedEngine, nil, opts.Engine)
+	}
+	cpu, err := resource.ParseQuantity(opts.CPU)
+	if err != nil {
+		return nil, newError(MsgInvalidCPU, err)
+	}
+	memory, err := resource.ParseQuantity(opts.Memory)
+	if err != nil {
+		return nil, newError(MsgInvalidMemory, err)
+	}
+	disk, err := resource.ParseQuantity(opts.Disk)
+	if err != nil {
+		return nil, newError(MsgInvalidDisk, err)
+	}
+	var storageClass *string
+	if opts.StorageClass != "" {
+		storageClass = &opts.StorageClass
+	}
+	return &dbaasv1.DatabaseCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: databaseClusterAPIVersion,
+			Kind:       databaseClusterKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: opts.Name,
+		},
+		Spec: dbaasv1.DatabaseSpec{
+			Database:      engine,
+			DatabaseImage: image,
+			ClusterSize:   opts.Size,
+			DBInstance: dbaasv1.DBInstanceSpec{
+				CPU:              cpu,
+				Memory:           memory,
+				DiskSize:         disk,
+				StorageClassName: storageClass,
+			},
+			LoadBalancer: dbaasv1.LoadBalancerSpec{
+				Type: defaultLoadBalancers[engine],
+				Size: opts.Size,
+			},
+		},
+	}, nil
+}
+
+func loadDatabaseCluster(path string) (*dbaasv1.DatabaseCluster, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cluster := &dbaasv1.DatabaseCluster{}
+	if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), len(data)).Decode(cluster); err != nil {
+		return nil, newError(MsgManifestParseFailed, err, path)
+	}
+	if cluster.APIVersion == "" {
+		cluster.APIVersion = databaseClusterAPIVersion
+	}
+	if cluster.Kind == "" {
+		cluster.Kind = databaseClusterKind
+	}
+	return cluster, nil
+}
+
+// DeleteDatabaseCluster deletes a database cluster together with its volumes and secrets.
+func (c *CLI) DeleteDatabaseCluster(ctx context.Context, name string, opts ConfirmOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db delete")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	plan, err := c.kubeClient.PlanDatabaseClusterDeletion(ctx, name)
+	if err != nil {
+		c.logError(MsgDatabaseDeletePlanFailed, name)
+		return err
+	}
+	ok, err := confirmDeletion(plan, opts)
+	if err != nil || !ok {
+		if err == nil {
+			c.logInfo(MsgDeletionCancelled)
+		}
+		return err
+	}
+	if err := c.kubeClient.ExecuteDeletionPlan(ctx, plan); err != nil {
+		c.logError(MsgDatabaseDeleteFailed, name)
+		return err
+	}
+	c.logInfo(MsgDatabaseDeleted, name)
+	return nil
+}
+
+// DatabaseCredentials prints the connection details of the admin user of the database cluster.
+func (c *CLI) DatabaseCredentials(ctx context.Context, name, output string) error {
+	if output != OutputText && output != OutputJSON {
+		return newError(MsgUnsupportedOutput, nil, output)
+	}
+	creds, err := c.kubeClient.GetDatabaseCredentials(ctx, name)
+	if err != nil {
+		c.logError(MsgDatabaseCredentialsFailed, name)
+		return err
+	}
+	if output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(creds)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Engine:\t%s\n", creds.Engine)
+	fmt.Fprintf(w, "Host:\t%s\n", creds.Host)
+	fmt.Fprintf(w, "Port:\t%d\n", creds.Port)
+	fmt.Fprintf(w, "User:\t%s\n", creds.User)
+	fmt.Fprintf(w, "Password:\t%s\n", creds.Password)
+	fmt.Fprintf(w, "Connection string:\t%s\n", creds.ConnectionString)
+	return w.Flush()
+}
+
+// PingDatabaseCluster checks that the database cluster accepts connections by connecting
+// with the driver of the engine through a forwarded port.
+func (c *CLI) PingDatabaseCluster(ctx context.Context, name string) error {
+	c.logInfo(MsgDatabasePinging, name)
+	ping, err := c.kubeClient.PingDatabaseCluster(ctx, name)
+	if err != nil {
+		return newError(MsgDatabasePingFailed, err, name)
+	}
+	latency := ping.Latency.Round(time.Millisecond)
+	if ping.Version != "" {
+		c.logInfo(MsgDatabasePingedVersion, name, ping.Pod, latency, ping.Version)
+		return nil
+	}
+	c.logInfo(MsgDatabasePinged, name, ping.Pod, latency)
+	return nil
+}
+
+// DatabaseClusterNames returns the names of the database clusters, e.g. for shell completion.
+func (c *CLI) DatabaseClusterNames(ctx context.Context) ([]string, error) {
+	clusters, err := c.kubeClient.ListDatabaseClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(clusters.Items))
+	for _, cluster := range clusters.Items {
+		names = append(names, cluster.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// DiagnoseArchive bundles the diagnostics into a tar.gz archive with the logs and events in separate files.
+const DiagnoseArchive = "tar.gz"
+
+// DiagnoseOptions holds parameters of the diagnose command.
+type DiagnoseOptions struct {
+	// Format is either DiagnoseArchive or OutputJSON.
+	Format string
+	// File is the path the report is written to. The JSON report is printed to stdout
+	// if it is empty, the archive is written to a file named after the collection time.
+	File string
+}
+
+// Diagnose collects the health of the operators and writes the report for attaching to support tickets.
+func (c *CLI) Diagnose(ctx context.Context, opts DiagnoseOptions) error {
+	if opts.Format != DiagnoseArchive && opts.Format != OutputJSON {
+		return newError(MsgUnsupportedOutput, nil, opts.Format)
+	}
+	c.logInfo(MsgDiagnoseCollecting, namespace)
+	d := c.kubeClient.CollectDiagnostics(ctx, namespace)
+	if opts.Format == DiagnoseArchive && opts.File == "" {
+		opts.File = fmt.Sprintf("everest-diagnostics-%s.tar.gz", d.CollectedAt.Format("20060102-150405"))
+	}
+	if err := writeDiagnostics(d, opts); err != nil {
+		return newError(MsgDiagnoseWriteFailed, err, opts.File)
+	}
+	if opts.File != "" {
+		c.logInfo(MsgDiagnoseWritten, opts.File)
+	}
+	if len(d.Errors) != 0 {
+		c.logWarn(MsgDiagnoseIncomplete, len(d.Errors))
+	}
+	if !d.Healthy() {
+		c.logWarn(MsgDiagnoseUnhealthy)
+	}
+	return nil
+}
+
+// writeDiagnostics writes the report to the file of the options or to stdout.
+func writeDiagnostics(d *kubernetes.Diagnostics, opts DiagnoseOptions) error {
+	if opts.File == "" {
+		return encodeDiagnostics(os.Stdout, d, opts.Format)
+	}
+	f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := encodeDiagnostics(f, d, opts.Format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func encodeDiagnostics(w io.Writer, d *kubernetes.Diagnostics, format string) error {
+	if format == DiagnoseArchive {
+		return writeDiagnosticsArchive(w, d)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// writeDiagnosticsArchive writes the report as a tar.gz archive. The logs and events of the pods
+// are stored as plain text files so they can be read without processing the JSON report.
+func writeDiagnosticsArchive(w io.Writer, d *kubernetes.Diagnostics) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	report := *d
+	report.Pods = make([]kubernetes.PodDiagnostics, 0, len(d.Pods))
+	for _, pod := range d.Pods {
+		for container, logs := range pod.Logs {
+			if err := addArchiveFile(tw, d, path.Join("logs", pod.Name, container+".log"), logs); err != nil {
+				return err
+			}
+		}
+		if err := addArchiveFile(tw, d, path.Join("events", pod.Name+".txt"), pod.Events); err != nil {
+			return err
+		}
+		pod.Logs, pod.Events = nil, nil
+		report.Pods = append(report.Pods, pod)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := addArchiveFile(tw, d, "diagnostics.json", []string{string(data)}); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func addArchiveFile(tw *tar.Writer, d *kubernetes.Diagnostics, name string, lines []string) error {
+	data := []byte(strings.Join(lines, "\n"))
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: d.CollectedAt,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"github.com/gen1us2k/everest-provisioner/pkg/httpclient"
+)
+
+// digestTimeout limits the delivery of the digest to the webhook and to the SMTP server.
+const digestTimeout = 30 * time.Second
+
+// SendMaintenanceDigest collects pending operator upgrades, backups failed since the given
+// time, database clusters in error and capacity warnings and delivers them to the webhook
+// and the email recipients of the configuration.
+func (c *CLI) SendMaintenanceDigest(ctx context.Context, since time.Time) error {
+	c.logInfo(MsgDigestSending)
+	digest, err := c.kubeClient.MaintenanceDigest(ctx, kubernetes.DigestOptions{
+		Namespace: namespace,
+		Operators: operators,
+		Since:     since,
+	})
+	if err != nil {
+		c.logError(MsgDigestFailed)
+		return err
+	}
+	cfg := c.config.Digest
+	if cfg.WebhookURL != "" {
+		if err := postDigest(ctx, c.http.Client(nil), cfg.WebhookURL, c.digestCluster(), digest); err != nil {
+			c.logError(MsgDigestFailed)
+			return err
+		}
+	}
+	if len(cfg.Email.To) != 0 {
+		if err := c.mailDigest(ctx, digest); err != nil {
+			c.logError(MsgDigestFailed)
+			return err
+		}
+	}
+	c.logInfo(MsgDigestSent)
+	return nil
+}
+
+// digestConfigured returns true if the digest has a destination.
+func (c *CLI) digestConfigured() bool {
+	return c.config.Digest.WebhookURL != "" || len(c.config.Digest.Email.To) != 0
+}
+
+// sendMaintenanceDigestEvery sends the digest of the past interval periodically until the
+// context is done. A failed digest is retried on the next tick and covers both periods.
+func (c *CLI) sendMaintenanceDigestEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	since := time.Now().Add(-interval)
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		now := time.Now()
+		if err := c.SendMaintenanceDigest(ctx, since); err == nil {
+			since = now
+		}
+	}
+}
+
+// digestCluster names the Kubernetes cluster the digest is about.
+func (c *CLI) digestCluster() string {
+	if c.config.Cluster != "" {
+		return c.config.Cluster
+	}
+	if c.config.KubeContext != "" {
+		return c.config.KubeContext
+	}
+	return "the cluster"
+}
+
+// digestPayload is the JSON body posted to the webhook.
+type digestPayload struct {
+	Cluster string `json:"cluster"`
+	*kubernetes.Digest
+	// Text is the digest formatted for chat systems accepting a text field.
+	Text string `json:"text"`
+}
+
+func postDigest(ctx context.Context, client *http.Client, url, cluster string, digest *kubernetes.Digest) error {
+	body, err := json.Marshal(digestPayload{Cluster: cluster, Digest: digest, Text: formatDigest(cluster, digest)})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, digestTimeout)
+	defer cancel()
+	// A repeated digest would be posted twice to chats, the next tick delivers it instead.
+	req, err := http.NewRequestWithContext(httpclient.WithoutRetries(ctx), http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newError(MsgDigestWebhookBadStatus, nil, url, resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *CLI) mailDigest(ctx context.Context, digest *kubernetes.Digest) error {
+	cfg := c.config.Digest.Email
+	cluster := c.digestCluster()
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", Message(MsgDigestTitle, cluster))
+	fmt.Fprintf(&msg, "Date: %s\r\n", digest.GeneratedAt.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(formatDigest(cluster, digest), "\n", "\r\n"))
+
+	host, _, err := net.SplitHostPort(cfg.SMTPAddress)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+	ctx, cancel := context.WithTimeout(ctx, digestTimeout)
+	defer cancel()
+	return sendMail(ctx, cfg.SMTPAddress, host, auth, cfg.From, cfg.To, []byte(msg.String()))
+}
+
+// sendMail works like smtp.SendMail but dials with the context and bounds the whole
+// conversation with the SMTP server by the deadline of the context.
+func sendMail(ctx context.Context, addr, host string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close() //nolint:errcheck
+			return err
+		}
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close() //nolint:errcheck
+		return err
+	}
+	defer client.Close() //nolint:errcheck
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// formatDigest renders the digest as plain text.
+func formatDigest(cluster string, digest *kubernetes.Digest) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n%s - %s\n", Message(MsgDigestTitle, cluster),
+		digest.Since.Format(time.RFC3339), digest.GeneratedAt.Format(time.RFC3339))
+	if digest.Empty() {
+		fmt.Fprintf(&buf, "\n%s\n", Message(MsgDigestNothing))
+		return buf.String()
+	}
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	if len(digest.OperatorUpgrades) != 0 {
+		fmt.Fprintf(w, "\n%s\n", Message(MsgDigestOperatorUpgrades))
+		fmt.Fprintln(w, "OPERATOR\tINSTALLED\tAVAILABLE\tINSTALL PLAN")
+		for _, u := range digest.OperatorUpgrades {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", u.Name, u.InstalledCSV, u.TargetCSV, u.InstallPlan)
+		}
+	}
+	if len(digest.FailedBackups) != 0 {
+		fmt.Fprintf(w, "\n%s\n", Message(MsgDigestFailedBackups))
+		fmt.Fprintln(w, "BACKUP\tCLUSTER\tCREATED")
+		for _, b := range digest.FailedBackups {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", b.Name, b.Cluster, b.Created.Format(time.RFC3339))
+		}
+	}
+	if len(digest.ClustersInError) != 0 {
+		fmt.Fprintf(w, "\n%s\n", Message(MsgDigestClustersInError))
+		fmt.Fprintln(w, "CLUSTER\tMESSAGE")
+		for _, cl := range digest.ClustersInError {
+			fmt.Fprintf(w, "%s\t%s\n", cl.Name, cl.Message)
+		}
+	}
+	if len(digest.CapacityWarnings) != 0 {
+		fmt.Fprintf(w, "\n%s\n", Message(MsgDigestCapacity))
+		for _, warning := range digest.CapacityWarnings {
+			fmt.Fprintln(w, warning.String())
+		}
+	}
+	w.Flush()
+	return buf.String()
+}
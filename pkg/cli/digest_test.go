@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendMailDeadline(t *testing.T) {
+	// The server accepts the connection but never greets, like a stuck SMTP server.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() }) //nolint:errcheck
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+	t.Cleanup(func() {
+		select {
+		case conn := <-accepted:
+			conn.Close() //nolint:errcheck
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	err = sendMail(ctx, l.Addr().String(), "127.0.0.1", nil, "everest@example.com", []string{"ops@example.com"}, []byte("digest"))
+	assert.Error(t, err)
+	assert.Less(t, time.Since(started), 5*time.Second)
+}
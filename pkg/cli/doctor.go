@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ErrUpgradeChecksFailed is returned when the installation is not ready for a Kubernetes upgrade.
+var ErrUpgradeChecksFailed error = &Error{ID: MsgDoctorUpgradeFailed}
+
+// DoctorOptions holds parameters of the doctor command.
+type DoctorOptions struct {
+	// KubernetesUpgrade is the Kubernetes version the control plane is going to be upgraded to.
+	KubernetesUpgrade string
+}
+
+// Doctor diagnoses the installation and prints a report.
+func (c *CLI) Doctor(ctx context.Context, opts DoctorOptions) error {
+	topology, err := c.kubeClient.GetNodeTopology(ctx)
+	if err != nil {
+		c.logError(MsgDoctorTopologyFailed)
+		return err
+	}
+	printTopology(topology)
+	if opts.KubernetesUpgrade == "" {
+		return nil
+	}
+	c.logInfo(MsgDoctorUpgradeRunning, opts.KubernetesUpgrade)
+	checks, err := c.kubeClient.RunUpgradeChecks(ctx, opts.KubernetesUpgrade)
+	if err != nil {
+		c.logError(MsgDoctorUpgradeRunFailed)
+		return err
+	}
+	if !printChecks(checks) {
+		return ErrUpgradeChecksFailed
+	}
+	c.logInfo(MsgDoctorUpgradePassed, opts.KubernetesUpgrade)
+	return nil
+}
+
+// printTopology prints the worker nodes grouped by region, zone and instance type
+// and whether database clusters can be spread across zones.
+func printTopology(topology *kubernetes.NodeTopology) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REGION\tZONE\tINSTANCE TYPE\tNODES\tCPU\tMEMORY")
+	for _, group := range topology.Groups {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			valueOrNone(group.Region), valueOrNone(group.Zone), valueOrNone(group.InstanceType), group.Nodes,
+			resource.NewMilliQuantity(int64(group.Allocatable.CPUMillis), resource.DecimalSI),
+			resource.NewQuantity(int64(group.Allocatable.MemoryBytes), resource.BinarySI),
+		)
+	}
+	w.Flush()
+	if zones := len(topology.Zones()); zones > 1 {
+		fmt.Printf("\n%s\n\n", Message(MsgDoctorMultiAZ, zones))
+	} else {
+		fmt.Printf("\n%s\n\n", Message(MsgDoctorSingleAZ))
+	}
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+)
+
+// Engines prints the database versions supported by the installed operators of the engines.
+// Engines whose operator is not installed are skipped unless the engine is given.
+func (c *CLI) Engines(ctx context.Context, engine, output string) error {
+	if output != OutputText && output != OutputJSON {
+		return newError(MsgUnsupportedOutput, nil, output)
+	}
+	engines := kubernetes.Engines()
+	if engine != "" {
+		if _, ok := defaultEngineImages[dbaasv1.EngineType(engine)]; !ok {
+			return newError(MsgUnsupportedEngine, nil, engine)
+		}
+		engines = []string{engine}
+	}
+	all := make([]*kubernetes.EngineVersions, 0, len(engines))
+	for _, e := range engines {
+		versions, err := c.kubeClient.ListEngineVersions(ctx, e)
+		if errors.Is(err, kubernetes.ErrOperatorNotFound) && engine == "" {
+			continue
+		}
+		if err != nil {
+			return newError(MsgEngineVersionsFailed, err, e)
+		}
+		all = append(all, versions)
+	}
+	if output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(all)
+	}
+	if len(all) == 0 {
+		fmt.Println(Message(MsgEnginesNone))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENGINE\tOPERATOR\tVERSIONS")
+	for _, v := range all {
+		versions := make([]string, 0, len(v.Versions))
+		for _, ev := range v.Versions {
+			versions = append(versions, ev.Version)
+		}
+		fmt.Fprintf(w, "%s\t%s %s\t%s\n", v.Engine, v.Operator, v.OperatorVersion, valueOrDash(strings.Join(versions, ", ")))
+	}
+	return w.Flush()
+}
+
+// engineImage returns the image of the database version if the installed operator of the engine supports it.
+func (c *CLI) engineImage(ctx context.Context, engine dbaasv1.EngineType, version string) (string, error) {
+	versions, err := c.kubeClient.ListEngineVersions(ctx, string(engine))
+	if err != nil {
+		return "", newError(MsgEngineVersionsFailed, err, engine)
+	}
+	image, ok := versions.Image(version)
+	if !ok {
+		supported := make([]string, 0, len(versions.Versions))
+		for _, ev := range versions.Versions {
+			supported = append(supported, ev.Version)
+		}
+		return "", newError(MsgEngineVersionUnsupported, nil, engine, version, versions.Operator, versions.OperatorVersion,
+			valueOrDash(strings.Join(supported, ", ")))
+	}
+	return image, nil
+}
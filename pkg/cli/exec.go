@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"golang.org/x/term"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// terminalResizeInterval is how often the size of the local terminal is checked.
+const terminalResizeInterval = 250 * time.Millisecond
+
+// ExecOptions holds parameters of the db exec command.
+type ExecOptions struct {
+	Command []string
+	// Pod and Container override the primary pod and the database container.
+	Pod       string
+	Container string
+	// Stdin passes the standard input to the command.
+	Stdin bool
+	// TTY allocates a terminal for the command. The standard input must be a terminal.
+	TTY bool
+}
+
+// ExecDatabaseCluster runs the command in the primary pod of the database cluster.
+// An error implementing k8s.io/client-go/util/exec.ExitError is returned unwrapped if
+// the command exits with a non-zero status, so it is not reported as a failure of the
+// provisioner.
+func (c *CLI) ExecDatabaseCluster(ctx context.Context, name string, opts ExecOptions) error {
+	execOpts := kubernetes.DatabaseExecOptions{
+		Command:   opts.Command,
+		Pod:       opts.Pod,
+		Container: opts.Container,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+		TTY:       opts.TTY,
+	}
+	if opts.Stdin || opts.TTY {
+		execOpts.Stdin = os.Stdin
+	}
+	if opts.TTY {
+		fd := int(os.Stdin.Fd())
+		if !term.IsTerminal(fd) {
+			return newError(MsgDatabaseExecNoTerminal, nil)
+		}
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return newError(MsgDatabaseExecNoTerminal, err)
+		}
+		defer term.Restore(fd, state) //nolint:errcheck
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		execOpts.TerminalSizes = watchTerminalSize(ctx, int(os.Stdout.Fd()))
+	}
+
+	err := c.kubeClient.ExecInDatabaseCluster(ctx, name, execOpts)
+	var exitErr utilexec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return newError(MsgDatabaseExecFailed, err, name)
+	}
+	return err
+}
+
+// terminalSizeQueue reports the sizes of the local terminal to the remote terminal.
+type terminalSizeQueue chan remotecommand.TerminalSize
+
+// Next returns the next size of the terminal or nil once the command has exited.
+func (q terminalSizeQueue) Next() *remotecommand.TerminalSize {
+	size, ok := <-q
+	if !ok {
+		return nil
+	}
+	return &size
+}
+
+// watchTerminalSize sends the size of the terminal and its changes until the context is
+// done. The size is polled, there is no portable notification of resizes.
+func watchTerminalSize(ctx context.Context, fd int) terminalSizeQueue {
+	q := make(terminalSizeQueue, 1)
+	go func() {
+		defer close(q)
+		var last remotecommand.TerminalSize
+		ticker := time.NewTicker(terminalResizeInterval)
+		defer ticker.Stop()
+		for {
+			if width, height, err := term.GetSize(fd); err == nil {
+				size := remotecommand.TerminalSize{Width: uint16(width), Height: uint16(height)}
+				if size != last {
+					select {
+					case q <- size:
+						last = size
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return q
+}
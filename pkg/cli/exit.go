@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// Exit codes of the commands. They follow sysexits.h where applicable.
+const (
+	ExitCodeError            = 1
+	ExitCodeOperatorNotFound = 69
+	ExitCodeAlreadyExists    = 73
+	ExitCodeTimeout          = 75
+	ExitCodeForbidden        = 77
+	// ExitCodeInterrupted is returned if the command has been interrupted by a signal.
+	ExitCodeInterrupted = 130
+)
+
+// ExitCode returns the exit code matching the type of the error. The exit status of a
+// command run in a database cluster is passed through.
+func ExitCode(err error) int {
+	var exitErr utilexec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		return exitErr.ExitStatus()
+	case errors.Is(err, kubernetes.ErrOperatorNotFound):
+		return ExitCodeOperatorNotFound
+	case errors.Is(err, kubernetes.ErrAlreadyExists):
+		return ExitCodeAlreadyExists
+	case errors.Is(err, kubernetes.ErrTimeout):
+		return ExitCodeTimeout
+	case errors.Is(err, kubernetes.ErrForbidden):
+		return ExitCodeForbidden
+	case errors.Is(err, context.Canceled):
+		return ExitCodeInterrupted
+	default:
+		return ExitCodeError
+	}
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+const (
+	// ExportFormatKustomize writes a kustomization per component and one including all of them.
+	ExportFormatKustomize = "kustomize"
+	// ExportFormatArgoCD adds Argo CD Applications syncing the components in order.
+	ExportFormatArgoCD = "argocd"
+	// ExportFormatFlux adds Flux Kustomizations applying the components in order.
+	ExportFormatFlux = "flux"
+
+	exportManifestFile      = "manifests.yaml"
+	exportKustomizationFile = "kustomization.yaml"
+	argoCDNamespace         = "argocd"
+	fluxNamespace           = "flux-system"
+)
+
+// ExportOptions hold parameters of the export of the installation manifests.
+type ExportOptions struct {
+	Format string
+	// Dir is the directory the manifests are written to. It is created if it does not exist.
+	Dir string
+	// RepoURL is the Git repository the manifests are committed to. It is required by Argo CD.
+	RepoURL string
+	// Revision is the branch, tag or commit Argo CD syncs.
+	Revision string
+	// RepoPath is the path of Dir in the repository. Dir is used if it is empty.
+	RepoPath string
+	// Source is the name of the Flux GitRepository the manifests are committed to.
+	Source string
+}
+
+// Export writes the manifests ProvisionCluster would apply in a layout consumable by GitOps
+// controllers. Every component is written to its own directory with a kustomization.
+func (c *CLI) Export(ctx context.Context, opts ExportOptions) error {
+	switch opts.Format {
+	case ExportFormatKustomize, ExportFormatFlux:
+	case ExportFormatArgoCD:
+		if opts.RepoURL == "" {
+			return newError(MsgExportRepoRequired, nil)
+		}
+	default:
+		return newError(MsgExportUnsupportedFormat, nil, opts.Format)
+	}
+	if err := c.detectOpenShift(ctx); err != nil {
+		return err
+	}
+	exportOpts, err := c.exportOptions()
+	if err != nil {
+		return err
+	}
+	components, err := c.kubeClient.ExportManifests(exportOpts)
+	if err != nil {
+		c.logError(MsgExportFailed, opts.Dir)
+		return err
+	}
+	if err := writeExport(components, opts); err != nil {
+		return newError(MsgExportFailed, err, opts.Dir)
+	}
+	c.logInfo(MsgExportWritten, opts.Dir)
+	return nil
+}
+
+// exportOptions returns the installation of the configuration.
+func (c *CLI) exportOptions() (kubernetes.ExportOptions, error) {
+	reqs := operatorInstallRequests(c.catalog, c.catalogNamespace, c.config.OperatorChannels)
+	c.pinOperatorVersions(reqs)
+	opts := kubernetes.ExportOptions{
+		InstallOLM:       !c.openShift && c.config.InstallOLM,
+		Catalog:          c.catalog,
+		CatalogNamespace: c.catalogNamespace,
+		CatalogImage:     c.config.Catalog.Image,
+		Namespace:        namespace,
+		OperatorGroup:    operatorGroup,
+		Operators:        reqs,
+	}
+	if c.snapshot != nil {
+		opts.CatalogImage = c.snapshot.Catalog.Image
+	}
+	if opts.InstallOLM {
+		tuning, err := c.olmTuning()
+		if err != nil {
+			return opts, err
+		}
+		opts.OLMTuning = tuning
+	}
+	if c.config.Monitoring.Enabled && c.config.Monitoring.PMM != nil {
+		pmm := c.config.Monitoring.PMM
+		if pmm.CredentialsSecret == "" {
+			return opts, newError(MsgExportCredentialsSecretRequired, nil)
+		}
+		certs, err := loadMonitoringTLS(pmm.TLS)
+		if err != nil {
+			return opts, err
+		}
+		opts.MonitoringEndpoint = pmm.Endpoint
+		opts.MonitoringCredentialsSecret = pmm.CredentialsSecret
+		opts.MonitoringTLS = certs
+		opts.Selective = c.config.Monitoring.Selective
+	}
+	return opts, nil
+}
+
+// writeExport writes the components to directories prefixed with their order and
+// the files of the format referencing them.
+func writeExport(components []kubernetes.ExportComponent, opts ExportOptions) error {
+	repoPath := opts.RepoPath
+	if repoPath == "" {
+		repoPath = filepath.ToSlash(opts.Dir)
+	}
+	dirs := make([]string, 0, len(components))
+	var apps []interface{}
+	for i, component := range components {
+		dir := fmt.Sprintf("%02d-%s", i, component.Name)
+		dirs = append(dirs, dir)
+		objs := make([]interface{}, 0, len(component.Objects))
+		for j := range component.Objects {
+			objs = append(objs, component.Objects[j].Object)
+		}
+		manifest, err := kubernetes.MarshalManifests(objs)
+		if err != nil {
+			return err
+		}
+		if err := writeExportFile(filepath.Join(opts.Dir, dir), exportManifestFile, manifest); err != nil {
+			return err
+		}
+		if err := writeKustomization(filepath.Join(opts.Dir, dir), []string{exportManifestFile}); err != nil {
+			return err
+		}
+		switch opts.Format {
+		case ExportFormatArgoCD:
+			apps = append(apps, argoCDApplication(component.Name, i, path.Join(repoPath, dir), opts))
+		case ExportFormatFlux:
+			var dependsOn string
+			if i != 0 {
+				dependsOn = "everest-" + components[i-1].Name
+			}
+			apps = append(apps, fluxKustomization(component.Name, dependsOn, path.Join(repoPath, dir), opts.Source))
+		}
+	}
+	switch opts.Format {
+	case ExportFormatKustomize:
+		return writeKustomization(opts.Dir, dirs)
+	case ExportFormatArgoCD, ExportFormatFlux:
+		manifest, err := kubernetes.MarshalManifests(apps)
+		if err != nil {
+			return err
+		}
+		return writeExportFile(filepath.Join(opts.Dir, opts.Format), exportManifestFile, manifest)
+	}
+	return nil
+}
+
+func writeExportFile(dir, name string, content []byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name), content, 0o644)
+}
+
+func writeKustomization(dir string, resources []string) error {
+	manifest, err := kubernetes.MarshalManifests([]interface{}{map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  resources,
+	}})
+	if err != nil {
+		return err
+	}
+	return writeExportFile(dir, exportKustomizationFile, manifest)
+}
+
+// argoCDApplication syncs a component. Sync waves make Argo CD sync the components in order.
+func argoCDApplication(component string, wave int, repoPath string, opts ExportOptions) map[string]interface{} {
+	revision := opts.Revision
+	if revision == "" {
+		revision = "HEAD"
+	}
+	return map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata": map[string]interface{}{
+			"name":      "everest-" + component,
+			"namespace": argoCDNamespace,
+			"annotations": map[string]interface{}{
+				"argocd.argoproj.io/sync-wave": fmt.Sprint(wave),
+			},
+		},
+		"spec": map[string]interface{}{
+			"project": "default",
+			"source": map[string]interface{}{
+				"repoURL":        opts.RepoURL,
+				"targetRevision": revision,
+				"path":           repoPath,
+			},
+			"destination": map[string]interface{}{
+				"server": "https://kubernetes.default.svc",
+			},
+			"syncPolicy": map[string]interface{}{
+				"automated":   map[string]interface{}{"prune": false, "selfHeal": true},
+				"syncOptions": []string{"ServerSideApply=true"},
+			},
+		},
+	}
+}
+
+// fluxKustomization applies a component after the component it depends on is ready.
+func fluxKustomization(component, dependsOn, repoPath, source string) map[string]interface{} {
+	if source == "" {
+		source = fluxNamespace
+	}
+	spec := map[string]interface{}{
+		"interval": "10m",
+		"path":     "./" + repoPath,
+		"prune":    false,
+		"wait":     true,
+		"sourceRef": map[string]interface{}{
+			"kind": "GitRepository",
+			"name": source,
+		},
+	}
+	if dependsOn != "" {
+		spec["dependsOn"] = []interface{}{map[string]interface{}{"name": dependsOn}}
+	}
+	return map[string]interface{}{
+		"apiVersion": "kustomize.toolkit.fluxcd.io/v1",
+		"kind":       "Kustomization",
+		"metadata": map[string]interface{}{
+			"name":      "everest-" + component,
+			"namespace": fluxNamespace,
+		},
+		"spec": spec,
+	}
+}
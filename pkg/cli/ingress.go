@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+const (
+	// defaultPMMService is the HTTPS service of a PMM server installed with its Helm chart.
+	defaultPMMService = "monitoring-service"
+	// defaultPMMPort is the HTTPS port of the PMM service.
+	defaultPMMPort = 443
+)
+
+// exposeOptions returns the options exposing a service at the host with the ingress settings
+// of the configuration.
+func (c *CLI) exposeOptions(host, tlsSecret string) kubernetes.ExposeOptions {
+	i := c.config.Ingress
+	return kubernetes.ExposeOptions{
+		Host:           host,
+		TLSSecret:      tlsSecret,
+		CertificateARN: i.CertificateARN,
+		Class:          i.Class,
+		Controller:     kubernetes.IngressController(i.Controller),
+	}
+}
+
+// Expose creates the Ingresses, or the Routes on OpenShift, exposing Everest at backend.host
+// and PMM running in the cluster at ingress.pmm.host, and prints the URLs they are reachable at.
+func (c *CLI) Expose(ctx context.Context) error {
+	if err := c.detectOpenShift(ctx); err != nil {
+		return err
+	}
+	defer c.printWarnings()
+
+	b := c.config.Backend
+	pmm := c.config.Ingress.PMM
+	if b.Host == "" && pmm.Host == "" {
+		return newError(MsgExposeNoHost, nil)
+	}
+	if b.Host != "" {
+		opts := c.exposeOptions(b.Host, b.TLSSecret)
+		opts.Name = kubernetes.BackendName
+		opts.Service = kubernetes.BackendName
+		opts.Port = kubernetes.BackendPort
+		if err := c.expose(ctx, opts); err != nil {
+			return err
+		}
+	}
+	if pmm.Host != "" {
+		opts := c.exposeOptions(pmm.Host, pmm.TLSSecret)
+		opts.Name = "pmm"
+		opts.Namespace = pmm.Namespace
+		opts.Service = pmm.Service
+		if opts.Service == "" {
+			opts.Service = defaultPMMService
+		}
+		opts.Port = pmm.Port
+		if opts.Port == 0 {
+			opts.Port = defaultPMMPort
+		}
+		opts.BackendHTTPS = true
+		if err := c.expose(ctx, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expose creates the Ingress or the Route of the service and logs its URL.
+func (c *CLI) expose(ctx context.Context, opts kubernetes.ExposeOptions) error {
+	c.logInfo(MsgExposing, opts.Service, opts.Host)
+	warnings, err := c.kubeClient.Expose(ctx, opts)
+	c.warnings.Merge(warnings)
+	if err != nil {
+		c.logError(MsgExposeServiceFailed, opts.Service)
+		return err
+	}
+	c.logInfo(MsgExposed, opts.Service, c.kubeClient.ExposedURL(opts))
+	return nil
+}
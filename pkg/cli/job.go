@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"github.com/gen1us2k/everest-provisioner/pkg/version"
+)
+
+const (
+	provisionerImage = "perconalab/everest-provisioner"
+	snapshotFile     = "snapshot.json"
+)
+
+// InstallJobOptions configures the Job rendered by RenderInstallJob.
+type InstallJobOptions struct {
+	Name      string
+	Namespace string
+	// Image defaults to the provisioner image of the running version.
+	Image string
+	// Snapshot is the path of a snapshot the Job installs the versions of.
+	Snapshot string
+}
+
+// DefaultProvisionerImage returns the provisioner image of the running version.
+func DefaultProvisionerImage() string {
+	tag := version.Version
+	if tag == "dev" {
+		tag = "latest"
+	}
+	return provisionerImage + ":" + tag
+}
+
+// RenderInstallJob writes the manifest of a Job which runs the installation with
+// the configuration c inside the cluster. It does not connect to the cluster so
+// it can be used where the API server is not reachable. Certificate files and the
+// snapshot are stored in the secret of the Job together with the configuration.
+func RenderInstallJob(c *config.AppConfig, opts InstallJobOptions, w io.Writer) error {
+	jc := *c
+	jc.InCluster = true
+	jc.Kubeconfig, jc.KubeconfigData, jc.KubeContext = "", "", ""
+	jc.Cluster, jc.Clusters = "", nil
+
+	files := map[string][]byte{}
+	if c.Monitoring.PMM != nil {
+		pmm := *c.Monitoring.PMM
+		certs := []struct {
+			path *string
+			name string
+		}{
+			{&pmm.TLS.CA, "pmm-ca.crt"},
+			{&pmm.TLS.Cert, "pmm-tls.crt"},
+			{&pmm.TLS.Key, "pmm-tls.key"},
+		}
+		for _, cert := range certs {
+			if *cert.path == "" {
+				continue
+			}
+			data, err := os.ReadFile(*cert.path)
+			if err != nil {
+				return newError(MsgJobFileReadFailed, err, *cert.path)
+			}
+			files[cert.name] = data
+			*cert.path = path.Join(kubernetes.ProvisionerJobConfigDir, cert.name)
+		}
+		// The password file was read by ParseConfig and is not available in the cluster.
+		pmm.PasswordFile = ""
+		jc.Monitoring.PMM = &pmm
+	}
+
+	args := []string{"install"}
+	if opts.Snapshot != "" {
+		data, err := os.ReadFile(opts.Snapshot)
+		if err != nil {
+			return newError(MsgSnapshotReadFailed, err, opts.Snapshot)
+		}
+		if _, err := kubernetes.ParseInstallSnapshot(data); err != nil {
+			return newError(MsgSnapshotReadFailed, err, opts.Snapshot)
+		}
+		files[snapshotFile] = data
+		args = append(args, "--from-snapshot", path.Join(kubernetes.ProvisionerJobConfigDir, snapshotFile))
+	}
+
+	data, err := jc.Marshal()
+	if err != nil {
+		return newError(MsgJobRenderFailed, err)
+	}
+	files[kubernetes.ProvisionerJobConfigFile] = data
+
+	image := opts.Image
+	if image == "" {
+		image = DefaultProvisionerImage()
+	}
+	manifest, err := kubernetes.ProvisionerJobManifest(kubernetes.ProvisionerJob{
+		Name:      opts.Name,
+		Namespace: opts.Namespace,
+		Image:     image,
+		Args:      args,
+		Files:     files,
+	})
+	if err != nil {
+		return newError(MsgJobRenderFailed, err)
+	}
+	_, err = w.Write(manifest)
+	return err
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// acquireLock acquires the lock of the cluster for the command so concurrent runs of mutating
+// commands do not interfere. With force_unlock, the lock is taken over from any other run
+// holding it, whether that run is still going or not; a run losing the lock stops once it
+// notices. The returned context is canceled if the lock is lost.
+//
+// The lock is acquired once per process and shared by the commands running in it, e.g. the
+// loops of serve or a command calling another locked command. It is released once all of
+// them have returned.
+func (c *CLI) acquireLock(ctx context.Context, command string) (context.Context, func(), error) {
+	c.lockMu.Lock()
+	defer c.lockMu.Unlock()
+	if c.lock == nil {
+		lock, err := c.kubeClient.AcquireLock(ctx, kubernetes.LockOptions{
+			Identity: lockIdentity(),
+			Command:  command,
+			Force:    c.config.ForceUnlock,
+		})
+		var locked *kubernetes.LockedError
+		if errors.As(err, &locked) {
+			return ctx, nil, newError(MsgLockHeld, nil, locked.Holder, locked.Command, locked.Expires.Format(time.RFC3339))
+		}
+		if err != nil {
+			c.logError(MsgLockFailed)
+			return ctx, nil, err
+		}
+		if lock.Previous != "" {
+			c.logWarn(MsgLockTakenOver, lock.Previous)
+		}
+		watchCtx, stopWatch := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-lock.Lost():
+				c.logError(MsgLockLost)
+			case <-watchCtx.Done():
+			}
+		}()
+		c.lock, c.stopLockWatch = lock, stopWatch
+	} else {
+		select {
+		case <-c.lock.Lost():
+			return ctx, nil, newError(MsgLockLost, nil)
+		default:
+		}
+	}
+	lock := c.lock
+	c.lockRefs++
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-lock.Lost():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		cancel()
+		c.releaseLock(lock)
+	}, nil
+}
+
+// releaseLock releases the lock once the last command holding it has returned.
+func (c *CLI) releaseLock(lock *kubernetes.Lock) {
+	c.lockMu.Lock()
+	defer c.lockMu.Unlock()
+	c.lockRefs--
+	if c.lockRefs != 0 {
+		return
+	}
+	c.stopLockWatch()
+	c.lock, c.stopLockWatch = nil, nil
+	// The context of the command is canceled if it has been interrupted.
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	if err := lock.Release(ctx); err != nil {
+		c.logWarn(MsgLockReleaseFailed, err)
+	}
+}
+
+// lockIdentity identifies the run holding the lock to other runs.
+func lockIdentity() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s@%s/%d", currentUser(), host, os.Getpid())
+}
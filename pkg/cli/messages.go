@@ -0,0 +1,905 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MessageID identifies a user-facing message. IDs are stable and can be used
+// to map messages to localized texts or to match errors in tests.
+type MessageID string
+
+const (
+	MsgProvisionStarted     MessageID = "provision.started"
+	MsgEventRecordFailed    MessageID = "provision.event_record_failed"
+	MsgWarningsCheckFailed  MessageID = "provision.warnings_check_failed"
+	MsgWarningsHeader       MessageID = "provision.warnings_header"
+	MsgProgressReadFailed   MessageID = "provision.progress_read_failed"
+	MsgProgressRecordFailed MessageID = "provision.progress_record_failed"
+	MsgStepSkipped          MessageID = "provision.step_skipped"
+	MsgTimingsHeader        MessageID = "provision.timings_header"
+	MsgTimingsRecordFailed  MessageID = "provision.timings_record_failed"
+	MsgPullSecretsCreating  MessageID = "provision.pull_secrets_creating"
+	MsgPullSecretsFailed    MessageID = "provision.pull_secrets_failed"
+	MsgRollbackStarted      MessageID = "provision.rollback_started"
+	MsgRollbackFailed       MessageID = "provision.rollback_failed"
+	MsgRollbackDone         MessageID = "provision.rollback_done"
+
+	MsgOLMInstalling        MessageID = "olm.installing"
+	MsgOLMInstallFailed     MessageID = "olm.install_failed"
+	MsgOLMInstalled         MessageID = "olm.installed"
+	MsgOLMUnknownProfile    MessageID = "olm.unknown_profile"
+	MsgOLMUnknownComponent  MessageID = "olm.unknown_component"
+	MsgOLMInvalidResources  MessageID = "olm.invalid_resources"
+	MsgOLMBuiltIn           MessageID = "olm.built_in"
+	MsgCatalogInstallFailed MessageID = "olm.catalog_install_failed"
+
+	MsgClusterTypeFailed MessageID = "cluster.type_failed"
+	MsgOpenShiftDetected MessageID = "cluster.openshift_detected"
+
+	MsgMonitoringStarted            MessageID = "monitoring.started"
+	MsgMonitoringProvisioned        MessageID = "monitoring.provisioned"
+	MsgPMMAccountCreating           MessageID = "monitoring.pmm_account_creating"
+	MsgPMMTokenGenerated            MessageID = "monitoring.pmm_token_generated"
+	MsgPMMTokenMissing              MessageID = "monitoring.pmm_token_missing"
+	MsgPMMCredentialsSecret         MessageID = "monitoring.pmm_credentials_secret"
+	MsgPMMAPIKeyProvided            MessageID = "monitoring.pmm_api_key_provided"
+	MsgPMMServiceAccountsMissing    MessageID = "monitoring.pmm_service_accounts_missing"
+	MsgPMMPasswordReadFailed        MessageID = "monitoring.pmm_password_read_failed"
+	MsgPMMBadStatus                 MessageID = "monitoring.pmm_bad_status"
+	MsgMonitoringProvisioning       MessageID = "monitoring.provisioning"
+	MsgMonitoringProvisionFailed    MessageID = "monitoring.provision_failed"
+	MsgMonitoringAlreadyProvisioned MessageID = "monitoring.already_provisioned"
+	MsgMonitoringInstanceInvalid    MessageID = "monitoring.instance_invalid"
+	MsgMonitoringTLSInvalid         MessageID = "monitoring.tls_invalid"
+	MsgMonitoringInvalidResources   MessageID = "monitoring.invalid_resources"
+	MsgMonitoringInstanceFailed     MessageID = "monitoring.instance_failed"
+	MsgMonitoringInstanceCreated    MessageID = "monitoring.instance_created"
+	MsgMonitoringInstancesFailed    MessageID = "monitoring.instances_failed"
+	MsgMonitoringInstancesNone      MessageID = "monitoring.instances_none"
+	MsgMonitoringInstanceDelFailed  MessageID = "monitoring.instance_delete_failed"
+	MsgMonitoringInstanceDeleted    MessageID = "monitoring.instance_deleted"
+	MsgMonitoringVerifying          MessageID = "monitoring.verifying"
+	MsgMonitoringVerified           MessageID = "monitoring.verified"
+	MsgMonitoringVerifyFailed       MessageID = "monitoring.verify_failed"
+	MsgMonitoringNotProvisioned     MessageID = "monitoring.not_provisioned"
+	MsgMonitoringAgentNotReady      MessageID = "monitoring.agent_not_ready"
+	MsgMonitoringNoSamples          MessageID = "monitoring.no_samples"
+	MsgMonitoringSamplesSkipped     MessageID = "monitoring.samples_skipped"
+	MsgMonitoringNotConfigured      MessageID = "monitoring.not_configured"
+	MsgMonitoringDisableConfirm     MessageID = "monitoring.disable_confirm"
+	MsgMonitoringDisabling          MessageID = "monitoring.disabling"
+	MsgMonitoringRemoveFailed       MessageID = "monitoring.remove_failed"
+	MsgMonitoringRemoved            MessageID = "monitoring.removed"
+
+	MsgAlertingProvisioning MessageID = "alerting.provisioning"
+	MsgAlertingFailed       MessageID = "alerting.failed"
+	MsgAlertingProvisioned  MessageID = "alerting.provisioned"
+	MsgAlertingRemoveFailed MessageID = "alerting.remove_failed"
+
+	MsgOperatorsInstallingParallel MessageID = "operator.installing_parallel"
+	MsgOperatorInstalling          MessageID = "operator.installing"
+	MsgOperatorInstallFailed       MessageID = "operator.install_failed"
+	MsgOperatorInstalled           MessageID = "operator.installed"
+	MsgOperatorUnknown             MessageID = "operator.unknown"
+	MsgOperatorUninstallPlanFailed MessageID = "operator.uninstall_plan_failed"
+	MsgOperatorUninstallFailed     MessageID = "operator.uninstall_failed"
+	MsgOperatorUninstalled         MessageID = "operator.uninstalled"
+	MsgOperatorDataKept            MessageID = "operator.data_kept"
+	MsgOperatorPrunePlanFailed     MessageID = "operator.prune_plan_failed"
+	MsgOperatorPruneFailed         MessageID = "operator.prune_failed"
+	MsgOperatorsPruned             MessageID = "operator.pruned"
+	MsgOperatorsPruneSkipped       MessageID = "operator.prune_skipped"
+	MsgOperatorVersionsFailed      MessageID = "operator.versions_failed"
+	MsgSchedulingNotConfigured     MessageID = "operator.scheduling_not_configured"
+	MsgOperatorsScheduleFailed     MessageID = "operator.schedule_failed"
+	MsgOperatorsScheduled          MessageID = "operator.scheduled"
+	MsgOperatorsScheduleUnchanged  MessageID = "operator.schedule_unchanged"
+	MsgProgressResetFailed         MessageID = "operator.progress_reset_failed"
+
+	MsgLeftoversChecking     MessageID = "leftovers.checking"
+	MsgLeftoversCheckFailed  MessageID = "leftovers.check_failed"
+	MsgLeftoverFound         MessageID = "leftovers.found"
+	MsgLeftoversRemoving     MessageID = "leftovers.removing"
+	MsgLeftoversRemoveFailed MessageID = "leftovers.remove_failed"
+	MsgLeftoversRemoved      MessageID = "leftovers.removed"
+	MsgLeftoversRemain       MessageID = "leftovers.found_error"
+
+	MsgDBaaSConnecting       MessageID = "dbaas.connecting"
+	MsgKubeconfigFailed      MessageID = "dbaas.kubeconfig_failed"
+	MsgKubeconfigInvalidData MessageID = "dbaas.kubeconfig_invalid_data"
+	MsgKubeconfigStdinFailed MessageID = "dbaas.kubeconfig_stdin_failed"
+	MsgJSONMarshalFailed     MessageID = "dbaas.json_marshal_failed"
+	MsgDBaaSConnected        MessageID = "dbaas.connected"
+	MsgDBaaSBadStatus        MessageID = "dbaas.bad_status"
+
+	MsgDatabaseCreating           MessageID = "database.creating"
+	MsgDatabaseCreateFailed       MessageID = "database.create_failed"
+	MsgDatabaseWaitingReady       MessageID = "database.waiting_ready"
+	MsgDatabaseReady              MessageID = "database.ready"
+	MsgDatabaseRestarting         MessageID = "database.restarting"
+	MsgDatabaseRestartFailed      MessageID = "database.restart_failed"
+	MsgDatabaseWaitingRestart     MessageID = "database.waiting_restart"
+	MsgDatabaseRestarted          MessageID = "database.restarted"
+	MsgDatabaseReleaseFailed      MessageID = "database.release_failed"
+	MsgDatabaseNotManaged         MessageID = "database.not_managed"
+	MsgDatabaseReleased           MessageID = "database.released"
+	MsgMonitoringEnableFailed     MessageID = "database.monitoring_enable_failed"
+	MsgMonitoringEnabled          MessageID = "database.monitoring_enabled"
+	MsgMonitoringDisableFailed    MessageID = "database.monitoring_disable_failed"
+	MsgMonitoringDisabled         MessageID = "database.monitoring_disabled"
+	MsgMonitoringInstanceAssigned MessageID = "database.monitoring_instance_assigned"
+	MsgDatabaseExpires            MessageID = "database.expires"
+	MsgDatabaseExpired            MessageID = "database.expired"
+	MsgDatabaseExpireFailed       MessageID = "database.expire_failed"
+	MsgDatabaseDeletePlanFailed   MessageID = "database.delete_plan_failed"
+	MsgDatabaseDeleteFailed       MessageID = "database.delete_failed"
+	MsgDatabaseDeleted            MessageID = "database.deleted"
+	MsgDatabaseCheckLater         MessageID = "database.check_later"
+	MsgDatabaseNotReady           MessageID = "database.not_ready"
+	MsgDatabaseNotRestarted       MessageID = "database.not_restarted"
+	MsgDatabaseNameRequired       MessageID = "database.name_required"
+	MsgRestartStrategyUnknown     MessageID = "database.restart_strategy_unknown"
+	MsgDatabaseRestartingPod      MessageID = "database.restarting_pod"
+	MsgDatabaseRestartAborted     MessageID = "database.restart_aborted"
+	MsgUnsupportedEngine          MessageID = "database.unsupported_engine"
+	MsgEngineVersionsFailed       MessageID = "database.engine_versions_failed"
+	MsgEngineVersionUnsupported   MessageID = "database.engine_version_unsupported"
+	MsgEnginesNone                MessageID = "database.engines_none"
+	MsgInvalidCPU                 MessageID = "database.invalid_cpu"
+	MsgInvalidMemory              MessageID = "database.invalid_memory"
+	MsgDiskTooLarge               MessageID = "database.disk_too_large"
+	MsgStorageClassFailed         MessageID = "database.storage_class_failed"
+	MsgStorageClassInvalid        MessageID = "database.storage_class_invalid"
+	MsgStorageClassesFailed       MessageID = "database.storage_classes_failed"
+	MsgStorageClassesNone         MessageID = "database.storage_classes_none"
+	MsgPlacementFailed            MessageID = "database.placement_failed"
+	MsgPlacementZones             MessageID = "database.placement_zones"
+	MsgPlacementNodes             MessageID = "database.placement_nodes"
+	MsgPlacementImpossible        MessageID = "database.placement_impossible"
+	MsgPlacementNodesShared       MessageID = "database.placement_nodes_shared"
+	MsgPlacementZonesShared       MessageID = "database.placement_zones_shared"
+	MsgPlacementUnsatisfiable     MessageID = "database.placement_unsatisfiable"
+	MsgPlacementSpreadUnknown     MessageID = "database.placement_spread_unknown"
+	MsgPlacementRequiredNone      MessageID = "database.placement_required_none"
+	MsgPlacementApplyFailed       MessageID = "database.placement_apply_failed"
+	MsgInvalidDisk                MessageID = "database.invalid_disk"
+	MsgManifestParseFailed        MessageID = "database.manifest_parse"
+	MsgDatabaseScaling            MessageID = "database.scaling"
+	MsgDatabaseScaled             MessageID = "database.scaled"
+	MsgScaleFailed                MessageID = "database.scale_failed"
+	MsgScaleNothing               MessageID = "database.scale_nothing"
+	MsgScaleInvalidSize           MessageID = "database.scale_invalid_size"
+	MsgScaleInsufficient          MessageID = "database.scale_insufficient"
+	MsgResizeSizeRequired         MessageID = "database.resize_size_required"
+	MsgDatabaseResizing           MessageID = "database.resizing"
+	MsgResizeFailed               MessageID = "database.resize_failed"
+	MsgDatabaseWaitingResize      MessageID = "database.waiting_resize"
+	MsgVolumeNotResized           MessageID = "database.volume_not_resized"
+	MsgDatabaseResized            MessageID = "database.resized"
+	MsgProxyNothing               MessageID = "database.proxy_nothing"
+	MsgProxyInvalidReplicas       MessageID = "database.proxy_invalid_replicas"
+	MsgProxyExposeUnknown         MessageID = "database.proxy_expose_unknown"
+	MsgProxyExposedToAll          MessageID = "database.proxy_exposed_to_all"
+	MsgProxyConfiguring           MessageID = "database.proxy_configuring"
+	MsgProxyFailed                MessageID = "database.proxy_failed"
+	MsgProxyConfigured            MessageID = "database.proxy_configured"
+	MsgExposeUnknown              MessageID = "database.expose_unknown"
+	MsgInternalWithoutExpose      MessageID = "database.expose_internal_without_expose"
+	MsgExposeFailed               MessageID = "database.expose_failed"
+	MsgExposedToInternet          MessageID = "database.exposed_to_internet"
+	MsgCloneSameName              MessageID = "database.clone_same_name"
+	MsgDatabaseCloning            MessageID = "database.cloning"
+	MsgCloneFailed                MessageID = "database.clone_failed"
+	MsgDatabaseCloned             MessageID = "database.cloned"
+	MsgUpdateFileRequired         MessageID = "database.update_file_required"
+	MsgUpdateStrategyUnknown      MessageID = "database.update_strategy_unknown"
+	MsgUpdatePlanFailed           MessageID = "database.update_plan_failed"
+	MsgUpdateNothing              MessageID = "database.update_nothing"
+	MsgUpdateChangesHeader        MessageID = "database.update_changes_header"
+	MsgUpdateWarningsHeader       MessageID = "database.update_warnings_header"
+	MsgUpdateConfirm              MessageID = "database.update_confirm"
+	MsgUpdateCancelled            MessageID = "database.update_cancelled"
+	MsgDatabaseUpdating           MessageID = "database.updating"
+	MsgUpdateFailed               MessageID = "database.update_failed"
+	MsgDatabaseUpdated            MessageID = "database.updated"
+	MsgDatabaseNotUpdated         MessageID = "database.not_updated"
+	MsgDatabasePausing            MessageID = "database.pausing"
+	MsgDatabasePauseFailed        MessageID = "database.pause_failed"
+	MsgDatabaseWaitingPause       MessageID = "database.waiting_pause"
+	MsgDatabasePaused             MessageID = "database.paused"
+	MsgDatabaseNotPaused          MessageID = "database.not_paused"
+	MsgDatabaseResuming           MessageID = "database.resuming"
+	MsgDatabaseResumeFailed       MessageID = "database.resume_failed"
+	MsgDatabaseResumed            MessageID = "database.resumed"
+	MsgDatabaseNotResumed         MessageID = "database.not_resumed"
+	MsgDatabaseCredentialsFailed  MessageID = "database.credentials_failed"
+	MsgDatabasePinging            MessageID = "database.pinging"
+	MsgDatabasePingFailed         MessageID = "database.ping_failed"
+	MsgDatabasePinged             MessageID = "database.pinged"
+	MsgDatabasePingedVersion      MessageID = "database.pinged_version"
+	MsgDatabaseExecFailed         MessageID = "database.exec_failed"
+	MsgDatabaseExecNoTerminal     MessageID = "database.exec_no_terminal"
+
+	MsgDeletionCancelled      MessageID = "deletion.cancelled"
+	MsgDeletionHeader         MessageID = "deletion.header"
+	MsgDeletionNothing        MessageID = "deletion.nothing"
+	MsgDeletionWarningsHeader MessageID = "deletion.warnings_header"
+	MsgDeletionConfirm        MessageID = "deletion.confirm"
+	MsgForceRequired          MessageID = "deletion.force_required"
+
+	MsgDoctorTopologyFailed   MessageID = "doctor.topology_failed"
+	MsgDoctorMultiAZ          MessageID = "doctor.multi_az"
+	MsgDoctorSingleAZ         MessageID = "doctor.single_az"
+	MsgDoctorUpgradeRunning   MessageID = "doctor.upgrade_running"
+	MsgDoctorUpgradeRunFailed MessageID = "doctor.upgrade_run_failed"
+	MsgDoctorUpgradeFailed    MessageID = "doctor.upgrade_failed"
+	MsgDoctorUpgradePassed    MessageID = "doctor.upgrade_passed"
+
+	MsgPreflightRunning   MessageID = "preflight.running"
+	MsgPreflightRunFailed MessageID = "preflight.run_failed"
+	MsgPreflightPassed    MessageID = "preflight.passed"
+	MsgPreflightHint      MessageID = "preflight.hint"
+	MsgPreflightFailed    MessageID = "preflight.failed"
+
+	MsgSnapshotReadFailed      MessageID = "snapshot.read_failed"
+	MsgSnapshotOperatorMissing MessageID = "snapshot.operator_missing"
+	MsgSnapshotGetFailed       MessageID = "snapshot.get_failed"
+	MsgSnapshotPinningCatalog  MessageID = "snapshot.pinning_catalog"
+	MsgSnapshotRecordFailed    MessageID = "snapshot.record_failed"
+	MsgSnapshotRecorded        MessageID = "snapshot.recorded"
+
+	MsgJobFileReadFailed MessageID = "job.file_read_failed"
+	MsgJobRenderFailed   MessageID = "job.render_failed"
+
+	MsgStatusFailed        MessageID = "status.failed"
+	MsgStatusCatalog       MessageID = "status.catalog"
+	MsgStatusClusterDomain MessageID = "status.cluster_domain"
+	MsgUnsupportedOutput   MessageID = "status.unsupported_output"
+
+	MsgPlanFailed   MessageID = "plan.failed"
+	MsgPlanNoChange MessageID = "plan.no_change"
+	MsgPlanSummary  MessageID = "plan.summary"
+
+	MsgApplyFileRequired    MessageID = "apply.file_required"
+	MsgApplyLoadFailed      MessageID = "apply.load_failed"
+	MsgApplyUnknownOperator MessageID = "apply.unknown_operator"
+	MsgApplyFailed          MessageID = "apply.failed"
+	MsgApplyDatabase        MessageID = "apply.database"
+	MsgApplyDone            MessageID = "apply.done"
+
+	MsgExportUnsupportedFormat         MessageID = "export.unsupported_format"
+	MsgExportRepoRequired              MessageID = "export.repo_required"
+	MsgExportCredentialsSecretRequired MessageID = "export.credentials_secret_required"
+	MsgExportFailed                    MessageID = "export.failed"
+	MsgExportWritten                   MessageID = "export.written"
+
+	MsgResourcesFailed       MessageID = "resources.failed"
+	MsgResourcesFit          MessageID = "resources.fit"
+	MsgResourcesInsufficient MessageID = "resources.insufficient"
+
+	MsgConfigValid       MessageID = "config.valid"
+	MsgConfigValidNoFile MessageID = "config.valid_no_file"
+
+	MsgServeStarting    MessageID = "serve.starting"
+	MsgServeCacheFailed MessageID = "serve.cache_failed"
+	MsgServeFailed      MessageID = "serve.failed"
+	MsgServeStopped     MessageID = "serve.stopped"
+
+	MsgControllerStarting        MessageID = "controller.starting"
+	MsgControllerInvalidInterval MessageID = "controller.invalid_interval"
+	MsgControllerCRDFailed       MessageID = "controller.crd_failed"
+	MsgControllerListFailed      MessageID = "controller.list_failed"
+	MsgControllerDuplicate       MessageID = "controller.duplicate"
+	MsgControllerReconciling     MessageID = "controller.reconciling"
+	MsgControllerReconciled      MessageID = "controller.reconciled"
+	MsgControllerReconcileFailed MessageID = "controller.reconcile_failed"
+	MsgControllerStatusFailed    MessageID = "controller.status_failed"
+	MsgControllerStopped         MessageID = "controller.stopped"
+	MsgControllerMetrics         MessageID = "controller.metrics"
+	MsgControllerMetricsFailed   MessageID = "controller.metrics_failed"
+	MsgControllerLocked          MessageID = "controller.locked"
+
+	MsgServiceAccountProvisioning    MessageID = "token.provisioning"
+	MsgServiceAccountProvisionFailed MessageID = "token.provision_failed"
+
+	MsgBackendDeploying    MessageID = "backend.deploying"
+	MsgBackendDeployFailed MessageID = "backend.deploy_failed"
+	MsgBackendURLFailed    MessageID = "backend.url_failed"
+	MsgBackendDeployed     MessageID = "backend.deployed"
+	MsgBackendPortForward  MessageID = "backend.port_forward"
+
+	MsgExposeNoHost        MessageID = "expose.no_host"
+	MsgExposing            MessageID = "expose.exposing"
+	MsgExposeServiceFailed MessageID = "expose.failed"
+	MsgExposed             MessageID = "expose.done"
+
+	MsgRBACInvalidServiceAccount MessageID = "rbac.invalid_service_account"
+	MsgRBACRenderFailed          MessageID = "rbac.render_failed"
+	MsgRBACApplying              MessageID = "rbac.applying"
+	MsgRBACApplyFailed           MessageID = "rbac.apply_failed"
+	MsgRBACApplied               MessageID = "rbac.applied"
+	MsgRBACAppliedUnbound        MessageID = "rbac.applied_unbound"
+
+	MsgAuditWriteFailed MessageID = "audit.write_failed"
+
+	MsgLockHeld          MessageID = "lock.held"
+	MsgLockFailed        MessageID = "lock.failed"
+	MsgLockTakenOver     MessageID = "lock.taken_over"
+	MsgLockLost          MessageID = "lock.lost"
+	MsgLockReleaseFailed MessageID = "lock.release_failed"
+
+	MsgUninstallPlanFailed MessageID = "uninstall.plan_failed"
+	MsgUninstallCancelled  MessageID = "uninstall.cancelled"
+	MsgUninstallFailed     MessageID = "uninstall.failed"
+	MsgUninstalled         MessageID = "uninstall.done"
+
+	MsgUpgradesLooking             MessageID = "upgrade.looking"
+	MsgUpgradesListFailed          MessageID = "upgrade.list_failed"
+	MsgUpgradesNone                MessageID = "upgrade.none"
+	MsgUpgradeCancelled            MessageID = "upgrade.cancelled"
+	MsgOperatorUpgrading           MessageID = "upgrade.upgrading"
+	MsgOperatorUpgradeFailed       MessageID = "upgrade.upgrade_failed"
+	MsgOperatorUpgradeApproved     MessageID = "upgrade.approved"
+	MsgCSVWaiting                  MessageID = "upgrade.csv_waiting"
+	MsgOperatorUpgradeNotSucceeded MessageID = "upgrade.not_succeeded"
+	MsgOperatorUpgraded            MessageID = "upgrade.upgraded"
+	MsgUpgradeHeader               MessageID = "upgrade.header"
+	MsgUpgradeConfirm              MessageID = "upgrade.confirm"
+	MsgUpgradeCheckLater           MessageID = "upgrade.check_later"
+
+	MsgKubeStateMetricsUpgrading     MessageID = "upgrade.kube_state_metrics_upgrading"
+	MsgKubeStateMetricsUpgradeFailed MessageID = "upgrade.kube_state_metrics_failed"
+	MsgKubeStateMetricsUpgraded      MessageID = "upgrade.kube_state_metrics_upgraded"
+
+	MsgVersionServerFailed   MessageID = "version.server_failed"
+	MsgVersionOperatorFailed MessageID = "version.operator_failed"
+
+	MsgCertManagerInstalling    MessageID = "cert_manager.installing"
+	MsgCertManagerInstallFailed MessageID = "cert_manager.install_failed"
+	MsgCertIssuerFailed         MessageID = "cert_manager.issuer_failed"
+	MsgCertManagerReady         MessageID = "cert_manager.ready"
+	MsgCertificatesFailed       MessageID = "cert_manager.certificates_failed"
+	MsgCertificatesRequested    MessageID = "cert_manager.certificates_requested"
+
+	MsgHardenedInsecureAPIServer MessageID = "hardening.insecure_api_server"
+	MsgHardenedInsecureTLS       MessageID = "hardening.insecure_tls"
+	MsgNetworkPoliciesApplying   MessageID = "hardening.network_policies_applying"
+	MsgNetworkPoliciesFailed     MessageID = "hardening.network_policies_failed"
+	MsgRotationStarted           MessageID = "hardening.rotation_started"
+	MsgRotationDone              MessageID = "hardening.rotation_done"
+	MsgRotationFailed            MessageID = "hardening.rotation_failed"
+	MsgRotationNotConfigured     MessageID = "hardening.rotation_not_configured"
+	MsgAPIKeyRevokeFailed        MessageID = "hardening.api_key_revoke_failed"
+	MsgAPIKeysNotRevoked         MessageID = "hardening.api_keys_not_revoked"
+	MsgRotationNoAPIKeys         MessageID = "hardening.rotation_no_api_keys"
+	MsgRotationDoneOnce          MessageID = "hardening.rotation_done_once"
+
+	MsgDigestSending          MessageID = "digest.sending"
+	MsgDigestSent             MessageID = "digest.sent"
+	MsgDigestFailed           MessageID = "digest.failed"
+	MsgDigestWebhookBadStatus MessageID = "digest.webhook_bad_status"
+	MsgDigestTitle            MessageID = "digest.title"
+	MsgDigestNothing          MessageID = "digest.nothing"
+	MsgDigestOperatorUpgrades MessageID = "digest.operator_upgrades"
+	MsgDigestFailedBackups    MessageID = "digest.failed_backups"
+	MsgDigestClustersInError  MessageID = "digest.clusters_in_error"
+	MsgDigestCapacity         MessageID = "digest.capacity"
+
+	MsgPortForwarding     MessageID = "port_forward.forwarding"
+	MsgPortForwardLost    MessageID = "port_forward.lost"
+	MsgPortForwardRetry   MessageID = "port_forward.retry"
+	MsgPortForwardFailed  MessageID = "port_forward.failed"
+	MsgPortForwardStopped MessageID = "port_forward.stopped"
+
+	MsgBackupListFailed         MessageID = "backup.list_failed"
+	MsgBackupNone               MessageID = "backup.none"
+	MsgBackupDeleteConfirm      MessageID = "backup.delete_confirm"
+	MsgBackupDeleteFailed       MessageID = "backup.delete_failed"
+	MsgBackupDeleted            MessageID = "backup.deleted"
+	MsgBackupScheduleFailed     MessageID = "backup.schedule_failed"
+	MsgBackupScheduleSet        MessageID = "backup.schedule_set"
+	MsgBackupScheduleRemoved    MessageID = "backup.schedule_removed"
+	MsgBackupScheduleRequired   MessageID = "backup.schedule_required"
+	MsgPITRFailed               MessageID = "backup.pitr_failed"
+	MsgPITREnabled              MessageID = "backup.pitr_enabled"
+	MsgPITRDisabled             MessageID = "backup.pitr_disabled"
+	MsgRestoreTimestampRequired MessageID = "backup.restore_timestamp_required"
+	MsgRestoreInvalidTimestamp  MessageID = "backup.restore_invalid_timestamp"
+	MsgRestoreFailed            MessageID = "backup.restore_failed"
+	MsgRestoreCreated           MessageID = "backup.restore_created"
+	MsgRestoreCreatedPITR       MessageID = "backup.restore_created_pitr"
+	MsgBackupIRSAConfiguring    MessageID = "backup.irsa_configuring"
+	MsgBackupIRSAFailed         MessageID = "backup.irsa_failed"
+
+	MsgDiagnoseCollecting  MessageID = "diagnose.collecting"
+	MsgDiagnoseWriteFailed MessageID = "diagnose.write_failed"
+	MsgDiagnoseWritten     MessageID = "diagnose.written"
+	MsgDiagnoseIncomplete  MessageID = "diagnose.incomplete"
+	MsgDiagnoseUnhealthy   MessageID = "diagnose.unhealthy"
+
+	MsgCleanupModeRequired MessageID = "cleanup.mode_required"
+	MsgCleanupPlanFailed   MessageID = "cleanup.plan_failed"
+	MsgCleanupFailed       MessageID = "cleanup.failed"
+	MsgCleanupDone         MessageID = "cleanup.done"
+)
+
+// messages is the catalog of English texts of user-facing messages.
+var messages = map[MessageID]string{
+	MsgProvisionStarted:     "started provisioning the cluster",
+	MsgEventRecordFailed:    "failed recording the provisioning event: %s",
+	MsgWarningsCheckFailed:  "failed checking the cluster for warnings",
+	MsgWarningsHeader:       "Warnings:",
+	MsgProgressReadFailed:   "failed reading the provisioning progress",
+	MsgProgressRecordFailed: "failed recording the completed %s step, it will be repeated on the next run: %s",
+	MsgStepSkipped:          "Skipping the %s step completed by a previous run, use --force to repeat it",
+	MsgTimingsHeader:        "Install time per phase:",
+	MsgTimingsRecordFailed:  "failed recording the install time per phase: %s",
+	MsgPullSecretsCreating:  "Creating image pull secrets in %s namespace",
+	MsgPullSecretsFailed:    "failed creating image pull secrets in %s namespace",
+	MsgRollbackStarted:      "Installation failed, removing %d resources created by this run",
+	MsgRollbackFailed:       "failed removing the resources created by this run, remove them manually: %s",
+	MsgRollbackDone:         "Removed the resources created by this run",
+
+	MsgOLMInstalling:        "Installing Operator Lifecycle Manager",
+	MsgOLMInstallFailed:     "failed installing OLM",
+	MsgOLMInstalled:         "OLM has been installed",
+	MsgOLMUnknownProfile:    "unknown OLM profile %q, use minikube or production",
+	MsgOLMUnknownComponent:  "unknown OLM component %q",
+	MsgOLMInvalidResources:  "invalid resources of OLM component %s",
+	MsgOLMBuiltIn:           "Using the OLM built into OpenShift",
+	MsgCatalogInstallFailed: "failed installing catalog source %s/%s",
+
+	MsgClusterTypeFailed: "failed detecting the cluster type",
+	MsgOpenShiftDetected: "OpenShift detected, running in compatibility mode",
+
+	MsgMonitoringStarted:            "Started setting up monitoring",
+	MsgMonitoringProvisioned:        "Monitoring using PMM has been provisioned",
+	MsgPMMAccountCreating:           "Creating a new service account in PMM",
+	MsgPMMTokenGenerated:            "New token has been generated",
+	MsgPMMTokenMissing:              "PMM did not return an API key, response status %d",
+	MsgPMMCredentialsSecret:         "Using PMM credentials of the existing secret %s",
+	MsgPMMAPIKeyProvided:            "Using the configured PMM API key, it is not rotated",
+	MsgPMMServiceAccountsMissing:    "PMM does not support service accounts, creating a legacy API key",
+	MsgPMMPasswordReadFailed:        "cannot read the PMM password",
+	MsgPMMBadStatus:                 "PMM responded to %s %s with status %d",
+	MsgMonitoringProvisioning:       "Started provisioning monitoring in k8s cluster",
+	MsgMonitoringProvisionFailed:    "failed provisioning monitoring",
+	MsgMonitoringAlreadyProvisioned: "Monitoring has been provisioned already, use --force to provision it again",
+	MsgMonitoringInstanceInvalid:    "invalid monitoring instance: %s",
+	MsgMonitoringTLSInvalid:         "invalid TLS settings of the monitoring endpoint",
+	MsgMonitoringInvalidResources:   "invalid resources of %s in monitoring.resources",
+	MsgMonitoringInstanceFailed:     "failed creating %s monitoring instance",
+	MsgMonitoringInstanceCreated:    "%s monitoring instance has been created, assign database clusters to it with `db monitoring enable <name> --instance %s`",
+	MsgMonitoringInstancesFailed:    "failed listing monitoring instances",
+	MsgMonitoringInstancesNone:      "No monitoring instances found",
+	MsgMonitoringInstanceDelFailed:  "failed deleting %s monitoring instance",
+	MsgMonitoringInstanceDeleted:    "%s monitoring instance has been deleted",
+	MsgMonitoringVerifying:          "Verifying that metrics reach PMM",
+	MsgMonitoringVerified:           "Metrics are being written to PMM",
+	MsgMonitoringVerifyFailed:       "metrics do not reach PMM",
+	MsgMonitoringNotProvisioned:     "monitoring is not provisioned, install it with --monitoring.enabled",
+	MsgMonitoringAgentNotReady:      "VM agent %s is not ready: %s; check its pods and the VM operator logs",
+	MsgMonitoringNoSamples:          "PMM at %s has no recent metrics of VM agent %s; check that PMM is reachable from the cluster and the agent logs for rejected writes",
+	MsgMonitoringSamplesSkipped:     "Not checking that PMM at %s ingests metrics, the admin credentials or an API key are required",
+	MsgMonitoringNotConfigured:      "the PMM endpoint is required to enable monitoring, set monitoring.pmm.endpoint",
+	MsgMonitoringDisableConfirm:     "Metrics of the cluster are not sent to PMM anymore. Disable monitoring?",
+	MsgMonitoringDisabling:          "Removing the VM agents, their credentials and the monitoring stack",
+	MsgMonitoringRemoveFailed:       "failed disabling monitoring",
+	MsgMonitoringRemoved:            "Monitoring has been disabled, enable it again with `monitoring enable`",
+
+	MsgAlertingProvisioning: "Installing the alerting rules of database clusters",
+	MsgAlertingFailed:       "failed provisioning alerting",
+	MsgAlertingProvisioned:  "Alerting rules have been installed, alerts are sent to %s",
+	MsgAlertingRemoveFailed: "failed removing alerting",
+
+	MsgOperatorsInstallingParallel: "Installing operators in parallel",
+	MsgOperatorInstalling:          "Installing %s operator",
+	MsgOperatorInstallFailed:       "failed installing %s operator",
+	MsgOperatorInstalled:           "%s operator has been installed",
+	MsgOperatorUnknown:             "unknown operator %q, known operators: %s",
+	MsgOperatorUninstallPlanFailed: "failed preparing the uninstallation of %s operator",
+	MsgOperatorUninstallFailed:     "failed uninstalling %s operator",
+	MsgOperatorUninstalled:         "%s operator has been uninstalled",
+	MsgOperatorDataKept:            "CRDs and custom resources of %s operator have been kept, running databases are not managed until the operator is installed again",
+	MsgOperatorPrunePlanFailed:     "failed looking for superseded cluster service versions and failed install plans",
+	MsgOperatorPruneFailed:         "failed removing superseded cluster service versions and failed install plans",
+	MsgOperatorsPruned:             "%d superseded cluster service versions and failed install plans have been removed",
+	MsgOperatorsPruneSkipped:       "could not clean up after the upgrade, run `operator prune` later: %s",
+	MsgOperatorVersionsFailed:      "failed listing versions of %s operator available in the catalog",
+	MsgSchedulingNotConfigured:     "no scheduling constraints are configured, set scheduling.node_selector, scheduling.tolerations or scheduling.affinity",
+	MsgOperatorsScheduleFailed:     "failed applying the scheduling constraints to the operators",
+	MsgOperatorsScheduled:          "scheduling constraints have been applied to %s, OLM rolls out the operator deployments",
+	MsgOperatorsScheduleUnchanged:  "installed operators already have the configured scheduling constraints",
+	MsgProgressResetFailed:         "failed resetting the provisioning progress, pass --force to the next installation: %s",
+
+	MsgLeftoversChecking:     "Checking the cluster for leftovers of previous installations",
+	MsgLeftoversCheckFailed:  "failed checking the cluster for leftovers",
+	MsgLeftoverFound:         "found leftover %s",
+	MsgLeftoversRemoving:     "Removing leftovers of previous installations",
+	MsgLeftoversRemoveFailed: "failed removing leftovers",
+	MsgLeftoversRemoved:      "Leftovers have been removed",
+	MsgLeftoversRemain:       "found %d leftovers of previous installations; remove them manually or re-run with --cleanup_leftovers",
+
+	MsgDBaaSConnecting:       "Generating service account and connecting with DBaaS",
+	MsgKubeconfigFailed:      "failed generating kubeconfig",
+	MsgKubeconfigInvalidData: "kubeconfig data is not valid base64",
+	MsgKubeconfigStdinFailed: "failed reading kubeconfig from stdin",
+	MsgJSONMarshalFailed:     "failed marshaling JSON",
+	MsgDBaaSConnected:        "DBaaS has been connected",
+	MsgDBaaSBadStatus:        "non 200 status code",
+
+	MsgDatabaseCreating:           "Creating %s database cluster",
+	MsgDatabaseCreateFailed:       "failed creating %s database cluster",
+	MsgDatabaseWaitingReady:       "Waiting for %s database cluster to become ready",
+	MsgDatabaseReady:              "%s database cluster is ready",
+	MsgDatabaseRestarting:         "Restarting %s database cluster",
+	MsgDatabaseRestartFailed:      "failed restarting %s database cluster",
+	MsgDatabaseWaitingRestart:     "Waiting for %s database cluster to restart",
+	MsgDatabaseRestarted:          "%s database cluster has been restarted",
+	MsgDatabaseReleaseFailed:      "failed releasing %s database cluster",
+	MsgDatabaseNotManaged:         "%s database cluster has no managed-by markers, nothing to release",
+	MsgDatabaseReleased:           "%s database cluster has been released and is no longer managed by everest",
+	MsgMonitoringEnableFailed:     "failed enabling monitoring of %s database cluster",
+	MsgMonitoringEnabled:          "Monitoring of %s database cluster has been enabled",
+	MsgMonitoringDisableFailed:    "failed disabling monitoring of %s database cluster",
+	MsgMonitoringDisabled:         "Monitoring of %s database cluster has been disabled",
+	MsgMonitoringInstanceAssigned: "%s database cluster reports to %s monitoring instance",
+	MsgDatabaseExpires:            "%s database cluster expires at %s",
+	MsgDatabaseExpired:            "Expired %s database cluster has been deleted",
+	MsgDatabaseExpireFailed:       "failed deleting expired database clusters",
+	MsgDatabaseDeletePlanFailed:   "failed preparing deletion of %s database cluster",
+	MsgDatabaseDeleteFailed:       "failed deleting %s database cluster",
+	MsgDatabaseDeleted:            "%s database cluster has been deleted",
+	MsgDatabaseCheckLater:         "The change has been submitted. Check the status of the cluster later with:\n  kubectl get databasecluster %s -n %s",
+	MsgDatabaseNotReady:           "%s database cluster did not become ready",
+	MsgDatabaseNotRestarted:       "%s database cluster did not restart",
+	MsgRestartStrategyUnknown:     "unknown restart strategy %q, use one of: %s",
+	MsgDatabaseRestartingPod:      "Restarting pod %s (%d/%d)",
+	MsgDatabaseRestartAborted:     "Restart of %s database cluster has been aborted, pods restarted so far keep running. Check the cluster before retrying",
+	MsgDatabaseNameRequired:       "database cluster name is required",
+	MsgUnsupportedEngine:          "unsupported database engine %q",
+	MsgEngineVersionsFailed:       "failed listing versions of %s engine",
+	MsgEngineVersionUnsupported:   "%s version %s is not supported by %s %s, supported versions: %s",
+	MsgEnginesNone:                "No database engine operators are installed",
+	MsgInvalidCPU:                 "invalid CPU",
+	MsgInvalidMemory:              "invalid memory",
+	MsgDiskTooLarge:               "disk size %s exceeds the maximum volume size of %s clusters (%s)",
+	MsgStorageClassFailed:         "failed detecting the default storage class",
+	MsgStorageClassInvalid:        "invalid storage class",
+	MsgStorageClassesFailed:       "failed listing storage classes",
+	MsgStorageClassesNone:         "No storage classes found, database clusters cannot be created without one",
+	MsgPlacementFailed:            "failed checking the placement of %s database cluster",
+	MsgPlacementZones:             "Members of %s database cluster can be spread across %d zones",
+	MsgPlacementNodes:             "Members of %s database cluster can be spread across nodes but not zones",
+	MsgPlacementImpossible:        "%s database cluster has %d members but there are only %d worker nodes, members that cannot get their own node stay pending",
+	MsgPlacementNodesShared:       "%s database cluster has %d members but there are only %d worker nodes, some members share a node",
+	MsgPlacementZonesShared:       "%s database cluster has %d members but the worker nodes span only %d zones, some members share a zone",
+	MsgPlacementUnsatisfiable:     "%s database cluster has %d members but there are only %d failure domains by %s, spread them by another label or create fewer members",
+	MsgPlacementSpreadUnknown:     "unsupported spread %q, use one of: %s",
+	MsgPlacementRequiredNone:      "spreading the pods is required but the spread is none",
+	MsgPlacementApplyFailed:       "failed spreading the pods of %s database cluster",
+	MsgInvalidDisk:                "invalid disk size",
+	MsgManifestParseFailed:        "cannot parse %s",
+	MsgDatabaseScaling:            "Scaling %s database cluster",
+	MsgDatabaseScaled:             "%s database cluster has been scaled",
+	MsgScaleFailed:                "failed scaling %s database cluster",
+	MsgScaleNothing:               "pass the number of nodes or the resources to scale the database cluster to",
+	MsgScaleInvalidSize:           "invalid number of nodes %d",
+	MsgScaleInsufficient:          "the resources added to %s database cluster do not fit into the available resources",
+	MsgResizeSizeRequired:         "pass the disk size to grow the volumes to with --disk",
+	MsgDatabaseResizing:           "Resizing volumes of %s database cluster to %s",
+	MsgResizeFailed:               "failed resizing volumes of %s database cluster",
+	MsgDatabaseWaitingResize:      "Waiting for volumes of %s database cluster to be resized",
+	MsgVolumeNotResized:           "volumes of %s database cluster have not been resized",
+	MsgDatabaseResized:            "Volumes of %s database cluster have been resized to %s",
+	MsgProxyNothing:               "pass the proxy type, replicas, resources or how the proxy is exposed",
+	MsgProxyInvalidReplicas:       "invalid number of proxies %d",
+	MsgProxyExposeUnknown:         "unsupported expose type %q, supported types: %s",
+	MsgProxyExposedToAll:          "%s database cluster is exposed to all clients, restrict them with --source-ranges",
+	MsgProxyConfiguring:           "Configuring the proxy of %s database cluster",
+	MsgProxyFailed:                "failed configuring the proxy of %s database cluster",
+	MsgProxyConfigured:            "Proxy of %s database cluster has been configured",
+	MsgExposeUnknown:              "unsupported expose mode %q, use %s",
+	MsgInternalWithoutExpose:      "--internal requires exposing the database cluster with --expose external",
+	MsgExposeFailed:               "cannot expose %s database cluster",
+	MsgExposedToInternet:          "%s database cluster will be reachable from the internet, restrict the clients with db proxy --source-ranges or pass --internal",
+	MsgCloneSameName:              "the clone needs a name other than %s",
+	MsgDatabaseCloning:            "Cloning %s database cluster into %s",
+	MsgCloneFailed:                "failed cloning %s database cluster into %s",
+	MsgDatabaseCloned:             "%s database cluster has been restored from %s backup of %s",
+	MsgUpdateFileRequired:         "pass the modified DatabaseCluster manifest with --file",
+	MsgUpdateStrategyUnknown:      "unknown update strategy %q, use one of: %s",
+	MsgUpdatePlanFailed:           "failed comparing %s database cluster with the manifest",
+	MsgUpdateNothing:              "%s database cluster is up to date",
+	MsgUpdateChangesHeader:        "The following fields of %s database cluster will be changed:",
+	MsgUpdateWarningsHeader:       "Warning:",
+	MsgUpdateConfirm:              "Apply the changes?",
+	MsgUpdateCancelled:            "Update has been cancelled",
+	MsgDatabaseUpdating:           "Updating %s database cluster with the %s strategy",
+	MsgUpdateFailed:               "failed updating %s database cluster",
+	MsgDatabaseUpdated:            "%s database cluster has been updated",
+	MsgDatabaseNotUpdated:         "%s database cluster did not become ready after the update",
+	MsgDatabasePausing:            "Pausing %s database cluster",
+	MsgDatabasePauseFailed:        "failed pausing %s database cluster",
+	MsgDatabaseWaitingPause:       "Waiting for the pods of %s database cluster to stop",
+	MsgDatabasePaused:             "%s database cluster has been paused, its data is kept until it is resumed or deleted",
+	MsgDatabaseNotPaused:          "%s database cluster did not pause",
+	MsgDatabaseResuming:           "Resuming %s database cluster",
+	MsgDatabaseResumeFailed:       "failed resuming %s database cluster",
+	MsgDatabaseResumed:            "%s database cluster has been resumed",
+	MsgDatabaseNotResumed:         "%s database cluster did not resume",
+	MsgDatabaseCredentialsFailed:  "failed getting credentials of %s database cluster",
+	MsgDatabasePinging:            "Checking that %s database cluster accepts connections",
+	MsgDatabasePingFailed:         "%s database cluster does not accept connections",
+	MsgDatabasePinged:             "%s database cluster accepts connections on pod %s (%s)",
+	MsgDatabasePingedVersion:      "%s database cluster accepts connections on pod %s (%s, version %s)",
+	MsgDatabaseExecFailed:         "failed running the command in %s database cluster",
+	MsgDatabaseExecNoTerminal:     "cannot allocate a terminal, the standard input is not a terminal",
+
+	MsgDeletionCancelled:      "Deletion has been cancelled",
+	MsgDeletionHeader:         "The following resources will be deleted:",
+	MsgDeletionNothing:        "Nothing to delete",
+	MsgDeletionWarningsHeader: "Warning:",
+	MsgDeletionConfirm:        "Proceed with the deletion?",
+	MsgForceRequired:          "the operation is unsafe, use --force to proceed anyway",
+
+	MsgDoctorTopologyFailed:   "failed getting the topology of the worker nodes",
+	MsgDoctorMultiAZ:          "Worker nodes span %d zones, database clusters can be spread across zones",
+	MsgDoctorSingleAZ:         "Worker nodes are not spread across zones, database clusters cannot survive a zone outage",
+	MsgDoctorUpgradeRunning:   "Checking the installation for the upgrade to Kubernetes %s",
+	MsgDoctorUpgradeRunFailed: "failed running upgrade checks",
+	MsgDoctorUpgradeFailed:    "the installation is not ready for the Kubernetes upgrade",
+	MsgDoctorUpgradePassed:    "The installation is ready for the upgrade to Kubernetes %s",
+
+	MsgPreflightRunning:   "Running preflight checks",
+	MsgPreflightRunFailed: "failed running preflight checks",
+	MsgPreflightPassed:    "All preflight checks have passed",
+	MsgPreflightHint:      "hint: %s",
+	MsgPreflightFailed:    "preflight checks failed",
+
+	MsgSnapshotReadFailed:      "cannot read snapshot %s",
+	MsgSnapshotOperatorMissing: "snapshot has no version of %s operator",
+	MsgSnapshotGetFailed:       "failed getting the installation snapshot",
+	MsgSnapshotPinningCatalog:  "Pinning the catalog to %s",
+	MsgSnapshotRecordFailed:    "failed recording the installation snapshot: %s",
+	MsgSnapshotRecorded:        "Installed versions have been recorded in configmap %s/%s",
+
+	MsgJobFileReadFailed: "cannot read %s referenced by the configuration",
+	MsgJobRenderFailed:   "failed rendering the installation job",
+
+	MsgStatusFailed:        "failed getting installation status",
+	MsgStatusCatalog:       "Catalog %s/%s: %s",
+	MsgStatusClusterDomain: "Cluster domain: %s",
+	MsgUnsupportedOutput:   "unsupported output format %q",
+
+	MsgPlanFailed:   "failed comparing the cluster with the configuration",
+	MsgPlanNoChange: "No changes. The cluster matches the configuration.",
+	MsgPlanSummary:  "Plan: %d changes, run the install command to apply them",
+
+	MsgApplyFileRequired:    "pass the specification file with --filename",
+	MsgApplyLoadFailed:      "cannot load specification %s",
+	MsgApplyUnknownOperator: "specification declares unknown operator %q, supported operators: %s",
+	MsgApplyFailed:          "failed applying specification %s",
+	MsgApplyDatabase:        "Database cluster %s: %s",
+	MsgApplyDone:            "The cluster matches specification %s",
+
+	MsgExportUnsupportedFormat:         "unsupported export format %q, use argocd, flux or kustomize",
+	MsgExportRepoRequired:              "pass the Git repository the manifests are committed to with --repo-url",
+	MsgExportCredentialsSecretRequired: "credentials are not exported, create a secret with the PMM credentials and pass it with --monitoring.pmm.credentials_secret",
+	MsgExportFailed:                    "failed exporting manifests to %s",
+	MsgExportWritten:                   "Manifests have been written to %s",
+
+	MsgResourcesFailed:       "failed getting cluster resources",
+	MsgResourcesFit:          "The database cluster fits into the available resources",
+	MsgResourcesInsufficient: "a database cluster of %d nodes does not fit into the available resources",
+
+	MsgConfigValid:       "Configuration %s is valid",
+	MsgConfigValidNoFile: "Configuration is valid, no config file is used",
+
+	MsgServeStarting:    "Starting the API server on %s",
+	MsgServeCacheFailed: "failed starting the informer cache",
+	MsgServeFailed:      "API server failed",
+	MsgServeStopped:     "API server has been stopped",
+
+	MsgControllerStarting:        "Reconciling EverestInstallations in namespace %s every %s",
+	MsgControllerInvalidInterval: "reconcile interval must be positive, got %s",
+	MsgControllerCRDFailed:       "failed installing the EverestInstallation CRD",
+	MsgControllerListFailed:      "failed listing EverestInstallations: %s",
+	MsgControllerDuplicate:       "only one EverestInstallation is supported, %s is reconciled instead",
+	MsgControllerReconciling:     "Reconciling the cluster to EverestInstallation %s",
+	MsgControllerReconciled:      "Cluster matches EverestInstallation %s",
+	MsgControllerReconcileFailed: "failed reconciling EverestInstallation %s: %s",
+	MsgControllerStatusFailed:    "failed updating status of EverestInstallation %s: %s",
+	MsgControllerStopped:         "Controller has been stopped",
+	MsgControllerMetrics:         "Serving metrics on %s",
+	MsgControllerMetricsFailed:   "failed serving metrics: %s",
+	MsgControllerLocked:          "Skipping the reconciliation: %s",
+
+	MsgServiceAccountProvisioning:    "Provisioning %s service account",
+	MsgServiceAccountProvisionFailed: "failed provisioning %s service account",
+
+	MsgBackendDeploying:    "Deploying the Everest backend to namespace %s",
+	MsgBackendDeployFailed: "failed deploying the Everest backend",
+	MsgBackendURLFailed:    "failed getting the address of the Everest backend",
+	MsgBackendDeployed:     "Everest is available at %s",
+	MsgBackendPortForward:  "The service is reachable inside the cluster only, open it from your workstation with `port-forward %s --port %d`",
+
+	MsgExposeNoHost:        "nothing to expose, set backend.host or ingress.pmm.host",
+	MsgExposing:            "Exposing service %s at %s",
+	MsgExposeServiceFailed: "failed exposing service %s",
+	MsgExposed:             "Service %s is available at %s",
+
+	MsgRBACInvalidServiceAccount: "invalid service account %q, use namespace:name",
+	MsgRBACRenderFailed:          "failed rendering the RBAC objects",
+	MsgRBACApplying:              "Applying cluster role %s",
+	MsgRBACApplyFailed:           "failed applying cluster role %s",
+	MsgRBACApplied:               "Cluster role %s has been granted in namespaces %s",
+	MsgRBACAppliedUnbound:        "Cluster role %s has been applied, bind it with --user, --group or --service-account",
+
+	MsgAuditWriteFailed: "changes are not recorded in the audit log, disable it with --no-audit: %s",
+
+	MsgLockHeld:          "the cluster is locked by %s running %s; wait until it has finished or, if it has been stopped, until the lock expires at %s; --force-unlock takes the lock over even from a running command",
+	MsgLockFailed:        "failed acquiring the lock of the cluster",
+	MsgLockTakenOver:     "Took over the lock of the cluster from %s",
+	MsgLockLost:          "lost the lock of the cluster to another run, stopping",
+	MsgLockReleaseFailed: "failed releasing the lock of the cluster, it expires in a minute: %s",
+
+	MsgUninstallPlanFailed: "failed preparing the uninstallation",
+	MsgUninstallCancelled:  "Uninstallation has been cancelled",
+	MsgUninstallFailed:     "failed uninstalling operators",
+	MsgUninstalled:         "Operators have been uninstalled",
+
+	MsgUpgradesLooking:             "Looking for pending operator upgrades",
+	MsgUpgradesListFailed:          "failed listing pending upgrades",
+	MsgUpgradesNone:                "All operators are up to date",
+	MsgUpgradeCancelled:            "Upgrade has been cancelled",
+	MsgOperatorUpgrading:           "Upgrading %s operator",
+	MsgOperatorUpgradeFailed:       "failed upgrading %s operator",
+	MsgOperatorUpgradeApproved:     "%s operator upgrade has been approved",
+	MsgCSVWaiting:                  "Waiting for %s to reach 'Succeeded' phase",
+	MsgOperatorUpgradeNotSucceeded: "%s operator failed to upgrade",
+	MsgOperatorUpgraded:            "%s operator has been upgraded",
+	MsgUpgradeHeader:               "The following components will be upgraded:",
+	MsgUpgradeConfirm:              "Proceed with the upgrade?",
+	MsgUpgradeCheckLater:           "Upgrades are in progress. Check their status later with:\n  kubectl get csv -n %s",
+
+	MsgKubeStateMetricsUpgrading:     "Upgrading kube-state-metrics to %s",
+	MsgKubeStateMetricsUpgradeFailed: "failed upgrading kube-state-metrics",
+	MsgKubeStateMetricsUpgraded:      "kube-state-metrics has been upgraded",
+
+	MsgVersionServerFailed:   "failed getting the Kubernetes version",
+	MsgVersionOperatorFailed: "failed getting the version of %s",
+
+	MsgCertManagerInstalling:    "Installing cert-manager unless it is installed already",
+	MsgCertManagerInstallFailed: "failed installing cert-manager",
+	MsgCertIssuerFailed:         "failed creating the %s certificate issuer",
+	MsgCertManagerReady:         "TLS certificates of database clusters will be issued by %s issuer",
+	MsgCertificatesFailed:       "failed requesting TLS certificates of %s database cluster",
+	MsgCertificatesRequested:    "TLS certificates of %s database cluster have been requested from %s issuer",
+
+	MsgHardenedInsecureAPIServer: "the hardened profile requires verifying the certificate of the API server, remove insecure-skip-tls-verify from the kubeconfig",
+	MsgHardenedInsecureTLS:       "the hardened profile does not allow skipping the verification of the PMM server certificate",
+	MsgNetworkPoliciesApplying:   "Applying network policies to namespace %s",
+	MsgNetworkPoliciesFailed:     "failed applying network policies to namespace %s",
+	MsgRotationStarted:           "Rotating PMM credentials",
+	MsgRotationDone:              "PMM credentials have been rotated, the next rotation is due in %s",
+	MsgRotationFailed:            "failed rotating PMM credentials",
+	MsgRotationNotConfigured:     "PMM credentials cannot be rotated, the PMM endpoint and admin credentials are not configured",
+	MsgAPIKeyRevokeFailed:        "failed revoking PMM API key %s: %s",
+	MsgAPIKeysNotRevoked:         "PMM API keys %v have not been revoked, the PMM admin credentials are not configured",
+	MsgRotationNoAPIKeys:         "monitoring does not use PMM API keys created by the provisioner, credentials of a secret or a configured API key cannot be rotated",
+	MsgRotationDoneOnce:          "PMM credentials have been rotated",
+
+	MsgDigestSending:          "Sending the maintenance digest",
+	MsgDigestSent:             "Maintenance digest has been sent",
+	MsgDigestFailed:           "failed sending the maintenance digest",
+	MsgDigestWebhookBadStatus: "digest webhook %s responded with status %d",
+	MsgDigestTitle:            "Everest maintenance digest of %s",
+	MsgDigestNothing:          "Nothing needs attention.",
+	MsgDigestOperatorUpgrades: "PENDING OPERATOR UPGRADES",
+	MsgDigestFailedBackups:    "FAILED BACKUPS",
+	MsgDigestClustersInError:  "DATABASE CLUSTERS IN ERROR",
+	MsgDigestCapacity:         "CAPACITY WARNINGS",
+
+	MsgPortForwarding:     "Forwarding 127.0.0.1:%d to port %d of pod %s, press Ctrl+C to stop",
+	MsgPortForwardLost:    "connection to pod %s lost: %s, reconnecting in %s",
+	MsgPortForwardRetry:   "cannot forward port %d: %s, retrying in %s",
+	MsgPortForwardFailed:  "failed forwarding %s service",
+	MsgPortForwardStopped: "Port forwarding has been stopped",
+
+	MsgBackupListFailed:         "failed listing database cluster backups",
+	MsgBackupNone:               "No database cluster backups found",
+	MsgBackupDeleteConfirm:      "Delete %s backup? It is removed from the backup storage",
+	MsgBackupDeleteFailed:       "failed deleting %s backup",
+	MsgBackupDeleted:            "%s backup has been deleted",
+	MsgBackupScheduleFailed:     "failed updating backup schedules of %s database cluster",
+	MsgBackupScheduleSet:        "%s backup schedule of %s database cluster has been set",
+	MsgBackupScheduleRemoved:    "%s backup schedule has been removed from %s database cluster, existing backups are kept",
+	MsgBackupScheduleRequired:   "pass the cron schedule and the backup storage with --cron and --storage",
+	MsgPITRFailed:               "failed configuring point-in-time recovery of %s database cluster",
+	MsgPITREnabled:              "Point-in-time recovery of %s database cluster has been enabled, logs are uploaded to %s storage",
+	MsgPITRDisabled:             "Point-in-time recovery of %s database cluster has been disabled",
+	MsgRestoreTimestampRequired: "--pitr requires the point in time to restore to with --timestamp",
+	MsgRestoreInvalidTimestamp:  "invalid timestamp %q, use RFC 3339 or YYYY-MM-DD hh:mm:ss in UTC",
+	MsgRestoreFailed:            "failed restoring %s database cluster",
+	MsgRestoreCreated:           "Restoring %s database cluster from %s backup, check the progress with:\n  kubectl get databaseclusterrestore %s -n %s",
+	MsgRestoreCreatedPITR:       "Restoring %s database cluster from %s backup to %s, check the progress with:\n  kubectl get databaseclusterrestore %s -n %s",
+	MsgBackupIRSAConfiguring:    "Granting IAM role %s to service accounts %s for backups",
+	MsgBackupIRSAFailed:         "failed configuring IAM roles for service accounts in %s namespace",
+
+	MsgDiagnoseCollecting:  "Collecting diagnostics of the operators in %s namespace",
+	MsgDiagnoseWriteFailed: "failed writing diagnostics to %s",
+	MsgDiagnoseWritten:     "Diagnostics have been written to %s, attach the file to the support ticket",
+	MsgDiagnoseIncomplete:  "%d parts of the diagnostics could not be collected, see the errors in the report",
+	MsgDiagnoseUnhealthy:   "Some operators are not healthy, see the deployments and cluster service versions in the report",
+
+	MsgCleanupModeRequired: "nothing to clean up, pass --orphans to remove resources created by the provisioner which are not used anymore",
+	MsgCleanupPlanFailed:   "failed looking for orphaned resources",
+	MsgCleanupFailed:       "failed removing orphaned resources",
+	MsgCleanupDone:         "%d orphaned resources have been removed",
+}
+
+// Message returns the text of the message formatted with the arguments.
+func Message(id MessageID, args ...interface{}) string {
+	format, ok := messages[id]
+	if !ok {
+		format = string(id)
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Error is an error identified by a message ID.
+type Error struct {
+	ID   MessageID
+	Args []interface{}
+	Err  error
+}
+
+func newError(id MessageID, err error, args ...interface{}) *Error {
+	return &Error{ID: id, Args: args, Err: err}
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	msg := Message(e.ID, e.Args...)
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is an Error with the same message ID.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.ID == e.ID
+}
+
+// MessageIDOf returns the message ID of the first Error in the chain of err.
+func MessageIDOf(err error) (MessageID, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.ID, true
+	}
+	return "", false
+}
+
+func (c *CLI) logInfo(id MessageID, args ...interface{}) {
+	c.l.WithField("message_id", id).Info(Message(id, args...))
+}
+
+func (c *CLI) logWarn(id MessageID, args ...interface{}) {
+	c.l.WithField("message_id", id).Warn(Message(id, args...))
+}
+
+func (c *CLI) logError(id MessageID, args ...interface{}) {
+	c.l.WithField("message_id", id).Error(Message(id, args...))
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageIDOf(t *testing.T) {
+	err := fmt.Errorf("provisioning: %w", newError(MsgUnsupportedEngine, nil, "pg"))
+	id, ok := MessageIDOf(err)
+	assert.True(t, ok)
+	assert.Equal(t, MsgUnsupportedEngine, id)
+	assert.Equal(t, `provisioning: unsupported database engine "pg"`, err.Error())
+
+	_, ok = MessageIDOf(errors.New("plain error"))
+	assert.False(t, ok)
+}
+
+func TestErrorIs(t *testing.T) {
+	assert.ErrorIs(t, newError(MsgPreflightFailed, nil), ErrPreflightFailed)
+	assert.NotErrorIs(t, newError(MsgForceRequired, nil), ErrPreflightFailed)
+}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// MonitoringInstanceOptions holds parameters of a new monitoring instance.
+type MonitoringInstanceOptions struct {
+	Name     string
+	URL      string
+	Username string
+	Password string
+	// TLS configures the connection to the PMM server.
+	TLS config.TLSConfig
+}
+
+func (o MonitoringInstanceOptions) validate() error {
+	// The name is a part of the names of the secret and the VM agent of the instance.
+	if msgs := validation.IsDNS1123Label(o.Name); len(msgs) != 0 {
+		return newError(MsgMonitoringInstanceInvalid, nil, fmt.Sprintf("name %q: %s", o.Name, msgs[0]))
+	}
+	if u, err := url.Parse(o.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		return newError(MsgMonitoringInstanceInvalid, nil, fmt.Sprintf("URL %q must be absolute, e.g. https://pmm.example.com", o.URL))
+	}
+	if o.Username == "" || o.Password == "" {
+		return newError(MsgMonitoringInstanceInvalid, nil, "username and password are required")
+	}
+	return nil
+}
+
+// CreateMonitoringInstance creates a named PMM server database clusters can report to.
+func (c *CLI) CreateMonitoringInstance(ctx context.Context, opts MonitoringInstanceOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "monitoring create")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if c.config.Hardened() && opts.TLS.InsecureSkipVerify {
+		return newError(MsgHardenedInsecureTLS, nil)
+	}
+	certs, err := loadMonitoringTLS(opts.TLS)
+	if err != nil {
+		return err
+	}
+	err = c.kubeClient.CreateMonitoringInstance(ctx, kubernetes.MonitoringInstance{
+		Name:     opts.Name,
+		URL:      opts.URL,
+		Username: opts.Username,
+		Password: opts.Password,
+		TLS:      certs,
+	})
+	if err != nil {
+		c.logError(MsgMonitoringInstanceFailed, opts.Name)
+		return err
+	}
+	c.logInfo(MsgMonitoringInstanceCreated, opts.Name, opts.Name)
+	return nil
+}
+
+// ListMonitoringInstances prints the monitoring instances in the given output format.
+func (c *CLI) ListMonitoringInstances(ctx context.Context, output string) error {
+	if output != OutputText && output != OutputJSON {
+		return newError(MsgUnsupportedOutput, nil, output)
+	}
+	instances, err := c.kubeClient.ListMonitoringInstances(ctx)
+	if err != nil {
+		c.logError(MsgMonitoringInstancesFailed)
+		return err
+	}
+	if output == OutputJSON {
+		if instances == nil {
+			instances = []kubernetes.MonitoringInstance{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(instances)
+	}
+	if len(instances) == 0 {
+		fmt.Println(Message(MsgMonitoringInstancesNone))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tURL\tUSERNAME")
+	for _, instance := range instances {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", instance.Name, instance.URL, instance.Username)
+	}
+	return w.Flush()
+}
+
+// DeleteMonitoringInstance removes a monitoring instance no database cluster reports to.
+func (c *CLI) DeleteMonitoringInstance(ctx context.Context, name string) error {
+	ctx, unlock, err := c.acquireLock(ctx, "monitoring delete")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := c.kubeClient.DeleteMonitoringInstance(ctx, name); err != nil {
+		c.logError(MsgMonitoringInstanceDelFailed, name)
+		return err
+	}
+	c.logInfo(MsgMonitoringInstanceDeleted, name)
+	return nil
+}
+
+// loadMonitoringTLS reads the certificate files of a monitoring endpoint.
+func loadMonitoringTLS(cfg config.TLSConfig) (kubernetes.MonitoringTLS, error) {
+	t := kubernetes.MonitoringTLS{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if (cfg.Cert == "") != (cfg.Key == "") {
+		return t, newError(MsgMonitoringTLSInvalid, errors.New("cert and key must be set together"))
+	}
+	files := []struct {
+		path string
+		data *[]byte
+	}{{cfg.CA, &t.CA}, {cfg.Cert, &t.Cert}, {cfg.Key, &t.Key}}
+	for _, f := range files {
+		if f.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			return t, newError(MsgMonitoringTLSInvalid, err)
+		}
+		*f.data = data
+	}
+	if _, err := monitoringTLSConfig(t); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+// monitoringTLSConfig returns the TLS configuration of the connections to a monitoring endpoint.
+func monitoringTLSConfig(t kubernetes.MonitoringTLS) (*tls.Config, error) {
+	cfg := &tls.Config{InsecureSkipVerify: t.InsecureSkipVerify} //nolint:gosec
+	if len(t.CA) != 0 {
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(t.CA) {
+			return nil, newError(MsgMonitoringTLSInvalid, errors.New("no PEM encoded certificates found in the CA file"))
+		}
+	}
+	if len(t.Cert) != 0 {
+		cert, err := tls.X509KeyPair(t.Cert, t.Key)
+		if err != nil {
+			return nil, newError(MsgMonitoringTLSInvalid, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// monitoringHTTPClient returns a client of a monitoring endpoint API.
+func (c *CLI) monitoringHTTPClient(t kubernetes.MonitoringTLS) (*http.Client, error) {
+	cfg, err := monitoringTLSConfig(t)
+	if err != nil {
+		return nil, err
+	}
+	return c.http.Client(cfg), nil
+}
+
+// monitoringResources returns the configured resources of the VM agents and kube-state-metrics.
+func monitoringResources(cfg config.MonitoringResourcesConfig) (kubernetes.MonitoringResources, error) {
+	vmagent, err := resourceRequirements(cfg.VMAgent)
+	if err != nil {
+		return kubernetes.MonitoringResources{}, newError(MsgMonitoringInvalidResources, err, "vmagent")
+	}
+	ksm, err := resourceRequirements(cfg.KubeStateMetrics)
+	if err != nil {
+		return kubernetes.MonitoringResources{}, newError(MsgMonitoringInvalidResources, err, "kube-state-metrics")
+	}
+	return kubernetes.MonitoringResources{VMAgent: vmagent, KubeStateMetrics: ksm}, nil
+}
+
+// scrapeOptions returns the configured customization of the scraping of the VM agents.
+func scrapeOptions(cfg config.ScrapeConfig) kubernetes.ScrapeOptions {
+	return kubernetes.ScrapeOptions{
+		Interval:             cfg.Interval,
+		ServiceScrapeLabels:  cfg.ServiceScrapeSelector,
+		PodScrapeLabels:      cfg.PodScrapeSelector,
+		ExternalLabels:       cfg.ExternalLabels,
+		MemoryAllowedPercent: cfg.MemoryAllowedPercent,
+	}
+}
+
+func resourceRequirements(cfg config.ResourcesConfig) (corev1.ResourceRequirements, error) {
+	requests, err := resourceList(cfg.Requests)
+	if err != nil {
+		return corev1.ResourceRequirements{}, err
+	}
+	limits, err := resourceList(cfg.Limits)
+	if err != nil {
+		return corev1.ResourceRequirements{}, err
+	}
+	return corev1.ResourceRequirements{Requests: requests, Limits: limits}, nil
+}
+
+// resourceList parses the configured quantities. It returns nil if none is set.
+func resourceList(cfg config.ResourceListConfig) (corev1.ResourceList, error) {
+	var list corev1.ResourceList
+	for name, value := range map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    cfg.CPU,
+		corev1.ResourceMemory: cfg.Memory,
+	} {
+		if value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, err
+		}
+		if list == nil {
+			list = corev1.ResourceList{}
+		}
+		list[name] = q
+	}
+	return list, nil
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// EnableMonitoring provisions monitoring with the PMM server of the configuration after it
+// has been disabled or if it was not enabled on installation. It is skipped if monitoring
+// is provisioned already unless forced.
+func (c *CLI) EnableMonitoring(ctx context.Context) error {
+	ctx, unlock, err := c.acquireLock(ctx, "monitoring enable")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	pmm := c.config.Monitoring.PMM
+	if pmm == nil || pmm.Endpoint == "" {
+		return newError(MsgMonitoringNotConfigured, nil)
+	}
+	if err := c.provisionMonitoring(ctx); err != nil {
+		return err
+	}
+	c.completeStep(ctx, kubernetes.StepMonitoring)
+	return nil
+}
+
+// DisableMonitoring removes the VM agents writing metrics to PMM, the secrets with their
+// credentials and the monitoring stack, and revokes the PMM API keys created for them.
+// The keys are kept if the PMM admin credentials are not configured.
+func (c *CLI) DisableMonitoring(ctx context.Context, opts ConfirmOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "monitoring disable")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !opts.Yes {
+		ok, err := confirm(Message(MsgMonitoringDisableConfirm))
+		if err != nil || !ok {
+			return err
+		}
+	}
+	c.logInfo(MsgMonitoringDisabling)
+	apiKeys, err := c.kubeClient.DisableMonitoring(ctx)
+	if err != nil {
+		c.logError(MsgMonitoringRemoveFailed)
+		return err
+	}
+	c.forgetProvisionSteps(ctx, kubernetes.StepMonitoring)
+	if len(apiKeys) != 0 {
+		pmm := c.config.Monitoring.PMM
+		if pmm == nil || pmm.Endpoint == "" || pmm.Username == "" || pmm.Password == "" {
+			c.logWarn(MsgAPIKeysNotRevoked, apiKeys)
+		} else {
+			certs, err := loadMonitoringTLS(pmm.TLS)
+			if err != nil {
+				return err
+			}
+			c.revokeAPIKeys(apiKeys, certs)
+		}
+	}
+	c.logInfo(MsgMonitoringRemoved)
+	return nil
+}
+
+// reconcileAlerting provisions alerting if monitoring.alerts is enabled and removes it otherwise.
+// Alerting queries PMM with the credentials of the VM agent, so it is reconciled every time the
+// agent is replaced.
+func (c *CLI) reconcileAlerting(ctx context.Context) error {
+	alerts := c.config.Monitoring.Alerts
+	if !alerts.Enabled {
+		if err := c.kubeClient.RemoveAlerting(ctx); err != nil {
+			c.logError(MsgAlertingRemoveFailed)
+			return err
+		}
+		return nil
+	}
+	c.logInfo(MsgAlertingProvisioning)
+	warnings, err := c.kubeClient.ProvisionAlerting(ctx, kubernetes.AlertingOptions{
+		AlertmanagerURLs:   alerts.AlertmanagerURLs,
+		EvaluationInterval: alerts.EvaluationInterval,
+	})
+	c.warnings.Merge(warnings)
+	if err != nil {
+		c.logError(MsgAlertingFailed)
+		return err
+	}
+	receivers := strings.Join(alerts.AlertmanagerURLs, ", ")
+	if receivers == "" {
+		receivers = c.config.Monitoring.PMM.Endpoint
+	}
+	c.logInfo(MsgAlertingProvisioned, receivers)
+	return nil
+}
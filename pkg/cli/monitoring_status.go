@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+const (
+	// monitoringVerifyTimeout bounds how long provisioning waits for metrics to reach PMM.
+	monitoringVerifyTimeout = 5 * time.Minute
+	// monitoringVerifyInterval is the time between checks of the monitoring verification.
+	monitoringVerifyInterval = 10 * time.Second
+)
+
+// MonitoringAgentStatus is the state of a VM agent and whether PMM ingests its metrics.
+type MonitoringAgentStatus struct {
+	kubernetes.VMAgentStatus
+	// Ingesting is nil if PMM was not queried because the agent is not ready,
+	// writes to another server or there are no credentials to query it with.
+	Ingesting *bool `json:"ingesting,omitempty"`
+}
+
+// err returns why metrics of the agent do not reach PMM or nil if they do or it is unknown.
+func (s MonitoringAgentStatus) err() error {
+	if !s.Ready {
+		return newError(MsgMonitoringAgentNotReady, nil, s.Name, s.Problem)
+	}
+	if s.Ingesting != nil && !*s.Ingesting {
+		return newError(MsgMonitoringNoSamples, nil, s.URL, s.Name)
+	}
+	return nil
+}
+
+// VerifyMonitoring waits until the VM agents are ready and PMM has ingested recent samples
+// of them. The error of the last check is returned if they do not within the timeout.
+func (c *CLI) VerifyMonitoring(ctx context.Context, timeout time.Duration) error {
+	c.logInfo(MsgMonitoringVerifying)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(monitoringVerifyInterval)
+	defer ticker.Stop()
+	for {
+		statuses, err := c.checkMonitoring(ctx)
+		if err == nil {
+			err = monitoringError(statuses)
+		}
+		if err == nil {
+			c.logInfo(MsgMonitoringVerified)
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			c.logError(MsgMonitoringVerifyFailed)
+			return err
+		}
+	}
+}
+
+// MonitoringStatus prints the state of the VM agents and whether PMM ingests their metrics in
+// the given output format. An error is returned if metrics of an agent do not reach PMM.
+func (c *CLI) MonitoringStatus(ctx context.Context, output string) error {
+	if output != OutputText && output != OutputJSON {
+		return newError(MsgUnsupportedOutput, nil, output)
+	}
+	statuses, err := c.checkMonitoring(ctx)
+	if err != nil {
+		return err
+	}
+	if output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(statuses); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "AGENT\tURL\tREADY\tINGESTING\tPROBLEM")
+		for _, s := range statuses {
+			ingesting := "unknown"
+			if s.Ingesting != nil {
+				ingesting = fmt.Sprint(*s.Ingesting)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\n", s.Name, s.URL, s.Ready, ingesting, s.Problem)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return monitoringError(statuses)
+}
+
+// monitoringError returns the error of the first agent whose metrics do not reach PMM.
+func monitoringError(statuses []MonitoringAgentStatus) error {
+	for _, s := range statuses {
+		if err := s.err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkMonitoring returns the state of the VM agents. PMM of the configuration is queried
+// for samples of the ready agents writing to it if there are credentials to query it with.
+func (c *CLI) checkMonitoring(ctx context.Context) ([]MonitoringAgentStatus, error) {
+	agents, err := c.kubeClient.VerifyMonitoring(ctx)
+	if err != nil {
+		if errors.Is(err, kubernetes.ErrMonitoringNotProvisioned) {
+			return nil, newError(MsgMonitoringNotProvisioned, nil)
+		}
+		return nil, err
+	}
+	statuses := make([]MonitoringAgentStatus, 0, len(agents))
+	for _, agent := range agents {
+		statuses = append(statuses, MonitoringAgentStatus{VMAgentStatus: agent})
+	}
+
+	pmm := c.config.Monitoring.PMM
+	if pmm == nil || pmm.Endpoint == "" {
+		return statuses, nil
+	}
+	if pmm.APIKey == "" && (pmm.Username == "" || pmm.Password == "") {
+		c.logWarn(MsgMonitoringSamplesSkipped, pmm.Endpoint)
+		return statuses, nil
+	}
+	certs, err := loadMonitoringTLS(pmm.TLS)
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.monitoringHTTPClient(certs)
+	if err != nil {
+		return nil, err
+	}
+	for i, s := range statuses {
+		if !s.Ready || !strings.HasPrefix(s.URL, strings.TrimSuffix(pmm.Endpoint, "/")+"/") {
+			continue
+		}
+		ingesting, err := c.pmmIngesting(client, s.Pods)
+		if err != nil {
+			return nil, err
+		}
+		statuses[i].Ingesting = &ingesting
+	}
+	return statuses, nil
+}
+
+// pmmIngesting returns true if PMM has recent samples of the pods. kube-state-metrics reports
+// all pods, including the ones of the VM agent, and is scraped in the selective mode too,
+// so its samples of the agent pods show that metrics of this cluster reach PMM.
+func (c *CLI) pmmIngesting(client *http.Client, pods []string) (bool, error) {
+	query := fmt.Sprintf(`kube_pod_info{pod=~%q}`, strings.Join(pods, "|"))
+	endpoint := fmt.Sprintf("%s/victoriametrics/api/v1/query?query=%s", c.config.Monitoring.PMM.Endpoint, url.QueryEscape(query))
+	var result struct {
+		Data struct {
+			Result []json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	status, err := c.pmmJSONRequest(client, http.MethodGet, endpoint, c.config.Monitoring.PMM.APIKey, nil, &result)
+	if err != nil {
+		return false, err
+	}
+	if status != http.StatusOK {
+		return false, newError(MsgPMMBadStatus, nil, http.MethodGet, endpoint, status)
+	}
+	return len(result.Data.Result) != 0, nil
+}
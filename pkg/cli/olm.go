@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// olmTuning returns the tuning of OLM components from the profile and the
+// component overrides of the configuration.
+func (c *CLI) olmTuning() (kubernetes.OLMTuning, error) {
+	tuning := kubernetes.OLMTuning{}
+	if profile := c.config.OLM.Profile; profile != "" {
+		preset, ok := kubernetes.OLMTuningPresets[profile]
+		if !ok {
+			return nil, newError(MsgOLMUnknownProfile, nil, profile)
+		}
+		for name, t := range preset {
+			tuning[name] = t
+		}
+	}
+	for name, override := range c.config.OLM.Components {
+		switch name {
+		case kubernetes.OLMOperatorComponent, kubernetes.CatalogOperatorComponent, kubernetes.PackageServerComponent:
+		default:
+			return nil, newError(MsgOLMUnknownComponent, nil, name)
+		}
+		t := tuning[name]
+		if override.Replicas != nil {
+			t.Replicas = override.Replicas
+		}
+		requests := corev1.ResourceList{}
+		for res, value := range t.Requests {
+			requests[res] = value
+		}
+		for res, value := range map[corev1.ResourceName]string{
+			corev1.ResourceCPU:    override.CPU,
+			corev1.ResourceMemory: override.Memory,
+		} {
+			if value == "" {
+				continue
+			}
+			q, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, newError(MsgOLMInvalidResources, err, name)
+			}
+			requests[res] = q
+		}
+		t.Requests = requests
+		tuning[name] = t
+	}
+	return tuning, nil
+}
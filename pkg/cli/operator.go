@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// OperatorUninstallOptions holds parameters of the operator uninstall command.
+type OperatorUninstallOptions struct {
+	ConfirmOptions
+	// KeepData keeps the CRDs and custom resources of the operator so running databases survive.
+	KeepData bool
+}
+
+// UninstallOperator removes a single operator installed by the provisioner.
+func (c *CLI) UninstallOperator(ctx context.Context, name string, opts OperatorUninstallOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "operator uninstall")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !knownOperator(name) {
+		return newError(MsgOperatorUnknown, nil, name, strings.Join(operators, ", "))
+	}
+	plan, err := c.kubeClient.PlanOperatorUninstall(ctx, namespace, name, !opts.KeepData)
+	if err != nil {
+		c.logError(MsgOperatorUninstallPlanFailed, name)
+		return err
+	}
+	ok, err := confirmDeletion(plan, opts.ConfirmOptions)
+	if err != nil || !ok {
+		if err == nil {
+			c.logInfo(MsgUninstallCancelled)
+		}
+		return err
+	}
+	if err := c.kubeClient.ExecuteDeletionPlan(ctx, plan); err != nil {
+		c.logError(MsgOperatorUninstallFailed, name)
+		return err
+	}
+	c.forgetProvisionSteps(ctx, kubernetes.OperatorStep(name))
+	c.logInfo(MsgOperatorUninstalled, name)
+	if opts.KeepData {
+		c.logInfo(MsgOperatorDataKept, name)
+	}
+	return nil
+}
+
+// PruneOperators removes cluster service versions superseded by upgrades and failed install plans.
+func (c *CLI) PruneOperators(ctx context.Context, opts ConfirmOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "operator prune")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	plan, err := c.kubeClient.PlanOperatorPrune(ctx, namespace, operators)
+	if err != nil {
+		c.logError(MsgOperatorPrunePlanFailed)
+		return err
+	}
+	ok, err := confirmDeletion(plan, opts)
+	if err != nil || !ok {
+		if err == nil && len(plan.Items) != 0 {
+			c.logInfo(MsgDeletionCancelled)
+		}
+		return err
+	}
+	if err := c.kubeClient.ExecuteDeletionPlan(ctx, plan); err != nil {
+		c.logError(MsgOperatorPruneFailed)
+		return err
+	}
+	c.logInfo(MsgOperatorsPruned, len(plan.Items))
+	return nil
+}
+
+// OperatorVersions prints the installed and the available versions per channel of the
+// operator, or of all operators installed by the provisioner if name is empty.
+func (c *CLI) OperatorVersions(ctx context.Context, name, output string) error {
+	if output != OutputText && output != OutputJSON {
+		return newError(MsgUnsupportedOutput, nil, output)
+	}
+	names := operators
+	if name != "" {
+		if !knownOperator(name) {
+			return newError(MsgOperatorUnknown, nil, name, strings.Join(operators, ", "))
+		}
+		names = []string{name}
+	}
+	all := make([]*kubernetes.OperatorVersions, 0, len(names))
+	for _, op := range names {
+		versions, err := c.kubeClient.ListAvailableOperatorVersions(ctx, op, kubernetes.OperatorVersionsOptions{
+			Namespace:              namespace,
+			CatalogSource:          c.catalog,
+			CatalogSourceNamespace: c.catalogNamespace,
+		})
+		if err != nil {
+			return newError(MsgOperatorVersionsFailed, err, op)
+		}
+		all = append(all, versions)
+	}
+	if output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(all)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OPERATOR\tCHANNEL\tINSTALLED\tLATEST\tAVAILABLE")
+	for _, versions := range all {
+		for _, ch := range versions.Channels {
+			channel, installed := ch.Name, "-"
+			if ch.Name == versions.DefaultChannel {
+				channel += " (default)"
+			}
+			if ch.Name == versions.InstalledChannel && versions.InstalledVersion != "" {
+				installed = versions.InstalledVersion
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", versions.Name, channel, installed, ch.Latest, strings.Join(ch.Versions, ", "))
+		}
+	}
+	return w.Flush()
+}
+
+// pruneAfterUpgrade cleans up leftovers of finished upgrades. Failing to do so does not fail the upgrade.
+func (c *CLI) pruneAfterUpgrade(ctx context.Context) {
+	plan, err := c.kubeClient.PruneOperators(ctx, namespace, operators)
+	if err != nil {
+		c.logWarn(MsgOperatorsPruneSkipped, err)
+		return
+	}
+	if len(plan.Items) != 0 {
+		c.logInfo(MsgOperatorsPruned, len(plan.Items))
+	}
+}
+
+// forgetProvisionSteps makes the next installation repeat the steps undone by an uninstallation.
+func (c *CLI) forgetProvisionSteps(ctx context.Context, steps ...string) {
+	if err := c.kubeClient.ForgetProvisionSteps(ctx, namespace, steps...); err != nil {
+		c.logWarn(MsgProgressResetFailed, err)
+	}
+}
+
+func knownOperator(name string) bool {
+	for _, op := range operators {
+		if op == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// Plan prints the changes ProvisionCluster would make to the cluster in the given output format.
+// Nothing is changed.
+func (c *CLI) Plan(ctx context.Context, output string) error {
+	if output != OutputText && output != OutputJSON {
+		return newError(MsgUnsupportedOutput, nil, output)
+	}
+	if err := c.detectOpenShift(ctx); err != nil {
+		return err
+	}
+	plan, err := c.kubeClient.PlanProvisioning(ctx, c.desiredState())
+	if err != nil {
+		c.logError(MsgPlanFailed)
+		return err
+	}
+	if output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	}
+	printPlan(plan)
+	return nil
+}
+
+// desiredState returns the state ProvisionCluster converges the cluster to.
+func (c *CLI) desiredState() kubernetes.DesiredState {
+	reqs := operatorInstallRequests(c.catalog, c.catalogNamespace, c.config.OperatorChannels)
+	c.pinOperatorVersions(reqs)
+	desired := kubernetes.DesiredState{
+		CatalogNamespace: c.catalogNamespace,
+		Catalog:          c.catalog,
+		CatalogImage:     c.config.Catalog.Image,
+		Operators:        reqs,
+	}
+	if c.snapshot != nil {
+		desired.CatalogImage = c.snapshot.Catalog.Image
+	}
+	if c.config.Monitoring.Enabled {
+		desired.MonitoringEndpoint = c.config.Monitoring.PMM.Endpoint
+	}
+	return desired
+}
+
+func printPlan(plan *kubernetes.ProvisionPlan) {
+	if len(plan.Changes) == 0 {
+		fmt.Println(Message(MsgPlanNoChange))
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tKIND\tNAME\tCURRENT\tDESIRED\tREASON")
+	for _, change := range plan.Changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", change.Action, change.Kind, change.Name,
+			valueOrDash(change.Current), valueOrDash(change.Desired), change.Reason)
+	}
+	w.Flush()
+	fmt.Printf("\n%s\n", Message(MsgPlanSummary, len(plan.Changes)))
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// errServiceAccountsUnsupported is returned by the service account requests if the Grafana
+// of PMM predates service accounts. Legacy API keys are used instead.
+var errServiceAccountsUnsupported = errors.New("PMM does not support service accounts")
+
+// createServiceAccountToken creates a service account with the Admin role and a token of it,
+// both named name.
+func (c *CLI) createServiceAccountToken(client *http.Client, name, token string) (string, error) {
+	var account struct {
+		ID int64 `json:"id"`
+	}
+	endpoint := fmt.Sprintf("%s/graph/api/serviceaccounts", c.config.Monitoring.PMM.Endpoint)
+	status, err := c.pmmJSONRequest(client, http.MethodPost, endpoint, token, map[string]interface{}{
+		"name":       name,
+		"role":       "Admin",
+		"isDisabled": false,
+	}, &account)
+	if err != nil {
+		return "", err
+	}
+	if status == http.StatusNotFound {
+		return "", errServiceAccountsUnsupported
+	}
+	if status != http.StatusOK && status != http.StatusCreated {
+		return "", newError(MsgPMMBadStatus, nil, http.MethodPost, endpoint, status)
+	}
+
+	var key struct {
+		Key string `json:"key"`
+	}
+	endpoint = fmt.Sprintf("%s/%d/tokens", endpoint, account.ID)
+	status, err = c.pmmJSONRequest(client, http.MethodPost, endpoint, token, map[string]string{"name": name}, &key)
+	if err != nil {
+		return "", err
+	}
+	if key.Key == "" {
+		return "", newError(MsgPMMTokenMissing, nil, status)
+	}
+	return key.Key, nil
+}
+
+// createAPIKey creates a legacy API key with the Admin role.
+func (c *CLI) createAPIKey(client *http.Client, name, token string) (string, error) {
+	var key struct {
+		Key string `json:"key"`
+	}
+	endpoint := fmt.Sprintf("%s/graph/api/auth/keys", c.config.Monitoring.PMM.Endpoint)
+	status, err := c.pmmJSONRequest(client, http.MethodPost, endpoint, token, map[string]string{
+		"name": name,
+		"role": "Admin",
+	}, &key)
+	if err != nil {
+		return "", err
+	}
+	if key.Key == "" {
+		return "", newError(MsgPMMTokenMissing, nil, status)
+	}
+	return key.Key, nil
+}
+
+// pmmJSONRequest sends body as JSON to the PMM API, if it is set, and decodes a successful
+// response into out. Requests are authenticated with the bearer token if it is set or the
+// admin credentials. The status of the response is returned; failed ones are not decoded.
+func (c *CLI) pmmJSONRequest(client *http.Client, method, endpoint, token string, body, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		c.l.WithField("request", string(b)).WithField("url", endpoint).Debug("Sending PMM request")
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	if token == "" {
+		req.SetBasicAuth(c.config.Monitoring.PMM.Username, c.config.Monitoring.PMM.Password)
+	} else {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	c.l.WithField("status", resp.StatusCode).Debug("PMM response")
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return resp.StatusCode, nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
+
+// deleteServiceAccount deletes the service accounts with the given name and their tokens.
+func (c *CLI) deleteServiceAccount(client *http.Client, name string) error {
+	endpoint := fmt.Sprintf("%s/graph/api/serviceaccounts", c.config.Monitoring.PMM.Endpoint)
+	search := fmt.Sprintf("%s/search?query=%s", endpoint, url.QueryEscape(name))
+	var result struct {
+		ServiceAccounts []struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		} `json:"serviceAccounts"`
+	}
+	status, err := c.pmmJSONRequest(client, http.MethodGet, search, "", nil, &result)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return errServiceAccountsUnsupported
+	default:
+		return newError(MsgPMMBadStatus, nil, http.MethodGet, search, status)
+	}
+	for _, account := range result.ServiceAccounts {
+		if account.Name != name {
+			continue
+		}
+		resp, err := c.pmmRequest(client, http.MethodDelete, fmt.Sprintf("%s/%d", endpoint, account.ID))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+	}
+	return nil
+}
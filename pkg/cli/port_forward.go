@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// PortForwardOptions holds parameters of the port-forward command.
+type PortForwardOptions struct {
+	Namespace string
+	Service   string
+	Port      int
+	LocalPort int
+}
+
+// PortForward forwards a local port to a ready pod of the service until the context is
+// canceled. Lost connections are re-established to another ready pod of the service.
+func (c *CLI) PortForward(ctx context.Context, opts PortForwardOptions) error {
+	if opts.Namespace == "" {
+		opts.Namespace = namespace
+	}
+	err := c.kubeClient.ForwardService(ctx, kubernetes.PortForwardOptions{
+		Namespace: opts.Namespace,
+		Service:   opts.Service,
+		Port:      opts.Port,
+		LocalPort: opts.LocalPort,
+	}, func(e kubernetes.PortForwardEvent) {
+		switch {
+		case e.Err == nil:
+			c.logInfo(MsgPortForwarding, e.LocalPort, e.Port, e.Pod)
+		case e.Pod != "":
+			c.logWarn(MsgPortForwardLost, e.Pod, e.Err, e.Retry)
+		default:
+			c.logWarn(MsgPortForwardRetry, e.LocalPort, e.Err, e.Retry)
+		}
+	})
+	if err != nil {
+		c.logError(MsgPortForwardFailed, opts.Service)
+		return err
+	}
+	c.logInfo(MsgPortForwardStopped)
+	return nil
+}
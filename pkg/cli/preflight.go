@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// ErrPreflightFailed is returned when the cluster does not pass preflight checks.
+var ErrPreflightFailed error = &Error{ID: MsgPreflightFailed}
+
+// Preflight validates that the cluster is compatible with Everest and prints a report.
+func (c *CLI) Preflight(ctx context.Context) error {
+	return c.runPreflight(ctx)
+}
+
+func (c *CLI) runPreflight(ctx context.Context) error {
+	c.logInfo(MsgPreflightRunning)
+	checks, err := c.kubeClient.RunPreflightChecks(ctx, namespace)
+	if err != nil {
+		c.logError(MsgPreflightRunFailed)
+		return err
+	}
+	if !printChecks(checks) {
+		return ErrPreflightFailed
+	}
+	c.logInfo(MsgPreflightPassed)
+	return nil
+}
+
+// printChecks prints a pass/fail report of the checks. It returns false if any check failed.
+func printChecks(checks []kubernetes.PreflightCheck) bool {
+	passed := true
+	for _, check := range checks {
+		status := "PASS"
+		if !check.Passed {
+			status = "FAIL"
+			passed = false
+		}
+		fmt.Printf("[%s] %s: %s\n", status, check.Name, check.Message)
+		if !check.Passed && check.Remediation != "" {
+			fmt.Printf("       %s\n", Message(MsgPreflightHint, check.Remediation))
+		}
+	}
+	return passed
+}
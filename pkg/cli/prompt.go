@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// confirm asks the user a yes/no question and returns true if the answer is yes.
+func confirm(question string) (bool, error) {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// Interactive returns true if stdin is a terminal.
+func Interactive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// ReadPassword reads a password from stdin. On a terminal the prompt is printed
+// and the input is not echoed. Otherwise the first line of stdin is read so the
+// password can be piped, e.g. from a secrets manager.
+func ReadPassword(prompt string) (string, error) {
+	if Interactive() {
+		fmt.Fprint(os.Stderr, prompt)
+		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", newError(MsgPMMPasswordReadFailed, err)
+		}
+		return string(password), nil
+	}
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", newError(MsgPMMPasswordReadFailed, err)
+	}
+	return strings.TrimRight(password, "\r\n"), nil
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// DatabaseProxyOptions holds the load balancer of a database cluster. Zero and empty fields
+// keep the current values.
+type DatabaseProxyOptions struct {
+	Type     string
+	Replicas int32
+	CPU      string
+	Memory   string
+	Expose   string
+	// SourceRanges replace the allowed client CIDRs if they are not nil. An empty slice allows all clients.
+	SourceRanges []string
+}
+
+// proxyOptions parses the options.
+func (o DatabaseProxyOptions) proxyOptions() (kubernetes.ProxyOptions, error) {
+	opts := kubernetes.ProxyOptions{
+		Type:         dbaasv1.LoadBalancerType(o.Type),
+		Replicas:     o.Replicas,
+		SourceRanges: o.SourceRanges,
+	}
+	if o.Expose != "" {
+		for _, t := range kubernetes.ExposeTypes {
+			if strings.EqualFold(o.Expose, string(t)) {
+				opts.ExposeType = t
+			}
+		}
+		if opts.ExposeType == "" {
+			types := make([]string, 0, len(kubernetes.ExposeTypes))
+			for _, t := range kubernetes.ExposeTypes {
+				types = append(types, string(t))
+			}
+			return opts, newError(MsgProxyExposeUnknown, nil, o.Expose, strings.Join(types, ", "))
+		}
+	}
+	for _, f := range []struct {
+		value string
+		dst   **resource.Quantity
+		msg   MessageID
+	}{
+		{o.CPU, &opts.CPU, MsgInvalidCPU},
+		{o.Memory, &opts.Memory, MsgInvalidMemory},
+	} {
+		if f.value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(f.value)
+		if err != nil {
+			return opts, newError(f.msg, err)
+		}
+		*f.dst = &q
+	}
+	return opts, nil
+}
+
+// ConfigureDatabaseProxy changes the load balancer of a database cluster, e.g. to expose
+// the cluster outside of Kubernetes.
+func (c *CLI) ConfigureDatabaseProxy(ctx context.Context, name string, opts DatabaseProxyOptions, waitOpts WaitOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db proxy")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if opts.Replicas < 0 {
+		return newError(MsgProxyInvalidReplicas, nil, opts.Replicas)
+	}
+	proxy, err := opts.proxyOptions()
+	if err != nil {
+		return err
+	}
+	if proxy.Type == "" && proxy.Replicas == 0 && proxy.CPU == nil && proxy.Memory == nil &&
+		proxy.ExposeType == "" && proxy.SourceRanges == nil {
+		return newError(MsgProxyNothing, nil)
+	}
+	if proxy.ExposeType == corev1.ServiceTypeLoadBalancer && len(proxy.SourceRanges) == 0 {
+		c.logWarn(MsgProxyExposedToAll, name)
+	}
+	c.logInfo(MsgProxyConfiguring, name)
+	if err := c.kubeClient.ConfigureProxy(ctx, name, proxy); err != nil {
+		c.logError(MsgProxyFailed, name)
+		return err
+	}
+	if !waitOpts.Wait {
+		c.printCheckStatusHint(name)
+		return nil
+	}
+	c.logInfo(MsgDatabaseWaitingReady, name)
+	if err := c.waitForDatabaseClusterRestart(ctx, name, waitOpts); err != nil {
+		return newError(MsgDatabaseNotReady, err, name)
+	}
+	c.logInfo(MsgProxyConfigured, name)
+	return nil
+}
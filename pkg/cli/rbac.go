@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// RBACOptions configures the cluster role and the role bindings of RenderRBAC and ApplyRBAC.
+type RBACOptions struct {
+	// Role is viewer, operator or admin.
+	Role   string
+	Users  []string
+	Groups []string
+	// ServiceAccounts are given as namespace:name.
+	ServiceAccounts []string
+	// Namespaces the role is granted in, the installation namespace if empty.
+	Namespaces []string
+}
+
+// kubernetesOptions returns the options with the subjects of the users, groups and service accounts.
+func (o RBACOptions) kubernetesOptions() (kubernetes.RBACOptions, error) {
+	opts := kubernetes.RBACOptions{Role: kubernetes.RBACRole(o.Role), Namespaces: o.Namespaces}
+	if len(opts.Namespaces) == 0 {
+		opts.Namespaces = []string{namespace}
+	}
+	for _, user := range o.Users {
+		opts.Subjects = append(opts.Subjects, rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: user})
+	}
+	for _, group := range o.Groups {
+		opts.Subjects = append(opts.Subjects, rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: group})
+	}
+	for _, account := range o.ServiceAccounts {
+		ns, name, ok := strings.Cut(account, ":")
+		if !ok || ns == "" || name == "" {
+			return opts, newError(MsgRBACInvalidServiceAccount, nil, account)
+		}
+		opts.Subjects = append(opts.Subjects, rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: ns, Name: name})
+	}
+	return opts, nil
+}
+
+// RenderRBAC writes the manifest of the cluster role of the role and the role bindings of the
+// subjects to it. It does not connect to the cluster.
+func RenderRBAC(opts RBACOptions, w io.Writer) error {
+	kopts, err := opts.kubernetesOptions()
+	if err != nil {
+		return err
+	}
+	manifest, err := kubernetes.RBACManifest(kopts)
+	if err != nil {
+		return newError(MsgRBACRenderFailed, err)
+	}
+	_, err = w.Write(manifest)
+	return err
+}
+
+// ApplyRBAC applies the cluster role of the role and the role bindings of the subjects to it.
+func (c *CLI) ApplyRBAC(ctx context.Context, opts RBACOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "rbac generate")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	kopts, err := opts.kubernetesOptions()
+	if err != nil {
+		return err
+	}
+	name := kubernetes.RBACRoleName(kopts.Role)
+	c.logInfo(MsgRBACApplying, name)
+	if err := c.kubeClient.ApplyRBAC(ctx, kopts); err != nil {
+		c.logError(MsgRBACApplyFailed, name)
+		return err
+	}
+	if len(kopts.Subjects) == 0 {
+		c.logInfo(MsgRBACAppliedUnbound, name)
+		return nil
+	}
+	c.logInfo(MsgRBACApplied, name, strings.Join(kopts.Namespaces, ", "))
+	return nil
+}
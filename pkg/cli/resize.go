@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ResizeDatabaseVolume grows the volumes of every node of the database cluster and waits
+// for the volumes to be resized if requested.
+func (c *CLI) ResizeDatabaseVolume(ctx context.Context, name, size string, waitOpts WaitOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db resize")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if size == "" {
+		return newError(MsgResizeSizeRequired, nil)
+	}
+	disk, err := resource.ParseQuantity(size)
+	if err != nil {
+		return newError(MsgInvalidDisk, err)
+	}
+	c.logInfo(MsgDatabaseResizing, name, disk.String())
+	if err := c.kubeClient.ResizeDatabaseVolume(ctx, name, disk); err != nil {
+		c.logError(MsgResizeFailed, name)
+		return err
+	}
+	if !waitOpts.Wait {
+		c.printCheckStatusHint(name)
+		return nil
+	}
+	c.logInfo(MsgDatabaseWaitingResize, name)
+	waitCtx, cancel := context.WithTimeout(ctx, waitOpts.timeout())
+	defer cancel()
+	if err := c.kubeClient.WaitForVolumeResize(waitCtx, name, disk); err != nil {
+		return newError(MsgVolumeNotResized, err, name)
+	}
+	c.logInfo(MsgDatabaseResized, name, disk.String())
+	return nil
+}
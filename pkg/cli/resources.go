@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ResourcesOptions holds parameters of the resources command.
+type ResourcesOptions struct {
+	Output string
+	// Size, CPU, Memory and Disk describe a planned database cluster to check
+	// against the available resources. No check is done if Size is zero.
+	Size   int32
+	CPU    string
+	Memory string
+	Disk   string
+}
+
+// resourcesReport is the JSON output of the resources command.
+type resourcesReport struct {
+	*kubernetes.ClusterResources
+	Available kubernetes.Resources  `json:"available"`
+	Requested *kubernetes.Resources `json:"requested,omitempty"`
+	Fits      *bool                 `json:"fits,omitempty"`
+}
+
+// Resources prints allocatable, used and available resources of the worker nodes
+// and checks whether a database cluster of the given size fits into them.
+func (c *CLI) Resources(ctx context.Context, opts ResourcesOptions) error {
+	if opts.Output != OutputText && opts.Output != OutputJSON {
+		return newError(MsgUnsupportedOutput, nil, opts.Output)
+	}
+	var requested *kubernetes.Resources
+	if opts.Size > 0 {
+		r, err := requestedResources(opts)
+		if err != nil {
+			return err
+		}
+		requested = &r
+	}
+	res, err := c.kubeClient.GetClusterResources(ctx)
+	if err != nil {
+		c.logError(MsgResourcesFailed)
+		return err
+	}
+	report := resourcesReport{ClusterResources: res, Available: res.Available(), Requested: requested}
+	if requested != nil {
+		fits := report.Available.Fits(*requested)
+		report.Fits = &fits
+	}
+
+	if opts.Output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printResources(report)
+	}
+	if report.Fits != nil && !*report.Fits {
+		return newError(MsgResourcesInsufficient, nil, opts.Size)
+	}
+	return nil
+}
+
+// requestedResources returns the resources of all nodes of the planned database cluster.
+func requestedResources(opts ResourcesOptions) (kubernetes.Resources, error) {
+	cpu, err := resource.ParseQuantity(opts.CPU)
+	if err != nil {
+		return kubernetes.Resources{}, newError(MsgInvalidCPU, err)
+	}
+	memory, err := resource.ParseQuantity(opts.Memory)
+	if err != nil {
+		return kubernetes.Resources{}, newError(MsgInvalidMemory, err)
+	}
+	disk, err := resource.ParseQuantity(opts.Disk)
+	if err != nil {
+		return kubernetes.Resources{}, newError(MsgInvalidDisk, err)
+	}
+	return totalResources(opts.Size, cpu, memory, disk), nil
+}
+
+// totalResources returns the resources of all nodes of a database cluster.
+func totalResources(size int32, cpu, memory, disk resource.Quantity) kubernetes.Resources {
+	n := uint64(size)
+	return kubernetes.Resources{
+		CPUMillis:   n * uint64(cpu.MilliValue()),
+		MemoryBytes: n * uint64(memory.Value()),
+		DiskBytes:   n * uint64(disk.Value()),
+	}
+}
+
+func printResources(report resourcesReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "WORKER NODES: %d\n\n", report.Nodes)
+	fmt.Fprintln(w, "\tCPU\tMEMORY\tDISK")
+	rows := []struct {
+		name string
+		res  *kubernetes.Resources
+	}{
+		{"Allocatable", &report.Allocatable},
+		{"Used", &report.Used},
+		{"Available", &report.Available},
+		{"Requested", report.Requested},
+	}
+	for _, row := range rows {
+		if row.res == nil {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", row.name,
+			resource.NewMilliQuantity(int64(row.res.CPUMillis), resource.DecimalSI),
+			resource.NewQuantity(int64(row.res.MemoryBytes), resource.BinarySI),
+			resource.NewQuantity(int64(row.res.DiskBytes), resource.BinarySI),
+		)
+	}
+	w.Flush()
+	if report.Fits != nil && *report.Fits {
+		fmt.Printf("\n%s\n", Message(MsgResourcesFit))
+	}
+}
@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// RotateMonitoringCredentials replaces the PMM credentials of the VM agents with a new
+// API key once their rotation is due and revokes the API keys they were created from.
+// The PMM server and its admin credentials are taken from the configuration.
+func (c *CLI) RotateMonitoringCredentials(ctx context.Context) error {
+	rotations, err := c.kubeClient.CredentialsRotations(ctx)
+	if err != nil {
+		c.logError(MsgRotationFailed)
+		return err
+	}
+	var period time.Duration
+	now := time.Now()
+	for _, r := range rotations {
+		if r.Period > 0 && !r.Due.After(now) {
+			period = r.Period
+			break
+		}
+	}
+	if period == 0 {
+		return nil
+	}
+	ctx, unlock, err := c.acquireLock(ctx, "serve")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return c.rotateMonitoringCredentials(ctx, period, rotations)
+}
+
+// RotateMonitoringCredentialsNow replaces the PMM credentials of the VM agents with a new
+// API key whether their rotation is due or not. The rotation period is kept.
+func (c *CLI) RotateMonitoringCredentialsNow(ctx context.Context) error {
+	ctx, unlock, err := c.acquireLock(ctx, "monitoring rotate-credentials")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	rotations, err := c.kubeClient.CredentialsRotations(ctx)
+	if err != nil {
+		c.logError(MsgRotationFailed)
+		return err
+	}
+	if len(rotations) == 0 {
+		return newError(MsgRotationNoAPIKeys, nil)
+	}
+	var period time.Duration
+	for _, r := range rotations {
+		if r.Period > period {
+			period = r.Period
+		}
+	}
+	return c.rotateMonitoringCredentials(ctx, period, rotations)
+}
+
+// rotateMonitoringCredentials provisions monitoring with a new API key rotated after the
+// period and revokes the API keys of the rotations.
+func (c *CLI) rotateMonitoringCredentials(ctx context.Context, period time.Duration, rotations []kubernetes.CredentialsRotation) error {
+	pmm := c.config.Monitoring.PMM
+	if pmm == nil || pmm.Endpoint == "" || pmm.CredentialsSecret != "" || pmm.APIKey != "" {
+		c.logError(MsgRotationNotConfigured)
+		return newError(MsgRotationNotConfigured, nil)
+	}
+	c.logInfo(MsgRotationStarted)
+	// Provisioning replaces all VM agents writing to PMM, so the keys of all of them are revoked.
+	if err := c.provisionPMMMonitoring(ctx, period); err != nil {
+		c.logError(MsgRotationFailed)
+		return err
+	}
+	certs, err := loadMonitoringTLS(pmm.TLS)
+	if err != nil {
+		return err
+	}
+	c.revokeAPIKeys(rotationAPIKeys(rotations), certs)
+	if period > 0 {
+		c.logInfo(MsgRotationDone, period)
+	} else {
+		c.logInfo(MsgRotationDoneOnce)
+	}
+	return nil
+}
+
+// revokeAPIKeys revokes the PMM API keys. Failures are logged since the keys are not used anymore.
+func (c *CLI) revokeAPIKeys(apiKeys []string, certs kubernetes.MonitoringTLS) {
+	for _, apiKey := range apiKeys {
+		if err := c.deleteAPIKey(apiKey, certs); err != nil {
+			c.logWarn(MsgAPIKeyRevokeFailed, apiKey, err)
+		}
+	}
+}
+
+func rotationAPIKeys(rotations []kubernetes.CredentialsRotation) []string {
+	apiKeys := make([]string, 0, len(rotations))
+	for _, r := range rotations {
+		apiKeys = append(apiKeys, r.APIKey)
+	}
+	return apiKeys
+}
+
+// rotateMonitoringCredentialsEvery rotates the PMM credentials which are due periodically
+// until the context is done. Errors are logged and retried on the next tick.
+func (c *CLI) rotateMonitoringCredentialsEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_ = c.RotateMonitoringCredentials(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deleteAPIKey revokes the PMM service account or the legacy API key with the given name.
+// Keys which do not exist are ignored.
+func (c *CLI) deleteAPIKey(name string, certs kubernetes.MonitoringTLS) error {
+	client, err := c.monitoringHTTPClient(certs)
+	if err != nil {
+		return err
+	}
+	if err := c.deleteServiceAccount(client, name); !errors.Is(err, errServiceAccountsUnsupported) {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/graph/api/auth/keys", c.config.Monitoring.PMM.Endpoint)
+	resp, err := c.pmmRequest(client, http.MethodGet, endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var keys []struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key.Name != name {
+			continue
+		}
+		resp, err := c.pmmRequest(client, http.MethodDelete, fmt.Sprintf("%s/%d", endpoint, key.ID))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// pmmRequest sends a request to the PMM API authenticated with the admin credentials.
+// Responses with a status other than 200 are returned as errors.
+func (c *CLI) pmmRequest(client *http.Client, method, url string) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.config.Monitoring.PMM.Username, c.config.Monitoring.PMM.Password)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, newError(MsgPMMBadStatus, nil, method, url, resp.StatusCode)
+	}
+	return resp, nil
+}
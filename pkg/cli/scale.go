@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// DatabaseScaleOptions holds the new size of a database cluster and the resources of
+// every node. Zero and empty fields keep the current values.
+type DatabaseScaleOptions struct {
+	Size   int32
+	CPU    string
+	Memory string
+	Disk   string
+}
+
+// resources parses the resources of the options.
+func (o DatabaseScaleOptions) resources() (kubernetes.DatabaseClusterResources, error) {
+	var res kubernetes.DatabaseClusterResources
+	for _, f := range []struct {
+		value string
+		dst   **resource.Quantity
+		msg   MessageID
+	}{
+		{o.CPU, &res.CPU, MsgInvalidCPU},
+		{o.Memory, &res.Memory, MsgInvalidMemory},
+		{o.Disk, &res.Disk, MsgInvalidDisk},
+	} {
+		if f.value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(f.value)
+		if err != nil {
+			return res, newError(f.msg, err)
+		}
+		*f.dst = &q
+	}
+	return res, nil
+}
+
+// ScaleDatabaseCluster changes the number of nodes of a database cluster and the resources
+// of every node. The change is refused if the additional resources do not fit into the
+// resources available in the cluster.
+func (c *CLI) ScaleDatabaseCluster(ctx context.Context, name string, opts DatabaseScaleOptions, waitOpts WaitOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db scale")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if opts.Size < 0 {
+		return newError(MsgScaleInvalidSize, nil, opts.Size)
+	}
+	res, err := opts.resources()
+	if err != nil {
+		return err
+	}
+	if opts.Size == 0 && res.CPU == nil && res.Memory == nil && res.Disk == nil {
+		return newError(MsgScaleNothing, nil)
+	}
+	cluster, err := c.kubeClient.GetDatabaseCluster(ctx, name)
+	if err != nil {
+		c.logError(MsgScaleFailed, name)
+		return err
+	}
+	scaled, err := kubernetes.ScaledDatabaseCluster(cluster, opts.Size, res)
+	if err != nil {
+		c.logError(MsgScaleFailed, name)
+		return err
+	}
+	if err := c.checkScale(ctx, cluster, scaled); err != nil {
+		return err
+	}
+
+	c.logInfo(MsgDatabaseScaling, name)
+	if err := c.kubeClient.ScaleDatabaseCluster(ctx, name, opts.Size, res); err != nil {
+		c.logError(MsgScaleFailed, name)
+		return err
+	}
+	if !waitOpts.Wait {
+		c.printCheckStatusHint(name)
+		return nil
+	}
+	c.logInfo(MsgDatabaseWaitingReady, name)
+	if err := c.waitForDatabaseClusterRestart(ctx, name, waitOpts); err != nil {
+		return newError(MsgDatabaseNotReady, err, name)
+	}
+	c.logInfo(MsgDatabaseScaled, name)
+	return nil
+}
+
+// checkScale checks that the scaled cluster fits into the volume size limit, the worker
+// nodes and the resources available in addition to the ones used by the cluster already.
+func (c *CLI) checkScale(ctx context.Context, cluster, scaled *dbaasv1.DatabaseCluster) error {
+	if scaled.Spec.DBInstance.DiskSize.Cmp(cluster.Spec.DBInstance.DiskSize) > 0 {
+		if err := c.checkDiskSize(ctx, scaled.Spec.DBInstance.DiskSize); err != nil {
+			return err
+		}
+	}
+	if scaled.Spec.ClusterSize > cluster.Spec.ClusterSize {
+		if err := c.checkPlacement(ctx, scaled); err != nil {
+			return err
+		}
+	}
+	current := databaseClusterResources(cluster)
+	requested := databaseClusterResources(scaled)
+	additional := kubernetes.Resources{
+		CPUMillis:   subtract(requested.CPUMillis, current.CPUMillis),
+		MemoryBytes: subtract(requested.MemoryBytes, current.MemoryBytes),
+		DiskBytes:   subtract(requested.DiskBytes, current.DiskBytes),
+	}
+	if additional == (kubernetes.Resources{}) {
+		return nil
+	}
+	available, err := c.kubeClient.GetClusterResources(ctx)
+	if err != nil {
+		c.logError(MsgResourcesFailed)
+		return err
+	}
+	if !available.Available().Fits(additional) {
+		return newError(MsgScaleInsufficient, nil, cluster.Name)
+	}
+	return nil
+}
+
+// databaseClusterResources returns the resources requested by all nodes of the cluster.
+func databaseClusterResources(cluster *dbaasv1.DatabaseCluster) kubernetes.Resources {
+	instance := cluster.Spec.DBInstance
+	return totalResources(cluster.Spec.ClusterSize, instance.CPU, instance.Memory, instance.DiskSize)
+}
+
+func subtract(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
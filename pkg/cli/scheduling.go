@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gen1us2k/everest-provisioner/config"
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// scheduling converts the configured scheduling constraints of the installed components.
+func scheduling(cfg config.SchedulingConfig) (kubernetes.Scheduling, error) {
+	affinity, err := cfg.ParseAffinity()
+	if err != nil {
+		return kubernetes.Scheduling{}, err
+	}
+	s := kubernetes.Scheduling{NodeSelector: cfg.NodeSelector, Affinity: affinity}
+	for _, t := range cfg.Tolerations {
+		s.Tolerations = append(s.Tolerations, corev1.Toleration{
+			Key:               t.Key,
+			Operator:          corev1.TolerationOperator(t.Operator),
+			Value:             t.Value,
+			Effect:            corev1.TaintEffect(t.Effect),
+			TolerationSeconds: t.TolerationSeconds,
+		})
+	}
+	return s, nil
+}
+
+// ScheduleOperators applies the configured scheduling constraints to the installed
+// operators. OLM rolls out the operator deployments with the new constraints.
+func (c *CLI) ScheduleOperators(ctx context.Context) error {
+	ctx, unlock, err := c.acquireLock(ctx, "operator schedule")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := scheduling(c.config.Scheduling)
+	if err != nil {
+		return err
+	}
+	if s.IsZero() {
+		return newError(MsgSchedulingNotConfigured, nil)
+	}
+	updated, err := c.kubeClient.ScheduleOperators(ctx, namespace, operators)
+	if err != nil {
+		return newError(MsgOperatorsScheduleFailed, err)
+	}
+	if len(updated) == 0 {
+		c.logInfo(MsgOperatorsScheduleUnchanged)
+		return nil
+	}
+	c.logInfo(MsgOperatorsScheduled, strings.Join(updated, ", "))
+	return nil
+}
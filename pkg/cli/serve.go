@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/pkg/server"
+)
+
+// ServeOptions holds parameters of the API server.
+type ServeOptions struct {
+	Address string
+	// Token is the bearer token required by requests changing database clusters.
+	// Such requests are refused if it is empty.
+	Token string
+	// CacheResync is the resync period of the informer cache. The cache is disabled if it is zero.
+	CacheResync time.Duration
+	// ExpiryInterval is how often expired database clusters are deleted. Expired clusters are kept if it is zero.
+	ExpiryInterval time.Duration
+	// RotationInterval is how often PMM credentials due for rotation are looked for.
+	// The credentials are not rotated if it is zero.
+	RotationInterval time.Duration
+	// DigestInterval is how often the maintenance digest is sent. The digest is not sent
+	// if it is zero or no webhook or email recipients are configured.
+	DigestInterval time.Duration
+}
+
+// Serve runs the database cluster API until the context is canceled.
+func (c *CLI) Serve(ctx context.Context, opts ServeOptions) error {
+	if opts.CacheResync > 0 {
+		if err := c.kubeClient.EnableCache(ctx, opts.CacheResync); err != nil {
+			c.logError(MsgServeCacheFailed)
+			return err
+		}
+	}
+	if opts.ExpiryInterval > 0 {
+		go c.deleteExpiredDatabaseClustersEvery(ctx, opts.ExpiryInterval)
+	}
+	if opts.RotationInterval > 0 {
+		go c.rotateMonitoringCredentialsEvery(ctx, opts.RotationInterval)
+	}
+	if opts.DigestInterval > 0 && c.digestConfigured() {
+		go c.sendMaintenanceDigestEvery(ctx, opts.DigestInterval)
+	}
+	c.kubeClient.EnableMetrics()
+	c.logInfo(MsgServeStarting, opts.Address)
+	if err := server.New(c.kubeClient, opts.Token).ListenAndServe(ctx, opts.Address); err != nil {
+		c.logError(MsgServeFailed)
+		return err
+	}
+	c.logInfo(MsgServeStopped)
+	return nil
+}
+
+// deleteExpiredDatabaseClustersEvery deletes expired database clusters periodically until the context is done.
+// Errors are logged and retried on the next tick.
+func (c *CLI) deleteExpiredDatabaseClustersEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_ = c.DeleteExpiredDatabaseClusters(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// UseSnapshot makes ProvisionCluster install the catalog image and the operator
+// versions recorded in the snapshot file instead of the latest ones.
+func (c *CLI) UseSnapshot(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return newError(MsgSnapshotReadFailed, err, path)
+	}
+	snapshot, err := kubernetes.ParseInstallSnapshot(data)
+	if err != nil {
+		return newError(MsgSnapshotReadFailed, err, path)
+	}
+	for _, name := range operators {
+		if _, ok := snapshot.Operator(name); !ok {
+			return newError(MsgSnapshotOperatorMissing, nil, name)
+		}
+	}
+	c.snapshot = snapshot
+	return nil
+}
+
+// Snapshot prints the snapshot recorded by the last installation.
+func (c *CLI) Snapshot(ctx context.Context) error {
+	snapshot, err := c.kubeClient.GetInstallSnapshot(ctx, namespace)
+	if err != nil {
+		c.logError(MsgSnapshotGetFailed)
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(snapshot)
+}
+
+// pinCatalog pins the catalog source to the image of the snapshot.
+func (c *CLI) pinCatalog(ctx context.Context) error {
+	if c.snapshot == nil {
+		return nil
+	}
+	c.logInfo(MsgSnapshotPinningCatalog, c.snapshot.Catalog.Image)
+	if err := c.kubeClient.PinCatalogSource(ctx, c.catalog, c.snapshot.Catalog.Image, c.catalogNamespace); err != nil {
+		c.logError(MsgCatalogInstallFailed, c.catalogNamespace, c.catalog)
+		return err
+	}
+	return nil
+}
+
+// pinOperatorVersions makes the requests install the operator versions of the snapshot.
+func (c *CLI) pinOperatorVersions(reqs []kubernetes.InstallOperatorRequest) {
+	if c.snapshot == nil {
+		return
+	}
+	for i := range reqs {
+		op, ok := c.snapshot.Operator(reqs[i].Name)
+		if !ok {
+			continue
+		}
+		reqs[i].Channel = op.Channel
+		reqs[i].StartingCSV = op.CSV
+	}
+}
+
+// recordSnapshot stores the installed versions in the state config map and returns them.
+// Failures are reported as warnings since the installation itself has succeeded; nil is returned then.
+func (c *CLI) recordSnapshot(ctx context.Context) *kubernetes.InstallSnapshot {
+	snapshot, err := c.kubeClient.CreateInstallSnapshot(ctx, namespace, c.catalogNamespace, c.catalog, operators)
+	if err == nil {
+		err = c.kubeClient.SaveInstallSnapshot(ctx, namespace, snapshot)
+	}
+	if err != nil {
+		c.logWarn(MsgSnapshotRecordFailed, err)
+		return nil
+	}
+	c.logInfo(MsgSnapshotRecorded, namespace, kubernetes.StateConfigMapName)
+	return snapshot
+}
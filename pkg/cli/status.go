@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+const (
+	// OutputText prints the status as a human readable table.
+	OutputText = "text"
+	// OutputJSON prints the status as JSON.
+	OutputJSON = "json"
+)
+
+// Status prints the health of the installation in the given output format.
+func (c *CLI) Status(ctx context.Context, output string) error {
+	if output != OutputText && output != OutputJSON {
+		return newError(MsgUnsupportedOutput, nil, output)
+	}
+	if err := c.detectOpenShift(ctx); err != nil {
+		return err
+	}
+	status, err := c.kubeClient.GetInstallStatus(ctx, namespace, c.catalogNamespace, c.catalog, operators)
+	if err != nil {
+		c.logError(MsgStatusFailed)
+		return err
+	}
+	status.ClusterDomain = c.kubeClient.ClusterDomain(ctx)
+	if output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(status)
+	}
+	printStatus(status)
+	return nil
+}
+
+func printStatus(status *kubernetes.InstallStatus) {
+	catalog := "not found"
+	if status.Catalog.Found {
+		catalog = status.Catalog.State
+		if catalog == "" {
+			catalog = "unknown"
+		}
+	}
+	fmt.Println(Message(MsgStatusCatalog, status.Catalog.Namespace, status.Catalog.Name, catalog))
+	fmt.Printf("%s\n\n", Message(MsgStatusClusterDomain, status.ClusterDomain))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OPERATOR\tSTATE\tINSTALLED CSV\tPHASE\tPENDING PLAN")
+	for _, op := range status.Operators {
+		state := op.SubscriptionState
+		if !op.Subscribed {
+			state = "not installed"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", op.Name, state, op.InstalledCSV, op.CSVPhase, op.PendingInstallPlan)
+	}
+	w.Flush()
+	if status.Timings != nil {
+		fmt.Println()
+		printTimings(status.Timings)
+	}
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// StorageClasses prints the storage classes database volumes can be provisioned with.
+func (c *CLI) StorageClasses(ctx context.Context, output string) error {
+	if output != OutputText && output != OutputJSON {
+		return newError(MsgUnsupportedOutput, nil, output)
+	}
+	classes, err := c.kubeClient.ListStorageClasses(ctx)
+	if err != nil {
+		c.logError(MsgStorageClassesFailed)
+		return err
+	}
+	if output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(classes)
+	}
+	if len(classes) == 0 {
+		fmt.Println(Message(MsgStorageClassesNone))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPROVISIONER\tDEFAULT\tEXPANSION\tRECLAIM POLICY\tBINDING MODE\tACCESS MODES")
+	for _, sc := range classes {
+		modes := make([]string, 0, len(sc.AccessModes))
+		for _, m := range sc.AccessModes {
+			modes = append(modes, string(m))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%t\t%s\t%s\t%s\n", sc.Name, sc.Provisioner, sc.Default, sc.AllowVolumeExpansion,
+			valueOrDash(sc.ReclaimPolicy), valueOrDash(sc.VolumeBindingMode), valueOrDash(strings.Join(modes, ", ")))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+const tokenTimeout = 2 * time.Minute
+
+// Token provisions a service account for the Everest backend and prints a kubeconfig for it.
+func (c *CLI) Token(ctx context.Context, name string) error {
+	ctx, unlock, err := c.acquireLock(ctx, "service-account")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, tokenTimeout)
+	defer cancel()
+	c.logInfo(MsgServiceAccountProvisioning, name)
+	kubeconfig, err := c.kubeClient.ProvisionServiceAccount(ctx, name, kubernetes.EverestPolicyRules, kubernetes.EverestNamespacePolicyRules)
+	if err != nil {
+		c.logError(MsgServiceAccountProvisionFailed, name)
+		return err
+	}
+	fmt.Print(kubeconfig)
+	return nil
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"context"
+)
+
+// Uninstall removes operators installed by the provisioner together with their CRDs.
+func (c *CLI) Uninstall(ctx context.Context, opts ConfirmOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "uninstall")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	plan, err := c.kubeClient.PlanUninstall(ctx, namespace, operatorGroup, operators)
+	if err != nil {
+		c.logError(MsgUninstallPlanFailed)
+		return err
+	}
+	ok, err := confirmDeletion(plan, opts)
+	if err != nil || !ok {
+		if err == nil {
+			c.logInfo(MsgUninstallCancelled)
+		}
+		return err
+	}
+	if err := c.kubeClient.ExecuteDeletionPlan(ctx, plan); err != nil {
+		c.logError(MsgUninstallFailed)
+		return err
+	}
+	c.forgetProvisionSteps(ctx)
+	c.logInfo(MsgUninstalled)
+	return nil
+}
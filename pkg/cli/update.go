@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+)
+
+// DatabaseUpdateOptions holds parameters of the database cluster update.
+type DatabaseUpdateOptions struct {
+	// Name overrides the name of the cluster in the manifest if it is set.
+	Name string
+	// File is the modified DatabaseCluster manifest.
+	File string
+	// Strategy is one of kubernetes.UpdateStrategies. The rolling update is used if it is empty.
+	Strategy string
+}
+
+// UpdateDatabaseCluster applies the spec of the modified manifest to the live database cluster.
+// The changed fields are printed and confirmed before they are applied. Destructive changes,
+// e.g. shrinking volumes or downgrading the engine, require forcing the update.
+func (c *CLI) UpdateDatabaseCluster(ctx context.Context, opts DatabaseUpdateOptions, confirmOpts ConfirmOptions, waitOpts WaitOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "db update")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	strategy := kubernetes.UpdateStrategy(opts.Strategy)
+	switch strategy {
+	case "":
+		strategy = kubernetes.UpdateStrategyRolling
+	case kubernetes.UpdateStrategyRolling, kubernetes.UpdateStrategyRecreate:
+	default:
+		strategies := make([]string, 0, len(kubernetes.UpdateStrategies))
+		for _, s := range kubernetes.UpdateStrategies {
+			strategies = append(strategies, string(s))
+		}
+		return newError(MsgUpdateStrategyUnknown, nil, opts.Strategy, strings.Join(strategies, ", "))
+	}
+	if opts.File == "" {
+		return newError(MsgUpdateFileRequired, nil)
+	}
+	desired, err := loadDatabaseCluster(opts.File)
+	if err != nil {
+		return err
+	}
+	if opts.Name != "" {
+		desired.Name = opts.Name
+	}
+	if desired.Name == "" {
+		return newError(MsgDatabaseNameRequired, nil)
+	}
+
+	update, err := c.kubeClient.PlanDatabaseClusterUpdate(ctx, desired)
+	if err != nil {
+		c.logError(MsgUpdatePlanFailed, desired.Name)
+		return err
+	}
+	if len(update.Changes) == 0 {
+		c.logInfo(MsgUpdateNothing, desired.Name)
+		return nil
+	}
+	ok, err := confirmUpdate(update, confirmOpts)
+	if err != nil || !ok {
+		if err == nil {
+			c.logInfo(MsgUpdateCancelled)
+		}
+		return err
+	}
+
+	c.logInfo(MsgDatabaseUpdating, desired.Name, strategy)
+	updateCtx, cancel := context.WithTimeout(ctx, waitOpts.timeout())
+	err = c.kubeClient.UpdateDatabaseCluster(updateCtx, update, strategy)
+	cancel()
+	if err != nil {
+		c.logError(MsgUpdateFailed, desired.Name)
+		return err
+	}
+	if !waitOpts.Wait || desired.Spec.Pause {
+		c.printCheckStatusHint(desired.Name)
+		return nil
+	}
+	c.logInfo(MsgDatabaseWaitingReady, desired.Name)
+	if strategy == kubernetes.UpdateStrategyRecreate {
+		err = c.waitForDatabaseClusterReady(ctx, desired.Name, waitOpts)
+	} else {
+		err = c.waitForDatabaseClusterRestart(ctx, desired.Name, waitOpts)
+	}
+	if err != nil {
+		return newError(MsgDatabaseNotUpdated, err, desired.Name)
+	}
+	c.logInfo(MsgDatabaseUpdated, desired.Name)
+	return nil
+}
+
+// confirmUpdate prints the changes of the update and asks the user to confirm them.
+// It returns false if the user declined the update.
+func confirmUpdate(update *kubernetes.DatabaseClusterUpdate, opts ConfirmOptions) (bool, error) {
+	fmt.Println(Message(MsgUpdateChangesHeader, update.Current.Name))
+	for _, change := range update.Changes {
+		fmt.Printf("  - %s\n", change)
+	}
+	if len(update.Warnings) != 0 {
+		fmt.Println(Message(MsgUpdateWarningsHeader))
+		for _, w := range update.Warnings {
+			fmt.Printf("  - %s\n", w)
+		}
+		if !opts.Force {
+			return false, ErrForceRequired
+		}
+	}
+	if opts.Yes {
+		return true, nil
+	}
+	return confirm(Message(MsgUpdateConfirm))
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// UpgradeOperators upgrades operators installed by the provisioner to the
+// latest versions available in their channels. kube-state-metrics is re-applied
+// if the configured image differs from the deployed one.
+func (c *CLI) UpgradeOperators(ctx context.Context, assumeYes bool, opts WaitOptions) error {
+	ctx, unlock, err := c.acquireLock(ctx, "upgrade")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c.logInfo(MsgUpgradesLooking)
+	upgrades, err := c.kubeClient.ListOperatorUpgrades(ctx, namespace, operators)
+	if err != nil {
+		c.logError(MsgUpgradesListFailed)
+		return err
+	}
+	ksm, err := c.kubeClient.KubeStateMetricsUpgrade(ctx)
+	if err != nil {
+		c.logError(MsgUpgradesListFailed)
+		return err
+	}
+	if len(upgrades) == 0 && ksm == nil {
+		c.logInfo(MsgUpgradesNone)
+		return nil
+	}
+
+	fmt.Println(Message(MsgUpgradeHeader))
+	for _, u := range upgrades {
+		installed := u.InstalledCSV
+		if installed == "" {
+			installed = "<none>"
+		}
+		fmt.Printf("  %s: %s -> %s\n", u.Name, installed, u.TargetCSV)
+	}
+	if ksm != nil {
+		fmt.Printf("  kube-state-metrics: %s -> %s\n", ksm.From, ksm.To)
+	}
+	if !assumeYes {
+		ok, err := confirm(Message(MsgUpgradeConfirm))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			c.logInfo(MsgUpgradeCancelled)
+			return nil
+		}
+	}
+
+	if ksm != nil {
+		c.logInfo(MsgKubeStateMetricsUpgrading, ksm.To)
+		warnings, err := c.kubeClient.UpgradeKubeStateMetrics(ctx)
+		c.warnings.Merge(warnings)
+		if err != nil {
+			c.logError(MsgKubeStateMetricsUpgradeFailed)
+			return err
+		}
+		c.logInfo(MsgKubeStateMetricsUpgraded)
+		if len(upgrades) == 0 {
+			return nil
+		}
+	}
+	for _, u := range upgrades {
+		c.logInfo(MsgOperatorUpgrading, u.Name)
+		if err := c.kubeClient.UpgradeOperator(ctx, u.Namespace, u.Name); err != nil {
+			c.logError(MsgOperatorUpgradeFailed, u.Name)
+			return err
+		}
+		if !opts.Wait {
+			c.logInfo(MsgOperatorUpgradeApproved, u.Name)
+			continue
+		}
+		c.logInfo(MsgCSVWaiting, u.TargetCSV)
+		waitCtx, cancel := context.WithTimeout(ctx, opts.timeout())
+		err := c.kubeClient.WaitForClusterServiceVersion(waitCtx, types.NamespacedName{Namespace: u.Namespace, Name: u.TargetCSV})
+		cancel()
+		if err != nil {
+			c.logError(MsgOperatorUpgradeNotSucceeded, u.Name)
+			return err
+		}
+		c.logInfo(MsgOperatorUpgraded, u.Name)
+	}
+	if !opts.Wait {
+		fmt.Println(Message(MsgUpgradeCheckLater, namespace))
+		return nil
+	}
+	c.pruneAfterUpgrade(ctx)
+	return nil
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"github.com/gen1us2k/everest-provisioner/pkg/version"
+)
+
+// operatorNotInstalled is reported as the version of operators missing in the cluster.
+const operatorNotInstalled = "not installed"
+
+// VersionReport holds the versions of the CLI and of the components running in the cluster.
+type VersionReport struct {
+	CLI        version.Info      `json:"cli"`
+	Kubernetes string            `json:"kubernetes,omitempty"`
+	Operators  map[string]string `json:"operators,omitempty"`
+}
+
+// PrintVersion prints the version of the CLI without connecting to the cluster.
+func PrintVersion(output string) error {
+	return printVersionReport(&VersionReport{CLI: version.Get()}, output)
+}
+
+// Version prints the versions of the CLI, Kubernetes and the installed operators.
+func (c *CLI) Version(ctx context.Context, output string) error {
+	if output != OutputText && output != OutputJSON {
+		return newError(MsgUnsupportedOutput, nil, output)
+	}
+	report := &VersionReport{CLI: version.Get(), Operators: make(map[string]string)}
+	info, err := c.kubeClient.GetServerVersion(ctx)
+	if err != nil {
+		c.logError(MsgVersionServerFailed)
+		return err
+	}
+	report.Kubernetes = info.GitVersion
+	for name, get := range map[string]func(context.Context) (string, error){
+		"percona-xtradb-cluster-operator": c.kubeClient.GetPXCOperatorVersion,
+		"percona-server-mongodb-operator": c.kubeClient.GetPSMDBOperatorVersion,
+		"dbaas-operator":                  c.kubeClient.GetDBaaSOperatorVersion,
+	} {
+		v, err := get(ctx)
+		switch {
+		case errors.Is(err, kubernetes.ErrOperatorNotFound):
+			v = operatorNotInstalled
+		case err != nil:
+			c.logError(MsgVersionOperatorFailed, name)
+			return err
+		}
+		report.Operators[name] = v
+	}
+	return printVersionReport(report, output)
+}
+
+func printVersionReport(report *VersionReport, output string) error {
+	switch output {
+	case OutputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case OutputText:
+	default:
+		return newError(MsgUnsupportedOutput, nil, output)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "CLI\t%s (commit %s, built %s, %s, %s)\n",
+		report.CLI.Version, report.CLI.GitCommit, report.CLI.BuildDate, report.CLI.GoVersion, report.CLI.Platform)
+	if report.Kubernetes != "" {
+		fmt.Fprintf(w, "Kubernetes\t%s\n", report.Kubernetes)
+	}
+	for _, name := range operators {
+		if v, ok := report.Operators[name]; ok {
+			fmt.Fprintf(w, "%s\t%s\n", name, v)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,21 @@
+package cli
+
+import "time"
+
+// DefaultWaitTimeout is used when no timeout is given for a waiting command.
+const DefaultWaitTimeout = 10 * time.Minute
+
+// WaitOptions controls whether a command waits for submitted changes to be applied.
+type WaitOptions struct {
+	// Wait makes the command block until the change is applied.
+	Wait bool
+	// Timeout limits how long the command waits.
+	Timeout time.Duration
+}
+
+func (o WaitOptions) timeout() time.Duration {
+	if o.Timeout <= 0 {
+		return DefaultWaitTimeout
+	}
+	return o.Timeout
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"github.com/gen1us2k/everest-provisioner/kubernetes/fake"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestWaitOptionsTimeout(t *testing.T) {
+	assert.Equal(t, DefaultWaitTimeout, WaitOptions{Wait: true}.timeout())
+	assert.Equal(t, time.Minute, WaitOptions{Wait: true, Timeout: time.Minute}.timeout())
+}
+
+func TestWaitForDatabaseClusterReady(t *testing.T) {
+	ctx := context.Background()
+	f, err := fake.NewKubeClient(namespace, &dbaasv1.DatabaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "db"},
+		Status:     dbaasv1.DatabaseClusterStatus{State: "initializing"},
+	})
+	require.NoError(t, err)
+	c := &CLI{kubeClient: kubernetes.NewWithClient(f)}
+
+	err = c.waitForDatabaseClusterReady(ctx, "db", WaitOptions{Wait: true, Timeout: 50 * time.Millisecond})
+	assert.ErrorIs(t, err, kubernetes.ErrTimeout)
+
+	f.Dynamic.ClearActions()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.waitForDatabaseClusterReady(ctx, "db", WaitOptions{Wait: true, Timeout: 10 * time.Second})
+	}()
+	// The cluster becomes ready once the wait watches it.
+	require.Eventually(t, func() bool {
+		for _, action := range f.Dynamic.Actions() {
+			if action.GetVerb() == "watch" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+	_, err = f.PatchDatabaseCluster(ctx, "db", types.MergePatchType, []byte(`{"status":{"status":"ready"}}`))
+	require.NoError(t, err)
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,230 @@
+// Package httpclient builds the clients of outbound HTTP requests, e.g. to the PMM API,
+// Vault and webhooks. The clients share the proxy, the trusted CAs, retries of failed
+// requests and the rate limit.
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultTimeout limits a request including retries if Options.Timeout is not set.
+	DefaultTimeout = 30 * time.Second
+	// DefaultRetries is how often a failed request is repeated by default.
+	DefaultRetries = 3
+
+	retryInitialInterval = 500 * time.Millisecond
+	retryMaxInterval     = 10 * time.Second
+)
+
+// Options configure the clients.
+type Options struct {
+	// Proxy is the URL of the proxy. HTTPS_PROXY, HTTP_PROXY and NO_PROXY are used if it is empty.
+	Proxy string
+	// CAFile is a PEM file of CAs trusted in addition to the system CAs.
+	CAFile string
+	// Timeout limits a request including retries.
+	Timeout time.Duration
+	// Retries is how often requests failed with network errors, 429 or 502-504 are repeated.
+	// Requests are sent once if it is zero. Only idempotent requests and requests marked
+	// with WithRetrySafe are repeated, requests marked with WithoutRetries never.
+	Retries int
+	// DisableRetries sends every request once.
+	DisableRetries bool
+	// RateLimit limits the requests per second of all clients. Zero disables the limit.
+	RateLimit float64
+}
+
+// Factory creates clients sharing the options and the rate limit. It is safe for concurrent use.
+type Factory struct {
+	opts    Options
+	proxy   func(*http.Request) (*url.URL, error)
+	roots   *x509.CertPool
+	limiter *rate.Limiter
+}
+
+// New returns a factory of clients configured with the options. Empty options get defaults.
+func New(opts Options) (*Factory, error) {
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.DisableRetries || opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	f := &Factory{opts: opts, proxy: http.ProxyFromEnvironment}
+	if opts.Proxy != "" {
+		u, err := url.Parse(opts.Proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		f.proxy = http.ProxyURL(u)
+	}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA file: %w", err)
+		}
+		if f.roots, err = x509.SystemCertPool(); err != nil {
+			f.roots = x509.NewCertPool()
+		}
+		if !f.roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM encoded certificates found in %s", opts.CAFile)
+		}
+	}
+	if opts.RateLimit > 0 {
+		f.limiter = rate.NewLimiter(rate.Limit(opts.RateLimit), int(math.Ceil(opts.RateLimit)))
+	}
+	return f, nil
+}
+
+// Client returns a client of the factory. The TLS configuration is optional; the CAs of the
+// factory are trusted if it does not set its own.
+func (f *Factory) Client(tlsConfig *tls.Config) *http.Client {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+	if tlsConfig.RootCAs == nil {
+		tlsConfig.RootCAs = f.roots
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = f.proxy
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{
+		Timeout:   f.opts.Timeout,
+		Transport: &retryTransport{next: transport, retries: f.opts.Retries, limiter: f.limiter},
+	}
+}
+
+// retryTransport waits for the rate limiter before every attempt and repeats requests
+// failed with transient errors with a bounded exponential backoff.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	limiter *rate.Limiter
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	retries := t.retries
+	if !replayable || withoutRetries(req.Context()) || !(idempotent(req.Method) || retrySafe(req.Context())) {
+		retries = 0
+	}
+	interval := retryInitialInterval
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		if t.limiter != nil {
+			if err := t.limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+		resp, err := t.next.RoundTrip(attemptReq)
+		if attempt == retries || !retriable(req, resp, err) {
+			return resp, err
+		}
+		wait := interval
+		if resp != nil {
+			if after := retryAfter(resp); after > 0 {
+				wait = after
+			}
+			io.Copy(io.Discard, resp.Body) //nolint:errcheck
+			resp.Body.Close()              //nolint:errcheck
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		if interval *= 2; interval > retryMaxInterval {
+			interval = retryMaxInterval
+		}
+	}
+}
+
+type retrySafeKey struct{}
+
+// WithRetrySafe returns a context marking the requests sent with it as safe to repeat
+// although their method is not idempotent, e.g. logins or writes of the same value.
+func WithRetrySafe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retrySafeKey{}, true)
+}
+
+func retrySafe(ctx context.Context) bool {
+	safe, _ := ctx.Value(retrySafeKey{}).(bool)
+	return safe
+}
+
+type withoutRetriesKey struct{}
+
+// WithoutRetries returns a context marking the requests sent with it to be sent once,
+// e.g. notifications which must not be delivered twice.
+func WithoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutRetriesKey{}, true)
+}
+
+func withoutRetries(ctx context.Context) bool {
+	once, _ := ctx.Value(withoutRetriesKey{}).(bool)
+	return once
+}
+
+// idempotent returns true for methods that can be repeated without changing the result.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retriable returns true for network errors, throttling and unavailable upstreams.
+// Untrusted certificates are not retried, they fail again.
+func retriable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		var certErr *tls.CertificateVerificationError
+		return req.Context().Err() == nil && !errors.As(err, &certErr)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter returns the wait requested by the Retry-After header in seconds, bounded by
+// the maximum backoff. Zero is returned if the header is missing or is a date.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	if d := time.Duration(seconds) * time.Second; d < retryMaxInterval {
+		return d
+	}
+	return retryMaxInterval
+}
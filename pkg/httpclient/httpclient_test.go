@@ -0,0 +1,119 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetries(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	f, err := New(Options{Retries: DefaultRetries})
+	require.NoError(t, err)
+	resp, err := f.Client(nil).Post(srv.URL, "text/plain", strings.NewReader("body"))
+	require.NoError(t, err)
+	resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "POST is not idempotent")
+	assert.Equal(t, int32(1), calls.Load())
+
+	calls.Store(0)
+	req, err := http.NewRequestWithContext(WithRetrySafe(context.Background()), http.MethodPost, srv.URL, strings.NewReader("body"))
+	require.NoError(t, err)
+	resp, err = f.Client(nil).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+
+	calls.Store(0)
+	resp, err = f.Client(nil).Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+
+	calls.Store(0)
+	req, err = http.NewRequestWithContext(WithoutRetries(context.Background()), http.MethodPut, srv.URL, strings.NewReader("body"))
+	require.NoError(t, err)
+	resp, err = f.Client(nil).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "marked to be sent once")
+	assert.Equal(t, int32(1), calls.Load())
+
+	calls.Store(0)
+	f, err = New(Options{})
+	require.NoError(t, err)
+	resp, err = f.Client(nil).Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "zero retries")
+
+	calls.Store(0)
+	f, err = New(Options{Retries: DefaultRetries, DisableRetries: true})
+	require.NoError(t, err)
+	resp, err = f.Client(nil).Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestProxy(t *testing.T) {
+	t.Parallel()
+	var proxied atomic.Bool
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(r.URL.Host == "pmm.example.com")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(proxy.Close)
+
+	f, err := New(Options{Proxy: proxy.URL})
+	require.NoError(t, err)
+	resp, err := f.Client(nil).Get("http://pmm.example.com/v1/readyz")
+	require.NoError(t, err)
+	resp.Body.Close() //nolint:errcheck
+	assert.True(t, proxied.Load())
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+
+	f, err := New(Options{RateLimit: 10})
+	require.NoError(t, err)
+	client := f.Client(nil)
+	started := time.Now()
+	for i := 0; i < 15; i++ {
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close() //nolint:errcheck
+	}
+	assert.GreaterOrEqual(t, time.Since(started), 400*time.Millisecond)
+}
+
+func TestInvalidOptions(t *testing.T) {
+	t.Parallel()
+	_, err := New(Options{Proxy: "not a url"})
+	assert.Error(t, err)
+	_, err = New(Options{CAFile: "/nonexistent/ca.pem"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+// Package logger configures the logrus logger shared by the cli, kubernetes and client packages.
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// FormatText writes human readable log lines.
+	FormatText = "text"
+	// FormatJSON writes a JSON object per log line.
+	FormatJSON = "json"
+
+	redacted = "[REDACTED]"
+)
+
+// Formats lists the supported log formats.
+var Formats = []string{FormatText, FormatJSON}
+
+// secretFields are the names of fields whose values are never logged.
+var secretFields = []string{"password", "token", "key", "secret", "kubeconfig"}
+
+// Configure sets the level and the format of the standard logger and installs
+// the redaction of secrets. Debug messages are logged if verbose is set.
+func Configure(verbose bool, format string) error {
+	switch format {
+	case "", FormatText:
+		logrus.SetFormatter(&logrus.TextFormatter{})
+	case FormatJSON:
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return fmt.Errorf("unsupported log format %q, supported formats: %s", format, strings.Join(Formats, ", "))
+	}
+	level := logrus.InfoLevel
+	if verbose {
+		level = logrus.DebugLevel
+	}
+	logrus.SetLevel(level)
+	hookOnce.Do(func() { logrus.AddHook(secrets) })
+	return nil
+}
+
+// Redact registers secret values which are replaced in every logged message and field.
+func Redact(values ...string) {
+	secrets.add(values...)
+}
+
+var (
+	secrets  = &redactHook{}
+	hookOnce sync.Once
+)
+
+// redactHook replaces registered secret values and the values of secret fields.
+type redactHook struct {
+	lock   sync.RWMutex
+	values []string
+}
+
+func (h *redactHook) add(values ...string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for _, v := range values {
+		if v != "" {
+			h.values = append(h.values, v)
+		}
+	}
+}
+
+func (h *redactHook) redact(s string) string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	for _, v := range h.values {
+		s = strings.ReplaceAll(s, v, redacted)
+	}
+	return s
+}
+
+func (h *redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.redact(entry.Message)
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		switch {
+		case secretField(k):
+			data[k] = redacted
+		case isString(v):
+			data[k] = h.redact(fmt.Sprint(v))
+		default:
+			data[k] = v
+		}
+	}
+	entry.Data = data
+	return nil
+}
+
+func secretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, f := range secretFields {
+		if strings.Contains(name, f) {
+			return true
+		}
+	}
+	return false
+}
+
+func isString(v interface{}) bool {
+	switch v.(type) {
+	case string, error, fmt.Stringer:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure(t *testing.T) {
+	require.NoError(t, Configure(true, FormatJSON))
+	assert.Equal(t, logrus.DebugLevel, logrus.GetLevel())
+	require.NoError(t, Configure(false, ""))
+	assert.Equal(t, logrus.InfoLevel, logrus.GetLevel())
+	assert.Error(t, Configure(false, "yaml"))
+}
+
+func TestRedact(t *testing.T) {
+	require.NoError(t, Configure(false, FormatText))
+	var buf bytes.Buffer
+	logrus.SetOutput(&buf)
+	Redact("s3cr3t-value")
+
+	logrus.WithField("response", `{"key":"s3cr3t-value"}`).
+		WithField("password", "plain").
+		Info("created key s3cr3t-value")
+
+	out := buf.String()
+	assert.NotContains(t, out, "s3cr3t-value")
+	assert.NotContains(t, out, "plain")
+	assert.Contains(t, out, redacted)
+}
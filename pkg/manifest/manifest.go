@@ -0,0 +1,143 @@
+// Package manifest holds the declarative specification of an Everest installation: the
+// operators, monitoring, backup storages and database clusters. The specification is
+// loaded from a YAML file and the cluster is reconciled to match it.
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// APIVersion is the version of the specification schema.
+	APIVersion = "everest.percona.com/v1alpha1"
+	// Kind is the kind of the specification.
+	Kind = "Everest"
+)
+
+// Spec is the desired state of an Everest installation.
+type Spec struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Operators override the subscription channels of the operators by operator name.
+	// Operators which are not listed are installed from their default channels.
+	Operators map[string]Operator `json:"operators,omitempty"`
+	// Monitoring overrides the monitoring configuration. The configuration is kept if it is not set.
+	Monitoring *Monitoring `json:"monitoring,omitempty"`
+	// BackupStorages are the storages database clusters upload backups to, by storage name.
+	BackupStorages map[string]*dbaasv1.BackupStorageSpec `json:"backupStorages,omitempty"`
+	// DatabaseClusters are created if they do not exist and updated if they differ.
+	// Database clusters which are not listed are left intact.
+	DatabaseClusters []DatabaseCluster `json:"databaseClusters,omitempty"`
+}
+
+// Operator is the desired subscription of an operator.
+type Operator struct {
+	Channel string `json:"channel"`
+}
+
+// Monitoring is the desired PMM monitoring. The password is never read from the
+// specification; it is taken from the configuration or CredentialsSecret is used.
+type Monitoring struct {
+	Enabled  bool   `json:"enabled"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Username string `json:"username,omitempty"`
+	// CredentialsSecret names an existing secret with the credentials metrics are written with.
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
+
+// DatabaseCluster is the desired state of a database cluster.
+type DatabaseCluster struct {
+	Name   string `json:"name"`
+	Engine string `json:"engine"`
+	// Image overrides the default image of the engine. The image of an existing cluster
+	// is changed only if it is set.
+	Image  string `json:"image,omitempty"`
+	Size   int32  `json:"size"`
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+	Disk   string `json:"disk"`
+	// BackupStorages names the storages of Spec.BackupStorages the cluster can upload backups to.
+	BackupStorages []string `json:"backupStorages,omitempty"`
+}
+
+// Load reads the specification from a YAML or JSON file and validates it.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes the specification from YAML or JSON and validates it. Unknown fields are rejected
+// so typos do not silently leave parts of the installation unmanaged.
+func Parse(data []byte) (*Spec, error) {
+	data, err := yaml.ToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse specification: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	spec := &Spec{}
+	if err := dec.Decode(spec); err != nil {
+		return nil, fmt.Errorf("cannot parse specification: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Validate checks the kind and the version of the specification and the references between its parts.
+func (s *Spec) Validate() error {
+	if s.APIVersion != APIVersion || s.Kind != Kind {
+		return fmt.Errorf("unsupported specification %s %s, expected %s %s", s.APIVersion, s.Kind, APIVersion, Kind)
+	}
+	for name, op := range s.Operators {
+		if op.Channel == "" {
+			return fmt.Errorf("operator %s has no channel", name)
+		}
+	}
+	if s.Monitoring != nil && s.Monitoring.Enabled && s.Monitoring.Endpoint == "" {
+		return fmt.Errorf("monitoring is enabled without an endpoint")
+	}
+	for name, storage := range s.BackupStorages {
+		if storage == nil {
+			return fmt.Errorf("backup storage %s is empty", name)
+		}
+	}
+	names := make(map[string]struct{}, len(s.DatabaseClusters))
+	for _, db := range s.DatabaseClusters {
+		if db.Name == "" {
+			return fmt.Errorf("database cluster without a name")
+		}
+		if _, ok := names[db.Name]; ok {
+			return fmt.Errorf("database cluster %s is declared twice", db.Name)
+		}
+		names[db.Name] = struct{}{}
+		if db.Size <= 0 {
+			return fmt.Errorf("database cluster %s has invalid size %d", db.Name, db.Size)
+		}
+		for _, storage := range db.BackupStorages {
+			if _, ok := s.BackupStorages[storage]; !ok {
+				return fmt.Errorf("database cluster %s uses undeclared backup storage %s", db.Name, storage)
+			}
+		}
+	}
+	return nil
+}
+
+// OperatorChannels returns the channels of the operators by operator name.
+func (s *Spec) OperatorChannels() map[string]string {
+	channels := make(map[string]string, len(s.Operators))
+	for name, op := range s.Operators {
+		channels[name] = op.Channel
+	}
+	return channels
+}
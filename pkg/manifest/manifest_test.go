@@ -0,0 +1,141 @@
+package manifest
+
+import (
+	"context"
+	"testing"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const testSpec = `
+apiVersion: everest.percona.com/v1alpha1
+kind: Everest
+operators:
+  dbaas-operator:
+    channel: stable-v0
+backupStorages:
+  s3:
+    type: s3
+databaseClusters:
+- name: orders
+  engine: pxc
+  size: 3
+  cpu: "1"
+  memory: 2G
+  disk: 25G
+  backupStorages: [s3]
+- name: sessions
+  engine: psmdb
+  size: 1
+  cpu: 500m
+  memory: 1G
+  disk: 10G
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(testSpec))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"dbaas-operator": "stable-v0"}, spec.OperatorChannels())
+	assert.Len(t, spec.DatabaseClusters, 2)
+
+	for _, tc := range []struct {
+		name string
+		spec string
+	}{
+		{name: "kind", spec: "apiVersion: everest.percona.com/v1alpha1\nkind: Other\n"},
+		{name: "unknown field", spec: "apiVersion: everest.percona.com/v1alpha1\nkind: Everest\noperator: {}\n"},
+		{name: "channel", spec: "apiVersion: everest.percona.com/v1alpha1\nkind: Everest\noperators:\n  dbaas-operator: {}\n"},
+		{name: "storage", spec: "apiVersion: everest.percona.com/v1alpha1\nkind: Everest\ndatabaseClusters:\n- {name: db, size: 1, backupStorages: [s3]}\n"},
+		{name: "duplicate", spec: "apiVersion: everest.percona.com/v1alpha1\nkind: Everest\ndatabaseClusters:\n- {name: db, size: 1}\n- {name: db, size: 1}\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.spec))
+			assert.Error(t, err)
+		})
+	}
+}
+
+type fakeTarget struct {
+	clusters    map[string]*dbaasv1.DatabaseCluster
+	provisioned bool
+	created     []string
+	updated     []string
+}
+
+func (f *fakeTarget) Provision(context.Context, *Spec) error {
+	f.provisioned = true
+	return nil
+}
+
+func (f *fakeTarget) BuildDatabaseCluster(_ context.Context, db DatabaseCluster) (*dbaasv1.DatabaseCluster, error) {
+	return &dbaasv1.DatabaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: db.Name},
+		Spec: dbaasv1.DatabaseSpec{
+			Database:      dbaasv1.EngineType(db.Engine),
+			DatabaseImage: "default-image",
+			ClusterSize:   db.Size,
+			DBInstance: dbaasv1.DBInstanceSpec{
+				CPU:      resource.MustParse(db.CPU),
+				Memory:   resource.MustParse(db.Memory),
+				DiskSize: resource.MustParse(db.Disk),
+			},
+			LoadBalancer: dbaasv1.LoadBalancerSpec{Size: db.Size},
+		},
+	}, nil
+}
+
+func (f *fakeTarget) GetDatabaseCluster(_ context.Context, name string) (*dbaasv1.DatabaseCluster, error) {
+	cluster, ok := f.clusters[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: "dbaas.percona.com", Resource: "databaseclusters"}, name)
+	}
+	return cluster.DeepCopy(), nil
+}
+
+func (f *fakeTarget) CreateDatabaseCluster(_ context.Context, cluster *dbaasv1.DatabaseCluster) error {
+	f.created = append(f.created, cluster.Name)
+	f.clusters[cluster.Name] = cluster
+	return nil
+}
+
+func (f *fakeTarget) UpdateDatabaseCluster(_ context.Context, cluster *dbaasv1.DatabaseCluster) error {
+	f.updated = append(f.updated, cluster.Name)
+	f.clusters[cluster.Name] = cluster
+	return nil
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	spec, err := Parse([]byte(testSpec))
+	require.NoError(t, err)
+
+	target := &fakeTarget{clusters: map[string]*dbaasv1.DatabaseCluster{}}
+	existing, err := target.BuildDatabaseCluster(ctx, spec.DatabaseClusters[1])
+	require.NoError(t, err)
+	existing.Spec.DatabaseImage = "upgraded-image"
+	target.clusters["sessions"] = existing
+
+	results, err := Reconcile(ctx, spec, target)
+	require.NoError(t, err)
+	assert.True(t, target.provisioned)
+	assert.Equal(t, []Result{{Name: "orders", Action: ActionCreated}, {Name: "sessions", Action: ActionUnchanged}}, results)
+	assert.Contains(t, target.clusters["orders"].Spec.Backup.Storages, "s3")
+	assert.Equal(t, "upgraded-image", target.clusters["sessions"].Spec.DatabaseImage)
+
+	spec.DatabaseClusters[1].Size = 3
+	results, err = Reconcile(ctx, spec, target)
+	require.NoError(t, err)
+	assert.Equal(t, []Result{{Name: "orders", Action: ActionUnchanged}, {Name: "sessions", Action: ActionUpdated}}, results)
+	assert.Equal(t, int32(3), target.clusters["sessions"].Spec.ClusterSize)
+	assert.Equal(t, []string{"sessions"}, target.updated)
+
+	spec.DatabaseClusters[1].Engine = "pxc"
+	_, err = Reconcile(ctx, spec, target)
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Action is what the reconciliation did to an object.
+type Action string
+
+const (
+	// ActionCreated means the object did not exist and has been created.
+	ActionCreated Action = "created"
+	// ActionUpdated means the object differed from the specification and has been updated.
+	ActionUpdated Action = "updated"
+	// ActionUnchanged means the object matched the specification.
+	ActionUnchanged Action = "unchanged"
+)
+
+// Result is the outcome of the reconciliation of a database cluster.
+type Result struct {
+	Name   string `json:"name"`
+	Action Action `json:"action"`
+}
+
+// Target is the cluster the specification is reconciled with.
+type Target interface {
+	// Provision installs the operators and monitoring of the specification. It must be
+	// safe to call on a provisioned cluster.
+	Provision(ctx context.Context, spec *Spec) error
+	// BuildDatabaseCluster returns the database cluster described by the specification
+	// with the defaults of the engine applied.
+	BuildDatabaseCluster(ctx context.Context, db DatabaseCluster) (*dbaasv1.DatabaseCluster, error)
+	GetDatabaseCluster(ctx context.Context, name string) (*dbaasv1.DatabaseCluster, error)
+	CreateDatabaseCluster(ctx context.Context, cluster *dbaasv1.DatabaseCluster) error
+	UpdateDatabaseCluster(ctx context.Context, cluster *dbaasv1.DatabaseCluster) error
+}
+
+// Reconcile provisions the operators and monitoring and then creates or updates the database
+// clusters of the specification in order. It stops at the first failure; the results of the
+// database clusters reconciled so far are returned together with the error.
+func Reconcile(ctx context.Context, spec *Spec, target Target) ([]Result, error) {
+	if err := target.Provision(ctx, spec); err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(spec.DatabaseClusters))
+	for _, db := range spec.DatabaseClusters {
+		action, err := reconcileDatabaseCluster(ctx, spec, db, target)
+		if err != nil {
+			return results, fmt.Errorf("cannot reconcile database cluster %s: %w", db.Name, err)
+		}
+		results = append(results, Result{Name: db.Name, Action: action})
+	}
+	return results, nil
+}
+
+func reconcileDatabaseCluster(ctx context.Context, spec *Spec, db DatabaseCluster, target Target) (Action, error) {
+	desired, err := target.BuildDatabaseCluster(ctx, db)
+	if err != nil {
+		return "", err
+	}
+	if len(db.BackupStorages) != 0 {
+		if desired.Spec.Backup == nil {
+			desired.Spec.Backup = &dbaasv1.BackupSpec{}
+		}
+		desired.Spec.Backup.Storages = make(map[string]*dbaasv1.BackupStorageSpec, len(db.BackupStorages))
+		for _, name := range db.BackupStorages {
+			desired.Spec.Backup.Storages[name] = spec.BackupStorages[name]
+		}
+	}
+	current, err := target.GetDatabaseCluster(ctx, db.Name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return "", err
+		}
+		if err := target.CreateDatabaseCluster(ctx, desired); err != nil {
+			return "", err
+		}
+		return ActionCreated, nil
+	}
+	if current.Spec.Database != desired.Spec.Database {
+		return "", fmt.Errorf("engine %s cannot be changed to %s", current.Spec.Database, desired.Spec.Database)
+	}
+	if !mergeDatabaseCluster(current, desired, db.Image != "") {
+		return ActionUnchanged, nil
+	}
+	if err := target.UpdateDatabaseCluster(ctx, current); err != nil {
+		return "", err
+	}
+	return ActionUpdated, nil
+}
+
+// mergeDatabaseCluster copies the fields managed by the specification from desired to current.
+// The image is copied only if the specification sets it so upgrades made by other means are kept.
+// It returns true if current has changed.
+func mergeDatabaseCluster(current, desired *dbaasv1.DatabaseCluster, image bool) bool {
+	updated := current.DeepCopy()
+	if image {
+		updated.Spec.DatabaseImage = desired.Spec.DatabaseImage
+	}
+	updated.Spec.ClusterSize = desired.Spec.ClusterSize
+	updated.Spec.LoadBalancer.Size = desired.Spec.LoadBalancer.Size
+	updated.Spec.DBInstance.CPU = desired.Spec.DBInstance.CPU
+	updated.Spec.DBInstance.Memory = desired.Spec.DBInstance.Memory
+	updated.Spec.DBInstance.DiskSize = desired.Spec.DBInstance.DiskSize
+	if desired.Spec.Backup != nil && desired.Spec.Backup.Storages != nil {
+		if updated.Spec.Backup == nil {
+			updated.Spec.Backup = &dbaasv1.BackupSpec{}
+		}
+		updated.Spec.Backup.Storages = desired.Spec.Backup.Storages
+	}
+	if equality.Semantic.DeepEqual(current.Spec, updated.Spec) {
+		return false
+	}
+	current.Spec = updated.Spec
+	return true
+}
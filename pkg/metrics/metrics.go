@@ -0,0 +1,130 @@
+// Package metrics exposes Prometheus metrics of the provisioner itself: applied objects,
+// install phase durations, retried API requests and failed provisioning runs.
+package metrics
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// Path is the path metrics are served on.
+	Path = "/metrics"
+
+	namespace = "everest_provisioner"
+
+	resultSuccess = "success"
+	resultFailure = "failure"
+
+	shutdownTimeout = 10 * time.Second
+)
+
+var (
+	applyOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "apply_operations_total",
+		Help:      "Objects applied to the cluster by kind and result.",
+	}, []string{"kind", "result"})
+	applyDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "apply_duration_seconds",
+		Help:      "Time it took to apply an object by kind.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"kind"})
+	installPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "install_phase_duration_seconds",
+		Help:      "Time the installation phases took by phase and result.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"phase", "result"})
+	retries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_request_retries_total",
+		Help:      "Kubernetes API requests repeated after transient failures by HTTP method.",
+	}, []string{"method"})
+	provisioningRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provisioning_runs_total",
+		Help:      "Installations and reconciliations of the cluster by result.",
+	}, []string{"result"})
+)
+
+// registry holds the metrics of the provisioner and of the Go runtime.
+var registry = prometheus.NewRegistry()
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		applyOperations,
+		applyDuration,
+		installPhaseDuration,
+		retries,
+		provisioningRuns,
+	)
+}
+
+// ObserveApply records an object of the kind applied since started.
+func ObserveApply(kind string, started time.Time, err error) {
+	applyOperations.WithLabelValues(kind, result(err != nil)).Inc()
+	applyDuration.WithLabelValues(kind).Observe(time.Since(started).Seconds())
+}
+
+// ObserveInstallPhase records how long the installation phase took.
+func ObserveInstallPhase(phase string, duration time.Duration, failed bool) {
+	installPhaseDuration.WithLabelValues(phase, result(failed)).Observe(duration.Seconds())
+}
+
+// ObserveRetry records an API request repeated after a transient failure.
+func ObserveRetry(method string) {
+	retries.WithLabelValues(method).Inc()
+}
+
+// ObserveProvisioning records the result of an installation or a reconciliation.
+func ObserveProvisioning(err error) {
+	provisioningRuns.WithLabelValues(result(err != nil)).Inc()
+}
+
+func result(failed bool) string {
+	if failed {
+		return resultFailure
+	}
+	return resultSuccess
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ListenAndServe serves the metrics on the address until the context is done.
+func ListenAndServe(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler())
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(_ net.Listener) context.Context {
+			return ctx
+		},
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	ObserveApply("ConfigMap", time.Now(), nil)
+	ObserveApply("Secret", time.Now(), errors.New("forbidden"))
+	ObserveInstallPhase("olm", 3*time.Second, false)
+	ObserveRetry(http.MethodGet)
+	ObserveProvisioning(nil)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, `everest_provisioner_apply_operations_total{kind="ConfigMap",result="success"} 1`)
+	assert.Contains(t, body, `everest_provisioner_apply_operations_total{kind="Secret",result="failure"} 1`)
+	assert.Contains(t, body, `everest_provisioner_install_phase_duration_seconds_count{phase="olm",result="success"} 1`)
+	assert.Contains(t, body, `everest_provisioner_api_request_retries_total{method="GET"} 1`)
+	assert.Contains(t, body, `everest_provisioner_provisioning_runs_total{result="success"} 1`)
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	dbaasv1 "github.com/percona/dbaas-operator/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	databaseClustersPath = "/v1/database-clusters"
+	eventsSuffix         = "/events"
+
+	// maxBodySize limits the size of database cluster manifests and patches.
+	maxBodySize = 1 << 20
+)
+
+// Types of events sent over the database cluster event stream.
+const (
+	EventStatus  = "status"
+	EventDeleted = "deleted"
+	EventError   = "error"
+)
+
+// databaseClusters serves the collection of database clusters.
+func (s *Server) databaseClusters(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		clusters, err := s.kubeClient.ListDatabaseClusters(r.Context())
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, clusters)
+	case http.MethodPost:
+		s.createDatabaseCluster(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		s.writeError(w, &requestError{status: http.StatusMethodNotAllowed, msg: "method not allowed"})
+	}
+}
+
+// databaseCluster serves a single database cluster and its event stream.
+func (s *Server) databaseCluster(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, databaseClustersPath+"/")
+	if strings.HasSuffix(name, eventsSuffix) {
+		name = strings.TrimSuffix(name, eventsSuffix)
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			s.writeError(w, &requestError{status: http.StatusMethodNotAllowed, msg: "method not allowed"})
+			return
+		}
+		s.streamDatabaseCluster(w, r, name)
+		return
+	}
+	if name == "" || strings.Contains(name, "/") {
+		s.writeError(w, &requestError{status: http.StatusNotFound, msg: "not found"})
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		cluster, err := s.kubeClient.GetDatabaseCluster(r.Context(), name)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, cluster)
+	case http.MethodPatch:
+		s.patchDatabaseCluster(w, r, name)
+	case http.MethodDelete:
+		if err := s.kubeClient.DeleteDatabaseCluster(r.Context(), name); err != nil {
+			s.writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PATCH, DELETE")
+		s.writeError(w, &requestError{status: http.StatusMethodNotAllowed, msg: "method not allowed"})
+	}
+}
+
+func (s *Server) createDatabaseCluster(w http.ResponseWriter, r *http.Request) {
+	cluster := &dbaasv1.DatabaseCluster{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBodySize)).Decode(cluster); err != nil {
+		s.writeError(w, &requestError{status: http.StatusBadRequest, msg: fmt.Sprintf("invalid database cluster: %v", err)})
+		return
+	}
+	if cluster.Name == "" {
+		s.writeError(w, &requestError{status: http.StatusBadRequest, msg: "database cluster name is required"})
+		return
+	}
+	// CreateDatabaseCluster replaces existing clusters; refuse to do that on POST.
+	_, err := s.kubeClient.GetDatabaseCluster(r.Context(), cluster.Name)
+	switch {
+	case err == nil:
+		s.writeError(w, &requestError{status: http.StatusConflict, msg: fmt.Sprintf("database cluster %s already exists", cluster.Name)})
+		return
+	case !apierrors.IsNotFound(err):
+		s.writeError(w, err)
+		return
+	}
+	if err := s.kubeClient.CreateDatabaseCluster(r.Context(), cluster); err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, cluster)
+}
+
+func (s *Server) patchDatabaseCluster(w http.ResponseWriter, r *http.Request, name string) {
+	patchType := types.MergePatchType
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			s.writeError(w, &requestError{status: http.StatusUnsupportedMediaType, msg: err.Error()})
+			return
+		}
+		if mediaType != "application/json" {
+			patchType = types.PatchType(mediaType)
+		}
+	}
+	if patchType != types.MergePatchType && patchType != types.JSONPatchType {
+		s.writeError(w, &requestError{
+			status: http.StatusUnsupportedMediaType,
+			msg:    fmt.Sprintf("unsupported patch type %q", patchType),
+		})
+		return
+	}
+	patch, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		s.writeError(w, &requestError{status: http.StatusBadRequest, msg: err.Error()})
+		return
+	}
+	if !json.Valid(patch) {
+		s.writeError(w, &requestError{status: http.StatusBadRequest, msg: "patch is not a valid JSON document"})
+		return
+	}
+	cluster, err := s.kubeClient.PatchDatabaseClusterFields(r.Context(), name, patch, patchType)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, cluster)
+}
+
+// streamDatabaseCluster pushes the database cluster to the client as server-sent events
+// every time it changes until the cluster is deleted or the client disconnects.
+func (s *Server) streamDatabaseCluster(w http.ResponseWriter, r *http.Request, name string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeError(w, errors.New("streaming is not supported"))
+		return
+	}
+	if _, err := s.kubeClient.GetDatabaseCluster(r.Context(), name); err != nil {
+		s.writeError(w, err)
+		return
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	updates := make(chan *dbaasv1.DatabaseCluster)
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := s.kubeClient.WaitForDatabaseCluster(ctx, name, func(cluster *dbaasv1.DatabaseCluster) (bool, error) {
+			select {
+			case updates <- cluster:
+				return false, nil
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+		})
+		errCh <- err
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(s.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case cluster := <-updates:
+			err = writeEvent(w, EventStatus, cluster)
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		case err = <-errCh:
+			switch {
+			case errors.Is(err, kubernetes.ErrDatabaseClusterDeleted):
+				_ = writeEvent(w, EventDeleted, map[string]string{"name": name})
+			case err != nil && ctx.Err() == nil:
+				_ = writeEvent(w, EventError, errorResponse{Error: err.Error()})
+			}
+			flusher.Flush()
+			return
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			s.l.Debugf("stopped streaming database cluster %s: %v", name, err)
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes a server-sent event with the JSON encoded data.
+func writeEvent(w io.Writer, event string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+	return err
+}
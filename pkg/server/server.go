@@ -0,0 +1,159 @@
+// Package server exposes database clusters over HTTP for the Everest frontend.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gen1us2k/everest-provisioner/kubernetes"
+	"github.com/gen1us2k/everest-provisioner/pkg/metrics"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// DefaultHeartbeatInterval is how often an idle event stream sends a keep-alive comment.
+	DefaultHeartbeatInterval = 30 * time.Second
+
+	shutdownTimeout = 10 * time.Second
+)
+
+// Server serves the database cluster API.
+type Server struct {
+	kubeClient *kubernetes.Kubernetes
+	l          *logrus.Entry
+	heartbeat  time.Duration
+	// token is the bearer token required by mutating requests.
+	token string
+}
+
+// New returns a new server using the kubernetes client. Mutating requests must carry
+// the token as a bearer token and are refused if the token is empty.
+func New(kubeClient *kubernetes.Kubernetes, token string) *Server {
+	return &Server{
+		kubeClient: kubeClient,
+		l:          logrus.WithField("component", "server"),
+		heartbeat:  DefaultHeartbeatInterval,
+		token:      token,
+	}
+}
+
+// Handler returns the HTTP handler of the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(databaseClustersPath, s.databaseClusters)
+	mux.HandleFunc(databaseClustersPath+"/", s.databaseCluster)
+	mux.Handle(metrics.Path, metrics.Handler())
+	return s.authenticate(mux)
+}
+
+// authenticate refuses mutating requests without the bearer token of the server.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if s.token == "" {
+			s.writeError(w, &requestError{status: http.StatusForbidden, msg: "mutating requests are disabled, start the server with a token"})
+			return
+		}
+		token, ok := bearerToken(r)
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			s.writeError(w, &requestError{status: http.StatusUnauthorized, msg: "invalid or missing bearer token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken returns the bearer token of the Authorization header.
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if len(auth) <= len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", false
+	}
+	return auth[len(prefix):], true
+}
+
+// ListenAndServe serves the API on the address until the context is done.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(_ net.Listener) context.Context {
+			return ctx
+		},
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		s.l.Infof("Listening on %s", addr)
+		errCh <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// errorResponse is the body of failed requests.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.l.Errorf("failed writing response: %v", err)
+	}
+}
+
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	s.writeJSON(w, errorStatus(err), errorResponse{Error: err.Error()})
+}
+
+// errorStatus returns the HTTP status code matching the error.
+func errorStatus(err error) int {
+	var reqErr *requestError
+	switch {
+	case errors.As(err, &reqErr):
+		return reqErr.status
+	case apierrors.IsNotFound(err):
+		return http.StatusNotFound
+	case errors.Is(err, kubernetes.ErrAlreadyExists):
+		return http.StatusConflict
+	case errors.Is(err, kubernetes.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, kubernetes.ErrTimeout):
+		return http.StatusGatewayTimeout
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// requestError is returned for requests the server refuses to process.
+type requestError struct {
+	status int
+	msg    string
+}
+
+func (e *requestError) Error() string {
+	return e.msg
+}
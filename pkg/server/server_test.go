@@ -0,0 +1,71 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestErrorStatus(t *testing.T) {
+	gr := schema.GroupResource{Group: "dbaas.percona.com", Resource: "databaseclusters"}
+	assert.Equal(t, http.StatusNotFound, errorStatus(errors.Wrap(apierrors.NewNotFound(gr, "test"), "cannot get")))
+	assert.Equal(t, http.StatusConflict, errorStatus(&requestError{status: http.StatusConflict, msg: "exists"}))
+	assert.Equal(t, http.StatusInternalServerError, errorStatus(errors.New("unknown")))
+}
+
+func TestWriteEvent(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeEvent(&buf, EventDeleted, map[string]string{"name": "test"}))
+	assert.Equal(t, "event: deleted\ndata: {\"name\":\"test\"}\n\n", buf.String())
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	s := New(nil, "secret")
+	for _, tc := range []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodPut, path: "/v1/database-clusters"},
+		{method: http.MethodPost, path: "/v1/database-clusters/test"},
+		{method: http.MethodPost, path: "/v1/database-clusters/test/events"},
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		s.Handler().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, tc.method+" "+tc.path)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		token  string
+		method string
+		auth   string
+		status int
+	}{
+		{name: "no token configured", method: http.MethodDelete, auth: "Bearer secret", status: http.StatusForbidden},
+		{name: "missing token", token: "secret", method: http.MethodDelete, status: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", method: http.MethodPatch, auth: "Bearer other", status: http.StatusUnauthorized},
+		{name: "valid token", token: "secret", method: http.MethodPut, auth: "Bearer secret", status: http.StatusMethodNotAllowed},
+		{name: "basic auth", token: "secret", method: http.MethodPut, auth: "Basic secret", status: http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, "/v1/database-clusters", nil)
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+			New(nil, tc.token).Handler().ServeHTTP(rec, req)
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// Package version holds the build information of the binary.
+// The variables are set at build time, e.g.
+//
+//	go build -ldflags "-X github.com/gen1us2k/everest-provisioner/pkg/version.Version=v0.1.0 \
+//		-X github.com/gen1us2k/everest-provisioner/pkg/version.GitCommit=$(git rev-parse HEAD) \
+//		-X github.com/gen1us2k/everest-provisioner/pkg/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" \
+//		./cmd/everest-cli
+package version
+
+import "runtime"
+
+var (
+	// Version is the released version of the binary.
+	Version = "dev"
+	// GitCommit is the commit the binary was built from.
+	GitCommit = "unknown"
+	// BuildDate is the time the binary was built at in RFC 3339 format.
+	BuildDate = "unknown"
+)
+
+// Info describes the build of the binary.
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get returns the build information.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+}